@@ -0,0 +1,78 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"io"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"bronivik/internal/importer"
+)
+
+// runImport выполняет подкоманду import: загрузку заявок, аппаратов или пользователей
+// из файла CSV или XLSX прямо в базу, минуя бота. Отчет печатается в stdout.
+//
+//	bot import -kind bookings -file bookings.xlsx [-map "date=Дата заявки,item=Аппарат"] [-dry-run] [-batch 500]
+func runImport(args []string) error {
+	fs := flag.NewFlagSet("import", flag.ContinueOnError)
+	kind := fs.String("kind", "", "records to import: bookings, items or users")
+	file := fs.String("file", "", "CSV or XLSX file")
+	mapping := fs.String("map", "", `column mapping, e.g. "date=Дата заявки,item=Аппарат"`)
+	dryRun := fs.Bool("dry-run", false, "validate the file without saving anything")
+	batch := fs.Int("batch", importer.DefaultBatchSize, "records saved in one transaction")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *kind == "" || *file == "" {
+		fs.Usage()
+		return errors.New("-kind and -file are required")
+	}
+
+	k, err := importer.ParseKind(*kind)
+	if err != nil {
+		return err
+	}
+	opts := importer.Options{Kind: k, DryRun: *dryRun, BatchSize: *batch}
+	if *mapping != "" {
+		m, err := importer.ParseMapping(*mapping)
+		if err != nil {
+			return err
+		}
+		opts.Mapping = m
+	}
+
+	f, err := os.Open(*file)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	table, err := importer.ReadTable(*file, f)
+	if err != nil {
+		return err
+	}
+
+	cfg, items, logger, closer, err := loadConfigAndLogger()
+	if err != nil {
+		return err
+	}
+	if closer != nil {
+		defer (func(c io.Closer) { _ = c.Close() })(closer)
+	}
+	db, err := initDatabase(cfg, items, &logger)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	report, err := importer.Run(ctx, db, table, opts)
+	if report != nil {
+		_ = report.WriteText(os.Stdout, 0)
+	}
+	return err
+}
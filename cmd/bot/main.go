@@ -29,7 +29,17 @@ import (
 	"gopkg.in/yaml.v2"
 )
 
+// accessReloadInterval — как часто бот перечитывает роли и черный список из БД
+const accessReloadInterval = time.Minute
+
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "import" {
+		if err := runImport(os.Args[2:]); err != nil {
+			log.Fatalf("Import failed: %v", err)
+		}
+		return
+	}
+
 	if err := run(); err != nil {
 		log.Fatalf("Fatal error: %v", err)
 	}
@@ -78,6 +88,13 @@ func run() error {
 	// Инициализация бизнес-сервисов
 	bookingService := service.NewBookingService(db, eventBus, sheetsWorker, cfg.Bot.MaxBookingDays, cfg.Bot.MinBookingAdvance, &logger)
 	userService := service.NewUserService(db, cfg, &logger)
+	if err := userService.LoadRoles(ctx); err != nil {
+		logger.Error().Err(err).Msg("Failed to load user roles")
+	}
+	if err := userService.LoadBlacklist(ctx); err != nil {
+		logger.Error().Err(err).Msg("Failed to load blacklist")
+	}
+	go reloadAccessLists(ctx, userService, &logger)
 	itemService := service.NewItemService(db, &logger)
 	metrics := bot.NewMetrics()
 
@@ -101,6 +118,33 @@ func run() error {
 	return startBot(ctx, cfg, stateService, sheetsService, sheetsWorker, eventBus, bookingService, userService, itemService, metrics, &logger)
 }
 
+// reloadAccessLists перечитывает роли и черный список из БД по таймеру и по SIGHUP,
+// чтобы изменения из bronivikctl применялись без перезапуска бота
+func reloadAccessLists(ctx context.Context, users *service.UserService, logger *zerolog.Logger) {
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	defer signal.Stop(hup)
+
+	ticker := time.NewTicker(accessReloadInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-hup:
+			logger.Info().Msg("SIGHUP received, reloading roles and blacklist")
+		}
+		if err := users.LoadRoles(ctx); err != nil {
+			logger.Error().Err(err).Msg("Failed to reload user roles")
+		}
+		if err := users.LoadBlacklist(ctx); err != nil {
+			logger.Error().Err(err).Msg("Failed to reload blacklist")
+		}
+	}
+}
+
 func loadConfigAndLogger() (*config.Config, []models.Item, zerolog.Logger, io.Closer, error) {
 	configPath := os.Getenv("CONFIG_PATH")
 	if configPath == "" {
@@ -251,6 +295,10 @@ func startBot(
 
 	logger.Info().Msg("Бот запущен...")
 	telegramBot.StartReminders(ctx)
+	telegramBot.StartDigest(ctx)
+	telegramBot.StartEscalations(ctx)
+	telegramBot.StartOverdueReturns(ctx)
+	telegramBot.StartExternalCalendars(ctx)
 	telegramBot.Start(ctx)
 
 	logger.Info().Msg("Shutdown complete.")
@@ -267,6 +315,8 @@ func subscribeBookingEvents(
 	if bus == nil || sheetsWorker == nil || db == nil {
 		return
 	}
+	// Обработчики событий только синхронизируют таблицу, в журнале это источник "таблица"
+	ctx = models.WithAuditActor(ctx, models.AuditActor{Source: models.AuditSourceSheet})
 
 	decode := func(ev *events.Event) (events.BookingEventPayload, error) {
 		var payload events.BookingEventPayload
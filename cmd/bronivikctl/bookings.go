@@ -0,0 +1,124 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+
+	"bronivik/internal/models"
+)
+
+// defaultListLimit — сколько заявок показывает bookings list без -limit
+const defaultListLimit = 50
+
+func runBookingsList(ctx context.Context, c *ctl, args []string) error {
+	fs := c.newFlagSet("bookings list")
+	query := url.Values{}
+	for _, name := range []string{"id", "status", "from", "to", "name", "phone", "offset"} {
+		fs.Func(name, "filter by "+name, func(v string) error {
+			query.Set(name, v)
+			return nil
+		})
+	}
+	fs.Func("item", "filter by item ids, comma-separated", func(v string) error {
+		query.Set("item_id", v)
+		return nil
+	})
+	limit := fs.Int("limit", defaultListLimit, fmt.Sprintf("max bookings to show, up to %d", models.MaxSearchResults))
+	asJSON := fs.Bool("json", false, "print JSON")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	query.Set("limit", strconv.Itoa(*limit))
+
+	// Фильтр разбирается так же, как в API, поэтому оба режима понимают одни и те же значения
+	filter, err := models.ParseBookingFilter(query)
+	if err != nil {
+		return err
+	}
+	if filter.Limit <= 0 || filter.Limit > models.MaxSearchResults {
+		filter.Limit = models.MaxSearchResults
+	}
+
+	var bookings []*models.Booking
+	if c.apiURL != "" {
+		var resp struct {
+			Bookings []*models.Booking `json:"bookings"`
+		}
+		if err := c.apiGet(ctx, "/api/v1/bookings", filter.Values(), &resp); err != nil {
+			return err
+		}
+		bookings = resp.Bookings
+	} else {
+		if err := c.open(ctx); err != nil {
+			return err
+		}
+		if bookings, err = c.bookings.SearchBookings(ctx, filter); err != nil {
+			return err
+		}
+	}
+
+	if *asJSON {
+		if bookings == nil {
+			bookings = []*models.Booking{}
+		}
+		return c.printJSON(bookings)
+	}
+	w := c.table()
+	fmt.Fprintln(w, "ID\tDATE\tITEM\tCLIENT\tPHONE\tSTATUS\tVERSION")
+	for _, b := range bookings {
+		fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%s\t%s\t%d\n",
+			b.ID, b.Date.Format("2006-01-02"), b.ItemName, b.UserName, b.Phone, b.Status, b.Version)
+	}
+	return w.Flush()
+}
+
+// runBookingsSetStatus меняет статус заявки теми же методами, что и кнопки менеджера в боте.
+// Уведомления клиенту при этом не отправляются: их рассылает только бот.
+func runBookingsSetStatus(ctx context.Context, c *ctl, args []string) error {
+	fs := c.newFlagSet("bookings set-status")
+	id := fs.Int64("id", 0, "booking id")
+	status := fs.String("status", "", "new status: confirmed, canceled, completed or pending")
+	by := fs.Int64("by", 0, "telegram id recorded as the author of the change")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *id <= 0 || *status == "" {
+		fs.Usage()
+		return errors.New("-id and -status are required")
+	}
+
+	if err := c.open(ctx); err != nil {
+		return err
+	}
+
+	var apply func(ctx context.Context, bookingID, version, managerID int64) error
+	switch *status {
+	case models.StatusConfirmed:
+		apply = c.bookings.ConfirmBooking
+	case models.StatusCanceled:
+		apply = c.bookings.RejectBooking
+	case models.StatusCompleted:
+		apply = c.bookings.CompleteBooking
+	case models.StatusPending:
+		apply = c.bookings.ReopenBooking
+	default:
+		return fmt.Errorf("unsupported status %q", *status)
+	}
+
+	booking, err := c.bookings.GetBooking(ctx, *id)
+	if err != nil {
+		return err
+	}
+	if booking.Status == *status {
+		fmt.Fprintf(c.out, "booking #%d is already %s\n", booking.ID, booking.Status)
+		return nil
+	}
+	if err := apply(ctx, booking.ID, booking.Version, *by); err != nil {
+		return err
+	}
+	fmt.Fprintf(c.out, "booking #%d: %s -> %s\n", booking.ID, booking.Status, *status)
+	return nil
+}
@@ -0,0 +1,169 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"bronivik/internal/config"
+	"bronivik/internal/database"
+	"bronivik/internal/domain"
+	"bronivik/internal/service"
+	"bronivik/internal/worker"
+
+	"github.com/rs/zerolog"
+)
+
+// ctl хранит общие настройки утилиты и лениво открывает конфиг, базу и сервисы:
+// команды, которым база не нужна (apikeys, backup restore), ее не блокируют
+type ctl struct {
+	configPath string
+	itemsPath  string
+	apiURL     string
+	apiKey     string
+	apiExtra   string
+	out        io.Writer
+	errOut     io.Writer
+
+	cfg      *config.Config
+	logger   zerolog.Logger
+	db       *database.DB
+	bookings *service.BookingService
+	users    *service.UserService
+	items    *service.ItemService
+}
+
+func (c *ctl) config() (*config.Config, error) {
+	if c.cfg != nil {
+		return c.cfg, nil
+	}
+	cfg, err := config.Load(c.configPath)
+	if err != nil {
+		return nil, fmt.Errorf("load config %s: %w", c.configPath, err)
+	}
+	c.cfg = cfg
+	// Утилита пишет результат в stdout, поэтому в лог идут только предупреждения и ошибки
+	c.logger = zerolog.New(zerolog.ConsoleWriter{Out: c.errOut, TimeFormat: time.TimeOnly}).
+		Level(zerolog.WarnLevel).With().Timestamp().Logger()
+	return cfg, nil
+}
+
+// open открывает базу из конфига и создает сервисы поверх нее. Изменения идут через сервисы,
+// как в боте: они пишут журнал изменений и ставят задачи синхронизации с Google Sheets.
+func (c *ctl) open(ctx context.Context) error {
+	if c.db != nil {
+		return nil
+	}
+	cfg, err := c.config()
+	if err != nil {
+		return err
+	}
+
+	db, err := database.NewDB(cfg.Database.Path, &c.logger)
+	if err != nil {
+		return fmt.Errorf("open database %s: %w", cfg.Database.Path, err)
+	}
+	c.db = db
+
+	// Задачи синхронизации сохраняются в sync_queue, их выполнит воркер запущенного бота.
+	// Без настроек Google бот воркер не запускает, и задачи не ставятся вовсе.
+	var syncWorker domain.SyncWorker
+	if cfg.Google.GoogleCredentialsFile != "" && cfg.Google.BookingSpreadSheetID != "" {
+		syncWorker = worker.NewSheetsWorker(db, nil, nil, worker.RetryPolicy{}, &c.logger)
+	}
+
+	c.bookings = service.NewBookingService(db, nil, syncWorker, cfg.Bot.MaxBookingDays, cfg.Bot.MinBookingAdvance, &c.logger)
+	c.items = service.NewItemService(db, &c.logger)
+	c.users = service.NewUserService(db, cfg, &c.logger)
+	if err := c.users.LoadRoles(ctx); err != nil {
+		return fmt.Errorf("load roles: %w", err)
+	}
+	if err := c.users.LoadBlacklist(ctx); err != nil {
+		return fmt.Errorf("load blacklist: %w", err)
+	}
+	return nil
+}
+
+func (c *ctl) close() {
+	if c.db != nil {
+		_ = c.db.Close()
+	}
+}
+
+// notice печатает подсказку в stderr, не смешивая ее с результатом команды
+func (c *ctl) notice(format string, args ...any) {
+	fmt.Fprintf(c.errOut, format+"\n", args...)
+}
+
+func (c *ctl) table() *tabwriter.Writer {
+	return tabwriter.NewWriter(c.out, 0, 0, 2, ' ', 0)
+}
+
+func (c *ctl) printJSON(v any) error {
+	enc := json.NewEncoder(c.out)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+// apiHeaders возвращает имена заголовков ключа API из конфига. Без файла конфига
+// (утилита запущена не рядом с сервисом) используются имена по умолчанию.
+func (c *ctl) apiHeaders() (key, extra string, err error) {
+	cfg, err := c.config()
+	if errors.Is(err, os.ErrNotExist) {
+		return config.DefaultHeaderAPIKey, config.DefaultHeaderExtra, nil
+	}
+	if err != nil {
+		return "", "", err
+	}
+	return cfg.API.Auth.HeaderAPIKey, cfg.API.Auth.HeaderExtra, nil
+}
+
+// apiGet выполняет GET к HTTP API и разбирает JSON-ответ в out
+func (c *ctl) apiGet(ctx context.Context, path string, query url.Values, out any) error {
+	u := strings.TrimRight(c.apiURL, "/") + path
+	if len(query) > 0 {
+		u += "?" + query.Encode()
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, http.NoBody)
+	if err != nil {
+		return err
+	}
+	if c.apiKey != "" {
+		keyHeader, extraHeader, err := c.apiHeaders()
+		if err != nil {
+			return err
+		}
+		req.Header.Set(keyHeader, c.apiKey)
+		req.Header.Set(extraHeader, c.apiExtra)
+	}
+
+	resp, err := apiHTTPClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("api request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		var apiErr struct {
+			Error string `json:"error"`
+		}
+		if json.NewDecoder(io.LimitReader(resp.Body, 1<<16)).Decode(&apiErr) == nil && apiErr.Error != "" {
+			return fmt.Errorf("api %s: %s (status %d)", path, apiErr.Error, resp.StatusCode)
+		}
+		return fmt.Errorf("api %s: status %d", path, resp.StatusCode)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("api %s: decode response: %w", path, err)
+	}
+	return nil
+}
+
+var apiHTTPClient = &http.Client{Timeout: 30 * time.Second}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"os"
+
+	"bronivik/internal/importer"
+)
+
+// runImport загружает заявки, аппараты или пользователей из файла CSV или XLSX,
+// как /import в боте и "bot import". Отчет печатается в stdout.
+func runImport(ctx context.Context, c *ctl, args []string) error {
+	fs := c.newFlagSet("import run")
+	kind := fs.String("kind", "", "records to import: bookings, items or users")
+	file := fs.String("file", "", "CSV or XLSX file")
+	mapping := fs.String("map", "", `column mapping, e.g. "date=Дата заявки,item=Аппарат"`)
+	dryRun := fs.Bool("dry-run", false, "validate the file without saving anything")
+	batch := fs.Int("batch", importer.DefaultBatchSize, "records saved in one transaction")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *kind == "" || *file == "" {
+		fs.Usage()
+		return errors.New("-kind and -file are required")
+	}
+
+	k, err := importer.ParseKind(*kind)
+	if err != nil {
+		return err
+	}
+	opts := importer.Options{Kind: k, DryRun: *dryRun, BatchSize: *batch}
+	if *mapping != "" {
+		if opts.Mapping, err = importer.ParseMapping(*mapping); err != nil {
+			return err
+		}
+	}
+
+	f, err := os.Open(*file)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	table, err := importer.ReadTable(*file, f)
+	if err != nil {
+		return err
+	}
+
+	if err := c.open(ctx); err != nil {
+		return err
+	}
+	report, err := importer.Run(ctx, c.db, table, opts)
+	if report != nil {
+		_ = report.WriteText(c.out, 0)
+	}
+	return err
+}
@@ -0,0 +1,150 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"sort"
+
+	"bronivik/internal/config"
+	"bronivik/internal/models"
+
+	"gopkg.in/yaml.v2"
+)
+
+func runItemsList(ctx context.Context, c *ctl, args []string) error {
+	fs := c.newFlagSet("items list")
+	asJSON := fs.Bool("json", false, "print JSON")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	var items []*models.Item
+	if c.apiURL != "" {
+		var resp struct {
+			Items []*models.Item `json:"items"`
+		}
+		if err := c.apiGet(ctx, "/api/v1/items", nil, &resp); err != nil {
+			return err
+		}
+		items = resp.Items
+	} else {
+		if err := c.open(ctx); err != nil {
+			return err
+		}
+		items = c.db.GetItems()
+	}
+	sort.Slice(items, func(i, j int) bool {
+		if items[i].SortOrder == items[j].SortOrder {
+			return items[i].ID < items[j].ID
+		}
+		return items[i].SortOrder < items[j].SortOrder
+	})
+
+	if *asJSON {
+		return c.printJSON(items)
+	}
+	w := c.table()
+	fmt.Fprintln(w, "ID\tNAME\tQTY\tACTIVE\tCATEGORY\tPRICE/DAY")
+	for _, item := range items {
+		fmt.Fprintf(w, "%d\t%s\t%d\t%t\t%s\t%d\n", item.ID, item.Name, item.TotalQuantity, item.IsActive, item.Category, item.PricePerDay)
+	}
+	return w.Flush()
+}
+
+// runItemsSync приводит позиции в базе к items.yaml: новые создаются, существующие (по имени)
+// обновляются. Бот при старте только добавляет новые позиции, поэтому правки описаний,
+// количества и цен в items.yaml попадают в базу этой командой.
+func runItemsSync(ctx context.Context, c *ctl, args []string) error {
+	fs := c.newFlagSet("items sync")
+	file := fs.String("file", c.itemsPath, "items.yaml to sync from")
+	dryRun := fs.Bool("dry-run", false, "only show what would change")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	configItems, err := loadItems(*file)
+	if err != nil {
+		return err
+	}
+	if err := c.open(ctx); err != nil {
+		return err
+	}
+
+	var created, updated, unchanged int
+	for i := range configItems {
+		item := configItems[i]
+		existing, err := c.items.GetItemByName(ctx, item.Name)
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			if !*dryRun {
+				if err := c.items.CreateItem(ctx, &item); err != nil {
+					return fmt.Errorf("create %s: %w", item.Name, err)
+				}
+			}
+			fmt.Fprintf(c.out, "create  %s\n", item.Name)
+			created++
+		case err != nil:
+			return fmt.Errorf("get %s: %w", item.Name, err)
+		case !itemChanged(existing, &item):
+			unchanged++
+		default:
+			if !*dryRun {
+				if err := c.items.UpdateItem(ctx, &item); err != nil {
+					return fmt.Errorf("update %s: %w", item.Name, err)
+				}
+			}
+			fmt.Fprintf(c.out, "update  %s\n", item.Name)
+			updated++
+		}
+	}
+
+	prefix := ""
+	if *dryRun {
+		prefix = "dry run: "
+	}
+	fmt.Fprintf(c.out, "%screated=%d updated=%d unchanged=%d\n", prefix, created, updated, unchanged)
+	return nil
+}
+
+// itemChanged сравнивает позицию из items.yaml с базой и дополняет ее полями, которых нет в
+// файле: идентификатором, датами и фотографиями, загруженными через бота
+func itemChanged(existing, item *models.Item) bool {
+	item.ID = existing.ID
+	item.CreatedAt, item.UpdatedAt = existing.CreatedAt, existing.UpdatedAt
+	if len(item.PhotoFileIDs) == 0 {
+		item.PhotoFileIDs = existing.PhotoFileIDs
+	}
+
+	a, b := *existing, *item
+	for _, it := range []*models.Item{&a, &b} {
+		if len(it.PhotoFileIDs) == 0 {
+			it.PhotoFileIDs = nil
+		}
+		if len(it.Specs) == 0 {
+			it.Specs = nil
+		}
+	}
+	return !reflect.DeepEqual(a, b)
+}
+
+// loadItems читает и проверяет items.yaml так же, как бот при запуске
+func loadItems(path string) ([]models.Item, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var itemsConfig struct {
+		Items []models.Item `yaml:"items"`
+	}
+	if err := yaml.Unmarshal(data, &itemsConfig); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", path, err)
+	}
+	if err := config.ValidateItems(itemsConfig.Items); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return itemsConfig.Items, nil
+}
@@ -0,0 +1,159 @@
+// Command bronivikctl — консольная утилита администратора: синхронизация позиций, заявки,
+// роли, черный список, импорт, ключи API, очередь синхронизации, резервные копии и проверка конфига.
+//
+// Команды работают напрямую с базой SQLite из конфига. С флагом -api команды чтения
+// (items list, bookings list) выполняются через HTTP API работающего сервиса; режим -api
+// только читает данные, остальные команды в нем завершаются ошибкой. Имена заголовков
+// ключа берутся из api.auth конфига, если он доступен.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+
+	"bronivik/internal/models"
+)
+
+// command — подкоманда вида "группа действие"
+type command struct {
+	name  string
+	usage string
+	// remote — команда умеет работать через HTTP API
+	remote bool
+	run    func(ctx context.Context, c *ctl, args []string) error
+}
+
+var commands = []command{
+	{name: "items list", usage: "[-json]", remote: true, run: runItemsList},
+	{name: "items sync", usage: "[-file items.yaml] [-dry-run]", run: runItemsSync},
+	{
+		name:   "bookings list",
+		usage:  "[-id N] [-status s1,s2] [-item 1,2] [-from YYYY-MM-DD] [-to YYYY-MM-DD] [-name text] [-phone digits] [-limit 50] [-offset 0] [-json]",
+		remote: true,
+		run:    runBookingsList,
+	},
+	{name: "bookings set-status", usage: "-id N -status confirmed|canceled|completed|pending [-by telegram_id]", run: runBookingsSetStatus},
+	{name: "managers list", run: runManagersList},
+	{name: "managers set", usage: "-user telegram_id -role admin|manager|viewer [-items 1,2]", run: runManagersSet},
+	{name: "managers remove", usage: "-user telegram_id", run: runManagersRemove},
+	{name: "blacklist list", run: runBlacklistList},
+	{name: "blacklist add", usage: "-user telegram_id [-reason text] [-until YYYY-MM-DD]", run: runBlacklistAdd},
+	{name: "blacklist remove", usage: "-user telegram_id", run: runBlacklistRemove},
+	{
+		name:  "import run",
+		usage: "-kind bookings|items|users -file data.csv|data.xlsx [-map field=Column,...] [-dry-run] [-batch 500]",
+		run:   runImport,
+	},
+	{name: "apikeys list", run: runAPIKeysList},
+	{name: "apikeys add", usage: "-name name [-role admin|manager|viewer] [-permissions read:items,read:bookings]", run: runAPIKeysAdd},
+	{name: "apikeys remove", usage: "-name name", run: runAPIKeysRemove},
+	{name: "sync list", run: runSyncList},
+	{name: "sync replay", usage: "[-id 1,2]", run: runSyncReplay},
+	{name: "backup run", run: runBackupRun},
+	{name: "backup list", run: runBackupList},
+	{name: "backup restore", usage: "-file backup.db -yes", run: runBackupRestore},
+	{name: "config check", run: runConfigCheck},
+}
+
+func main() {
+	if err := run(os.Args[1:], os.Stdout, os.Stderr); err != nil {
+		fmt.Fprintf(os.Stderr, "bronivikctl: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func run(args []string, stdout, stderr io.Writer) error {
+	c := &ctl{out: stdout, errOut: stderr}
+
+	fs := flag.NewFlagSet("bronivikctl", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.StringVar(&c.configPath, "config", envOr("CONFIG_PATH", "configs/config.yaml"), "path to config.yaml")
+	fs.StringVar(&c.itemsPath, "items", envOr("ITEMS_PATH", "configs/items.yaml"), "path to items.yaml")
+	fs.StringVar(&c.apiURL, "api", os.Getenv("BRONIVIK_API_URL"),
+		"HTTP API base URL for read-only commands (items list, bookings list), e.g. http://localhost:8080; empty - use the database")
+	fs.StringVar(&c.apiKey, "api-key", os.Getenv("BRONIVIK_API_KEY"), "API key for -api")
+	fs.StringVar(&c.apiExtra, "api-extra", os.Getenv("BRONIVIK_API_EXTRA"), "API extra secret for -api")
+	fs.Usage = func() { printUsage(fs) }
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return nil
+		}
+		return err
+	}
+
+	rest := fs.Args()
+	if len(rest) < 2 {
+		printUsage(fs)
+		return errors.New("command is required")
+	}
+	name := rest[0] + " " + rest[1]
+	var cmd *command
+	for i := range commands {
+		if commands[i].name == name {
+			cmd = &commands[i]
+			break
+		}
+	}
+	if cmd == nil {
+		printUsage(fs)
+		return fmt.Errorf("unknown command %q", name)
+	}
+	if c.apiURL != "" && !cmd.remote {
+		return fmt.Errorf("%s needs direct database access; run it without -api", name)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	defer c.close()
+	// Изменения из утилиты помечаются в журнале отдельным источником; -by у команд задает автора
+	ctx = models.WithAuditActor(ctx, models.AuditActor{Source: models.AuditSourceCLI})
+
+	return cmd.run(ctx, c, rest[2:])
+}
+
+func printUsage(fs *flag.FlagSet) {
+	w := fs.Output()
+	fmt.Fprintln(w, "Usage: bronivikctl [global flags] <command> [flags]")
+	fmt.Fprintln(w, "\nCommands:")
+	for _, cmd := range commands {
+		line := "  " + cmd.name
+		if cmd.usage != "" {
+			line += " " + cmd.usage
+		}
+		fmt.Fprintln(w, line)
+	}
+	fmt.Fprintln(w, "\nGlobal flags:")
+	fs.PrintDefaults()
+}
+
+// newFlagSet создает набор флагов подкоманды; ошибки разбора печатаются в stderr утилиты
+func (c *ctl) newFlagSet(name string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(c.errOut)
+	return fs
+}
+
+func envOr(key, fallback string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
+	}
+	return fallback
+}
+
+// splitList разбирает список через запятую без пустых элементов
+func splitList(raw string) []string {
+	var out []string
+	for _, part := range strings.Split(raw, ",") {
+		if part = strings.TrimSpace(part); part != "" {
+			out = append(out, part)
+		}
+	}
+	return out
+}
@@ -0,0 +1,271 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"bronivik/internal/database"
+	"bronivik/internal/models"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testConfig = `
+telegram:
+  bot_token: "test_token"
+database:
+  path: "bronivik.db"
+`
+
+const testItems = `
+items:
+  - id: 1
+    name: "Camera"
+    total_quantity: 1
+    is_active: true
+  - id: 2
+    name: "Light"
+    total_quantity: 2
+    is_active: true
+`
+
+// testEnv — рабочий каталог с конфигом, items.yaml и файлами для импорта.
+// config.Load читает .env из текущего каталога, поэтому тест переходит в него.
+func testEnv(t *testing.T) {
+	t.Helper()
+	dir := t.TempDir()
+	t.Chdir(dir)
+	files := map[string]string{
+		".env":         "",
+		"config.yaml":  testConfig,
+		"items.yaml":   testItems,
+		"bookings.csv": "дата,аппарат,клиент,телефон\n10.04.2030,Camera,Анна,79990000002\n11.04.2030,Light,Олег,\n10.04.2030,Camera,Иван,\n",
+	}
+	for name, content := range files {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0o600))
+	}
+}
+
+func runCtl(args ...string) (stdout, stderr string, err error) {
+	var out, errOut bytes.Buffer
+	err = run(args, &out, &errOut)
+	return out.String(), errOut.String(), err
+}
+
+func TestRun_Arguments(t *testing.T) {
+	testEnv(t)
+
+	tests := []struct {
+		name    string
+		args    []string
+		wantErr string
+	}{
+		{name: "Help", args: []string{"-h"}},
+		{name: "NoCommand", args: nil, wantErr: "command is required"},
+		{name: "GroupOnly", args: []string{"items"}, wantErr: "command is required"},
+		{name: "UnknownCommand", args: []string{"items", "drop"}, wantErr: `unknown command "items drop"`},
+		{name: "UnknownGlobalFlag", args: []string{"-verbose", "items", "list"}, wantErr: "flag provided but not defined"},
+		{name: "UnknownCommandFlag", args: []string{"items", "list", "-csv"}, wantErr: "flag provided but not defined"},
+		{
+			name:    "APIWithLocalCommand",
+			args:    []string{"-api", "http://localhost:1", "managers", "list"},
+			wantErr: "managers list needs direct database access",
+		},
+		{name: "SetStatusRequiresID", args: []string{"bookings", "set-status", "-status", "confirmed"}, wantErr: "-id and -status are required"},
+		{name: "ManagersSetRequiresRole", args: []string{"managers", "set", "-user", "1"}, wantErr: "-user and -role are required"},
+		{
+			name:    "ManagersSetBadItems",
+			args:    []string{"managers", "set", "-user", "1", "-role", "manager", "-items", "1,x"},
+			wantErr: `invalid item id "x"`,
+		},
+		{
+			name:    "BlacklistAddPastUntil",
+			args:    []string{"blacklist", "add", "-user", "1", "-until", "2020-01-01"},
+			wantErr: "-until must be in the future",
+		},
+		{name: "ImportRequiresFile", args: []string{"import", "run", "-kind", "bookings"}, wantErr: "-kind and -file are required"},
+		{name: "ImportUnknownKind", args: []string{"import", "run", "-kind", "orders", "-file", "bookings.csv"}, wantErr: "unknown import kind"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, err := runCtl(tt.args...)
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+
+	_, err := os.Stat("bronivik.db")
+	assert.True(t, os.IsNotExist(err), "invalid arguments must be rejected before the database is opened")
+}
+
+// TestRun_Commands выполняет команды по очереди над одной временной базой
+func TestRun_Commands(t *testing.T) {
+	testEnv(t)
+	global := []string{"-config", "config.yaml", "-items", "items.yaml"}
+
+	steps := []struct {
+		name       string
+		args       []string
+		wantOut    []string // регулярные выражения для stdout
+		wantNotice bool
+		wantErr    string
+	}{
+		{
+			name:    "ItemsSyncDryRun",
+			args:    []string{"items", "sync", "-dry-run"},
+			wantOut: []string{`(?m)^create  Camera$`, `dry run: created=2 updated=0`},
+		},
+		{name: "ItemsSync", args: []string{"items", "sync"}, wantOut: []string{`created=2 updated=0 unchanged=0`}},
+		{name: "ItemsSyncAgain", args: []string{"items", "sync"}, wantOut: []string{`created=0 updated=0 unchanged=2`}},
+		{name: "ItemsList", args: []string{"items", "list"}, wantOut: []string{`(?m)^1\s+Camera\s+1\s+true`, `(?m)^2\s+Light\s+2\s+true`}},
+		{name: "BookingsListEmpty", args: []string{"bookings", "list", "-json"}, wantOut: []string{`^\[\]\n$`}},
+		{
+			name:    "ImportDryRun",
+			args:    []string{"import", "run", "-kind", "bookings", "-file", "bookings.csv", "-dry-run"},
+			wantOut: []string{`bookings dry run: 3 rows, 2 imported, 0 duplicates, 1 errors`, `row 4: not available`},
+		},
+		{name: "BookingsListAfterDryRun", args: []string{"bookings", "list", "-json"}, wantOut: []string{`^\[\]\n$`}},
+		{
+			name:    "Import",
+			args:    []string{"import", "run", "-kind", "bookings", "-file", "bookings.csv"},
+			wantOut: []string{`bookings import: 3 rows, 2 imported`},
+		},
+		{
+			name:    "BookingsList",
+			args:    []string{"bookings", "list"},
+			wantOut: []string{`(?m)^1\s+2030-04-10\s+Camera\s+Анна\s+79990000002\s+confirmed`, `(?m)^2\s+2030-04-11\s+Light\s+Олег`},
+		},
+		{name: "BookingsListFiltered", args: []string{"bookings", "list", "-item", "2"}, wantOut: []string{`(?m)^2\s+2030-04-11`}},
+		{name: "BookingsListBadStatus", args: []string{"bookings", "list", "-status", "lost"}, wantErr: "status"},
+		{
+			name:    "SetStatus",
+			args:    []string{"bookings", "set-status", "-id", "1", "-status", "canceled"},
+			wantOut: []string{`booking #1: confirmed -> canceled`},
+		},
+		{
+			name:    "SetStatusAgain",
+			args:    []string{"bookings", "set-status", "-id", "1", "-status", "canceled"},
+			wantOut: []string{`booking #1 is already canceled`},
+		},
+		{
+			name:    "SetStatusUnsupported",
+			args:    []string{"bookings", "set-status", "-id", "1", "-status", "lost"},
+			wantErr: `unsupported status "lost"`,
+		},
+		{name: "ManagersSetUnknownItem", args: []string{"managers", "set", "-user", "123", "-role", "manager", "-items", "9"}, wantErr: "item 9"},
+		{
+			name:       "ManagersSet",
+			args:       []string{"managers", "set", "-user", "123", "-role", "manager", "-items", "1"},
+			wantOut:    []string{`123 is now manager`},
+			wantNotice: true,
+		},
+		{name: "ManagersList", args: []string{"managers", "list"}, wantOut: []string{`(?m)^123\s+manager\s+1\s+database$`}},
+		{
+			name:       "ManagersRemove",
+			args:       []string{"managers", "remove", "-user", "123"},
+			wantOut:    []string{`role of 123 removed`},
+			wantNotice: true,
+		},
+		{
+			name:       "BlacklistAdd",
+			args:       []string{"blacklist", "add", "-user", "456", "-reason", "не вернул", "-until", "2099-12-31"},
+			wantOut:    []string{`456 blacklisted`},
+			wantNotice: true,
+		},
+		{name: "BlacklistList", args: []string{"blacklist", "list"}, wantOut: []string{`(?m)^456\s+2099-12-31\s+не вернул\s+database$`}},
+		{
+			name:       "BlacklistRemove",
+			args:       []string{"blacklist", "remove", "-user", "456"},
+			wantOut:    []string{`456 removed from blacklist`},
+			wantNotice: true,
+		},
+	}
+
+	for _, step := range steps {
+		t.Run(step.name, func(t *testing.T) {
+			out, errOut, err := runCtl(append(global, step.args...)...)
+			if step.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), step.wantErr)
+				return
+			}
+			require.NoError(t, err, errOut)
+			for _, re := range step.wantOut {
+				assert.Regexp(t, re, out)
+			}
+			if step.wantNotice {
+				assert.Contains(t, errOut, reloadNotice)
+			}
+		})
+	}
+
+	t.Run("BlacklistListEmpty", func(t *testing.T) {
+		out, _, err := runCtl(append(global, "blacklist", "list")...)
+		require.NoError(t, err)
+		assert.NotContains(t, out, "456")
+	})
+
+	t.Run("AuditSource", func(t *testing.T) {
+		logger := zerolog.Nop()
+		db, err := database.NewDB("bronivik.db", &logger)
+		require.NoError(t, err)
+		defer db.Close()
+
+		entries, err := db.GetAuditEntries(context.Background(), models.AuditEntityBooking, 1)
+		require.NoError(t, err)
+		require.NotEmpty(t, entries)
+		for _, e := range entries {
+			if e.Action == models.AuditActionStatusChange {
+				assert.Equal(t, models.AuditSourceCLI, e.Source)
+				return
+			}
+		}
+		t.Fatal("status change is not in the audit log")
+	})
+}
+
+func TestRun_API(t *testing.T) {
+	testEnv(t)
+	require.NoError(t, os.Remove("config.yaml"))
+
+	var gotKey, gotExtra string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotKey, gotExtra = r.Header.Get("x-api-key"), r.Header.Get("x-api-extra")
+		switch r.URL.Path {
+		case "/api/v1/items":
+			_ = json.NewEncoder(w).Encode(map[string]any{"items": []*models.Item{{ID: 7, Name: "Remote", TotalQuantity: 3, IsActive: true}}})
+		case "/api/v1/bookings":
+			assert.Equal(t, "pending", r.URL.Query().Get("status"))
+			w.WriteHeader(http.StatusForbidden)
+			_ = json.NewEncoder(w).Encode(map[string]string{"error": "forbidden"})
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer ts.Close()
+
+	global := []string{"-api", ts.URL + "/", "-api-key", "key", "-api-extra", "extra"}
+
+	out, _, err := runCtl(append(global, "items", "list")...)
+	require.NoError(t, err)
+	assert.Regexp(t, `(?m)^7\s+Remote\s+3\s+true`, out)
+	assert.Equal(t, "key", gotKey, "without config.yaml the default header names are used")
+	assert.Equal(t, "extra", gotExtra)
+
+	_, _, err = runCtl(append(global, "bookings", "list", "-status", "pending")...)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "forbidden (status 403)")
+}
@@ -0,0 +1,241 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"bronivik/internal/config"
+	"bronivik/internal/database"
+)
+
+// apiRestartNotice — API читает ключи из конфига при старте
+const apiRestartNotice = "note: restart the bot and API services to apply the new keys"
+
+func runAPIKeysList(_ context.Context, c *ctl, args []string) error {
+	if err := c.newFlagSet("apikeys list").Parse(args); err != nil {
+		return err
+	}
+	cfg, err := c.config()
+	if err != nil {
+		return err
+	}
+
+	w := c.table()
+	fmt.Fprintln(w, "NAME\tKEY\tROLE\tPERMISSIONS")
+	for _, k := range cfg.API.Auth.APIKeys {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", k.Name, maskSecret(k.Key), k.Role, strings.Join(k.Permissions, ","))
+	}
+	return w.Flush()
+}
+
+// runAPIKeysAdd создает ключ и дописывает его в config.yaml. Ключ печатается один раз:
+// в списке ключей он виден только замаскированным.
+func runAPIKeysAdd(_ context.Context, c *ctl, args []string) error {
+	fs := c.newFlagSet("apikeys add")
+	name := fs.String("name", "", "client name")
+	role := fs.String("role", "", "admin, manager or viewer")
+	perms := fs.String("permissions", "", "permissions, comma-separated, e.g. read:items,read:bookings")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	key, err := config.GenerateAPIKey(*name, *role, splitList(*perms))
+	if err != nil {
+		return err
+	}
+	if err := config.AddAPIKey(c.configPath, key); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(c.out, "added api key %q to %s\nx-api-key:   %s\nx-api-extra: %s\n", key.Name, c.configPath, key.Key, key.Extra)
+	if key.Role == "" && len(key.Permissions) == 0 {
+		c.notice("note: a key without role and permissions may only read availability and items")
+	}
+	c.notice(apiRestartNotice)
+	return nil
+}
+
+func runAPIKeysRemove(_ context.Context, c *ctl, args []string) error {
+	fs := c.newFlagSet("apikeys remove")
+	name := fs.String("name", "", "client name")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *name == "" {
+		fs.Usage()
+		return errors.New("-name is required")
+	}
+
+	if err := config.RemoveAPIKey(c.configPath, *name); err != nil {
+		return err
+	}
+	fmt.Fprintf(c.out, "removed api key %q from %s\n", *name, c.configPath)
+	c.notice(apiRestartNotice)
+	return nil
+}
+
+func maskSecret(s string) string {
+	if len(s) <= 8 {
+		return strings.Repeat("*", len(s))
+	}
+	return s[:4] + "…" + s[len(s)-4:]
+}
+
+func runSyncList(ctx context.Context, c *ctl, args []string) error {
+	if err := c.newFlagSet("sync list").Parse(args); err != nil {
+		return err
+	}
+	if err := c.open(ctx); err != nil {
+		return err
+	}
+	tasks, err := c.db.GetFailedSyncTasks(ctx)
+	if err != nil {
+		return err
+	}
+
+	w := c.table()
+	fmt.Fprintln(w, "ID\tTYPE\tBOOKING\tRETRIES\tCREATED\tERROR")
+	for _, t := range tasks {
+		lastErr := ""
+		if t.LastError != nil {
+			lastErr = *t.LastError
+		}
+		fmt.Fprintf(w, "%d\t%s\t%d\t%d\t%s\t%s\n",
+			t.ID, t.TaskType, t.BookingID, t.RetryCount, t.CreatedAt.Format("2006-01-02 15:04"), lastErr)
+	}
+	return w.Flush()
+}
+
+// runSyncReplay возвращает упавшие задачи синхронизации с Google Sheets в очередь.
+// Их подхватит воркер запущенного бота при очередном опросе sync_queue.
+func runSyncReplay(ctx context.Context, c *ctl, args []string) error {
+	fs := c.newFlagSet("sync replay")
+	idsStr := fs.String("id", "", "task ids, comma-separated; empty - all failed tasks")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	var ids []int64
+	for _, s := range splitList(*idsStr) {
+		id, err := strconv.ParseInt(s, 10, 64)
+		if err != nil || id <= 0 {
+			return fmt.Errorf("invalid task id %q", s)
+		}
+		ids = append(ids, id)
+	}
+
+	if err := c.open(ctx); err != nil {
+		return err
+	}
+	n, err := c.db.RequeueFailedSyncTasks(ctx, ids)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(c.out, "requeued %d task(s)\n", n)
+	return nil
+}
+
+// backupService создает сервис резервных копий из конфига, не открывая базу
+func (c *ctl) backupService() (*database.BackupService, error) {
+	cfg, err := c.config()
+	if err != nil {
+		return nil, err
+	}
+	if cfg.Backup.StoragePath == "" {
+		return nil, errors.New("backup.storage_path is not set in config")
+	}
+	return database.NewBackupService(cfg.Database.Path, cfg.Backup, &c.logger), nil
+}
+
+func runBackupRun(_ context.Context, c *ctl, args []string) error {
+	if err := c.newFlagSet("backup run").Parse(args); err != nil {
+		return err
+	}
+	s, err := c.backupService()
+	if err != nil {
+		return err
+	}
+	if err := s.PerformBackup(); err != nil {
+		return err
+	}
+	s.CleanupOldBackups()
+
+	backups, err := s.ListBackups()
+	if err == nil && len(backups) > 0 {
+		fmt.Fprintf(c.out, "backup created: %s\n", filepath.Join(c.cfg.Backup.StoragePath, backups[0].Name()))
+	}
+	return err
+}
+
+func runBackupList(_ context.Context, c *ctl, args []string) error {
+	if err := c.newFlagSet("backup list").Parse(args); err != nil {
+		return err
+	}
+	s, err := c.backupService()
+	if err != nil {
+		return err
+	}
+	backups, err := s.ListBackups()
+	if err != nil {
+		return err
+	}
+
+	w := c.table()
+	fmt.Fprintln(w, "FILE\tSIZE\tCREATED")
+	for _, b := range backups {
+		fmt.Fprintf(w, "%s\t%d\t%s\n", filepath.Join(c.cfg.Backup.StoragePath, b.Name()), b.Size(), b.ModTime().Format("2006-01-02 15:04:05"))
+	}
+	return w.Flush()
+}
+
+// runBackupRestore заменяет базу резервной копией. Бот и API на это время нужно остановить:
+// открытое соединение продолжит писать в старый файл.
+func runBackupRestore(_ context.Context, c *ctl, args []string) error {
+	fs := c.newFlagSet("backup restore")
+	file := fs.String("file", "", "backup file to restore")
+	yes := fs.Bool("yes", false, "confirm that the bot and API are stopped and the database may be replaced")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *file == "" {
+		fs.Usage()
+		return errors.New("-file is required")
+	}
+	if !*yes {
+		return errors.New("restore replaces the database: stop the bot and API, then rerun with -yes")
+	}
+
+	s, err := c.backupService()
+	if err != nil {
+		return err
+	}
+	if err := s.RestoreBackup(*file); err != nil {
+		return err
+	}
+	fmt.Fprintf(c.out, "database %s restored from %s\n", c.cfg.Database.Path, *file)
+	return nil
+}
+
+// runConfigCheck загружает конфиг и items.yaml с теми же проверками, что и при запуске бота
+func runConfigCheck(_ context.Context, c *ctl, args []string) error {
+	if err := c.newFlagSet("config check").Parse(args); err != nil {
+		return err
+	}
+	cfg, err := c.config()
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(c.out, "%s: ok (database %s, %d managers, %d api keys)\n",
+		c.configPath, cfg.Database.Path, len(cfg.Managers), len(cfg.API.Auth.APIKeys))
+
+	items, err := loadItems(c.itemsPath)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(c.out, "%s: ok (%d items)\n", c.itemsPath, len(items))
+	return nil
+}
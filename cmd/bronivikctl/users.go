@@ -0,0 +1,193 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"bronivik/internal/models"
+)
+
+// reloadNotice — бот перечитывает роли и черный список раз в минуту и по SIGHUP
+const reloadNotice = "note: the running bot applies this change within a minute (send SIGHUP to apply it now)"
+
+func runManagersList(ctx context.Context, c *ctl, args []string) error {
+	if err := c.newFlagSet("managers list").Parse(args); err != nil {
+		return err
+	}
+	if err := c.open(ctx); err != nil {
+		return err
+	}
+	roles, err := c.users.ListUserRoles(ctx)
+	if err != nil {
+		return err
+	}
+
+	w := c.table()
+	fmt.Fprintln(w, "TELEGRAM_ID\tROLE\tITEMS\tSOURCE")
+	for _, id := range c.cfg.Managers {
+		fmt.Fprintf(w, "%d\t%s\t%s\t%s\n", id, models.RoleAdmin, "all", "config")
+	}
+	for _, r := range roles {
+		items := "all"
+		if len(r.ItemIDs) > 0 {
+			ids := make([]string, 0, len(r.ItemIDs))
+			for _, id := range r.ItemIDs {
+				ids = append(ids, strconv.FormatInt(id, 10))
+			}
+			items = strings.Join(ids, ",")
+		}
+		fmt.Fprintf(w, "%d\t%s\t%s\t%s\n", r.TelegramID, r.Role, items, "database")
+	}
+	return w.Flush()
+}
+
+func runManagersSet(ctx context.Context, c *ctl, args []string) error {
+	fs := c.newFlagSet("managers set")
+	user := fs.Int64("user", 0, "telegram id")
+	role := fs.String("role", "", "admin, manager or viewer")
+	items := fs.String("items", "", "limit the role to these item ids, comma-separated")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *user == 0 || *role == "" {
+		fs.Usage()
+		return errors.New("-user and -role are required")
+	}
+
+	userRole := &models.UserRole{TelegramID: *user, Role: *role}
+	for _, s := range splitList(*items) {
+		id, err := strconv.ParseInt(s, 10, 64)
+		if err != nil || id <= 0 {
+			return fmt.Errorf("invalid item id %q", s)
+		}
+		userRole.ItemIDs = append(userRole.ItemIDs, id)
+	}
+
+	if err := c.open(ctx); err != nil {
+		return err
+	}
+	for _, id := range userRole.ItemIDs {
+		if _, err := c.items.GetItemByID(ctx, id); err != nil {
+			return fmt.Errorf("item %d: %w", id, err)
+		}
+	}
+	if err := c.users.SetUserRole(ctx, userRole); err != nil {
+		return err
+	}
+	fmt.Fprintf(c.out, "%d is now %s\n", *user, *role)
+	c.notice(reloadNotice)
+	return nil
+}
+
+func runManagersRemove(ctx context.Context, c *ctl, args []string) error {
+	fs := c.newFlagSet("managers remove")
+	user := fs.Int64("user", 0, "telegram id")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *user == 0 {
+		fs.Usage()
+		return errors.New("-user is required")
+	}
+
+	if err := c.open(ctx); err != nil {
+		return err
+	}
+	if err := c.users.RemoveUserRole(ctx, *user); err != nil {
+		return err
+	}
+	fmt.Fprintf(c.out, "role of %d removed\n", *user)
+	c.notice(reloadNotice)
+	return nil
+}
+
+func runBlacklistList(ctx context.Context, c *ctl, args []string) error {
+	if err := c.newFlagSet("blacklist list").Parse(args); err != nil {
+		return err
+	}
+	if err := c.open(ctx); err != nil {
+		return err
+	}
+	users, err := c.users.ListBlacklistedUsers(ctx)
+	if err != nil {
+		return err
+	}
+
+	w := c.table()
+	fmt.Fprintln(w, "TELEGRAM_ID\tUSERNAME\tUNTIL\tREASON\tSOURCE")
+	for _, id := range c.cfg.Blacklist {
+		fmt.Fprintf(w, "%d\t\t%s\t\t%s\n", id, "forever", "config")
+	}
+	for _, u := range users {
+		until := "forever"
+		if u.BlacklistedUntil.Valid {
+			until = u.BlacklistedUntil.Time.Add(-time.Second).Format("2006-01-02")
+		}
+		fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%s\n", u.TelegramID, u.Username, until, u.BlacklistReason, "database")
+	}
+	return w.Flush()
+}
+
+func runBlacklistAdd(ctx context.Context, c *ctl, args []string) error {
+	fs := c.newFlagSet("blacklist add")
+	user := fs.Int64("user", 0, "telegram id")
+	reason := fs.String("reason", "", "reason shown to managers")
+	untilStr := fs.String("until", "", "last day of the block, YYYY-MM-DD; empty - forever")
+	by := fs.Int64("by", 0, "telegram id recorded as the author of the block")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *user == 0 {
+		fs.Usage()
+		return errors.New("-user is required")
+	}
+
+	var until time.Time
+	if *untilStr != "" {
+		day, err := time.ParseInLocation("2006-01-02", *untilStr, time.Local)
+		if err != nil {
+			return fmt.Errorf("invalid -until: %w", err)
+		}
+		// Как и в боте, блокировка действует до конца указанного дня
+		until = day.AddDate(0, 0, 1)
+		if !until.After(time.Now()) {
+			return errors.New("-until must be in the future")
+		}
+	}
+
+	if err := c.open(ctx); err != nil {
+		return err
+	}
+	if err := c.users.BlockUser(ctx, *user, *reason, until, *by); err != nil {
+		return err
+	}
+	fmt.Fprintf(c.out, "%d blacklisted\n", *user)
+	c.notice(reloadNotice)
+	return nil
+}
+
+func runBlacklistRemove(ctx context.Context, c *ctl, args []string) error {
+	fs := c.newFlagSet("blacklist remove")
+	user := fs.Int64("user", 0, "telegram id")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *user == 0 {
+		fs.Usage()
+		return errors.New("-user is required")
+	}
+
+	if err := c.open(ctx); err != nil {
+		return err
+	}
+	if err := c.users.UnblockUser(ctx, *user); err != nil {
+		return err
+	}
+	fmt.Fprintf(c.out, "%d removed from blacklist\n", *user)
+	c.notice(reloadNotice)
+	return nil
+}
@@ -0,0 +1,340 @@
+// Package analytics computes booking statistics over a period: utilization of
+// items, cancellation and no-show rates, lead times, repeat clients and busy
+// weekdays. Reports are shown by /stats, served by the HTTP API and exported to XLSX.
+package analytics
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"bronivik/internal/models"
+)
+
+const (
+	// MaxPeriodDays limits the length of a report period.
+	MaxPeriodDays = 366
+
+	// historyDays is how far before the period bookings are loaded to recognize repeat clients.
+	historyDays = 365
+)
+
+// ErrInvalidPeriod is returned for periods that end before they start or are too long.
+var ErrInvalidPeriod = errors.New("invalid analytics period")
+
+// Source loads the data of a report. It is implemented by *database.DB.
+type Source interface {
+	GetActiveItems(ctx context.Context) ([]*models.Item, error)
+	GetBookingsByDateRange(ctx context.Context, start, end time.Time) ([]*models.Booking, error)
+	GetMaintenanceWindows(ctx context.Context, itemID int64, start, end time.Time) ([]*models.MaintenanceWindow, error)
+	GetHandoversByPeriod(ctx context.Context, start, end time.Time) ([]*models.Handover, error)
+}
+
+// Input is everything Compute needs. Bookings may include bookings before Start:
+// they are only used to recognize repeat clients.
+type Input struct {
+	Start       time.Time
+	End         time.Time
+	Now         time.Time
+	Items       []*models.Item
+	Bookings    []*models.Booking
+	Maintenance []*models.MaintenanceWindow
+	Handovers   []*models.Handover
+}
+
+// ItemUtilization is the share of available unit-days an item was booked.
+type ItemUtilization struct {
+	ItemID      int64   `json:"item_id"`
+	ItemName    string  `json:"item_name"`
+	BookedDays  int64   `json:"booked_days"`
+	UnitDays    int64   `json:"unit_days"` // units in service summed over the days of the period
+	Utilization float64 `json:"utilization"`
+}
+
+// LeadTimeBucket counts bookings made MinDays to MaxDays days in advance. MaxDays -1 means no limit.
+type LeadTimeBucket struct {
+	Label   string `json:"label"`
+	MinDays int    `json:"min_days"`
+	MaxDays int    `json:"max_days"`
+	Count   int    `json:"count"`
+}
+
+// Report is the result of Compute. Rates are fractions between 0 and 1.
+type Report struct {
+	Start time.Time `json:"start"`
+	End   time.Time `json:"end"`
+
+	Bookings         int     `json:"bookings"`
+	Canceled         int     `json:"canceled"`
+	CancellationRate float64 `json:"cancellation_rate"`
+
+	// No-shows are past confirmed bookings that were neither completed nor handed over.
+	// They are only counted when handovers were recorded in the period.
+	NoShowTracked bool    `json:"no_show_tracked"`
+	NoShows       int     `json:"no_shows"`
+	NoShowBase    int     `json:"no_show_base"`
+	NoShowRate    float64 `json:"no_show_rate"`
+
+	Clients       int     `json:"clients"`
+	RepeatClients int     `json:"repeat_clients"`
+	RepeatShare   float64 `json:"repeat_share"`
+
+	Utilization     float64           `json:"utilization"`
+	Items           []ItemUtilization `json:"items"`
+	LeadTime        []LeadTimeBucket  `json:"lead_time"`
+	MedianLeadDays  float64           `json:"median_lead_days"`
+	Weekdays        [7]int            `json:"weekdays"` // bookings per weekday, Monday first
+	BusiestWeekday  time.Weekday      `json:"busiest_weekday"`
+	BusiestBookings int               `json:"busiest_bookings"`
+}
+
+// Period returns the period of days days ending today.
+func Period(now time.Time, days int) (start, end time.Time) {
+	end = day(now)
+	return end.AddDate(0, 0, -(days - 1)), end
+}
+
+// ValidatePeriod checks that the inclusive period is not empty and not longer than MaxPeriodDays.
+func ValidatePeriod(start, end time.Time) error {
+	start, end = day(start), day(end)
+	if end.Before(start) || end.Sub(start) >= MaxPeriodDays*24*time.Hour {
+		return ErrInvalidPeriod
+	}
+	return nil
+}
+
+// Load reads the data of the period from src and computes its report.
+func Load(ctx context.Context, src Source, start, end, now time.Time) (*Report, error) {
+	if err := ValidatePeriod(start, end); err != nil {
+		return nil, err
+	}
+	start, end = day(start), day(end)
+
+	items, err := src.GetActiveItems(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load items: %w", err)
+	}
+	bookings, err := src.GetBookingsByDateRange(ctx, start.AddDate(0, 0, -historyDays), end)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load bookings: %w", err)
+	}
+	maintenance, err := src.GetMaintenanceWindows(ctx, 0, start, end)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load maintenance: %w", err)
+	}
+	handovers, err := src.GetHandoversByPeriod(ctx, start, end)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load handovers: %w", err)
+	}
+
+	return Compute(&Input{
+		Start: start, End: end, Now: now,
+		Items: items, Bookings: bookings, Maintenance: maintenance, Handovers: handovers,
+	}), nil
+}
+
+// Compute builds the report of the input period.
+func Compute(in *Input) *Report {
+	start, end, today := day(in.Start), day(in.End), day(in.Now)
+	r := &Report{Start: start, End: end, LeadTime: newLeadTimeBuckets()}
+
+	var (
+		period  []*models.Booking
+		earlier = make(map[string]bool)
+	)
+	for _, b := range in.Bookings {
+		d := day(b.Date)
+		switch {
+		case d.Before(start):
+			if b.Status != models.StatusCanceled {
+				earlier[clientKey(b)] = true
+			}
+		case !d.After(end):
+			period = append(period, b)
+		}
+	}
+
+	r.Bookings = len(period)
+	booked := make(map[int64]int64)
+	var leadDays []int
+	for _, b := range period {
+		if b.Status == models.StatusCanceled {
+			r.Canceled++
+			continue
+		}
+		if isBooked(b.Status) {
+			booked[b.ItemID]++
+		}
+
+		lead := int(day(b.Date).Sub(day(b.CreatedAt)).Hours() / 24)
+		if b.CreatedAt.IsZero() || lead < 0 {
+			lead = 0
+		}
+		leadDays = append(leadDays, lead)
+		for i := range r.LeadTime {
+			if lead >= r.LeadTime[i].MinDays && (r.LeadTime[i].MaxDays < 0 || lead <= r.LeadTime[i].MaxDays) {
+				r.LeadTime[i].Count++
+				break
+			}
+		}
+
+		r.Weekdays[(int(day(b.Date).Weekday())+6)%7]++
+	}
+	r.CancellationRate = rate(r.Canceled, r.Bookings)
+	r.MedianLeadDays = median(leadDays)
+	for i, n := range r.Weekdays {
+		if n > r.BusiestBookings {
+			r.BusiestBookings = n
+			r.BusiestWeekday = time.Weekday((i + 1) % 7)
+		}
+	}
+
+	r.countNoShows(period, in.Handovers, today)
+	r.countClients(period, earlier)
+	r.computeUtilization(in.Items, in.Maintenance, booked, start, end)
+	return r
+}
+
+// isBooked reports whether a booking occupies a unit for utilization.
+func isBooked(status string) bool {
+	return status == models.StatusConfirmed || status == models.StatusCompleted
+}
+
+func (r *Report) countNoShows(period []*models.Booking, handovers []*models.Handover, today time.Time) {
+	r.NoShowTracked = len(handovers) > 0
+	if !r.NoShowTracked {
+		return
+	}
+	for _, b := range period {
+		if !isBooked(b.Status) || !day(b.Date).Before(today) {
+			continue
+		}
+		r.NoShowBase++
+		if b.Status == models.StatusConfirmed && !handedOver(b, handovers) {
+			r.NoShows++
+		}
+	}
+	r.NoShowRate = rate(r.NoShows, r.NoShowBase)
+}
+
+func handedOver(b *models.Booking, handovers []*models.Handover) bool {
+	d := day(b.Date)
+	for _, h := range handovers {
+		if h.UserID == b.UserID && h.ItemID == b.ItemID && !d.Before(day(h.PlannedStart)) && !d.After(day(h.PlannedEnd)) {
+			return true
+		}
+	}
+	return false
+}
+
+// countClients counts clients of the period. A client is a repeat client if they booked
+// before the period or had more than one rental in it; a rental is a run of consecutive
+// days of one item.
+func (r *Report) countClients(period []*models.Booking, earlier map[string]bool) {
+	days := make(map[string]map[int64][]time.Time)
+	for _, b := range period {
+		if b.Status == models.StatusCanceled {
+			continue
+		}
+		key := clientKey(b)
+		if days[key] == nil {
+			days[key] = make(map[int64][]time.Time)
+		}
+		days[key][b.ItemID] = append(days[key][b.ItemID], day(b.Date))
+	}
+
+	r.Clients = len(days)
+	for key, byItem := range days {
+		rentals := 0
+		for _, dates := range byItem {
+			rentals += countRuns(dates)
+		}
+		if earlier[key] || rentals > 1 {
+			r.RepeatClients++
+		}
+	}
+	r.RepeatShare = rate(r.RepeatClients, r.Clients)
+}
+
+// clientKey identifies a client by phone, so bookings made by a manager on a client's
+// behalf count for the client; without a phone the Telegram ID is used.
+func clientKey(b *models.Booking) string {
+	if b.Phone != "" {
+		return "phone:" + b.Phone
+	}
+	return fmt.Sprintf("user:%d", b.UserID)
+}
+
+func countRuns(dates []time.Time) int {
+	sort.Slice(dates, func(i, j int) bool { return dates[i].Before(dates[j]) })
+	runs := 0
+	for i, d := range dates {
+		if i == 0 || d.Sub(dates[i-1]) > 24*time.Hour {
+			runs++
+		}
+	}
+	return runs
+}
+
+func (r *Report) computeUtilization(
+	items []*models.Item,
+	maintenance []*models.MaintenanceWindow,
+	booked map[int64]int64,
+	start, end time.Time,
+) {
+	var totalBooked, totalUnitDays int64
+	for _, item := range items {
+		u := ItemUtilization{ItemID: item.ID, ItemName: item.Name, BookedDays: booked[item.ID]}
+		for d := start; !d.After(end); d = d.AddDate(0, 0, 1) {
+			u.UnitDays += item.EffectiveQuantity(models.MaintenanceUnits(maintenance, item.ID, d))
+		}
+		u.Utilization = rate64(u.BookedDays, u.UnitDays)
+		totalBooked += u.BookedDays
+		totalUnitDays += u.UnitDays
+		r.Items = append(r.Items, u)
+	}
+	sort.SliceStable(r.Items, func(i, j int) bool { return r.Items[i].Utilization > r.Items[j].Utilization })
+	r.Utilization = rate64(totalBooked, totalUnitDays)
+}
+
+func newLeadTimeBuckets() []LeadTimeBucket {
+	return []LeadTimeBucket{
+		{Label: "0", MinDays: 0, MaxDays: 0},
+		{Label: "1", MinDays: 1, MaxDays: 1},
+		{Label: "2-3", MinDays: 2, MaxDays: 3},
+		{Label: "4-7", MinDays: 4, MaxDays: 7},
+		{Label: "8-14", MinDays: 8, MaxDays: 14},
+		{Label: "15-30", MinDays: 15, MaxDays: 30},
+		{Label: "31+", MinDays: 31, MaxDays: -1},
+	}
+}
+
+func median(values []int) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	sort.Ints(values)
+	mid := len(values) / 2
+	if len(values)%2 == 1 {
+		return float64(values[mid])
+	}
+	return float64(values[mid-1]+values[mid]) / 2
+}
+
+func rate(n, total int) float64 {
+	return rate64(int64(n), int64(total))
+}
+
+func rate64(n, total int64) float64 {
+	if total == 0 {
+		return 0
+	}
+	return float64(n) / float64(total)
+}
+
+// day returns the calendar day of t as midnight UTC.
+func day(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
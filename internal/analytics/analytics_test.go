@@ -0,0 +1,168 @@
+package analytics
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"database/sql"
+	"strings"
+	"testing"
+	"time"
+
+	"bronivik/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xuri/excelize/v2"
+)
+
+// 2030-06-03 is a Monday.
+func testDay(d int) time.Time { return time.Date(2030, 6, d, 0, 0, 0, 0, time.UTC) }
+
+func testInput() *Input {
+	booking := func(id, userID, itemID int64, d int, status string, leadDays int) *models.Booking {
+		return &models.Booking{
+			ID: id, UserID: userID, ItemID: itemID, Date: testDay(d), Status: status,
+			CreatedAt: testDay(d).AddDate(0, 0, -leadDays).Add(15 * time.Hour),
+		}
+	}
+	return &Input{
+		Start: testDay(3),
+		End:   testDay(9),
+		Now:   testDay(8).Add(10 * time.Hour),
+		Items: []*models.Item{
+			{ID: 1, Name: "Camera", TotalQuantity: 2},
+			{ID: 2, Name: "Laser", TotalQuantity: 1},
+		},
+		Bookings: []*models.Booking{
+			// Бронь до периода делает клиента 3 постоянным
+			booking(1, 3, 2, 1, models.StatusCompleted, 5),
+			booking(2, 1, 1, 3, models.StatusConfirmed, 0),
+			booking(3, 1, 1, 4, models.StatusConfirmed, 1),
+			booking(4, 2, 1, 3, models.StatusConfirmed, 10),
+			booking(5, 2, 2, 5, models.StatusCanceled, 2),
+			booking(6, 3, 2, 6, models.StatusCompleted, 40),
+			booking(7, 4, 1, 9, models.StatusPending, 3),
+			booking(8, 1, 1, 7, models.StatusConfirmed, 2),
+		},
+		Maintenance: []*models.MaintenanceWindow{
+			{ItemID: 1, Units: 1, StartDate: testDay(8), EndDate: testDay(9)},
+		},
+		Handovers: []*models.Handover{
+			{UserID: 1, ItemID: 1, PlannedStart: testDay(3), PlannedEnd: testDay(4), CheckedInAt: sql.NullTime{Valid: true}},
+		},
+	}
+}
+
+func TestCompute(t *testing.T) {
+	r := Compute(testInput())
+
+	assert.Equal(t, testDay(3), r.Start)
+	assert.Equal(t, 7, r.Bookings)
+	assert.Equal(t, 1, r.Canceled)
+	assert.InDelta(t, 1.0/7, r.CancellationRate, 1e-9)
+
+	// Прошедшие подтвержденные: 2 и 3 выданы, 4 и 8 - нет; 6 завершена
+	require.True(t, r.NoShowTracked)
+	assert.Equal(t, 5, r.NoShowBase)
+	assert.Equal(t, 2, r.NoShows)
+
+	// Клиент 1 - две аренды, клиент 3 бронировал раньше; клиенты 2 и 4 - новые
+	assert.Equal(t, 4, r.Clients)
+	assert.Equal(t, 2, r.RepeatClients)
+	assert.InDelta(t, 0.5, r.RepeatShare, 1e-9)
+
+	require.Len(t, r.Items, 2)
+	camera := r.Items[0]
+	assert.Equal(t, "Camera", camera.ItemName)
+	assert.Equal(t, int64(4), camera.BookedDays)
+	assert.Equal(t, int64(12), camera.UnitDays, "two units for 7 days, one of them in maintenance for 2 days")
+	assert.InDelta(t, 4.0/12, camera.Utilization, 1e-9)
+	assert.Equal(t, int64(1), r.Items[1].BookedDays)
+	assert.Equal(t, int64(7), r.Items[1].UnitDays)
+	assert.InDelta(t, 5.0/19, r.Utilization, 1e-9)
+
+	counts := make(map[string]int)
+	for _, b := range r.LeadTime {
+		counts[b.Label] = b.Count
+	}
+	assert.Equal(t, map[string]int{"0": 1, "1": 1, "2-3": 2, "4-7": 0, "8-14": 1, "15-30": 0, "31+": 1}, counts)
+	assert.Equal(t, 2.5, r.MedianLeadDays)
+
+	assert.Equal(t, [7]int{2, 1, 0, 1, 1, 0, 1}, r.Weekdays)
+	assert.Equal(t, time.Monday, r.BusiestWeekday)
+}
+
+func TestCompute_NoHandovers(t *testing.T) {
+	in := testInput()
+	in.Handovers = nil
+	r := Compute(in)
+	assert.False(t, r.NoShowTracked)
+	assert.Zero(t, r.NoShows)
+}
+
+type fakeSource struct {
+	in          *Input
+	bookingFrom time.Time
+}
+
+func (f *fakeSource) GetActiveItems(context.Context) ([]*models.Item, error) { return f.in.Items, nil }
+
+func (f *fakeSource) GetBookingsByDateRange(_ context.Context, start, _ time.Time) ([]*models.Booking, error) {
+	f.bookingFrom = start
+	return f.in.Bookings, nil
+}
+
+func (f *fakeSource) GetMaintenanceWindows(context.Context, int64, time.Time, time.Time) ([]*models.MaintenanceWindow, error) {
+	return f.in.Maintenance, nil
+}
+
+func (f *fakeSource) GetHandoversByPeriod(context.Context, time.Time, time.Time) ([]*models.Handover, error) {
+	return f.in.Handovers, nil
+}
+
+func TestLoad(t *testing.T) {
+	src := &fakeSource{in: testInput()}
+	r, err := Load(context.Background(), src, testDay(3), testDay(9), testDay(8))
+	require.NoError(t, err)
+	assert.Equal(t, 7, r.Bookings)
+	assert.Equal(t, testDay(3).AddDate(0, 0, -historyDays), src.bookingFrom, "history is loaded for repeat clients")
+
+	_, err = Load(context.Background(), src, testDay(9), testDay(3), testDay(8))
+	assert.ErrorIs(t, err, ErrInvalidPeriod)
+	_, err = Load(context.Background(), src, testDay(1), testDay(1).AddDate(0, 0, MaxPeriodDays), testDay(8))
+	assert.ErrorIs(t, err, ErrInvalidPeriod)
+
+	start, end := Period(time.Date(2030, 6, 9, 18, 0, 0, 0, time.UTC), 7)
+	assert.Equal(t, testDay(3), start)
+	assert.Equal(t, testDay(9), end)
+}
+
+func TestRenderXLSX(t *testing.T) {
+	r := Compute(testInput())
+	data, err := RenderXLSX(r)
+	require.NoError(t, err)
+	assert.Equal(t, "analytics_2030-06-03_2030-06-09.xlsx", Filename(r))
+
+	f, err := excelize.OpenReader(bytes.NewReader(data))
+	require.NoError(t, err)
+	defer f.Close()
+	assert.Equal(t, []string{sheetSummary, sheetUtilization, sheetLeadTime, sheetWeekdays}, f.GetSheetList())
+
+	value, err := f.GetCellValue(sheetSummary, "B2")
+	require.NoError(t, err)
+	assert.Equal(t, "7", value)
+	value, err = f.GetCellValue(sheetUtilization, "A2")
+	require.NoError(t, err)
+	assert.Equal(t, "Camera", value)
+
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	require.NoError(t, err)
+	charts := 0
+	for _, file := range zr.File {
+		if strings.HasPrefix(file.Name, "xl/charts/chart") {
+			charts++
+		}
+	}
+	assert.Equal(t, 3, charts)
+}
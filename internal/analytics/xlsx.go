@@ -0,0 +1,199 @@
+package analytics
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// ContentType is the MIME type of RenderXLSX output.
+const ContentType = "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+
+// Sheet names of the XLSX report.
+const (
+	sheetSummary     = "Сводка"
+	sheetUtilization = "Загрузка"
+	sheetLeadTime    = "Срок брони"
+	sheetWeekdays    = "Дни недели"
+)
+
+var weekdayNames = [7]string{"Пн", "Вт", "Ср", "Чт", "Пт", "Сб", "Вс"}
+
+// Filename returns the name of the XLSX report of r.
+func Filename(r *Report) string {
+	return fmt.Sprintf("analytics_%s_%s.xlsx", r.Start.Format("2006-01-02"), r.End.Format("2006-01-02"))
+}
+
+// RenderXLSX writes the report as a workbook with a summary sheet and charts of
+// utilization per item, lead time and bookings per weekday.
+func RenderXLSX(r *Report) ([]byte, error) {
+	f := excelize.NewFile()
+	defer f.Close()
+
+	if err := f.SetSheetName("Sheet1", sheetSummary); err != nil {
+		return nil, err
+	}
+	percent, err := f.NewStyle(&excelize.Style{NumFmt: 10}) // 0.00%
+	if err != nil {
+		return nil, err
+	}
+	bold, err := f.NewStyle(&excelize.Style{Font: &excelize.Font{Bold: true}})
+	if err != nil {
+		return nil, err
+	}
+
+	steps := []func(*excelize.File, *Report, int, int) error{
+		writeSummary, writeUtilization, writeLeadTime, writeWeekdays,
+	}
+	for _, step := range steps {
+		if err := step(f, r, percent, bold); err != nil {
+			return nil, err
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := f.Write(&buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func writeSummary(f *excelize.File, r *Report, percent, bold int) error {
+	noShow := interface{}("—")
+	if r.NoShowTracked {
+		noShow = r.NoShowRate
+	}
+	rows := []struct {
+		label   string
+		value   interface{}
+		percent bool
+	}{
+		{"Период", fmt.Sprintf("%s – %s", r.Start.Format("02.01.2006"), r.End.Format("02.01.2006")), false},
+		{"Заявок", r.Bookings, false},
+		{"Отменено", r.Canceled, false},
+		{"Доля отмен", r.CancellationRate, true},
+		{"Неявки", r.NoShows, false},
+		{"Доля неявок", noShow, true},
+		{"Клиентов", r.Clients, false},
+		{"Постоянных клиентов", r.RepeatClients, false},
+		{"Доля постоянных", r.RepeatShare, true},
+		{"Загрузка аппаратов", r.Utilization, true},
+		{"Медиана срока брони, дней", r.MedianLeadDays, false},
+	}
+	for i, row := range rows {
+		values := []interface{}{row.label, row.value}
+		cell, _ := excelize.CoordinatesToCellName(1, i+1)
+		if err := f.SetSheetRow(sheetSummary, cell, &values); err != nil {
+			return err
+		}
+		if row.percent {
+			value, _ := excelize.CoordinatesToCellName(2, i+1)
+			if err := f.SetCellStyle(sheetSummary, value, value, percent); err != nil {
+				return err
+			}
+		}
+	}
+	if err := f.SetCellStyle(sheetSummary, "A1", fmt.Sprintf("A%d", len(rows)), bold); err != nil {
+		return err
+	}
+	return f.SetColWidth(sheetSummary, "A", "A", 30)
+}
+
+func writeUtilization(f *excelize.File, r *Report, percent, bold int) error {
+	if _, err := f.NewSheet(sheetUtilization); err != nil {
+		return err
+	}
+	header := []interface{}{"Аппарат", "Дней в аренде", "Доступно аппарато-дней", "Загрузка"}
+	if err := f.SetSheetRow(sheetUtilization, "A1", &header); err != nil {
+		return err
+	}
+	for i, u := range r.Items {
+		row := []interface{}{u.ItemName, u.BookedDays, u.UnitDays, u.Utilization}
+		cell, _ := excelize.CoordinatesToCellName(1, i+2)
+		if err := f.SetSheetRow(sheetUtilization, cell, &row); err != nil {
+			return err
+		}
+	}
+	last := len(r.Items) + 1
+	if err := styleTable(f, sheetUtilization, "D", last, percent, bold); err != nil {
+		return err
+	}
+	if err := f.SetColWidth(sheetUtilization, "A", "D", 22); err != nil {
+		return err
+	}
+	if len(r.Items) == 0 {
+		return nil
+	}
+	return addChart(f, sheetUtilization, "F2", excelize.Bar, "Загрузка аппаратов", last, "D")
+}
+
+func writeLeadTime(f *excelize.File, r *Report, _, bold int) error {
+	if _, err := f.NewSheet(sheetLeadTime); err != nil {
+		return err
+	}
+	header := []interface{}{"За сколько дней", "Заявок"}
+	if err := f.SetSheetRow(sheetLeadTime, "A1", &header); err != nil {
+		return err
+	}
+	for i, b := range r.LeadTime {
+		row := []interface{}{b.Label, b.Count}
+		cell, _ := excelize.CoordinatesToCellName(1, i+2)
+		if err := f.SetSheetRow(sheetLeadTime, cell, &row); err != nil {
+			return err
+		}
+	}
+	if err := f.SetCellStyle(sheetLeadTime, "A1", "B1", bold); err != nil {
+		return err
+	}
+	if err := f.SetColWidth(sheetLeadTime, "A", "B", 18); err != nil {
+		return err
+	}
+	return addChart(f, sheetLeadTime, "D2", excelize.Col, "За сколько дней бронируют", len(r.LeadTime)+1, "B")
+}
+
+func writeWeekdays(f *excelize.File, r *Report, _, bold int) error {
+	if _, err := f.NewSheet(sheetWeekdays); err != nil {
+		return err
+	}
+	header := []interface{}{"День недели", "Заявок"}
+	if err := f.SetSheetRow(sheetWeekdays, "A1", &header); err != nil {
+		return err
+	}
+	for i, n := range r.Weekdays {
+		row := []interface{}{weekdayNames[i], n}
+		cell, _ := excelize.CoordinatesToCellName(1, i+2)
+		if err := f.SetSheetRow(sheetWeekdays, cell, &row); err != nil {
+			return err
+		}
+	}
+	if err := f.SetCellStyle(sheetWeekdays, "A1", "B1", bold); err != nil {
+		return err
+	}
+	return addChart(f, sheetWeekdays, "D2", excelize.Col, "Заявки по дням недели", len(r.Weekdays)+1, "B")
+}
+
+// styleTable makes the header bold and formats the column as percent.
+func styleTable(f *excelize.File, sheet, percentCol string, last, percent, bold int) error {
+	if err := f.SetCellStyle(sheet, "A1", percentCol+"1", bold); err != nil {
+		return err
+	}
+	if last < 2 {
+		return nil
+	}
+	return f.SetCellStyle(sheet, percentCol+"2", fmt.Sprintf("%s%d", percentCol, last), percent)
+}
+
+// addChart plots column valuesCol against the labels in column A, rows 2 to last.
+func addChart(f *excelize.File, sheet, cell string, chartType excelize.ChartType, title string, last int, valuesCol string) error {
+	return f.AddChart(sheet, cell, &excelize.Chart{
+		Type: chartType,
+		Series: []excelize.ChartSeries{{
+			Name:       fmt.Sprintf("'%s'!$%s$1", sheet, valuesCol),
+			Categories: fmt.Sprintf("'%s'!$A$2:$A$%d", sheet, last),
+			Values:     fmt.Sprintf("'%s'!$%s$2:$%s$%d", sheet, valuesCol, valuesCol, last),
+		}},
+		Title:  []excelize.RichTextRun{{Text: title}},
+		Legend: excelize.ChartLegend{Position: "none"},
+	})
+}
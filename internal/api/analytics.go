@@ -0,0 +1,70 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"bronivik/internal/analytics"
+	"bronivik/internal/metrics"
+)
+
+// defaultAnalyticsDays is the period of a report requested without from and to.
+const defaultAnalyticsDays = 30
+
+// handleAnalytics returns booking analytics: GET /api/v1/analytics?from=YYYY-MM-DD&to=YYYY-MM-DD&format=json|xlsx.
+// Without from and to the report covers the last 30 days.
+func (s *HTTPServer) handleAnalytics(w http.ResponseWriter, r *http.Request) {
+	metrics.IncHTTP("analytics")
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	format := strings.ToLower(r.URL.Query().Get("format"))
+	if format != "" && format != "json" && format != "xlsx" {
+		writeError(w, http.StatusBadRequest, "format must be json or xlsx")
+		return
+	}
+
+	now := time.Now()
+	from, to := analytics.Period(now, defaultAnalyticsDays)
+	fromStr := strings.TrimSpace(r.URL.Query().Get("from"))
+	toStr := strings.TrimSpace(r.URL.Query().Get("to"))
+	if fromStr != "" || toStr != "" {
+		var errFrom, errTo error
+		from, errFrom = time.Parse("2006-01-02", fromStr)
+		to, errTo = time.Parse("2006-01-02", toStr)
+		if errFrom != nil || errTo != nil {
+			writeError(w, http.StatusBadRequest, "from and to must both be set; expected YYYY-MM-DD")
+			return
+		}
+	}
+
+	report, err := analytics.Load(r.Context(), s.db, from, to, now)
+	if errors.Is(err, analytics.ErrInvalidPeriod) {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("to must not be before from; period is limited to %d days", analytics.MaxPeriodDays))
+		return
+	}
+	if err != nil {
+		s.log.Error().Err(err).Msg("failed to compute analytics")
+		writeError(w, http.StatusInternalServerError, "failed to compute analytics")
+		return
+	}
+
+	if format != "xlsx" {
+		writeJSON(w, http.StatusOK, report)
+		return
+	}
+	data, err := analytics.RenderXLSX(report)
+	if err != nil {
+		s.log.Error().Err(err).Msg("failed to render analytics report")
+		writeError(w, http.StatusInternalServerError, "failed to render report")
+		return
+	}
+	w.Header().Set("Content-Type", analytics.ContentType)
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", analytics.Filename(report)))
+	_, _ = w.Write(data)
+}
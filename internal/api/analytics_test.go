@@ -0,0 +1,62 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"bronivik/internal/analytics"
+	"bronivik/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAnalytics(t *testing.T) {
+	db := newTestDB(t)
+	item := models.Item{Name: "camera", TotalQuantity: 2, IsActive: true}
+	require.NoError(t, db.CreateItem(context.Background(), &item))
+
+	today := time.Now()
+	insertTestBooking(t, db, &item, today.AddDate(0, 0, -1), models.StatusConfirmed)
+	insertTestBooking(t, db, &item, today.AddDate(0, 0, -2), models.StatusCanceled)
+	insertTestBooking(t, db, &item, today.AddDate(0, 0, -60), models.StatusConfirmed)
+
+	ts := httptest.NewServer(newTestHTTPServer(db).server.Handler)
+	t.Cleanup(ts.Close)
+	get := func(query string) *http.Response {
+		resp, err := http.Get(ts.URL + "/api/v1/analytics" + query)
+		require.NoError(t, err)
+		t.Cleanup(func() { resp.Body.Close() })
+		return resp
+	}
+
+	// По умолчанию - последние 30 дней
+	resp := get("")
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	var report analytics.Report
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&report))
+	assert.Equal(t, 2, report.Bookings)
+	assert.Equal(t, 1, report.Canceled)
+	assert.Equal(t, 1, report.RepeatClients, "the client booked two months ago")
+	require.Len(t, report.Items, 1)
+	assert.Equal(t, int64(1), report.Items[0].BookedDays)
+	assert.Equal(t, int64(60), report.Items[0].UnitDays)
+
+	from := today.AddDate(0, 0, -90).Format("2006-01-02")
+	resp = get("?from=" + from + "&to=" + today.Format("2006-01-02") + "&format=xlsx")
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, analytics.ContentType, resp.Header.Get("Content-Type"))
+	assert.Contains(t, resp.Header.Get("Content-Disposition"), "analytics_"+from)
+	data, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	assert.Equal(t, "PK", string(data[:2]))
+
+	assert.Equal(t, http.StatusBadRequest, get("?from=2030-01-10&to=2030-01-01").StatusCode)
+	assert.Equal(t, http.StatusBadRequest, get("?from=2030-01-01").StatusCode)
+	assert.Equal(t, http.StatusBadRequest, get("?format=pdf").StatusCode)
+}
@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"bronivik/internal/config"
+	"bronivik/internal/models"
 
 	"github.com/google/uuid"
 	"github.com/rs/zerolog"
@@ -59,8 +60,8 @@ func (a *AuthInterceptor) Unary() grpc.UnaryServerInterceptor {
 const (
 	apiKeyHeaderDefault   = "x-api-key"
 	apiExtraHeaderDefault = "x-api-extra"
-	permReadAvailability  = "read:availability"
-	permReadItems         = "read:items"
+	permReadAvailability  = models.PermAPIReadAvailability
+	permReadItems         = models.PermAPIReadItems
 	clientKeyUnknown      = "unknown"
 )
 
@@ -108,17 +109,37 @@ func (a *AuthInterceptor) checkPermissions(client config.APIClientKey, fullMetho
 		return nil
 	}
 
-	// If permissions list is empty, treat as allow-all.
-	if len(client.Permissions) == 0 {
+	if clientHasPermission(client, required) {
 		return nil
 	}
+	return status.Error(codes.PermissionDenied, "permission denied")
+}
+
+// legacyPermissions are granted to a client without role and permissions: keys issued
+// before roles existed keep access to availability and items. Booking data, audit and
+// statistics carry client personal data and must be granted explicitly.
+var legacyPermissions = map[string]bool{
+	permReadAvailability: true,
+	permReadItems:        true,
+}
+
+// clientHasPermission checks the client's role first, then its explicit
+// permission list. A client without role and permissions gets legacyPermissions.
+func clientHasPermission(client config.APIClientKey, required string) bool {
+	if client.Role != "" && models.RoleHasPermission(client.Role, required) {
+		return true
+	}
+
+	if client.Role == "" && len(client.Permissions) == 0 {
+		return legacyPermissions[required]
+	}
 
 	for _, p := range client.Permissions {
 		if strings.TrimSpace(p) == required {
-			return nil
+			return true
 		}
 	}
-	return status.Error(codes.PermissionDenied, "permission denied")
+	return false
 }
 
 func requiredPermission(fullMethod string) string {
@@ -180,6 +201,7 @@ func LoggingUnaryInterceptor(logger *zerolog.Logger) grpc.UnaryServerInterceptor
 	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
 		requestID := requestIDFromMetadata(ctx)
 		_ = grpc.SetHeader(ctx, metadata.Pairs(requestIDMetadataKey, requestID))
+		ctx = models.WithAuditActor(ctx, models.AuditActor{Source: models.AuditSourceAPI})
 
 		start := time.Now()
 		resp, err := handler(ctx, req)
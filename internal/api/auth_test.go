@@ -5,6 +5,7 @@ import (
 	"testing"
 
 	"bronivik/internal/config"
+	"bronivik/internal/models"
 
 	"github.com/stretchr/testify/assert"
 	"google.golang.org/grpc"
@@ -116,6 +117,7 @@ func TestAuthInterceptor_RateLimit(t *testing.T) {
 func TestLoggingUnaryInterceptor(t *testing.T) {
 	interceptor := LoggingUnaryInterceptor(nil)
 	handler := func(ctx context.Context, req any) (any, error) {
+		assert.Equal(t, models.AuditSourceAPI, models.AuditActorFromContext(ctx).Source)
 		return "ok", nil
 	}
 	info := &grpc.UnaryServerInfo{FullMethod: "test"}
@@ -140,3 +142,29 @@ func TestRequiredPermission(t *testing.T) {
 		assert.Equal(t, tt.want, requiredPermission(tt.method))
 	}
 }
+
+func TestClientHasPermission(t *testing.T) {
+	tests := []struct {
+		name     string
+		client   config.APIClientKey
+		required string
+		want     bool
+	}{
+		{"legacy key reads availability", config.APIClientKey{}, "read:availability", true},
+		{"legacy key reads items", config.APIClientKey{}, "read:items", true},
+		{"legacy key without audit", config.APIClientKey{}, "read:audit", false},
+		{"legacy key without bookings", config.APIClientKey{}, "read:bookings", false},
+		{"legacy key without stats", config.APIClientKey{}, "read:stats", false},
+		{"explicit permission", config.APIClientKey{Permissions: []string{"read:items"}}, "read:items", true},
+		{"missing permission", config.APIClientKey{Permissions: []string{"read:items"}}, "read:audit", false},
+		{"viewer role", config.APIClientKey{Role: "viewer"}, "read:availability", true},
+		{"viewer role without audit", config.APIClientKey{Role: "viewer"}, "read:audit", false},
+		{"role plus explicit permission", config.APIClientKey{Role: "viewer", Permissions: []string{"read:audit"}}, "read:audit", true},
+		{"manager role", config.APIClientKey{Role: "manager"}, "read:audit", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, clientHasPermission(tt.client, tt.required))
+		})
+	}
+}
@@ -0,0 +1,136 @@
+package api
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"bronivik/internal/ical"
+	"bronivik/internal/metrics"
+	"bronivik/internal/models"
+)
+
+const (
+	// calendarName names client and all-bookings calendars; item calendars use the item name.
+	calendarName = "Bronivik"
+
+	// Item and all-bookings feeds cover this window around today.
+	calendarPastDays   = 30
+	calendarFutureDays = 365
+)
+
+var errInvalidCalendarToken = errors.New("invalid or revoked calendar token")
+
+// handleCalendarFeed serves iCalendar feeds: GET /api/v1/ics/user/{telegram_id}.ics,
+// /api/v1/ics/item/{item_id}.ics and /api/v1/ics/all.ics with ?token=. The token replaces
+// API key auth, so calendar apps can subscribe to the URL directly.
+func (s *HTTPServer) handleCalendarFeed(w http.ResponseWriter, r *http.Request) {
+	metrics.IncHTTP("calendar_feed")
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+	if s.cfg.Calendar.Secret == "" {
+		writeError(w, http.StatusServiceUnavailable, "calendar feeds are not configured")
+		return
+	}
+
+	scope, subjectID, ok := ical.ParsePath(r.URL.Path)
+	if !ok {
+		writeError(w, http.StatusNotFound, "not found")
+		return
+	}
+
+	feed, err := s.calendarFeed(r.Context(), scope, subjectID, r.URL.Query().Get("token"))
+	if errors.Is(err, errInvalidCalendarToken) {
+		writeError(w, http.StatusUnauthorized, err.Error())
+		return
+	}
+	if err != nil {
+		s.log.Error().Err(err).Str("scope", scope).Int64("subject_id", subjectID).Msg("failed to check calendar token")
+		writeError(w, http.StatusInternalServerError, "failed to load calendar")
+		return
+	}
+
+	name, bookings, err := s.calendarBookings(r.Context(), feed)
+	if err != nil {
+		s.log.Error().Err(err).Int64("feed_id", feed.ID).Msg("failed to load calendar bookings")
+		writeError(w, http.StatusInternalServerError, "failed to load calendar")
+		return
+	}
+	if bookings == nil {
+		writeError(w, http.StatusNotFound, "not found")
+		return
+	}
+
+	withClient := feed.Scope != models.CalendarScopeUser
+	w.Header().Set("Content-Type", ical.ContentType)
+	w.Header().Set("Content-Disposition", fmt.Sprintf("inline; filename=%q", strings.TrimPrefix(r.URL.Path, ical.PathPrefix)))
+	w.Header().Set("Cache-Control", "private, max-age=300")
+	_, _ = w.Write(ical.Render(name, ical.BookingEvents(bookings, withClient)))
+}
+
+// calendarFeed returns the feed the token was issued for if it matches the requested path
+// and is not revoked.
+func (s *HTTPServer) calendarFeed(ctx context.Context, scope string, subjectID int64, token string) (*models.CalendarFeed, error) {
+	id, err := ical.FeedID(token)
+	if err != nil {
+		return nil, errInvalidCalendarToken
+	}
+	feed, err := s.db.GetCalendarFeed(ctx, id)
+	if errors.Is(err, models.ErrCalendarFeedNotFound) {
+		return nil, errInvalidCalendarToken
+	}
+	if err != nil {
+		return nil, err
+	}
+	if feed.IsRevoked() || feed.Scope != scope || feed.SubjectID != subjectID ||
+		!ical.NewSigner(s.cfg.Calendar.Secret).Verify(feed, token) {
+		return nil, errInvalidCalendarToken
+	}
+	return feed, nil
+}
+
+// calendarBookings returns the calendar name and bookings of a feed: all recent and
+// future bookings of a client, the occupied days of an item or all confirmed bookings.
+// Bookings are nil when the feed's item no longer exists.
+func (s *HTTPServer) calendarBookings(ctx context.Context, feed *models.CalendarFeed) (string, []*models.Booking, error) {
+	if feed.Scope == models.CalendarScopeUser {
+		bookings, err := s.db.GetUserBookings(ctx, feed.SubjectID)
+		if bookings == nil && err == nil {
+			bookings = []*models.Booking{}
+		}
+		return calendarName, bookings, err
+	}
+
+	name := calendarName
+	statuses := map[string]bool{models.StatusConfirmed: true, models.StatusCompleted: true}
+	if feed.Scope == models.CalendarScopeItem {
+		item, err := s.db.GetItemByID(ctx, feed.SubjectID)
+		if errors.Is(err, sql.ErrNoRows) {
+			return "", nil, nil
+		}
+		if err != nil {
+			return "", nil, err
+		}
+		name = item.Name
+		statuses[models.StatusPending] = true
+	}
+
+	today := time.Now().Truncate(24 * time.Hour)
+	all, err := s.db.GetBookingsByDateRange(ctx, today.AddDate(0, 0, -calendarPastDays), today.AddDate(0, 0, calendarFutureDays))
+	if err != nil {
+		return "", nil, err
+	}
+	bookings := make([]*models.Booking, 0, len(all))
+	for _, b := range all {
+		if statuses[b.Status] && (feed.Scope == models.CalendarScopeAll || b.ItemID == feed.SubjectID) {
+			bookings = append(bookings, b)
+		}
+	}
+	return name, bookings, nil
+}
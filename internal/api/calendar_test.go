@@ -0,0 +1,106 @@
+package api
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"bronivik/internal/config"
+	"bronivik/internal/ical"
+	"bronivik/internal/models"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCalendarFeed(t *testing.T) {
+	db := newTestDB(t)
+	ctx := context.Background()
+	item := createTestItem(t, db, "camera", 2)
+	other := createTestItem(t, db, "laser", 1)
+	tomorrow := time.Now().AddDate(0, 0, 1)
+	insertTestBooking(t, db, &item, tomorrow, models.StatusConfirmed)
+	insertTestBooking(t, db, &item, tomorrow.AddDate(0, 0, 1), models.StatusPending)
+	insertTestBooking(t, db, &other, tomorrow, models.StatusConfirmed)
+
+	// API-ключи включены: фид должен открываться только по токену из ссылки
+	cfg := config.APIConfig{
+		Enabled:  true,
+		HTTP:     config.APIHTTPConfig{Enabled: true},
+		Auth:     config.APIAuthConfig{Enabled: true, APIKeys: []config.APIClientKey{{Key: "k", Extra: "e", Name: "crm"}}},
+		Calendar: config.APICalendarConfig{Secret: "0123456789abcdef"},
+	}
+	logger := zerolog.New(io.Discard)
+	server := NewHTTPServer(&cfg, db, nil, nil, &logger)
+	ts := httptest.NewServer(server.server.Handler)
+	t.Cleanup(ts.Close)
+
+	signer := ical.NewSigner(cfg.Calendar.Secret)
+	issue := func(scope string, subjectID int64) *models.CalendarFeed {
+		feed := &models.CalendarFeed{Scope: scope, SubjectID: subjectID}
+		require.NoError(t, db.CreateCalendarFeed(ctx, feed))
+		return feed
+	}
+	get := func(url string) (int, string) {
+		resp, err := http.Get(url)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(resp.Body)
+		return resp.StatusCode, string(body)
+	}
+
+	userFeed := issue(models.CalendarScopeUser, 1)
+	status, body := get(signer.URL(ts.URL, userFeed))
+	require.Equal(t, http.StatusOK, status, body)
+	assert.Equal(t, 3, strings.Count(body, "BEGIN:VEVENT"))
+	assert.Contains(t, body, "SUMMARY:camera\r\n")
+	assert.NotContains(t, body, "+100000000", "the client's own feed has no contact details")
+
+	itemFeed := issue(models.CalendarScopeItem, item.ID)
+	status, body = get(signer.URL(ts.URL, itemFeed))
+	require.Equal(t, http.StatusOK, status)
+	assert.Equal(t, 2, strings.Count(body, "BEGIN:VEVENT"))
+	assert.Contains(t, body, "STATUS:TENTATIVE")
+	assert.Contains(t, body, "SUMMARY:camera — tester")
+
+	allFeed := issue(models.CalendarScopeAll, 0)
+	status, body = get(signer.URL(ts.URL, allFeed))
+	require.Equal(t, http.StatusOK, status)
+	assert.Equal(t, 2, strings.Count(body, "BEGIN:VEVENT"), "only confirmed bookings")
+	assert.NotContains(t, body, "TENTATIVE")
+
+	// Токен чужого фида, поддельная подпись и отсутствие токена не принимаются
+	status, _ = get(ts.URL + ical.Path(models.CalendarScopeUser, 2) + "?token=" + signer.Token(userFeed))
+	assert.Equal(t, http.StatusUnauthorized, status)
+	status, _ = get(signer.URL(ts.URL, userFeed) + "x")
+	assert.Equal(t, http.StatusUnauthorized, status)
+	status, _ = get(ts.URL + ical.Path(models.CalendarScopeAll, 0))
+	assert.Equal(t, http.StatusUnauthorized, status)
+	status, _ = get(ts.URL + ical.PathPrefix + "team.ics")
+	assert.Equal(t, http.StatusNotFound, status)
+
+	_, err := db.RevokeCalendarFeeds(ctx, models.CalendarScopeUser, 1)
+	require.NoError(t, err)
+	status, _ = get(signer.URL(ts.URL, userFeed))
+	assert.Equal(t, http.StatusUnauthorized, status, "revoked feed")
+
+	// Остальные эндпоинты по-прежнему требуют API-ключ
+	status, _ = get(ts.URL + "/api/v1/items")
+	assert.Equal(t, http.StatusUnauthorized, status)
+}
+
+func TestCalendarFeed_NotConfigured(t *testing.T) {
+	db := newTestDB(t)
+	ts := httptest.NewServer(newTestHTTPServer(db).server.Handler)
+	t.Cleanup(ts.Close)
+
+	resp, err := http.Get(ts.URL + ical.Path(models.CalendarScopeAll, 0) + "?token=1.abc")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+}
@@ -0,0 +1,107 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"bronivik/internal/exports"
+	"bronivik/internal/metrics"
+	"bronivik/internal/models"
+)
+
+const (
+	// exportWriteTimeout replaces the server's WriteTimeout for exports. The deadline is
+	// moved forward after every exportFlushRows rows, so only a stalled export is cut off.
+	exportWriteTimeout = 30 * time.Second
+	exportFlushRows    = 500
+)
+
+// handleBookingExport streams a flat list of bookings:
+// GET /api/v1/exports/bookings?format=csv|jsonl|xlsx&from=&to=&status=&item_id=&name=&phone=
+// (CSV by default). Filters are the same as in booking search; limit and offset are ignored.
+func (s *HTTPServer) handleBookingExport(w http.ResponseWriter, r *http.Request) {
+	metrics.IncHTTP("booking_export")
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	format, err := exports.ParseFormat(r.URL.Query().Get("format"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "format must be csv, jsonl or xlsx")
+		return
+	}
+	filter, err := models.ParseBookingFilter(r.URL.Query())
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	rc := http.NewResponseController(w)
+	extendDeadline := func() {
+		err := rc.SetWriteDeadline(time.Now().Add(exportWriteTimeout))
+		if err != nil && !errors.Is(err, http.ErrNotSupported) {
+			s.log.Warn().Err(err).Msg("failed to extend booking export deadline")
+		}
+	}
+	extendDeadline()
+
+	// The writer is created with the first booking, so that a failed query
+	// can still be reported with an error status.
+	var out exports.BookingWriter
+	start := func() error {
+		w.Header().Set("Content-Type", exports.ContentType(format))
+		w.Header().Set("Content-Disposition",
+			fmt.Sprintf("attachment; filename=%q", exports.Filename(format, &filter, time.Now())))
+		out, err = exports.NewBookingWriter(w, format)
+		return err
+	}
+
+	rows := 0
+	err = s.db.StreamBookings(r.Context(), filter, func(b *models.Booking) error {
+		if out == nil {
+			if err := start(); err != nil {
+				return err
+			}
+		}
+		rows++
+		if err := out.Write(b); err != nil {
+			return err
+		}
+		if rows%exportFlushRows != 0 {
+			return nil
+		}
+		if err := out.Flush(); err != nil {
+			return err
+		}
+		if err := rc.Flush(); err != nil && !errors.Is(err, http.ErrNotSupported) {
+			return err
+		}
+		extendDeadline()
+		return nil
+	})
+	if err != nil && out == nil {
+		s.log.Error().Err(err).Msg("failed to export bookings")
+		writeError(w, http.StatusInternalServerError, "failed to export bookings")
+		return
+	}
+	if err != nil {
+		// Headers are sent already: the client gets a truncated file.
+		s.log.Error().Err(err).Int("rows", rows).Msg("booking export interrupted")
+		return
+	}
+
+	if out == nil {
+		if err := start(); err != nil {
+			s.log.Error().Err(err).Msg("failed to export bookings")
+			return
+		}
+	}
+	// An XLSX workbook is written out as a whole on Close.
+	extendDeadline()
+	if err := out.Close(); err != nil {
+		s.log.Error().Err(err).Int("rows", rows).Msg("failed to finish booking export")
+	}
+}
@@ -0,0 +1,95 @@
+package api
+
+import (
+	"context"
+	"encoding/csv"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"bronivik/internal/exports"
+	"bronivik/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBookingExport(t *testing.T) {
+	db := newTestDB(t)
+	camera := models.Item{Name: "camera", TotalQuantity: 2, IsActive: true}
+	light := models.Item{Name: "light", TotalQuantity: 2, IsActive: true}
+	require.NoError(t, db.CreateItem(context.Background(), &camera))
+	require.NoError(t, db.CreateItem(context.Background(), &light))
+
+	day := time.Date(2030, 3, 10, 0, 0, 0, 0, time.UTC)
+	insertTestBooking(t, db, &camera, day, models.StatusConfirmed)
+	insertTestBooking(t, db, &light, day.AddDate(0, 0, 1), models.StatusCanceled)
+	insertTestBooking(t, db, &camera, day.AddDate(0, 0, 40), models.StatusConfirmed)
+
+	ts := httptest.NewServer(newTestHTTPServer(db).server.Handler)
+	t.Cleanup(ts.Close)
+	get := func(query string) (*http.Response, string) {
+		resp, err := http.Get(ts.URL + "/api/v1/exports/bookings" + query)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		body, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		return resp, string(body)
+	}
+
+	resp, body := get("?from=2030-03-01&to=2030-03-31")
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, exports.ContentType(exports.FormatCSV), resp.Header.Get("Content-Type"))
+	assert.Contains(t, resp.Header.Get("Content-Disposition"), "bookings_2030-03-01_2030-03-31.csv")
+	rows, err := csv.NewReader(strings.NewReader(body)).ReadAll()
+	require.NoError(t, err)
+	require.Len(t, rows, 3)
+	assert.Equal(t, "2030-03-10", rows[1][1])
+	assert.Equal(t, "2030-03-11", rows[2][1])
+
+	_, body = get("?format=jsonl&status=confirmed&item_id=" + strconv.FormatInt(camera.ID, 10))
+	lines := strings.Split(strings.TrimSpace(body), "\n")
+	require.Len(t, lines, 2)
+	assert.Contains(t, lines[1], `"date":"2030-04-19"`)
+
+	resp, body = get("?format=xlsx")
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "PK", body[:2])
+
+	// Пустая выгрузка - только заголовок
+	resp, body = get("?from=2031-01-01&to=2031-01-31")
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, 1, strings.Count(body, "\n"))
+
+	resp, _ = get("?format=pdf")
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	resp, _ = get("?status=unknown")
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+}
+
+func TestBookingExport_ExtendsWriteDeadline(t *testing.T) {
+	db := newTestDB(t)
+	camera := models.Item{Name: "camera", TotalQuantity: 2, IsActive: true}
+	require.NoError(t, db.CreateItem(context.Background(), &camera))
+	insertTestBooking(t, db, &camera, time.Date(2030, 3, 10, 0, 0, 0, 0, time.UTC), models.StatusConfirmed)
+
+	// WriteTimeout сервера истекает раньше, чем выгрузка начинает писать ответ
+	ts := httptest.NewUnstartedServer(newTestHTTPServer(db).server.Handler)
+	ts.Config.WriteTimeout = time.Nanosecond
+	ts.Start()
+	t.Cleanup(ts.Close)
+
+	for _, format := range []string{exports.FormatCSV, exports.FormatXLSX} {
+		resp, err := http.Get(ts.URL + "/api/v1/exports/bookings?format=" + format)
+		require.NoError(t, err, format)
+		body, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		require.NoError(t, err, format)
+		assert.Equal(t, http.StatusOK, resp.StatusCode, format)
+		assert.NotEmpty(t, body, format)
+	}
+}
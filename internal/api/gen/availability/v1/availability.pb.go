@@ -362,6 +362,11 @@ type Item struct {
 	Id            int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
 	Name          string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
 	TotalQuantity int64                  `protobuf:"varint,3,opt,name=total_quantity,json=totalQuantity,proto3" json:"total_quantity,omitempty"`
+	Description   string                 `protobuf:"bytes,4,opt,name=description,proto3" json:"description,omitempty"`
+	Category      string                 `protobuf:"bytes,5,opt,name=category,proto3" json:"category,omitempty"`
+	PhotoFileIds  []string               `protobuf:"bytes,6,rep,name=photo_file_ids,json=photoFileIds,proto3" json:"photo_file_ids,omitempty"`
+	Specs         []*ItemSpec            `protobuf:"bytes,7,rep,name=specs,proto3" json:"specs,omitempty"`
+	PricePerDay   int64                  `protobuf:"varint,8,opt,name=price_per_day,json=pricePerDay,proto3" json:"price_per_day,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -417,6 +422,93 @@ func (x *Item) GetTotalQuantity() int64 {
 	return 0
 }
 
+func (x *Item) GetDescription() string {
+	if x != nil {
+		return x.Description
+	}
+	return ""
+}
+
+func (x *Item) GetCategory() string {
+	if x != nil {
+		return x.Category
+	}
+	return ""
+}
+
+func (x *Item) GetPhotoFileIds() []string {
+	if x != nil {
+		return x.PhotoFileIds
+	}
+	return nil
+}
+
+func (x *Item) GetSpecs() []*ItemSpec {
+	if x != nil {
+		return x.Specs
+	}
+	return nil
+}
+
+func (x *Item) GetPricePerDay() int64 {
+	if x != nil {
+		return x.PricePerDay
+	}
+	return 0
+}
+
+type ItemSpec struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Value         string                 `protobuf:"bytes,2,opt,name=value,proto3" json:"value,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ItemSpec) Reset() {
+	*x = ItemSpec{}
+	mi := &file_availability_v1_availability_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ItemSpec) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ItemSpec) ProtoMessage() {}
+
+func (x *ItemSpec) ProtoReflect() protoreflect.Message {
+	mi := &file_availability_v1_availability_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ItemSpec.ProtoReflect.Descriptor instead.
+func (*ItemSpec) Descriptor() ([]byte, []int) {
+	return file_availability_v1_availability_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *ItemSpec) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *ItemSpec) GetValue() string {
+	if x != nil {
+		return x.Value
+	}
+	return ""
+}
+
 type ListItemsResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Items         []*Item                `protobuf:"bytes,1,rep,name=items,proto3" json:"items,omitempty"`
@@ -426,7 +518,7 @@ type ListItemsResponse struct {
 
 func (x *ListItemsResponse) Reset() {
 	*x = ListItemsResponse{}
-	mi := &file_availability_v1_availability_proto_msgTypes[7]
+	mi := &file_availability_v1_availability_proto_msgTypes[8]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -438,7 +530,7 @@ func (x *ListItemsResponse) String() string {
 func (*ListItemsResponse) ProtoMessage() {}
 
 func (x *ListItemsResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_availability_v1_availability_proto_msgTypes[7]
+	mi := &file_availability_v1_availability_proto_msgTypes[8]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -451,7 +543,7 @@ func (x *ListItemsResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ListItemsResponse.ProtoReflect.Descriptor instead.
 func (*ListItemsResponse) Descriptor() ([]byte, []int) {
-	return file_availability_v1_availability_proto_rawDescGZIP(), []int{7}
+	return file_availability_v1_availability_proto_rawDescGZIP(), []int{8}
 }
 
 func (x *ListItemsResponse) GetItems() []*Item {
@@ -486,11 +578,19 @@ const file_availability_v1_availability_proto_rawDesc = "" +
 	"\x05total\x18\x05 \x01(\x03R\x05total\"_\n" +
 	"\x1bGetAvailabilityBulkResponse\x12@\n" +
 	"\aresults\x18\x01 \x03(\v2&.bronivik.availability.v1.AvailabilityR\aresults\"\x12\n" +
-	"\x10ListItemsRequest\"Q\n" +
+	"\x10ListItemsRequest\"\x93\x02\n" +
 	"\x04Item\x12\x0e\n" +
 	"\x02id\x18\x01 \x01(\x03R\x02id\x12\x12\n" +
 	"\x04name\x18\x02 \x01(\tR\x04name\x12%\n" +
-	"\x0etotal_quantity\x18\x03 \x01(\x03R\rtotalQuantity\"I\n" +
+	"\x0etotal_quantity\x18\x03 \x01(\x03R\rtotalQuantity\x12 \n" +
+	"\vdescription\x18\x04 \x01(\tR\vdescription\x12\x1a\n" +
+	"\bcategory\x18\x05 \x01(\tR\bcategory\x12$\n" +
+	"\x0ephoto_file_ids\x18\x06 \x03(\tR\fphotoFileIds\x128\n" +
+	"\x05specs\x18\a \x03(\v2\".bronivik.availability.v1.ItemSpecR\x05specs\x12\"\n" +
+	"\rprice_per_day\x18\b \x01(\x03R\vpricePerDay\"4\n" +
+	"\bItemSpec\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value\"I\n" +
 	"\x11ListItemsResponse\x124\n" +
 	"\x05items\x18\x01 \x03(\v2\x1e.bronivik.availability.v1.ItemR\x05items2\xf8\x02\n" +
 	"\x13AvailabilityService\x12v\n" +
@@ -510,7 +610,7 @@ func file_availability_v1_availability_proto_rawDescGZIP() []byte {
 	return file_availability_v1_availability_proto_rawDescData
 }
 
-var file_availability_v1_availability_proto_msgTypes = make([]protoimpl.MessageInfo, 8)
+var file_availability_v1_availability_proto_msgTypes = make([]protoimpl.MessageInfo, 9)
 var file_availability_v1_availability_proto_goTypes = []any{
 	(*GetAvailabilityRequest)(nil),      // 0: bronivik.availability.v1.GetAvailabilityRequest
 	(*GetAvailabilityResponse)(nil),     // 1: bronivik.availability.v1.GetAvailabilityResponse
@@ -519,22 +619,24 @@ var file_availability_v1_availability_proto_goTypes = []any{
 	(*GetAvailabilityBulkResponse)(nil), // 4: bronivik.availability.v1.GetAvailabilityBulkResponse
 	(*ListItemsRequest)(nil),            // 5: bronivik.availability.v1.ListItemsRequest
 	(*Item)(nil),                        // 6: bronivik.availability.v1.Item
-	(*ListItemsResponse)(nil),           // 7: bronivik.availability.v1.ListItemsResponse
+	(*ItemSpec)(nil),                    // 7: bronivik.availability.v1.ItemSpec
+	(*ListItemsResponse)(nil),           // 8: bronivik.availability.v1.ListItemsResponse
 }
 var file_availability_v1_availability_proto_depIdxs = []int32{
 	3, // 0: bronivik.availability.v1.GetAvailabilityBulkResponse.results:type_name -> bronivik.availability.v1.Availability
-	6, // 1: bronivik.availability.v1.ListItemsResponse.items:type_name -> bronivik.availability.v1.Item
-	0, // 2: bronivik.availability.v1.AvailabilityService.GetAvailability:input_type -> bronivik.availability.v1.GetAvailabilityRequest
-	2, // 3: bronivik.availability.v1.AvailabilityService.GetAvailabilityBulk:input_type -> bronivik.availability.v1.GetAvailabilityBulkRequest
-	5, // 4: bronivik.availability.v1.AvailabilityService.ListItems:input_type -> bronivik.availability.v1.ListItemsRequest
-	1, // 5: bronivik.availability.v1.AvailabilityService.GetAvailability:output_type -> bronivik.availability.v1.GetAvailabilityResponse
-	4, // 6: bronivik.availability.v1.AvailabilityService.GetAvailabilityBulk:output_type -> bronivik.availability.v1.GetAvailabilityBulkResponse
-	7, // 7: bronivik.availability.v1.AvailabilityService.ListItems:output_type -> bronivik.availability.v1.ListItemsResponse
-	5, // [5:8] is the sub-list for method output_type
-	2, // [2:5] is the sub-list for method input_type
-	2, // [2:2] is the sub-list for extension type_name
-	2, // [2:2] is the sub-list for extension extendee
-	0, // [0:2] is the sub-list for field type_name
+	7, // 1: bronivik.availability.v1.Item.specs:type_name -> bronivik.availability.v1.ItemSpec
+	6, // 2: bronivik.availability.v1.ListItemsResponse.items:type_name -> bronivik.availability.v1.Item
+	0, // 3: bronivik.availability.v1.AvailabilityService.GetAvailability:input_type -> bronivik.availability.v1.GetAvailabilityRequest
+	2, // 4: bronivik.availability.v1.AvailabilityService.GetAvailabilityBulk:input_type -> bronivik.availability.v1.GetAvailabilityBulkRequest
+	5, // 5: bronivik.availability.v1.AvailabilityService.ListItems:input_type -> bronivik.availability.v1.ListItemsRequest
+	1, // 6: bronivik.availability.v1.AvailabilityService.GetAvailability:output_type -> bronivik.availability.v1.GetAvailabilityResponse
+	4, // 7: bronivik.availability.v1.AvailabilityService.GetAvailabilityBulk:output_type -> bronivik.availability.v1.GetAvailabilityBulkResponse
+	8, // 8: bronivik.availability.v1.AvailabilityService.ListItems:output_type -> bronivik.availability.v1.ListItemsResponse
+	6, // [6:9] is the sub-list for method output_type
+	3, // [3:6] is the sub-list for method input_type
+	3, // [3:3] is the sub-list for extension type_name
+	3, // [3:3] is the sub-list for extension extendee
+	0, // [0:3] is the sub-list for field type_name
 }
 
 func init() { file_availability_v1_availability_proto_init() }
@@ -548,7 +650,7 @@ func file_availability_v1_availability_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_availability_v1_availability_proto_rawDesc), len(file_availability_v1_availability_proto_rawDesc)),
 			NumEnums:      0,
-			NumMessages:   8,
+			NumMessages:   9,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
@@ -59,7 +59,12 @@ func (s *AvailabilityService) GetAvailability(ctx context.Context, req *availabi
 		return nil, status.Error(codes.Internal, "failed to get booked count")
 	}
 
-	total := item.TotalQuantity
+	maintenance, err := s.db.GetMaintenanceUnits(ctx, item.ID, date)
+	if err != nil {
+		return nil, status.Error(codes.Internal, "failed to get maintenance")
+	}
+
+	total := item.EffectiveQuantity(maintenance)
 	available := int64(booked) < total
 
 	return &availabilityv1.GetAvailabilityResponse{
@@ -103,7 +108,12 @@ func (s *AvailabilityService) GetAvailabilityBulk(ctx context.Context, req *avai
 				return nil, status.Error(codes.Internal, "failed to get booked count")
 			}
 
-			total := item.TotalQuantity
+			maintenance, err := s.db.GetMaintenanceUnits(ctx, item.ID, date)
+			if err != nil {
+				return nil, status.Error(codes.Internal, "failed to get maintenance")
+			}
+
+			total := item.EffectiveQuantity(maintenance)
 			results = append(results, &availabilityv1.Availability{
 				ItemName:    item.Name,
 				Date:        dateStr,
@@ -124,10 +134,19 @@ func (s *AvailabilityService) ListItems(
 	items := s.db.GetItems()
 	out := make([]*availabilityv1.Item, 0, len(items))
 	for _, it := range items {
+		specs := make([]*availabilityv1.ItemSpec, 0, len(it.Specs))
+		for _, spec := range it.Specs {
+			specs = append(specs, &availabilityv1.ItemSpec{Name: spec.Name, Value: spec.Value})
+		}
 		out = append(out, &availabilityv1.Item{
 			Id:            it.ID,
 			Name:          it.Name,
 			TotalQuantity: it.TotalQuantity,
+			Description:   it.Description,
+			Category:      it.Category,
+			PhotoFileIds:  it.PhotoFileIDs,
+			Specs:         specs,
+			PricePerDay:   it.PricePerDay,
 		})
 	}
 	return &availabilityv1.ListItemsResponse{Items: out}, nil
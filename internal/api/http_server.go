@@ -8,19 +8,26 @@ import (
 	"net"
 	"net/http"
 	"sort"
+	"strconv"
 	"strings"
 	"time"
 
 	"bronivik/internal/config"
 	"bronivik/internal/database"
 	"bronivik/internal/google"
+	"bronivik/internal/ical"
 	"bronivik/internal/metrics"
+	"bronivik/internal/models"
+	"bronivik/internal/stickers"
 
 	"github.com/google/uuid"
 	"github.com/redis/go-redis/v9"
 	"github.com/rs/zerolog"
 )
 
+// maxAvailabilityRangeDays limits how many days one availability range request may cover.
+const maxAvailabilityRangeDays = 92
+
 // HTTPServer exposes a lightweight HTTP API alongside the gRPC service.
 type HTTPServer struct {
 	cfg           *config.APIConfig
@@ -54,6 +61,12 @@ func NewHTTPServer(
 	apiMux.HandleFunc("/api/v1/availability/bulk", srv.handleAvailabilityBulk)
 	apiMux.HandleFunc("/api/v1/availability/", srv.handleAvailability)
 	apiMux.HandleFunc("/api/v1/items", srv.handleItems)
+	apiMux.HandleFunc("/api/v1/items/stickers", srv.handleItemStickers)
+	apiMux.HandleFunc("/api/v1/bookings", srv.handleBookingSearch)
+	apiMux.HandleFunc("/api/v1/bookings/", srv.handleBookingHistory)
+	apiMux.HandleFunc("/api/v1/analytics", srv.handleAnalytics)
+	apiMux.HandleFunc("/api/v1/exports/bookings", srv.handleBookingExport)
+	apiMux.HandleFunc(ical.PathPrefix, srv.handleCalendarFeed)
 	apiMux.HandleFunc("/healthz", srv.handleHealthz)
 	apiMux.HandleFunc("/readyz", srv.handleReadyz)
 
@@ -101,7 +114,13 @@ func (s *HTTPServer) handleAvailability(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	dateStr := strings.TrimSpace(r.URL.Query().Get("date"))
+	query := r.URL.Query()
+	if query.Get("from") != "" || query.Get("to") != "" {
+		s.handleAvailabilityRange(w, r, itemName)
+		return
+	}
+
+	dateStr := strings.TrimSpace(query.Get("date"))
 	if dateStr == "" {
 		writeError(w, http.StatusBadRequest, "date is required")
 		return
@@ -123,10 +142,58 @@ func (s *HTTPServer) handleAvailability(w http.ResponseWriter, r *http.Request)
 		"available":    info.Available,
 		"booked_count": info.BookedCount,
 		"total":        info.Total,
+		"maintenance":  info.Maintenance,
 	}
 	writeJSON(w, http.StatusOK, resp)
 }
 
+// handleAvailabilityRange answers /api/v1/availability/{item}?from=&to= with one entry per day.
+// Total is the capacity left after maintenance on that day.
+func (s *HTTPServer) handleAvailabilityRange(w http.ResponseWriter, r *http.Request, itemName string) {
+	from, errFrom := time.Parse("2006-01-02", strings.TrimSpace(r.URL.Query().Get("from")))
+	to, errTo := time.Parse("2006-01-02", strings.TrimSpace(r.URL.Query().Get("to")))
+	if errFrom != nil || errTo != nil {
+		writeError(w, http.StatusBadRequest, "from and to are required; expected YYYY-MM-DD")
+		return
+	}
+
+	days := int(to.Sub(from).Hours()/24) + 1
+	if days <= 0 {
+		writeError(w, http.StatusBadRequest, "to must not be before from")
+		return
+	}
+	if days > maxAvailabilityRangeDays {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("range is limited to %d days", maxAvailabilityRangeDays))
+		return
+	}
+
+	item, err := s.db.GetItemByName(r.Context(), itemName)
+	if err != nil {
+		writeError(w, http.StatusNotFound, "item not found")
+		return
+	}
+
+	availability, err := s.db.GetAvailabilityForPeriod(r.Context(), item.ID, from, days)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to get availability")
+		return
+	}
+
+	results := make([]map[string]any, 0, len(availability))
+	for _, a := range availability {
+		results = append(results, map[string]any{
+			"date":         a.Date.Format("2006-01-02"),
+			"available":    a.Available > 0,
+			"free":         a.Available,
+			"booked_count": a.Booked,
+			"total":        item.EffectiveQuantity(a.Maintenance),
+			"maintenance":  a.Maintenance,
+		})
+	}
+
+	writeJSON(w, http.StatusOK, map[string]any{"item_name": item.Name, "days": results})
+}
+
 func (s *HTTPServer) parseItemName(path, prefix string) string {
 	if !strings.HasPrefix(path, prefix) {
 		return ""
@@ -224,6 +291,7 @@ func (s *HTTPServer) processBulkAvailability(ctx context.Context, items, dates [
 				"available":    info.Available,
 				"booked_count": info.BookedCount,
 				"total":        info.Total,
+				"maintenance":  info.Maintenance,
 			})
 		}
 	}
@@ -286,6 +354,121 @@ func (s *HTTPServer) handleItems(w http.ResponseWriter, r *http.Request) {
 	writeJSON(w, http.StatusOK, map[string]any{"items": items})
 }
 
+// handleItemStickers returns a printable sheet of QR code stickers for all active items:
+// GET /api/v1/items/stickers?format=pdf|png (PDF by default). Each code opens the bot on the item's schedule.
+func (s *HTTPServer) handleItemStickers(w http.ResponseWriter, r *http.Request) {
+	metrics.IncHTTP("item_stickers")
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	format := strings.ToLower(r.URL.Query().Get("format"))
+	if format == "" {
+		format = stickers.FormatPDF
+	}
+	if format != stickers.FormatPDF && format != stickers.FormatPNG {
+		writeError(w, http.StatusBadRequest, "format must be pdf or png")
+		return
+	}
+	if s.cfg.BotUsername == "" {
+		writeError(w, http.StatusServiceUnavailable, "bot username is not configured")
+		return
+	}
+
+	items, err := s.db.GetActiveItems(r.Context())
+	if err != nil {
+		s.log.Error().Err(err).Msg("failed to load items for stickers")
+		writeError(w, http.StatusInternalServerError, "failed to load items")
+		return
+	}
+	if len(items) == 0 {
+		writeError(w, http.StatusNotFound, "no active items")
+		return
+	}
+
+	sheet, err := stickers.ForItems(items, s.cfg.BotUsername)
+	if err == nil {
+		var data []byte
+		if data, err = stickers.Render(sheet, format); err == nil {
+			w.Header().Set("Content-Type", stickers.ContentType(format))
+			w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", "item_stickers."+format))
+			_, _ = w.Write(data)
+			return
+		}
+	}
+	s.log.Error().Err(err).Str("format", format).Msg("failed to render item stickers")
+	writeError(w, http.StatusInternalServerError, "failed to render stickers")
+}
+
+// handleBookingHistory returns the audit trail of a booking: GET /api/v1/bookings/{id}/history.
+func (s *HTTPServer) handleBookingHistory(w http.ResponseWriter, r *http.Request) {
+	metrics.IncHTTP("booking_history")
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	const prefix = "/api/v1/bookings/"
+	rest := strings.TrimPrefix(r.URL.Path, prefix)
+	idStr, ok := strings.CutSuffix(rest, "/history")
+	if !ok {
+		writeError(w, http.StatusNotFound, "not found")
+		return
+	}
+
+	bookingID, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil || bookingID <= 0 {
+		writeError(w, http.StatusBadRequest, "invalid booking id")
+		return
+	}
+
+	entries, err := s.db.GetAuditEntries(r.Context(), models.AuditEntityBooking, bookingID)
+	if err != nil {
+		s.log.Error().Err(err).Int64("booking_id", bookingID).Msg("failed to load booking history")
+		writeError(w, http.StatusInternalServerError, "failed to load history")
+		return
+	}
+	if entries == nil {
+		entries = []*models.AuditEntry{}
+	}
+
+	writeJSON(w, http.StatusOK, map[string]any{"booking_id": bookingID, "entries": entries})
+}
+
+// handleBookingSearch returns bookings matching the query filter: GET /api/v1/bookings
+// with id, status, item_id, from, to, name, phone, limit and offset (see models.ParseBookingFilter).
+func (s *HTTPServer) handleBookingSearch(w http.ResponseWriter, r *http.Request) {
+	metrics.IncHTTP("booking_search")
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	filter, err := models.ParseBookingFilter(r.URL.Query())
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if filter.Limit <= 0 || filter.Limit > models.MaxSearchResults {
+		filter.Limit = models.MaxSearchResults
+	}
+
+	bookings, err := s.db.SearchBookings(r.Context(), filter)
+	if err != nil {
+		s.log.Error().Err(err).Msg("failed to search bookings")
+		writeError(w, http.StatusInternalServerError, "failed to search bookings")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]any{
+		"bookings": bookings,
+		"count":    len(bookings),
+		"limit":    filter.Limit,
+		"offset":   filter.Offset,
+	})
+}
+
 // corsMiddleware adds permissive CORS headers for simple API consumption.
 func corsMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -328,7 +511,8 @@ func (a *HTTPAuth) Wrap(next http.Handler) http.Handler {
 			return
 		}
 
-		if a.cfg.Auth.Enabled {
+		// Calendar feeds carry a signed token in the URL instead: calendar apps cannot send headers.
+		if a.cfg.Auth.Enabled && !strings.HasPrefix(r.URL.Path, ical.PathPrefix) {
 			if err := a.checkAuth(r); err != nil {
 				statusCode := http.StatusUnauthorized
 				if err == errPermissionDenied {
@@ -386,24 +570,28 @@ func (a *HTTPAuth) checkPermissions(client config.APIClientKey, r *http.Request)
 	if required == "" {
 		return nil
 	}
-	if len(client.Permissions) == 0 {
+	if clientHasPermission(client, required) {
 		return nil
 	}
-	for _, p := range client.Permissions {
-		if strings.TrimSpace(p) == required {
-			return nil
-		}
-	}
 	return errPermissionDenied
 }
 
 func requiredPermissionHTTP(r *http.Request) string {
 	path := r.URL.Path
 	if strings.HasPrefix(path, "/api/v1/availability") {
-		return "read:availability"
+		return permReadAvailability
 	}
-	if path == "/api/v1/items" {
-		return "read:items"
+	if path == "/api/v1/items" || path == "/api/v1/items/stickers" {
+		return permReadItems
+	}
+	if strings.HasPrefix(path, "/api/v1/bookings/") && strings.HasSuffix(path, "/history") {
+		return models.PermAPIReadAudit
+	}
+	if path == "/api/v1/bookings" || path == "/api/v1/exports/bookings" {
+		return models.PermAPIReadBookings
+	}
+	if path == "/api/v1/analytics" {
+		return models.PermAPIReadStats
 	}
 	return ""
 }
@@ -442,6 +630,8 @@ func (s *HTTPServer) loggingMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		reqID := requestIDFromHeader(r)
 		ctx := context.WithValue(r.Context(), requestIDKey{}, reqID)
+		// API clients have no Telegram ID: changes made through the API are attributed to the channel only.
+		ctx = models.WithAuditActor(ctx, models.AuditActor{Source: models.AuditSourceAPI})
 		r = r.WithContext(ctx)
 		w.Header().Set(requestIDHeader, reqID)
 
@@ -514,3 +704,8 @@ func (r *statusRecorder) WriteHeader(status int) {
 	r.status = status
 	r.ResponseWriter.WriteHeader(status)
 }
+
+// Unwrap lets http.ResponseController reach the connection to flush and extend deadlines.
+func (r *statusRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
@@ -9,6 +9,7 @@ import (
 	"net/http/httptest"
 	"path/filepath"
 	"reflect"
+	"strconv"
 	"strings"
 	"testing"
 	"time"
@@ -20,6 +21,7 @@ import (
 
 	"github.com/rs/zerolog"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"google.golang.org/grpc"
 )
 
@@ -82,6 +84,79 @@ func TestAvailabilityNotFound(t *testing.T) {
 	}
 }
 
+func TestAvailabilityRangeWithMaintenance(t *testing.T) {
+	db := newTestDB(t)
+	item := createTestItem(t, db, "camera", 3)
+	day := time.Date(2025, 12, 1, 0, 0, 0, 0, time.UTC)
+	insertTestBooking(t, db, &item, day, "confirmed")
+	insertTestBooking(t, db, &item, day, "confirmed")
+	if err := db.CreateMaintenance(context.Background(), &models.MaintenanceWindow{
+		ItemID: item.ID, Units: 2, StartDate: day, EndDate: day.AddDate(0, 0, 1),
+	}); err != nil {
+		t.Fatalf("create maintenance: %v", err)
+	}
+
+	server := newTestHTTPServer(db)
+	ts := httptest.NewServer(server.server.Handler)
+	t.Cleanup(ts.Close)
+
+	resp, err := http.Get(ts.URL + "/api/v1/availability/camera?from=2025-12-01&to=2025-12-03")
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	var body struct {
+		Days []struct {
+			Date        string `json:"date"`
+			Available   bool   `json:"available"`
+			Free        int64  `json:"free"`
+			BookedCount int64  `json:"booked_count"`
+			Total       int64  `json:"total"`
+			Maintenance int64  `json:"maintenance"`
+		} `json:"days"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		t.Fatalf("decode body: %v", err)
+	}
+	if len(body.Days) != 3 {
+		t.Fatalf("expected 3 days, got %d", len(body.Days))
+	}
+
+	// Two bookings on a day with one unit left in service: fully booked.
+	assert.False(t, body.Days[0].Available)
+	assert.Equal(t, int64(1), body.Days[0].Total)
+	assert.Equal(t, int64(2), body.Days[0].Maintenance)
+	assert.Equal(t, int64(1), body.Days[1].Free)
+	assert.True(t, body.Days[2].Available)
+	assert.Equal(t, int64(3), body.Days[2].Total)
+
+	single, err := http.Get(ts.URL + "/api/v1/availability/camera?date=2025-12-02")
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	defer single.Body.Close()
+	var info struct {
+		Total       int64 `json:"total"`
+		Maintenance int64 `json:"maintenance"`
+	}
+	if err := json.NewDecoder(single.Body).Decode(&info); err != nil {
+		t.Fatalf("decode body: %v", err)
+	}
+	assert.Equal(t, int64(1), info.Total)
+	assert.Equal(t, int64(2), info.Maintenance)
+
+	for _, query := range []string{"from=2025-12-03&to=2025-12-01", "from=2025-01-01&to=2025-12-31", "from=bad&to=2025-12-01"} {
+		bad, err := http.Get(ts.URL + "/api/v1/availability/camera?" + query)
+		if err != nil {
+			t.Fatalf("request failed: %v", err)
+		}
+		bad.Body.Close()
+		assert.Equal(t, http.StatusBadRequest, bad.StatusCode, query)
+	}
+}
+
 func TestItems(t *testing.T) {
 	db := newTestDB(t)
 	createTestItem(t, db, "Item A", 5)
@@ -113,6 +188,44 @@ func TestItems(t *testing.T) {
 	}
 }
 
+func TestItemStickers(t *testing.T) {
+	db := newTestDB(t)
+	if err := db.CreateItem(context.Background(), &models.Item{Name: "Кресло", TotalQuantity: 1, IsActive: true}); err != nil {
+		t.Fatalf("create item: %v", err)
+	}
+
+	server := newTestHTTPServer(db)
+	ts := httptest.NewServer(server.server.Handler)
+	t.Cleanup(ts.Close)
+
+	get := func(query string) *http.Response {
+		resp, err := http.Get(ts.URL + "/api/v1/items/stickers" + query)
+		if err != nil {
+			t.Fatalf("request failed: %v", err)
+		}
+		t.Cleanup(func() { resp.Body.Close() })
+		return resp
+	}
+
+	resp := get("")
+	assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode, "без имени бота ссылки не построить")
+
+	server.cfg.BotUsername = "bronivik_bot"
+
+	resp = get("")
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "application/pdf", resp.Header.Get("Content-Type"))
+	body, _ := io.ReadAll(resp.Body)
+	assert.True(t, strings.HasPrefix(string(body), "%PDF"))
+
+	resp = get("?format=png")
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "image/png", resp.Header.Get("Content-Type"))
+
+	resp = get("?format=gif")
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+}
+
 func TestHealthz(t *testing.T) {
 	db := newTestDB(t)
 	server := newTestHTTPServer(db)
@@ -292,6 +405,7 @@ func TestAuth(t *testing.T) {
 			HeaderExtra:  "x-api-extra",
 			APIKeys: []config.APIClientKey{
 				{Key: "valid-key", Extra: "valid-extra", Permissions: []string{"read:items"}},
+				{Key: "legacy-key", Extra: "legacy-extra"},
 			},
 		},
 	}
@@ -334,6 +448,47 @@ func TestAuth(t *testing.T) {
 		}
 	})
 
+	t.Run("HistoryRequiresAuditPermission", func(t *testing.T) {
+		req, _ := http.NewRequest("GET", ts.URL+"/api/v1/bookings/1/history", http.NoBody)
+		req.Header.Set("x-api-key", "valid-key")
+		req.Header.Set("x-api-extra", "valid-extra")
+		resp, err := http.DefaultClient.Do(req)
+		assert.NoError(t, err)
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusForbidden {
+			t.Errorf("expected 403, got %d", resp.StatusCode)
+		}
+	})
+
+	t.Run("SearchRequiresBookingsPermission", func(t *testing.T) {
+		req, _ := http.NewRequest("GET", ts.URL+"/api/v1/bookings?name=test", http.NoBody)
+		req.Header.Set("x-api-key", "valid-key")
+		req.Header.Set("x-api-extra", "valid-extra")
+		resp, err := http.DefaultClient.Do(req)
+		assert.NoError(t, err)
+		defer resp.Body.Close()
+		assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+	})
+
+	// Ключ без роли и прав читает только доступность и аппараты, но не персональные данные
+	t.Run("LegacyKey", func(t *testing.T) {
+		for path, want := range map[string]int{
+			"/api/v1/items":                       http.StatusOK,
+			"/api/v1/exports/bookings?format=csv": http.StatusForbidden,
+			"/api/v1/bookings?name=test":          http.StatusForbidden,
+			"/api/v1/bookings/1/history":          http.StatusForbidden,
+			"/api/v1/analytics":                   http.StatusForbidden,
+		} {
+			req, _ := http.NewRequest("GET", ts.URL+path, http.NoBody)
+			req.Header.Set("x-api-key", "legacy-key")
+			req.Header.Set("x-api-extra", "legacy-extra")
+			resp, err := http.DefaultClient.Do(req)
+			require.NoError(t, err)
+			resp.Body.Close()
+			assert.Equal(t, want, resp.StatusCode, path)
+		}
+	})
+
 	t.Run("WrongPermission", func(t *testing.T) {
 		req, _ := http.NewRequest("GET", ts.URL+"/api/v1/availability/camera?date=2025-01-01", http.NoBody)
 		req.Header.Set("x-api-key", "valid-key")
@@ -347,6 +502,110 @@ func TestAuth(t *testing.T) {
 	})
 }
 
+func TestBookingHistory(t *testing.T) {
+	db := newTestDB(t)
+	ctx := context.Background()
+	for _, e := range []*models.AuditEntry{
+		{EntityType: models.AuditEntityBooking, EntityID: 5, Action: models.AuditActionCreate, Source: models.AuditSourceBot},
+		{EntityType: models.AuditEntityBooking, EntityID: 5, Action: models.AuditActionStatusChange, ActorID: 7, Source: models.AuditSourceBot},
+		{EntityType: models.AuditEntityBooking, EntityID: 6, Action: models.AuditActionCreate, Source: models.AuditSourceBot},
+	} {
+		if err := db.CreateAuditEntry(ctx, e); err != nil {
+			t.Fatalf("create audit entry: %v", err)
+		}
+	}
+
+	server := newTestHTTPServer(db)
+	ts := httptest.NewServer(server.server.Handler)
+	t.Cleanup(ts.Close)
+
+	resp, err := http.Get(ts.URL + "/api/v1/bookings/5/history")
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("unexpected status: %d", resp.StatusCode)
+	}
+
+	var body struct {
+		BookingID int64                `json:"booking_id"`
+		Entries   []*models.AuditEntry `json:"entries"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		t.Fatalf("decode body: %v", err)
+	}
+	assert.Equal(t, int64(5), body.BookingID)
+	if assert.Len(t, body.Entries, 2) {
+		assert.Equal(t, models.AuditActionCreate, body.Entries[0].Action)
+		assert.Equal(t, int64(7), body.Entries[1].ActorID)
+	}
+
+	respBad, err := http.Get(ts.URL + "/api/v1/bookings/abc/history")
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	defer respBad.Body.Close()
+	assert.Equal(t, http.StatusBadRequest, respBad.StatusCode)
+
+	respUnknown, err := http.Get(ts.URL + "/api/v1/bookings/5")
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	defer respUnknown.Body.Close()
+	assert.Equal(t, http.StatusNotFound, respUnknown.StatusCode)
+}
+
+func TestBookingSearch(t *testing.T) {
+	db := newTestDB(t)
+	camera := createTestItem(t, db, "camera", 2)
+	light := createTestItem(t, db, "light", 2)
+	day := time.Date(2030, 5, 1, 0, 0, 0, 0, time.UTC)
+	insertTestBooking(t, db, &camera, day, models.StatusPending)
+	insertTestBooking(t, db, &camera, day.AddDate(0, 0, 1), models.StatusConfirmed)
+	insertTestBooking(t, db, &light, day.AddDate(0, 0, 2), models.StatusConfirmed)
+
+	server := newTestHTTPServer(db)
+	ts := httptest.NewServer(server.server.Handler)
+	t.Cleanup(ts.Close)
+
+	search := func(query string) (int, []*models.Booking) {
+		resp, err := http.Get(ts.URL + "/api/v1/bookings?" + query)
+		if err != nil {
+			t.Fatalf("request failed: %v", err)
+		}
+		defer resp.Body.Close()
+		var body struct {
+			Bookings []*models.Booking `json:"bookings"`
+			Count    int               `json:"count"`
+		}
+		_ = json.NewDecoder(resp.Body).Decode(&body)
+		assert.Equal(t, len(body.Bookings), body.Count)
+		return resp.StatusCode, body.Bookings
+	}
+
+	status, bookings := search("status=confirmed&item_id=" + strconv.FormatInt(camera.ID, 10))
+	assert.Equal(t, http.StatusOK, status)
+	if assert.Len(t, bookings, 1) {
+		assert.Equal(t, camera.ID, bookings[0].ItemID)
+		assert.Equal(t, models.StatusConfirmed, bookings[0].Status)
+	}
+
+	status, bookings = search("from=2030-05-02&to=2030-05-31")
+	assert.Equal(t, http.StatusOK, status)
+	assert.Len(t, bookings, 2)
+
+	status, bookings = search("name=TESTER&limit=1")
+	assert.Equal(t, http.StatusOK, status)
+	assert.Len(t, bookings, 1)
+
+	status, _ = search("status=unknown")
+	assert.Equal(t, http.StatusBadRequest, status)
+
+	status, _ = search("from=01.05.2030")
+	assert.Equal(t, http.StatusBadRequest, status)
+}
+
 func TestAvailabilityErrors(t *testing.T) {
 	db := newTestDB(t)
 	server := newTestHTTPServer(db)
@@ -490,6 +749,17 @@ func (s *HTTPServer) GetHandler() http.Handler {
 	return s.server.Handler
 }
 
+func TestLoggingMiddlewareSetsAuditActor(t *testing.T) {
+	server := newTestHTTPServer(nil)
+	var actor models.AuditActor
+	handler := server.loggingMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		actor = models.AuditActorFromContext(r.Context())
+	}))
+
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/api/v1/items", http.NoBody))
+	assert.Equal(t, models.AuditActor{Source: models.AuditSourceAPI}, actor)
+}
+
 func newTestHTTPServer(db *database.DB) *HTTPServer {
 	cfg := config.APIConfig{
 		Enabled: true,
@@ -632,6 +902,40 @@ func TestAvailabilityService_ListItems(t *testing.T) {
 	}
 }
 
+func TestAvailabilityService_ListItemsCatalog(t *testing.T) {
+	db := newTestDB(t)
+	item := &models.Item{
+		Name:          "laser",
+		TotalQuantity: 1,
+		IsActive:      true,
+		Category:      "Lasers/Diode",
+		PhotoFileIDs:  []string{"file-1"},
+		Specs:         []models.ItemSpec{{Name: "Power", Value: "1200 W"}},
+		PricePerDay:   3500,
+	}
+	if err := db.CreateItem(context.Background(), item); err != nil {
+		t.Fatalf("create item: %v", err)
+	}
+
+	svc := NewAvailabilityService(db)
+	resp, err := svc.ListItems(context.Background(), &availabilityv1.ListItemsRequest{})
+	if err != nil {
+		t.Fatalf("ListItems: %v", err)
+	}
+	if len(resp.Items) != 1 {
+		t.Fatalf("expected 1 item, got %d", len(resp.Items))
+	}
+
+	got := resp.Items[0]
+	assert.Equal(t, "Lasers/Diode", got.GetCategory())
+	assert.Equal(t, []string{"file-1"}, got.GetPhotoFileIds())
+	assert.Equal(t, int64(3500), got.GetPricePerDay())
+	if assert.Len(t, got.GetSpecs(), 1) {
+		assert.Equal(t, "Power", got.GetSpecs()[0].GetName())
+		assert.Equal(t, "1200 W", got.GetSpecs()[0].GetValue())
+	}
+}
+
 func TestChainUnaryInterceptors(t *testing.T) {
 	callCount := 0
 	var calls []string
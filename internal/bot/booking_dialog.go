@@ -0,0 +1,243 @@
+package bot
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"bronivik/internal/database"
+	"bronivik/internal/models"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// bookingDialogState - состояние бронирования пользователем
+type bookingDialogState struct {
+	ItemID   int64     `json:"item_id"`
+	Date     time.Time `json:"date"`
+	UserName string    `json:"user_name"`
+	Phone    string    `json:"phone"`
+}
+
+type bookingContext = dialogContext[bookingDialogState]
+
+// userBookingDialog - бронирование аппарата пользователем: дата, согласие на обработку данных, ФИО, телефон
+var userBookingDialog = &dialog[bookingDialogState]{
+	name:     "booking",
+	timeout:  time.Hour,
+	canceled: "menu.action_canceled",
+	leave: func(c *bookingContext) {
+		c.bot.handleSelectItem(c.ctx, c.update)
+	},
+	steps: map[string]*dialogStep[bookingDialogState]{
+		models.StateWaitingDate: {
+			prompt: promptBookingDate,
+			input:  inputBookingDate,
+		},
+		models.StatePersonalData: {
+			prompt: promptBookingConsent,
+			input:  inputBookingConsent,
+			back:   backTo[bookingDialogState](models.StateWaitingDate),
+		},
+		models.StateEnterName: {
+			prompt: promptBookingName,
+			input:  inputBookingName,
+			back:   backTo[bookingDialogState](models.StateWaitingDate),
+		},
+		models.StatePhoneNumber: {
+			prompt: promptBookingPhone,
+			input:  inputBookingPhone,
+			back:   backTo[bookingDialogState](models.StateEnterName),
+		},
+	},
+}
+
+func promptBookingDate(c *bookingContext) {
+	item, err := c.bot.itemService.GetItemByID(c.ctx, c.state.ItemID)
+	if err != nil || item == nil {
+		c.bot.logger.Error().Err(err).Int64("item_id", c.state.ItemID).Msg("Error getting item by ID")
+		c.send(c.bot.t(c.ctx, "error.item_not_found"), nil)
+		return
+	}
+
+	c.bot.sendItemCard(c.ctx, c.chatID, item)
+	c.send(c.bot.t(c.ctx, "booking.item_selected", item.Name), c.calendar())
+}
+
+// inputBookingDate проверяет дату и доступность аппарата; ФИО и телефон запрашиваются только после согласия
+func inputBookingDate(c *bookingContext, text string) (string, error) {
+	b := c.bot
+	date, err := time.Parse("02.01.2006", text)
+	if err != nil {
+		return "", dialogError("error.invalid_date")
+	}
+
+	// Валидация даты через сервис
+	if errVal := b.bookingService.ValidateBookingDate(date); errVal != nil {
+		return "", errVal
+	}
+
+	item, ok := b.getItemByID(c.state.ItemID)
+	if !ok {
+		b.sendMessage(c.chatID, b.t(c.ctx, "error.element_not_found_restart"))
+		b.handleMainMenu(c.ctx, c.update)
+		return dialogDone, nil
+	}
+
+	available, err := b.bookingService.CheckAvailability(c.ctx, item.ID, date)
+	if err != nil {
+		b.logger.Error().Err(err).Int64("item_id", item.ID).Time("date", date).Msg("Error checking availability")
+		return "", dialogError("error.availability_later")
+	}
+	if !available {
+		return "", dialogError("booking.date_unavailable")
+	}
+
+	c.state.Date = date
+	if !b.userService.HasConsent(c.ctx, c.userID) {
+		return models.StatePersonalData, nil
+	}
+	return models.StateEnterName, nil
+}
+
+func promptBookingName(c *bookingContext) {
+	c.send(c.bot.t(c.ctx, "booking.enter_name"), tgbotapi.NewReplyKeyboard(
+		tgbotapi.NewKeyboardButtonRow(
+			tgbotapi.NewKeyboardButton(c.bot.t(c.ctx, btnManagerContacts)),
+			tgbotapi.NewKeyboardButton(c.bot.t(c.ctx, btnCancel)),
+		),
+		tgbotapi.NewKeyboardButtonRow(
+			tgbotapi.NewKeyboardButton(c.bot.t(c.ctx, btnBack)),
+		),
+	))
+}
+
+func inputBookingName(c *bookingContext, text string) (string, error) {
+	name := c.bot.sanitizeInput(text)
+	if name == "" {
+		return "", dialogError("booking.enter_name")
+	}
+	c.state.UserName = name
+	return models.StatePhoneNumber, nil
+}
+
+func promptBookingPhone(c *bookingContext) {
+	c.send(c.bot.t(c.ctx, "booking.enter_phone"), tgbotapi.NewReplyKeyboard(
+		tgbotapi.NewKeyboardButtonRow(
+			tgbotapi.NewKeyboardButtonContact(c.bot.t(c.ctx, "btn.send_contact")),
+		),
+		tgbotapi.NewKeyboardButtonRow(
+			tgbotapi.NewKeyboardButton(c.bot.t(c.ctx, btnManagerContacts)),
+			tgbotapi.NewKeyboardButton(c.bot.t(c.ctx, btnCancel)),
+		),
+		tgbotapi.NewKeyboardButtonRow(
+			tgbotapi.NewKeyboardButton(c.bot.t(c.ctx, btnBack)),
+		),
+	))
+}
+
+// inputBookingPhone принимает телефон текстом или контактом, еще раз проверяет доступность и создает заявку
+func inputBookingPhone(c *bookingContext, text string) (string, error) {
+	b := c.bot
+	if contact := c.update.Message.Contact; contact != nil {
+		text = contact.PhoneNumber
+	}
+
+	phone := b.normalizePhone(text)
+	if phone == "" {
+		return "", dialogError("error.invalid_phone")
+	}
+
+	if c.state.ItemID == 0 || c.state.Date.IsZero() {
+		b.sendMessage(c.chatID, b.t(c.ctx, "error.session_expired"))
+		b.handleMainMenu(c.ctx, c.update)
+		return dialogDone, nil
+	}
+
+	item, ok := b.getItemByID(c.state.ItemID)
+	if !ok {
+		b.sendMessage(c.chatID, b.t(c.ctx, "error.item_not_found_restart"))
+		b.handleMainMenu(c.ctx, c.update)
+		return dialogDone, nil
+	}
+
+	c.state.Phone = phone
+	b.updateUserPhone(c.userID, phone)
+
+	available, err := b.bookingService.CheckAvailability(c.ctx, item.ID, c.state.Date)
+	if err != nil || !available {
+		b.sendMessage(c.chatID, b.t(c.ctx, "booking.no_longer_available"))
+		b.handleMainMenu(c.ctx, c.update)
+		return dialogDone, nil
+	}
+
+	if !b.finalizeBooking(c.ctx, c.update, c.state, &item) {
+		return dialogDone, nil
+	}
+
+	// Сводку отправляем только по созданной заявке
+	b.sendMessage(c.chatID, b.t(c.ctx, "booking.confirmation",
+		item.Name,
+		c.state.Date.Format("02.01.2006"),
+		c.state.UserName,
+		phone))
+	return dialogDone, nil
+}
+
+// finalizeBooking создает заявку пользователя и уведомляет менеджеров; возвращает false, если заявка не создана
+func (b *Bot) finalizeBooking(ctx context.Context, update *tgbotapi.Update, state *bookingDialogState, item *models.Item) bool {
+	from := update.Message.From
+	userName := state.UserName
+	if userName == "" {
+		// Если имя не было введено, используем имя из Telegram
+		userName = from.FirstName + " " + from.LastName
+	}
+
+	booking := models.Booking{
+		UserID:       from.ID,
+		UserName:     userName,
+		UserNickname: from.FirstName + " " + from.LastName,
+		Phone:        state.Phone,
+		ItemID:       item.ID,
+		ItemName:     item.Name,
+		Date:         state.Date,
+		Status:       models.StatusPending,
+		CreatedAt:    time.Now(),
+		UpdatedAt:    time.Now(),
+	}
+
+	start := time.Now()
+	err := b.bookingService.CreateBooking(ctx, &booking)
+	if err != nil {
+		b.logger.Error().Err(err).Int64("user_id", from.ID).Msg("Error creating booking")
+		b.sendMessage(update.Message.Chat.ID, b.getErrorMessage(ctx, err))
+		if errors.Is(err, database.ErrNotAvailable) || errors.Is(err, database.ErrPastDate) {
+			b.handleMainMenu(ctx, update)
+		}
+		return false
+	}
+
+	// Track metrics
+	if b.metrics != nil {
+		b.metrics.BookingsCreated.WithLabelValues(item.Name).Inc()
+		b.metrics.BookingDuration.WithLabelValues(item.Name).Observe(time.Since(start).Seconds())
+	}
+
+	// Уведомляем менеджеров
+	b.notifyManagers(ctx, &booking)
+
+	text := b.t(ctx, "booking.created", booking.ID, booking.ItemName)
+	if lines := b.bookingPriceLines(ctx, &booking); len(lines) > 0 {
+		text += "\n\n" + strings.Join(lines, "\n")
+	}
+	msg := tgbotapi.NewMessage(update.Message.Chat.ID, text)
+
+	// Очищаем состояние
+	b.clearUserState(ctx, from.ID)
+	b.handleMainMenu(ctx, update)
+	if _, err := b.tgService.Send(msg); err != nil {
+		b.logger.Error().Err(err).Msg("Failed to send final booking msg")
+	}
+	return true
+}
@@ -2,40 +2,51 @@ package bot
 
 import (
 	"context"
+	"fmt"
 	"os"
 	"time"
 
 	"bronivik/internal/config"
 	"bronivik/internal/domain"
 	"bronivik/internal/events"
+	"bronivik/internal/i18n"
+	"bronivik/internal/models"
 
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
 	"github.com/google/uuid"
 	"github.com/rs/zerolog"
 )
 
+// Ключи кнопок в каталоге сообщений (см. internal/i18n/locales)
 const (
-	btnCancel               = "❌ Отмена"
-	btnBack                 = "⬅️ Назад"
-	btnCreateBooking        = "📋 СОЗДАТЬ ЗАЯВКУ"
-	btnMyBookings           = "📊 Мои заявки"
-	btnManagerContacts      = "📞 Контакты менеджеров"
-	btnAvailableItems       = "💼 Ассортимент"
-	btnViewSchedule         = "📅 Посмотреть расписание"
-	btnMonthSchedule        = "📅 30 дней"
-	btnPickDate             = "🗓 Выбрать дату"
-	btnBackToItems          = "⬅️ Назад к выбору аппарата"
-	btnCreateForItem        = "📋 СОЗДАТЬ ЗАЯВКУ НА ЭТОТ АППАРАТ"
-	btnAllBookings          = "👨‍💼 Все заявки"
-	btnCreateBookingManager = "➕ Создать заявку (Менеджер)"
-	btnSyncBookings         = "🔄 Синхронизировать бронирования (Google Sheets)"
-	btnSyncSchedule         = "📅 Синхронизировать расписание (Google Sheets)"
-	btnConfirmCreate        = "✅ Подтвердить создание"
+	btnCancel               = "btn.cancel"
+	btnBack                 = "btn.back"
+	btnCreateBooking        = "btn.create_booking"
+	btnMyBookings           = "btn.my_bookings"
+	btnManagerContacts      = "btn.manager_contacts"
+	btnAvailableItems       = "btn.available_items"
+	btnViewSchedule         = "btn.view_schedule"
+	btnMonthSchedule        = "btn.month_schedule"
+	btnPickDate             = "btn.pick_date"
+	btnBackToItems          = "btn.back_to_items"
+	btnCreateForItem        = "btn.create_for_item"
+	btnAllBookings          = "btn.all_bookings"
+	btnSearchBookings       = "btn.search_bookings"
+	btnCreateBookingManager = "btn.create_booking_manager"
+	btnSyncBookings         = "btn.sync_bookings"
+	btnSyncSchedule         = "btn.sync_schedule"
+	btnConfirmCreate        = "btn.confirm_create"
+	btnConsentAccept        = "btn.consent_accept"
+)
 
+const (
 	statusSuccess = "✅"
 	statusPending = "⏳"
 	statusError   = "❌"
 	typeSingle    = "single"
+	typeRange     = "range"
+
+	msgAccessDenied = "error.access_denied"
 )
 
 // Bot represents the Telegram bot instance and its dependencies.
@@ -49,6 +60,7 @@ type Bot struct {
 	bookingService domain.BookingService
 	userService    domain.UserService
 	itemService    domain.ItemService
+	i18n           *i18n.Bundle
 	metrics        *Metrics
 	logger         *zerolog.Logger
 }
@@ -76,6 +88,15 @@ func NewBot(
 		logger = &l
 	}
 
+	var defaultLang, localesDir string
+	if config != nil {
+		defaultLang, localesDir = config.Bot.DefaultLanguage, config.Bot.LocalesDir
+	}
+	bundle, err := i18n.New(defaultLang, localesDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load message catalogs: %w", err)
+	}
+
 	return &Bot{
 		tgService:      tgService,
 		config:         config,
@@ -86,12 +107,14 @@ func NewBot(
 		bookingService: bookingService,
 		userService:    userService,
 		itemService:    itemService,
+		i18n:           bundle,
 		metrics:        metrics,
 		logger:         logger,
 	}, nil
 }
 
-// Start begins the bot's update polling loop.
+// Start begins the bot's update polling loop. Updates are processed concurrently,
+// in order within a chat; on shutdown the accepted updates are drained.
 func (b *Bot) Start(ctx context.Context) {
 	u := tgbotapi.NewUpdate(0)
 	u.Timeout = 60
@@ -103,6 +126,19 @@ func (b *Bot) Start(ctx context.Context) {
 	// Start metrics updater
 	go b.startMetricsUpdater(ctx)
 
+	var cfg config.DispatcherConfig
+	if b.config != nil {
+		cfg = b.config.Bot.Dispatcher
+	}
+	d := newDispatcher(cfg, b.processUpdate, b.metrics, b.logger)
+	// Принятые обновления дорабатываются после остановки, поэтому их контекст не отменяется вместе с ctx
+	d.start(context.WithoutCancel(ctx))
+	defer func() {
+		if d.stop() {
+			b.logger.Info().Msg("Bot stopped, pending updates processed")
+		}
+	}()
+
 	for {
 		select {
 		case <-ctx.Done():
@@ -112,7 +148,10 @@ func (b *Bot) Start(ctx context.Context) {
 			if !ok {
 				return
 			}
-			b.processUpdate(ctx, &update)
+			if !d.submit(ctx, &update) {
+				b.logger.Info().Msg("Bot stopping...")
+				return
+			}
 		}
 	}
 }
@@ -136,10 +175,16 @@ func (b *Bot) processUpdate(ctx context.Context, update *tgbotapi.Update) {
 
 	b.withRecovery(func() {
 		var userID int64
+		var languageCode string
 		if update.Message != nil {
 			userID = update.Message.From.ID
+			languageCode = update.Message.From.LanguageCode
 		} else if update.CallbackQuery != nil {
 			userID = update.CallbackQuery.From.ID
+			languageCode = update.CallbackQuery.From.LanguageCode
+		} else if update.InlineQuery != nil {
+			userID = update.InlineQuery.From.ID
+			languageCode = update.InlineQuery.From.LanguageCode
 		}
 
 		if userID == 0 {
@@ -149,7 +194,10 @@ func (b *Bot) processUpdate(ctx context.Context, update *tgbotapi.Update) {
 		// Track activity
 		b.trackActivity(userID)
 
-		if !b.isManager(userID) {
+		updateCtx = models.WithAuditActor(updateCtx, models.AuditActor{ID: userID, Source: models.AuditSourceBot})
+		updateCtx = i18n.WithLanguage(updateCtx, b.userLanguage(updateCtx, userID, languageCode))
+
+		if !b.isStaff(userID) {
 			window := time.Duration(b.config.Bot.RateLimitWindow) * time.Second
 			allowed, err := b.stateService.CheckRateLimit(updateCtx, userID, b.config.Bot.RateLimitMessages, window)
 			if err != nil {
@@ -157,9 +205,9 @@ func (b *Bot) processUpdate(ctx context.Context, update *tgbotapi.Update) {
 			} else if !allowed {
 				b.logger.Warn().Int64("user_id", userID).Msg("Rate limit exceeded")
 				if update.Message != nil {
-					b.sendMessage(update.Message.Chat.ID, "⚠️ Вы отправляете сообщения слишком часто. Пожалуйста, подождите немного.")
+					b.sendMessage(update.Message.Chat.ID, b.t(updateCtx, "rate_limit.message"))
 				} else if update.CallbackQuery != nil {
-					callbackConfig := tgbotapi.NewCallback(update.CallbackQuery.ID, "⚠️ Слишком много запросов. Подождите немного.")
+					callbackConfig := tgbotapi.NewCallback(update.CallbackQuery.ID, b.t(updateCtx, "rate_limit.callback"))
 					callbackConfig.ShowAlert = true
 					_, _ = b.tgService.Request(callbackConfig)
 				}
@@ -172,11 +220,16 @@ func (b *Bot) processUpdate(ctx context.Context, update *tgbotapi.Update) {
 			return
 		}
 
+		if update.InlineQuery != nil {
+			b.handleInlineQuery(updateCtx, update.InlineQuery)
+			return
+		}
+
 		if update.Message == nil {
 			return
 		}
 
-		if b.isBlacklisted(update.Message.From.ID) {
+		if b.denyIfBlacklisted(updateCtx, update.Message.Chat.ID, update.Message.From.ID) {
 			return
 		}
 
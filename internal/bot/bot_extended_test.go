@@ -36,7 +36,7 @@ func TestHandleManagerCommand(t *testing.T) {
 		{"Stats Cmd", "/stats", "Статистика"},
 		{"Sync Bookings", "🔄 Синхронизировать бронирования (Google Sheets)", "Запускаю фоновую синхронизацию"},
 		{"Sync Schedule", "📅 Синхронизировать расписание (Google Sheets)", "Запускаю фоновую синхронизацию"},
-		{"Add Item Prompt", "/add_item", "/add_item <название> <количество>"},
+		{"Add Item Prompt", "/add_item", "Введите название аппарата"},
 		{"List Items Prompt", "/list_items", "Список активных аппаратов"},
 	}
 
@@ -195,7 +195,7 @@ func TestUtils_More(t *testing.T) {
 				},
 			},
 		}
-		b.editManagerItemsPage(&update, 0)
+		b.editManagerItemsPage(context.Background(), &update, 0)
 		// Should not panic
 	})
 }
@@ -2,16 +2,28 @@ package bot
 
 import (
 	"context"
+	"database/sql"
 	"errors"
+	"fmt"
 	"io"
+	"net/http"
+	"net/http/httptest"
 	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
 	"sync"
 	"testing"
 	"time"
 
+	"bronivik/internal/analytics"
 	"bronivik/internal/config"
 	"bronivik/internal/database"
 	"bronivik/internal/domain"
+	"bronivik/internal/extcal"
+	"bronivik/internal/i18n"
 	"bronivik/internal/models"
 
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
@@ -19,6 +31,7 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
+	"github.com/xuri/excelize/v2"
 )
 
 type botMocks struct {
@@ -75,6 +88,9 @@ type mockTelegramService struct {
 	domain.TelegramService
 	updatesChan  chan tgbotapi.Update
 	sentMessages []tgbotapi.Chattable
+	requests     []tgbotapi.Chattable
+	editedTexts  []string
+	files        map[string]string // file ID -> download URL
 	mu           sync.RWMutex
 }
 
@@ -93,10 +109,29 @@ func (m *mockTelegramService) GetSelf() tgbotapi.User {
 	return tgbotapi.User{UserName: "test_bot"}
 }
 
+func (m *mockTelegramService) GetFileDirectURL(fileID string) (string, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	url, ok := m.files[fileID]
+	if !ok {
+		return "", errors.New("file not found")
+	}
+	return url, nil
+}
+
 func (m *mockTelegramService) Request(c tgbotapi.Chattable) (*tgbotapi.APIResponse, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.requests = append(m.requests, c)
 	return &tgbotapi.APIResponse{Ok: true}, nil
 }
 
+func (m *mockTelegramService) getRequests() []tgbotapi.Chattable {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return append([]tgbotapi.Chattable(nil), m.requests...)
+}
+
 func (m *mockTelegramService) SendMessage(chatID int64, text string) (tgbotapi.Message, error) {
 	m.mu.Lock()
 	defer m.mu.Unlock()
@@ -153,6 +188,9 @@ func (m *mockTelegramService) EditMessage(
 	text string,
 	keyboard *tgbotapi.InlineKeyboardMarkup,
 ) (tgbotapi.Message, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.editedTexts = append(m.editedTexts, text)
 	return tgbotapi.Message{}, nil
 }
 
@@ -228,12 +266,29 @@ type mockUserService struct {
 	mock.Mock
 	domain.UserService
 	users               map[int64]*models.User
+	roles               map[int64]*models.UserRole
+	withoutConsent      map[int64]bool
 	saveError           error
 	updateActivityError error
 	updatePhoneError    error
+	feeds               []*models.CalendarFeed
 	mu                  sync.RWMutex
 }
 
+func (m *mockUserService) ImportUsers(ctx context.Context, users []*models.User, dryRun bool) error {
+	if dryRun {
+		return nil
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, u := range users {
+		if _, ok := m.users[u.TelegramID]; !ok {
+			m.users[u.TelegramID] = u
+		}
+	}
+	return nil
+}
+
 func (m *mockUserService) SaveUser(ctx context.Context, user *models.User) error {
 	m.mu.Lock()
 	defer m.mu.Unlock()
@@ -266,6 +321,49 @@ func (m *mockUserService) UpdateUserPhone(ctx context.Context, telegramID int64,
 	return nil
 }
 
+func (m *mockUserService) GetLanguage(ctx context.Context, telegramID int64) string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	if u, ok := m.users[telegramID]; ok {
+		if u.PreferredLanguage != "" {
+			return u.PreferredLanguage
+		}
+		return u.LanguageCode
+	}
+	return ""
+}
+
+func (m *mockUserService) SetLanguage(ctx context.Context, telegramID int64, lang string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if u, ok := m.users[telegramID]; ok {
+		u.PreferredLanguage = lang
+		return nil
+	}
+	m.users[telegramID] = &models.User{TelegramID: telegramID, PreferredLanguage: lang}
+	return nil
+}
+
+func (m *mockUserService) RemindersEnabled(ctx context.Context, telegramID int64) bool {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	if u, ok := m.users[telegramID]; ok {
+		return !u.RemindersDisabled
+	}
+	return true
+}
+
+func (m *mockUserService) SetRemindersEnabled(ctx context.Context, telegramID int64, enabled bool) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if u, ok := m.users[telegramID]; ok {
+		u.RemindersDisabled = !enabled
+		return nil
+	}
+	m.users[telegramID] = &models.User{TelegramID: telegramID, RemindersDisabled: !enabled}
+	return nil
+}
+
 func (m *mockUserService) IsManager(userID int64) bool {
 	m.mu.RLock()
 	defer m.mu.RUnlock()
@@ -275,6 +373,75 @@ func (m *mockUserService) IsManager(userID int64) bool {
 	return false
 }
 
+// GetRole: менеджеры из users считаются администраторами, остальные роли берутся из roles
+func (m *mockUserService) GetRole(userID int64) string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	if r, ok := m.roles[userID]; ok {
+		return r.Role
+	}
+	if u, ok := m.users[userID]; ok && u.IsManager {
+		return models.RoleAdmin
+	}
+	return ""
+}
+
+func (m *mockUserService) HasPermission(userID int64, perm string) bool {
+	role := m.GetRole(userID)
+	return role != "" && models.RoleHasPermission(role, perm)
+}
+
+func (m *mockUserService) CanManageItem(userID, itemID int64) bool {
+	if !m.HasPermission(userID, models.PermManageBookings) {
+		return false
+	}
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	if r, ok := m.roles[userID]; ok {
+		return r.CoversItem(itemID)
+	}
+	return true
+}
+
+func (m *mockUserService) ItemScope(userID int64) ([]int64, bool) {
+	if !m.HasPermission(userID, models.PermManageBookings) {
+		return nil, true
+	}
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	if r, ok := m.roles[userID]; ok && r.Role != models.RoleAdmin && len(r.ItemIDs) > 0 {
+		return append([]int64(nil), r.ItemIDs...), true
+	}
+	return nil, false
+}
+
+func (m *mockUserService) GetStaffForItem(itemID int64, perm string) []int64 {
+	m.mu.RLock()
+	ids := make([]int64, 0, len(m.users)+len(m.roles))
+	for id := range m.users {
+		ids = append(ids, id)
+	}
+	for id := range m.roles {
+		if _, ok := m.users[id]; !ok {
+			ids = append(ids, id)
+		}
+	}
+	m.mu.RUnlock()
+
+	result := make([]int64, 0, len(ids))
+	for _, id := range ids {
+		if m.HasPermission(id, perm) && (perm != models.PermManageBookings || m.CanManageItem(id, itemID)) {
+			result = append(result, id)
+		}
+	}
+	return result
+}
+
+func (m *mockUserService) SetUserRole(ctx context.Context, role *models.UserRole) error {
+	args := m.Called(ctx, role)
+	return args.Error(0)
+}
+
 func (m *mockUserService) IsBlacklisted(userID int64) bool {
 	m.mu.RLock()
 	defer m.mu.RUnlock()
@@ -284,6 +451,51 @@ func (m *mockUserService) IsBlacklisted(userID int64) bool {
 	return false
 }
 
+func (m *mockUserService) GetBlacklistInfo(userID int64) (*models.User, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	if u, ok := m.users[userID]; ok && u.IsBlockedAt(time.Now()) {
+		info := *u
+		return &info, true
+	}
+	return nil, false
+}
+
+func (m *mockUserService) BlockUser(ctx context.Context, telegramID int64, reason string, until time.Time, blockedBy int64) error {
+	args := m.Called(ctx, telegramID, reason, until, blockedBy)
+	return args.Error(0)
+}
+
+// HasConsent: по умолчанию согласие считается данным, чтобы не мешать тестам сценария бронирования
+func (m *mockUserService) HasConsent(ctx context.Context, telegramID int64) bool {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return !m.withoutConsent[telegramID]
+}
+
+func (m *mockUserService) GiveConsent(ctx context.Context, telegramID int64) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.withoutConsent, telegramID)
+	return nil
+}
+
+func (m *mockUserService) ExportPersonalData(ctx context.Context, telegramID int64) (*models.PersonalDataExport, error) {
+	args := m.Called(ctx, telegramID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.PersonalDataExport), args.Error(1)
+}
+
+func (m *mockUserService) ForgetUser(ctx context.Context, telegramID int64) ([]*models.Booking, error) {
+	args := m.Called(ctx, telegramID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.Booking), args.Error(1)
+}
+
 func (m *mockUserService) GetManagers(ctx context.Context) ([]*models.User, error) {
 	m.mu.RLock()
 	defer m.mu.RUnlock()
@@ -339,6 +551,34 @@ func (m *mockUserService) GetUserByID(ctx context.Context, id int64) (*models.Us
 	return nil, errors.New("not found")
 }
 
+func (m *mockUserService) GetCalendarFeed(ctx context.Context, scope string, subjectID, createdBy int64) (*models.CalendarFeed, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, f := range m.feeds {
+		if f.Scope == scope && f.SubjectID == subjectID && !f.IsRevoked() {
+			return f, nil
+		}
+	}
+	return m.issueFeed(scope, subjectID, createdBy), nil
+}
+
+func (m *mockUserService) ResetCalendarFeed(ctx context.Context, scope string, subjectID, createdBy int64) (*models.CalendarFeed, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, f := range m.feeds {
+		if f.Scope == scope && f.SubjectID == subjectID {
+			f.RevokedAt = sql.NullTime{Time: time.Now(), Valid: true}
+		}
+	}
+	return m.issueFeed(scope, subjectID, createdBy), nil
+}
+
+func (m *mockUserService) issueFeed(scope string, subjectID, createdBy int64) *models.CalendarFeed {
+	feed := &models.CalendarFeed{ID: int64(len(m.feeds) + 1), Scope: scope, SubjectID: subjectID, CreatedBy: createdBy}
+	m.feeds = append(m.feeds, feed)
+	return feed
+}
+
 func (m *mockUserService) GetAllUsers(ctx context.Context) ([]*models.User, error) {
 	m.mu.RLock()
 	defer m.mu.RUnlock()
@@ -380,8 +620,11 @@ func (m *mockUserService) getUsers() map[int64]*models.User {
 
 type mockItemService struct {
 	domain.ItemService
-	items []*models.Item
-	mu    sync.RWMutex
+	items       []*models.Item
+	maintenance []*models.MaintenanceWindow
+	overflow    []*models.MaintenanceOverflow
+	units       []*models.ItemUnit
+	mu          sync.RWMutex
 }
 
 func (m *mockItemService) GetActiveItems(ctx context.Context) ([]*models.Item, error) {
@@ -409,7 +652,20 @@ func (m *mockItemService) GetItemByName(ctx context.Context, name string) (*mode
 			return item, nil
 		}
 	}
-	return nil, errors.New("not found")
+	return nil, fmt.Errorf("not found: %w", sql.ErrNoRows)
+}
+
+func (m *mockItemService) ImportItems(ctx context.Context, items []*models.Item, dryRun bool) error {
+	if dryRun {
+		return nil
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, item := range items {
+		item.ID = int64(len(m.items) + 1)
+		m.items = append(m.items, item)
+	}
+	return nil
 }
 
 func (m *mockItemService) CreateItem(ctx context.Context, item *models.Item) error {
@@ -456,6 +712,160 @@ func (m *mockItemService) ReorderItem(ctx context.Context, id, order int64) erro
 	return errors.New("not found")
 }
 
+func (m *mockItemService) AddMaintenance(
+	ctx context.Context,
+	window *models.MaintenanceWindow,
+) ([]*models.MaintenanceOverflow, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if window.EndDate.Before(window.StartDate) {
+		return nil, models.ErrInvalidMaintenancePeriod
+	}
+	window.ID = int64(len(m.maintenance) + 1)
+	m.maintenance = append(m.maintenance, window)
+	return m.overflow, nil
+}
+
+func (m *mockItemService) GetMaintenance(ctx context.Context, id int64) (*models.MaintenanceWindow, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	for _, w := range m.maintenance {
+		if w.ID == id {
+			return w, nil
+		}
+	}
+	return nil, models.ErrMaintenanceNotFound
+}
+
+func (m *mockItemService) EndMaintenance(ctx context.Context, id, actorID int64) (*models.MaintenanceWindow, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for i, w := range m.maintenance {
+		if w.ID == id {
+			if w.IsExternalHold() {
+				return nil, models.ErrExternalHold
+			}
+			m.maintenance = append(m.maintenance[:i], m.maintenance[i+1:]...)
+			return w, nil
+		}
+	}
+	return nil, models.ErrMaintenanceNotFound
+}
+
+func (m *mockItemService) GetMaintenanceWindows(
+	ctx context.Context,
+	itemID int64,
+	start, end time.Time,
+) ([]*models.MaintenanceWindow, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	var windows []*models.MaintenanceWindow
+	for _, w := range m.maintenance {
+		if (itemID == 0 || w.ItemID == itemID) && !w.StartDate.After(end) && !w.EndDate.Before(start) {
+			windows = append(windows, w)
+		}
+	}
+	return windows, nil
+}
+
+func (m *mockItemService) SyncExternalHolds(
+	ctx context.Context,
+	source string,
+	windows []*models.MaintenanceWindow,
+) ([]*models.MaintenanceOverflow, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	kept := m.maintenance[:0]
+	for _, w := range m.maintenance {
+		if w.Source != source {
+			kept = append(kept, w)
+		}
+	}
+	m.maintenance = kept
+	for _, w := range windows {
+		w.ID = int64(len(m.maintenance) + 100)
+		m.maintenance = append(m.maintenance, w)
+	}
+	return m.overflow, nil
+}
+
+func (m *mockItemService) AddUnit(ctx context.Context, unit *models.ItemUnit, actorID int64) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, u := range m.units {
+		if u.SerialNumber == unit.SerialNumber {
+			return models.ErrDuplicateSerial
+		}
+	}
+	if unit.Status == "" {
+		unit.Status = models.UnitStatusActive
+	}
+	unit.ID = int64(len(m.units) + 1)
+	m.units = append(m.units, unit)
+	return nil
+}
+
+func (m *mockItemService) SetUnitStatus(
+	ctx context.Context,
+	serial, status, note string,
+	actorID int64,
+) (*models.ItemUnit, error) {
+	if !models.ValidUnitStatus(status) {
+		return nil, models.ErrInvalidUnitStatus
+	}
+	unit, err := m.GetUnitBySerial(ctx, serial)
+	if err != nil {
+		return nil, err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	unit.Status = status
+	unit.Note = note
+	return unit, nil
+}
+
+func (m *mockItemService) GetUnit(ctx context.Context, id int64) (*models.ItemUnit, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	for _, u := range m.units {
+		if u.ID == id {
+			return u, nil
+		}
+	}
+	return nil, models.ErrUnitNotFound
+}
+
+func (m *mockItemService) GetUnitBySerial(ctx context.Context, serial string) (*models.ItemUnit, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	for _, u := range m.units {
+		if u.SerialNumber == serial {
+			return u, nil
+		}
+	}
+	return nil, models.ErrUnitNotFound
+}
+
+func (m *mockItemService) GetItemUnits(ctx context.Context, itemID int64) ([]*models.ItemUnit, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	var units []*models.ItemUnit
+	for _, u := range m.units {
+		if u.ItemID == itemID {
+			units = append(units, u)
+		}
+	}
+	return units, nil
+}
+
+func (m *mockItemService) GetUnitHistory(ctx context.Context, serial string) (*models.UnitHistory, error) {
+	unit, err := m.GetUnitBySerial(ctx, serial)
+	if err != nil {
+		return nil, err
+	}
+	return &models.UnitHistory{Unit: unit}, nil
+}
+
 func (m *mockItemService) setItems(items []*models.Item) {
 	m.mu.Lock()
 	defer m.mu.Unlock()
@@ -465,9 +875,13 @@ func (m *mockItemService) setItems(items []*models.Item) {
 type mockBookingService struct {
 	mock.Mock
 	domain.BookingService
-	available bool
-	bookings  map[int64]*models.Booking
-	mu        sync.RWMutex
+	available   bool
+	bookings    map[int64]*models.Booking
+	fullyBooked map[string]bool
+	escalations map[int64]*models.BookingEscalation
+	reminders   map[string]bool
+	handovers   map[int64]*models.Handover
+	mu          sync.RWMutex
 }
 
 func (m *mockBookingService) CheckAvailability(ctx context.Context, itemID int64, date time.Time) (bool, error) {
@@ -522,7 +936,16 @@ func (m *mockBookingService) ValidateBookingDate(date time.Time) error {
 }
 
 func (m *mockBookingService) GetDailyBookings(ctx context.Context, start, end time.Time) (map[string][]*models.Booking, error) {
-	return nil, nil
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	daily := make(map[string][]*models.Booking)
+	for _, b := range m.bookings {
+		day := b.Date.Format("2006-01-02")
+		if day >= start.Format("2006-01-02") && day <= end.Format("2006-01-02") {
+			daily[day] = append(daily[day], b)
+		}
+	}
+	return daily, nil
 }
 
 func (m *mockBookingService) GetBookedCount(ctx context.Context, itemID int64, date time.Time) (int, error) {
@@ -559,6 +982,37 @@ func (m *mockBookingService) ReopenBooking(ctx context.Context, bookingID, versi
 	return nil
 }
 
+func (m *mockBookingService) ApplyBulkAction(
+	ctx context.Context,
+	action string,
+	bookings []*models.Booking,
+	managerID int64,
+) (*models.BulkResult, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	statuses := map[string]string{
+		models.BulkActionConfirm:  models.StatusConfirmed,
+		models.BulkActionReject:   models.StatusCanceled,
+		models.BulkActionComplete: models.StatusCompleted,
+	}
+	result := &models.BulkResult{}
+	for _, booking := range bookings {
+		stored, ok := m.bookings[booking.ID]
+		switch {
+		case !ok || !models.BulkActionAllowed(action, booking.Status):
+			result.Skipped = append(result.Skipped, booking)
+		case stored.Version != booking.Version:
+			result.Conflicts = append(result.Conflicts, booking)
+		default:
+			stored.Status = statuses[action]
+			stored.Version++
+			result.Succeeded = append(result.Succeeded, booking)
+		}
+	}
+	return result, nil
+}
+
 func (m *mockBookingService) ChangeBookingItem(ctx context.Context, bookingID, version, newItemID, managerID int64) error {
 	m.mu.Lock()
 	defer m.mu.Unlock()
@@ -626,7 +1080,57 @@ func (m *mockBookingService) GetAvailability(
 	startDate time.Time,
 	days int,
 ) ([]*models.Availability, error) {
-	return []*models.Availability{{Date: startDate, Available: 1}}, nil
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	result := make([]*models.Availability, 0, days)
+	for i := 0; i < days; i++ {
+		date := startDate.AddDate(0, 0, i)
+		available := int64(1)
+		if m.fullyBooked[date.Format("2006-01-02")] {
+			available = 0
+		}
+		result = append(result, &models.Availability{Date: date, ItemID: itemID, Available: available})
+	}
+	return result, nil
+}
+
+func (m *mockBookingService) SearchBookings(ctx context.Context, filter models.BookingFilter) ([]*models.Booking, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	result := make([]*models.Booking, 0)
+	for _, b := range m.bookings {
+		switch {
+		case filter.BookingID != 0 && b.ID != filter.BookingID,
+			len(filter.Statuses) > 0 && !slices.Contains(filter.Statuses, b.Status),
+			len(filter.ItemIDs) > 0 && !slices.Contains(filter.ItemIDs, b.ItemID),
+			!filter.DateFrom.IsZero() && b.Date.Before(filter.DateFrom),
+			!filter.DateTo.IsZero() && b.Date.After(filter.DateTo),
+			filter.Phone != "" && !strings.Contains(b.Phone, filter.Phone),
+			filter.ClientName != "" && !strings.Contains(strings.ToLower(b.UserName), strings.ToLower(filter.ClientName)):
+			continue
+		}
+		result = append(result, b)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].ID < result[j].ID })
+	// Как и база, поиск отдает не больше Limit заявок
+	if filter.Offset > 0 {
+		result = result[min(filter.Offset, len(result)):]
+	}
+	if filter.Limit > 0 && len(result) > filter.Limit {
+		result = result[:filter.Limit]
+	}
+	return result, nil
+}
+
+func (m *mockBookingService) StreamBookings(ctx context.Context, filter models.BookingFilter, fn func(*models.Booking) error) error {
+	bookings, _ := m.SearchBookings(ctx, filter)
+	for _, b := range bookings {
+		if err := fn(b); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
 func (m *mockBookingService) RejectBooking(ctx context.Context, bookingID, version, managerID int64) error {
@@ -638,27 +1142,199 @@ func (m *mockBookingService) RejectBooking(ctx context.Context, bookingID, versi
 	return nil
 }
 
-func (m *mockBookingService) getBookings() map[int64]*models.Booking {
+func (m *mockBookingService) GetBookingHistory(ctx context.Context, bookingID int64) ([]*models.AuditEntry, error) {
+	args := m.Called(ctx, bookingID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.AuditEntry), args.Error(1)
+}
+
+func (m *mockBookingService) GetBookingEscalations(ctx context.Context, ids []int64) (map[int64]*models.BookingEscalation, error) {
 	m.mu.RLock()
 	defer m.mu.RUnlock()
-	cp := make(map[int64]*models.Booking)
-	for k, v := range m.bookings {
-		cp[k] = v
+	result := make(map[int64]*models.BookingEscalation)
+	for _, id := range ids {
+		if e, ok := m.escalations[id]; ok {
+			cp := *e
+			result[id] = &cp
+		}
 	}
-	return cp
+	return result, nil
 }
 
-func (m *mockBookingService) setBookings(bookings map[int64]*models.Booking) {
+func (m *mockBookingService) SaveBookingEscalation(ctx context.Context, e *models.BookingEscalation) error {
 	m.mu.Lock()
 	defer m.mu.Unlock()
-	m.bookings = bookings
-}
-
-type mockSheetsWriter struct {
-	domain.SheetsWriter
+	if m.escalations == nil {
+		m.escalations = make(map[int64]*models.BookingEscalation)
+	}
+	cp := *e
+	m.escalations[e.BookingID] = &cp
+	return nil
 }
 
-func (m *mockSheetsWriter) AppendBooking(ctx context.Context, booking *models.Booking) error {
+func (m *mockBookingService) ClaimReminder(ctx context.Context, bookingID int64, key string) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.reminders == nil {
+		m.reminders = make(map[string]bool)
+	}
+	claim := fmt.Sprintf("%d:%s", bookingID, key)
+	if m.reminders[claim] {
+		return false, nil
+	}
+	m.reminders[claim] = true
+	return true, nil
+}
+
+func (m *mockBookingService) ReleaseReminder(ctx context.Context, bookingID int64, key string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.reminders, fmt.Sprintf("%d:%s", bookingID, key))
+	return nil
+}
+
+func (m *mockBookingService) CheckOutBooking(
+	ctx context.Context,
+	bookingID, managerID int64,
+	note string,
+	photos []string,
+) (*models.Handover, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	b, ok := m.bookings[bookingID]
+	if !ok || (b.Status != models.StatusConfirmed && b.Status != models.StatusChanged) {
+		return nil, models.ErrHandoverNotAllowed
+	}
+	if m.handovers == nil {
+		m.handovers = make(map[int64]*models.Handover)
+	}
+	if _, ok := m.handovers[bookingID]; ok {
+		return nil, models.ErrAlreadyCheckedOut
+	}
+	h := &models.Handover{
+		ID: int64(len(m.handovers) + 1), BookingID: bookingID, UserID: b.UserID, UserName: b.UserName,
+		ItemID: b.ItemID, ItemName: b.ItemName, PlannedStart: b.Date, PlannedEnd: b.Date,
+		CheckedOutAt: time.Now(), CheckedOutBy: managerID, CheckOutNote: note, CheckOutPhotos: photos,
+	}
+	m.handovers[bookingID] = h
+	return h, nil
+}
+
+func (m *mockBookingService) CheckInBooking(
+	ctx context.Context,
+	bookingID, managerID int64,
+	note string,
+	photos []string,
+) (*models.Handover, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	h, ok := m.handovers[bookingID]
+	if !ok || h.IsReturned() {
+		return nil, models.ErrNotCheckedOut
+	}
+	h.CheckedInAt = sql.NullTime{Time: time.Now(), Valid: true}
+	h.CheckedInBy = managerID
+	h.CheckInNote = note
+	h.CheckInPhotos = photos
+	if b, ok := m.bookings[bookingID]; ok {
+		b.Status = models.StatusCompleted
+	}
+	return h, nil
+}
+
+func (m *mockBookingService) GetBookingHandover(ctx context.Context, booking *models.Booking) (*models.Handover, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.handovers[booking.ID], nil
+}
+
+func (m *mockBookingService) GetBookingQuote(ctx context.Context, booking *models.Booking) (*models.BookingQuote, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	run := []*models.Booking{booking}
+	for _, b := range m.bookings {
+		if b.ID != booking.ID && b.UserID == booking.UserID && b.ItemID == booking.ItemID && b.Phone == booking.Phone {
+			run = append(run, b)
+		}
+	}
+	sort.Slice(run, func(i, j int) bool { return run[i].Date.Before(run[j].Date) })
+	return models.NewBookingQuote(run), nil
+}
+
+func (m *mockBookingService) GetOpenHandovers(ctx context.Context) ([]*models.Handover, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	var result []*models.Handover
+	for _, h := range m.handovers {
+		if !h.IsReturned() {
+			result = append(result, h)
+		}
+	}
+	return result, nil
+}
+
+func (m *mockBookingService) GetHandoversByPeriod(ctx context.Context, start, end time.Time) ([]*models.Handover, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	var result []*models.Handover
+	for _, h := range m.handovers {
+		result = append(result, h)
+	}
+	return result, nil
+}
+
+func (m *mockBookingService) MarkHandoverOverdueNotified(ctx context.Context, id int64, at time.Time) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, h := range m.handovers {
+		if h.ID == id {
+			h.OverdueNotifiedAt = sql.NullTime{Time: at, Valid: true}
+		}
+	}
+	return nil
+}
+
+func (m *mockBookingService) getBookings() map[int64]*models.Booking {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	cp := make(map[int64]*models.Booking)
+	for k, v := range m.bookings {
+		cp[k] = v
+	}
+	return cp
+}
+
+// ImportBookings отклоняет заявки на даты из fullyBooked
+func (m *mockBookingService) ImportBookings(ctx context.Context, bookings []*models.Booking, dryRun bool) ([]error, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	rejected := make([]error, len(bookings))
+	for i, b := range bookings {
+		if m.fullyBooked[b.Date.Format("2006-01-02")] {
+			rejected[i] = database.ErrNotAvailable
+			continue
+		}
+		if !dryRun {
+			b.ID = int64(len(m.bookings) + 1)
+			m.bookings[b.ID] = b
+		}
+	}
+	return rejected, nil
+}
+
+func (m *mockBookingService) setBookings(bookings map[int64]*models.Booking) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.bookings = bookings
+}
+
+type mockSheetsWriter struct {
+	domain.SheetsWriter
+}
+
+func (m *mockSheetsWriter) AppendBooking(ctx context.Context, booking *models.Booking) error {
 	return nil
 }
 
@@ -666,6 +1342,7 @@ func (m *mockSheetsWriter) UpdateScheduleSheet(
 	ctx context.Context,
 	startDate, endDate time.Time,
 	bookings map[string][]*models.Booking,
+	maintenance []*models.MaintenanceWindow,
 	items []*models.Item,
 ) error {
 	return nil
@@ -691,6 +1368,10 @@ func (m *mockSheetsWriter) UpdateBookingStatus(ctx context.Context, bookingID in
 	return nil
 }
 
+func (m *mockSheetsWriter) UpdateHandoversSheet(ctx context.Context, handovers []*models.Handover) error {
+	return nil
+}
+
 type mockSyncWorker struct {
 	domain.SyncWorker
 }
@@ -1382,7 +2063,7 @@ func TestHandleBack(t *testing.T) {
 }
 
 func TestGetErrorMessage(t *testing.T) {
-	b := &Bot{}
+	b, _ := setupTestBot()
 	tests := []struct {
 		err      error
 		expected string
@@ -1397,7 +2078,7 @@ func TestGetErrorMessage(t *testing.T) {
 	}
 
 	for _, tt := range tests {
-		if got := b.getErrorMessage(tt.err); got != tt.expected {
+		if got := b.getErrorMessage(context.Background(), tt.err); got != tt.expected {
 			t.Errorf("getErrorMessage(%v) = %v, want %v", tt.err, got, tt.expected)
 		}
 	}
@@ -1417,93 +2098,111 @@ func TestManagerBookingFlow(t *testing.T) {
 	ctx := context.Background()
 	managerID := int64(123) // From config.Managers
 
-	// 1. Start Manager Booking
-	update := tgbotapi.Update{
-		Message: &tgbotapi.Message{
+	say := func(text string) {
+		b.handleMessage(ctx, &tgbotapi.Update{Message: &tgbotapi.Message{
 			From: &tgbotapi.User{ID: managerID},
 			Chat: &tgbotapi.Chat{ID: managerID},
-			Text: "/start_booking",
-		},
+			Text: text,
+		}})
+	}
+	press := func(data string) {
+		b.handleCallbackQuery(ctx, &tgbotapi.Update{CallbackQuery: &tgbotapi.CallbackQuery{
+			From:    &tgbotapi.User{ID: managerID},
+			Message: &tgbotapi.Message{Chat: &tgbotapi.Chat{ID: managerID}, MessageID: 456},
+			Data:    data,
+		}})
 	}
 
-	b.startManagerBooking(ctx, &update)
+	// 1. Start Manager Booking
+	b.startManagerBooking(ctx, &tgbotapi.Update{Message: &tgbotapi.Message{
+		From: &tgbotapi.User{ID: managerID},
+		Chat: &tgbotapi.Chat{ID: managerID},
+	}})
 
 	state := b.getUserState(ctx, managerID)
 	assert.NotNil(t, state)
 	assert.Equal(t, models.StateManagerWaitingClientName, state.CurrentStep)
-	assert.True(t, state.TempData["is_manager_booking"].(bool))
+	assert.False(t, state.GetTime(dialogStepAtKey).IsZero())
 	assert.Len(t, mocks.tg.getSentMessages(), 1)
 
-	// 2. Handle Client Name
-	mocks.tg.clearSentMessages()
-	b.handleManagerClientName(ctx, &update, "John Doe", state)
-
+	// 2. Client Name
+	say("John Doe")
 	state = b.getUserState(ctx, managerID)
 	assert.Equal(t, models.StateManagerWaitingClientPhone, state.CurrentStep)
 	assert.Equal(t, "John Doe", state.TempData["client_name"])
 
-	// 3. Handle Client Phone
+	// 3. Client Phone: invalid phone keeps the step
 	mocks.tg.clearSentMessages()
-	b.handleManagerClientPhone(ctx, &update, "+79991234567", state)
+	say("abc")
+	state = b.getUserState(ctx, managerID)
+	assert.Equal(t, models.StateManagerWaitingClientPhone, state.CurrentStep)
+	sent := mocks.tg.getSentMessages()
+	require.Len(t, sent, 1)
+	assert.Equal(t, b.t(ctx, "error.invalid_phone"), sent[0].(tgbotapi.MessageConfig).Text)
 
+	say("+79991234567")
 	state = b.getUserState(ctx, managerID)
 	assert.Equal(t, models.StateManagerWaitingItemSelection, state.CurrentStep)
 	assert.Equal(t, "79991234567", state.TempData["client_phone"])
 
-	// 4. Handle Item Selection
+	// 4. Item Selection: typed text is rejected, the button is accepted
 	mocks.tg.clearSentMessages()
-	callbackUpdate := tgbotapi.Update{
-		CallbackQuery: &tgbotapi.CallbackQuery{
-			From: &tgbotapi.User{ID: managerID},
-			Message: &tgbotapi.Message{
-				Chat:      &tgbotapi.Chat{ID: managerID},
-				MessageID: 456,
-			},
-			Data: "manager_select_item:1",
-		},
-	}
-	b.handleManagerItemSelection(ctx, &callbackUpdate)
+	say("Item 1")
+	state = b.getUserState(ctx, managerID)
+	assert.Equal(t, models.StateManagerWaitingItemSelection, state.CurrentStep)
+	assert.Equal(t, b.t(ctx, "dialog.use_buttons"), mocks.tg.getSentMessages()[0].(tgbotapi.MessageConfig).Text)
 
+	press("manager_select_item:1")
 	state = b.getUserState(ctx, managerID)
 	assert.Equal(t, models.StateManagerWaitingDateType, state.CurrentStep)
 	assert.Equal(t, int64(1), state.TempData["item_id"])
 
-	// 5. Handle Date Type (Single)
-	mocks.tg.clearSentMessages()
-	callbackUpdate.CallbackQuery.Data = "manager_single_date"
-	b.handleManagerDateType(ctx, &callbackUpdate, "single")
-
+	// 5. Date Type (Single); the old item list no longer selects
+	press("manager_single_date")
 	state = b.getUserState(ctx, managerID)
 	assert.Equal(t, models.StateManagerWaitingSingleDate, state.CurrentStep)
-	assert.Equal(t, "single", state.TempData["date_type"])
+	assert.Equal(t, typeSingle, state.TempData["date_type"])
+	assert.Contains(t, mocks.tg.editedTexts, b.t(ctx, "btn.single_date"))
 
-	// 6. Handle Single Date
 	mocks.tg.clearSentMessages()
+	press("manager_select_item:1")
+	sent = mocks.tg.getSentMessages()
+	require.NotEmpty(t, sent)
+	assert.Equal(t, b.t(ctx, "dialog.stale"), sent[len(sent)-1].(tgbotapi.MessageConfig).Text)
+
+	// 6. Single Date
 	dateStr := time.Now().AddDate(0, 0, 1).Format("02.01.2006")
 	mocks.booking.On("ValidateBookingDate", mock.Anything).Return(nil)
-	b.handleManagerSingleDate(ctx, &update, dateStr, state)
-
+	say(dateStr)
 	state = b.getUserState(ctx, managerID)
 	assert.Equal(t, models.StateManagerWaitingComment, state.CurrentStep)
-	assert.NotEmpty(t, state.TempData["dates"])
-
-	// 7. Handle Comment
-	mocks.tg.clearSentMessages()
-	b.handleManagerComment(ctx, &update, "Test comment", state)
+	assert.Len(t, state.GetDates("dates"), 1)
 
+	// 7. Comment, then back and forth
+	say("Test comment")
 	state = b.getUserState(ctx, managerID)
 	assert.Equal(t, models.StateManagerConfirmBooking, state.CurrentStep)
 	assert.Equal(t, "Test comment", state.TempData["comment"])
 
+	say("⬅️ Назад")
+	state = b.getUserState(ctx, managerID)
+	assert.Equal(t, models.StateManagerWaitingComment, state.CurrentStep)
+	assert.Equal(t, "John Doe", state.TempData["client_name"], "back keeps the entered data")
+	say("Test comment")
+
 	// 8. Create Bookings
-	mocks.tg.clearSentMessages()
 	mocks.booking.On("CheckAvailability", mock.Anything, int64(1), mock.Anything).Return(true, nil)
-	mocks.booking.On("CreateBooking", mock.Anything, mock.Anything).Return(nil)
-	b.createManagerBookings(ctx, &update, state)
+	say(b.t(ctx, btnConfirmCreate))
 
 	state = b.getUserState(ctx, managerID)
 	assert.NotNil(t, state)
 	assert.Equal(t, models.StateMainMenu, state.CurrentStep)
+	require.Len(t, mocks.booking.getBookings(), 1)
+	for _, booking := range mocks.booking.getBookings() {
+		assert.Equal(t, "John Doe", booking.UserName)
+		assert.Equal(t, "Test comment", booking.Comment)
+		assert.Equal(t, models.StatusConfirmed, booking.Status)
+	}
 }
 
 func TestManagerBookingFlow_DateRange(t *testing.T) {
@@ -1518,49 +2217,57 @@ func TestManagerBookingFlow_DateRange(t *testing.T) {
 		"client_phone": "79991234567",
 	})
 
-	update := tgbotapi.Update{
-		Message: &tgbotapi.Message{
+	say := func(text string) {
+		b.handleMessage(ctx, &tgbotapi.Update{Message: &tgbotapi.Message{
 			From: &tgbotapi.User{ID: managerID},
 			Chat: &tgbotapi.Chat{ID: managerID},
-		},
-		CallbackQuery: &tgbotapi.CallbackQuery{
-			From: &tgbotapi.User{ID: managerID},
-			Message: &tgbotapi.Message{
-				Chat:      &tgbotapi.Chat{ID: managerID},
-				MessageID: 456,
-			},
-		},
+			Text: text,
+		}})
 	}
 
 	// 1. Select Range
-	b.handleManagerDateType(ctx, &update, "range")
+	b.handleCallbackQuery(ctx, &tgbotapi.Update{CallbackQuery: &tgbotapi.CallbackQuery{
+		From:    &tgbotapi.User{ID: managerID},
+		Message: &tgbotapi.Message{Chat: &tgbotapi.Chat{ID: managerID}, MessageID: 456},
+		Data:    "manager_date_range",
+	}})
 	state := b.getUserState(ctx, managerID)
 	assert.Equal(t, models.StateManagerWaitingStartDate, state.CurrentStep)
 
-	// 2. Handle Start Date
+	// 2. Start Date
 	startDate := time.Now().AddDate(0, 0, 1)
 	mocks.booking.On("ValidateBookingDate", mock.Anything).Return(nil)
-	b.handleManagerStartDate(ctx, &update, startDate.Format("02.01.2006"), state)
+	say(startDate.Format("02.01.2006"))
+	state = b.getUserState(ctx, managerID)
+	assert.Equal(t, models.StateManagerWaitingEndDate, state.CurrentStep)
+
+	// 3. End Date: before the start and too long ranges are rejected
+	mocks.tg.clearSentMessages()
+	say(startDate.AddDate(0, 0, -1).Format("02.01.2006"))
+	say(startDate.AddDate(0, 0, 40).Format("02.01.2006"))
 	state = b.getUserState(ctx, managerID)
 	assert.Equal(t, models.StateManagerWaitingEndDate, state.CurrentStep)
+	sent := mocks.tg.getSentMessages()
+	require.Len(t, sent, 2)
+	assert.Equal(t, b.t(ctx, "manager_booking.end_before_start"), sent[0].(tgbotapi.MessageConfig).Text)
+	assert.Equal(t, b.t(ctx, "manager_booking.range_too_long"), sent[1].(tgbotapi.MessageConfig).Text)
 
-	// 3. Handle End Date
 	endDate := startDate.AddDate(0, 0, 2) // 3 days total
-	b.handleManagerEndDate(ctx, &update, endDate.Format("02.01.2006"), state)
+	say(endDate.Format("02.01.2006"))
 	state = b.getUserState(ctx, managerID)
 	assert.Equal(t, models.StateManagerWaitingComment, state.CurrentStep)
 	dates := state.GetDates("dates")
 	assert.Len(t, dates, 3)
 
-	// 4. Handle Comment & Create
-	b.handleManagerComment(ctx, &update, "Range comment", state)
+	// 4. Comment & Create
+	say("Range comment")
 	mocks.booking.On("CheckAvailability", mock.Anything, int64(1), mock.Anything).Return(true, nil)
-	mocks.booking.On("CreateBooking", mock.Anything, mock.Anything).Return(nil)
-	b.createManagerBookings(ctx, &update, state)
+	say(b.t(ctx, btnConfirmCreate))
 
 	state = b.getUserState(ctx, managerID)
 	assert.NotNil(t, state)
 	assert.Equal(t, models.StateMainMenu, state.CurrentStep)
+	assert.Len(t, mocks.booking.getBookings(), 3)
 }
 
 func TestManagerItemsCommands(t *testing.T) {
@@ -1575,11 +2282,11 @@ func TestManagerItemsCommands(t *testing.T) {
 		},
 	}
 
-	// 1. Add Item
-	update.Message.Text = "/add_item NewItem 5"
+	// 1. Add Item: название из команды, мастер спрашивает количество
+	update.Message.Text = "/add_item NewItem"
 	b.handleAddItemCommand(ctx, &update)
 	assert.Len(t, mocks.tg.getSentMessages(), 1)
-	assert.Contains(t, mocks.tg.getSentMessages()[0].(tgbotapi.MessageConfig).Text, "✅ Аппарат 'NewItem' добавлен")
+	assert.Contains(t, mocks.tg.getSentMessages()[0].(tgbotapi.MessageConfig).Text, "Введите количество")
 
 	// 2. List Items
 	mocks.tg.clearSentMessages()
@@ -1591,10 +2298,10 @@ func TestManagerItemsCommands(t *testing.T) {
 
 	// 3. Edit Item
 	mocks.tg.clearSentMessages()
-	update.Message.Text = "/edit_item Item 1 20"
+	update.Message.Text = "/edit_item Item 1"
 	b.handleEditItemCommand(ctx, &update)
 	assert.Len(t, mocks.tg.getSentMessages(), 1)
-	assert.Contains(t, mocks.tg.getSentMessages()[0].(tgbotapi.MessageConfig).Text, "✅ Аппарат 'Item 1' обновлён")
+	assert.Contains(t, mocks.tg.getSentMessages()[0].(tgbotapi.MessageConfig).Text, "🔢 Количество: 10")
 
 	// 4. Set Item Order
 	mocks.tg.clearSentMessages()
@@ -1634,13 +2341,29 @@ func TestExportToExcel(t *testing.T) {
 	// Mock data
 	mocks.item.setItems([]*models.Item{{ID: 1, Name: "Item 1", TotalQuantity: 5}})
 	mocks.booking.setBookings(map[int64]*models.Booking{
-		1: {ID: 1, ItemID: 1, Date: startDate, Status: models.StatusConfirmed, UserName: "User 1"},
+		1: {ID: 1, ItemID: 1, Date: startDate, Status: models.StatusConfirmed, UserName: "User 1", UnitID: 1},
 	})
+	mocks.item.units = []*models.ItemUnit{{ID: 1, ItemID: 1, SerialNumber: "SN-100"}}
+	mocks.item.maintenance = []*models.MaintenanceWindow{
+		{ID: 1, ItemID: 1, Units: 2, StartDate: startDate.AddDate(0, 0, 2), EndDate: startDate.AddDate(0, 0, 3)},
+	}
 
 	filePath, err := b.exportToExcel(ctx, startDate, endDate)
 	assert.NoError(t, err)
 	assert.NotEmpty(t, filePath)
 	assert.FileExists(t, filePath)
+
+	f, err := excelize.OpenFile(filePath)
+	require.NoError(t, err)
+	defer f.Close()
+	// Третий день периода - колонка D: день без заявок отмечен обслуживанием
+	value, err := f.GetCellValue("Бронирования", "D3")
+	require.NoError(t, err)
+	assert.Contains(t, value, "На обслуживании: 2")
+	assert.Contains(t, value, "Доступно: 3/5")
+	value, err = f.GetCellValue("Бронирования", "B3")
+	require.NoError(t, err)
+	assert.Contains(t, value, "S/N SN-100")
 }
 
 func TestExportUsersToExcel(t *testing.T) {
@@ -1728,34 +2451,111 @@ func TestManagerStats(t *testing.T) {
 	assert.True(t, foundDoc)
 }
 
+func TestManagerStatsAnalytics(t *testing.T) {
+	b, mocks := setupTestBot()
+	ctx := context.Background()
+	today := time.Now()
+	yesterday := time.Date(today.Year(), today.Month(), today.Day()-1, 0, 0, 0, 0, time.UTC)
+
+	mocks.item.setItems([]*models.Item{{ID: 1, Name: "Item 1", TotalQuantity: 1, IsActive: true}})
+	mocks.booking.setBookings(map[int64]*models.Booking{
+		1: {ID: 1, UserID: 1, ItemID: 1, ItemName: "Item 1", Status: models.StatusConfirmed, Date: yesterday, CreatedAt: yesterday},
+		2: {ID: 2, UserID: 2, ItemID: 1, ItemName: "Item 1", Status: models.StatusCanceled, Date: yesterday, CreatedAt: yesterday},
+	})
+
+	send := func(text string) tgbotapi.MessageConfig {
+		mocks.tg.clearSentMessages()
+		b.getUserStats(ctx, &tgbotapi.Update{Message: &tgbotapi.Message{
+			From: &tgbotapi.User{ID: 123}, Chat: &tgbotapi.Chat{ID: 123}, Text: text,
+		}})
+		sent := mocks.tg.getSentMessages()
+		require.Len(t, sent, 1)
+		return sent[0].(tgbotapi.MessageConfig)
+	}
+
+	t.Run("DefaultPeriod", func(t *testing.T) {
+		start, end := analytics.Period(today, statsDefaultDays)
+		msg := send("/stats")
+		assert.Contains(t, msg.Text, "Статистика")
+		assert.Contains(t, msg.Text, "Аналитика "+start.Format("02.01.2006")+" – "+end.Format("02.01.2006"))
+		assert.Contains(t, msg.Text, "Отмены: *1* из 2 (50%)")
+		assert.Contains(t, msg.Text, "Неявки: нет данных")
+
+		keyboard := msg.ReplyMarkup.(*tgbotapi.InlineKeyboardMarkup)
+		require.Len(t, keyboard.InlineKeyboard, 3)
+		assert.Equal(t, statsPeriodPrefix+"7", *keyboard.InlineKeyboard[0][0].CallbackData)
+		assert.Equal(t, "export_users", *keyboard.InlineKeyboard[2][0].CallbackData)
+	})
+
+	t.Run("CustomPeriod", func(t *testing.T) {
+		msg := send("/stats 01.01.2030 31.01.2030")
+		assert.Contains(t, msg.Text, "Аналитика 01.01.2030 – 31.01.2030")
+
+		start, _ := analytics.Period(today, 7)
+		assert.Contains(t, send("/stats 7").Text, "Аналитика "+start.Format("02.01.2006"))
+	})
+
+	t.Run("InvalidPeriod", func(t *testing.T) {
+		for _, text := range []string{"/stats abc", "/stats 0", "/stats 31.01.2030 01.01.2030", "/stats 01.01.2030 01.01.2032"} {
+			assert.Contains(t, send(text).Text, "Использование: /stats", text)
+		}
+	})
+
+	t.Run("Callbacks", func(t *testing.T) {
+		callback := func(data string) []tgbotapi.Chattable {
+			mocks.tg.clearSentMessages()
+			b.handleStatsCallback(ctx, &tgbotapi.Update{CallbackQuery: &tgbotapi.CallbackQuery{
+				From: &tgbotapi.User{ID: 123}, Message: &tgbotapi.Message{Chat: &tgbotapi.Chat{ID: 123}}, Data: data,
+			}}, data)
+			return mocks.tg.getSentMessages()
+		}
+
+		sent := callback(statsPeriodPrefix + "90")
+		require.Len(t, sent, 1)
+		start, _ := analytics.Period(today, 90)
+		assert.Contains(t, sent[0].(tgbotapi.MessageConfig).Text, "Аналитика "+start.Format("02.01.2006"))
+
+		sent = callback(statsXLSXPrefix + "20300101:20300131")
+		require.Len(t, sent, 1)
+		doc, ok := sent[0].(tgbotapi.DocumentConfig)
+		require.True(t, ok)
+		assert.Equal(t, "analytics_2030-01-01_2030-01-31.xlsx", doc.File.(tgbotapi.FileBytes).Name)
+		assert.Contains(t, doc.Caption, "01.01.2030 – 31.01.2030")
+	})
+}
+
 func TestRemindersExtended(t *testing.T) {
 	b, mocks := setupTestBot()
+	b.config.Bot.Reminders = []config.ReminderConfig{{Kind: models.ReminderKindBefore, DaysBefore: 1, Time: "09:00"}}
 	ctx := context.Background()
-	tomorrow := time.Now().Add(24 * time.Hour).Truncate(24 * time.Hour)
+	now := time.Date(2030, 3, 10, 10, 0, 0, 0, time.Local)
+	today := time.Date(2030, 3, 10, 0, 0, 0, 0, time.UTC)
+	tomorrow := today.AddDate(0, 0, 1)
 
 	// Case 1: Booking service error
-	mocks.booking.On("GetBookingsByDateRange", ctx, tomorrow, tomorrow).Return(nil, errors.New("db error")).Once()
-	b.sendTomorrowReminders(ctx)
+	mocks.booking.On("GetBookingsByDateRange", ctx, today, tomorrow).Return(nil, errors.New("db error")).Once()
+	b.sendDueReminders(ctx, now)
 
 	// Case 2: Status not for reminder
 	bookingCanceled := models.Booking{ID: 1, UserID: 1, Status: models.StatusCanceled, Date: tomorrow}
-	mocks.booking.On("GetBookingsByDateRange", ctx, tomorrow, tomorrow).Return([]*models.Booking{&bookingCanceled}, nil).Once()
-	b.sendTomorrowReminders(ctx)
+	mocks.booking.On("GetBookingsByDateRange", ctx, today, tomorrow).Return([]*models.Booking{&bookingCanceled}, nil).Once()
+	b.sendDueReminders(ctx, now)
+
+	// Case 3: Stage time has not come yet
+	b.sendDueReminders(ctx, now.Add(-2*time.Hour))
 
-	// Case 3: User service error
-	bookingPending := models.Booking{ID: 2, UserID: 1, Status: models.StatusPending, Date: tomorrow}
-	mocks.booking.On("GetBookingsByDateRange", ctx, tomorrow, tomorrow).Return([]*models.Booking{&bookingPending}, nil).Once()
-	mocks.user.On("GetUserByID", ctx, int64(1)).Return(nil, errors.New("not found")).Once()
-	b.sendTomorrowReminders(ctx)
+	// Case 4: User opted out of reminders
+	bookingConfirmed := models.Booking{ID: 2, UserID: 1, Status: models.StatusConfirmed, Date: tomorrow}
+	require.NoError(t, mocks.user.SetRemindersEnabled(ctx, 1, false))
+	mocks.booking.On("GetBookingsByDateRange", ctx, today, tomorrow).Return([]*models.Booking{&bookingConfirmed}, nil).Once()
+	b.sendDueReminders(ctx, now)
 
-	// Case 4: User without TelegramID
-	mocks.booking.On("GetBookingsByDateRange", ctx, tomorrow, tomorrow).Return([]*models.Booking{&bookingPending}, nil).Once()
-	mocks.user.On("GetUserByID", ctx, int64(1)).Return(&models.User{TelegramID: 0}, nil).Once()
-	b.sendTomorrowReminders(ctx)
+	assert.Empty(t, mocks.tg.getSentMessages())
+	mocks.booking.AssertExpectations(t)
 }
 
-func TestTimeUntilNextHour(t *testing.T) {
-	d := timeUntilNextHour(10)
+func TestTimeUntilNextClock(t *testing.T) {
+	d := timeUntilNextClock(10, 0)
 	assert.True(t, d >= 0)
 	assert.True(t, d <= 25*time.Hour)
 }
@@ -1764,37 +2564,97 @@ func TestReminders(t *testing.T) {
 	b, mocks := setupTestBot()
 	ctx := context.Background()
 
-	tomorrow := time.Now().Add(24 * time.Hour).Truncate(24 * time.Hour)
+	now := time.Date(2030, 3, 10, 10, 0, 0, 0, time.Local)
+	day := func(offset int) time.Time { return time.Date(2030, 3, 10+offset, 0, 0, 0, 0, time.UTC) }
 
-	// Add a user
-	err := mocks.user.SaveUser(ctx, &models.User{
-		TelegramID: 1,
-		FirstName:  "User 1",
-	})
-	require.NoError(t, err)
+	b.config.Bot.Reminders = []config.ReminderConfig{
+		{Kind: models.ReminderKindBefore, DaysBefore: 3, Time: "09:00"},
+		{Kind: models.ReminderKindBefore, DaysBefore: 0, Time: "08:00"},
+		{Kind: models.ReminderKindReturn, DaysBefore: 0, Time: "09:30"},
+		{Kind: models.ReminderKindReturn, DaysBefore: 1, Time: "18:00"},
+	}
+
+	require.NoError(t, mocks.user.SaveUser(ctx, &models.User{TelegramID: 1, FirstName: "User 1"}))
+	require.NoError(t, mocks.user.SaveUser(ctx, &models.User{TelegramID: 2, FirstName: "User 2", PreferredLanguage: "en"}))
 
-	// Add a booking for tomorrow
 	mocks.booking.setBookings(map[int64]*models.Booking{
-		1: {
-			ID:       1,
-			UserID:   1,
-			ItemName: "Item 1",
-			Status:   models.StatusConfirmed,
-			Date:     tomorrow,
-		},
+		// Двухдневная бронь через 3 дня: напоминание только о первом дне
+		1: {ID: 1, UserID: 1, ItemID: 1, ItemName: "Item 1", Status: models.StatusConfirmed, Date: day(3)},
+		2: {ID: 2, UserID: 1, ItemID: 1, ItemName: "Item 1", Status: models.StatusConfirmed, Date: day(4)},
+		// Бронь начинается сегодня
+		3: {ID: 3, UserID: 2, ItemID: 2, ItemName: "Item 2", Status: models.StatusChanged, Date: day(0)},
+		// Бронь закончилась вчера: аппарат возвращается сегодня
+		4: {ID: 4, UserID: 1, ItemID: 2, ItemName: "Item 2", Status: models.StatusConfirmed, Date: day(-1)},
+		// Заявка без подтверждения не напоминается
+		5: {ID: 5, UserID: 1, ItemID: 3, ItemName: "Item 3", Status: models.StatusPending, Date: day(3)},
 	})
 
-	// Run reminders
-	b.sendTomorrowReminders(ctx)
+	messagesTo := func() map[int64][]string {
+		result := make(map[int64][]string)
+		for _, m := range mocks.tg.getSentMessages() {
+			msg := m.(tgbotapi.MessageConfig)
+			result[msg.ChatID] = append(result[msg.ChatID], msg.Text)
+		}
+		return result
+	}
+
+	b.sendDueReminders(ctx, now)
 
-	// Check if message was sent
-	sentMsgs := mocks.tg.getSentMessages()
-	assert.True(t, len(sentMsgs) > 0)
-	msg := sentMsgs[len(sentMsgs)-1].(tgbotapi.MessageConfig)
-	assert.Equal(t, int64(1), msg.ChatID)
-	assert.Contains(t, msg.Text, "Напоминание")
-	assert.Contains(t, msg.Text, "Item 1")
+	sent := messagesTo()
+	require.Len(t, sent[1], 2)
+	require.Len(t, sent[2], 1)
+	assert.Contains(t, sent[1], "⏰ Напоминание: через 3 дня, 13.03.2030, у вас бронь «Item 1». Статус: ✅ Подтверждена\n\nОтключить напоминания: /reminders")
+	assert.Contains(t, sent[1], "📦 Напоминание: сегодня, 10.03.2030, нужно вернуть «Item 2».\n\nОтключить напоминания: /reminders")
+	assert.Contains(t, sent[2][0], "booking for «Item 2» today, 10.03.2030")
+
+	// Повторная проверка и вечерний этап в тот же день ничего не дублируют
+	mocks.tg.clearSentMessages()
+	b.sendDueReminders(ctx, now.Add(9*time.Hour))
+	sent = messagesTo()
+	assert.Empty(t, sent[1])
+	require.Len(t, sent[2], 1, "only the evening return stage is new")
+	assert.Contains(t, sent[2][0], "please return «Item 2» tomorrow, 11.03.2030")
+
+	t.Run("CustomTemplate", func(t *testing.T) {
+		mocks.tg.clearSentMessages()
+		b.config.Bot.Reminders = []config.ReminderConfig{
+			{Kind: models.ReminderKindBefore, DaysBefore: 2, Time: "09:00", Template: "{name}, бронь №{id} ({item}) {when}"},
+		}
+		mocks.booking.setBookings(map[int64]*models.Booking{
+			6: {ID: 6, UserID: 1, UserName: "Иван", ItemID: 1, ItemName: "Item 1", Status: models.StatusConfirmed, Date: day(2)},
+		})
+
+		b.sendDueReminders(ctx, now)
+
+		sent := messagesTo()
+		require.Len(t, sent[1], 1)
+		assert.True(t, strings.HasPrefix(sent[1][0], "Иван, бронь №6 (Item 1) через 2 дня"))
+	})
+}
+
+func TestRemindersOptOut(t *testing.T) {
+	b, mocks := setupTestBot()
+	ctx := context.Background()
+
+	b.handleRemindersCommand(ctx, 900, 900)
+	sent := mocks.tg.getSentMessages()
+	require.Len(t, sent, 1)
+	msg := sent[0].(tgbotapi.MessageConfig)
+	assert.Equal(t, b.t(ctx, "reminders.enabled"), msg.Text)
+	keyboard, ok := msg.ReplyMarkup.(tgbotapi.InlineKeyboardMarkup)
+	require.True(t, ok)
+	assert.Equal(t, "set_reminders:off", *keyboard.InlineKeyboard[0][0].CallbackData)
+
+	b.handleCallbackQuery(ctx, &tgbotapi.Update{CallbackQuery: &tgbotapi.CallbackQuery{
+		From:    &tgbotapi.User{ID: 900},
+		Message: &tgbotapi.Message{Chat: &tgbotapi.Chat{ID: 900}, MessageID: 3},
+		Data:    "set_reminders:off",
+	}})
+
+	assert.False(t, mocks.user.RemindersEnabled(ctx, 900))
+	assert.Contains(t, mocks.tg.editedTexts, b.t(ctx, "reminders.disabled"))
 }
+
 func TestMiddleware(t *testing.T) {
 	b, _ := setupTestBot()
 
@@ -1850,6 +2710,7 @@ func TestManagerBookingActions(t *testing.T) {
 	update := tgbotapi.Update{
 		Message: &tgbotapi.Message{
 			Chat: &tgbotapi.Chat{ID: 123},
+			From: &tgbotapi.User{ID: 123},
 		},
 	}
 	b.showManagerBookingDetail(ctx, &update, 1)
@@ -1933,32 +2794,18 @@ func TestManagerMoreActions(t *testing.T) {
 		},
 	})
 
-	// Test handleManagerSingleDate error cases
-	state := &models.UserState{UserID: managerID}
-	b.handleManagerSingleDate(ctx, &tgbotapi.Update{
-		Message: &tgbotapi.Message{
-			Chat: &tgbotapi.Chat{ID: managerID},
-			From: &tgbotapi.User{ID: managerID},
-		},
-	}, "invalid date", state)
-
-	// Test handleManagerEndDate edge cases
-	state.TempData = map[string]interface{}{"start_date": time.Now()}
-	b.handleManagerEndDate(ctx, &tgbotapi.Update{
-		Message: &tgbotapi.Message{
-			Chat: &tgbotapi.Chat{ID: managerID},
-			From: &tgbotapi.User{ID: managerID},
-		},
-	}, time.Now().AddDate(0, 0, -1).Format("02.01.2006"), state)
-	b.handleManagerEndDate(ctx, &tgbotapi.Update{
+	// Test manager dialog error cases
+	b.setUserState(ctx, managerID, models.StateManagerWaitingSingleDate, nil)
+	b.handleMessage(ctx, &tgbotapi.Update{
 		Message: &tgbotapi.Message{
 			Chat: &tgbotapi.Chat{ID: managerID},
 			From: &tgbotapi.User{ID: managerID},
+			Text: "invalid date",
 		},
-	}, time.Now().AddDate(0, 0, 40).Format("02.01.2006"), state)
+	})
 
 	// Test notifyManagers
-	b.notifyManagers(&models.Booking{ID: 1, ItemName: "Item 1", Date: time.Now()})
+	b.notifyManagers(context.Background(), &models.Booking{ID: 1, ItemName: "Item 1", Date: time.Now()})
 	assert.True(t, len(mocks.tg.sentMessages) > 0)
 }
 
@@ -1989,13 +2836,6 @@ func TestUtilsMoreCoverage(t *testing.T) {
 	})
 	assert.True(t, len(mocks.tg.getSentMessages()) > 2)
 
-	// Test restoreStateOrRestart failures
-	b.restoreStateOrRestart(ctx, &tgbotapi.Update{
-		Message: &tgbotapi.Message{
-			From: &tgbotapi.User{ID: 999}, Chat: &tgbotapi.Chat{ID: 999},
-		},
-	}, "missing_field")
-
 	// Test handleCustomInput back buttons
 	b.setUserState(ctx, 123, models.StateEnterName, map[string]interface{}{"item_id": int64(1)})
 	b.handleCustomInput(ctx, &tgbotapi.Update{
@@ -2018,14 +2858,18 @@ func TestUtilsMoreCoverage(t *testing.T) {
 		},
 	}, b.getUserState(ctx, 123))
 
-	// Test handleContactReceived
-	b.setUserState(ctx, 123, models.StatePhoneNumber, map[string]interface{}{"item_id": int64(1), "date": time.Now()})
-	b.handleContactReceived(ctx, &tgbotapi.Update{
+	// Test shared contact on the phone step
+	b.setUserState(ctx, 123, models.StatePhoneNumber, map[string]interface{}{"item_id": int64(1), "date": time.Now().AddDate(0, 0, 1)})
+	b.handleMessage(ctx, &tgbotapi.Update{
 		Message: &tgbotapi.Message{
 			From: &tgbotapi.User{ID: 123}, Chat: &tgbotapi.Chat{ID: 123},
-			Contact: &tgbotapi.Contact{PhoneNumber: "1234567890"},
+			Contact: &tgbotapi.Contact{PhoneNumber: "+79991234567"},
 		},
 	})
+	for _, booking := range mocks.booking.getBookings() {
+		assert.Equal(t, "79991234567", booking.Phone)
+	}
+	assert.NotEmpty(t, mocks.booking.getBookings())
 }
 
 func TestPagination(t *testing.T) {
@@ -2117,3 +2961,1540 @@ func TestUserHandlersErrors(t *testing.T) {
 	b.updateUserPhone(123, "+1234567890")
 	// Should not panic
 }
+
+func TestShowBookingHistory(t *testing.T) {
+	b, mocks := setupTestBot()
+	ctx := context.Background()
+
+	entries := []*models.AuditEntry{
+		{
+			ID: 1, EntityType: models.AuditEntityBooking, EntityID: 7, Action: models.AuditActionCreate,
+			ActorID: 42, Source: models.AuditSourceBot, After: `{"status":"pending","item_name":"Item 1"}`,
+			CreatedAt: time.Date(2025, 1, 2, 10, 0, 0, 0, time.UTC),
+		},
+		{
+			ID: 2, EntityType: models.AuditEntityBooking, EntityID: 7, Action: models.AuditActionStatusChange,
+			ActorID: 123, Source: models.AuditSourceBot,
+			Before:    `{"status":"pending","item_name":"Item 1","version":1}`,
+			After:     `{"status":"confirmed","item_name":"Item 1","version":2}`,
+			CreatedAt: time.Date(2025, 1, 2, 11, 0, 0, 0, time.UTC),
+		},
+	}
+	mocks.booking.bookings[7] = &models.Booking{ID: 7, ItemID: 1, Date: time.Now()}
+	mocks.booking.On("GetBookingHistory", mock.Anything, int64(7)).Return(entries, nil).Once()
+
+	update := tgbotapi.Update{
+		Message: &tgbotapi.Message{
+			Chat: &tgbotapi.Chat{ID: 123},
+			From: &tgbotapi.User{ID: 123},
+			Text: "/booking_history_7",
+		},
+	}
+	handled := b.handleManagerBasicCommands(ctx, &update, update.Message.Text, 123)
+	assert.True(t, handled)
+
+	sent := mocks.tg.getSentMessages()
+	require.NotEmpty(t, sent)
+	msg := sent[len(sent)-1].(tgbotapi.MessageConfig)
+	assert.Contains(t, msg.Text, "История заявки #7")
+	assert.Contains(t, msg.Text, "status: pending → confirmed")
+	assert.NotContains(t, msg.Text, "version")
+	mocks.booking.AssertExpectations(t)
+}
+
+func TestManagerRoleScopes(t *testing.T) {
+	b, mocks := setupTestBot()
+	ctx := context.Background()
+
+	mocks.user.roles = map[int64]*models.UserRole{
+		200: {TelegramID: 200, Role: models.RoleManager, ItemIDs: []int64{2}},
+		300: {TelegramID: 300, Role: models.RoleViewer},
+	}
+	mocks.booking.bookings[1] = &models.Booking{ID: 1, ItemID: 1, ItemName: "Item 1", Date: time.Now()}
+
+	t.Run("ScopedManagerDeniedForOtherItem", func(t *testing.T) {
+		update := tgbotapi.Update{Message: &tgbotapi.Message{
+			Chat: &tgbotapi.Chat{ID: 200},
+			From: &tgbotapi.User{ID: 200},
+		}}
+		b.showManagerBookingDetail(ctx, &update, 1)
+
+		sent := mocks.tg.getSentMessages()
+		require.NotEmpty(t, sent)
+		assert.Equal(t, b.t(ctx, msgAccessDenied), sent[len(sent)-1].(tgbotapi.MessageConfig).Text)
+	})
+
+	t.Run("ViewerCannotAssignRoles", func(t *testing.T) {
+		update := tgbotapi.Update{Message: &tgbotapi.Message{
+			Chat: &tgbotapi.Chat{ID: 300},
+			From: &tgbotapi.User{ID: 300},
+			Text: "/set_role 400 manager",
+		}}
+		assert.True(t, b.handleManagerRoleCommands(ctx, &update, update.Message.Text))
+
+		sent := mocks.tg.getSentMessages()
+		require.NotEmpty(t, sent)
+		assert.Equal(t, b.t(ctx, msgAccessDenied), sent[len(sent)-1].(tgbotapi.MessageConfig).Text)
+	})
+
+	t.Run("AdminAssignsScopedRole", func(t *testing.T) {
+		mocks.user.On("SetUserRole", mock.Anything, mock.MatchedBy(func(r *models.UserRole) bool {
+			return r.TelegramID == 400 && r.Role == models.RoleManager &&
+				len(r.ItemIDs) == 1 && r.ItemIDs[0] == 1 && r.GrantedBy == 123
+		})).Return(nil).Once()
+
+		update := tgbotapi.Update{Message: &tgbotapi.Message{
+			Chat: &tgbotapi.Chat{ID: 123},
+			From: &tgbotapi.User{ID: 123},
+			Text: "/set_role 400 manager 1",
+		}}
+		assert.True(t, b.handleManagerRoleCommands(ctx, &update, update.Message.Text))
+
+		sent := mocks.tg.getSentMessages()
+		require.NotEmpty(t, sent)
+		assert.Contains(t, sent[len(sent)-1].(tgbotapi.MessageConfig).Text, "назначена роль: менеджер [Item 1]")
+		mocks.user.AssertExpectations(t)
+	})
+}
+
+func TestBlacklistCommands(t *testing.T) {
+	b, mocks := setupTestBot()
+	ctx := context.Background()
+
+	t.Run("BlockedUserGetsReason", func(t *testing.T) {
+		_ = mocks.user.SaveUser(ctx, &models.User{
+			TelegramID: 500, IsBlacklisted: true, BlacklistReason: "неявка",
+		})
+		assert.True(t, b.denyIfBlacklisted(ctx, 500, 500))
+
+		sent := mocks.tg.getSentMessages()
+		require.NotEmpty(t, sent)
+		text := sent[len(sent)-1].(tgbotapi.MessageConfig).Text
+		assert.Contains(t, text, "бессрочно")
+		assert.Contains(t, text, "Причина: неявка")
+
+		assert.False(t, b.denyIfBlacklisted(ctx, 123, 123))
+	})
+
+	t.Run("BlockWithExpiryOffersCancellation", func(t *testing.T) {
+		future := &models.Booking{
+			ID: 10, UserID: 600, ItemID: 1, ItemName: "Item 1",
+			Date: time.Now().AddDate(0, 0, 3), Status: models.StatusConfirmed,
+		}
+		past := &models.Booking{
+			ID: 11, UserID: 600, ItemID: 1, Date: time.Now().AddDate(0, 0, -3), Status: models.StatusConfirmed,
+		}
+		mocks.booking.bookings[10] = future
+		mocks.user.On("GetUserBookings", mock.Anything, int64(600)).Return([]*models.Booking{future, past}, nil)
+
+		expiry := time.Now().AddDate(0, 0, 10)
+		expectedUntil := time.Date(expiry.Year(), expiry.Month(), expiry.Day()+1, 0, 0, 0, 0, time.Local)
+		mocks.user.On("BlockUser", mock.Anything, int64(600), "спам", expectedUntil, int64(123)).Return(nil).Once()
+
+		update := tgbotapi.Update{Message: &tgbotapi.Message{
+			Chat: &tgbotapi.Chat{ID: 123},
+			From: &tgbotapi.User{ID: 123},
+			Text: "/block 600 " + expiry.Format("02.01.2006") + " спам",
+		}}
+		assert.True(t, b.handleManagerBlacklistCommands(ctx, &update, update.Message.Text))
+
+		sent := mocks.tg.getSentMessages()
+		require.NotEmpty(t, sent)
+		msg := sent[len(sent)-1].(tgbotapi.MessageConfig)
+		assert.Contains(t, msg.Text, "до "+expiry.Format("02.01.2006")+" включительно")
+		keyboard, ok := msg.ReplyMarkup.(tgbotapi.InlineKeyboardMarkup)
+		require.True(t, ok)
+		assert.Equal(t, "blacklist_cancel:600", *keyboard.InlineKeyboard[0][0].CallbackData)
+
+		// Пользователь заблокирован — отменяем будущие заявки
+		_ = mocks.user.SaveUser(ctx, &models.User{TelegramID: 600, IsBlacklisted: true})
+		b.cancelFutureBookingsOfBlocked(ctx, 123, 123, 600)
+		assert.Equal(t, models.StatusCanceled, mocks.booking.bookings[10].Status)
+
+		sent = mocks.tg.getSentMessages()
+		assert.Contains(t, sent[len(sent)-1].(tgbotapi.MessageConfig).Text, "Отменено заявок: 1")
+		mocks.user.AssertExpectations(t)
+	})
+
+	t.Run("ViewerCannotBlock", func(t *testing.T) {
+		mocks.user.roles = map[int64]*models.UserRole{300: {TelegramID: 300, Role: models.RoleViewer}}
+		update := tgbotapi.Update{Message: &tgbotapi.Message{
+			Chat: &tgbotapi.Chat{ID: 300},
+			From: &tgbotapi.User{ID: 300},
+			Text: "/block 700",
+		}}
+		assert.True(t, b.handleManagerBlacklistCommands(ctx, &update, update.Message.Text))
+
+		sent := mocks.tg.getSentMessages()
+		assert.Equal(t, b.t(ctx, msgAccessDenied), sent[len(sent)-1].(tgbotapi.MessageConfig).Text)
+	})
+}
+
+func TestPersonalDataFlow(t *testing.T) {
+	b, mocks := setupTestBot()
+	ctx := context.Background()
+
+	t.Run("ConsentRequestedBeforeName", func(t *testing.T) {
+		mocks.user.withoutConsent = map[int64]bool{700: true}
+		b.setUserState(ctx, 700, models.StateWaitingDate, map[string]interface{}{"item_id": int64(1)})
+
+		update := tgbotapi.Update{Message: &tgbotapi.Message{
+			Chat: &tgbotapi.Chat{ID: 700},
+			From: &tgbotapi.User{ID: 700},
+			Text: time.Now().AddDate(0, 0, 3).Format("02.01.2006"),
+		}}
+		b.handleMessage(ctx, &update)
+
+		state := b.getUserState(ctx, 700)
+		require.NotNil(t, state)
+		assert.Equal(t, models.StatePersonalData, state.CurrentStep)
+		assert.Equal(t, int64(1), state.TempData["item_id"])
+
+		// Без согласия шаг не меняется
+		update.Message.Text = "Иванов Иван"
+		b.handleMessage(ctx, &update)
+		assert.Equal(t, models.StatePersonalData, b.getUserState(ctx, 700).CurrentStep)
+		assert.True(t, mocks.user.withoutConsent[700])
+
+		update.Message.Text = "✅ Согласен на обработку данных"
+		b.handleMessage(ctx, &update)
+
+		state = b.getUserState(ctx, 700)
+		assert.Equal(t, models.StateEnterName, state.CurrentStep)
+		assert.False(t, mocks.user.withoutConsent[700])
+	})
+
+	t.Run("MyDataSendsDocument", func(t *testing.T) {
+		mocks.tg.clearSentMessages()
+		mocks.user.On("ExportPersonalData", mock.Anything, int64(700)).
+			Return(models.NewPersonalDataExport(700, &models.User{TelegramID: 700, Phone: "79990000000"}, nil, nil), nil).Once()
+
+		update := tgbotapi.Update{Message: &tgbotapi.Message{
+			Chat: &tgbotapi.Chat{ID: 700},
+			From: &tgbotapi.User{ID: 700},
+			Text: "/mydata",
+		}}
+		b.handleMyDataCommand(ctx, &update)
+
+		sent := mocks.tg.getSentMessages()
+		require.Len(t, sent, 1)
+		doc, ok := sent[0].(tgbotapi.DocumentConfig)
+		require.True(t, ok)
+		file, ok := doc.File.(tgbotapi.FileBytes)
+		require.True(t, ok)
+		assert.Equal(t, "mydata_700.json", file.Name)
+		assert.Contains(t, string(file.Bytes), "79990000000")
+		assert.Contains(t, string(file.Bytes), models.StateEnterName)
+	})
+
+	t.Run("ForgetConfirmClearsState", func(t *testing.T) {
+		mocks.tg.clearSentMessages()
+		mocks.user.On("ForgetUser", mock.Anything, int64(700)).
+			Return([]*models.Booking{{ID: 1, UserName: models.AnonymizedUserName}}, nil).Once()
+
+		b.handleForgetConfirm(ctx, 700, 700)
+
+		assert.Nil(t, b.getUserState(ctx, 700))
+		sent := mocks.tg.getSentMessages()
+		require.NotEmpty(t, sent)
+		assert.Contains(t, sent[len(sent)-1].(tgbotapi.MessageConfig).Text, "обезличена 1 заявка")
+		mocks.user.AssertExpectations(t)
+	})
+}
+
+func TestLanguageSelection(t *testing.T) {
+	b, mocks := setupTestBot()
+	ctx := context.Background()
+
+	t.Run("TelegramLanguageUsedByDefault", func(t *testing.T) {
+		assert.Equal(t, "en", b.userLanguage(ctx, 800, "en-US"))
+		assert.Equal(t, "ru", b.userLanguage(ctx, 800, "de"))
+		assert.Equal(t, "ru", b.userLanguage(ctx, 800, ""))
+	})
+
+	t.Run("LanguageCommandOffersAllCatalogs", func(t *testing.T) {
+		mocks.tg.clearSentMessages()
+		b.handleLanguageCommand(ctx, 800)
+
+		sent := mocks.tg.getSentMessages()
+		require.Len(t, sent, 1)
+		keyboard, ok := sent[0].(tgbotapi.MessageConfig).ReplyMarkup.(tgbotapi.InlineKeyboardMarkup)
+		require.True(t, ok)
+		require.Len(t, keyboard.InlineKeyboard[0], 2)
+		assert.Equal(t, "set_language:ru", *keyboard.InlineKeyboard[0][0].CallbackData)
+		assert.Equal(t, "set_language:en", *keyboard.InlineKeyboard[0][1].CallbackData)
+	})
+
+	t.Run("SetLanguageRedrawsMenu", func(t *testing.T) {
+		mocks.tg.clearSentMessages()
+		b.handleCallbackQuery(ctx, &tgbotapi.Update{CallbackQuery: &tgbotapi.CallbackQuery{
+			From:    &tgbotapi.User{ID: 800},
+			Message: &tgbotapi.Message{Chat: &tgbotapi.Chat{ID: 800}},
+			Data:    "set_language:en",
+		}})
+
+		assert.Equal(t, "en", mocks.user.GetLanguage(ctx, 800))
+		assert.Equal(t, "en", b.userLanguage(ctx, 800, "ru"), "explicit choice wins over the Telegram language")
+
+		sent := mocks.tg.getSentMessages()
+		require.GreaterOrEqual(t, len(sent), 2)
+		assert.Equal(t, "✅ Interface language: English", sent[len(sent)-2].(tgbotapi.MessageConfig).Text)
+		menu := sent[len(sent)-1].(tgbotapi.MessageConfig)
+		assert.Equal(t, "Welcome! Choose an action:", menu.Text)
+		keyboard, ok := menu.ReplyMarkup.(tgbotapi.ReplyKeyboardMarkup)
+		require.True(t, ok)
+		assert.Equal(t, "📋 NEW BOOKING", keyboard.Keyboard[0][0].Text)
+	})
+
+	t.Run("EnglishButtonsAreRouted", func(t *testing.T) {
+		mocks.tg.clearSentMessages()
+		b.handleMessage(i18n.WithLanguage(ctx, "en"), &tgbotapi.Update{Message: &tgbotapi.Message{
+			Chat: &tgbotapi.Chat{ID: 800},
+			From: &tgbotapi.User{ID: 800},
+			Text: "📊 My bookings",
+		}})
+
+		sent := mocks.tg.getSentMessages()
+		require.NotEmpty(t, sent)
+		assert.Contains(t, sent[len(sent)-1].(tgbotapi.MessageConfig).Text, "You have no bookings yet")
+	})
+}
+
+// calendarDay возвращает кнопку дня day в календаре, построенном на месяц day
+func calendarDay(kb tgbotapi.InlineKeyboardMarkup, day time.Time) tgbotapi.InlineKeyboardButton {
+	first := time.Date(day.Year(), day.Month(), 1, 0, 0, 0, 0, time.UTC)
+	index := (int(first.Weekday())+6)%7 + day.Day() - 1
+	return kb.InlineKeyboard[2+index/7][index%7]
+}
+
+func TestCalendar(t *testing.T) {
+	b, mocks := setupTestBot()
+	ctx := context.Background()
+
+	now := time.Now()
+	month := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC).AddDate(0, 1, 0)
+	booked := month.AddDate(0, 0, 9)
+	mocks.booking.fullyBooked = map[string]bool{booked.Format("2006-01-02"): true}
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	mocks.booking.On("ValidateBookingDate", mock.MatchedBy(func(d time.Time) bool { return d.Before(today) })).
+		Return(database.ErrPastDate)
+	mocks.booking.On("ValidateBookingDate", mock.Anything).Return(nil)
+
+	calendarUpdate := func(userID int64, data string) *tgbotapi.Update {
+		return &tgbotapi.Update{CallbackQuery: &tgbotapi.CallbackQuery{
+			From:    &tgbotapi.User{ID: userID},
+			Message: &tgbotapi.Message{Chat: &tgbotapi.Chat{ID: userID}, MessageID: 5},
+			Data:    data,
+		}}
+	}
+
+	t.Run("MarksBookedAndPastDays", func(t *testing.T) {
+		state := &models.UserState{CurrentStep: models.StateWaitingDate, TempData: map[string]interface{}{"item_id": int64(1)}}
+
+		kb := b.calendarKeyboard(ctx, state, month)
+		assert.Contains(t, kb.InlineKeyboard[0][1].Text, strconv.Itoa(month.Year()))
+		assert.Len(t, kb.InlineKeyboard[1], 7)
+
+		first := calendarDay(kb, month)
+		assert.Equal(t, "1", first.Text)
+		assert.Equal(t, calendarDayPrefix+month.Format("2006-01-02"), *first.CallbackData)
+
+		busy := calendarDay(kb, booked)
+		assert.Equal(t, "✖", busy.Text)
+		assert.Equal(t, calendarIgnore, *busy.CallbackData)
+
+		current := b.calendarKeyboard(ctx, state, now)
+		assert.Equal(t, calendarIgnore, *current.InlineKeyboard[0][0].CallbackData, "no navigation into the past")
+		if now.Day() > 1 {
+			assert.Equal(t, calendarIgnore, *calendarDay(current, today.AddDate(0, 0, -1)).CallbackData)
+		}
+	})
+
+	t.Run("NavigationEditsKeyboard", func(t *testing.T) {
+		mocks.tg.clearSentMessages()
+		b.setUserState(ctx, 900, models.StateWaitingDate, map[string]interface{}{"item_id": int64(1)})
+
+		b.handleCallbackQuery(ctx, calendarUpdate(900, calendarNavPrefix+month.Format("2006-01")))
+
+		sent := mocks.tg.getSentMessages()
+		require.NotEmpty(t, sent)
+		edit, ok := sent[len(sent)-1].(tgbotapi.EditMessageReplyMarkupConfig)
+		require.True(t, ok)
+		assert.Equal(t, 5, edit.MessageID)
+		assert.Equal(t, "1", calendarDay(*edit.ReplyMarkup, month).Text)
+	})
+
+	t.Run("PickingDayContinuesBooking", func(t *testing.T) {
+		day := month.AddDate(0, 0, 2)
+		b.handleCallbackQuery(ctx, calendarUpdate(900, calendarDayPrefix+day.Format("2006-01-02")))
+
+		state := b.getUserState(ctx, 900)
+		require.NotNil(t, state)
+		assert.Equal(t, models.StateEnterName, state.CurrentStep)
+		assert.True(t, day.Equal(state.GetTime("date")))
+		assert.Contains(t, mocks.tg.editedTexts, b.t(ctx, "calendar.selected", day.Format("02.01.2006")))
+	})
+
+	t.Run("StaleCalendarIsRejected", func(t *testing.T) {
+		mocks.tg.clearSentMessages()
+		b.handleCallbackQuery(ctx, calendarUpdate(900, calendarDayPrefix+month.Format("2006-01-02")))
+
+		sent := mocks.tg.getSentMessages()
+		require.NotEmpty(t, sent)
+		assert.Equal(t, b.t(ctx, "calendar.expired"), sent[len(sent)-1].(tgbotapi.MessageConfig).Text)
+	})
+
+	t.Run("ManagerRangeSelection", func(t *testing.T) {
+		mocks.tg.clearSentMessages()
+		b.setUserState(ctx, 123, models.StateManagerWaitingStartDate, map[string]interface{}{
+			"client_name": "Client", "client_phone": "+79990000000", "item_id": int64(1), "date_type": "range",
+		})
+
+		start := month.AddDate(0, 0, 7)
+		b.handleCallbackQuery(ctx, calendarUpdate(123, calendarDayPrefix+start.Format("2006-01-02")))
+
+		state := b.getUserState(ctx, 123)
+		require.NotNil(t, state)
+		assert.Equal(t, models.StateManagerWaitingEndDate, state.CurrentStep)
+
+		sent := mocks.tg.getSentMessages()
+		require.NotEmpty(t, sent)
+		msg := sent[len(sent)-1].(tgbotapi.MessageConfig)
+		kb, ok := msg.ReplyMarkup.(tgbotapi.InlineKeyboardMarkup)
+		require.True(t, ok)
+		assert.Equal(t, "["+strconv.Itoa(start.Day())+"]", calendarDay(kb, start).Text)
+		assert.Equal(t, calendarIgnore, *calendarDay(kb, start.AddDate(0, 0, -1)).CallbackData)
+		// Занятый день можно включить в интервал: недоступные даты отбрасываются при создании
+		assert.Equal(t, calendarDayPrefix+booked.Format("2006-01-02"), *calendarDay(kb, booked).CallbackData)
+
+		end := start.AddDate(0, 0, 3)
+		b.handleCallbackQuery(ctx, calendarUpdate(123, calendarDayPrefix+end.Format("2006-01-02")))
+
+		state = b.getUserState(ctx, 123)
+		require.NotNil(t, state)
+		assert.Equal(t, models.StateManagerWaitingComment, state.CurrentStep)
+		assert.Len(t, state.GetDates("dates"), 4)
+	})
+}
+
+func TestManagerSearch(t *testing.T) {
+	b, mocks := setupTestBot()
+	ctx := context.Background()
+
+	day := time.Date(2030, 1, 10, 0, 0, 0, 0, time.UTC)
+	mocks.booking.bookings = map[int64]*models.Booking{
+		1: {ID: 1, ItemID: 1, ItemName: "Item 1", UserName: "Иван Петров", Phone: "79991234567", Status: models.StatusPending, Date: day},
+		2: {ID: 2, ItemID: 1, ItemName: "Item 1", UserName: "Анна Иванова", Phone: "79990000000", Status: models.StatusConfirmed, Date: day.AddDate(0, 0, 1)},
+		3: {ID: 3, ItemID: 1, ItemName: "Item 1", UserName: "John Smith", Phone: "79995554433", Status: models.StatusCanceled, Date: day.AddDate(0, 0, 2)},
+	}
+
+	send := func(text string) {
+		b.handleMessage(ctx, &tgbotapi.Update{Message: &tgbotapi.Message{
+			Chat: &tgbotapi.Chat{ID: 123},
+			From: &tgbotapi.User{ID: 123},
+			Text: text,
+		}})
+	}
+	lastText := func() string {
+		sent := mocks.tg.getSentMessages()
+		require.NotEmpty(t, sent)
+		switch msg := sent[len(sent)-1].(type) {
+		case tgbotapi.MessageConfig:
+			return msg.Text
+		case tgbotapi.EditMessageTextConfig:
+			return msg.Text
+		}
+		t.Fatalf("unexpected message type %T", sent[len(sent)-1])
+		return ""
+	}
+
+	t.Run("PromptThenQuery", func(t *testing.T) {
+		mocks.tg.clearSentMessages()
+		send("/search")
+		assert.Equal(t, models.StateManagerSearch, b.getUserState(ctx, 123).CurrentStep)
+		assert.Equal(t, b.t(ctx, "search.prompt"), lastText())
+
+		send("иван")
+		text := lastText()
+		assert.Contains(t, text, "Клиент: иван")
+		assert.Contains(t, text, "Найдено 2 заявки")
+		assert.Contains(t, text, "Иван Петров")
+		assert.Contains(t, text, "Анна Иванова")
+		assert.NotContains(t, text, "John Smith")
+
+		msg := mocks.tg.getSentMessages()[len(mocks.tg.getSentMessages())-1].(tgbotapi.MessageConfig)
+		keyboard := msg.ReplyMarkup.(tgbotapi.InlineKeyboardMarkup)
+		var data []string
+		for _, row := range keyboard.InlineKeyboard {
+			for _, btn := range row {
+				data = append(data, *btn.CallbackData)
+			}
+		}
+		assert.Contains(t, data, "show_booking:1")
+		assert.Contains(t, data, searchStatusPrefix+models.StatusConfirmed)
+		assert.Contains(t, data, searchPeriodPrefix+"week")
+		assert.Contains(t, data, searchItemsMenu)
+	})
+
+	t.Run("StatusFilterNarrowsResults", func(t *testing.T) {
+		b.handleCallbackQuery(ctx, &tgbotapi.Update{CallbackQuery: &tgbotapi.CallbackQuery{
+			From:    &tgbotapi.User{ID: 123},
+			Message: &tgbotapi.Message{Chat: &tgbotapi.Chat{ID: 123}, MessageID: 7},
+			Data:    searchStatusPrefix + models.StatusConfirmed,
+		}})
+
+		sent := mocks.tg.getSentMessages()
+		edit, ok := sent[len(sent)-1].(tgbotapi.EditMessageTextConfig)
+		require.True(t, ok)
+		assert.Equal(t, 7, edit.MessageID)
+		assert.Contains(t, edit.Text, "Анна Иванова")
+		assert.NotContains(t, edit.Text, "Иван Петров")
+
+		filter := b.searchFilterFromState(b.getUserState(ctx, 123))
+		assert.Equal(t, "иван", filter.ClientName)
+		assert.Equal(t, []string{models.StatusConfirmed}, filter.Statuses)
+	})
+
+	t.Run("PhoneAndIDQueries", func(t *testing.T) {
+		send("/search 8 (999) 555-44-33")
+		assert.Contains(t, lastText(), "John Smith")
+		assert.Equal(t, "79995554433", b.searchFilterFromState(b.getUserState(ctx, 123)).Phone)
+
+		send("#1")
+		assert.Contains(t, lastText(), "Иван Петров")
+		assert.NotContains(t, lastText(), "John Smith")
+
+		send("11.01.2030")
+		assert.Contains(t, lastText(), "Анна Иванова")
+		assert.NotContains(t, lastText(), "Иван Петров")
+	})
+
+	t.Run("NothingFound", func(t *testing.T) {
+		send("/search Сидоров")
+		assert.Contains(t, lastText(), b.t(ctx, "search.empty"))
+	})
+
+	t.Run("ExpiredSearch", func(t *testing.T) {
+		b.clearUserState(ctx, 123)
+		b.handleCallbackQuery(ctx, &tgbotapi.Update{CallbackQuery: &tgbotapi.CallbackQuery{
+			From:    &tgbotapi.User{ID: 123},
+			Message: &tgbotapi.Message{Chat: &tgbotapi.Chat{ID: 123}, MessageID: 7},
+			Data:    searchPagePrefix + "1",
+		}})
+		assert.Equal(t, b.t(ctx, "error.session_expired"), lastText())
+	})
+}
+
+func TestManagerSearch_ItemScope(t *testing.T) {
+	b, mocks := setupTestBot()
+	ctx := context.Background()
+
+	// Заявок на чужой аппарат больше лимита поиска: область роли должна попасть в запрос
+	day := time.Date(2030, 1, 10, 0, 0, 0, 0, time.UTC)
+	mocks.booking.bookings = make(map[int64]*models.Booking)
+	for id := int64(1); id <= models.MaxSearchResults+100; id++ {
+		mocks.booking.bookings[id] = &models.Booking{
+			ID: id, ItemID: 1, ItemName: "Item 1", UserName: "Клиент", Status: models.StatusPending, Date: day,
+		}
+	}
+	for id := int64(models.MaxSearchResults + 101); id <= models.MaxSearchResults+103; id++ {
+		mocks.booking.bookings[id] = &models.Booking{
+			ID: id, ItemID: 2, ItemName: "Item 2", UserName: "Клиент", Status: models.StatusPending, Date: day,
+		}
+	}
+	mocks.user.roles = map[int64]*models.UserRole{200: {TelegramID: 200, Role: models.RoleManager, ItemIDs: []int64{2}}}
+
+	search := func(filter *models.BookingFilter) string {
+		mocks.tg.clearSentMessages()
+		b.saveSearchFilter(ctx, 200, filter)
+		b.sendSearchResults(ctx, 200, 200, 0, 0)
+		sent := mocks.tg.getSentMessages()
+		require.NotEmpty(t, sent)
+		return sent[len(sent)-1].(tgbotapi.MessageConfig).Text
+	}
+
+	t.Run("AllItems", func(t *testing.T) {
+		text := search(&models.BookingFilter{ClientName: "клиент"})
+		assert.Contains(t, text, b.tn(ctx, "search.found", 3))
+	})
+
+	t.Run("ChosenItemsIntersected", func(t *testing.T) {
+		filter := &models.BookingFilter{ClientName: "клиент", ItemIDs: []int64{1, 2}}
+		assert.Contains(t, search(filter), b.tn(ctx, "search.found", 3))
+		assert.Equal(t, []int64{1, 2}, b.searchFilterFromState(b.getUserState(ctx, 200)).ItemIDs,
+			"the saved filter keeps the manager's choice")
+	})
+
+	t.Run("OnlyForeignItemsChosen", func(t *testing.T) {
+		assert.Contains(t, search(&models.BookingFilter{ItemIDs: []int64{1}}), b.t(ctx, "search.empty"))
+	})
+
+	t.Run("BulkCandidates", func(t *testing.T) {
+		candidates, err := b.bulkCandidates(ctx, 200, models.BookingFilter{Statuses: []string{models.StatusPending}})
+		require.NoError(t, err)
+		assert.Len(t, candidates, 3)
+	})
+
+	t.Run("UnscopedAdmin", func(t *testing.T) {
+		filter := models.BookingFilter{ItemIDs: []int64{1}}
+		assert.True(t, b.scopeBookingFilter(123, &filter))
+		assert.Equal(t, []int64{1}, filter.ItemIDs)
+	})
+}
+
+func TestBulkActions(t *testing.T) {
+	b, mocks := setupTestBot()
+	ctx := context.Background()
+
+	day := time.Date(2030, 1, 10, 0, 0, 0, 0, time.UTC)
+	mocks.booking.bookings = map[int64]*models.Booking{
+		1: {ID: 1, UserID: 501, ItemID: 1, ItemName: "Item 1", UserName: "Client 1", Status: models.StatusPending, Date: day, Version: 1},
+		2: {ID: 2, UserID: 502, ItemID: 1, ItemName: "Item 1", UserName: "Client 2", Status: models.StatusPending, Date: day, Version: 3},
+		3: {ID: 3, UserID: 503, ItemID: 1, ItemName: "Item 1", UserName: "Client 3", Status: models.StatusConfirmed, Date: day, Version: 1},
+		4: {ID: 4, UserID: 504, ItemID: 1, ItemName: "Item 1", UserName: "Client 4", Status: models.StatusPending, Date: day.AddDate(0, 0, 1), Version: 1},
+	}
+
+	callback := func(data string) {
+		b.handleCallbackQuery(ctx, &tgbotapi.Update{CallbackQuery: &tgbotapi.CallbackQuery{
+			From:    &tgbotapi.User{ID: 123},
+			Message: &tgbotapi.Message{Chat: &tgbotapi.Chat{ID: 123}, MessageID: 9},
+			Data:    data,
+		}})
+	}
+	textsTo := func(chatID int64) []string {
+		var texts []string
+		for _, c := range mocks.tg.getSentMessages() {
+			if msg, ok := c.(tgbotapi.MessageConfig); ok && msg.ChatID == chatID {
+				texts = append(texts, msg.Text)
+			}
+		}
+		return texts
+	}
+
+	t.Run("ConfirmPendingPreselectsDay", func(t *testing.T) {
+		mocks.tg.clearSentMessages()
+		b.handleMessage(ctx, &tgbotapi.Update{Message: &tgbotapi.Message{
+			Chat: &tgbotapi.Chat{ID: 123},
+			From: &tgbotapi.User{ID: 123},
+			Text: "/confirm_pending 10.01.2030",
+		}})
+
+		state := b.getUserState(ctx, 123)
+		require.NotNil(t, state)
+		assert.Equal(t, models.StateManagerBulkSelect, state.CurrentStep)
+		assert.Equal(t, map[int64]int64{1: 1, 2: 3}, parseBulkSelection(state.GetString(bulkSelectedKey)))
+
+		sent := mocks.tg.getSentMessages()
+		msg, ok := sent[len(sent)-1].(tgbotapi.MessageConfig)
+		require.True(t, ok)
+		assert.Contains(t, msg.Text, "Выбрано 2 заявки")
+		assert.NotContains(t, msg.Text, "Client 3")
+		assert.NotContains(t, msg.Text, "Client 4")
+
+		var data []string
+		for _, row := range msg.ReplyMarkup.(tgbotapi.InlineKeyboardMarkup).InlineKeyboard {
+			for _, btn := range row {
+				data = append(data, *btn.CallbackData)
+				if *btn.CallbackData == bulkTogglePrefix+"0:1" {
+					assert.True(t, strings.HasPrefix(btn.Text, "☑️"))
+				}
+			}
+		}
+		assert.Contains(t, data, bulkTogglePrefix+"0:1")
+		assert.Contains(t, data, bulkDoPrefix+models.BulkActionConfirm)
+	})
+
+	t.Run("ConfirmReportsConflicts", func(t *testing.T) {
+		// Другой менеджер успел изменить заявку после того, как ее отметили
+		mocks.booking.bookings[2].Version = 4
+		mocks.tg.clearSentMessages()
+
+		callback(bulkDoPrefix + models.BulkActionConfirm)
+
+		assert.Equal(t, models.StatusConfirmed, mocks.booking.bookings[1].Status)
+		assert.Equal(t, models.StatusPending, mocks.booking.bookings[2].Status)
+		assert.Len(t, textsTo(501), 1)
+		assert.Empty(t, textsTo(502))
+
+		summary := textsTo(123)
+		require.NotEmpty(t, summary)
+		assert.Contains(t, summary[0], b.t(ctx, "bulk.summary_succeeded", 1))
+		assert.Contains(t, summary[0], b.t(ctx, "bulk.summary_conflicts", 1, "#2"))
+		assert.Empty(t, parseBulkSelection(b.getUserState(ctx, 123).GetString(bulkSelectedKey)))
+	})
+
+	t.Run("ToggleThenReject", func(t *testing.T) {
+		callback(bulkTogglePrefix + "0:2")
+		assert.Equal(t, map[int64]int64{2: 4}, parseBulkSelection(b.getUserState(ctx, 123).GetString(bulkSelectedKey)))
+
+		callback(bulkDoPrefix + models.BulkActionReject)
+		assert.Equal(t, models.StatusCanceled, mocks.booking.bookings[2].Status)
+		assert.Len(t, textsTo(502), 1)
+	})
+
+	t.Run("CancelClosesSelection", func(t *testing.T) {
+		callback(bulkCancel)
+		assert.Nil(t, b.getUserState(ctx, 123))
+		assert.Contains(t, mocks.tg.editedTexts, b.t(ctx, "bulk.finished"))
+	})
+
+	t.Run("BadArguments", func(t *testing.T) {
+		mocks.tg.clearSentMessages()
+		b.handleMessage(ctx, &tgbotapi.Update{Message: &tgbotapi.Message{
+			Chat: &tgbotapi.Chat{ID: 123},
+			From: &tgbotapi.User{ID: 123},
+			Text: "/confirm_pending tomorrow",
+		}})
+		assert.Equal(t, []string{b.t(ctx, "bulk.confirm_pending_usage")}, textsTo(123))
+	})
+}
+
+func TestManagerDigest(t *testing.T) {
+	b, mocks := setupTestBot()
+	ctx := context.Background()
+	b.config.Bot.Digest = config.DigestConfig{Enabled: true, Time: "08:00", PendingAfterHours: 24}
+
+	now := time.Date(2030, 1, 10, 9, 0, 0, 0, time.UTC)
+	today, yesterday, tomorrow := now.Truncate(24*time.Hour), now.AddDate(0, 0, -1).Truncate(24*time.Hour), now.AddDate(0, 0, 1).Truncate(24*time.Hour)
+	mocks.booking.bookings = map[int64]*models.Booking{
+		1: {ID: 1, UserID: 501, ItemID: 1, ItemName: "Item 1", UserName: "Handout", Phone: "79990000001", Status: models.StatusConfirmed, Date: today},
+		2: {ID: 2, UserID: 502, ItemID: 1, ItemName: "Item 1", UserName: "Return", Phone: "79990000002", Status: models.StatusConfirmed, Date: yesterday},
+		3: {ID: 3, UserID: 503, ItemID: 1, ItemName: "Item 1", UserName: "Keeps", Status: models.StatusConfirmed, Date: yesterday},
+		4: {ID: 4, UserID: 503, ItemID: 1, ItemName: "Item 1", UserName: "Keeps", Status: models.StatusConfirmed, Date: today},
+		5: {ID: 5, UserID: 505, ItemID: 1, ItemName: "Item 1", UserName: "Stale", Status: models.StatusPending,
+			Date: today.AddDate(0, 0, 2), CreatedAt: now.Add(-30 * time.Hour)},
+		6: {ID: 6, UserID: 506, ItemID: 1, ItemName: "Item 1", UserName: "Fresh", Status: models.StatusPending,
+			Date: tomorrow, CreatedAt: now.Add(-time.Hour)},
+	}
+
+	mocks.tg.clearSentMessages()
+	b.sendManagerDigests(ctx, now)
+
+	var digest string
+	for _, c := range mocks.tg.getSentMessages() {
+		if msg, ok := c.(tgbotapi.MessageConfig); ok && msg.ChatID == 123 {
+			digest = msg.Text
+		}
+	}
+	require.NotEmpty(t, digest)
+
+	assert.Contains(t, digest, b.t(ctx, "digest.title", "10.01.2030"))
+	assert.Contains(t, digest, b.t(ctx, "digest.handouts", 1)+"\n"+b.t(ctx, "digest.booking_line", 1, "Item 1", "Handout", "79990000001"))
+	assert.Contains(t, digest, b.t(ctx, "digest.returns", 1)+"\n"+b.t(ctx, "digest.booking_line", 2, "Item 1", "Return", "79990000002"))
+	assert.NotContains(t, digest, "Keeps")
+	assert.Contains(t, digest, b.t(ctx, "digest.pending_line", 5, "Item 1", "12.01", "Stale", 30))
+	assert.NotContains(t, digest, "Fresh")
+	assert.Contains(t, digest, b.t(ctx, "digest.load_line", "Item 1", 1, 1))
+}
+
+func TestEscalation(t *testing.T) {
+	b, mocks := setupTestBot()
+	ctx := context.Background()
+	b.config.Bot.Escalation = config.EscalationConfig{Enabled: true, SLAHours: 4, Mode: models.EscalationModeReping, MaxRepeats: 2}
+
+	now := time.Date(2030, 1, 10, 9, 0, 0, 0, time.UTC)
+	mocks.booking.bookings = map[int64]*models.Booking{
+		7: {ID: 7, UserID: 507, ItemID: 1, ItemName: "Item 1", UserName: "Client", Status: models.StatusPending,
+			Date: now.AddDate(0, 0, 3).Truncate(24 * time.Hour), CreatedAt: now.Add(-5 * time.Hour)},
+		8: {ID: 8, UserID: 508, ItemID: 1, ItemName: "Item 1", UserName: "Recent", Status: models.StatusPending,
+			Date: now.AddDate(0, 0, 3).Truncate(24 * time.Hour), CreatedAt: now.Add(-time.Hour)},
+	}
+
+	escalationsTo := func(chatID int64) int {
+		count := 0
+		for _, c := range mocks.tg.getSentMessages() {
+			if msg, ok := c.(tgbotapi.MessageConfig); ok && msg.ChatID == chatID && msg.ReplyMarkup != nil {
+				count++
+			}
+		}
+		return count
+	}
+
+	t.Run("RepingRespectsSLAAndLimit", func(t *testing.T) {
+		mocks.tg.clearSentMessages()
+		b.escalateStaleBookings(ctx, now)
+		require.Equal(t, 1, escalationsTo(123))
+		sent := mocks.tg.getSentMessages()
+		assert.Contains(t, sent[0].(tgbotapi.MessageConfig).Text, b.t(ctx, "escalation.reping", 7, 5))
+		assert.Equal(t, 1, mocks.booking.escalations[7].Level)
+		assert.Nil(t, mocks.booking.escalations[8])
+
+		// Раньше чем через SLA повторно не напоминаем
+		b.escalateStaleBookings(ctx, now.Add(time.Hour))
+		assert.Equal(t, 1, escalationsTo(123))
+
+		b.escalateStaleBookings(ctx, now.Add(5*time.Hour))
+		assert.Equal(t, 3, escalationsTo(123)) // вторая эскалация #7 и первая #8
+		assert.Equal(t, 2, mocks.booking.escalations[7].Level)
+
+		// Лимит повторов исчерпан
+		b.escalateStaleBookings(ctx, now.Add(10*time.Hour))
+		assert.Equal(t, 2, mocks.booking.escalations[7].Level)
+	})
+
+	t.Run("AssignRotatesToAnotherManager", func(t *testing.T) {
+		mocks.booking.escalations = nil
+		mocks.user.roles = map[int64]*models.UserRole{200: {TelegramID: 200, Role: models.RoleManager}}
+		b.config.Bot.Escalation.Mode = models.EscalationModeAssign
+		delete(mocks.booking.bookings, 8)
+
+		mocks.tg.clearSentMessages()
+		b.escalateStaleBookings(ctx, now)
+		first := mocks.booking.escalations[7].AssignedTo
+		require.Contains(t, []int64{123, 200}, first)
+		assert.Equal(t, 1, escalationsTo(first))
+		assert.Equal(t, 1, escalationsTo(123)+escalationsTo(200))
+		assert.Contains(t, mocks.tg.getSentMessages()[0].(tgbotapi.MessageConfig).Text, b.t(ctx, "escalation.assigned", 7, 5))
+
+		b.escalateStaleBookings(ctx, now.Add(5*time.Hour))
+		second := mocks.booking.escalations[7].AssignedTo
+		assert.NotEqual(t, first, second)
+		assert.Equal(t, 1, escalationsTo(second))
+	})
+}
+
+func TestHandover(t *testing.T) {
+	b, mocks := setupTestBot()
+	ctx := context.Background()
+	b.config.Bot.Handover = config.HandoverConfig{ReturnTime: "12:00", OverdueCheckMinutes: 30, OverdueRepeatHours: 24}
+
+	day := time.Date(2030, 1, 10, 0, 0, 0, 0, time.UTC)
+	mocks.booking.bookings = map[int64]*models.Booking{
+		1: {ID: 1, UserID: 501, ItemID: 1, ItemName: "Item 1", UserName: "Client", Status: models.StatusConfirmed, Date: day, Version: 1},
+	}
+
+	callback := func(data string) {
+		b.handleCallbackQuery(ctx, &tgbotapi.Update{CallbackQuery: &tgbotapi.CallbackQuery{
+			From:    &tgbotapi.User{ID: 123},
+			Message: &tgbotapi.Message{Chat: &tgbotapi.Chat{ID: 123}, MessageID: 9},
+			Data:    data,
+		}})
+	}
+	buttonsOf := func(msg tgbotapi.MessageConfig) []string {
+		var keyboard tgbotapi.InlineKeyboardMarkup
+		switch markup := msg.ReplyMarkup.(type) {
+		case tgbotapi.InlineKeyboardMarkup:
+			keyboard = markup
+		case *tgbotapi.InlineKeyboardMarkup:
+			keyboard = *markup
+		}
+		var data []string
+		for _, row := range keyboard.InlineKeyboard {
+			for _, btn := range row {
+				data = append(data, *btn.CallbackData)
+			}
+		}
+		return data
+	}
+	lastTo := func(chatID int64) tgbotapi.MessageConfig {
+		sent := mocks.tg.getSentMessages()
+		for i := len(sent) - 1; i >= 0; i-- {
+			if msg, ok := sent[i].(tgbotapi.MessageConfig); ok && msg.ChatID == chatID {
+				return msg
+			}
+		}
+		t.Fatalf("no message to %d", chatID)
+		return tgbotapi.MessageConfig{}
+	}
+	deadline := time.Date(2030, 1, 11, 12, 0, 0, 0, time.Local)
+
+	t.Run("CheckOutWithNoteAndPhoto", func(t *testing.T) {
+		callback("show_booking:1")
+		assert.Contains(t, buttonsOf(lastTo(123)), handoverOutPrefix+"1")
+
+		callback(handoverOutPrefix + "1")
+		state := b.getUserState(ctx, 123)
+		require.NotNil(t, state)
+		assert.Equal(t, models.StateManagerHandover, state.CurrentStep)
+
+		b.handleMessage(ctx, &tgbotapi.Update{Message: &tgbotapi.Message{
+			Chat: &tgbotapi.Chat{ID: 123}, From: &tgbotapi.User{ID: 123}, Text: "царапина на корпусе",
+		}})
+		b.handleMessage(ctx, &tgbotapi.Update{Message: &tgbotapi.Message{
+			Chat: &tgbotapi.Chat{ID: 123}, From: &tgbotapi.User{ID: 123}, Caption: "кейс",
+			Photo: []tgbotapi.PhotoSize{{FileID: "small"}, {FileID: "large"}},
+		}})
+		assert.Equal(t, b.t(ctx, "handover.noted", 1), lastTo(123).Text)
+
+		mocks.tg.clearSentMessages()
+		callback(handoverDone)
+
+		h := mocks.booking.handovers[1]
+		require.NotNil(t, h)
+		assert.Equal(t, "царапина на корпусе\nкейс", h.CheckOutNote)
+		assert.Equal(t, []string{"large"}, h.CheckOutPhotos)
+		assert.Equal(t, int64(123), h.CheckedOutBy)
+		assert.Nil(t, b.getUserState(ctx, 123))
+
+		assert.Equal(t, b.t(ctx, "handover.client_out", "Item 1", deadline.Format("02.01.2006 15:04")), lastTo(501).Text)
+		card := lastTo(123)
+		assert.Contains(t, card.Text, b.t(ctx, "handover.detail_due", deadline.Format("02.01.2006 15:04")))
+		assert.Contains(t, buttonsOf(card), handoverInPrefix+"1")
+		assert.Contains(t, buttonsOf(card), handoverPhotosPrefix+"1")
+		assert.NotContains(t, buttonsOf(card), handoverOutPrefix+"1")
+	})
+
+	t.Run("OverdueAlertRepeats", func(t *testing.T) {
+		alerts := func() int {
+			count := 0
+			for _, c := range mocks.tg.getSentMessages() {
+				if msg, ok := c.(tgbotapi.MessageConfig); ok && msg.ChatID == 123 && slices.Contains(buttonsOf(msg), "show_booking:1") {
+					count++
+				}
+			}
+			return count
+		}
+
+		mocks.tg.clearSentMessages()
+		b.alertOverdueReturns(ctx, deadline.Add(-time.Hour))
+		assert.Equal(t, 0, alerts())
+
+		b.alertOverdueReturns(ctx, deadline.Add(2*time.Hour))
+		require.Equal(t, 1, alerts())
+		assert.Equal(t, b.t(ctx, "handover.overdue_alert", "Item 1", 1, "Client", deadline.Format("02.01.2006 15:04"), 2), lastTo(123).Text)
+
+		b.alertOverdueReturns(ctx, deadline.Add(3*time.Hour))
+		assert.Equal(t, 1, alerts())
+
+		b.alertOverdueReturns(ctx, deadline.Add(27*time.Hour))
+		assert.Equal(t, 2, alerts())
+	})
+
+	t.Run("CheckInCompletesBooking", func(t *testing.T) {
+		callback(handoverInPrefix + "1")
+		b.handleMessage(ctx, &tgbotapi.Update{Message: &tgbotapi.Message{
+			Chat: &tgbotapi.Chat{ID: 123}, From: &tgbotapi.User{ID: 123}, Text: "без повреждений",
+		}})
+		callback(handoverDone)
+
+		h := mocks.booking.handovers[1]
+		assert.True(t, h.IsReturned())
+		assert.Equal(t, "без повреждений", h.CheckInNote)
+		assert.Equal(t, models.StatusCompleted, mocks.booking.bookings[1].Status)
+		assert.Equal(t, b.t(ctx, "handover.client_in", "Item 1"), lastTo(501).Text)
+
+		// Повторный прием того же аппарата невозможен
+		callback(handoverInPrefix + "1")
+		callback(handoverDone)
+		assert.Equal(t, b.t(ctx, "error.not_checked_out"), lastTo(123).Text)
+	})
+
+	t.Run("CancelClearsState", func(t *testing.T) {
+		mocks.booking.bookings[2] = &models.Booking{ID: 2, UserID: 502, ItemID: 1, ItemName: "Item 1", Status: models.StatusConfirmed, Date: day}
+		callback(handoverOutPrefix + "2")
+		callback(handoverCancel)
+		assert.Nil(t, b.getUserState(ctx, 123))
+		assert.Nil(t, mocks.booking.handovers[2])
+	})
+}
+
+func TestMaintenance(t *testing.T) {
+	b, mocks := setupTestBot()
+	ctx := context.Background()
+	mocks.item.setItems([]*models.Item{{ID: 1, Name: "Item 1", TotalQuantity: 2, IsActive: true}})
+	_ = mocks.user.SaveUser(ctx, &models.User{TelegramID: 124, IsManager: true})
+
+	start := time.Now().AddDate(0, 0, 10).Truncate(24 * time.Hour)
+	mocks.item.overflow = []*models.MaintenanceOverflow{{
+		Date:     start,
+		Capacity: 1,
+		Bookings: []*models.Booking{
+			{ID: 5, ItemID: 1, UserName: "Client 5", Status: models.StatusConfirmed, Date: start},
+			{ID: 6, ItemID: 1, UserName: "Client 6", Status: models.StatusPending, Date: start},
+		},
+	}}
+
+	send := func(text string) {
+		b.handleMessage(ctx, &tgbotapi.Update{Message: &tgbotapi.Message{
+			Chat: &tgbotapi.Chat{ID: 123},
+			From: &tgbotapi.User{ID: 123},
+			Text: text,
+		}})
+	}
+	textsTo := func(chatID int64) []string {
+		var texts []string
+		for _, c := range mocks.tg.getSentMessages() {
+			if msg, ok := c.(tgbotapi.MessageConfig); ok && msg.ChatID == chatID {
+				texts = append(texts, msg.Text)
+			}
+		}
+		return texts
+	}
+
+	t.Run("AddWarnsAboutOverflow", func(t *testing.T) {
+		mocks.tg.clearSentMessages()
+		send(fmt.Sprintf("/maintenance_add 1 1 %s %s ремонт объектива",
+			start.Format("02.01.2006"), start.AddDate(0, 0, 13).Format("02.01.2006")))
+
+		require.Len(t, mocks.item.maintenance, 1)
+		window := mocks.item.maintenance[0]
+		assert.Equal(t, int64(1), window.Units)
+		assert.Equal(t, start.AddDate(0, 0, 13).Format("02.01.2006"), window.EndDate.Format("02.01.2006"))
+		assert.Equal(t, "ремонт объектива", window.Reason)
+		assert.Equal(t, int64(123), window.CreatedBy)
+
+		texts := textsTo(123)
+		require.Len(t, texts, 2)
+		assert.Contains(t, texts[0], "Item 1: 1 шт. на обслуживании")
+		assert.Contains(t, texts[1], "в работе 1, заявок 2")
+		assert.Contains(t, texts[1], "/manager_booking_5")
+		assert.Contains(t, texts[1], "/manager_booking_6")
+
+		// Остальные менеджеры аппарата тоже получают предупреждение
+		require.Len(t, textsTo(124), 1)
+		assert.Contains(t, textsTo(124)[0], "/manager_booking_6")
+	})
+
+	t.Run("List", func(t *testing.T) {
+		mocks.tg.clearSentMessages()
+		send("/maintenance")
+		texts := textsTo(123)
+		require.Len(t, texts, 1)
+		assert.Contains(t, texts[0], "#1 Item 1: 1 шт.")
+		assert.Contains(t, texts[0], "Причина: ремонт объектива")
+	})
+
+	t.Run("InvalidInput", func(t *testing.T) {
+		mocks.tg.clearSentMessages()
+		send("/maintenance_add 1 one 01.01.2030")
+		send(fmt.Sprintf("/maintenance_add 1 1 %s %s",
+			start.Format("02.01.2006"), start.AddDate(0, 0, -1).Format("02.01.2006")))
+		send("/maintenance_end 99")
+		assert.Equal(t, []string{
+			b.t(ctx, "maintenance.add_usage"),
+			b.t(ctx, "error.maintenance_period"),
+			b.t(ctx, "error.maintenance_not_found"),
+		}, textsTo(123))
+	})
+
+	t.Run("End", func(t *testing.T) {
+		mocks.tg.clearSentMessages()
+		send("/maintenance_end 1")
+		assert.Empty(t, mocks.item.maintenance)
+		assert.Equal(t, []string{b.t(ctx, "maintenance.ended", "Item 1", 1)}, textsTo(123))
+	})
+
+	t.Run("ExternalCalendar", func(t *testing.T) {
+		day := start.AddDate(0, 0, 2)
+		path := filepath.Join(t.TempDir(), "partner.ics")
+		require.NoError(t, os.WriteFile(path, []byte("BEGIN:VCALENDAR\r\nBEGIN:VEVENT\r\nUID:1\r\nSUMMARY:Свадьба\r\n"+
+			"DTSTART;VALUE=DATE:"+day.Format("20060102")+"\r\nEND:VEVENT\r\nEND:VCALENDAR\r\n"), 0o600))
+		b.config.ExternalCalendars = config.ExternalCalendarsConfig{Sources: []config.ExternalCalendarSource{
+			{Name: "partner", ItemID: 1, File: path, Units: 1},
+		}}
+
+		mocks.tg.clearSentMessages()
+		b.syncExternalCalendars(ctx, extcal.NewSyncer(b.config.ExternalCalendars, mocks.item, b.logger))
+		require.Len(t, mocks.item.maintenance, 1)
+		hold := mocks.item.maintenance[0]
+		assert.Equal(t, "partner", hold.Source)
+		assert.Equal(t, day.Format("02.01.2006"), hold.StartDate.Format("02.01.2006"))
+
+		// Сотрудники аппарата узнают, что внешняя бронь пересеклась с заявками
+		require.Len(t, textsTo(124), 1)
+		assert.Contains(t, textsTo(124)[0], b.t(ctx, "maintenance.external_overflow", "Item 1"))
+		assert.Contains(t, textsTo(124)[0], "/manager_booking_5")
+
+		mocks.tg.clearSentMessages()
+		send("/maintenance")
+		require.Len(t, textsTo(123), 1)
+		assert.Contains(t, textsTo(123)[0], "Свадьба")
+		assert.Contains(t, textsTo(123)[0], b.t(ctx, "maintenance.external", "partner"))
+
+		mocks.tg.clearSentMessages()
+		send(fmt.Sprintf("/maintenance_end %d", hold.ID))
+		assert.Equal(t, []string{b.t(ctx, "error.external_hold")}, textsTo(123))
+		assert.Len(t, mocks.item.maintenance, 1)
+	})
+}
+
+func TestUnits(t *testing.T) {
+	b, mocks := setupTestBot()
+	ctx := context.Background()
+	mocks.item.setItems([]*models.Item{{ID: 1, Name: "Item 1", TotalQuantity: 2, IsActive: true}})
+
+	send := func(text string) {
+		b.handleMessage(ctx, &tgbotapi.Update{Message: &tgbotapi.Message{
+			Chat: &tgbotapi.Chat{ID: 123},
+			From: &tgbotapi.User{ID: 123},
+			Text: text,
+		}})
+	}
+	texts := func() []string {
+		var texts []string
+		for _, c := range mocks.tg.getSentMessages() {
+			if msg, ok := c.(tgbotapi.MessageConfig); ok {
+				texts = append(texts, msg.Text)
+			}
+		}
+		return texts
+	}
+
+	t.Run("AddAndList", func(t *testing.T) {
+		mocks.tg.clearSentMessages()
+		send("/unit_add 1 SN-1 INV-7")
+		send("/unit_add 1 SN-1")
+		require.Len(t, mocks.item.units, 1)
+		assert.Equal(t, "INV-7", mocks.item.units[0].InventoryTag)
+
+		send("/units 1")
+		got := texts()
+		require.Len(t, got, 3)
+		assert.Equal(t, b.t(ctx, "units.added", "SN-1", "Item 1", 1), got[0])
+		assert.Equal(t, b.t(ctx, "error.duplicate_serial"), got[1])
+		assert.Contains(t, got[2], "#1 S/N SN-1")
+		assert.Contains(t, got[2], "Инв. номер: INV-7")
+	})
+
+	t.Run("Status", func(t *testing.T) {
+		mocks.tg.clearSentMessages()
+		send("/unit_status SN-1 repair треснул корпус")
+		send("/unit_status SN-1 lost")
+		send("/unit_status SN-9 active")
+		assert.Equal(t, models.UnitStatusRepair, mocks.item.units[0].Status)
+		assert.Equal(t, "треснул корпус", mocks.item.units[0].Note)
+		assert.Equal(t, []string{
+			b.t(ctx, "units.status_changed", "SN-1", b.t(ctx, "unit_status.repair")),
+			b.t(ctx, "error.invalid_unit_status"),
+			b.t(ctx, "error.unit_not_found"),
+		}, texts())
+	})
+
+	t.Run("History", func(t *testing.T) {
+		mocks.tg.clearSentMessages()
+		send("/unit_history SN-1")
+		got := texts()
+		require.Len(t, got, 1)
+		assert.Contains(t, got[0], "S/N SN-1 (Item 1)")
+		assert.Contains(t, got[0], b.t(ctx, "units.history_no_bookings"))
+	})
+
+	t.Run("BookingDetail", func(t *testing.T) {
+		mocks.tg.clearSentMessages()
+		booking := &models.Booking{ID: 7, ItemID: 1, ItemName: "Item 1", Status: models.StatusConfirmed, UnitID: 1}
+		b.sendManagerBookingDetail(ctx, 123, booking)
+		got := texts()
+		require.Len(t, got, 1)
+		assert.Contains(t, got[0], b.t(ctx, "units.booking_line", 1, "SN-1"))
+	})
+}
+
+func TestItemEditor(t *testing.T) {
+	b, mocks := setupTestBot()
+	ctx := context.Background()
+	mocks.item.setItems([]*models.Item{{ID: 1, Name: "Item 1", TotalQuantity: 2, IsActive: true}})
+
+	send := func(text string, photos ...string) {
+		msg := &tgbotapi.Message{Chat: &tgbotapi.Chat{ID: 123}, From: &tgbotapi.User{ID: 123}, Text: text}
+		for _, id := range photos {
+			msg.Photo = append(msg.Photo, tgbotapi.PhotoSize{FileID: id + "-small"}, tgbotapi.PhotoSize{FileID: id})
+		}
+		b.handleMessage(ctx, &tgbotapi.Update{Message: msg})
+	}
+	callback := func(data string) {
+		b.handleCallbackQuery(ctx, &tgbotapi.Update{CallbackQuery: &tgbotapi.CallbackQuery{
+			From:    &tgbotapi.User{ID: 123},
+			Message: &tgbotapi.Message{Chat: &tgbotapi.Chat{ID: 123}, MessageID: 9},
+			Data:    data,
+		}})
+	}
+	lastText := func() string {
+		sent := mocks.tg.getSentMessages()
+		require.NotEmpty(t, sent)
+		msg, ok := sent[len(sent)-1].(tgbotapi.MessageConfig)
+		require.True(t, ok)
+		return msg.Text
+	}
+
+	t.Run("AddWalksThroughFields", func(t *testing.T) {
+		send("/add_item")
+		assert.Equal(t, b.t(ctx, "item_editor.prompt_name"), lastText())
+		send("Лазер Alma")
+		send("ноль")
+		assert.Equal(t, b.t(ctx, "item_editor.invalid_quantity"), lastText())
+		send("2")
+		send(" Лазеры / Диодные ")
+		send("-")
+		send("3 500")
+		assert.Equal(t, b.t(ctx, "item_editor.prompt_weekend_price"), lastText())
+		send("4 000")
+		send("-")
+		assert.Equal(t, b.t(ctx, "item_editor.prompt_discount"), lastText())
+		send("7")
+		assert.Equal(t, b.t(ctx, "item_editor.invalid_discount"), lastText())
+		send("7 10%")
+		send("Мощность 1200")
+		assert.Equal(t, b.t(ctx, "item_editor.invalid_specs"), lastText())
+		send("Мощность: 1200 Вт\nВес: 40 кг")
+		assert.Equal(t, b.t(ctx, "item_editor.prompt_photos"), lastText())
+		send("", "photo-1")
+		assert.Equal(t, b.t(ctx, "item_editor.photos_noted", 1), lastText())
+		callback(itemEditorPhotosDone)
+		assert.Contains(t, lastText(), "🔢 Количество: 2")
+		require.Len(t, mocks.item.items, 1, "аппарат сохраняется только по кнопке")
+
+		callback(itemEditorSave)
+		require.Len(t, mocks.item.items, 2)
+		item := mocks.item.items[1]
+		assert.Equal(t, "Лазер Alma", item.Name)
+		assert.Equal(t, int64(2), item.TotalQuantity)
+		assert.Equal(t, "Лазеры/Диодные", item.Category)
+		assert.Empty(t, item.Description)
+		assert.Equal(t, int64(3500), item.PricePerDay)
+		assert.Equal(t, int64(4000), item.WeekendPrice)
+		assert.Zero(t, item.Deposit)
+		assert.Equal(t, int64(7), item.LongRentalDays)
+		assert.Equal(t, int64(10), item.LongRentalDiscount)
+		assert.Equal(t, []models.ItemSpec{{Name: "Мощность", Value: "1200 Вт"}, {Name: "Вес", Value: "40 кг"}}, item.Specs)
+		assert.Equal(t, []string{"photo-1"}, item.PhotoFileIDs)
+		assert.True(t, item.IsActive)
+		assert.Equal(t, b.t(ctx, "item_editor.created", "Лазер Alma", item.ID), lastText())
+		assert.Nil(t, b.getUserState(ctx, 123))
+	})
+
+	t.Run("AddWithoutPriceSkipsRates", func(t *testing.T) {
+		send("/add_item Кресло")
+		send("1")
+		send("-")
+		send("-")
+		send("-")
+		assert.Equal(t, b.t(ctx, "item_editor.prompt_deposit"), lastText())
+		send("5000")
+		assert.Equal(t, b.t(ctx, "item_editor.prompt_specs"), lastText())
+		callback(itemEditorCancel)
+	})
+
+	t.Run("EditChangesSingleField", func(t *testing.T) {
+		send("/edit_item 1")
+		assert.Contains(t, lastText(), "Item 1")
+		callback(itemEditorFieldPref + itemFieldPrice)
+		assert.Equal(t, b.t(ctx, "item_editor.prompt_price"), lastText())
+		send("1200")
+		assert.Contains(t, lastText(), b.t(ctx, "items.price", 1200))
+		assert.Zero(t, mocks.item.items[0].PricePerDay, "до сохранения аппарат не меняется")
+
+		callback(itemEditorSave)
+		assert.Equal(t, int64(1200), mocks.item.items[0].PricePerDay)
+		assert.Equal(t, "Item 1", mocks.item.items[0].Name)
+	})
+
+	t.Run("Cancel", func(t *testing.T) {
+		send("/edit_item Item 1")
+		callback(itemEditorCancel)
+		assert.Equal(t, b.t(ctx, "item_editor.canceled"), lastText())
+		assert.Nil(t, b.getUserState(ctx, 123))
+	})
+}
+
+func TestItemCatalog(t *testing.T) {
+	b, mocks := setupTestBot()
+	ctx := context.Background()
+	mocks.item.setItems([]*models.Item{
+		{ID: 1, Name: "Alma", TotalQuantity: 1, IsActive: true, Category: "Лазеры/Диодные", PricePerDay: 3500,
+			PhotoFileIDs: []string{"photo-1"}, Specs: []models.ItemSpec{{Name: "Мощность", Value: "1200 Вт"}}},
+		{ID: 2, Name: "Candela", TotalQuantity: 1, IsActive: true, Category: "Лазеры"},
+		{ID: 3, Name: "Кресло", TotalQuantity: 1, IsActive: true},
+	})
+
+	callback := func(data string) {
+		b.handleCallbackQuery(ctx, &tgbotapi.Update{CallbackQuery: &tgbotapi.CallbackQuery{
+			From:    &tgbotapi.User{ID: 456},
+			Message: &tgbotapi.Message{Chat: &tgbotapi.Chat{ID: 456}, MessageID: 9},
+			Data:    data,
+		}})
+	}
+	lastPage := func() (string, []string) {
+		sent := mocks.tg.getSentMessages()
+		require.NotEmpty(t, sent)
+		var text string
+		var markup *tgbotapi.InlineKeyboardMarkup
+		switch msg := sent[len(sent)-1].(type) {
+		case tgbotapi.MessageConfig:
+			text, markup = msg.Text, msg.ReplyMarkup.(*tgbotapi.InlineKeyboardMarkup)
+		case tgbotapi.EditMessageTextConfig:
+			text, markup = msg.Text, msg.ReplyMarkup
+		default:
+			t.Fatalf("unexpected message %T", msg)
+		}
+		var data []string
+		for _, row := range markup.InlineKeyboard {
+			for _, btn := range row {
+				data = append(data, *btn.CallbackData)
+			}
+		}
+		return text, data
+	}
+
+	// Категории: 0 - "Лазеры", 1 - "Лазеры/Диодные"
+	callback("start_the_order")
+	text, buttons := lastPage()
+	assert.Contains(t, text, "Кресло")
+	assert.NotContains(t, text, "Candela")
+	assert.Contains(t, buttons, catalogCategoryPrefix+"0")
+
+	callback(catalogCategoryPrefix + "0")
+	text, buttons = lastPage()
+	assert.Contains(t, text, "Candela")
+	assert.NotContains(t, text, "Alma")
+	assert.Contains(t, buttons, catalogCategoryPrefix+"1")
+	assert.Contains(t, buttons, catalogCategoryPrefix+"-1")
+
+	callback(catalogCategoryPrefix + "1")
+	text, buttons = lastPage()
+	assert.Contains(t, text, "Alma")
+	assert.Contains(t, text, b.t(ctx, "items.price", 3500))
+	assert.Contains(t, buttons, catalogCategoryPrefix+"0")
+	assert.Equal(t, "Лазеры/Диодные", b.getUserState(ctx, 456).GetString(catalogCategoryKey))
+
+	mocks.tg.clearSentMessages()
+	callback("select_item:1")
+	sent := mocks.tg.getSentMessages()
+	require.Len(t, sent, 2)
+	photo, ok := sent[0].(tgbotapi.PhotoConfig)
+	require.True(t, ok)
+	assert.Equal(t, tgbotapi.FileID("photo-1"), photo.File)
+	assert.Contains(t, photo.Caption, "Мощность: 1200 Вт")
+	assert.Contains(t, photo.Caption, "Лазеры › Диодные")
+}
+
+func TestInvoiceCommands(t *testing.T) {
+	b, mocks := setupTestBot()
+	ctx := context.Background()
+
+	tmpDir, err := os.MkdirTemp("", "bronivik_invoice")
+	require.NoError(t, err)
+	defer os.RemoveAll(tmpDir)
+	b.config.Exports.Path = tmpDir
+
+	day := func(d int) time.Time { return time.Date(2030, 3, d, 0, 0, 0, 0, time.UTC) }
+	mocks.booking.setBookings(map[int64]*models.Booking{
+		1: {ID: 1, UserID: 5, ItemID: 1, ItemName: "Camera", UserName: "Иван", Phone: "79991234567",
+			Date: day(1), Status: models.StatusConfirmed, Price: 1000, Deposit: 5000},
+		2: {ID: 2, UserID: 5, ItemID: 1, ItemName: "Camera", UserName: "Иван", Phone: "79991234567",
+			Date: day(2), Status: models.StatusCompleted, Price: 1500},
+		3: {ID: 3, UserID: 6, ItemID: 2, ItemName: "Tripod", UserName: "Петр", Phone: "79990000000",
+			Date: day(3), Status: models.StatusPending},
+	})
+
+	send := func(text string) tgbotapi.Chattable {
+		mocks.tg.clearSentMessages()
+		b.handleMessage(ctx, &tgbotapi.Update{Message: &tgbotapi.Message{
+			Chat: &tgbotapi.Chat{ID: 123}, From: &tgbotapi.User{ID: 123}, Text: text,
+		}})
+		sent := mocks.tg.getSentMessages()
+		require.NotEmpty(t, sent)
+		return sent[len(sent)-1]
+	}
+	cell := func(file, axis string) string {
+		f, err := excelize.OpenFile(filepath.Join(tmpDir, file))
+		require.NoError(t, err)
+		defer f.Close()
+		value, err := f.GetCellValue("Счет", axis)
+		require.NoError(t, err)
+		return value
+	}
+
+	t.Run("Booking", func(t *testing.T) {
+		doc, ok := send("/invoice 2").(tgbotapi.DocumentConfig)
+		require.True(t, ok)
+		assert.Equal(t, b.t(ctx, "invoice.caption_booking", 2), doc.Caption)
+		assert.Equal(t, "01.03.2030", cell("invoice_2.xlsx", "A6"))
+		assert.Equal(t, "2500", cell("invoice_2.xlsx", "E8"))
+		assert.Equal(t, "5000", cell("invoice_2.xlsx", "F8"))
+		assert.Equal(t, "7500", cell("invoice_2.xlsx", "E9"))
+	})
+
+	t.Run("ClientMonth", func(t *testing.T) {
+		doc, ok := send("/invoice_client 89991234567 03.2030").(tgbotapi.DocumentConfig)
+		require.True(t, ok)
+		assert.Equal(t, b.t(ctx, "invoice.caption_client", "Иван", "03.2030"), doc.Caption)
+		assert.Equal(t, "Camera", cell("invoice_79991234567_2030-03.xlsx", "C6"))
+		assert.Equal(t, "2500", cell("invoice_79991234567_2030-03.xlsx", "E8"))
+
+		msg := send("/invoice_client 89991234567 04.2030").(tgbotapi.MessageConfig)
+		assert.Equal(t, b.t(ctx, "invoice.client_empty", "89991234567", "04.2030"), msg.Text)
+	})
+
+	t.Run("BookingDetailShowsPrice", func(t *testing.T) {
+		mocks.tg.clearSentMessages()
+		booking, _ := mocks.booking.GetBooking(ctx, 1)
+		b.sendManagerBookingDetail(ctx, 123, booking)
+		text := mocks.tg.getSentMessages()[0].(tgbotapi.MessageConfig).Text
+		assert.Contains(t, text, b.t(ctx, "booking.price_day", 1000))
+		assert.Contains(t, text, b.t(ctx, "booking.price_total", "01.03.2030", "02.03.2030", 2, 2500))
+		assert.Contains(t, text, b.t(ctx, "booking.price_deposit", 5000))
+	})
+
+	t.Run("Errors", func(t *testing.T) {
+		assert.Equal(t, b.t(ctx, "invoice.usage"), send("/invoice").(tgbotapi.MessageConfig).Text)
+		assert.Equal(t, b.t(ctx, "invoice.client_usage"), send("/invoice_client 123 03.2030").(tgbotapi.MessageConfig).Text)
+		assert.Equal(t, b.t(ctx, "invoice.not_found", 99), send("/invoice 99").(tgbotapi.MessageConfig).Text)
+		assert.Equal(t, b.t(ctx, "invoice.not_priced"), send("/invoice 3").(tgbotapi.MessageConfig).Text)
+	})
+}
+
+func TestBookingExportCommand(t *testing.T) {
+	b, mocks := setupTestBot()
+	ctx := context.Background()
+
+	tmpDir, err := os.MkdirTemp("", "bronivik_bookings_export")
+	require.NoError(t, err)
+	defer os.RemoveAll(tmpDir)
+	b.config.Exports.Path = tmpDir
+
+	mocks.user.roles = map[int64]*models.UserRole{
+		200: {TelegramID: 200, Role: models.RoleManager, ItemIDs: []int64{2}},
+		300: {TelegramID: 300, Role: models.RoleViewer},
+	}
+	day := func(d int) time.Time { return time.Date(2030, 3, d, 0, 0, 0, 0, time.UTC) }
+	mocks.booking.setBookings(map[int64]*models.Booking{
+		1: {ID: 1, ItemID: 1, ItemName: "Camera", UserName: "Иван", Date: day(1), Status: models.StatusConfirmed, Price: 1000},
+		2: {ID: 2, ItemID: 2, ItemName: "Tripod", UserName: "Петр", Date: day(2), Status: models.StatusCanceled},
+		3: {ID: 3, ItemID: 2, ItemName: "Tripod", UserName: "Анна", Date: day(20), Status: models.StatusConfirmed},
+	})
+
+	send := func(userID int64, text string) tgbotapi.Chattable {
+		mocks.tg.clearSentMessages()
+		b.handleMessage(ctx, &tgbotapi.Update{Message: &tgbotapi.Message{
+			Chat: &tgbotapi.Chat{ID: userID}, From: &tgbotapi.User{ID: userID}, Text: text,
+		}})
+		sent := mocks.tg.getSentMessages()
+		require.NotEmpty(t, sent)
+		return sent[len(sent)-1]
+	}
+	lines := func(file string) []string {
+		data, err := os.ReadFile(filepath.Join(tmpDir, file))
+		require.NoError(t, err)
+		return strings.Split(strings.TrimSpace(string(data)), "\n")
+	}
+
+	t.Run("CSV", func(t *testing.T) {
+		doc, ok := send(123, "/export_bookings 01.03.2030 10.03.2030").(tgbotapi.DocumentConfig)
+		require.True(t, ok)
+		assert.Equal(t, b.t(ctx, "export.caption", "01.03.2030", "10.03.2030", 2), doc.Caption)
+		rows := lines("bookings_2030-03-01_2030-03-10.csv")
+		require.Len(t, rows, 3)
+		assert.True(t, strings.HasPrefix(rows[1], "1,2030-03-01,confirmed,1,Camera"))
+	})
+
+	t.Run("FormatAndFilters", func(t *testing.T) {
+		_, ok := send(123, "/export_bookings 01.03.2030 31.03.2030 jsonl confirmed 2").(tgbotapi.DocumentConfig)
+		require.True(t, ok)
+		rows := lines("bookings_2030-03-01_2030-03-31.jsonl")
+		require.Len(t, rows, 1)
+		assert.Contains(t, rows[0], `"user_name":"Анна"`)
+	})
+
+	t.Run("ScopedManager", func(t *testing.T) {
+		doc, ok := send(200, "/export_bookings 01.03.2030 31.03.2030 xlsx").(tgbotapi.DocumentConfig)
+		require.True(t, ok)
+		assert.Equal(t, b.t(ctx, "export.caption", "01.03.2030", "31.03.2030", 2), doc.Caption)
+
+		msg := send(200, "/export_bookings 01.03.2030 31.03.2030 1").(tgbotapi.MessageConfig)
+		assert.Equal(t, b.t(ctx, msgAccessDenied), msg.Text)
+	})
+
+	t.Run("Denied", func(t *testing.T) {
+		msg := send(300, "/export_bookings 01.03.2030 31.03.2030").(tgbotapi.MessageConfig)
+		assert.Equal(t, b.t(ctx, msgAccessDenied), msg.Text)
+	})
+
+	t.Run("Usage", func(t *testing.T) {
+		for _, text := range []string{"/export_bookings", "/export_bookings 10.03.2030 01.03.2030", "/export_bookings 01.03.2030 10.03.2030 pdf"} {
+			msg := send(123, text).(tgbotapi.MessageConfig)
+			assert.Equal(t, b.t(ctx, "export.usage"), msg.Text, text)
+		}
+	})
+}
+
+func TestImportCommand(t *testing.T) {
+	b, mocks := setupTestBot()
+	ctx := context.Background()
+
+	files := map[string]string{
+		"bookings.csv": strings.Join([]string{
+			"Дата заявки;Техника;Клиент;Телефон",
+			"10.04.2030;Item 1;Иван;89990000001",
+			"10.04.2030;Item 1;Иван;+7 999 000-00-01",
+			"11.04.2030;Item 1;Анна;",
+			"12.04.2030;Item 9;Олег;",
+			"13.04.2030;Item 1;;",
+		}, "\n"),
+		"items.csv":  "name,total_quantity\nTripod,2\nItem 1,1\n",
+		"notes.txt":  "text",
+		"broken.csv": "date,item\n10.04.2030,Item 1\n",
+	}
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, files[strings.TrimPrefix(r.URL.Path, "/")])
+	}))
+	defer ts.Close()
+	mocks.tg.files = make(map[string]string)
+	for name := range files {
+		mocks.tg.files[name] = ts.URL + "/" + name
+	}
+	mocks.booking.fullyBooked = map[string]bool{"2030-04-11": true}
+	mocks.user.roles = map[int64]*models.UserRole{
+		200: {TelegramID: 200, Role: models.RoleManager, ItemIDs: []int64{2}},
+	}
+
+	send := func(userID int64, fileName, caption string) string {
+		mocks.tg.clearSentMessages()
+		msg := &tgbotapi.Message{Chat: &tgbotapi.Chat{ID: userID}, From: &tgbotapi.User{ID: userID}, Text: caption}
+		if fileName != "" {
+			msg.Text, msg.Caption = "", caption
+			msg.Document = &tgbotapi.Document{FileID: fileName, FileName: fileName}
+		}
+		b.handleMessage(ctx, &tgbotapi.Update{Message: msg})
+		sent := mocks.tg.getSentMessages()
+		require.NotEmpty(t, sent)
+		return sent[len(sent)-1].(tgbotapi.MessageConfig).Text
+	}
+
+	t.Run("DryRun", func(t *testing.T) {
+		text := send(123, "bookings.csv", "/import bookings dry date=Дата заявки, item=Техника")
+		assert.Contains(t, text, b.t(ctx, "import.report_dry", "bookings", 5, 1, 1, 3))
+		assert.Contains(t, text, "item ← Техника")
+		assert.Contains(t, text, b.t(ctx, "import.duplicates", "3"))
+		assert.Contains(t, text, b.t(ctx, "import.row_error", 4, b.t(ctx, "import.err_no_capacity")))
+		assert.Contains(t, text, b.t(ctx, "import.row_error", 5, b.t(ctx, "import.err_unknown_item", "Item 9")))
+		assert.Contains(t, text, b.t(ctx, "import.row_error", 6, b.t(ctx, "import.err_required", "Клиент")))
+		assert.Contains(t, text, b.t(ctx, "import.dry_hint"))
+		assert.Empty(t, mocks.booking.bookings)
+	})
+
+	t.Run("Apply", func(t *testing.T) {
+		text := send(123, "bookings.csv", "/import bookings date=Дата заявки,item=Техника")
+		assert.Contains(t, text, b.t(ctx, "import.report", "bookings", 5, 1, 1, 3))
+		require.Len(t, mocks.booking.bookings, 1)
+		assert.Equal(t, "79990000001", mocks.booking.bookings[1].Phone)
+		assert.Equal(t, int64(1), mocks.booking.bookings[1].ItemID)
+
+		// Повторная отправка того же файла находит заявку в базе
+		text = send(123, "bookings.csv", "/import bookings date=Дата заявки,item=Техника")
+		assert.Contains(t, text, b.t(ctx, "import.duplicates", "2, 3"))
+		assert.Len(t, mocks.booking.bookings, 1)
+	})
+
+	t.Run("Items", func(t *testing.T) {
+		text := send(123, "items.csv", "/import items")
+		assert.Contains(t, text, b.t(ctx, "import.report", "items", 2, 1, 1, 0))
+		_, err := mocks.item.GetItemByName(ctx, "Tripod")
+		assert.NoError(t, err)
+	})
+
+	t.Run("ScopedManager", func(t *testing.T) {
+		assert.Equal(t, b.t(ctx, msgAccessDenied), send(200, "items.csv", "/import items"))
+
+		// Аппарат 1 не входит в роль менеджера и считается неизвестным
+		text := send(200, "bookings.csv", "/import bookings dry date=Дата заявки,item=Техника")
+		assert.Contains(t, text, b.t(ctx, "import.row_error", 2, b.t(ctx, "import.err_unknown_item", "Item 1")))
+	})
+
+	t.Run("BadInput", func(t *testing.T) {
+		assert.Equal(t, b.t(ctx, "import.usage"), send(123, "", "/import bookings"))
+		assert.Equal(t, b.t(ctx, "import.usage"), send(123, "items.csv", "/import orders"))
+		assert.Equal(t, b.t(ctx, "import.usage"), send(123, "items.csv", "/import items date"))
+		assert.Equal(t, b.t(ctx, "import.unsupported"), send(123, "notes.txt", "/import items"))
+		assert.Contains(t, send(123, "broken.csv", "/import bookings"), b.t(ctx, "import.invalid", ""))
+	})
+}
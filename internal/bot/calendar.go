@@ -0,0 +1,189 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"bronivik/internal/models"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+const (
+	calendarNavPrefix = "cal_nav:"
+	calendarDayPrefix = "cal_day:"
+	calendarIgnore    = "cal_ignore"
+
+	calendarMonthLayout = "2006-01"
+	calendarDayLayout   = "2006-01-02"
+
+	// maxRangeDays совпадает с ограничением интервала в handleManagerEndDate
+	maxRangeDays = 31
+)
+
+// calendarSteps - шаги мастеров, в которых дату можно выбрать в календаре
+var calendarSteps = map[string]bool{
+	models.StateWaitingDate:              true,
+	models.StateManagerWaitingSingleDate: true,
+	models.StateManagerWaitingStartDate:  true,
+	models.StateManagerWaitingEndDate:    true,
+}
+
+// calendarKeyboard строит календарь на месяц month для текущего шага мастера.
+// Прошедшие, слишком далекие и полностью занятые дни неактивны; при выборе конца
+// интервала доступны только дни после начала (занятые можно включить в интервал).
+func (b *Bot) calendarKeyboard(ctx context.Context, state *models.UserState, month time.Time) tgbotapi.InlineKeyboardMarkup {
+	first := time.Date(month.Year(), month.Month(), 1, 0, 0, 0, 0, time.UTC)
+	next := first.AddDate(0, 1, 0)
+	days := next.AddDate(0, 0, -1).Day()
+
+	rangeEnd := state.CurrentStep == models.StateManagerWaitingEndDate
+	start := state.GetTime("start_date")
+
+	booked := b.calendarBookedDays(ctx, state.GetInt64("item_id"), first, days)
+
+	rows := make([][]tgbotapi.InlineKeyboardButton, 0, 8)
+	rows = append(rows, b.calendarHeader(ctx, first, next))
+
+	weekdays := strings.Fields(b.t(ctx, "calendar.weekdays"))
+	header := make([]tgbotapi.InlineKeyboardButton, 0, len(weekdays))
+	for _, name := range weekdays {
+		header = append(header, tgbotapi.NewInlineKeyboardButtonData(name, calendarIgnore))
+	}
+	rows = append(rows, header)
+
+	// Неделя начинается с понедельника
+	week := make([]tgbotapi.InlineKeyboardButton, 0, 7)
+	for i := 0; i < (int(first.Weekday())+6)%7; i++ {
+		week = append(week, tgbotapi.NewInlineKeyboardButtonData(" ", calendarIgnore))
+	}
+
+	for d := 1; d <= days; d++ {
+		day := first.AddDate(0, 0, d-1)
+		label, data := strconv.Itoa(d), calendarDayPrefix+day.Format(calendarDayLayout)
+
+		switch {
+		case b.bookingService.ValidateBookingDate(day) != nil:
+			label, data = "·", calendarIgnore
+		case rangeEnd && (day.Before(start) || day.After(start.AddDate(0, 0, maxRangeDays))):
+			label, data = "·", calendarIgnore
+		case rangeEnd && day.Equal(start):
+			label = "[" + label + "]"
+		case booked[day.Format(calendarDayLayout)]:
+			label = "✖"
+			if !rangeEnd {
+				data = calendarIgnore
+			}
+		}
+
+		week = append(week, tgbotapi.NewInlineKeyboardButtonData(label, data))
+		if len(week) == 7 {
+			rows = append(rows, week)
+			week = make([]tgbotapi.InlineKeyboardButton, 0, 7)
+		}
+	}
+
+	if len(week) > 0 {
+		for len(week) < 7 {
+			week = append(week, tgbotapi.NewInlineKeyboardButtonData(" ", calendarIgnore))
+		}
+		rows = append(rows, week)
+	}
+
+	return tgbotapi.NewInlineKeyboardMarkup(rows...)
+}
+
+// calendarHeader возвращает строку с названием месяца и переключателями;
+// в прошлое и дальше разрешенного горизонта бронирования листать нельзя
+func (b *Bot) calendarHeader(ctx context.Context, first, next time.Time) []tgbotapi.InlineKeyboardButton {
+	months := strings.Fields(b.t(ctx, "calendar.months"))
+	title := fmt.Sprintf("%d.%d", int(first.Month()), first.Year())
+	if len(months) == 12 {
+		title = fmt.Sprintf("%s %d", months[first.Month()-1], first.Year())
+	}
+
+	now := time.Now()
+	prev, prevData := " ", calendarIgnore
+	if first.After(time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)) {
+		prev, prevData = "«", calendarNavPrefix+first.AddDate(0, -1, 0).Format(calendarMonthLayout)
+	}
+
+	forward, forwardData := " ", calendarIgnore
+	if b.bookingService.ValidateBookingDate(next) == nil {
+		forward, forwardData = "»", calendarNavPrefix+next.Format(calendarMonthLayout)
+	}
+
+	return tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData(prev, prevData),
+		tgbotapi.NewInlineKeyboardButtonData(title, calendarIgnore),
+		tgbotapi.NewInlineKeyboardButtonData(forward, forwardData),
+	)
+}
+
+// calendarBookedDays возвращает дни месяца, на которые аппарат полностью занят
+func (b *Bot) calendarBookedDays(ctx context.Context, itemID int64, first time.Time, days int) map[string]bool {
+	booked := make(map[string]bool)
+	if itemID == 0 {
+		return booked
+	}
+
+	availability, err := b.bookingService.GetAvailability(ctx, itemID, first, days)
+	if err != nil {
+		// Без данных о занятости календарь все равно полезен: проверка будет при выборе дня
+		b.logger.Error().Err(err).Int64("item_id", itemID).Time("month", first).Msg("Error loading calendar availability")
+		return booked
+	}
+
+	for _, a := range availability {
+		if a.Available <= 0 {
+			booked[a.Date.Format(calendarDayLayout)] = true
+		}
+	}
+	return booked
+}
+
+// calendarStartMonth возвращает месяц, с которого открывается календарь
+func calendarStartMonth(state *models.UserState) time.Time {
+	if state.CurrentStep == models.StateManagerWaitingEndDate {
+		if start := state.GetTime("start_date"); !start.IsZero() {
+			return start
+		}
+	}
+	return time.Now()
+}
+
+// handleCalendarCallback обрабатывает листание месяцев и выбор дня в календаре
+func (b *Bot) handleCalendarCallback(ctx context.Context, update *tgbotapi.Update) {
+	callback := update.CallbackQuery
+	chatID := callback.Message.Chat.ID
+
+	state := b.getUserState(ctx, callback.From.ID)
+	if state == nil || !calendarSteps[state.CurrentStep] {
+		b.sendMessage(chatID, b.t(ctx, "calendar.expired"))
+		return
+	}
+
+	if strings.HasPrefix(callback.Data, calendarNavPrefix) {
+		month, err := time.Parse(calendarMonthLayout, strings.TrimPrefix(callback.Data, calendarNavPrefix))
+		if err != nil {
+			return
+		}
+		edit := tgbotapi.NewEditMessageReplyMarkup(chatID, callback.Message.MessageID, b.calendarKeyboard(ctx, state, month))
+		if _, err := b.tgService.Send(edit); err != nil {
+			b.logger.Error().Err(err).Msg("Failed to switch calendar month")
+		}
+		return
+	}
+
+	day, err := time.Parse(calendarDayLayout, strings.TrimPrefix(callback.Data, calendarDayPrefix))
+	if err != nil {
+		return
+	}
+
+	// Выбор дня передается шагу диалога так же, как дата, набранная вручную
+	dateStr := day.Format("02.01.2006")
+	b.handleDialogCallback(ctx, update, state, dateStr, b.t(ctx, "calendar.selected", dateStr))
+}
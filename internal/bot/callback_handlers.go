@@ -2,7 +2,6 @@ package bot
 
 import (
 	"context"
-	"fmt"
 	"strconv"
 	"strings"
 
@@ -24,12 +23,12 @@ func (b *Bot) handleCallbackQuery(ctx context.Context, update *tgbotapi.Update)
 	callbackConfig := tgbotapi.NewCallback(callback.ID, "")
 	_, _ = b.tgService.Request(callbackConfig)
 
-	if b.isBlacklisted(userID) {
+	if b.denyIfBlacklisted(ctx, userID, userID) {
 		return
 	}
 
 	// Обработка команд менеджера
-	if b.isManager(userID) {
+	if b.isStaff(userID) {
 		if b.handleManagerCallback(ctx, update) {
 			return
 		}
@@ -47,7 +46,14 @@ func (b *Bot) handleCallbackQuery(ctx context.Context, update *tgbotapi.Update)
 
 	case strings.HasPrefix(data, "items_page:"):
 		page, _ := strconv.Atoi(strings.TrimPrefix(data, "items_page:"))
-		b.sendItemsPage(ctx, callback.Message.Chat.ID, callback.Message.MessageID, page)
+		category := ""
+		if state := b.getUserState(ctx, userID); state != nil {
+			category = state.GetString(catalogCategoryKey)
+		}
+		b.sendItemsPage(ctx, callback.Message.Chat.ID, callback.Message.MessageID, category, page)
+
+	case strings.HasPrefix(data, catalogCategoryPrefix):
+		b.handleCatalogCategory(ctx, update, data)
 
 	case strings.HasPrefix(data, "select_item:"):
 		itemID, _ := strconv.ParseInt(strings.TrimPrefix(data, "select_item:"), 10, 64)
@@ -61,6 +67,24 @@ func (b *Bot) handleCallbackQuery(ctx context.Context, update *tgbotapi.Update)
 		itemID, _ := strconv.ParseInt(strings.TrimPrefix(data, "schedule_select_item:"), 10, 64)
 		b.handleScheduleItemSelected(ctx, update, itemID)
 
+	case data == "forget_confirm":
+		b.handleForgetConfirm(ctx, callback.Message.Chat.ID, userID)
+
+	case data == "forget_cancel":
+		b.sendMessage(callback.Message.Chat.ID, b.t(ctx, "forget.canceled"))
+
+	case strings.HasPrefix(data, "set_language:"):
+		b.handleSetLanguage(ctx, update)
+
+	case strings.HasPrefix(data, "set_reminders:"):
+		b.handleSetReminders(ctx, update)
+
+	case data == calendarIgnore:
+		// Неактивная клетка календаря
+
+	case strings.HasPrefix(data, calendarNavPrefix), strings.HasPrefix(data, calendarDayPrefix):
+		b.handleCalendarCallback(ctx, update)
+
 	case data == "start_the_order":
 		b.handleSelectItem(ctx, update)
 
@@ -73,66 +97,46 @@ func (b *Bot) handleCallbackQuery(ctx context.Context, update *tgbotapi.Update)
 	}
 }
 
+// handleDateSelection начинает бронирование выбранного аппарата
 func (b *Bot) handleDateSelection(ctx context.Context, update *tgbotapi.Update, itemID int64) {
-	selectedItem, err := b.itemService.GetItemByID(ctx, itemID)
-	if err != nil {
+	if _, err := b.itemService.GetItemByID(ctx, itemID); err != nil {
 		b.logger.Error().Err(err).Int64("item_id", itemID).Msg("Error getting item by ID")
 		return
 	}
 
-	var chatID int64
-	var userID int64
-
-	switch {
-	case update.CallbackQuery != nil:
-		chatID = update.CallbackQuery.Message.Chat.ID
-		userID = update.CallbackQuery.From.ID
-	case update.Message != nil:
-		chatID = update.Message.Chat.ID
-		userID = update.Message.From.ID
-	default:
-		return
-	}
-
-	msg := tgbotapi.NewMessage(chatID,
-		fmt.Sprintf("Вы выбрали: %s\n\nВведите дату в формате ДД.ММ.ГГГГ (например, 25.12.2024):", selectedItem.Name))
-
-	b.setUserState(ctx, userID, models.StateWaitingDate, map[string]interface{}{
-		"item_id": itemID,
-	})
-
-	if _, err := b.tgService.Send(msg); err != nil {
-		b.logger.Error().Err(err).Msg("Failed to send message in handleDateSelection")
-	}
+	userBookingDialog.start(ctx, b, update, models.StateWaitingDate, bookingDialogState{ItemID: itemID})
 }
 
 func (b *Bot) handleScheduleItemSelected(ctx context.Context, update *tgbotapi.Update, itemID int64) {
 	selectedItem, err := b.itemService.GetItemByID(ctx, itemID)
-	if err != nil {
-		b.sendMessage(update.CallbackQuery.Message.Chat.ID, "Ошибка: аппарат не найден")
+	if err != nil || selectedItem == nil {
+		b.sendMessage(update.CallbackQuery.Message.Chat.ID, b.t(ctx, "error.item_not_found"))
 		return
 	}
 
-	// Сохраняем выбранный аппарат в состоянии
-	b.setUserState(ctx, update.CallbackQuery.From.ID, models.StateViewSchedule, map[string]interface{}{
-		"item_id": itemID,
+	b.openItemSchedule(ctx, update.CallbackQuery.Message.Chat.ID, update.CallbackQuery.From.ID, selectedItem)
+}
+
+// openItemSchedule сохраняет выбранный аппарат и предлагает расписание на месяц или на дату
+func (b *Bot) openItemSchedule(ctx context.Context, chatID, userID int64, item *models.Item) {
+	b.setUserState(ctx, userID, models.StateViewSchedule, map[string]interface{}{
+		"item_id": item.ID,
 	})
 
-	msg := tgbotapi.NewMessage(update.CallbackQuery.Message.Chat.ID,
-		fmt.Sprintf("Выбран аппарат: %s\n\nВыберите период или введите дату:", selectedItem.Name))
+	msg := tgbotapi.NewMessage(chatID, b.t(ctx, "schedule.item_selected", item.Name))
 
 	keyboard := tgbotapi.NewReplyKeyboard(
 		tgbotapi.NewKeyboardButtonRow(
-			tgbotapi.NewKeyboardButton(btnMonthSchedule),
-			tgbotapi.NewKeyboardButton(btnPickDate),
+			tgbotapi.NewKeyboardButton(b.t(ctx, btnMonthSchedule)),
+			tgbotapi.NewKeyboardButton(b.t(ctx, btnPickDate)),
 		),
 		tgbotapi.NewKeyboardButtonRow(
-			tgbotapi.NewKeyboardButton(btnBackToItems),
+			tgbotapi.NewKeyboardButton(b.t(ctx, btnBackToItems)),
 		),
 	)
 	msg.ReplyMarkup = keyboard
 
 	if _, err := b.tgService.Send(msg); err != nil {
-		b.logger.Error().Err(err).Msg("Failed to send message in handleScheduleItemSelected")
+		b.logger.Error().Err(err).Msg("Failed to send message in openItemSchedule")
 	}
 }
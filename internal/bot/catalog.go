@@ -0,0 +1,219 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"bronivik/internal/models"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+const (
+	catalogCategoryPrefix = "items_cat:"
+	catalogCategoryKey    = "category"
+	catalogRoot           = -1
+
+	// photoCaptionLimit - ограничение Telegram на длину подписи к фото
+	photoCaptionLimit = 1024
+)
+
+// handleCatalogCategory открывает категорию каталога по ее номеру в списке категорий
+func (b *Bot) handleCatalogCategory(ctx context.Context, update *tgbotapi.Update, data string) {
+	callback := update.CallbackQuery
+	idx, _ := strconv.Atoi(strings.TrimPrefix(data, catalogCategoryPrefix))
+
+	category := ""
+	if items, err := b.itemService.GetActiveItems(ctx); err == nil {
+		if categories := itemCategories(items); idx >= 0 && idx < len(categories) {
+			category = categories[idx]
+		}
+	}
+
+	b.setUserState(ctx, callback.From.ID, models.StateSelectItem, map[string]interface{}{
+		"page":             0,
+		catalogCategoryKey: category,
+	})
+	b.sendItemsPage(ctx, callback.Message.Chat.ID, callback.Message.MessageID, category, 0)
+}
+
+// sendItemsPage отправляет страницу каталога: подкатегории и аппараты выбранной категории
+func (b *Bot) sendItemsPage(ctx context.Context, chatID int64, messageID int, category string, page int) {
+	items, err := b.itemService.GetActiveItems(ctx)
+	if err != nil {
+		b.logger.Error().Err(err).Msg("Error getting active items for catalog")
+		b.sendMessage(chatID, b.t(ctx, "error.items_list"))
+		return
+	}
+
+	categories := itemCategories(items)
+	if category != "" && categoryIndex(categories, category) == catalogRoot {
+		// Категория исчезла из каталога, пока пользователь листал его
+		category = ""
+	}
+	subcategories, levelItems := catalogLevel(items, category)
+
+	rows := make([][]tgbotapi.InlineKeyboardButton, 0, len(subcategories)+1)
+	for _, sub := range subcategories {
+		label := b.t(ctx, "items.category_button", lastCategoryLevel(sub), countInCategory(items, sub))
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(tgbotapi.NewInlineKeyboardButtonData(
+			label, fmt.Sprintf("%s%d", catalogCategoryPrefix, categoryIndex(categories, sub)))))
+	}
+
+	title := b.t(ctx, "items.title")
+	if category != "" {
+		title = b.t(ctx, "items.category_title", strings.ReplaceAll(category, models.CategorySeparator, " › "))
+		parent := catalogRoot
+		if i := strings.LastIndex(category, models.CategorySeparator); i > 0 {
+			parent = categoryIndex(categories, category[:i])
+		}
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(tgbotapi.NewInlineKeyboardButtonData(
+			b.t(ctx, "btn.category_up"), fmt.Sprintf("%s%d", catalogCategoryPrefix, parent))))
+	}
+
+	b.renderPaginatedItems(&PaginationParams{
+		Ctx:          ctx,
+		ChatID:       chatID,
+		MessageID:    messageID,
+		Page:         page,
+		Title:        title,
+		ItemPrefix:   "select_item:",
+		PagePrefix:   "items_page:",
+		BackCallback: "back_to_main",
+		ShowCapacity: false,
+		ExtraRows:    rows,
+		Items:        levelItems,
+	})
+}
+
+// sendItemCard показывает карточку аппарата с фото, характеристиками и ценой.
+// Аппараты без фото, характеристик и цен карточки не имеют.
+func (b *Bot) sendItemCard(ctx context.Context, chatID int64, item *models.Item) {
+	if len(item.PhotoFileIDs) == 0 && len(item.Specs) == 0 && !item.HasPricing() {
+		return
+	}
+
+	card := b.itemCardText(ctx, item)
+	if len(item.PhotoFileIDs) == 0 {
+		b.sendMessage(chatID, card)
+		return
+	}
+
+	for i, fileID := range item.PhotoFileIDs {
+		photo := tgbotapi.NewPhoto(chatID, tgbotapi.FileID(fileID))
+		if i == 0 {
+			photo.Caption = truncateRunes(card, photoCaptionLimit)
+		}
+		if _, err := b.tgService.Send(photo); err != nil {
+			b.logger.Error().Err(err).Int64("item_id", item.ID).Msg("Failed to send item photo")
+		}
+	}
+}
+
+// itemCardText формирует описание аппарата: название, категория, описание, цена и характеристики
+func (b *Bot) itemCardText(ctx context.Context, item *models.Item) string {
+	lines := []string{"🏢 " + item.Name}
+	if item.Category != "" {
+		lines = append(lines, b.t(ctx, "items.card_category", strings.ReplaceAll(item.Category, models.CategorySeparator, " › ")))
+	}
+	if item.Description != "" {
+		lines = append(lines, "📝 "+item.Description)
+	}
+	if item.PricePerDay > 0 {
+		lines = append(lines, b.t(ctx, "items.price", item.PricePerDay))
+	}
+	if item.WeekendPrice > 0 {
+		lines = append(lines, b.t(ctx, "items.price_weekend", item.WeekendPrice))
+	}
+	if item.LongRentalDiscount > 0 && item.LongRentalDays > 0 {
+		lines = append(lines, b.t(ctx, "items.long_rental", item.LongRentalDays, item.LongRentalDiscount))
+	}
+	if item.Deposit > 0 {
+		lines = append(lines, b.t(ctx, "items.deposit", item.Deposit))
+	}
+	if len(item.Specs) > 0 {
+		lines = append(lines, "", b.t(ctx, "items.card_specs"))
+		for _, spec := range item.Specs {
+			lines = append(lines, fmt.Sprintf("• %s: %s", spec.Name, spec.Value))
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// itemCategories возвращает все категории каталога вместе с родительскими уровнями в алфавитном порядке
+func itemCategories(items []*models.Item) []string {
+	seen := make(map[string]bool)
+	for _, item := range items {
+		path := item.CategoryPath()
+		for i := range path {
+			seen[strings.Join(path[:i+1], models.CategorySeparator)] = true
+		}
+	}
+	categories := make([]string, 0, len(seen))
+	for category := range seen {
+		categories = append(categories, category)
+	}
+	sort.Strings(categories)
+	return categories
+}
+
+// catalogLevel разбирает уровень каталога: подкатегории следующего уровня и аппараты самой категории.
+// Список аппаратов не бывает nil, чтобы renderPaginatedItems не подставил весь каталог.
+func catalogLevel(items []*models.Item, category string) (subcategories []string, levelItems []*models.Item) {
+	prefix := ""
+	if category != "" {
+		prefix = category + models.CategorySeparator
+	}
+
+	seen := make(map[string]bool)
+	levelItems = make([]*models.Item, 0, len(items))
+	for _, item := range items {
+		switch {
+		case item.Category == category:
+			levelItems = append(levelItems, item)
+		case strings.HasPrefix(item.Category, prefix):
+			rest := strings.TrimPrefix(item.Category, prefix)
+			sub := prefix + strings.SplitN(rest, models.CategorySeparator, 2)[0]
+			if !seen[sub] {
+				seen[sub] = true
+				subcategories = append(subcategories, sub)
+			}
+		}
+	}
+	sort.Strings(subcategories)
+	return subcategories, levelItems
+}
+
+// countInCategory считает аппараты категории вместе с вложенными
+func countInCategory(items []*models.Item, category string) int {
+	count := 0
+	for _, item := range items {
+		if item.Category == category || strings.HasPrefix(item.Category, category+models.CategorySeparator) {
+			count++
+		}
+	}
+	return count
+}
+
+func categoryIndex(categories []string, category string) int {
+	i := sort.SearchStrings(categories, category)
+	if i < len(categories) && categories[i] == category {
+		return i
+	}
+	return catalogRoot
+}
+
+func lastCategoryLevel(category string) string {
+	return category[strings.LastIndex(category, models.CategorySeparator)+1:]
+}
+
+func truncateRunes(s string, limit int) string {
+	runes := []rune(s)
+	if len(runes) <= limit {
+		return s
+	}
+	return string(runes[:limit-1]) + "…"
+}
@@ -0,0 +1,120 @@
+package bot
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"time"
+
+	"bronivik/internal/models"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// handleStartPayload обрабатывает параметр ссылки t.me/<бот>?start=<payload> (см. models.DeepLinkItem и др.).
+// Возвращает false, если параметр не распознан и нужно показать главное меню.
+func (b *Bot) handleStartPayload(ctx context.Context, update *tgbotapi.Update, payload string) bool {
+	if itemID, date, ok := parseBookingDeepLink(payload); ok {
+		return b.startBookingFromLink(ctx, update, itemID, date)
+	}
+	if id, ok := parseDeepLinkID(payload, models.DeepLinkItem); ok {
+		return b.openItemFromLink(ctx, update, id)
+	}
+	if id, ok := parseDeepLinkID(payload, models.DeepLinkBooking); ok {
+		return b.showBookingFromLink(ctx, update, id)
+	}
+
+	b.logger.Debug().Str("payload", payload).Msg("Unknown start payload")
+	return false
+}
+
+// parseDeepLinkID разбирает payload вида <prefix><id>
+func parseDeepLinkID(payload, prefix string) (int64, bool) {
+	rest, found := strings.CutPrefix(payload, prefix)
+	if !found {
+		return 0, false
+	}
+	id, err := strconv.ParseInt(rest, 10, 64)
+	if err != nil || id <= 0 {
+		return 0, false
+	}
+	return id, true
+}
+
+// parseBookingDeepLink разбирает payload book_<id>[_<ГГГГММДД>]
+func parseBookingDeepLink(payload string) (itemID int64, date time.Time, ok bool) {
+	rest, found := strings.CutPrefix(payload, models.DeepLinkBook)
+	if !found {
+		return 0, time.Time{}, false
+	}
+	idPart, datePart, hasDate := strings.Cut(rest, "_")
+	itemID, err := strconv.ParseInt(idPart, 10, 64)
+	if err != nil || itemID <= 0 {
+		return 0, time.Time{}, false
+	}
+	if hasDate {
+		if date, err = time.Parse(deepLinkDateLayout, datePart); err != nil {
+			return 0, time.Time{}, false
+		}
+	}
+	return itemID, date, true
+}
+
+// activeItemFromLink возвращает аппарат из ссылки; об отключенном или удаленном аппарате сообщает пользователю
+func (b *Bot) activeItemFromLink(ctx context.Context, update *tgbotapi.Update, itemID int64) (*models.Item, bool) {
+	item, ok := b.getItemByID(itemID)
+	if !ok || !item.IsActive {
+		b.sendMessage(update.Message.Chat.ID, b.t(ctx, "error.item_not_found"))
+		return nil, false
+	}
+	return &item, true
+}
+
+// openItemFromLink открывает карточку и расписание аппарата по QR-наклейке
+func (b *Bot) openItemFromLink(ctx context.Context, update *tgbotapi.Update, itemID int64) bool {
+	item, ok := b.activeItemFromLink(ctx, update, itemID)
+	if !ok {
+		return false
+	}
+
+	b.sendItemCard(ctx, update.Message.Chat.ID, item)
+	b.openItemSchedule(ctx, update.Message.Chat.ID, update.Message.From.ID, item)
+	return true
+}
+
+// showBookingFromLink показывает заявку: сотрудникам - карточку с действиями, клиенту - только его заявку
+func (b *Bot) showBookingFromLink(ctx context.Context, update *tgbotapi.Update, bookingID int64) bool {
+	userID := update.Message.From.ID
+	if b.hasPermission(userID, models.PermViewBookings) {
+		b.showManagerBookingDetail(ctx, update, bookingID)
+		return true
+	}
+
+	booking, err := b.bookingService.GetBooking(ctx, bookingID)
+	if err != nil || booking == nil || booking.UserID != userID {
+		b.sendMessage(update.Message.Chat.ID, b.t(ctx, "error.booking_not_found"))
+		return false
+	}
+
+	b.sendMessage(update.Message.Chat.ID, b.userBookingText(ctx, booking))
+	return true
+}
+
+// startBookingFromLink начинает бронирование по ссылке из инлайн-результата: аппарат выбран,
+// а дата, если она есть в ссылке, сразу проверяется как введенная пользователем
+func (b *Bot) startBookingFromLink(ctx context.Context, update *tgbotapi.Update, itemID int64, date time.Time) bool {
+	item, ok := b.activeItemFromLink(ctx, update, itemID)
+	if !ok {
+		return false
+	}
+
+	state := bookingDialogState{ItemID: item.ID}
+	if date.IsZero() {
+		userBookingDialog.start(ctx, b, update, models.StateWaitingDate, state)
+		return true
+	}
+
+	b.sendMessage(update.Message.Chat.ID, b.t(ctx, "inline.booking_started", item.Name, date.Format("02.01.2006")))
+	userBookingDialog.startWithInput(ctx, b, update, models.StateWaitingDate, state, date.Format("02.01.2006"))
+	return true
+}
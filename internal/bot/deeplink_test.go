@@ -0,0 +1,147 @@
+package bot
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"bronivik/internal/models"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseBookingDeepLink(t *testing.T) {
+	id, date, ok := parseBookingDeepLink("book_12_20261225")
+	require.True(t, ok)
+	assert.Equal(t, int64(12), id)
+	assert.Equal(t, time.Date(2026, 12, 25, 0, 0, 0, 0, time.UTC), date)
+
+	id, date, ok = parseBookingDeepLink("book_7")
+	require.True(t, ok)
+	assert.Equal(t, int64(7), id)
+	assert.True(t, date.IsZero())
+
+	for _, payload := range []string{"inline", "book_", "book_x", "book_-1", "book_3_2026"} {
+		_, _, ok = parseBookingDeepLink(payload)
+		assert.False(t, ok, payload)
+	}
+}
+
+func TestStartDeepLink_StartsBooking(t *testing.T) {
+	b, mocks := setupTestBot()
+	ctx := context.Background()
+	date := time.Now().AddDate(0, 0, 3).Truncate(24 * time.Hour)
+
+	b.handleMessage(ctx, &tgbotapi.Update{Message: &tgbotapi.Message{
+		From: &tgbotapi.User{ID: 555},
+		Chat: &tgbotapi.Chat{ID: 555},
+		Text: "/start book_1_" + date.Format(deepLinkDateLayout),
+	}})
+
+	state := b.getUserState(ctx, 555)
+	require.NotNil(t, state)
+	assert.Equal(t, models.StateEnterName, state.CurrentStep)
+	assert.Equal(t, int64(1), state.GetInt64("item_id"))
+	assert.True(t, date.Equal(state.GetTime("date")))
+
+	t.Run("UnknownItem", func(t *testing.T) {
+		b.handleMessage(ctx, &tgbotapi.Update{Message: &tgbotapi.Message{
+			From: &tgbotapi.User{ID: 556},
+			Chat: &tgbotapi.Chat{ID: 556},
+			Text: "/start book_99",
+		}})
+		assert.Contains(t, textsOf(mocks.tg.getSentMessages()), b.t(ctx, "error.item_not_found"))
+		state := b.getUserState(ctx, 556)
+		assert.True(t, state == nil || state.CurrentStep == models.StateMainMenu)
+	})
+}
+
+func TestStartDeepLink_Item(t *testing.T) {
+	b, mocks := setupTestBot()
+	ctx := context.Background()
+
+	b.handleMessage(ctx, &tgbotapi.Update{Message: &tgbotapi.Message{
+		From: &tgbotapi.User{ID: 600},
+		Chat: &tgbotapi.Chat{ID: 600},
+		Text: "/start item_1",
+	}})
+
+	state := b.getUserState(ctx, 600)
+	require.NotNil(t, state)
+	assert.Equal(t, models.StateViewSchedule, state.CurrentStep)
+	assert.Equal(t, int64(1), state.GetInt64("item_id"))
+	assert.Contains(t, textsOf(mocks.tg.getSentMessages()), b.t(ctx, "schedule.item_selected", "Item 1"))
+
+	t.Run("InactiveItem", func(t *testing.T) {
+		mocks.item.items = append(mocks.item.items, &models.Item{ID: 2, Name: "Old", IsActive: false})
+		mocks.tg.clearSentMessages()
+
+		b.handleMessage(ctx, &tgbotapi.Update{Message: &tgbotapi.Message{
+			From: &tgbotapi.User{ID: 601},
+			Chat: &tgbotapi.Chat{ID: 601},
+			Text: "/start item_2",
+		}})
+
+		texts := textsOf(mocks.tg.getSentMessages())
+		assert.Contains(t, texts, b.t(ctx, "error.item_not_found"))
+		assert.Contains(t, texts, b.t(ctx, "menu.welcome"), "после ошибки показывается главное меню")
+	})
+}
+
+func TestStartDeepLink_Booking(t *testing.T) {
+	b, mocks := setupTestBot()
+	ctx := context.Background()
+	date := time.Date(2026, 12, 25, 0, 0, 0, 0, time.UTC)
+	mocks.booking.setBookings(map[int64]*models.Booking{
+		5: {ID: 5, UserID: 700, UserName: "Иван", ItemID: 1, ItemName: "Item 1", Date: date, Status: models.StatusConfirmed},
+	})
+	start := func(userID int64) []string {
+		mocks.tg.clearSentMessages()
+		b.handleMessage(ctx, &tgbotapi.Update{Message: &tgbotapi.Message{
+			From: &tgbotapi.User{ID: userID},
+			Chat: &tgbotapi.Chat{ID: userID},
+			Text: "/start booking_5",
+		}})
+		return textsOf(mocks.tg.getSentMessages())
+	}
+
+	texts := start(700)
+	require.NotEmpty(t, texts)
+	assert.Contains(t, texts[0], "25.12.2026", "владелец видит свою заявку")
+
+	texts = start(701)
+	assert.Contains(t, texts, b.t(ctx, "error.booking_not_found"), "чужая заявка не показывается")
+
+	mocks.user.roles = map[int64]*models.UserRole{900: {TelegramID: 900, Role: models.RoleManager}}
+	texts = start(900)
+	require.NotEmpty(t, texts)
+	assert.Contains(t, texts[0], "Иван", "менеджер видит карточку заявки")
+}
+
+func TestStickersCommand(t *testing.T) {
+	b, mocks := setupTestBot()
+	ctx := context.Background()
+	send := func(text string) {
+		mocks.tg.clearSentMessages()
+		b.handleMessage(ctx, &tgbotapi.Update{Message: &tgbotapi.Message{
+			From: &tgbotapi.User{ID: 123},
+			Chat: &tgbotapi.Chat{ID: 123},
+			Text: text,
+		}})
+	}
+
+	send("/qr_stickers png")
+	sent := mocks.tg.getSentMessages()
+	require.Len(t, sent, 1)
+	doc, ok := sent[0].(tgbotapi.DocumentConfig)
+	require.True(t, ok)
+	file, ok := doc.File.(tgbotapi.FileBytes)
+	require.True(t, ok)
+	assert.Equal(t, "item_stickers.png", file.Name)
+	assert.NotEmpty(t, file.Bytes)
+
+	send("/qr_stickers gif")
+	assert.Equal(t, []string{b.t(ctx, "stickers.usage")}, textsOf(mocks.tg.getSentMessages()))
+}
@@ -0,0 +1,328 @@
+package bot
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
+	"time"
+
+	"bronivik/internal/models"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// Диалоги бота описываются декларативно: шаги с приглашением и проверкой ввода,
+// переходы по кнопкам "Назад" и "Отмена", время жизни. Состояние диалога - типизированная
+// структура S; в TempData оно хранится плоскими ключами из json-тегов, поэтому его видят
+// календарь и /mydata, а сессии в Redis переживают перезапуск бота.
+
+// dialogDone - результат ввода, после которого диалог завершен и шаг не меняется
+const dialogDone = ""
+
+// dialogStepAtKey - ключ TempData со временем входа в текущий шаг
+const dialogStepAtKey = "step_at"
+
+// errUseButtons - текст введен на шаге, где нужно нажать кнопку
+var errUseButtons = dialogError("dialog.use_buttons")
+
+// dialogInputError - ошибка ввода, которую диалог показывает пользователю, оставаясь на шаге
+type dialogInputError struct {
+	key  string
+	args []interface{}
+}
+
+func (e *dialogInputError) Error() string {
+	return e.key
+}
+
+// dialogError создает ошибку ввода с текстом из каталога сообщений
+func dialogError(key string, args ...interface{}) error {
+	return &dialogInputError{key: key, args: args}
+}
+
+// dialogStep - шаг диалога
+type dialogStep[S any] struct {
+	// prompt отправляет приглашение; вызывается при каждом входе в шаг
+	prompt func(c *dialogContext[S])
+	// input проверяет ввод и обновляет состояние. Возвращает следующий шаг или dialogDone;
+	// при ошибке шаг не меняется, а пользователь получает ее текст
+	input func(c *dialogContext[S], text string) (string, error)
+	// back возвращает шаг для кнопки "Назад"; без него кнопка выходит из диалога
+	back func(s *S) string
+}
+
+// dialog - диалог с типизированным состоянием S
+type dialog[S any] struct {
+	name    string
+	steps   map[string]*dialogStep[S]
+	timeout time.Duration
+	// canceled - ключ сообщения об отмене диалога
+	canceled string
+	// allowed проверяет, может ли пользователь вести диалог; nil - может любой
+	allowed func(b *Bot, userID int64) bool
+	// leave вызывается кнопкой "Назад" на шаге без back; nil - диалог отменяется
+	leave func(c *dialogContext[S])
+}
+
+// dialogContext - обрабатываемое обновление и состояние диалога
+type dialogContext[S any] struct {
+	ctx    context.Context
+	bot    *Bot
+	update *tgbotapi.Update
+	chatID int64
+	userID int64
+	step   string
+	state  *S
+}
+
+// dialogRunner позволяет найти диалог по шагу независимо от типа его состояния
+type dialogRunner interface {
+	hasStep(step string) bool
+	handle(ctx context.Context, b *Bot, update *tgbotapi.Update, state *models.UserState, text string) bool
+}
+
+// dialogs - все диалоги бота; шаги разных диалогов не должны совпадать
+var dialogs = []dialogRunner{userBookingDialog, managerBookingDialog}
+
+// dialogFor возвращает диалог, которому принадлежит шаг
+func dialogFor(step string) dialogRunner {
+	for _, d := range dialogs {
+		if d.hasStep(step) {
+			return d
+		}
+	}
+	return nil
+}
+
+// handleDialogMessage передает ввод диалогу текущего шага. Возвращает false, если
+// пользователь не в диалоге или диалог ему недоступен.
+func (b *Bot) handleDialogMessage(ctx context.Context, update *tgbotapi.Update, state *models.UserState, text string) bool {
+	if state == nil {
+		return false
+	}
+	d := dialogFor(state.CurrentStep)
+	if d == nil {
+		return false
+	}
+	return d.handle(ctx, b, update, state, text)
+}
+
+// handleDialogCallback передает диалогу значение инлайн-кнопки так же, как набранный текст.
+// Если шаг пройден и closed не пуст, клавиатура сообщения заменяется текстом closed, чтобы ее нельзя было нажать повторно.
+func (b *Bot) handleDialogCallback(ctx context.Context, update *tgbotapi.Update, state *models.UserState, value, closed string) {
+	callback := update.CallbackQuery
+	chatID := callback.Message.Chat.ID
+
+	input := &tgbotapi.Update{Message: &tgbotapi.Message{
+		From: callback.From,
+		Chat: callback.Message.Chat,
+		Text: value,
+	}}
+
+	step := state.CurrentStep
+	if !b.handleDialogMessage(ctx, input, state, value) {
+		b.sendMessage(chatID, b.t(ctx, "dialog.stale"))
+		return
+	}
+
+	if closed == "" {
+		return
+	}
+	if current := b.getUserState(ctx, callback.From.ID); current == nil || current.CurrentStep != step {
+		if _, err := b.tgService.EditMessage(chatID, callback.Message.MessageID, closed, nil); err != nil {
+			b.logger.Error().Err(err).Msg("Failed to close dialog keyboard")
+		}
+	}
+}
+
+func (d *dialog[S]) hasStep(step string) bool {
+	_, ok := d.steps[step]
+	return ok
+}
+
+// start начинает диалог с шага step
+func (d *dialog[S]) start(ctx context.Context, b *Bot, update *tgbotapi.Update, step string, state S) {
+	c := d.newContext(ctx, b, update)
+	c.state = &state
+	d.enter(c, step)
+}
+
+// startWithInput начинает диалог с шага step, сразу передавая ему ввод text.
+// Если ввод не подошел, пользователь получает ошибку и приглашение шага.
+func (d *dialog[S]) startWithInput(ctx context.Context, b *Bot, update *tgbotapi.Update, step string, state S, text string) {
+	c := d.newContext(ctx, b, update)
+	c.state = &state
+	c.step = step
+
+	next, err := d.steps[step].input(c, text)
+	if err != nil {
+		b.sendMessage(c.chatID, d.errorText(ctx, b, err))
+		d.enter(c, step)
+		return
+	}
+	if next != dialogDone {
+		d.enter(c, next)
+	}
+}
+
+// handle обрабатывает ввод на текущем шаге: отмену, возврат, истекший таймаут или значение шага
+func (d *dialog[S]) handle(ctx context.Context, b *Bot, update *tgbotapi.Update, state *models.UserState, text string) bool {
+	c := d.newContext(ctx, b, update)
+	if d.allowed != nil && !d.allowed(b, c.userID) {
+		return false
+	}
+
+	if d.expired(state) {
+		b.logger.Info().Int64("user_id", c.userID).Str("dialog", d.name).Str("step", state.CurrentStep).Msg("Dialog expired")
+		b.clearUserState(ctx, c.userID)
+		b.sendMessage(c.chatID, b.t(ctx, "dialog.expired"))
+		b.handleMainMenu(ctx, update)
+		return true
+	}
+
+	s, err := decodeDialogState[S](state)
+	if err != nil {
+		b.logger.Error().Err(err).Int64("user_id", c.userID).Str("dialog", d.name).Msg("Error decoding dialog state")
+		b.clearUserState(ctx, c.userID)
+		b.sendMessage(c.chatID, b.t(ctx, "error.session_expired"))
+		b.handleMainMenu(ctx, update)
+		return true
+	}
+	c.state = s
+	c.step = state.CurrentStep
+	step := d.steps[state.CurrentStep]
+
+	switch {
+	case b.isButton(text, btnCancel):
+		b.clearUserState(ctx, c.userID)
+		b.sendMessage(c.chatID, b.t(ctx, d.canceled))
+		b.handleMainMenu(ctx, update)
+
+	case b.isButton(text, btnBack):
+		switch {
+		case step.back != nil:
+			d.enter(c, step.back(s))
+		case d.leave != nil:
+			d.leave(c)
+		default:
+			b.clearUserState(ctx, c.userID)
+			b.sendMessage(c.chatID, b.t(ctx, d.canceled))
+			b.handleMainMenu(ctx, update)
+		}
+
+	default:
+		next, errInput := step.input(c, text)
+		if errInput != nil {
+			b.sendMessage(c.chatID, d.errorText(ctx, b, errInput))
+			return true
+		}
+		if next != dialogDone {
+			d.enter(c, next)
+		}
+	}
+	return true
+}
+
+// enter сохраняет состояние на шаге step и отправляет его приглашение
+func (d *dialog[S]) enter(c *dialogContext[S], step string) {
+	c.step = step
+	c.bot.setUserState(c.ctx, c.userID, step, encodeDialogState(c.state, time.Now()))
+	c.bot.logger.Debug().Int64("user_id", c.userID).Str("dialog", d.name).Str("step", step).Msg("Dialog step")
+	d.steps[step].prompt(c)
+}
+
+// expired проверяет таймаут шага. Состояния без времени входа (созданные до появления диалогов) не истекают.
+func (d *dialog[S]) expired(state *models.UserState) bool {
+	if d.timeout <= 0 {
+		return false
+	}
+	at := state.GetTime(dialogStepAtKey)
+	return !at.IsZero() && time.Since(at) > d.timeout
+}
+
+func (d *dialog[S]) errorText(ctx context.Context, b *Bot, err error) string {
+	var inputErr *dialogInputError
+	if errors.As(err, &inputErr) {
+		return b.t(ctx, inputErr.key, inputErr.args...)
+	}
+	return b.getErrorMessage(ctx, err)
+}
+
+func (d *dialog[S]) newContext(ctx context.Context, b *Bot, update *tgbotapi.Update) *dialogContext[S] {
+	c := &dialogContext[S]{ctx: ctx, bot: b, update: update}
+	if chat := update.FromChat(); chat != nil {
+		c.chatID = chat.ID
+	}
+	if user := update.SentFrom(); user != nil {
+		c.userID = user.ID
+	}
+	return c
+}
+
+// send отправляет сообщение в чат диалога; markup может быть nil
+func (c *dialogContext[S]) send(text string, markup interface{}) {
+	msg := tgbotapi.NewMessage(c.chatID, text)
+	if markup != nil {
+		msg.ReplyMarkup = markup
+	}
+	if _, err := c.bot.tgService.Send(msg); err != nil {
+		c.bot.logger.Error().Err(err).Int64("chat_id", c.chatID).Str("step", c.step).Msg("Failed to send dialog message")
+	}
+}
+
+// calendar возвращает календарь для выбора даты на текущем шаге
+func (c *dialogContext[S]) calendar() tgbotapi.InlineKeyboardMarkup {
+	state := &models.UserState{UserID: c.userID, CurrentStep: c.step, TempData: encodeDialogState(c.state, time.Time{})}
+	return c.bot.calendarKeyboard(c.ctx, state, calendarStartMonth(state))
+}
+
+// navigationKeyboard - клавиатура с кнопками "Назад" и "Отмена" для шагов с текстовым вводом
+func (c *dialogContext[S]) navigationKeyboard() tgbotapi.ReplyKeyboardMarkup {
+	return tgbotapi.NewReplyKeyboard(
+		tgbotapi.NewKeyboardButtonRow(
+			tgbotapi.NewKeyboardButton(c.bot.t(c.ctx, btnBack)),
+			tgbotapi.NewKeyboardButton(c.bot.t(c.ctx, btnCancel)),
+		),
+	)
+}
+
+// backTo возвращает переход "Назад" на шаг step
+func backTo[S any](step string) func(*S) string {
+	return func(*S) string { return step }
+}
+
+// encodeDialogState раскладывает состояние по ключам json-тегов. Поля сохраняются
+// со своими типами, нулевые поля пропускаются.
+func encodeDialogState(state interface{}, stepAt time.Time) map[string]interface{} {
+	data := make(map[string]interface{})
+	if !stepAt.IsZero() {
+		data[dialogStepAtKey] = stepAt
+	}
+
+	v := reflect.ValueOf(state).Elem()
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		name, _, _ := strings.Cut(t.Field(i).Tag.Get("json"), ",")
+		if name == "" || name == "-" || v.Field(i).IsZero() {
+			continue
+		}
+		data[name] = v.Field(i).Interface()
+	}
+	return data
+}
+
+// decodeDialogState собирает состояние из TempData. Значения могут прийти как есть
+// или после JSON из Redis (числа float64, время строкой), поэтому разбор идет через JSON.
+func decodeDialogState[S any](state *models.UserState) (*S, error) {
+	s := new(S)
+	if len(state.TempData) == 0 {
+		return s, nil
+	}
+	raw, err := json.Marshal(state.TempData)
+	if err != nil {
+		return nil, err
+	}
+	return s, json.Unmarshal(raw, s)
+}
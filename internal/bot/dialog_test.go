@@ -0,0 +1,185 @@
+package bot
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"bronivik/internal/database"
+	"bronivik/internal/models"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDialogState_EncodeDecode(t *testing.T) {
+	date := time.Date(2026, 5, 4, 0, 0, 0, 0, time.UTC)
+	stepAt := time.Now()
+
+	data := encodeDialogState(&managerBookingState{ClientName: "Иван", ItemID: 3, Dates: []time.Time{date}}, stepAt)
+	assert.Equal(t, "Иван", data["client_name"])
+	assert.Equal(t, int64(3), data["item_id"], "поля сохраняются со своими типами")
+	assert.Equal(t, stepAt, data[dialogStepAtKey])
+	assert.NotContains(t, data, "comment", "нулевые поля не сохраняются")
+	assert.NotContains(t, data, "start_date")
+
+	t.Run("NativeValues", func(t *testing.T) {
+		s, err := decodeDialogState[managerBookingState](&models.UserState{TempData: data})
+		require.NoError(t, err)
+		assert.Equal(t, "Иван", s.ClientName)
+		assert.Equal(t, int64(3), s.ItemID)
+		require.Len(t, s.Dates, 1)
+		assert.True(t, date.Equal(s.Dates[0]))
+	})
+
+	t.Run("AfterRedis", func(t *testing.T) {
+		s, err := decodeDialogState[managerBookingState](&models.UserState{TempData: map[string]interface{}{
+			"item_id":    float64(3),
+			"start_date": date.Format(time.RFC3339),
+			"dates":      []interface{}{date.Format(time.RFC3339)},
+			"page":       float64(2),
+		}})
+		require.NoError(t, err)
+		assert.Equal(t, int64(3), s.ItemID)
+		assert.True(t, date.Equal(s.StartDate))
+		require.Len(t, s.Dates, 1)
+	})
+
+	t.Run("WrongType", func(t *testing.T) {
+		_, err := decodeDialogState[managerBookingState](&models.UserState{TempData: map[string]interface{}{"item_id": "x"}})
+		assert.Error(t, err)
+	})
+}
+
+func TestDialogs_StepsDoNotOverlap(t *testing.T) {
+	for step := range managerBookingDialog.steps {
+		assert.False(t, userBookingDialog.hasStep(step), "step %s belongs to two dialogs", step)
+	}
+	assert.Equal(t, userBookingDialog, dialogFor(models.StateEnterName))
+	assert.Equal(t, managerBookingDialog, dialogFor(models.StateManagerWaitingComment))
+	assert.Nil(t, dialogFor(models.StateViewSchedule))
+}
+
+func TestDialog_Transitions(t *testing.T) {
+	b, mocks := setupTestBot()
+	ctx := context.Background()
+
+	say := func(userID int64, text string) {
+		b.handleMessage(ctx, &tgbotapi.Update{Message: &tgbotapi.Message{
+			From: &tgbotapi.User{ID: userID},
+			Chat: &tgbotapi.Chat{ID: userID},
+			Text: text,
+		}})
+	}
+	lastText := func() string {
+		sent := mocks.tg.getSentMessages()
+		require.NotEmpty(t, sent)
+		return sent[len(sent)-1].(tgbotapi.MessageConfig).Text
+	}
+
+	t.Run("Timeout", func(t *testing.T) {
+		mocks.tg.clearSentMessages()
+		b.setUserState(ctx, 501, models.StatePhoneNumber, map[string]interface{}{
+			"item_id":       int64(1),
+			dialogStepAtKey: time.Now().Add(-2 * time.Hour),
+		})
+
+		say(501, "+79991234567")
+
+		state := b.getUserState(ctx, 501)
+		require.NotNil(t, state)
+		assert.Equal(t, models.StateMainMenu, state.CurrentStep)
+		assert.Empty(t, mocks.booking.getBookings())
+		assert.Equal(t, b.t(ctx, "dialog.expired"), mocks.tg.getSentMessages()[0].(tgbotapi.MessageConfig).Text)
+	})
+
+	t.Run("InvalidInputKeepsStep", func(t *testing.T) {
+		mocks.tg.clearSentMessages()
+		b.setUserState(ctx, 502, models.StateWaitingDate, map[string]interface{}{"item_id": int64(1)})
+
+		say(502, "завтра")
+
+		assert.Equal(t, models.StateWaitingDate, b.getUserState(ctx, 502).CurrentStep)
+		assert.Equal(t, b.t(ctx, "error.invalid_date"), lastText())
+	})
+
+	t.Run("BackFromFirstStepLeaves", func(t *testing.T) {
+		b.setUserState(ctx, 503, models.StateWaitingDate, map[string]interface{}{"item_id": int64(1)})
+
+		say(503, "⬅️ Назад")
+
+		assert.Equal(t, models.StateSelectItem, b.getUserState(ctx, 503).CurrentStep)
+	})
+
+	t.Run("CancelUsesDialogMessage", func(t *testing.T) {
+		b.setUserState(ctx, 123, models.StateManagerWaitingComment, map[string]interface{}{"item_id": int64(1)})
+
+		say(123, "❌ Отмена")
+
+		assert.Equal(t, models.StateMainMenu, b.getUserState(ctx, 123).CurrentStep)
+		assert.Contains(t, textsOf(mocks.tg.getSentMessages()), b.t(ctx, "manager_booking.canceled"))
+	})
+
+	t.Run("ManagerDialogNeedsPermission", func(t *testing.T) {
+		mocks.user.roles = map[int64]*models.UserRole{300: {TelegramID: 300, Role: models.RoleViewer}}
+		b.setUserState(ctx, 300, models.StateManagerWaitingClientName, nil)
+
+		say(300, "Иван")
+
+		state := b.getUserState(ctx, 300)
+		require.NotNil(t, state)
+		assert.NotEqual(t, models.StateManagerWaitingClientPhone, state.CurrentStep)
+		assert.Empty(t, state.TempData["client_name"])
+	})
+}
+
+func TestBookingDialog_ConfirmationAfterCreate(t *testing.T) {
+	ctx := context.Background()
+	date := time.Now().AddDate(0, 0, 5)
+	confirmation := func(b *Bot) string {
+		return b.t(ctx, "booking.confirmation", "Item 1", date.Format("02.01.2006"), "Test User", "79991234567")
+	}
+	enterPhone := func(b *Bot) {
+		b.setUserState(ctx, 123, models.StatePhoneNumber, map[string]interface{}{
+			"item_id":   int64(1),
+			"date":      date,
+			"user_name": "Test User",
+		})
+		b.handleMessage(ctx, &tgbotapi.Update{Message: &tgbotapi.Message{
+			From: &tgbotapi.User{ID: 123},
+			Chat: &tgbotapi.Chat{ID: 123},
+			Text: "89991234567",
+		}})
+	}
+
+	t.Run("Created", func(t *testing.T) {
+		b, mocks := setupTestBot()
+		enterPhone(b)
+
+		require.Len(t, mocks.booking.getBookings(), 1)
+		texts := textsOf(mocks.tg.getSentMessages())
+		assert.Equal(t, confirmation(b), texts[len(texts)-1])
+	})
+
+	t.Run("CreateFailed", func(t *testing.T) {
+		b, mocks := setupTestBot()
+		mocks.booking.On("CreateBooking", mock.Anything, mock.Anything).Return(database.ErrNotAvailable)
+		enterPhone(b)
+
+		texts := textsOf(mocks.tg.getSentMessages())
+		assert.NotContains(t, texts, confirmation(b))
+		assert.Contains(t, texts, b.getErrorMessage(ctx, database.ErrNotAvailable))
+	})
+}
+
+func textsOf(sent []tgbotapi.Chattable) []string {
+	texts := make([]string, 0, len(sent))
+	for _, c := range sent {
+		if msg, ok := c.(tgbotapi.MessageConfig); ok {
+			texts = append(texts, msg.Text)
+		}
+	}
+	return texts
+}
@@ -0,0 +1,356 @@
+package bot
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"time"
+
+	"bronivik/internal/config"
+	"bronivik/internal/models"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// StartDigest schedules the morning digest for managers.
+func (b *Bot) StartDigest(ctx context.Context) {
+	if b == nil || b.tgService == nil || !b.config.Bot.Digest.Enabled {
+		return
+	}
+
+	hour, minute, err := config.ParseClock(b.config.Bot.Digest.Time)
+	if err != nil {
+		b.logger.Error().Err(err).Str("digest_time", b.config.Bot.Digest.Time).Msg("Invalid digest time format")
+		return
+	}
+
+	go func() {
+		timer := time.NewTimer(timeUntilNextClock(hour, minute))
+		defer timer.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-timer.C:
+				b.sendManagerDigests(ctx, time.Now())
+				timer.Reset(timeUntilNextClock(hour, minute))
+			}
+		}
+	}()
+}
+
+// StartEscalations periodically escalates pending bookings nobody answered within the SLA.
+func (b *Bot) StartEscalations(ctx context.Context) {
+	if b == nil || b.tgService == nil || !b.config.Bot.Escalation.Enabled {
+		return
+	}
+
+	interval := time.Duration(b.config.Bot.Escalation.CheckMinutes) * time.Minute
+	if interval <= 0 {
+		interval = 15 * time.Minute
+	}
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				b.escalateStaleBookings(ctx, time.Now())
+			}
+		}
+	}()
+}
+
+// digestData - данные сводки, загруженные один раз для всех менеджеров
+type digestData struct {
+	today       time.Time
+	handouts    []*models.Booking
+	returns     []*models.Booking
+	stale       []*models.Booking
+	escalations map[int64]*models.BookingEscalation
+	items       []*models.Item
+	tomorrow    map[int64]int // занято единиц каждого аппарата на завтра
+}
+
+// sendManagerDigests отправляет каждому менеджеру сводку по его аппаратам
+func (b *Bot) sendManagerDigests(ctx context.Context, now time.Time) {
+	data, err := b.loadDigestData(ctx, now)
+	if err != nil {
+		b.logger.Error().Err(err).Msg("digest: load data error")
+		return
+	}
+
+	for _, managerID := range b.digestRecipients(data.items) {
+		managerCtx := b.withUserLanguage(ctx, managerID)
+		if _, err := b.tgService.Send(tgbotapi.NewMessage(managerID, b.formatDigest(managerCtx, managerID, data, now))); err != nil {
+			b.logger.Error().Err(err).Int64("manager_id", managerID).Msg("digest: send error")
+		}
+	}
+}
+
+// loadDigestData собирает выдачи и возвраты на сегодня, зависшие заявки и загрузку на завтра
+func (b *Bot) loadDigestData(ctx context.Context, now time.Time) (*digestData, error) {
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	yesterday, tomorrow := today.AddDate(0, 0, -1), today.AddDate(0, 0, 1)
+
+	bookings, err := b.bookingService.GetBookingsByDateRange(ctx, yesterday, tomorrow)
+	if err != nil {
+		return nil, err
+	}
+
+	items, err := b.itemService.GetActiveItems(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	data := &digestData{today: today, items: items, tomorrow: make(map[int64]int)}
+
+	// Аппарат выдается в первый день брони клиента и возвращается на следующий день после последнего
+	active := make(map[string]bool)
+	for _, booking := range bookings {
+		if shouldRemindStatus(booking.Status) {
+			active[bookingDayKey(booking, booking.Date)] = true
+		}
+	}
+	for _, booking := range bookings {
+		day := booking.Date.Format("2006-01-02")
+		switch {
+		case day == tomorrow.Format("2006-01-02") && isActiveBookingStatus(booking.Status):
+			data.tomorrow[booking.ItemID]++
+		case !shouldRemindStatus(booking.Status):
+		case day == today.Format("2006-01-02") && !active[bookingDayKey(booking, yesterday)]:
+			data.handouts = append(data.handouts, booking)
+		case day == yesterday.Format("2006-01-02") && !active[bookingDayKey(booking, today)]:
+			data.returns = append(data.returns, booking)
+		}
+	}
+
+	pendingAfter := time.Duration(b.config.Bot.Digest.PendingAfterHours) * time.Hour
+	data.stale, err = b.stalePendingBookings(ctx, now, pendingAfter)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]int64, 0, len(data.stale))
+	for _, booking := range data.stale {
+		ids = append(ids, booking.ID)
+	}
+	if data.escalations, err = b.bookingService.GetBookingEscalations(ctx, ids); err != nil {
+		return nil, err
+	}
+
+	return data, nil
+}
+
+// stalePendingBookings возвращает предстоящие заявки, ждущие ответа дольше age, начиная с самых старых
+func (b *Bot) stalePendingBookings(ctx context.Context, now time.Time, age time.Duration) ([]*models.Booking, error) {
+	pending, err := b.bookingService.SearchBookings(ctx, models.BookingFilter{
+		Statuses: []string{models.StatusPending},
+		DateFrom: time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC),
+		Limit:    models.MaxSearchResults,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	stale := make([]*models.Booking, 0, len(pending))
+	for _, booking := range pending {
+		if !booking.CreatedAt.IsZero() && now.Sub(booking.CreatedAt) >= age {
+			stale = append(stale, booking)
+		}
+	}
+	sort.SliceStable(stale, func(i, j int) bool { return stale[i].CreatedAt.Before(stale[j].CreatedAt) })
+	return stale, nil
+}
+
+// digestRecipients возвращает сотрудников, отвечающих за заявки хотя бы одного аппарата
+func (b *Bot) digestRecipients(items []*models.Item) []int64 {
+	seen := make(map[int64]bool)
+	var recipients []int64
+	for _, item := range items {
+		for _, id := range b.userService.GetStaffForItem(item.ID, models.PermManageBookings) {
+			if !seen[id] {
+				seen[id] = true
+				recipients = append(recipients, id)
+			}
+		}
+	}
+	sort.Slice(recipients, func(i, j int) bool { return recipients[i] < recipients[j] })
+	return recipients
+}
+
+// formatDigest формирует сводку для менеджера с учетом его области аппаратов
+func (b *Bot) formatDigest(ctx context.Context, managerID int64, data *digestData, now time.Time) string {
+	inScope := func(bookings []*models.Booking) []*models.Booking {
+		return b.filterBookingsByScope(managerID, bookings)
+	}
+
+	lines := []string{b.t(ctx, "digest.title", data.today.Format("02.01.2006"))}
+
+	section := func(header string, bookings []*models.Booking, line func(*models.Booking) string) {
+		lines = append(lines, "", header)
+		if len(bookings) == 0 {
+			lines = append(lines, b.t(ctx, "digest.none"))
+		}
+		for _, booking := range bookings {
+			lines = append(lines, line(booking))
+		}
+	}
+	bookingLine := func(booking *models.Booking) string {
+		return b.t(ctx, "digest.booking_line", booking.ID, booking.ItemName, booking.UserName, booking.Phone)
+	}
+
+	handouts, returns, stale := inScope(data.handouts), inScope(data.returns), inScope(data.stale)
+	section(b.t(ctx, "digest.handouts", len(handouts)), handouts, bookingLine)
+	section(b.t(ctx, "digest.returns", len(returns)), returns, bookingLine)
+	section(b.t(ctx, "digest.pending", b.config.Bot.Digest.PendingAfterHours, len(stale)), stale, func(booking *models.Booking) string {
+		line := b.t(ctx, "digest.pending_line", booking.ID, booking.ItemName,
+			booking.Date.Format("02.01"), booking.UserName, int(now.Sub(booking.CreatedAt).Hours()))
+		if e := data.escalations[booking.ID]; e != nil && e.AssignedTo == managerID {
+			line += " " + b.t(ctx, "digest.assigned_to_you")
+		}
+		return line
+	})
+
+	lines = append(lines, "", b.t(ctx, "digest.tomorrow"))
+	for _, item := range data.items {
+		if !b.canManageItem(managerID, item.ID) {
+			continue
+		}
+		lines = append(lines, b.t(ctx, "digest.load_line", item.Name, data.tomorrow[item.ID], item.TotalQuantity))
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// escalateStaleBookings напоминает о заявках без ответа дольше SLA или назначает их менеджеру.
+// Повторная эскалация одной заявки происходит не чаще раза в SLA и не больше max_repeats раз.
+func (b *Bot) escalateStaleBookings(ctx context.Context, now time.Time) {
+	cfg := b.config.Bot.Escalation
+	if cfg.SLAHours <= 0 {
+		return
+	}
+	sla := time.Duration(cfg.SLAHours) * time.Hour
+
+	stale, err := b.stalePendingBookings(ctx, now, sla)
+	if err != nil {
+		b.logger.Error().Err(err).Msg("escalation: load pending bookings error")
+		return
+	}
+	if len(stale) == 0 {
+		return
+	}
+
+	ids := make([]int64, 0, len(stale))
+	for _, booking := range stale {
+		ids = append(ids, booking.ID)
+	}
+	escalations, err := b.bookingService.GetBookingEscalations(ctx, ids)
+	if err != nil {
+		b.logger.Error().Err(err).Msg("escalation: load escalations error")
+		return
+	}
+
+	// Нагрузка менеджеров - сколько зависших заявок уже назначено каждому
+	load := make(map[int64]int)
+	for _, e := range escalations {
+		if e.AssignedTo != 0 {
+			load[e.AssignedTo]++
+		}
+	}
+
+	for _, booking := range stale {
+		e := escalations[booking.ID]
+		if e == nil {
+			e = &models.BookingEscalation{BookingID: booking.ID}
+		}
+		if e.Level >= cfg.MaxRepeats || (e.Level > 0 && now.Sub(e.EscalatedAt) < sla) {
+			continue
+		}
+
+		staff := b.userService.GetStaffForItem(booking.ItemID, models.PermManageBookings)
+		if len(staff) == 0 {
+			continue
+		}
+
+		recipients := staff
+		if cfg.Mode == models.EscalationModeAssign {
+			assignee := pickAssignee(staff, load, e.AssignedTo)
+			if e.AssignedTo != 0 {
+				load[e.AssignedTo]--
+			}
+			load[assignee]++
+			e.AssignedTo = assignee
+			recipients = []int64{assignee}
+		}
+
+		e.Level++
+		e.EscalatedAt = now
+		// Сначала сохраняем эскалацию: если запись не удалась, напоминание повторится
+		// на следующей проверке, а не будет приходить каждые несколько минут
+		if err := b.bookingService.SaveBookingEscalation(ctx, e); err != nil {
+			b.logger.Error().Err(err).Int64("booking_id", booking.ID).Msg("escalation: save error")
+			continue
+		}
+
+		hours := int(now.Sub(booking.CreatedAt).Hours())
+		for _, managerID := range recipients {
+			b.sendEscalation(ctx, managerID, booking, e, hours)
+		}
+	}
+}
+
+// sendEscalation отправляет менеджеру напоминание о заявке с кнопками ответа
+func (b *Bot) sendEscalation(ctx context.Context, managerID int64, booking *models.Booking, e *models.BookingEscalation, hours int) {
+	managerCtx := b.withUserLanguage(ctx, managerID)
+
+	header := b.t(managerCtx, "escalation.reping", booking.ID, hours)
+	if e.AssignedTo == managerID {
+		header = b.t(managerCtx, "escalation.assigned", booking.ID, hours)
+	}
+
+	msg := tgbotapi.NewMessage(managerID, header+"\n\n"+b.newBookingText(managerCtx, booking))
+	keyboard := b.newBookingKeyboard(managerCtx, booking.ID)
+	msg.ReplyMarkup = &keyboard
+	if _, err := b.tgService.Send(msg); err != nil {
+		b.logger.Error().Err(err).Int64("manager_id", managerID).Int64("booking_id", booking.ID).Msg("escalation: send error")
+	}
+}
+
+// pickAssignee выбирает наименее загруженного сотрудника, по возможности не того, кто уже не ответил
+func pickAssignee(staff []int64, load map[int64]int, previous int64) int64 {
+	best := int64(0)
+	for _, id := range staff {
+		if id == previous && len(staff) > 1 {
+			continue
+		}
+		if best == 0 || load[id] < load[best] {
+			best = id
+		}
+	}
+	return best
+}
+
+// isActiveBookingStatus - заявки, которые занимают аппарат
+func isActiveBookingStatus(status string) bool {
+	switch status {
+	case models.StatusPending, models.StatusConfirmed, models.StatusChanged:
+		return true
+	}
+	return false
+}
+
+// timeUntilNextClock возвращает время до ближайшего наступления hour:minute по местному времени
+func timeUntilNextClock(hour, minute int) time.Duration {
+	now := time.Now()
+	next := time.Date(now.Year(), now.Month(), now.Day(), hour, minute, 0, 0, now.Location())
+	if !next.After(now) {
+		next = next.Add(24 * time.Hour)
+	}
+	return next.Sub(now)
+}
@@ -0,0 +1,181 @@
+package bot
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"bronivik/internal/config"
+	"bronivik/internal/models"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/rs/zerolog"
+)
+
+// dispatcher раздает обновления пулу обработчиков. Обновления разных чатов обрабатываются параллельно,
+// одного чата - строго по очереди, чтобы шаги UserState не перемешались.
+type dispatcher struct {
+	process func(context.Context, *tgbotapi.Update)
+	metrics *Metrics
+	logger  *zerolog.Logger
+	workers int
+	drain   time.Duration
+
+	// slots ограничивает число принятых, но еще не обработанных обновлений.
+	// Когда свободных мест нет, submit ждет, и бот перестает читать новые обновления.
+	slots chan struct{}
+	// ready - чаты, у которых есть обновления и нет занятого ими обработчика
+	ready chan int64
+
+	mu     sync.Mutex
+	queues map[int64][]queuedUpdate // наличие ключа означает, что чат уже в ready или в обработке
+	wg     sync.WaitGroup
+}
+
+type queuedUpdate struct {
+	update   tgbotapi.Update
+	received time.Time
+}
+
+func newDispatcher(
+	cfg config.DispatcherConfig,
+	process func(context.Context, *tgbotapi.Update),
+	metrics *Metrics,
+	logger *zerolog.Logger,
+) *dispatcher {
+	if cfg.Workers <= 0 {
+		cfg.Workers = models.DispatcherWorkers
+	}
+	if cfg.QueueSize <= 0 {
+		cfg.QueueSize = models.DispatcherQueueSize
+	}
+	if cfg.DrainTimeout <= 0 {
+		cfg.DrainTimeout = models.DispatcherDrainTimeout
+	}
+
+	return &dispatcher{
+		process: process,
+		metrics: metrics,
+		logger:  logger,
+		workers: cfg.Workers,
+		drain:   time.Duration(cfg.DrainTimeout) * time.Second,
+		slots:   make(chan struct{}, cfg.QueueSize),
+		// В ready не бывает больше чатов, чем принятых обновлений, поэтому запись в него не блокируется
+		ready:  make(chan int64, cfg.QueueSize),
+		queues: make(map[int64][]queuedUpdate),
+	}
+}
+
+// start запускает обработчики. Контекст обработчиков не должен отменяться при остановке бота,
+// иначе принятые обновления не успеют завершиться.
+func (d *dispatcher) start(ctx context.Context) {
+	for i := 0; i < d.workers; i++ {
+		d.wg.Add(1)
+		go d.work(ctx)
+	}
+}
+
+// submit ставит обновление в очередь его чата. Возвращает false, если ctx отменен раньше,
+// чем освободилось место.
+func (d *dispatcher) submit(ctx context.Context, update *tgbotapi.Update) bool {
+	select {
+	case d.slots <- struct{}{}:
+	default:
+		if d.metrics != nil {
+			d.metrics.DispatcherBackpressure.Inc()
+		}
+		select {
+		case d.slots <- struct{}{}:
+		case <-ctx.Done():
+			return false
+		}
+	}
+	if d.metrics != nil {
+		d.metrics.DispatcherQueued.Inc()
+	}
+
+	key := updateChatKey(update)
+	d.mu.Lock()
+	queue, busy := d.queues[key]
+	d.queues[key] = append(queue, queuedUpdate{update: *update, received: time.Now()})
+	d.mu.Unlock()
+
+	if !busy {
+		d.ready <- key
+	}
+	return true
+}
+
+// stop перестает принимать обновления и ждет обработки принятых, но не дольше drain.
+// Вызывается после того, как submit больше не вызывается.
+func (d *dispatcher) stop() bool {
+	close(d.ready)
+
+	done := make(chan struct{})
+	go func() {
+		d.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return true
+	case <-time.After(d.drain):
+		d.mu.Lock()
+		left := 0
+		for _, queue := range d.queues {
+			left += len(queue)
+		}
+		d.mu.Unlock()
+		d.logger.Warn().Int("updates_left", left).Dur("timeout", d.drain).Msg("Dispatcher drain timed out")
+		return false
+	}
+}
+
+// work обрабатывает очередь готового чата целиком, прежде чем взять следующий чат
+func (d *dispatcher) work(ctx context.Context) {
+	defer d.wg.Done()
+	for key := range d.ready {
+		for {
+			d.mu.Lock()
+			queue := d.queues[key]
+			if len(queue) == 0 {
+				delete(d.queues, key)
+				d.mu.Unlock()
+				break
+			}
+			next := queue[0]
+			d.queues[key] = queue[1:]
+			d.mu.Unlock()
+
+			d.handle(ctx, next)
+		}
+	}
+}
+
+func (d *dispatcher) handle(ctx context.Context, item queuedUpdate) {
+	if d.metrics != nil {
+		d.metrics.DispatcherWaitTime.Observe(time.Since(item.received).Seconds())
+		d.metrics.DispatcherBusyWorkers.Inc()
+	}
+	defer func() {
+		<-d.slots
+		if d.metrics != nil {
+			d.metrics.DispatcherBusyWorkers.Dec()
+			d.metrics.DispatcherQueued.Dec()
+		}
+	}()
+
+	d.process(ctx, &item.update)
+}
+
+// updateChatKey определяет очередь обновления: чат, а для обновлений без чата - пользователя
+func updateChatKey(update *tgbotapi.Update) int64 {
+	if chat := update.FromChat(); chat != nil {
+		return chat.ID
+	}
+	if user := update.SentFrom(); user != nil {
+		return user.ID
+	}
+	return 0
+}
@@ -0,0 +1,113 @@
+package bot
+
+import (
+	"context"
+	"io"
+	"sync"
+	"testing"
+	"time"
+
+	"bronivik/internal/config"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func chatUpdate(chatID int64, text string) *tgbotapi.Update {
+	return &tgbotapi.Update{Message: &tgbotapi.Message{
+		Chat: &tgbotapi.Chat{ID: chatID},
+		From: &tgbotapi.User{ID: chatID},
+		Text: text,
+	}}
+}
+
+func TestDispatcher_OrderWithinChat(t *testing.T) {
+	logger := zerolog.New(io.Discard)
+	ctx := context.Background()
+
+	var mu sync.Mutex
+	processed := make(map[int64][]string)
+	release := make(chan struct{})
+	otherDone := make(chan struct{})
+
+	d := newDispatcher(config.DispatcherConfig{Workers: 4, QueueSize: 16}, func(_ context.Context, u *tgbotapi.Update) {
+		chatID := u.Message.Chat.ID
+		if chatID == 1 && u.Message.Text == "1" {
+			<-release
+		}
+		mu.Lock()
+		processed[chatID] = append(processed[chatID], u.Message.Text)
+		mu.Unlock()
+		if chatID == 2 {
+			close(otherDone)
+		}
+	}, nil, &logger)
+	d.start(ctx)
+
+	for _, text := range []string{"1", "2", "3"} {
+		require.True(t, d.submit(ctx, chatUpdate(1, text)))
+	}
+	require.True(t, d.submit(ctx, chatUpdate(2, "other")))
+
+	// Медленное обновление первого чата не задерживает второй чат
+	select {
+	case <-otherDone:
+	case <-time.After(time.Second):
+		t.Fatal("update of another chat is blocked by a slow chat")
+	}
+	mu.Lock()
+	assert.Empty(t, processed[1])
+	mu.Unlock()
+
+	close(release)
+	require.True(t, d.stop())
+	assert.Equal(t, []string{"1", "2", "3"}, processed[1])
+	assert.Equal(t, []string{"other"}, processed[2])
+}
+
+func TestDispatcher_Backpressure(t *testing.T) {
+	logger := zerolog.New(io.Discard)
+	release := make(chan struct{})
+
+	d := newDispatcher(config.DispatcherConfig{Workers: 1, QueueSize: 1}, func(context.Context, *tgbotapi.Update) {
+		<-release
+	}, nil, &logger)
+	d.start(context.Background())
+
+	require.True(t, d.submit(context.Background(), chatUpdate(1, "first")))
+
+	// Очередь заполнена: submit ждет свободного места, пока не отменят контекст
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	assert.False(t, d.submit(ctx, chatUpdate(2, "second")))
+
+	close(release)
+	assert.True(t, d.stop())
+}
+
+func TestDispatcher_DrainTimeout(t *testing.T) {
+	logger := zerolog.New(io.Discard)
+	release := make(chan struct{})
+	defer close(release)
+
+	d := newDispatcher(config.DispatcherConfig{Workers: 1, QueueSize: 4}, func(context.Context, *tgbotapi.Update) {
+		<-release
+	}, nil, &logger)
+	d.drain = 20 * time.Millisecond
+	d.start(context.Background())
+
+	require.True(t, d.submit(context.Background(), chatUpdate(1, "stuck")))
+	assert.False(t, d.stop(), "зависшее обновление не задерживает остановку дольше drain_timeout")
+}
+
+func TestUpdateChatKey(t *testing.T) {
+	assert.Equal(t, int64(5), updateChatKey(chatUpdate(5, "")))
+	assert.Equal(t, int64(7), updateChatKey(&tgbotapi.Update{CallbackQuery: &tgbotapi.CallbackQuery{
+		From:    &tgbotapi.User{ID: 9},
+		Message: &tgbotapi.Message{Chat: &tgbotapi.Chat{ID: 7}},
+	}}))
+	assert.Equal(t, int64(9), updateChatKey(&tgbotapi.Update{InlineQuery: &tgbotapi.InlineQuery{From: &tgbotapi.User{ID: 9}}}))
+	assert.Zero(t, updateChatKey(&tgbotapi.Update{}))
+}
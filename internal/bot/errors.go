@@ -1,32 +1,49 @@
 package bot
 
 import (
+	"context"
 	"errors"
 
 	"bronivik/internal/database"
+	"bronivik/internal/models"
 )
 
-func (b *Bot) getErrorMessage(err error) string {
+// errorMessages сопоставляет известные ошибки с ключами каталога сообщений
+var errorMessages = []struct {
+	err error
+	key string
+}{
+	{database.ErrNotAvailable, "error.not_available"},
+	{database.ErrPastDate, "error.past_date"},
+	{database.ErrDateTooFar, "error.date_too_far"},
+	{database.ErrConcurrentModification, "error.concurrent_modification"},
+	{models.ErrRoleManagedByConfig, "error.role_managed_by_config"},
+	{models.ErrInvalidRole, "error.invalid_role"},
+	{models.ErrCannotBlockStaff, "error.cannot_block_staff"},
+	{models.ErrBlacklistManagedByConfig, "error.blacklist_managed_by_config"},
+	{models.ErrHandoverNotAllowed, "error.handover_not_allowed"},
+	{models.ErrAlreadyCheckedOut, "error.already_checked_out"},
+	{models.ErrNotCheckedOut, "error.not_checked_out"},
+	{models.ErrInvalidMaintenancePeriod, "error.maintenance_period"},
+	{models.ErrInvalidMaintenanceUnits, "error.maintenance_units"},
+	{models.ErrMaintenanceNotFound, "error.maintenance_not_found"},
+	{models.ErrExternalHold, "error.external_hold"},
+	{models.ErrUnitNotFound, "error.unit_not_found"},
+	{models.ErrDuplicateSerial, "error.duplicate_serial"},
+	{models.ErrInvalidUnitStatus, "error.invalid_unit_status"},
+}
+
+func (b *Bot) getErrorMessage(ctx context.Context, err error) string {
 	if err == nil {
 		return ""
 	}
 
-	if errors.Is(err, database.ErrNotAvailable) {
-		return "⚠️ Извините, этот аппарат уже забронирован на выбранную дату. Пожалуйста, выберите другое время или аппарат."
-	}
-
-	if errors.Is(err, database.ErrPastDate) {
-		return "⚠️ Нельзя создавать бронирование на прошедшую дату."
-	}
-
-	if errors.Is(err, database.ErrDateTooFar) {
-		return "⚠️ Вы не можете бронировать так далеко в будущем. Пожалуйста, выберите более раннюю дату."
-	}
-
-	if errors.Is(err, database.ErrConcurrentModification) {
-		return "⚠️ Произошла ошибка при сохранении (конфликт версий). Пожалуйста, попробуйте еще раз."
+	for _, e := range errorMessages {
+		if errors.Is(err, e.err) {
+			return b.t(ctx, e.key)
+		}
 	}
 
 	// Default error message
-	return "❌ Произошла ошибка при обработке вашего запроса. Пожалуйста, попробуйте позже или обратитесь к менеджеру."
+	return b.t(ctx, "error.default")
 }
@@ -35,7 +35,7 @@ func (b *Bot) exportToExcel(ctx context.Context, startDate, endDate time.Time) (
 	defer f.Close()
 
 	// Создаем лист с данными
-	sheetName := "Бронирования"
+	sheetName := b.t(ctx, "xlsx.bookings_sheet")
 	index, err := f.NewSheet(sheetName)
 	if err != nil {
 		return "", fmt.Errorf("error creating sheet: %v", err)
@@ -43,7 +43,7 @@ func (b *Bot) exportToExcel(ctx context.Context, startDate, endDate time.Time) (
 	f.SetActiveSheet(index)
 
 	// Устанавливаем заголовок периода
-	_ = f.SetCellValue(sheetName, "A1", fmt.Sprintf("Период: %s - %s",
+	_ = f.SetCellValue(sheetName, "A1", b.t(ctx, "xlsx.period",
 		startDate.Format("02.01.2006"), endDate.Format("02.01.2006")))
 
 	// Заголовки - даты
@@ -52,8 +52,17 @@ func (b *Bot) exportToExcel(ctx context.Context, startDate, endDate time.Time) (
 	// Названия аппаратов
 	b.writeItemHeaders(f, sheetName, items)
 
+	// Окна обслуживания уменьшают число доступных аппаратов в ячейках расписания
+	maintenance, err := b.itemService.GetMaintenanceWindows(ctx, 0, startDate, endDate)
+	if err != nil {
+		b.logger.Error().Err(err).Msg("Error getting maintenance windows for export")
+	}
+
+	// Серийные номера закрепленных за заявками экземпляров
+	serials := b.unitSerials(ctx, items)
+
 	// Заполняем данные по бронированиям
-	b.writeBookingData(ctx, f, sheetName, dailyBookings, items, dateHeaders)
+	b.writeBookingData(ctx, f, sheetName, dailyBookings, maintenance, serials, items, dateHeaders)
 
 	// Настраиваем ширину колонок
 	_ = f.SetColWidth(sheetName, "A", "A", 25)
@@ -72,6 +81,14 @@ func (b *Bot) exportToExcel(ctx context.Context, startDate, endDate time.Time) (
 	})
 	_ = f.SetCellStyle(sheetName, "A1", "A1", style)
 
+	// Лист выдачи и возврата: плановое и фактическое использование
+	handovers, err := b.bookingService.GetHandoversByPeriod(ctx, startDate, endDate)
+	if err != nil {
+		b.logger.Error().Err(err).Msg("Error getting handovers for export")
+	} else if err := b.writeHandoversSheet(ctx, f, handovers, serials); err != nil {
+		b.logger.Error().Err(err).Msg("Error writing handovers sheet")
+	}
+
 	// Удаляем стандартный лист
 	_ = f.DeleteSheet("Sheet1")
 
@@ -89,6 +106,87 @@ func (b *Bot) exportToExcel(ctx context.Context, startDate, endDate time.Time) (
 	return filePath, nil
 }
 
+// writeHandoversSheet добавляет лист с плановыми и фактическими датами выдачи и возврата
+func (b *Bot) writeHandoversSheet(ctx context.Context, f *excelize.File, handovers []*models.Handover, serials map[int64]string) error {
+	sheetName := b.t(ctx, "xlsx.handovers_sheet")
+	if _, err := f.NewSheet(sheetName); err != nil {
+		return fmt.Errorf("error creating sheet: %v", err)
+	}
+
+	columns := []string{
+		"booking", "client", "item", "planned_start", "planned_end", "checked_out", "checked_in",
+		"delay", "checked_out_by", "checked_in_by", "check_out_note", "check_in_note", "unit",
+	}
+	headers := make([]interface{}, 0, len(columns))
+	for _, col := range columns {
+		headers = append(headers, b.t(ctx, "xlsx.handover_col."+col))
+	}
+	_ = f.SetSheetRow(sheetName, "A1", &headers)
+	style, _ := f.NewStyle(&excelize.Style{
+		Fill: excelize.Fill{Type: "pattern", Color: []string{"#DDEBF7"}, Pattern: 1},
+		Font: &excelize.Font{Bold: true},
+	})
+	_ = f.SetCellStyle(sheetName, "A1", "M1", style)
+
+	now := time.Now()
+	for i, h := range handovers {
+		checkedIn, checkedInBy := b.t(ctx, "xlsx.not_returned"), ""
+		if h.IsReturned() {
+			checkedIn = h.CheckedInAt.Time.Format("02.01.2006 15:04")
+			checkedInBy = fmt.Sprintf("%d", h.CheckedInBy)
+		}
+		row := []interface{}{
+			h.BookingID,
+			h.UserName,
+			h.ItemName,
+			h.PlannedStart.Format("02.01.2006"),
+			h.ReturnDate().Format("02.01.2006"),
+			h.CheckedOutAt.Format("02.01.2006 15:04"),
+			checkedIn,
+			h.DelayDays(now),
+			h.CheckedOutBy,
+			checkedInBy,
+			h.CheckOutNote,
+			h.CheckInNote,
+			unitSerial(serials, h.UnitID),
+		}
+		cell, _ := excelize.CoordinatesToCellName(1, i+2)
+		_ = f.SetSheetRow(sheetName, cell, &row)
+	}
+
+	_ = f.SetColWidth(sheetName, "A", "J", 18)
+	_ = f.SetColWidth(sheetName, "K", "L", 40)
+	_ = f.SetColWidth(sheetName, "M", "M", 18)
+	return nil
+}
+
+// unitSerials возвращает серийные номера экземпляров аппаратов по их ID
+func (b *Bot) unitSerials(ctx context.Context, items []*models.Item) map[int64]string {
+	serials := make(map[int64]string)
+	for _, item := range items {
+		units, err := b.itemService.GetItemUnits(ctx, item.ID)
+		if err != nil {
+			b.logger.Error().Err(err).Int64("item_id", item.ID).Msg("Error getting item units for export")
+			continue
+		}
+		for _, u := range units {
+			serials[u.ID] = u.SerialNumber
+		}
+	}
+	return serials
+}
+
+// unitSerial возвращает серийный номер экземпляра, а для неизвестного экземпляра - его ID
+func unitSerial(serials map[int64]string, unitID int64) string {
+	if unitID == 0 {
+		return ""
+	}
+	if serial, ok := serials[unitID]; ok {
+		return serial
+	}
+	return fmt.Sprintf("#%d", unitID)
+}
+
 func (b *Bot) writeDateHeaders(f *excelize.File, sheetName string, startDate, endDate time.Time) map[string]int {
 	col := 2
 	currentDate := startDate
@@ -132,12 +230,15 @@ func (b *Bot) writeItemHeaders(f *excelize.File, sheetName string, items []*mode
 func (b *Bot) writeBookingData(
 	ctx context.Context, f *excelize.File, sheetName string,
 	dailyBookings map[string][]*models.Booking,
+	maintenance []*models.MaintenanceWindow,
+	serials map[int64]string,
 	items []*models.Item,
 	dateHeaders map[string]int,
 ) {
-	for dateKey, bookings := range dailyBookings {
-		col, exists := dateHeaders[dateKey]
-		if !exists {
+	for dateKey, col := range dateHeaders {
+		bookings := dailyBookings[dateKey]
+		date := parseDate(dateKey)
+		if len(bookings) == 0 && !hasMaintenanceOn(maintenance, date) {
 			continue
 		}
 
@@ -150,30 +251,50 @@ func (b *Bot) writeBookingData(
 		for _, item := range items {
 			cell, _ := excelize.CoordinatesToCellName(col, row)
 			itemBookings := bookingsByItem[item.ID]
+			units := models.MaintenanceUnits(maintenance, item.ID, date)
+			external := models.ExternalHoldUnits(maintenance, item.ID, date)
+			capacity := item.EffectiveQuantity(units)
 
-			bookedCount, err := b.bookingService.GetBookedCount(ctx, item.ID, parseDate(dateKey))
+			bookedCount, err := b.bookingService.GetBookedCount(ctx, item.ID, date)
 			if err != nil {
 				b.logger.Error().Err(err).Int64("item_id", item.ID).Str("date", dateKey).Msg("Error getting booked count")
 				bookedCount = 0
 			}
 
 			var cellValue string
+			if repair := units - external; repair > 0 {
+				cellValue = b.t(ctx, "xlsx.maintenance", repair) + "\n"
+			}
+			if external > 0 {
+				cellValue += b.t(ctx, "xlsx.external_hold", external) + "\n"
+			}
 			if len(itemBookings) > 0 {
 				for _, booking := range itemBookings {
 					status := b.getBookingStatusIcon(booking.Status)
 					cellValue += fmt.Sprintf("%s %s (%s)\n", status, booking.UserName, booking.Phone)
+					if booking.UnitID != 0 {
+						cellValue += fmt.Sprintf("   🔖 S/N %s\n", unitSerial(serials, booking.UnitID))
+					}
 					if booking.Comment != "" {
 						cellValue += fmt.Sprintf("   💬 %s\n", booking.Comment)
 					}
 				}
-				cellValue += fmt.Sprintf("\nЗанято: %d/%d", bookedCount, item.TotalQuantity)
+				cellValue += "\n" + b.t(ctx, "xlsx.booked", bookedCount, capacity)
+				if int64(bookedCount) > capacity {
+					cellValue += "\n" + b.t(ctx, "xlsx.overbooked")
+				}
 			} else {
-				cellValue = fmt.Sprintf("Свободно\n\nДоступно: %d/%d", item.TotalQuantity, item.TotalQuantity)
+				cellValue += b.t(ctx, "xlsx.free", capacity, item.TotalQuantity)
 			}
 
 			_ = f.SetCellValue(sheetName, cell, cellValue)
 
-			styleID, err := b.getCellStyle(f, itemBookings, bookedCount, int(item.TotalQuantity))
+			var styleID int
+			if units > 0 && len(b.filterActiveBookings(itemBookings)) == 0 {
+				styleID, err = b.getMaintenanceCellStyle(f)
+			} else {
+				styleID, err = b.getCellStyle(f, itemBookings, bookedCount, int(capacity))
+			}
 			if err == nil {
 				_ = f.SetCellStyle(sheetName, cell, cell, styleID)
 			}
@@ -182,6 +303,16 @@ func (b *Bot) writeBookingData(
 	}
 }
 
+// hasMaintenanceOn проверяет, есть ли в этот день аппараты на обслуживании
+func hasMaintenanceOn(maintenance []*models.MaintenanceWindow, date time.Time) bool {
+	for _, w := range maintenance {
+		if w.Covers(date) {
+			return true
+		}
+	}
+	return false
+}
+
 func (b *Bot) getBookingStatusIcon(status string) string {
 	switch status {
 	case models.StatusConfirmed, models.StatusCompleted:
@@ -269,6 +400,18 @@ func (b *Bot) getCellStyle(f *excelize.File, itemBookings []*models.Booking, boo
 	return style, err
 }
 
+// getMaintenanceCellStyle возвращает стиль свободной ячейки, в которой часть аппаратов на обслуживании
+func (b *Bot) getMaintenanceCellStyle(f *excelize.File) (int, error) {
+	return f.NewStyle(&excelize.Style{
+		Fill: excelize.Fill{Type: "pattern", Color: []string{"#D9D9D9"}, Pattern: 1},
+		Alignment: &excelize.Alignment{
+			Horizontal: "left",
+			Vertical:   "top",
+			WrapText:   true,
+		},
+	})
+}
+
 // filterActiveBookings фильтрует активные заявки
 func (b *Bot) filterActiveBookings(bookings []*models.Booking) []*models.Booking {
 	var active []*models.Booking
@@ -294,7 +437,7 @@ func getLastColumn(colCount int) string {
 }
 
 // exportUsersToExcel создает Excel файл с данными пользователей
-func (b *Bot) exportUsersToExcel(_ context.Context, users []*models.User) (string, error) {
+func (b *Bot) exportUsersToExcel(ctx context.Context, users []*models.User) (string, error) {
 	// Создаем папку для экспорта, если не существует
 	if err := os.MkdirAll(b.config.Exports.Path, 0o755); err != nil {
 		return "", fmt.Errorf("error creating export directory: %v", err)
@@ -304,7 +447,8 @@ func (b *Bot) exportUsersToExcel(_ context.Context, users []*models.User) (strin
 	f := excelize.NewFile()
 
 	// Создаем лист с пользователями
-	index, err := f.NewSheet("Пользователи")
+	sheet := b.t(ctx, "xlsx.users_sheet")
+	index, err := f.NewSheet(sheet)
 	if err != nil {
 		return "", fmt.Errorf("error creating sheet: %v", err)
 	}
@@ -312,43 +456,50 @@ func (b *Bot) exportUsersToExcel(_ context.Context, users []*models.User) (strin
 
 	// Заголовки
 	headers := []string{
-		"ID", "Telegram ID", "Username", "Имя", "Фамилия", "Телефон",
-		"Менеджер", "Черный список", "Язык", "Последняя активность", "Дата регистрации",
+		"id", "telegram_id", "username", "first_name", "last_name", "phone",
+		"manager", "blacklisted", "language", "last_activity", "registered",
+		"block_reason", "blocked_until",
 	}
 	for i, header := range headers {
 		cell, _ := excelize.CoordinatesToCellName(i+1, 1)
-		_ = f.SetCellValue("Пользователи", cell, header)
-		// f.SetCellStyle("Пользователи", cell, cell, f.SetCellStyle("Пользователи", cell, "bold")
+		_ = f.SetCellValue(sheet, cell, b.t(ctx, "xlsx.user_col."+header))
 	}
 
 	// Данные пользователей
+	now := time.Now()
 	for i, user := range users {
 		row := i + 2
-		_ = f.SetCellValue("Пользователи", fmt.Sprintf("A%d", row), user.ID)
-		_ = f.SetCellValue("Пользователи", fmt.Sprintf("B%d", row), user.TelegramID)
-		_ = f.SetCellValue("Пользователи", fmt.Sprintf("C%d", row), user.Username)
-		_ = f.SetCellValue("Пользователи", fmt.Sprintf("D%d", row), user.FirstName)
-		_ = f.SetCellValue("Пользователи", fmt.Sprintf("E%d", row), user.LastName)
-		_ = f.SetCellValue("Пользователи", fmt.Sprintf("F%d", row), user.Phone)
-		_ = f.SetCellValue("Пользователи", fmt.Sprintf("G%d", row), boolToYesNo(user.IsManager))
-		_ = f.SetCellValue("Пользователи", fmt.Sprintf("H%d", row), boolToYesNo(user.IsBlacklisted))
-		_ = f.SetCellValue("Пользователи", fmt.Sprintf("I%d", row), user.LanguageCode)
-		_ = f.SetCellValue("Пользователи", fmt.Sprintf("J%d", row), user.LastActivity.Format("02.01.2006 15:04"))
-		_ = f.SetCellValue("Пользователи", fmt.Sprintf("K%d", row), user.CreatedAt.Format("02.01.2006 15:04"))
+		_ = f.SetCellValue(sheet, fmt.Sprintf("A%d", row), user.ID)
+		_ = f.SetCellValue(sheet, fmt.Sprintf("B%d", row), user.TelegramID)
+		_ = f.SetCellValue(sheet, fmt.Sprintf("C%d", row), user.Username)
+		_ = f.SetCellValue(sheet, fmt.Sprintf("D%d", row), user.FirstName)
+		_ = f.SetCellValue(sheet, fmt.Sprintf("E%d", row), user.LastName)
+		_ = f.SetCellValue(sheet, fmt.Sprintf("F%d", row), user.Phone)
+		_ = f.SetCellValue(sheet, fmt.Sprintf("G%d", row), b.yesNo(ctx, user.IsManager))
+		_ = f.SetCellValue(sheet, fmt.Sprintf("H%d", row), b.yesNo(ctx, user.IsBlockedAt(now)))
+		_ = f.SetCellValue(sheet, fmt.Sprintf("I%d", row), user.LanguageCode)
+		_ = f.SetCellValue(sheet, fmt.Sprintf("J%d", row), user.LastActivity.Format("02.01.2006 15:04"))
+		_ = f.SetCellValue(sheet, fmt.Sprintf("K%d", row), user.CreatedAt.Format("02.01.2006 15:04"))
+		if user.IsBlockedAt(now) {
+			_ = f.SetCellValue(sheet, fmt.Sprintf("L%d", row), user.BlacklistReason)
+			_ = f.SetCellValue(sheet, fmt.Sprintf("M%d", row), b.blacklistTerm(ctx, user))
+		}
 	}
 
 	// Настраиваем ширину колонок
-	_ = f.SetColWidth("Пользователи", "A", "A", 10)
-	_ = f.SetColWidth("Пользователи", "B", "B", 15)
-	_ = f.SetColWidth("Пользователи", "C", "C", 20)
-	_ = f.SetColWidth("Пользователи", "D", "D", 15)
-	_ = f.SetColWidth("Пользователи", "E", "E", 15)
-	_ = f.SetColWidth("Пользователи", "F", "F", 15)
-	_ = f.SetColWidth("Пользователи", "G", "G", 10)
-	_ = f.SetColWidth("Пользователи", "H", "H", 12)
-	_ = f.SetColWidth("Пользователи", "I", "I", 10)
-	_ = f.SetColWidth("Пользователи", "J", "J", 20)
-	_ = f.SetColWidth("Пользователи", "K", "K", 20)
+	_ = f.SetColWidth(sheet, "A", "A", 10)
+	_ = f.SetColWidth(sheet, "B", "B", 15)
+	_ = f.SetColWidth(sheet, "C", "C", 20)
+	_ = f.SetColWidth(sheet, "D", "D", 15)
+	_ = f.SetColWidth(sheet, "E", "E", 15)
+	_ = f.SetColWidth(sheet, "F", "F", 15)
+	_ = f.SetColWidth(sheet, "G", "G", 10)
+	_ = f.SetColWidth(sheet, "H", "H", 12)
+	_ = f.SetColWidth(sheet, "I", "I", 10)
+	_ = f.SetColWidth(sheet, "J", "J", 20)
+	_ = f.SetColWidth(sheet, "K", "K", 20)
+	_ = f.SetColWidth(sheet, "L", "L", 30)
+	_ = f.SetColWidth(sheet, "M", "M", 22)
 
 	// Удаляем стандартный лист
 	_ = f.DeleteSheet("Sheet1")
@@ -365,10 +516,10 @@ func (b *Bot) exportUsersToExcel(_ context.Context, users []*models.User) (strin
 	return filePath, nil
 }
 
-// boolToYesNo преобразует bool в "Да"/"Нет"
-func boolToYesNo(b bool) string {
-	if b {
-		return "Да"
+// yesNo преобразует bool в "Да"/"Нет" на языке пользователя
+func (b *Bot) yesNo(ctx context.Context, v bool) string {
+	if v {
+		return b.t(ctx, "xlsx.yes")
 	}
-	return "Нет"
+	return b.t(ctx, "xlsx.no")
 }
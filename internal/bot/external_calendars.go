@@ -0,0 +1,57 @@
+package bot
+
+import (
+	"context"
+	"time"
+
+	"bronivik/internal/extcal"
+	"bronivik/internal/models"
+)
+
+// StartExternalCalendars periodically imports external calendars as holds on items.
+func (b *Bot) StartExternalCalendars(ctx context.Context) {
+	if b == nil || len(b.config.ExternalCalendars.Sources) == 0 {
+		return
+	}
+
+	interval := time.Duration(b.config.ExternalCalendars.PollMinutes) * time.Minute
+	if interval <= 0 {
+		interval = 15 * time.Minute
+	}
+	syncer := extcal.NewSyncer(b.config.ExternalCalendars, b.itemService, b.logger)
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		// Первый импорт сразу при запуске, чтобы брони не ждали первого тика
+		b.syncExternalCalendars(ctx, syncer)
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				b.syncExternalCalendars(ctx, syncer)
+			}
+		}
+	}()
+}
+
+// syncExternalCalendars импортирует календари и предупреждает сотрудников аппарата,
+// если новые внешние брони заняли аппараты, уже выданные по заявкам
+func (b *Bot) syncExternalCalendars(ctx context.Context, syncer *extcal.Syncer) {
+	for _, res := range syncer.SyncAll(ctx) {
+		if res.Err != nil || len(res.Overflow) == 0 {
+			continue
+		}
+		item, err := b.itemService.GetItemByID(ctx, res.Source.ItemID)
+		if err != nil {
+			b.logger.Error().Err(err).Int64("item_id", res.Source.ItemID).Msg("external calendar: item not found")
+			continue
+		}
+		b.logger.Warn().Str("source", res.Source.Name).Int64("item_id", item.ID).Int("days", len(res.Overflow)).
+			Msg("external calendar holds overlap bookings")
+		recipients := b.userService.GetStaffForItem(item.ID, models.PermManageBookings)
+		b.sendOverflowWarning(ctx, item, recipients, "maintenance.external_overflow", res.Overflow)
+	}
+}
@@ -0,0 +1,113 @@
+package bot
+
+import (
+	"context"
+	"strconv"
+	"strings"
+
+	"bronivik/internal/ical"
+	"bronivik/internal/models"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// handleICSCommand выдает ссылку на подписку в календаре (.ics):
+// /ics — свои заявки (сотрудникам также все подтвержденные), /ics all, /ics <id_аппарата>,
+// /ics reset [all|<id_аппарата>] — отозвать выданную ссылку и выдать новую
+func (b *Bot) handleICSCommand(ctx context.Context, update *tgbotapi.Update) {
+	chatID, userID := update.Message.Chat.ID, update.Message.From.ID
+	if b.config == nil || b.config.API.Calendar.Secret == "" || b.config.API.Calendar.PublicURL == "" {
+		b.sendMessage(chatID, b.t(ctx, "ics.not_configured"))
+		return
+	}
+
+	args := strings.Fields(strings.TrimPrefix(update.Message.Text, "/ics"))
+	reset := len(args) > 0 && args[0] == "reset"
+	if reset {
+		args = args[1:]
+	}
+	if len(args) > 1 {
+		b.sendMessage(chatID, b.t(ctx, "ics.usage"))
+		return
+	}
+
+	if len(args) == 0 {
+		feed, ok := b.icsFeed(ctx, chatID, userID, models.CalendarScopeUser, userID, reset)
+		if !ok {
+			return
+		}
+		text := b.t(ctx, "ics.personal", b.icsURL(feed))
+		if !reset && b.hasPermission(userID, models.PermViewBookings) {
+			if all, okAll := b.icsFeed(ctx, chatID, userID, models.CalendarScopeAll, 0, false); okAll {
+				text += "\n\n" + b.t(ctx, "ics.all", b.icsURL(all))
+			}
+		}
+		b.sendICSLink(ctx, chatID, text, reset)
+		return
+	}
+
+	if b.denyWithoutPermission(ctx, chatID, userID, models.PermViewBookings) {
+		return
+	}
+
+	if args[0] == models.CalendarScopeAll {
+		if feed, ok := b.icsFeed(ctx, chatID, userID, models.CalendarScopeAll, 0, reset); ok {
+			b.sendICSLink(ctx, chatID, b.t(ctx, "ics.all", b.icsURL(feed)), reset)
+		}
+		return
+	}
+
+	itemID, err := strconv.ParseInt(args[0], 10, 64)
+	if err != nil {
+		b.sendMessage(chatID, b.t(ctx, "ics.usage"))
+		return
+	}
+	item, ok := b.getItemByID(itemID)
+	if !ok {
+		b.sendMessage(chatID, b.t(ctx, "error.item_not_found"))
+		return
+	}
+	if feed, okFeed := b.icsFeed(ctx, chatID, userID, models.CalendarScopeItem, item.ID, reset); okFeed {
+		b.sendICSLink(ctx, chatID, b.t(ctx, "ics.item", item.Name, b.icsURL(feed)), reset)
+	}
+}
+
+// icsFeed возвращает действующую подписку или, при reset, отзывает ее и выдает новую.
+// Об ошибке сообщает пользователю.
+func (b *Bot) icsFeed(
+	ctx context.Context,
+	chatID, userID int64,
+	scope string,
+	subjectID int64,
+	reset bool,
+) (*models.CalendarFeed, bool) {
+	var feed *models.CalendarFeed
+	var err error
+	if reset {
+		feed, err = b.userService.ResetCalendarFeed(ctx, scope, subjectID, userID)
+	} else {
+		feed, err = b.userService.GetCalendarFeed(ctx, scope, subjectID, userID)
+	}
+	if err != nil {
+		b.logger.Error().Err(err).Str("scope", scope).Int64("subject_id", subjectID).Msg("Error issuing calendar feed")
+		b.sendMessage(chatID, b.t(ctx, "ics.error"))
+		return nil, false
+	}
+	return feed, true
+}
+
+func (b *Bot) icsURL(feed *models.CalendarFeed) string {
+	cfg := b.config.API.Calendar
+	return ical.NewSigner(cfg.Secret).URL(cfg.PublicURL, feed)
+}
+
+func (b *Bot) sendICSLink(ctx context.Context, chatID int64, text string, reset bool) {
+	if reset {
+		text = b.t(ctx, "ics.revoked") + "\n\n" + text
+	}
+	msg := tgbotapi.NewMessage(chatID, text)
+	msg.DisableWebPagePreview = true
+	if _, err := b.tgService.Send(msg); err != nil {
+		b.logger.Error().Err(err).Int64("chat_id", chatID).Msg("Failed to send calendar link")
+	}
+}
@@ -0,0 +1,75 @@
+package bot
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"bronivik/internal/config"
+	"bronivik/internal/ical"
+	"bronivik/internal/models"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestICSCommand(t *testing.T) {
+	b, mocks := setupTestBot()
+	ctx := context.Background()
+	b.config.API.Calendar = config.APICalendarConfig{Secret: "0123456789abcdef", PublicURL: "https://bronivik.example"}
+	signer := ical.NewSigner(b.config.API.Calendar.Secret)
+
+	send := func(userID int64, text string) string {
+		mocks.tg.clearSentMessages()
+		b.handleMessage(ctx, &tgbotapi.Update{Message: &tgbotapi.Message{
+			From: &tgbotapi.User{ID: userID},
+			Chat: &tgbotapi.Chat{ID: userID},
+			Text: text,
+		}})
+		texts := textsOf(mocks.tg.getSentMessages())
+		require.Len(t, texts, 1)
+		return texts[0]
+	}
+
+	reply := send(555, "/ics")
+	require.Len(t, mocks.user.feeds, 1)
+	personal := mocks.user.feeds[0]
+	assert.Equal(t, models.CalendarScopeUser, personal.Scope)
+	assert.Equal(t, int64(555), personal.SubjectID)
+	assert.Contains(t, reply, signer.URL("https://bronivik.example", personal))
+	assert.NotContains(t, reply, "/all.ics", "clients get only their own feed")
+
+	// Повторный запрос возвращает ту же ссылку
+	assert.Equal(t, reply, send(555, "/ics"))
+	assert.Len(t, mocks.user.feeds, 1)
+
+	reply = send(555, "/ics reset")
+	assert.True(t, personal.IsRevoked())
+	require.Len(t, mocks.user.feeds, 2)
+	assert.True(t, strings.HasPrefix(reply, b.t(ctx, "ics.revoked")))
+	assert.Contains(t, reply, signer.URL("https://bronivik.example", mocks.user.feeds[1]))
+
+	assert.Equal(t, b.t(ctx, msgAccessDenied), send(555, "/ics all"))
+	assert.Equal(t, b.t(ctx, msgAccessDenied), send(555, "/ics 1"))
+
+	t.Run("Staff", func(t *testing.T) {
+		mocks.user.roles = map[int64]*models.UserRole{900: {TelegramID: 900, Role: models.RoleManager}}
+
+		reply := send(900, "/ics")
+		assert.Contains(t, reply, "/api/v1/ics/user/900.ics")
+		assert.Contains(t, reply, "/api/v1/ics/all.ics")
+
+		reply = send(900, "/ics 1")
+		assert.Contains(t, reply, "Item 1")
+		assert.Contains(t, reply, "/api/v1/ics/item/1.ics")
+
+		assert.Equal(t, b.t(ctx, "error.item_not_found"), send(900, "/ics 99"))
+		assert.Equal(t, b.t(ctx, "ics.usage"), send(900, "/ics laser"))
+	})
+
+	t.Run("NotConfigured", func(t *testing.T) {
+		b.config.API.Calendar = config.APICalendarConfig{}
+		assert.Equal(t, b.t(ctx, "ics.not_configured"), send(555, "/ics"))
+	})
+}
@@ -0,0 +1,178 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"bronivik/internal/models"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+const (
+	// inlineResultsLimit - максимум аппаратов в ответе на инлайн-запрос (Telegram допускает до 50)
+	inlineResultsLimit = 20
+	// inlinePeriodDays - период доступности, если дата в запросе не указана
+	inlinePeriodDays = 7
+	// inlineCacheSeconds - сколько Telegram кеширует ответ на одинаковый запрос
+	inlineCacheSeconds = 60
+
+	// deepLinkDateLayout - формат даты в ссылке бронирования
+	deepLinkDateLayout = "20060102"
+)
+
+// inlineQuery - разобранный инлайн-запрос: слова для поиска аппарата и необязательная дата
+type inlineQuery struct {
+	words []string
+	date  time.Time
+}
+
+// parseInlineQuery разбирает запрос вида "laser 25.12". Дата без года относится к ближайшему
+// такому дню, начиная с сегодняшнего.
+func parseInlineQuery(text string, today time.Time) inlineQuery {
+	var q inlineQuery
+	for _, word := range strings.Fields(strings.ToLower(text)) {
+		if date, ok := parseInlineDate(word, today); ok && q.date.IsZero() {
+			q.date = date
+			continue
+		}
+		q.words = append(q.words, word)
+	}
+	return q
+}
+
+func parseInlineDate(word string, today time.Time) (time.Time, bool) {
+	if date, err := time.Parse("02.01.2006", word); err == nil {
+		return date, true
+	}
+	date, err := time.Parse("02.01", word)
+	if err != nil {
+		return time.Time{}, false
+	}
+	date = date.AddDate(today.Year()-date.Year(), 0, 0)
+	if date.Before(today) {
+		date = date.AddDate(1, 0, 0)
+	}
+	return date, true
+}
+
+// matches проверяет, что все слова запроса есть в названии или категории аппарата
+func (q inlineQuery) matches(item *models.Item) bool {
+	text := strings.ToLower(item.Name + " " + item.Category)
+	for _, word := range q.words {
+		if !strings.Contains(text, word) {
+			return false
+		}
+	}
+	return true
+}
+
+// handleInlineQuery отвечает на инлайн-запрос списком подходящих аппаратов и их доступностью
+// на указанную дату или на ближайшую неделю
+func (b *Bot) handleInlineQuery(ctx context.Context, query *tgbotapi.InlineQuery) {
+	if b.isBlacklisted(query.From.ID) {
+		return
+	}
+
+	items, err := b.itemService.GetActiveItems(ctx)
+	if err != nil {
+		b.logger.Error().Err(err).Msg("Error getting active items for inline query")
+		return
+	}
+
+	today := time.Now().Truncate(24 * time.Hour)
+	q := parseInlineQuery(query.Query, today)
+	start, days := today, inlinePeriodDays
+	if !q.date.IsZero() {
+		start, days = q.date, 1
+	}
+
+	results := make([]interface{}, 0, inlineResultsLimit)
+	for _, item := range items {
+		if len(results) == inlineResultsLimit {
+			break
+		}
+		if !q.matches(item) {
+			continue
+		}
+		availability, errAvail := b.bookingService.GetAvailability(ctx, item.ID, start, days)
+		if errAvail != nil {
+			b.logger.Error().Err(errAvail).Int64("item_id", item.ID).Msg("Error getting availability for inline query")
+			continue
+		}
+		results = append(results, b.inlineItemResult(ctx, item, start, availability))
+	}
+
+	answer := tgbotapi.InlineConfig{
+		InlineQueryID: query.ID,
+		Results:       results,
+		CacheTime:     inlineCacheSeconds,
+		IsPersonal:    true,
+	}
+	if len(results) == 0 {
+		answer.SwitchPMText = b.t(ctx, "inline.open_bot")
+		answer.SwitchPMParameter = "inline"
+	}
+	if _, err := b.tgService.Request(answer); err != nil {
+		b.logger.Error().Err(err).Str("query", query.Query).Msg("Failed to answer inline query")
+	}
+}
+
+// inlineItemResult формирует результат инлайн-запроса: краткая доступность в описании,
+// расписание по дням в сообщении и ссылка на бронирование первого свободного дня
+func (b *Bot) inlineItemResult(
+	ctx context.Context,
+	item *models.Item,
+	start time.Time,
+	availability []*models.Availability,
+) tgbotapi.InlineQueryResultArticle {
+	lines := []string{"🏢 " + item.Name, ""}
+	var free []string
+	var bookDate time.Time
+	for _, avail := range availability {
+		if avail.Available > 0 {
+			free = append(free, avail.Date.Format("02.01"))
+			if bookDate.IsZero() {
+				bookDate = avail.Date
+			}
+			lines = append(lines, b.t(ctx, "inline.day_free", avail.Date.Format("02.01"), avail.Available))
+		} else {
+			lines = append(lines, b.t(ctx, "inline.day_busy", avail.Date.Format("02.01")))
+		}
+	}
+
+	var description string
+	switch {
+	case len(availability) == 1 && len(free) == 1:
+		description = b.t(ctx, "inline.free_on", free[0], availability[0].Available)
+	case len(availability) == 1:
+		description = b.t(ctx, "inline.busy_on", start.Format("02.01"))
+	case len(free) == 0:
+		description = b.t(ctx, "inline.no_free_days")
+	default:
+		description = b.t(ctx, "inline.free_days", strings.Join(free, ", "))
+	}
+
+	result := tgbotapi.NewInlineQueryResultArticle(
+		fmt.Sprintf("%d_%s", item.ID, start.Format(deepLinkDateLayout)), item.Name, strings.Join(lines, "\n"))
+	result.Description = description
+	if link := b.bookingDeepLink(item.ID, bookDate); link != "" {
+		markup := tgbotapi.NewInlineKeyboardMarkup(tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonURL(b.t(ctx, "btn.inline_book"), link)))
+		result.ReplyMarkup = &markup
+	}
+	return result
+}
+
+// bookingDeepLink возвращает ссылку, которая открывает бота и начинает бронирование аппарата
+// на дату date (без даты - с выбора даты). Пустая строка, если имя бота неизвестно.
+func (b *Bot) bookingDeepLink(itemID int64, date time.Time) string {
+	payload := models.DeepLinkBook + strconv.FormatInt(itemID, 10)
+	if !date.IsZero() {
+		payload += "_" + date.Format(deepLinkDateLayout)
+	}
+	return models.StartLink(b.tgService.GetSelf().UserName, payload)
+}
@@ -0,0 +1,90 @@
+package bot
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"bronivik/internal/models"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseInlineQuery(t *testing.T) {
+	today := time.Date(2026, 10, 19, 0, 0, 0, 0, time.UTC)
+
+	q := parseInlineQuery("Laser 25.12", today)
+	assert.Equal(t, []string{"laser"}, q.words)
+	assert.Equal(t, time.Date(2026, 12, 25, 0, 0, 0, 0, time.UTC), q.date)
+
+	q = parseInlineQuery("05.01", today)
+	assert.Empty(t, q.words)
+	assert.Equal(t, time.Date(2027, 1, 5, 0, 0, 0, 0, time.UTC), q.date, "прошедший день относится к следующему году")
+
+	q = parseInlineQuery("19.10", today)
+	assert.Equal(t, today, q.date)
+
+	q = parseInlineQuery("Кресло 3.5", today)
+	assert.Equal(t, []string{"кресло", "3.5"}, q.words)
+	assert.True(t, q.date.IsZero())
+
+	assert.True(t, q.matches(&models.Item{Name: "Кресло 3.5 т"}))
+	assert.False(t, q.matches(&models.Item{Name: "Кресло"}))
+	assert.True(t, parseInlineQuery("", today).matches(&models.Item{Name: "Любой"}))
+}
+
+func TestHandleInlineQuery(t *testing.T) {
+	b, mocks := setupTestBot()
+	ctx := context.Background()
+	mocks.item.items = []*models.Item{
+		{ID: 1, Name: "Laser A", TotalQuantity: 1, IsActive: true},
+		{ID: 2, Name: "Кресло", TotalQuantity: 1, IsActive: true},
+	}
+	today := time.Now().Truncate(24 * time.Hour)
+	mocks.booking.fullyBooked = map[string]bool{today.Format("2006-01-02"): true}
+
+	answer := func(query string) tgbotapi.InlineConfig {
+		b.processUpdate(ctx, &tgbotapi.Update{InlineQuery: &tgbotapi.InlineQuery{
+			ID: "q", From: &tgbotapi.User{ID: 777}, Query: query,
+		}})
+		requests := mocks.tg.getRequests()
+		require.NotEmpty(t, requests)
+		cfg, ok := requests[len(requests)-1].(tgbotapi.InlineConfig)
+		require.True(t, ok)
+		return cfg
+	}
+
+	t.Run("Week", func(t *testing.T) {
+		cfg := answer("laser")
+		require.Len(t, cfg.Results, 1)
+		result := cfg.Results[0].(tgbotapi.InlineQueryResultArticle)
+		assert.Equal(t, "Laser A", result.Title)
+
+		tomorrow := today.AddDate(0, 0, 1)
+		assert.Contains(t, result.Description, tomorrow.Format("02.01"))
+		assert.NotContains(t, result.Description, today.Format("02.01"), "занятый день не попадает в свободные")
+
+		require.NotNil(t, result.ReplyMarkup)
+		link := result.ReplyMarkup.InlineKeyboard[0][0].URL
+		require.NotNil(t, link)
+		assert.Equal(t, "https://t.me/test_bot?start=book_1_"+tomorrow.Format(deepLinkDateLayout), *link)
+	})
+
+	t.Run("BusyDate", func(t *testing.T) {
+		cfg := answer("кресло " + today.Format("02.01.2006"))
+		require.Len(t, cfg.Results, 1)
+		result := cfg.Results[0].(tgbotapi.InlineQueryResultArticle)
+		assert.Equal(t, b.t(ctx, "inline.busy_on", today.Format("02.01")), result.Description)
+		require.NotNil(t, result.ReplyMarkup)
+		assert.Equal(t, "https://t.me/test_bot?start=book_2", *result.ReplyMarkup.InlineKeyboard[0][0].URL,
+			"без свободного дня ссылка открывает выбор даты")
+	})
+
+	t.Run("NothingFound", func(t *testing.T) {
+		cfg := answer("принтер")
+		assert.Empty(t, cfg.Results)
+		assert.NotEmpty(t, cfg.SwitchPMText)
+	})
+}
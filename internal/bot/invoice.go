@@ -0,0 +1,239 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"bronivik/internal/models"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/xuri/excelize/v2"
+)
+
+// invoiceStatuses - заявки, которые попадают в счет клиента за месяц
+var invoiceStatuses = []string{models.StatusPending, models.StatusConfirmed, models.StatusChanged, models.StatusCompleted}
+
+// handleManagerInvoiceCommands обрабатывает /invoice и /invoice_client
+func (b *Bot) handleManagerInvoiceCommands(ctx context.Context, update *tgbotapi.Update, text string) bool {
+	fields := strings.Fields(text)
+	if len(fields) == 0 {
+		return false
+	}
+
+	var handler func(context.Context, *tgbotapi.Update, []string)
+	switch fields[0] {
+	case "/invoice":
+		handler = b.handleInvoiceCommand
+	case "/invoice_client":
+		handler = b.handleClientInvoiceCommand
+	default:
+		return false
+	}
+
+	if !b.denyWithoutPermission(ctx, update.Message.Chat.ID, update.Message.From.ID, models.PermViewBookings) {
+		handler(ctx, update, fields[1:])
+	}
+	return true
+}
+
+// handleInvoiceCommand формирует счет за аренду, в которую входит заявка: /invoice <номер заявки>
+func (b *Bot) handleInvoiceCommand(ctx context.Context, update *tgbotapi.Update, args []string) {
+	chatID := update.Message.Chat.ID
+	if len(args) != 1 {
+		b.sendMessage(chatID, b.t(ctx, "invoice.usage"))
+		return
+	}
+	bookingID, err := strconv.ParseInt(strings.TrimPrefix(args[0], "#"), 10, 64)
+	if err != nil || bookingID <= 0 {
+		b.sendMessage(chatID, b.t(ctx, "invoice.usage"))
+		return
+	}
+
+	booking, err := b.bookingService.GetBooking(ctx, bookingID)
+	if err != nil || booking == nil {
+		b.sendMessage(chatID, b.t(ctx, "invoice.not_found", bookingID))
+		return
+	}
+	quote, err := b.bookingService.GetBookingQuote(ctx, booking)
+	if err != nil {
+		b.logger.Error().Err(err).Int64("booking_id", bookingID).Msg("Error getting booking quote")
+		b.sendMessage(chatID, b.t(ctx, "invoice.error"))
+		return
+	}
+
+	title := fmt.Sprintf("Счет по заявке №%d", booking.ID)
+	fileName := fmt.Sprintf("invoice_%d.xlsx", booking.ID)
+	b.sendInvoice(ctx, chatID, title, fileName, quote.Bookings, b.t(ctx, "invoice.caption_booking", booking.ID))
+}
+
+// handleClientInvoiceCommand формирует счет клиента за месяц: /invoice_client <телефон> <ММ.ГГГГ>
+func (b *Bot) handleClientInvoiceCommand(ctx context.Context, update *tgbotapi.Update, args []string) {
+	chatID := update.Message.Chat.ID
+	if len(args) != 2 {
+		b.sendMessage(chatID, b.t(ctx, "invoice.client_usage"))
+		return
+	}
+	phone := models.NormalizePhone(args[0])
+	month, err := time.Parse("01.2006", args[1])
+	if phone == "" || err != nil {
+		b.sendMessage(chatID, b.t(ctx, "invoice.client_usage"))
+		return
+	}
+
+	bookings, err := b.bookingService.SearchBookings(ctx, models.BookingFilter{
+		Statuses: invoiceStatuses,
+		DateFrom: month,
+		DateTo:   month.AddDate(0, 1, -1),
+		Phone:    phone,
+	})
+	if err != nil {
+		b.logger.Error().Err(err).Str("month", args[1]).Msg("Error searching client bookings for invoice")
+		b.sendMessage(chatID, b.t(ctx, "invoice.error"))
+		return
+	}
+	if len(bookings) == 0 {
+		b.sendMessage(chatID, b.t(ctx, "invoice.client_empty", args[0], args[1]))
+		return
+	}
+	sort.SliceStable(bookings, func(i, j int) bool {
+		if !bookings[i].Date.Equal(bookings[j].Date) {
+			return bookings[i].Date.Before(bookings[j].Date)
+		}
+		return bookings[i].ID < bookings[j].ID
+	})
+
+	title := fmt.Sprintf("Счет клиента за %s", month.Format("01.2006"))
+	fileName := fmt.Sprintf("invoice_%s_%s.xlsx", phone, month.Format("2006-01"))
+	b.sendInvoice(ctx, chatID, title, fileName, bookings, b.t(ctx, "invoice.caption_client", bookings[0].UserName, args[1]))
+}
+
+// sendInvoice создает файл счета и отправляет его документом
+func (b *Bot) sendInvoice(ctx context.Context, chatID int64, title, fileName string, bookings []*models.Booking, caption string) {
+	if !models.NewBookingQuote(bookings).IsPriced() {
+		b.sendMessage(chatID, b.t(ctx, "invoice.not_priced"))
+		return
+	}
+
+	filePath, err := b.exportInvoice(ctx, title, fileName, bookings)
+	if err != nil {
+		b.logger.Error().Err(err).Str("file_name", fileName).Msg("Error exporting invoice")
+		b.sendMessage(chatID, b.t(ctx, "invoice.error"))
+		return
+	}
+
+	file, err := os.Open(filePath)
+	if err != nil {
+		b.logger.Error().Err(err).Str("file_path", filePath).Msg("Error opening invoice file")
+		b.sendMessage(chatID, b.t(ctx, "invoice.error"))
+		return
+	}
+	defer file.Close()
+
+	doc := tgbotapi.NewDocument(chatID, tgbotapi.FileReader{Name: filepath.Base(filePath), Reader: file})
+	doc.Caption = caption
+	if _, err := b.tgService.Send(doc); err != nil {
+		b.logger.Error().Err(err).Msg("Error sending invoice document")
+		b.sendMessage(chatID, b.t(ctx, "invoice.error"))
+	}
+}
+
+// exportInvoice создает Excel файл счета: дни аренды с ценами, итог и залог
+func (b *Bot) exportInvoice(ctx context.Context, title, fileName string, bookings []*models.Booking) (string, error) {
+	if err := os.MkdirAll(b.config.Exports.Path, 0o755); err != nil {
+		return "", fmt.Errorf("error creating export directory: %v", err)
+	}
+
+	f := excelize.NewFile()
+	defer f.Close()
+
+	sheetName := "Счет"
+	index, err := f.NewSheet(sheetName)
+	if err != nil {
+		return "", fmt.Errorf("error creating sheet: %v", err)
+	}
+	f.SetActiveSheet(index)
+
+	_ = f.SetCellValue(sheetName, "A1", title)
+	_ = f.MergeCell(sheetName, "A1", "F1")
+	if len(bookings) > 0 {
+		_ = f.SetCellValue(sheetName, "A2", fmt.Sprintf("Клиент: %s, %s", bookings[0].UserName, bookings[0].Phone))
+	}
+	_ = f.SetCellValue(sheetName, "A3", fmt.Sprintf("Дата счета: %s", time.Now().Format("02.01.2006")))
+
+	headers := []interface{}{"Дата", "Заявка", "Аппарат", "Статус", "Цена, ₽", "Залог, ₽"}
+	_ = f.SetSheetRow(sheetName, "A5", &headers)
+
+	quote := models.NewBookingQuote(bookings)
+	row := 6
+	for _, booking := range bookings {
+		values := []interface{}{
+			booking.Date.Format("02.01.2006"),
+			booking.ID,
+			booking.ItemName,
+			b.statusName(ctx, booking.Status),
+			booking.Price,
+			booking.Deposit,
+		}
+		_ = f.SetSheetRow(sheetName, fmt.Sprintf("A%d", row), &values)
+		row++
+	}
+
+	totals := []interface{}{"Итого", "", "", "", quote.Total, quote.Deposit}
+	_ = f.SetSheetRow(sheetName, fmt.Sprintf("A%d", row), &totals)
+	due := []interface{}{"К оплате с залогом", "", "", "", quote.Total + quote.Deposit}
+	_ = f.SetSheetRow(sheetName, fmt.Sprintf("A%d", row+1), &due)
+
+	bold, _ := f.NewStyle(&excelize.Style{Font: &excelize.Font{Bold: true}})
+	_ = f.SetCellStyle(sheetName, "A5", "F5", bold)
+	_ = f.SetCellStyle(sheetName, fmt.Sprintf("A%d", row), fmt.Sprintf("F%d", row+1), bold)
+	titleStyle, _ := f.NewStyle(&excelize.Style{Font: &excelize.Font{Bold: true, Size: 14}})
+	_ = f.SetCellStyle(sheetName, "A1", "A1", titleStyle)
+
+	_ = f.SetColWidth(sheetName, "A", "B", 14)
+	_ = f.SetColWidth(sheetName, "C", "C", 30)
+	_ = f.SetColWidth(sheetName, "D", "F", 16)
+
+	_ = f.DeleteSheet("Sheet1")
+
+	filePath := filepath.Join(b.config.Exports.Path, fileName)
+	if err := f.SaveAs(filePath); err != nil {
+		return "", fmt.Errorf("error saving file: %v", err)
+	}
+
+	b.logger.Info().Str("file_path", filePath).Msg("Invoice file created")
+	return filePath, nil
+}
+
+// bookingPriceLines описывает стоимость заявки: цену дня, итог аренды и залог
+func (b *Bot) bookingPriceLines(ctx context.Context, booking *models.Booking) []string {
+	quote, err := b.bookingService.GetBookingQuote(ctx, booking)
+	if err != nil {
+		b.logger.Error().Err(err).Int64("booking_id", booking.ID).Msg("Error getting booking quote")
+		return nil
+	}
+	if !quote.IsPriced() {
+		return nil
+	}
+
+	var lines []string
+	if booking.Price > 0 {
+		lines = append(lines, b.t(ctx, "booking.price_day", booking.Price))
+	}
+	return append(lines, b.rentalQuoteLines(ctx, quote)...)
+}
+
+// rentalQuoteLines описывает итог аренды и залог
+func (b *Bot) rentalQuoteLines(ctx context.Context, quote *models.BookingQuote) []string {
+	lines := []string{b.t(ctx, "booking.price_total",
+		quote.Start.Format("02.01.2006"), quote.End.Format("02.01.2006"), quote.Days, quote.Total)}
+	if quote.Deposit > 0 {
+		lines = append(lines, b.t(ctx, "booking.price_deposit", quote.Deposit))
+	}
+	return lines
+}
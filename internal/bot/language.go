@@ -0,0 +1,81 @@
+package bot
+
+import (
+	"context"
+	"strings"
+
+	"bronivik/internal/i18n"
+	"bronivik/internal/models"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// t возвращает перевод ключа на язык пользователя, чье обновление обрабатывается в ctx
+func (b *Bot) t(ctx context.Context, key string, args ...interface{}) string {
+	return b.i18n.T(i18n.LanguageFromContext(ctx), key, args...)
+}
+
+// tn возвращает форму ключа, согласованную с числом n
+func (b *Bot) tn(ctx context.Context, key string, n int, args ...interface{}) string {
+	return b.i18n.N(i18n.LanguageFromContext(ctx), key, n, args...)
+}
+
+// isButton проверяет, что текст совпадает с кнопкой key на любом из поддерживаемых языков
+func (b *Bot) isButton(text, key string) bool {
+	return text != "" && b.i18n.Match(text) == key
+}
+
+// userLanguage определяет язык пользователя: выбор через /language, язык профиля, язык клиента Telegram
+func (b *Bot) userLanguage(ctx context.Context, userID int64, telegramCode string) string {
+	return b.i18n.Resolve(b.userService.GetLanguage(ctx, userID), telegramCode)
+}
+
+// languageOf возвращает язык уже загруженного пользователя
+func (b *Bot) languageOf(user *models.User) string {
+	if user == nil {
+		return b.i18n.Resolve()
+	}
+	return b.i18n.Resolve(user.PreferredLanguage, user.LanguageCode)
+}
+
+// withUserLanguage возвращает контекст для сообщений другому пользователю (уведомления)
+func (b *Bot) withUserLanguage(ctx context.Context, userID int64) context.Context {
+	return i18n.WithLanguage(ctx, b.userLanguage(ctx, userID, ""))
+}
+
+// handleLanguageCommand показывает выбор языка
+func (b *Bot) handleLanguageCommand(ctx context.Context, chatID int64) {
+	row := make([]tgbotapi.InlineKeyboardButton, 0, len(b.i18n.Languages()))
+	for _, lang := range b.i18n.Languages() {
+		row = append(row, tgbotapi.NewInlineKeyboardButtonData(b.i18n.T(lang, i18n.KeyLanguageName), "set_language:"+lang))
+	}
+	keyboard := tgbotapi.NewInlineKeyboardMarkup(row)
+
+	if _, err := b.tgService.SendWithInlineKeyboard(chatID, b.t(ctx, "language.choose"), keyboard); err != nil {
+		b.logger.Error().Err(err).Int64("chat_id", chatID).Msg("Failed to send language choice")
+	}
+}
+
+// handleSetLanguage сохраняет выбранный язык и перерисовывает меню уже на нем
+func (b *Bot) handleSetLanguage(ctx context.Context, update *tgbotapi.Update) {
+	callback := update.CallbackQuery
+	lang := strings.TrimPrefix(callback.Data, "set_language:")
+	if !b.i18n.Supports(lang) {
+		return
+	}
+
+	if err := b.userService.SetLanguage(ctx, callback.From.ID, lang); err != nil {
+		b.logger.Error().Err(err).Int64("user_id", callback.From.ID).Str("language", lang).Msg("Error saving language")
+		b.sendMessage(callback.Message.Chat.ID, b.getErrorMessage(ctx, err))
+		return
+	}
+
+	ctx = i18n.WithLanguage(ctx, lang)
+	b.sendMessage(callback.Message.Chat.ID, b.t(ctx, "language.changed"))
+	b.handleMainMenu(ctx, update)
+}
+
+// statusName возвращает название статуса заявки на языке пользователя
+func (b *Bot) statusName(ctx context.Context, status string) string {
+	return b.t(ctx, "status."+status)
+}
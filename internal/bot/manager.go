@@ -2,7 +2,6 @@ package bot
 
 import (
 	"context"
-	"fmt"
 	"strconv"
 	"strings"
 
@@ -13,7 +12,7 @@ import (
 
 // handleManagerCommand обработка команд менеджера
 func (b *Bot) handleManagerCommand(ctx context.Context, update *tgbotapi.Update) bool {
-	if !b.isManager(update.Message.From.ID) {
+	if !b.isStaff(update.Message.From.ID) {
 		return false
 	}
 
@@ -31,6 +30,51 @@ func (b *Bot) handleManagerCommand(ctx context.Context, update *tgbotapi.Update)
 		return true
 	}
 
+	// Обслуживание аппаратов
+	if b.handleManagerMaintenanceCommands(ctx, update, text) {
+		return true
+	}
+
+	// Экземпляры аппаратов
+	if b.handleManagerUnitCommands(ctx, update, text) {
+		return true
+	}
+
+	// Управление ролями
+	if b.handleManagerRoleCommands(ctx, update, text) {
+		return true
+	}
+
+	// Черный список
+	if b.handleManagerBlacklistCommands(ctx, update, text) {
+		return true
+	}
+
+	// Поиск заявок
+	if b.handleManagerSearchCommands(ctx, update, text, state) {
+		return true
+	}
+
+	// Массовые действия с заявками
+	if b.handleManagerBulkCommands(ctx, update, text) {
+		return true
+	}
+
+	// Счета по заявкам
+	if b.handleManagerInvoiceCommands(ctx, update, text) {
+		return true
+	}
+
+	// Выгрузка списка заявок
+	if b.handleManagerExportCommands(ctx, update, text) {
+		return true
+	}
+
+	// Импорт из файлов CSV и XLSX
+	if b.handleManagerImportCommands(ctx, update, text) {
+		return true
+	}
+
 	// Команды с учетом состояния
 	if state != nil && b.handleManagerStateCommands(ctx, update, text, state) {
 		return true
@@ -41,17 +85,25 @@ func (b *Bot) handleManagerCommand(ctx context.Context, update *tgbotapi.Update)
 
 // handleManagerBasicCommands обрабатывает основные команды менеджера
 func (b *Bot) handleManagerBasicCommands(ctx context.Context, update *tgbotapi.Update, text string, userID int64) bool {
+	chatID := update.Message.Chat.ID
+
 	switch {
-	case text == btnAllBookings || text == "/get_all":
-		b.showManagerBookings(ctx, update)
+	case b.isButton(text, btnAllBookings) || text == "/get_all":
+		if !b.denyWithoutPermission(ctx, chatID, userID, models.PermViewBookings) {
+			b.showManagerBookings(ctx, update)
+		}
 		return true
 
-	case text == btnCreateBookingManager:
-		b.startManagerBooking(ctx, update)
+	case b.isButton(text, btnCreateBookingManager):
+		if !b.denyWithoutPermission(ctx, chatID, userID, models.PermManageBookings) {
+			b.startManagerBooking(ctx, update)
+		}
 		return true
 
-	case text == "/stats" && b.isManager(userID):
-		b.getUserStats(ctx, update)
+	case text == "/stats" || strings.HasPrefix(text, "/stats "):
+		if !b.denyWithoutPermission(ctx, chatID, userID, models.PermViewStats) {
+			b.getUserStats(ctx, update)
+		}
 		return true
 
 	case strings.HasPrefix(text, "/manager_booking_"):
@@ -63,14 +115,24 @@ func (b *Bot) handleManagerBasicCommands(ctx context.Context, update *tgbotapi.U
 		}
 		return true
 
-	case text == btnSyncBookings:
-		b.sendMessage(update.Message.Chat.ID, "⏳ Запускаю фоновую синхронизацию бронирований...")
-		go b.SyncBookingsToSheets(ctx)
+	case strings.HasPrefix(text, "/booking_history_"):
+		if bookingID, err := strconv.ParseInt(strings.TrimPrefix(text, "/booking_history_"), 10, 64); err == nil {
+			b.showBookingHistory(ctx, chatID, userID, bookingID)
+		}
+		return true
+
+	case b.isButton(text, btnSyncBookings):
+		if !b.denyWithoutPermission(ctx, chatID, userID, models.PermSyncSheets) {
+			b.sendMessage(chatID, b.t(ctx, "sync.bookings_started"))
+			go b.SyncBookingsToSheets(ctx)
+		}
 		return true
 
-	case text == btnSyncSchedule:
-		b.sendMessage(update.Message.Chat.ID, "⏳ Запускаю фоновую синхронизацию расписания...")
-		go b.SyncScheduleToSheets(ctx)
+	case b.isButton(text, btnSyncSchedule):
+		if !b.denyWithoutPermission(ctx, chatID, userID, models.PermSyncSheets) {
+			b.sendMessage(chatID, b.t(ctx, "sync.schedule_started"))
+			go b.SyncScheduleToSheets(ctx)
+		}
 		return true
 	}
 	return false
@@ -78,63 +140,60 @@ func (b *Bot) handleManagerBasicCommands(ctx context.Context, update *tgbotapi.U
 
 // handleManagerItemCommands обрабатывает команды управления аппаратами
 func (b *Bot) handleManagerItemCommands(ctx context.Context, update *tgbotapi.Update, text string) bool {
+	var handler func(context.Context, *tgbotapi.Update)
+	perm := models.PermManageItems
+
 	switch {
 	case strings.HasPrefix(text, "/add_item"):
-		b.handleAddItemCommand(ctx, update)
-		return true
+		handler = b.handleAddItemCommand
 	case strings.HasPrefix(text, "/edit_item"):
-		b.handleEditItemCommand(ctx, update)
-		return true
+		handler = b.handleEditItemCommand
 	case strings.HasPrefix(text, "/list_items"):
-		b.handleListItemsCommand(ctx, update)
-		return true
+		handler = b.handleListItemsCommand
+		perm = models.PermViewBookings
 	case strings.HasPrefix(text, "/disable_item"):
-		b.handleDisableItemCommand(ctx, update)
-		return true
+		handler = b.handleDisableItemCommand
 	case strings.HasPrefix(text, "/set_item_order"):
-		b.handleSetItemOrderCommand(ctx, update)
-		return true
+		handler = b.handleSetItemOrderCommand
 	case strings.HasPrefix(text, "/move_item_up"):
-		b.handleMoveItemCommand(ctx, update, -1)
-		return true
+		handler = func(ctx context.Context, update *tgbotapi.Update) { b.handleMoveItemCommand(ctx, update, -1) }
 	case strings.HasPrefix(text, "/move_item_down"):
-		b.handleMoveItemCommand(ctx, update, 1)
-		return true
+		handler = func(ctx context.Context, update *tgbotapi.Update) { b.handleMoveItemCommand(ctx, update, 1) }
+	case strings.HasPrefix(text, "/qr_stickers"):
+		handler = b.handleStickersCommand
+	default:
+		return false
 	}
-	return false
+
+	if !b.denyWithoutPermission(ctx, update.Message.Chat.ID, update.Message.From.ID, perm) {
+		handler(ctx, update)
+	}
+	return true
 }
 
 // handleManagerStateCommands обрабатывает команды менеджера в зависимости от состояния
 func (b *Bot) handleManagerStateCommands(ctx context.Context, update *tgbotapi.Update, text string, state *models.UserState) bool {
-	switch state.CurrentStep {
-	case models.StateManagerWaitingClientName:
-		b.handleManagerClientName(ctx, update, text, state)
-		return true
-	case models.StateManagerWaitingClientPhone:
-		b.handleManagerClientPhone(ctx, update, text, state)
-		return true
-	case models.StateManagerWaitingSingleDate:
-		b.handleManagerSingleDate(ctx, update, text, state)
-		return true
-	case models.StateManagerWaitingStartDate:
-		b.handleManagerStartDate(ctx, update, text, state)
-		return true
-	case models.StateManagerWaitingEndDate:
-		b.handleManagerEndDate(ctx, update, text, state)
-		return true
-	case models.StateManagerWaitingComment:
-		b.handleManagerComment(ctx, update, text, state)
-		return true
-	case models.StateManagerConfirmBooking:
-		if text == btnConfirmCreate {
-			b.createManagerBookings(ctx, update, state)
-			return true
-		} else if text == btnCancel {
-			b.clearUserState(ctx, update.Message.From.ID)
-			b.sendMessage(update.Message.Chat.ID, "❌ Создание заявки отменено")
-			b.handleMainMenu(ctx, update)
-			return true
+	// Мастер аппарата доступен с правом управления аппаратами
+	if state.CurrentStep == models.StateManagerItemEditor {
+		if !b.hasPermission(update.Message.From.ID, models.PermManageItems) {
+			return false
 		}
+		b.handleItemEditorInput(ctx, update, text, state)
+		return true
+	}
+
+	if !b.hasPermission(update.Message.From.ID, models.PermManageBookings) {
+		return false
+	}
+
+	// Диалог создания заявки обрабатывает и свои кнопки "Назад" и "Отмена"
+	if managerBookingDialog.hasStep(state.CurrentStep) {
+		return managerBookingDialog.handle(ctx, b, update, state, text)
+	}
+
+	if state.CurrentStep == models.StateManagerHandover {
+		b.handleHandoverInput(ctx, update, text, state)
+		return true
 	}
 	return false
 }
@@ -153,6 +212,22 @@ func (b *Bot) handleManagerCallback(ctx context.Context, update *tgbotapi.Update
 		return true
 	}
 
+	if b.handleManagerSearchCallback(ctx, update, data) {
+		return true
+	}
+
+	if b.handleManagerBulkCallback(ctx, update, data) {
+		return true
+	}
+
+	if b.handleManagerHandoverCallback(ctx, update, data) {
+		return true
+	}
+
+	if b.handleItemEditorCallback(ctx, update, data) {
+		return true
+	}
+
 	// Проверяем тип даты и другие действия
 	if b.handleManagerMiscCallbacks(ctx, update, data) {
 		return true
@@ -165,26 +240,42 @@ func (b *Bot) handleManagerCallback(ctx context.Context, update *tgbotapi.Update
 // handleManagerPaginationAndSelection обрабатывает пагинацию и выбор аппаратов/заявок
 func (b *Bot) handleManagerPaginationAndSelection(ctx context.Context, update *tgbotapi.Update, data string) bool {
 	callback := update.CallbackQuery
+	chatID := callback.Message.Chat.ID
+	userID := callback.From.ID
+
 	switch {
 	case strings.HasPrefix(data, "manager_items_page:"):
-		page, _ := strconv.Atoi(strings.TrimPrefix(data, "manager_items_page:"))
-		b.editManagerItemsPage(update, page)
+		if !b.denyWithoutPermission(ctx, chatID, userID, models.PermManageBookings) {
+			page, _ := strconv.Atoi(strings.TrimPrefix(data, "manager_items_page:"))
+			b.editManagerItemsPage(ctx, update, page)
+		}
 		return true
 
 	case strings.HasPrefix(data, "manager_bookings_page:"):
-		page, _ := strconv.Atoi(strings.TrimPrefix(data, "manager_bookings_page:"))
-		b.sendManagerBookingsPage(ctx, callback.Message.Chat.ID, callback.Message.MessageID, page)
+		if !b.denyWithoutPermission(ctx, chatID, userID, models.PermViewBookings) {
+			page, _ := strconv.Atoi(strings.TrimPrefix(data, "manager_bookings_page:"))
+			b.sendManagerBookingsPage(ctx, chatID, callback.Message.MessageID, page)
+		}
 		return true
 
 	case strings.HasPrefix(data, "show_booking:"):
 		id, _ := strconv.ParseInt(strings.TrimPrefix(data, "show_booking:"), 10, 64)
 		if booking, err := b.bookingService.GetBooking(ctx, id); err == nil {
-			b.sendManagerBookingDetail(ctx, callback.Message.Chat.ID, booking)
+			if !b.denyWithoutItemAccess(ctx, chatID, userID, booking.ItemID) {
+				b.sendManagerBookingDetail(ctx, chatID, booking)
+			}
 		}
 		return true
 
+	case strings.HasPrefix(data, "booking_history:"):
+		id, _ := strconv.ParseInt(strings.TrimPrefix(data, "booking_history:"), 10, 64)
+		b.showBookingHistory(ctx, chatID, userID, id)
+		return true
+
 	case strings.HasPrefix(data, "manager_select_item:"):
-		b.handleManagerItemSelection(ctx, update)
+		if !b.denyWithoutPermission(ctx, chatID, userID, models.PermManageBookings) {
+			b.handleManagerItemSelection(ctx, update)
+		}
 		return true
 	}
 	return false
@@ -192,12 +283,20 @@ func (b *Bot) handleManagerPaginationAndSelection(ctx context.Context, update *t
 
 // handleManagerMiscCallbacks обрабатывает прочие callback-запросы менеджера
 func (b *Bot) handleManagerMiscCallbacks(ctx context.Context, update *tgbotapi.Update, data string) bool {
+	callback := update.CallbackQuery
+	chatID := callback.Message.Chat.ID
+	userID := callback.From.ID
+
 	switch {
 	case data == "manager_single_date":
-		b.handleManagerDateType(ctx, update, "single")
+		if !b.denyWithoutPermission(ctx, chatID, userID, models.PermManageBookings) {
+			b.handleManagerDateType(ctx, update, typeSingle)
+		}
 		return true
 	case data == "manager_date_range":
-		b.handleManagerDateType(ctx, update, "range")
+		if !b.denyWithoutPermission(ctx, chatID, userID, models.PermManageBookings) {
+			b.handleManagerDateType(ctx, update, typeRange)
+		}
 		return true
 	case strings.HasPrefix(data, "change_to_"):
 		b.handleChangeItem(ctx, update)
@@ -205,8 +304,21 @@ func (b *Bot) handleManagerMiscCallbacks(ctx context.Context, update *tgbotapi.U
 	case strings.HasPrefix(data, "call_booking:"):
 		b.handleCallButton(ctx, update)
 		return true
+	case strings.HasPrefix(data, statsPeriodPrefix), strings.HasPrefix(data, statsXLSXPrefix):
+		if !b.denyWithoutPermission(ctx, chatID, userID, models.PermViewStats) {
+			b.handleStatsCallback(ctx, update, data)
+		}
+		return true
 	case data == "export_users":
-		b.handleExportUsers(ctx, update)
+		if !b.denyWithoutPermission(ctx, chatID, userID, models.PermExportData) {
+			b.handleExportUsers(ctx, update)
+		}
+		return true
+	case strings.HasPrefix(data, "blacklist_cancel:"):
+		if !b.denyWithoutPermission(ctx, chatID, userID, models.PermManageBlacklist) {
+			targetID, _ := strconv.ParseInt(strings.TrimPrefix(data, "blacklist_cancel:"), 10, 64)
+			b.cancelFutureBookingsOfBlocked(ctx, chatID, userID, targetID)
+		}
 		return true
 	}
 	return false
@@ -241,6 +353,10 @@ func (b *Bot) handleManagerBookingActions(ctx context.Context, update *tgbotapi.
 		return true
 	}
 
+	if b.denyWithoutItemAccess(ctx, callback.Message.Chat.ID, callback.From.ID, booking.ItemID) {
+		return true
+	}
+
 	switch action {
 	case "confirm_":
 		b.confirmBooking(ctx, booking, callback.Message.Chat.ID)
@@ -258,7 +374,7 @@ func (b *Bot) handleManagerBookingActions(ctx context.Context, update *tgbotapi.
 
 	if action != "change_item_" {
 		editMsg := tgbotapi.NewEditMessageText(callback.Message.Chat.ID, callback.Message.MessageID,
-			fmt.Sprintf("✅ Заявка #%d обработана\nДействие: %s", bookingID, action))
+			b.t(ctx, "manager_booking.processed", bookingID, action))
 		if _, err := b.tgService.Send(editMsg); err != nil {
 			b.logger.Error().Err(err).Msg("Failed to send edit message in handleManagerBookingActions")
 		}
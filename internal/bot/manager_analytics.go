@@ -0,0 +1,195 @@
+package bot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"bronivik/internal/analytics"
+	"bronivik/internal/models"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+const (
+	statsPeriodPrefix = "stats_period:"
+	statsXLSXPrefix   = "stats_xlsx:"
+
+	// statsDefaultDays - период аналитики /stats без аргументов
+	statsDefaultDays = 30
+
+	statsCallbackLayout = "20060102"
+)
+
+// statsPeriodButtons - периоды, между которыми можно переключиться кнопками под статистикой
+var statsPeriodButtons = []int{7, 30, 90}
+
+// analyticsSource дает аналитике данные через сервисы бота
+type analyticsSource struct {
+	b *Bot
+}
+
+func (s analyticsSource) GetActiveItems(ctx context.Context) ([]*models.Item, error) {
+	return s.b.itemService.GetActiveItems(ctx)
+}
+
+func (s analyticsSource) GetBookingsByDateRange(ctx context.Context, start, end time.Time) ([]*models.Booking, error) {
+	return s.b.bookingService.GetBookingsByDateRange(ctx, start, end)
+}
+
+func (s analyticsSource) GetMaintenanceWindows(
+	ctx context.Context,
+	itemID int64,
+	start, end time.Time,
+) ([]*models.MaintenanceWindow, error) {
+	return s.b.itemService.GetMaintenanceWindows(ctx, itemID, start, end)
+}
+
+func (s analyticsSource) GetHandoversByPeriod(ctx context.Context, start, end time.Time) ([]*models.Handover, error) {
+	return s.b.bookingService.GetHandoversByPeriod(ctx, start, end)
+}
+
+// parseStatsPeriod разбирает аргументы /stats: без аргументов - последние 30 дней,
+// число - последние N дней, две даты ДД.ММ.ГГГГ - произвольный период
+func parseStatsPeriod(args []string, now time.Time) (start, end time.Time, err error) {
+	switch len(args) {
+	case 0:
+		start, end = analytics.Period(now, statsDefaultDays)
+	case 1:
+		days, errDays := strconv.Atoi(args[0])
+		if errDays != nil || days < 1 || days > analytics.MaxPeriodDays {
+			return start, end, analytics.ErrInvalidPeriod
+		}
+		start, end = analytics.Period(now, days)
+	case 2:
+		var errStart, errEnd error
+		start, errStart = time.Parse("02.01.2006", args[0])
+		end, errEnd = time.Parse("02.01.2006", args[1])
+		if errStart != nil || errEnd != nil {
+			return start, end, analytics.ErrInvalidPeriod
+		}
+	default:
+		return start, end, analytics.ErrInvalidPeriod
+	}
+	return start, end, analytics.ValidatePeriod(start, end)
+}
+
+// loadAnalytics считает аналитику за период и логирует ошибки загрузки данных
+func (b *Bot) loadAnalytics(ctx context.Context, start, end time.Time) (*analytics.Report, error) {
+	report, err := analytics.Load(ctx, analyticsSource{b: b}, start, end, time.Now())
+	if err != nil && !errors.Is(err, analytics.ErrInvalidPeriod) {
+		b.logger.Error().Err(err).Time("start", start).Time("end", end).Msg("Error computing analytics")
+	}
+	return report, err
+}
+
+// analyticsText форматирует отчет для /stats
+func (b *Bot) analyticsText(ctx context.Context, r *analytics.Report) string {
+	percent := func(v float64) string { return fmt.Sprintf("%.0f%%", v*100) }
+
+	var sb strings.Builder
+	sb.WriteString(b.t(ctx, "stats.analytics_title", r.Start.Format("02.01.2006"), r.End.Format("02.01.2006")))
+	sb.WriteString("\n")
+	sb.WriteString(b.t(ctx, "stats.utilization", percent(r.Utilization)))
+	sb.WriteString("\n")
+	for _, u := range r.Items {
+		sb.WriteString(b.t(ctx, "stats.item_utilization", u.ItemName, percent(u.Utilization), u.BookedDays, u.UnitDays))
+		sb.WriteString("\n")
+	}
+	sb.WriteString(b.t(ctx, "stats.cancellations", r.Canceled, r.Bookings, percent(r.CancellationRate)))
+	sb.WriteString("\n")
+	if r.NoShowTracked {
+		sb.WriteString(b.t(ctx, "stats.no_shows", r.NoShows, r.NoShowBase, percent(r.NoShowRate)))
+	} else {
+		sb.WriteString(b.t(ctx, "stats.no_shows_untracked"))
+	}
+	sb.WriteString("\n")
+	sb.WriteString(b.t(ctx, "stats.repeat_clients", r.RepeatClients, r.Clients, percent(r.RepeatShare)))
+	sb.WriteString("\n")
+	sb.WriteString(b.t(ctx, "stats.lead_time", strconv.FormatFloat(r.MedianLeadDays, 'f', -1, 64)))
+	sb.WriteString("\n")
+	if r.BusiestBookings > 0 {
+		weekday := r.BusiestWeekday.String()
+		if names := strings.Fields(b.t(ctx, "calendar.weekdays")); len(names) == 7 {
+			weekday = names[(int(r.BusiestWeekday)+6)%7]
+		}
+		sb.WriteString(b.t(ctx, "stats.busiest_weekday", weekday, r.BusiestBookings))
+		sb.WriteString("\n")
+	}
+	return sb.String()
+}
+
+// analyticsKeyboard - переключение периода и выгрузка отчета за текущий период
+func (b *Bot) analyticsKeyboard(ctx context.Context, r *analytics.Report, withUsersExport bool) tgbotapi.InlineKeyboardMarkup {
+	periods := make([]tgbotapi.InlineKeyboardButton, 0, len(statsPeriodButtons))
+	for _, days := range statsPeriodButtons {
+		periods = append(periods, tgbotapi.NewInlineKeyboardButtonData(
+			b.t(ctx, "stats.period_days", days), statsPeriodPrefix+strconv.Itoa(days)))
+	}
+	rows := [][]tgbotapi.InlineKeyboardButton{
+		periods,
+		tgbotapi.NewInlineKeyboardRow(tgbotapi.NewInlineKeyboardButtonData(b.t(ctx, "stats.xlsx"),
+			statsXLSXPrefix+r.Start.Format(statsCallbackLayout)+":"+r.End.Format(statsCallbackLayout))),
+	}
+	if withUsersExport {
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(b.t(ctx, "stats.export_users"), "export_users"),
+		))
+	}
+	return tgbotapi.NewInlineKeyboardMarkup(rows...)
+}
+
+// handleStatsCallback переключает период аналитики и выгружает отчет в XLSX
+func (b *Bot) handleStatsCallback(ctx context.Context, update *tgbotapi.Update, data string) {
+	chatID := update.CallbackQuery.Message.Chat.ID
+
+	if strings.HasPrefix(data, statsPeriodPrefix) {
+		days, err := strconv.Atoi(strings.TrimPrefix(data, statsPeriodPrefix))
+		if err != nil {
+			return
+		}
+		start, end := analytics.Period(time.Now(), days)
+		report, err := b.loadAnalytics(ctx, start, end)
+		if err != nil {
+			b.sendMessage(chatID, b.t(ctx, "error.default"))
+			return
+		}
+		msg := tgbotapi.NewMessage(chatID, b.analyticsText(ctx, report))
+		msg.ParseMode = models.ParseModeMarkdown
+		msg.ReplyMarkup = b.analyticsKeyboard(ctx, report, false)
+		if _, err := b.tgService.Send(msg); err != nil {
+			b.logger.Error().Err(err).Msg("Failed to send analytics")
+		}
+		return
+	}
+
+	parts := strings.Split(strings.TrimPrefix(data, statsXLSXPrefix), ":")
+	if len(parts) != 2 {
+		return
+	}
+	start, errStart := time.Parse(statsCallbackLayout, parts[0])
+	end, errEnd := time.Parse(statsCallbackLayout, parts[1])
+	if errStart != nil || errEnd != nil {
+		return
+	}
+	report, err := b.loadAnalytics(ctx, start, end)
+	if err != nil {
+		b.sendMessage(chatID, b.t(ctx, "error.default"))
+		return
+	}
+	file, err := analytics.RenderXLSX(report)
+	if err != nil {
+		b.logger.Error().Err(err).Msg("Error rendering analytics report")
+		b.sendMessage(chatID, b.t(ctx, "error.default"))
+		return
+	}
+
+	doc := tgbotapi.NewDocument(chatID, tgbotapi.FileBytes{Name: analytics.Filename(report), Bytes: file})
+	doc.Caption = b.t(ctx, "stats.report_caption", report.Start.Format("02.01.2006"), report.End.Format("02.01.2006"))
+	if _, err := b.tgService.Send(doc); err != nil {
+		b.logger.Error().Err(err).Msg("Error sending analytics report")
+	}
+}
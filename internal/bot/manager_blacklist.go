@@ -0,0 +1,239 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"bronivik/internal/models"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// handleManagerBlacklistCommands обрабатывает команды черного списка
+func (b *Bot) handleManagerBlacklistCommands(ctx context.Context, update *tgbotapi.Update, text string) bool {
+	var handler func(context.Context, *tgbotapi.Update)
+
+	switch {
+	case text == "/blacklist":
+		handler = b.handleListBlacklistCommand
+	case strings.HasPrefix(text, "/block"):
+		handler = b.handleBlockCommand
+	case strings.HasPrefix(text, "/unblock"):
+		handler = b.handleUnblockCommand
+	default:
+		return false
+	}
+
+	if !b.denyWithoutPermission(ctx, update.Message.Chat.ID, update.Message.From.ID, models.PermManageBlacklist) {
+		handler(ctx, update)
+	}
+	return true
+}
+
+// handleListBlacklistCommand показывает действующие блокировки
+func (b *Bot) handleListBlacklistCommand(ctx context.Context, update *tgbotapi.Update) {
+	chatID := update.Message.Chat.ID
+	users, err := b.userService.ListBlacklistedUsers(ctx)
+	if err != nil {
+		b.logger.Error().Err(err).Msg("Error listing blacklist")
+		b.sendMessage(chatID, b.t(ctx, "error.blacklist"))
+		return
+	}
+
+	var sb strings.Builder
+	sb.WriteString(b.t(ctx, "blacklist.title") + "\n\n")
+	for _, id := range b.config.Blacklist {
+		sb.WriteString(b.t(ctx, "blacklist.from_config", id) + "\n")
+	}
+	for _, u := range users {
+		name := strings.TrimSpace(u.FirstName + " " + u.LastName)
+		if name == "" {
+			name = "—"
+		}
+		sb.WriteString(b.t(ctx, "blacklist.entry", u.TelegramID, name, b.blacklistTerm(ctx, u)) + "\n")
+		if u.BlacklistReason != "" {
+			sb.WriteString("   " + b.t(ctx, "blacklist.notice_reason", u.BlacklistReason) + "\n")
+		}
+	}
+	if len(users) == 0 && len(b.config.Blacklist) == 0 {
+		sb.WriteString(b.t(ctx, "blacklist.empty") + "\n")
+	}
+
+	sb.WriteString("\n" + b.t(ctx, "blacklist.help"))
+	b.sendMessage(chatID, sb.String())
+}
+
+// handleBlockCommand блокирует пользователя: /block <telegram_id> [ДД.ММ.ГГГГ] <причина>
+func (b *Bot) handleBlockCommand(ctx context.Context, update *tgbotapi.Update) {
+	chatID := update.Message.Chat.ID
+	managerID := update.Message.From.ID
+	parts := strings.Fields(update.Message.Text)
+	if len(parts) < 2 {
+		b.sendMessage(chatID, b.t(ctx, "blacklist.block_usage"))
+		return
+	}
+
+	telegramID, err := strconv.ParseInt(parts[1], 10, 64)
+	if err != nil || telegramID <= 0 {
+		b.sendMessage(chatID, b.t(ctx, "error.invalid_telegram_id"))
+		return
+	}
+
+	var until time.Time
+	rest := parts[2:]
+	if len(rest) > 0 {
+		if date, errParse := time.ParseInLocation("02.01.2006", rest[0], time.Local); errParse == nil {
+			// Блокировка действует до конца указанного дня
+			until = date.AddDate(0, 0, 1)
+			if !until.After(time.Now()) {
+				b.sendMessage(chatID, b.t(ctx, "blacklist.until_past"))
+				return
+			}
+			rest = rest[1:]
+		}
+	}
+	reason := strings.Join(rest, " ")
+
+	if err := b.userService.BlockUser(ctx, telegramID, reason, until, managerID); err != nil {
+		b.sendMessage(chatID, b.t(ctx, "blacklist.block_failed", b.getErrorMessage(ctx, err)))
+		return
+	}
+
+	b.logger.Info().
+		Int64("manager_id", managerID).
+		Int64("user_id", telegramID).
+		Str("reason", reason).
+		Time("until", until).
+		Msg("User blacklisted")
+
+	info := &models.User{TelegramID: telegramID, IsBlacklisted: true, BlacklistReason: reason}
+	if !until.IsZero() {
+		info.BlacklistedUntil.Time, info.BlacklistedUntil.Valid = until, true
+	}
+	text := b.t(ctx, "blacklist.blocked", telegramID, b.blacklistTerm(ctx, info))
+
+	future := b.futureBookingsOf(ctx, telegramID)
+	if len(future) == 0 {
+		b.sendMessage(chatID, text)
+		return
+	}
+
+	text += "\n\n" + b.tn(ctx, "blacklist.future_bookings", len(future), len(future))
+	keyboard := tgbotapi.NewInlineKeyboardMarkup(tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData(
+			b.t(ctx, "blacklist.cancel_future", len(future)),
+			fmt.Sprintf("blacklist_cancel:%d", telegramID),
+		),
+	))
+	if _, err := b.tgService.SendWithInlineKeyboard(chatID, text, keyboard); err != nil {
+		b.logger.Error().Err(err).Msg("Failed to send block confirmation")
+	}
+}
+
+// handleUnblockCommand снимает блокировку: /unblock <telegram_id>
+func (b *Bot) handleUnblockCommand(ctx context.Context, update *tgbotapi.Update) {
+	chatID := update.Message.Chat.ID
+	parts := strings.Fields(update.Message.Text)
+	if len(parts) != 2 {
+		b.sendMessage(chatID, b.t(ctx, "blacklist.unblock_usage"))
+		return
+	}
+
+	telegramID, err := strconv.ParseInt(parts[1], 10, 64)
+	if err != nil || telegramID <= 0 {
+		b.sendMessage(chatID, b.t(ctx, "error.invalid_telegram_id"))
+		return
+	}
+
+	if err := b.userService.UnblockUser(ctx, telegramID); err != nil {
+		b.sendMessage(chatID, b.t(ctx, "blacklist.unblock_failed", b.getErrorMessage(ctx, err)))
+		return
+	}
+
+	b.logger.Info().Int64("manager_id", update.Message.From.ID).Int64("user_id", telegramID).Msg("User unblocked")
+	b.sendMessage(chatID, b.t(ctx, "blacklist.unblocked", telegramID))
+}
+
+// futureBookingsOf возвращает активные заявки пользователя начиная с сегодняшнего дня
+func (b *Bot) futureBookingsOf(ctx context.Context, userID int64) []*models.Booking {
+	bookings, err := b.userService.GetUserBookings(ctx, userID)
+	if err != nil {
+		b.logger.Error().Err(err).Int64("user_id", userID).Msg("Error getting user bookings")
+		return nil
+	}
+
+	today := time.Now().Truncate(24 * time.Hour)
+	var future []*models.Booking
+	for _, booking := range bookings {
+		if booking.Date.Before(today) {
+			continue
+		}
+		switch booking.Status {
+		case models.StatusPending, models.StatusConfirmed, models.StatusChanged:
+			future = append(future, booking)
+		}
+	}
+	return future
+}
+
+// cancelFutureBookingsOfBlocked отменяет будущие заявки заблокированного пользователя
+// в пределах аппаратов, доступных менеджеру
+func (b *Bot) cancelFutureBookingsOfBlocked(ctx context.Context, chatID, managerID, userID int64) {
+	if !b.isBlacklisted(userID) {
+		b.sendMessage(chatID, b.t(ctx, "blacklist.already_unblocked"))
+		return
+	}
+
+	var canceled []string
+	skipped := 0
+	for _, booking := range b.futureBookingsOf(ctx, userID) {
+		if !b.canManageItem(managerID, booking.ItemID) {
+			skipped++
+			continue
+		}
+		if err := b.bookingService.RejectBooking(ctx, booking.ID, booking.Version, managerID); err != nil {
+			b.logger.Error().Err(err).Int64("booking_id", booking.ID).Msg("Error canceling booking of blocked user")
+			skipped++
+			continue
+		}
+		canceled = append(canceled, fmt.Sprintf("#%d %s %s", booking.ID, booking.ItemName, booking.Date.Format("02.01.2006")))
+	}
+
+	if len(canceled) > 0 {
+		b.sendMessage(userID, b.t(b.withUserLanguage(ctx, userID), "blacklist.user_bookings_canceled")+"\n"+strings.Join(canceled, "\n"))
+	}
+
+	text := b.t(ctx, "blacklist.canceled", len(canceled))
+	if skipped > 0 {
+		text += "\n" + b.t(ctx, "blacklist.cancel_skipped", skipped)
+	}
+	b.sendMessage(chatID, text)
+}
+
+// denyIfBlacklisted сообщает заблокированному пользователю о блокировке и возвращает true
+func (b *Bot) denyIfBlacklisted(ctx context.Context, chatID, userID int64) bool {
+	info, blocked := b.userService.GetBlacklistInfo(userID)
+	if !blocked {
+		return false
+	}
+
+	text := b.t(ctx, "blacklist.notice", b.blacklistTerm(ctx, info))
+	if info.BlacklistReason != "" {
+		text += "\n" + b.t(ctx, "blacklist.notice_reason", info.BlacklistReason)
+	}
+	text += "\n" + b.t(ctx, "blacklist.notice_footer")
+	b.sendMessage(chatID, text)
+	return true
+}
+
+// blacklistTerm описывает срок блокировки пользователя
+func (b *Bot) blacklistTerm(ctx context.Context, u *models.User) string {
+	if !u.BlacklistedUntil.Valid {
+		return b.t(ctx, "blacklist.term_forever")
+	}
+	// Храним начало следующего дня, показываем последний день блокировки
+	return b.t(ctx, "blacklist.term_until", u.BlacklistedUntil.Time.Add(-time.Second).Format("02.01.2006"))
+}
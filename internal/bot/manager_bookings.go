@@ -14,48 +14,127 @@ import (
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
 )
 
+// managerBookingState - состояние создания заявки менеджером
+type managerBookingState struct {
+	ClientName  string      `json:"client_name"`
+	ClientPhone string      `json:"client_phone"`
+	ItemID      int64       `json:"item_id"`
+	DateType    string      `json:"date_type"`
+	StartDate   time.Time   `json:"start_date"`
+	Dates       []time.Time `json:"dates"`
+	Comment     string      `json:"comment"`
+}
+
+type managerBookingContext = dialogContext[managerBookingState]
+
+// managerBookingDialog - создание заявок менеджером: клиент, аппарат, дата или интервал, комментарий, подтверждение
+var managerBookingDialog = &dialog[managerBookingState]{
+	name:     "manager_booking",
+	timeout:  2 * time.Hour,
+	canceled: "manager_booking.canceled",
+	allowed: func(b *Bot, userID int64) bool {
+		return b.hasPermission(userID, models.PermManageBookings)
+	},
+	steps: map[string]*dialogStep[managerBookingState]{
+		models.StateManagerWaitingClientName: {
+			prompt: func(c *managerBookingContext) {
+				c.send(c.bot.t(c.ctx, "manager_booking.start"), c.navigationKeyboard())
+			},
+			input: inputManagerClientName,
+		},
+		models.StateManagerWaitingClientPhone: {
+			prompt: func(c *managerBookingContext) {
+				c.send(c.bot.t(c.ctx, "manager_booking.enter_phone"), c.navigationKeyboard())
+			},
+			input: inputManagerClientPhone,
+			back:  backTo[managerBookingState](models.StateManagerWaitingClientName),
+		},
+		models.StateManagerWaitingItemSelection: {
+			prompt: func(c *managerBookingContext) {
+				c.bot.sendManagerItemsPage(c.ctx, c.chatID, 0, 0)
+			},
+			input: inputManagerItem,
+			back:  backTo[managerBookingState](models.StateManagerWaitingClientPhone),
+		},
+		models.StateManagerWaitingDateType: {
+			prompt: promptManagerDateType,
+			input:  inputManagerDateType,
+			back:   backTo[managerBookingState](models.StateManagerWaitingItemSelection),
+		},
+		models.StateManagerWaitingSingleDate: {
+			prompt: func(c *managerBookingContext) {
+				c.send(c.bot.t(c.ctx, "manager_booking.enter_date"), c.calendar())
+			},
+			input: inputManagerSingleDate,
+			back:  backTo[managerBookingState](models.StateManagerWaitingDateType),
+		},
+		models.StateManagerWaitingStartDate: {
+			prompt: func(c *managerBookingContext) {
+				c.send(c.bot.t(c.ctx, "manager_booking.enter_start_date"), c.calendar())
+			},
+			input: inputManagerStartDate,
+			back:  backTo[managerBookingState](models.StateManagerWaitingDateType),
+		},
+		models.StateManagerWaitingEndDate: {
+			prompt: func(c *managerBookingContext) {
+				c.send(c.bot.t(c.ctx, "manager_booking.enter_end_date", c.state.StartDate.Format("02.01.2006")), c.calendar())
+			},
+			input: inputManagerEndDate,
+			back:  backTo[managerBookingState](models.StateManagerWaitingStartDate),
+		},
+		models.StateManagerWaitingComment: {
+			prompt: promptManagerComment,
+			input: func(c *managerBookingContext, text string) (string, error) {
+				c.state.Comment = c.bot.sanitizeInput(text)
+				return models.StateManagerConfirmBooking, nil
+			},
+			back: func(s *managerBookingState) string {
+				if s.DateType == typeSingle {
+					return models.StateManagerWaitingSingleDate
+				}
+				return models.StateManagerWaitingStartDate
+			},
+		},
+		models.StateManagerConfirmBooking: {
+			prompt: promptManagerConfirmation,
+			input: func(c *managerBookingContext, text string) (string, error) {
+				if !c.bot.isButton(text, btnConfirmCreate) {
+					return "", errUseButtons
+				}
+				c.bot.createManagerBookings(c.ctx, c.update, c.state)
+				return dialogDone, nil
+			},
+			back: backTo[managerBookingState](models.StateManagerWaitingComment),
+		},
+	},
+}
+
 // startManagerBooking начало создания заявки менеджером
 func (b *Bot) startManagerBooking(ctx context.Context, update *tgbotapi.Update) {
 	if !b.isManager(update.Message.From.ID) {
 		return
 	}
 
-	msg := tgbotapi.NewMessage(update.Message.Chat.ID,
-		"📋 Создание заявки от имени клиента\n\nВведите Имя клиента:")
-
-	b.setUserState(ctx, update.Message.From.ID, models.StateManagerWaitingClientName, map[string]interface{}{
-		"is_manager_booking": true,
-	})
-	if _, err := b.tgService.Send(msg); err != nil {
-		b.logger.Error().Err(err).Msg("Failed to send message in startManagerBooking")
-	}
+	managerBookingDialog.start(ctx, b, update, models.StateManagerWaitingClientName, managerBookingState{})
 }
 
-// handleManagerClientName обработка ввода имени клиента
-func (b *Bot) handleManagerClientName(ctx context.Context, update *tgbotapi.Update, text string, state *models.UserState) {
-	state.TempData["client_name"] = b.sanitizeInput(text)
-	b.setUserState(ctx, update.Message.From.ID, models.StateManagerWaitingClientPhone, state.TempData)
-
-	msg := tgbotapi.NewMessage(update.Message.Chat.ID, "📱 Введите телефон клиента:")
-	if _, err := b.tgService.Send(msg); err != nil {
-		b.logger.Error().Err(err).Msg("Failed to send message in handleManagerClientName")
+func inputManagerClientName(c *managerBookingContext, text string) (string, error) {
+	name := c.bot.sanitizeInput(text)
+	if name == "" {
+		return "", dialogError("manager_booking.start")
 	}
+	c.state.ClientName = name
+	return models.StateManagerWaitingClientPhone, nil
 }
 
-// handleManagerClientPhone обработка ввода телефона клиента
-func (b *Bot) handleManagerClientPhone(ctx context.Context, update *tgbotapi.Update, text string, state *models.UserState) {
+func inputManagerClientPhone(c *managerBookingContext, text string) (string, error) {
 	// Нормализуем телефон
-	normalizedPhone := b.normalizePhone(text)
-	if normalizedPhone == "" {
-		b.sendMessage(update.Message.Chat.ID, "Неверный формат номера телефона. Пожалуйста, введите номер в формате +7XXXXXXXXXX или 8XXXXXXXXXX")
-		return
+	phone := c.bot.normalizePhone(text)
+	if phone == "" {
+		return "", dialogError("error.invalid_phone")
 	}
-
-	state.TempData["client_phone"] = normalizedPhone
-	b.setUserState(ctx, update.Message.From.ID, models.StateManagerWaitingItemSelection, state.TempData)
-
-	// Показываем выбор аппарата с пагинацией
-	b.sendManagerItemsPage(ctx, update.Message.Chat.ID, 0, 0)
+	c.state.ClientPhone = phone
+	return models.StateManagerWaitingItemSelection, nil
 }
 
 // sendManagerItemsPage отправляет страницу с аппаратами для менеджера
@@ -65,7 +144,7 @@ func (b *Bot) sendManagerItemsPage(ctx context.Context, chatID int64, messageID,
 		ChatID:       chatID,
 		MessageID:    messageID,
 		Page:         page,
-		Title:        "🏢 *Выберите аппарат:*",
+		Title:        b.t(ctx, "items.select_title"),
 		ItemPrefix:   "manager_select_item:",
 		PagePrefix:   "manager_items_page:",
 		BackCallback: "",
@@ -73,159 +152,127 @@ func (b *Bot) sendManagerItemsPage(ctx context.Context, chatID int64, messageID,
 	})
 }
 
-// handleManagerItemSelection обработка выбора аппарата менеджером
+// handleManagerItemSelection передает диалогу аппарат, выбранный в списке
 func (b *Bot) handleManagerItemSelection(ctx context.Context, update *tgbotapi.Update) {
 	callback := update.CallbackQuery
-	data := callback.Data
-
-	itemIDStr := strings.TrimPrefix(data, "manager_select_item:")
-	itemID, err := strconv.ParseInt(itemIDStr, 10, 64)
-	if err != nil {
-		b.logger.Error().Err(err).Str("item_id_str", itemIDStr).Msg("Error parsing item ID")
+	state := b.getUserState(ctx, callback.From.ID)
+	if state == nil || state.CurrentStep != models.StateManagerWaitingItemSelection {
+		b.sendMessage(callback.Message.Chat.ID, b.t(ctx, "dialog.stale"))
 		return
 	}
 
-	selectedItem, ok := b.getItemByID(itemID)
+	// Список аппаратов остается: его можно листать, а повторный выбор отклоняется как устаревший
+	b.handleDialogCallback(ctx, update, state, strings.TrimPrefix(callback.Data, "manager_select_item:"), "")
+}
 
-	if !ok {
-		b.sendMessage(callback.Message.Chat.ID, "Аппарат не найден")
-		return
+func inputManagerItem(c *managerBookingContext, text string) (string, error) {
+	itemID, err := strconv.ParseInt(text, 10, 64)
+	if err != nil {
+		return "", errUseButtons
 	}
 
-	state := b.getUserState(ctx, callback.From.ID)
-	if state == nil {
-		b.sendMessage(callback.Message.Chat.ID, "Сессия устарела. Начните заново.")
-		return
+	item, ok := c.bot.getItemByID(itemID)
+	if !ok {
+		return "", dialogError("error.item_not_found")
+	}
+	if !c.bot.canManageItem(c.userID, item.ID) {
+		return "", dialogError(msgAccessDenied)
 	}
 
-	state.TempData["item_id"] = selectedItem.ID
-	b.setUserState(ctx, callback.From.ID, models.StateManagerWaitingDateType, state.TempData)
-
-	// Спрашиваем тип даты (одна дата или интервал)
-	msg := tgbotapi.NewMessage(callback.Message.Chat.ID, "📅 Выберите тип бронирования:")
+	c.state.ItemID = item.ID
+	return models.StateManagerWaitingDateType, nil
+}
 
+// promptManagerDateType спрашивает тип даты: одна дата или интервал
+func promptManagerDateType(c *managerBookingContext) {
 	keyboard := tgbotapi.NewInlineKeyboardMarkup(
 		tgbotapi.NewInlineKeyboardRow(
-			tgbotapi.NewInlineKeyboardButtonData("📅 Одна дата", "manager_single_date"),
-			tgbotapi.NewInlineKeyboardButtonData("📆 Интервал дат", "manager_date_range"),
+			tgbotapi.NewInlineKeyboardButtonData(c.bot.t(c.ctx, "btn.single_date"), "manager_single_date"),
+			tgbotapi.NewInlineKeyboardButtonData(c.bot.t(c.ctx, "btn.date_range"), "manager_date_range"),
 		),
 	)
-	msg.ReplyMarkup = &keyboard
-
-	if _, err := b.tgService.Send(msg); err != nil {
-		b.logger.Error().Err(err).Msg("Failed to send message in handleManagerItemSelection")
-	}
-	if _, err := b.tgService.Send(tgbotapi.NewCallback(callback.ID, "")); err != nil {
-		b.logger.Error().Err(err).Msg("Failed to send callback in handleManagerItemSelection")
-	}
+	c.send(c.bot.t(c.ctx, "manager_booking.date_type"), keyboard)
 }
 
-// handleManagerDateType обработка выбора типа даты
+// handleManagerDateType передает диалогу выбранный тип даты
 func (b *Bot) handleManagerDateType(ctx context.Context, update *tgbotapi.Update, dateType string) {
 	callback := update.CallbackQuery
 	state := b.getUserState(ctx, callback.From.ID)
-	if state == nil {
+	if state == nil || state.CurrentStep != models.StateManagerWaitingDateType {
+		b.sendMessage(callback.Message.Chat.ID, b.t(ctx, "dialog.stale"))
 		return
 	}
 
+	label := b.t(ctx, "btn.date_range")
 	if dateType == typeSingle {
-		state.TempData["date_type"] = typeSingle
-		b.setUserState(ctx, callback.From.ID, models.StateManagerWaitingSingleDate, state.TempData)
-
-		editMsg := tgbotapi.NewEditMessageText(
-			callback.Message.Chat.ID,
-			callback.Message.MessageID,
-			"📅 Введите дату бронирования в формате ДД.ММ.ГГГГ (например, 25.12.2024):",
-		)
-		if _, err := b.tgService.Send(editMsg); err != nil {
-			b.logger.Error().Err(err).Msg("Failed to send edit message in handleManagerDateType")
-		}
-	} else {
-		state.TempData["date_type"] = "range"
-		b.setUserState(ctx, callback.From.ID, models.StateManagerWaitingStartDate, state.TempData)
-
-		editMsg := tgbotapi.NewEditMessageText(
-			callback.Message.Chat.ID,
-			callback.Message.MessageID,
-			"📅 Введите начальную дату интервала в формате ДД.ММ.ГГГГ (например, 25.12.2024):",
-		)
-		if _, err := b.tgService.Send(editMsg); err != nil {
-			b.logger.Error().Err(err).Msg("Failed to send edit message in handleManagerDateType")
-		}
+		label = b.t(ctx, "btn.single_date")
 	}
+	b.handleDialogCallback(ctx, update, state, dateType, label)
+}
 
-	if _, err := b.tgService.Send(tgbotapi.NewCallback(callback.ID, "")); err != nil {
-		b.logger.Error().Err(err).Msg("Failed to send callback in handleManagerDateType")
+func inputManagerDateType(c *managerBookingContext, text string) (string, error) {
+	switch text {
+	case typeSingle:
+		c.state.DateType = typeSingle
+		return models.StateManagerWaitingSingleDate, nil
+	case typeRange:
+		c.state.DateType = typeRange
+		return models.StateManagerWaitingStartDate, nil
 	}
+	return "", errUseButtons
 }
 
-// handleManagerSingleDate обработка ввода одной даты
-func (b *Bot) handleManagerSingleDate(ctx context.Context, update *tgbotapi.Update, dateStr string, state *models.UserState) {
-	date, err := time.Parse("02.01.2006", dateStr)
+// parseManagerDate разбирает дату и проверяет ее через сервис
+func parseManagerDate(c *managerBookingContext, text string) (time.Time, error) {
+	date, err := time.Parse("02.01.2006", text)
 	if err != nil {
-		b.sendMessage(update.Message.Chat.ID, "Неверный формат даты. Используйте ДД.ММ.ГГГГ (например, 25.12.2024)")
-		return
+		return time.Time{}, dialogError("error.invalid_date")
 	}
-
-	// Валидация даты через сервис
-	if err := b.bookingService.ValidateBookingDate(date); err != nil {
-		b.sendMessage(update.Message.Chat.ID, b.getErrorMessage(err))
-		return
+	if errVal := c.bot.bookingService.ValidateBookingDate(date); errVal != nil {
+		return time.Time{}, errVal
 	}
-
-	state.TempData["dates"] = []time.Time{date}
-	b.setUserState(ctx, update.Message.From.ID, models.StateManagerWaitingComment, state.TempData)
-
-	b.sendMessage(update.Message.Chat.ID, "💬 Введите комментарий к заявке "+
-		"(например: 'Техническое обслуживание', 'Обучение персонала' или любой другой текст):")
+	return date, nil
 }
 
-// handleManagerStartDate обработка ввода начальной даты интервала
-func (b *Bot) handleManagerStartDate(ctx context.Context, update *tgbotapi.Update, dateStr string, state *models.UserState) {
-	startDate, err := time.Parse("02.01.2006", dateStr)
+func inputManagerSingleDate(c *managerBookingContext, text string) (string, error) {
+	date, err := parseManagerDate(c, text)
 	if err != nil {
-		b.sendMessage(update.Message.Chat.ID, "Неверный формат даты. Используйте ДД.ММ.ГГГГ (например, 25.12.2024)")
-		return
+		return "", err
 	}
+	c.state.Dates = []time.Time{date}
+	return models.StateManagerWaitingComment, nil
+}
 
-	// Валидация даты через сервис
-	if err := b.bookingService.ValidateBookingDate(startDate); err != nil {
-		b.sendMessage(update.Message.Chat.ID, b.getErrorMessage(err))
-		return
+func inputManagerStartDate(c *managerBookingContext, text string) (string, error) {
+	date, err := parseManagerDate(c, text)
+	if err != nil {
+		return "", err
 	}
-
-	state.TempData["start_date"] = startDate
-	b.setUserState(ctx, update.Message.From.ID, models.StateManagerWaitingEndDate, state.TempData)
-
-	b.sendMessage(update.Message.Chat.ID, "📅 Введите конечную дату интервала в формате ДД.ММ.ГГГГ:")
+	c.state.StartDate = date
+	return models.StateManagerWaitingEndDate, nil
 }
 
-// handleManagerEndDate обработка ввода конечной даты интервала
-func (b *Bot) handleManagerEndDate(ctx context.Context, update *tgbotapi.Update, dateStr string, state *models.UserState) {
-	endDate, err := time.Parse("02.01.2006", dateStr)
+func inputManagerEndDate(c *managerBookingContext, text string) (string, error) {
+	endDate, err := time.Parse("02.01.2006", text)
 	if err != nil {
-		b.sendMessage(update.Message.Chat.ID, "Неверный формат даты. Используйте ДД.ММ.ГГГГ (например, 25.12.2024)")
-		return
+		return "", dialogError("error.invalid_date")
 	}
 
-	startDate := state.GetTime("start_date")
+	startDate := c.state.StartDate
 
 	// Проверяем, что конечная дата не раньше начальной
 	if endDate.Before(startDate) {
-		b.sendMessage(update.Message.Chat.ID, "Конечная дата не может быть раньше начальной.")
-		return
+		return "", dialogError("manager_booking.end_before_start")
 	}
 
 	// Валидация даты через сервис
-	if err := b.bookingService.ValidateBookingDate(endDate); err != nil {
-		b.sendMessage(update.Message.Chat.ID, b.getErrorMessage(err))
-		return
+	if errVal := c.bot.bookingService.ValidateBookingDate(endDate); errVal != nil {
+		return "", errVal
 	}
 
 	// Ограничиваем интервал (например, максимум 31 день за раз)
-	if endDate.Sub(startDate).Hours() > 24*31 {
-		b.sendMessage(update.Message.Chat.ID, "Максимальный интервал бронирования - 31 день.")
-		return
+	if endDate.Sub(startDate).Hours() > 24*maxRangeDays {
+		return "", dialogError("manager_booking.range_too_long")
 	}
 
 	// Создаем список всех дат в интервале
@@ -234,78 +281,66 @@ func (b *Bot) handleManagerEndDate(ctx context.Context, update *tgbotapi.Update,
 		dates = append(dates, d)
 	}
 
-	state.TempData["dates"] = dates
-	b.setUserState(ctx, update.Message.From.ID, models.StateManagerWaitingComment, state.TempData)
-
-	b.sendMessage(update.Message.Chat.ID, fmt.Sprintf("💬 Введите комментарий к заявке (будет применен ко всем %d дням):", len(dates)))
+	c.state.Dates = dates
+	return models.StateManagerWaitingComment, nil
 }
 
-// handleManagerComment обработка ввода комментария
-func (b *Bot) handleManagerComment(ctx context.Context, update *tgbotapi.Update, comment string, state *models.UserState) {
-	state.TempData["comment"] = b.sanitizeInput(comment)
-	b.setUserState(ctx, update.Message.From.ID, models.StateManagerConfirmBooking, state.TempData)
-
-	// Показываем подтверждение
-	b.showManagerBookingConfirmation(ctx, update, state)
+func promptManagerComment(c *managerBookingContext) {
+	if c.state.DateType == typeSingle {
+		c.send(c.bot.t(c.ctx, "manager_booking.enter_comment"), c.navigationKeyboard())
+		return
+	}
+	c.send(c.bot.tn(c.ctx, "manager_booking.enter_range_comment", len(c.state.Dates)), c.navigationKeyboard())
 }
 
-// showManagerBookingConfirmation показывает подтверждение заявки менеджером
-func (b *Bot) showManagerBookingConfirmation(_ context.Context, update *tgbotapi.Update, state *models.UserState) {
-	clientName := state.TempData["client_name"].(string)
-	clientPhone := state.TempData["client_phone"].(string)
-	itemID := state.GetInt64("item_id")
-	selectedItem, _ := b.getItemByID(itemID)
-	dates := state.GetDates("dates")
-	comment := state.TempData["comment"].(string)
-	dateType := state.TempData["date_type"].(string)
+// promptManagerConfirmation показывает подтверждение заявки менеджером
+func promptManagerConfirmation(c *managerBookingContext) {
+	ctx, b, s := c.ctx, c.bot, c.state
+	selectedItem, _ := b.getItemByID(s.ItemID)
 
 	var message strings.Builder
-	message.WriteString("📋 *Подтверждение заявки:*\n\n")
-	message.WriteString(fmt.Sprintf("👤 *Клиент:* %s\n", clientName))
-	message.WriteString(fmt.Sprintf("📱 *Телефон:* %s\n", clientPhone))
-	message.WriteString(fmt.Sprintf("🏢 *Аппарат:* %s\n", selectedItem.Name))
+	message.WriteString(b.t(ctx, "manager_booking.confirm_title") + "\n\n")
+	message.WriteString(b.t(ctx, "manager_booking.client", s.ClientName) + "\n")
+	message.WriteString(b.t(ctx, "manager_booking.phone", s.ClientPhone) + "\n")
+	message.WriteString(b.t(ctx, "manager_booking.item", selectedItem.Name) + "\n")
 
-	if dateType == typeSingle {
-		message.WriteString(fmt.Sprintf("📅 *Дата:* %s\n", dates[0].Format("02.01.2006")))
-	} else {
-		message.WriteString(fmt.Sprintf("📅 *Интервал:* %s - %s (%d дней)\n",
-			dates[0].Format("02.01.2006"),
-			dates[len(dates)-1].Format("02.01.2006"),
-			len(dates)))
+	if len(s.Dates) == 1 {
+		message.WriteString(b.t(ctx, "manager_booking.date", s.Dates[0].Format("02.01.2006")) + "\n")
+	} else if len(s.Dates) > 1 {
+		message.WriteString(b.tn(ctx, "manager_booking.range", len(s.Dates),
+			s.Dates[0].Format("02.01.2006"),
+			s.Dates[len(s.Dates)-1].Format("02.01.2006"),
+			len(s.Dates)) + "\n")
 	}
 
-	message.WriteString(fmt.Sprintf("💬 *Комментарий:* %s\n\n", comment))
+	message.WriteString(b.t(ctx, "manager_booking.comment", s.Comment) + "\n\n")
 
-	msg := tgbotapi.NewMessage(update.Message.Chat.ID, message.String())
-
-	keyboard := tgbotapi.NewReplyKeyboard(
+	msg := tgbotapi.NewMessage(c.chatID, message.String())
+	msg.ReplyMarkup = tgbotapi.NewReplyKeyboard(
+		tgbotapi.NewKeyboardButtonRow(
+			tgbotapi.NewKeyboardButton(b.t(ctx, btnConfirmCreate)),
+			tgbotapi.NewKeyboardButton(b.t(ctx, btnCancel)),
+		),
 		tgbotapi.NewKeyboardButtonRow(
-			tgbotapi.NewKeyboardButton("✅ Подтвердить создание"),
-			tgbotapi.NewKeyboardButton("❌ Отмена"),
+			tgbotapi.NewKeyboardButton(b.t(ctx, btnBack)),
 		),
 	)
-	msg.ReplyMarkup = keyboard
 	msg.ParseMode = models.ParseModeMarkdown
 
 	if _, err := b.tgService.Send(msg); err != nil {
-		b.logger.Error().Err(err).Msg("Failed to send message in showManagerBookingConfirmation")
+		b.logger.Error().Err(err).Msg("Failed to send message in promptManagerConfirmation")
 	}
 }
 
 // createManagerBookings создает заявки менеджера
-func (b *Bot) createManagerBookings(ctx context.Context, update *tgbotapi.Update, state *models.UserState) {
-	clientName := state.TempData["client_name"].(string)
-	clientPhone := state.TempData["client_phone"].(string)
-	itemID := state.GetInt64("item_id")
-	selectedItem, _ := b.getItemByID(itemID)
-	dates := state.GetDates("dates")
-	comment := state.TempData["comment"].(string)
-
-	createdBookings := make([]*models.Booking, 0, len(dates))
+func (b *Bot) createManagerBookings(ctx context.Context, update *tgbotapi.Update, state *managerBookingState) {
+	selectedItem, _ := b.getItemByID(state.ItemID)
+
+	createdBookings := make([]*models.Booking, 0, len(state.Dates))
 	failedDates := make([]string, 0)
 
 	// Создаем заявки на каждую дату
-	for _, date := range dates {
+	for _, date := range state.Dates {
 		// Проверяем доступность
 		available, err := b.bookingService.CheckAvailability(ctx, selectedItem.ID, date)
 		if err != nil {
@@ -322,14 +357,14 @@ func (b *Bot) createManagerBookings(ctx context.Context, update *tgbotapi.Update
 		// Создаем бронирование
 		booking := &models.Booking{
 			UserID:       update.Message.From.ID, // ID менеджера
-			UserName:     clientName,
-			UserNickname: clientName,
-			Phone:        clientPhone,
+			UserName:     state.ClientName,
+			UserNickname: state.ClientName,
+			Phone:        state.ClientPhone,
 			ItemID:       selectedItem.ID,
 			ItemName:     selectedItem.Name,
 			Date:         date,
 			Status:       models.StatusConfirmed, // Менеджер создает сразу подтвержденные заявки
-			Comment:      comment,
+			Comment:      state.Comment,
 			CreatedAt:    time.Now(),
 			UpdatedAt:    time.Now(),
 		}
@@ -338,7 +373,7 @@ func (b *Bot) createManagerBookings(ctx context.Context, update *tgbotapi.Update
 		err = b.bookingService.CreateBooking(ctx, booking)
 		if err != nil {
 			b.logger.Error().Err(err).Interface("booking", booking).Msg("Error creating manager booking")
-			failedDates = append(failedDates, fmt.Sprintf("%s (%s)", date.Format("02.01.2006"), b.getErrorMessage(err)))
+			failedDates = append(failedDates, fmt.Sprintf("%s (%s)", date.Format("02.01.2006"), b.getErrorMessage(ctx, err)))
 		} else {
 			createdBookings = append(createdBookings, booking)
 			// Track metrics
@@ -351,20 +386,36 @@ func (b *Bot) createManagerBookings(ctx context.Context, update *tgbotapi.Update
 
 	// Формируем отчет
 	var message strings.Builder
-	message.WriteString("📊 *Результат создания заявок:*\n\n")
+	message.WriteString(b.t(ctx, "manager_booking.result_title") + "\n\n")
 
 	if len(createdBookings) > 0 {
-		message.WriteString(fmt.Sprintf("✅ *Успешно создано:* %d заявок\n", len(createdBookings)))
+		message.WriteString(b.tn(ctx, "manager_booking.result_created", len(createdBookings)) + "\n")
 		for _, booking := range createdBookings {
 			message.WriteString(fmt.Sprintf("   • %s (№%d)\n", booking.Date.Format("02.01.2006"), booking.ID))
 		}
 		message.WriteString("\n")
+
+		// Стоимость каждой аренды, в которую вошли созданные заявки
+		quoted := make(map[string]bool)
+		for _, booking := range createdBookings {
+			quote, err := b.bookingService.GetBookingQuote(ctx, booking)
+			if err != nil {
+				b.logger.Error().Err(err).Int64("booking_id", booking.ID).Msg("Error getting booking quote")
+				continue
+			}
+			key := quote.Start.Format("2006-01-02")
+			if !quote.IsPriced() || quoted[key] {
+				continue
+			}
+			quoted[key] = true
+			message.WriteString(strings.Join(b.rentalQuoteLines(ctx, quote), "\n") + "\n\n")
+		}
 	}
 
 	if len(failedDates) > 0 {
-		message.WriteString(fmt.Sprintf("❌ *Не удалось создать:* %d заявок\n", len(failedDates)))
+		message.WriteString(b.tn(ctx, "manager_booking.result_failed", len(failedDates)) + "\n")
 		for _, date := range failedDates {
-			message.WriteString(fmt.Sprintf("   • %s (недоступно)\n", date))
+			message.WriteString("   • " + b.t(ctx, "manager_booking.result_failed_date", date) + "\n")
 		}
 	}
 
@@ -384,7 +435,7 @@ func (b *Bot) createManagerBookings(ctx context.Context, update *tgbotapi.Update
 
 // showManagerBookings показывает все заявки менеджеру с пагинацией
 func (b *Bot) showManagerBookings(ctx context.Context, update *tgbotapi.Update) {
-	if !b.isManager(update.Message.From.ID) {
+	if !b.hasPermission(update.Message.From.ID, models.PermViewBookings) {
 		return
 	}
 
@@ -400,12 +451,15 @@ func (b *Bot) sendManagerBookingsPage(ctx context.Context, chatID int64, message
 	bookings, err := b.bookingService.GetBookingsByDateRange(ctx, startDate, endDate)
 	if err != nil {
 		b.logger.Error().Err(err).Time("start_date", startDate).Time("end_date", endDate).Msg("Error getting bookings")
-		b.sendMessage(chatID, "Ошибка при получении заявок")
+		b.sendMessage(chatID, b.t(ctx, "error.bookings_list"))
 		return
 	}
 
+	// Менеджер видит только заявки на аппараты из своей области
+	bookings = b.filterBookingsByScope(chatID, bookings)
+
 	if len(bookings) == 0 {
-		b.sendMessage(chatID, "Заявок не найдено")
+		b.sendMessage(chatID, b.t(ctx, "manager_bookings.empty"))
 		return
 	}
 
@@ -414,10 +468,11 @@ func (b *Bot) sendManagerBookingsPage(ctx context.Context, chatID int64, message
 		ChatID:       chatID,
 		MessageID:    messageID,
 		Page:         page,
-		Title:        "📊 *Все заявки на квартал вперед:*",
+		Title:        b.t(ctx, "manager_bookings.title"),
 		ItemPrefix:   "show_booking:",
 		PagePrefix:   "manager_bookings_page:",
 		BackCallback: "back_to_main",
+		ExtraRows:    b.bulkStartRows(ctx, chatID, bulkOriginList),
 	}, bookings)
 }
 
@@ -431,7 +486,11 @@ func (b *Bot) showManagerBookingDetail(ctx context.Context, update *tgbotapi.Upd
 
 	booking, err := b.bookingService.GetBooking(ctx, bookingID)
 	if err != nil {
-		b.sendMessage(update.Message.Chat.ID, "Заявка не найдена")
+		b.sendMessage(update.Message.Chat.ID, b.t(ctx, "error.booking_not_found"))
+		return
+	}
+
+	if b.denyWithoutItemAccess(ctx, update.Message.Chat.ID, update.Message.From.ID, booking.ItemID) {
 		return
 	}
 
@@ -440,18 +499,20 @@ func (b *Bot) showManagerBookingDetail(ctx context.Context, update *tgbotapi.Upd
 
 // startChangeItem начало изменения аппарата в заявке
 func (b *Bot) startChangeItem(ctx context.Context, booking *models.Booking, managerChatID int64) {
-	msg := tgbotapi.NewMessage(managerChatID,
-		"Выберите новый аппарат для заявки #"+strconv.FormatInt(booking.ID, 10)+":")
+	msg := tgbotapi.NewMessage(managerChatID, b.t(ctx, "manager_booking.choose_new_item", booking.ID))
 
 	items, err := b.itemService.GetActiveItems(ctx)
 	if err != nil {
 		b.logger.Error().Err(err).Msg("Error getting active items")
-		b.sendMessage(managerChatID, "Ошибка при получении списка аппаратов")
+		b.sendMessage(managerChatID, b.t(ctx, "error.items_list"))
 		return
 	}
 
 	keyboardRows := make([][]tgbotapi.InlineKeyboardButton, 0, len(items))
 	for _, item := range items {
+		if !b.canManageItem(managerChatID, item.ID) {
+			continue
+		}
 		row := tgbotapi.NewInlineKeyboardRow(
 			tgbotapi.NewInlineKeyboardButtonData(item.Name,
 				fmt.Sprintf("change_to_%d_%d", booking.ID, item.ID)),
@@ -485,7 +546,7 @@ func (b *Bot) handleChangeItem(ctx context.Context, update *tgbotapi.Update) {
 	selectedItem, ok := b.getItemByID(itemID)
 
 	if !ok {
-		b.sendMessage(callback.Message.Chat.ID, "Аппарат не найден")
+		b.sendMessage(callback.Message.Chat.ID, b.t(ctx, "error.item_not_found"))
 		return
 	}
 
@@ -493,7 +554,13 @@ func (b *Bot) handleChangeItem(ctx context.Context, update *tgbotapi.Update) {
 	booking, err := b.bookingService.GetBooking(ctx, bookingID)
 	if err != nil {
 		b.logger.Error().Err(err).Int64("booking_id", bookingID).Msg("Error getting booking")
-		b.sendMessage(callback.Message.Chat.ID, "Ошибка при получении заявки")
+		b.sendMessage(callback.Message.Chat.ID, b.t(ctx, "error.booking_load"))
+		return
+	}
+
+	// Менеджер должен иметь доступ и к текущему, и к новому аппарату
+	if !b.canManageItem(callback.From.ID, booking.ItemID) || !b.canManageItem(callback.From.ID, selectedItem.ID) {
+		b.sendMessage(callback.Message.Chat.ID, b.t(ctx, msgAccessDenied))
 		return
 	}
 
@@ -501,7 +568,7 @@ func (b *Bot) handleChangeItem(ctx context.Context, update *tgbotapi.Update) {
 	err = b.bookingService.ChangeBookingItem(ctx, bookingID, booking.Version, selectedItem.ID, callback.From.ID)
 	if err != nil {
 		b.logger.Error().Err(err).Int64("booking_id", bookingID).Msg("Error changing booking item")
-		b.sendMessage(callback.Message.Chat.ID, "Ошибка при изменении аппарата: "+b.getErrorMessage(err))
+		b.sendMessage(callback.Message.Chat.ID, b.t(ctx, "manager_booking.change_item_error", b.getErrorMessage(ctx, err)))
 		return
 	}
 
@@ -514,13 +581,13 @@ func (b *Bot) handleChangeItem(ctx context.Context, update *tgbotapi.Update) {
 		Msg("Manager changed booking item")
 
 	// Уведомляем пользователя
-	userMsg := tgbotapi.NewMessage(booking.UserID,
-		fmt.Sprintf("🔄 В вашей заявке #%d изменен аппарат на: %s", bookingID, selectedItem.Name))
+	userCtx := b.withUserLanguage(ctx, booking.UserID)
+	userMsg := tgbotapi.NewMessage(booking.UserID, b.t(userCtx, "notify.item_changed", bookingID, selectedItem.Name))
 	if _, errSend := b.tgService.Send(userMsg); errSend != nil {
 		b.logger.Error().Err(errSend).Msg("Failed to send user notification in handleChangeItem")
 	}
 
-	b.sendMessage(callback.Message.Chat.ID, "✅ Аппарат успешно изменен")
+	b.sendMessage(callback.Message.Chat.ID, b.t(ctx, "manager_booking.item_changed"))
 
 	// ВМЕСТО ВЫЗОВА showManagerBookingDetail, который требует Message, используем sendManagerBookingDetail
 	updatedBooking, err := b.bookingService.GetBooking(ctx, bookingID)
@@ -534,68 +601,70 @@ func (b *Bot) handleChangeItem(ctx context.Context, update *tgbotapi.Update) {
 }
 
 // sendManagerBookingDetail отправляет детали заявки в указанный чат (без использования update)
-func (b *Bot) sendManagerBookingDetail(_ context.Context, chatID int64, booking *models.Booking) {
-	statusText := map[string]string{
-		models.StatusPending:   "⏳ Ожидает подтверждения",
-		models.StatusConfirmed: "✅ Подтверждена",
-		models.StatusCanceled:  "❌ Отменена",
-		models.StatusChanged:   "🔄 Изменена",
-		models.StatusCompleted: "🏁 Завершена",
-	}
-
-	message := fmt.Sprintf(`📋 Заявка #%d
-
-👤 Клиент: %s
-📱 Телефон: %s
-🏢 Позиция: %s
-📅 Дата: %s
-📊 Статус: %s
-� Комментарий: %s
-🕐 Создана: %s
-✏️ Обновлена: %s`,
+func (b *Bot) sendManagerBookingDetail(ctx context.Context, chatID int64, booking *models.Booking) {
+	message := b.t(ctx, "manager_booking.detail",
 		booking.ID,
 		booking.UserName,
 		booking.Phone,
 		booking.ItemName,
 		booking.Date.Format("02.01.2006"),
-		statusText[booking.Status],
+		b.statusName(ctx, booking.Status),
 		booking.Comment,
 		booking.CreatedAt.Format("02.01.2006 15:04"),
 		booking.UpdatedAt.Format("02.01.2006 15:04"),
 	)
+	if line := b.bookingUnitLine(ctx, booking); line != "" {
+		message += "\n" + line
+	}
+	if lines := b.bookingPriceLines(ctx, booking); len(lines) > 0 {
+		message += "\n" + strings.Join(lines, "\n")
+	}
+
+	// Фактическая выдача и возврат аппарата
+	handover, err := b.bookingService.GetBookingHandover(ctx, booking)
+	if err != nil {
+		b.logger.Error().Err(err).Int64("booking_id", booking.ID).Msg("Error getting booking handover")
+	}
+	if handover != nil {
+		message += "\n\n" + strings.Join(b.handoverDetailLines(ctx, handover, time.Now()), "\n")
+	}
 
 	msg := tgbotapi.NewMessage(chatID, message)
 
 	// Создаем инлайн-клавиатуру для управления заявкой
-	rows := make([][]tgbotapi.InlineKeyboardButton, 0, 4)
+	rows := make([][]tgbotapi.InlineKeyboardButton, 0, 5)
 
 	if booking.Status == models.StatusPending || booking.Status == models.StatusChanged {
 		rows = append(rows, tgbotapi.NewInlineKeyboardRow(
-			tgbotapi.NewInlineKeyboardButtonData("✅ Подтвердить", fmt.Sprintf("confirm_%d", booking.ID)),
-			tgbotapi.NewInlineKeyboardButtonData("❌ Отклонить", fmt.Sprintf("reject_%d", booking.ID)),
+			tgbotapi.NewInlineKeyboardButtonData(b.t(ctx, "btn.confirm"), fmt.Sprintf("confirm_%d", booking.ID)),
+			tgbotapi.NewInlineKeyboardButtonData(b.t(ctx, "btn.reject"), fmt.Sprintf("reject_%d", booking.ID)),
 		))
 	}
 
 	if booking.Status == models.StatusConfirmed {
 		rows = append(rows,
 			tgbotapi.NewInlineKeyboardRow(
-				tgbotapi.NewInlineKeyboardButtonData("🔄 Вернуть в работу", fmt.Sprintf("reopen_%d", booking.ID)),
-				tgbotapi.NewInlineKeyboardButtonData("🏁 Завершить", fmt.Sprintf("complete_%d", booking.ID)),
+				tgbotapi.NewInlineKeyboardButtonData(b.t(ctx, "btn.reopen"), fmt.Sprintf("reopen_%d", booking.ID)),
+				tgbotapi.NewInlineKeyboardButtonData(b.t(ctx, "btn.complete"), fmt.Sprintf("complete_%d", booking.ID)),
 			),
 			tgbotapi.NewInlineKeyboardRow(
-				tgbotapi.NewInlineKeyboardButtonData("✏️ Изменить аппарат", fmt.Sprintf("change_item_%d", booking.ID)),
-				tgbotapi.NewInlineKeyboardButtonData("🔄 Предложить выбрать другую дату", fmt.Sprintf("reschedule_%d", booking.ID)),
+				tgbotapi.NewInlineKeyboardButtonData(b.t(ctx, "btn.change_item"), fmt.Sprintf("change_item_%d", booking.ID)),
+				tgbotapi.NewInlineKeyboardButtonData(b.t(ctx, "btn.reschedule"), fmt.Sprintf("reschedule_%d", booking.ID)),
 			),
 			tgbotapi.NewInlineKeyboardRow(
-				tgbotapi.NewInlineKeyboardButtonData("📞 Позвонить", fmt.Sprintf("call_booking:%d", booking.ID)),
+				tgbotapi.NewInlineKeyboardButtonData(b.t(ctx, "btn.call"), fmt.Sprintf("call_booking:%d", booking.ID)),
 			),
 		)
 	}
 
-	if len(rows) > 0 {
-		keyboard := tgbotapi.NewInlineKeyboardMarkup(rows...)
-		msg.ReplyMarkup = &keyboard
-	}
+	rows = append(rows, b.handoverButtons(ctx, booking, handover)...)
+
+	rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData(b.t(ctx, "btn.history"), fmt.Sprintf("booking_history:%d", booking.ID)),
+	))
+
+	keyboard := tgbotapi.NewInlineKeyboardMarkup(rows...)
+	msg.ReplyMarkup = &keyboard
 
 	if _, err := b.tgService.Send(msg); err != nil {
 		b.logger.Error().Err(err).Msg("Failed to send message in sendManagerBookingDetail")
@@ -612,27 +681,23 @@ func (b *Bot) updateBookingStatus(ctx context.Context, booking *models.Booking,
 	case "reopen":
 		logMsg = "Manager reopened booking"
 		err = b.bookingService.ReopenBooking(ctx, booking.ID, booking.Version, managerChatID)
-		userMsgText = fmt.Sprintf("🔄 Ваша заявка #%d возвращена в работу. Ожидайте подтверждения.", booking.ID)
-		managerMsgText = "✅ Заявка возвращена в работу"
+		managerMsgText = b.t(ctx, "manager_booking.reopened")
 	case "complete":
 		logMsg = "Manager completed booking"
 		err = b.bookingService.CompleteBooking(ctx, booking.ID, booking.Version, managerChatID)
-		userMsgText = fmt.Sprintf("🏁 Ваша заявка #%d завершена. Спасибо за использование наших услуг!", booking.ID)
-		managerMsgText = "✅ Заявка завершена"
+		managerMsgText = b.t(ctx, "manager_booking.completed")
 	case "confirm":
 		logMsg = "Manager confirmed booking"
 		err = b.bookingService.ConfirmBooking(ctx, booking.ID, booking.Version, managerChatID)
-		userMsgText = fmt.Sprintf("✅ Ваша заявка на %s %s подтверждена!",
-			booking.ItemName, booking.Date.Format("02.01.2006"))
-		managerMsgText = "✅ Бронирование подтверждено"
+		managerMsgText = b.t(ctx, "manager_booking.confirmed")
 	case "reject":
 		logMsg = "Manager rejected booking"
 		err = b.bookingService.RejectBooking(ctx, booking.ID, booking.Version, managerChatID)
-		userMsgText = "❌ К сожалению, ваша заявка была отклонена менеджером."
-		managerMsgText = "❌ Бронирование отменено"
+		managerMsgText = b.t(ctx, "manager_booking.rejected")
 	default:
 		return
 	}
+	userMsgText = b.statusNotice(b.withUserLanguage(ctx, booking.UserID), booking, action)
 
 	b.logger.Info().
 		Int64("booking_id", booking.ID).
@@ -643,7 +708,7 @@ func (b *Bot) updateBookingStatus(ctx context.Context, booking *models.Booking,
 
 	if err != nil {
 		if errors.Is(err, database.ErrConcurrentModification) {
-			b.sendMessage(managerChatID, "Заявка уже изменена. Обновите данные и попробуйте снова.")
+			b.sendMessage(managerChatID, b.t(ctx, "manager_booking.already_changed"))
 			return
 		}
 		b.logger.Error().Err(err).Int64("booking_id", booking.ID).Msg("Error updating booking status")
@@ -661,6 +726,21 @@ func (b *Bot) updateBookingStatus(ctx context.Context, booking *models.Booking,
 	}
 }
 
+// statusNotice возвращает уведомление клиенту о смене статуса заявки; ctx должен нести язык клиента
+func (b *Bot) statusNotice(ctx context.Context, booking *models.Booking, action string) string {
+	switch action {
+	case "reopen":
+		return b.t(ctx, "notify.reopened", booking.ID)
+	case models.BulkActionComplete:
+		return b.t(ctx, "notify.completed", booking.ID)
+	case models.BulkActionConfirm:
+		return b.t(ctx, "notify.confirmed", booking.ItemName, booking.Date.Format("02.01.2006"))
+	case models.BulkActionReject:
+		return b.t(ctx, "notify.rejected")
+	}
+	return ""
+}
+
 // reopenBooking возврат заявки в работу
 func (b *Bot) reopenBooking(ctx context.Context, booking *models.Booking, managerChatID int64) {
 	b.updateBookingStatus(ctx, booking, managerChatID, "reopen")
@@ -691,13 +771,12 @@ func (b *Bot) rescheduleBooking(ctx context.Context, booking *models.Booking, ma
 		Msg("Manager proposed reschedule")
 
 	// Отправляем пользователю сообщение с предложением выбрать другую дату
-	userMsg := tgbotapi.NewMessage(booking.UserID,
-		fmt.Sprintf("🔄 Менеджер предложил выбрать другую дату для %s. Пожалуйста, создайте новую заявку.",
-			booking.ItemName))
+	userCtx := b.withUserLanguage(ctx, booking.UserID)
+	userMsg := tgbotapi.NewMessage(booking.UserID, b.t(userCtx, "notify.reschedule", booking.ItemName))
 
 	keyboard := tgbotapi.NewReplyKeyboard(
 		tgbotapi.NewKeyboardButtonRow(
-			tgbotapi.NewKeyboardButton(btnCreateBooking),
+			tgbotapi.NewKeyboardButton(b.t(userCtx, btnCreateBooking)),
 		),
 	)
 	userMsg.ReplyMarkup = keyboard
@@ -712,51 +791,52 @@ func (b *Bot) rescheduleBooking(ctx context.Context, booking *models.Booking, ma
 		b.logger.Error().Err(err).Int64("booking_id", booking.ID).Msg("Error updating booking status")
 	}
 
-	managerMsg := tgbotapi.NewMessage(managerChatID, "🔄 Пользователю предложено выбрать другую дату")
+	managerMsg := tgbotapi.NewMessage(managerChatID, b.t(ctx, "manager_booking.reschedule_sent"))
 	if _, err := b.tgService.Send(managerMsg); err != nil {
 		b.logger.Error().Err(err).Msg("Failed to send manager msg in rescheduleBooking")
 	}
 }
 
 // notifyManagers уведомление менеджеров о новой заявке
-func (b *Bot) notifyManagers(booking *models.Booking) {
-	message := fmt.Sprintf(`🆕 Новая заявка на бронирование:
-
-🏢 Позиция: %s
-📅 Дата: %s
-👤 Клиент: %s
-📱 Телефон: %s
-💬 Комментарий: %s
-🆔 ID заявки: %d`,
+func (b *Bot) notifyManagers(ctx context.Context, booking *models.Booking) {
+	for _, managerID := range b.userService.GetStaffForItem(booking.ItemID, models.PermManageBookings) {
+		managerCtx := b.withUserLanguage(ctx, managerID)
+		msg := tgbotapi.NewMessage(managerID, b.newBookingText(managerCtx, booking))
+		keyboard := b.newBookingKeyboard(managerCtx, booking.ID)
+		msg.ReplyMarkup = &keyboard
+
+		if _, err := b.tgService.Send(msg); err != nil {
+			b.logger.Error().Err(err).Int64("manager_id", managerID).Msg("Failed to notify manager")
+		}
+	}
+}
+
+// newBookingText описывает новую заявку для менеджера
+func (b *Bot) newBookingText(ctx context.Context, booking *models.Booking) string {
+	return b.t(ctx, "notify.new_booking",
 		booking.ItemName,
 		booking.Date.Format("02.01.2006"),
 		booking.UserName,
 		booking.Phone,
 		booking.Comment,
 		booking.ID)
+}
 
-	for _, managerID := range b.config.Managers {
-		msg := tgbotapi.NewMessage(managerID, message)
-
-		keyboard := tgbotapi.NewInlineKeyboardMarkup(
-			tgbotapi.NewInlineKeyboardRow(
-				tgbotapi.NewInlineKeyboardButtonData("✅ Подтвердить", fmt.Sprintf("confirm_%d", booking.ID)),
-				tgbotapi.NewInlineKeyboardButtonData("❌ Отклонить", fmt.Sprintf("reject_%d", booking.ID)),
-			),
-			tgbotapi.NewInlineKeyboardRow(
-				tgbotapi.NewInlineKeyboardButtonData("✏️ Изменить аппарат", fmt.Sprintf("change_item_%d", booking.ID)),
-				tgbotapi.NewInlineKeyboardButtonData("🔄 Предложить другую дату", fmt.Sprintf("reschedule_%d", booking.ID)),
-			),
-			tgbotapi.NewInlineKeyboardRow(
-				tgbotapi.NewInlineKeyboardButtonData("📞 Позвонить", fmt.Sprintf("call_booking:%d", booking.ID)),
-			),
-		)
-		msg.ReplyMarkup = &keyboard
-
-		if _, err := b.tgService.Send(msg); err != nil {
-			b.logger.Error().Err(err).Int64("manager_id", managerID).Msg("Failed to notify manager")
-		}
-	}
+// newBookingKeyboard - кнопки ответа на новую заявку
+func (b *Bot) newBookingKeyboard(ctx context.Context, bookingID int64) tgbotapi.InlineKeyboardMarkup {
+	return tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(b.t(ctx, "btn.confirm"), fmt.Sprintf("confirm_%d", bookingID)),
+			tgbotapi.NewInlineKeyboardButtonData(b.t(ctx, "btn.reject"), fmt.Sprintf("reject_%d", bookingID)),
+		),
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(b.t(ctx, "btn.change_item"), fmt.Sprintf("change_item_%d", bookingID)),
+			tgbotapi.NewInlineKeyboardButtonData(b.t(ctx, "btn.reschedule_short"), fmt.Sprintf("reschedule_%d", bookingID)),
+		),
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(b.t(ctx, "btn.call"), fmt.Sprintf("call_booking:%d", bookingID)),
+		),
+	)
 }
 
 // handleCallButton обработка нажатия кнопки "Позвонить"
@@ -772,23 +852,27 @@ func (b *Bot) handleCallButton(ctx context.Context, update *tgbotapi.Update) {
 	// Парсим ID заявки
 	bookingID, err := strconv.ParseInt(data, 10, 64)
 	if err != nil {
-		b.sendMessage(callback.Message.Chat.ID, "❌ Ошибка: неверный формат данных заявки")
+		b.sendMessage(callback.Message.Chat.ID, b.t(ctx, "call.invalid_data"))
 		// Подтверждаем callback даже при ошибке
-		_, _ = b.tgService.Send(tgbotapi.NewCallback(callback.ID, "❌ Ошибка"))
+		_, _ = b.tgService.Send(tgbotapi.NewCallback(callback.ID, b.t(ctx, "call.error")))
 		return
 	}
 
 	// Получаем заявку из базы данных
 	booking, err := b.bookingService.GetBooking(ctx, bookingID)
 	if err != nil {
-		b.sendMessage(callback.Message.Chat.ID, "❌ Заявка не найдена")
-		_, _ = b.tgService.Send(tgbotapi.NewCallback(callback.ID, "❌ Заявка не найдена"))
+		b.sendMessage(callback.Message.Chat.ID, b.t(ctx, "call.booking_not_found"))
+		_, _ = b.tgService.Send(tgbotapi.NewCallback(callback.ID, b.t(ctx, "call.booking_not_found")))
+		return
+	}
+
+	if b.denyWithoutItemAccess(ctx, callback.Message.Chat.ID, callback.From.ID, booking.ItemID) {
 		return
 	}
 
 	if booking.Phone == "" {
-		b.sendMessage(callback.Message.Chat.ID, "❌ Номер телефона не указан в заявке")
-		_, _ = b.tgService.Send(tgbotapi.NewCallback(callback.ID, "❌ Номер не указан"))
+		b.sendMessage(callback.Message.Chat.ID, b.t(ctx, "call.no_phone"))
+		_, _ = b.tgService.Send(tgbotapi.NewCallback(callback.ID, b.t(ctx, "call.no_phone_short")))
 		return
 	}
 
@@ -796,14 +880,14 @@ func (b *Bot) handleCallButton(ctx context.Context, update *tgbotapi.Update) {
 	formattedPhone := b.formatPhoneForDisplay(booking.Phone)
 
 	// Создаем информативное сообщение
-	message := "📞 *Информация для связи*\n\n"
-	message += fmt.Sprintf("👤 *Клиент:* %s\n", booking.UserName)
-	message += fmt.Sprintf("📱 *Телефон:* `%s`\n", formattedPhone)
-	message += fmt.Sprintf("🏢 *Аппарат:* %s\n", booking.ItemName)
-	message += fmt.Sprintf("📅 *Дата:* %s\n", booking.Date.Format("02.01.2006"))
+	message := b.t(ctx, "call.title") + "\n\n"
+	message += b.t(ctx, "manager_booking.client", booking.UserName) + "\n"
+	message += b.t(ctx, "manager_booking.phone", "`"+formattedPhone+"`") + "\n"
+	message += b.t(ctx, "manager_booking.item", booking.ItemName) + "\n"
+	message += b.t(ctx, "manager_booking.date", booking.Date.Format("02.01.2006")) + "\n"
 
 	if booking.Comment != "" {
-		message += fmt.Sprintf("💬 *Комментарий:* %s\n", booking.Comment)
+		message += b.t(ctx, "manager_booking.comment", booking.Comment) + "\n"
 	}
 
 	msg := tgbotapi.NewMessage(callback.Message.Chat.ID, message)
@@ -816,7 +900,7 @@ func (b *Bot) handleCallButton(ctx context.Context, update *tgbotapi.Update) {
 			tgbotapi.NewInlineKeyboardButtonURL("✉️ Telegram", fmt.Sprintf("https://t.me/%s", strings.TrimPrefix(booking.Phone, "+"))),
 		),
 		tgbotapi.NewInlineKeyboardRow(
-			tgbotapi.NewInlineKeyboardButtonData("⬅️ Назад к заявке", fmt.Sprintf("show_booking:%d", booking.ID)),
+			tgbotapi.NewInlineKeyboardButtonData(b.t(ctx, "btn.back_to_booking"), fmt.Sprintf("show_booking:%d", booking.ID)),
 		),
 	)
 	msg.ReplyMarkup = &keyboard
@@ -0,0 +1,468 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"bronivik/internal/models"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+const (
+	bulkStartPrefix  = "bulk_start:"
+	bulkTogglePrefix = "bulk_toggle:"
+	bulkPagePrefix   = "bulk_page:"
+	bulkDoPrefix     = "bulk_do:"
+	bulkSelectAll    = "bulk_all"
+	bulkClear        = "bulk_clear"
+	bulkCancel       = "bulk_cancel"
+
+	bulkFilterKey   = "bulk_filter"
+	bulkSelectedKey = "bulk_selected"
+	bulkOriginKey   = "bulk_origin"
+
+	bulkOriginList   = "list"
+	bulkOriginSearch = "search"
+)
+
+// bulkActions - массовые действия и кнопки, которыми они запускаются
+var bulkActions = []struct {
+	action string
+	button string
+}{
+	{models.BulkActionConfirm, "btn.confirm"},
+	{models.BulkActionReject, "btn.reject"},
+	{models.BulkActionComplete, "btn.complete"},
+}
+
+// handleManagerBulkCommands обрабатывает /confirm_pending ДД.ММ.ГГГГ [ID аппарата]:
+// все ожидающие заявки на дату попадают в выбор уже отмеченными
+func (b *Bot) handleManagerBulkCommands(ctx context.Context, update *tgbotapi.Update, text string) bool {
+	if text != "/confirm_pending" && !strings.HasPrefix(text, "/confirm_pending ") {
+		return false
+	}
+
+	chatID := update.Message.Chat.ID
+	userID := update.Message.From.ID
+	if b.denyWithoutPermission(ctx, chatID, userID, models.PermManageBookings) {
+		return true
+	}
+
+	args := strings.Fields(strings.TrimPrefix(text, "/confirm_pending"))
+	if len(args) == 0 || len(args) > 2 {
+		b.sendMessage(chatID, b.t(ctx, "bulk.confirm_pending_usage"))
+		return true
+	}
+
+	date, err := time.Parse("02.01.2006", args[0])
+	if err != nil {
+		b.sendMessage(chatID, b.t(ctx, "bulk.confirm_pending_usage"))
+		return true
+	}
+
+	filter := models.BookingFilter{
+		Statuses: []string{models.StatusPending},
+		DateFrom: date,
+		DateTo:   date,
+	}
+	if len(args) == 2 {
+		itemID, err := strconv.ParseInt(args[1], 10, 64)
+		if err != nil || itemID <= 0 {
+			b.sendMessage(chatID, b.t(ctx, "bulk.confirm_pending_usage"))
+			return true
+		}
+		if b.denyWithoutItemAccess(ctx, chatID, userID, itemID) {
+			return true
+		}
+		filter.ItemIDs = []int64{itemID}
+	}
+
+	bookings, err := b.bulkCandidates(ctx, userID, filter)
+	if err != nil {
+		b.sendMessage(chatID, b.t(ctx, "error.bookings_list"))
+		return true
+	}
+	if len(bookings) == 0 {
+		b.sendMessage(chatID, b.t(ctx, "bulk.no_pending", args[0]))
+		return true
+	}
+
+	selected := make(map[int64]int64, len(bookings))
+	for _, booking := range bookings {
+		selected[booking.ID] = booking.Version
+	}
+	b.saveBulkState(ctx, userID, &filter, selected, "")
+	b.sendBulkSelection(ctx, chatID, userID, 0, 0)
+	return true
+}
+
+// handleManagerBulkCallback обрабатывает режим выбора нескольких заявок
+func (b *Bot) handleManagerBulkCallback(ctx context.Context, update *tgbotapi.Update, data string) bool {
+	if !strings.HasPrefix(data, "bulk_") {
+		return false
+	}
+
+	callback := update.CallbackQuery
+	chatID := callback.Message.Chat.ID
+	userID := callback.From.ID
+	messageID := callback.Message.MessageID
+
+	if b.denyWithoutPermission(ctx, chatID, userID, models.PermManageBookings) {
+		return true
+	}
+
+	if strings.HasPrefix(data, bulkStartPrefix) {
+		b.startBulkSelection(ctx, chatID, userID, messageID, strings.TrimPrefix(data, bulkStartPrefix))
+		return true
+	}
+
+	state := b.getUserState(ctx, userID)
+	if state == nil || state.CurrentStep != models.StateManagerBulkSelect {
+		b.sendMessage(chatID, b.t(ctx, "error.session_expired"))
+		return true
+	}
+	filter := bulkFilterFromState(state)
+	selected := parseBulkSelection(state.GetString(bulkSelectedKey))
+	origin := state.GetString(bulkOriginKey)
+
+	switch {
+	case strings.HasPrefix(data, bulkPagePrefix):
+		page, _ := strconv.Atoi(strings.TrimPrefix(data, bulkPagePrefix))
+		b.sendBulkSelection(ctx, chatID, userID, messageID, page)
+		return true
+
+	case strings.HasPrefix(data, bulkTogglePrefix):
+		// bulk_toggle:<страница>:<id> - после отметки остаемся на той же странице
+		pageStr, idStr, _ := strings.Cut(strings.TrimPrefix(data, bulkTogglePrefix), ":")
+		page, _ := strconv.Atoi(pageStr)
+		id, _ := strconv.ParseInt(idStr, 10, 64)
+		if _, ok := selected[id]; ok {
+			delete(selected, id)
+		} else if booking, err := b.bookingService.GetBooking(ctx, id); err == nil && booking != nil {
+			// Запоминаем версию, которую видел менеджер: если заявку изменят до
+			// выполнения действия, она попадет в конфликты, а не будет перезаписана
+			selected[id] = booking.Version
+		}
+		b.saveBulkState(ctx, userID, &filter, selected, origin)
+		b.sendBulkSelection(ctx, chatID, userID, messageID, page)
+		return true
+
+	case data == bulkSelectAll:
+		bookings, err := b.bulkCandidates(ctx, userID, filter)
+		if err != nil {
+			b.sendMessage(chatID, b.t(ctx, "error.bookings_list"))
+			return true
+		}
+		for _, booking := range bookings {
+			selected[booking.ID] = booking.Version
+		}
+
+	case data == bulkClear:
+		selected = map[int64]int64{}
+
+	case strings.HasPrefix(data, bulkDoPrefix):
+		b.applyBulkAction(ctx, chatID, userID, strings.TrimPrefix(data, bulkDoPrefix), selected)
+		b.saveBulkState(ctx, userID, &filter, nil, origin)
+		b.sendBulkSelection(ctx, chatID, userID, messageID, 0)
+		return true
+
+	case data == bulkCancel:
+		b.finishBulkSelection(ctx, chatID, userID, messageID, &filter, origin)
+		return true
+
+	default:
+		return false
+	}
+
+	b.saveBulkState(ctx, userID, &filter, selected, origin)
+	b.sendBulkSelection(ctx, chatID, userID, messageID, 0)
+	return true
+}
+
+// startBulkSelection включает выбор в общем списке заявок или в результатах поиска
+func (b *Bot) startBulkSelection(ctx context.Context, chatID, userID int64, messageID int, origin string) {
+	var filter models.BookingFilter
+
+	switch origin {
+	case bulkOriginSearch:
+		state := b.getUserState(ctx, userID)
+		if state == nil || state.CurrentStep != models.StateManagerSearch {
+			b.sendMessage(chatID, b.t(ctx, "error.session_expired"))
+			return
+		}
+		filter = b.searchFilterFromState(state)
+	default:
+		// Тот же период, что и в списке «Все заявки»
+		origin = bulkOriginList
+		now := time.Now()
+		today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+		filter.DateFrom, filter.DateTo = today.AddDate(0, 0, -7), today.AddDate(0, 2, 0)
+	}
+
+	b.saveBulkState(ctx, userID, &filter, nil, origin)
+	b.sendBulkSelection(ctx, chatID, userID, messageID, 0)
+}
+
+// bulkStartRows возвращает кнопку включения выбора, если менеджер может менять заявки
+func (b *Bot) bulkStartRows(ctx context.Context, userID int64, origin string) [][]tgbotapi.InlineKeyboardButton {
+	if !b.hasPermission(userID, models.PermManageBookings) {
+		return nil
+	}
+	return [][]tgbotapi.InlineKeyboardButton{tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData(b.t(ctx, "bulk.select_button"), bulkStartPrefix+origin),
+	)}
+}
+
+// finishBulkSelection выходит из режима выбора туда, откуда он был открыт
+func (b *Bot) finishBulkSelection(
+	ctx context.Context,
+	chatID, userID int64,
+	messageID int,
+	filter *models.BookingFilter,
+	origin string,
+) {
+	switch origin {
+	case bulkOriginSearch:
+		b.saveSearchFilter(ctx, userID, filter)
+		b.sendSearchResults(ctx, chatID, userID, messageID, 0)
+	case bulkOriginList:
+		b.clearUserState(ctx, userID)
+		b.sendManagerBookingsPage(ctx, chatID, messageID, 0)
+	default:
+		b.clearUserState(ctx, userID)
+		if _, err := b.tgService.EditMessage(chatID, messageID, b.t(ctx, "bulk.finished"), nil); err != nil {
+			b.logger.Error().Err(err).Msg("Failed to close bulk selection")
+		}
+	}
+}
+
+// bulkCandidates возвращает заявки под фильтром, к которым применимо хотя бы одно массовое действие
+func (b *Bot) bulkCandidates(ctx context.Context, userID int64, filter models.BookingFilter) ([]*models.Booking, error) {
+	filter.Limit, filter.Offset = models.MaxSearchResults, 0
+	if !b.scopeBookingFilter(userID, &filter) {
+		return nil, nil
+	}
+
+	bookings, err := b.bookingService.SearchBookings(ctx, filter)
+	if err != nil {
+		b.logger.Error().Err(err).Int64("user_id", userID).Msg("Error loading bookings for bulk selection")
+		return nil, err
+	}
+
+	candidates := make([]*models.Booking, 0, len(bookings))
+	for _, booking := range b.filterBookingsByScope(userID, bookings) {
+		for _, a := range bulkActions {
+			if models.BulkActionAllowed(a.action, booking.Status) {
+				candidates = append(candidates, booking)
+				break
+			}
+		}
+	}
+	return candidates, nil
+}
+
+// sendBulkSelection показывает список с отметками и кнопками действий над выбранными заявками
+func (b *Bot) sendBulkSelection(ctx context.Context, chatID, userID int64, messageID, page int) {
+	state := b.getUserState(ctx, userID)
+	if state == nil || state.CurrentStep != models.StateManagerBulkSelect {
+		b.sendMessage(chatID, b.t(ctx, "error.session_expired"))
+		return
+	}
+	filter := bulkFilterFromState(state)
+	selected := parseBulkSelection(state.GetString(bulkSelectedKey))
+
+	bookings, err := b.bulkCandidates(ctx, userID, filter)
+	if err != nil {
+		b.sendMessage(chatID, b.t(ctx, "error.bookings_list"))
+		return
+	}
+
+	marked := make(map[int64]bool, len(selected))
+	for id := range selected {
+		marked[id] = true
+	}
+
+	title := b.t(ctx, "bulk.title") + "\n\n" + b.tn(ctx, "bulk.selected", len(selected))
+	if len(bookings) == 0 {
+		title += "\n\n" + b.t(ctx, "bulk.empty")
+	}
+
+	rows := [][]tgbotapi.InlineKeyboardButton{
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(b.t(ctx, "bulk.select_all", len(bookings)), bulkSelectAll),
+			tgbotapi.NewInlineKeyboardButtonData(b.t(ctx, "bulk.clear"), bulkClear),
+		),
+	}
+	if len(selected) > 0 {
+		actionRow := make([]tgbotapi.InlineKeyboardButton, 0, len(bulkActions))
+		for _, a := range bulkActions {
+			label := fmt.Sprintf("%s (%d)", b.t(ctx, a.button), len(selected))
+			actionRow = append(actionRow, tgbotapi.NewInlineKeyboardButtonData(label, bulkDoPrefix+a.action))
+		}
+		rows = append(rows, actionRow)
+	}
+	rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData(b.t(ctx, "bulk.cancel"), bulkCancel),
+	))
+
+	b.renderPaginatedBookings(&PaginationParams{
+		Ctx:        ctx,
+		ChatID:     chatID,
+		MessageID:  messageID,
+		Page:       page,
+		Title:      title,
+		ItemPrefix: fmt.Sprintf("%s%d:", bulkTogglePrefix, page),
+		PagePrefix: bulkPagePrefix,
+		ExtraRows:  rows,
+		Selected:   marked,
+	}, bookings)
+}
+
+// applyBulkAction выполняет действие над выбранными заявками и отправляет менеджеру итог
+func (b *Bot) applyBulkAction(ctx context.Context, chatID, userID int64, action string, selected map[int64]int64) {
+	if len(selected) == 0 {
+		b.sendMessage(chatID, b.t(ctx, "bulk.nothing_selected"))
+		return
+	}
+
+	ids := make([]int64, 0, len(selected))
+	for id := range selected {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+
+	bookings := make([]*models.Booking, 0, len(ids))
+	var outOfScope []*models.Booking
+	for _, id := range ids {
+		current, err := b.bookingService.GetBooking(ctx, id)
+		if err != nil || current == nil {
+			// Удаленная заявка не подходит ни под одно действие и попадет в пропущенные
+			bookings = append(bookings, &models.Booking{ID: id, Version: selected[id]})
+			continue
+		}
+		booking := *current
+		booking.Version = selected[id]
+		if !b.canManageItem(userID, booking.ItemID) {
+			outOfScope = append(outOfScope, &booking)
+			continue
+		}
+		bookings = append(bookings, &booking)
+	}
+
+	result, err := b.bookingService.ApplyBulkAction(ctx, action, bookings, userID)
+	if err != nil {
+		b.logger.Error().Err(err).Str("action", action).Msg("Error applying bulk action")
+		b.sendMessage(chatID, b.getErrorMessage(ctx, err))
+		return
+	}
+	result.Skipped = append(result.Skipped, outOfScope...)
+
+	b.logger.Info().
+		Int64("manager_id", userID).
+		Str("action", action).
+		Int("succeeded", len(result.Succeeded)).
+		Int("conflicts", len(result.Conflicts)).
+		Int("skipped", len(result.Skipped)).
+		Int("failed", len(result.Failed)).
+		Msg("Manager applied bulk action")
+
+	for _, booking := range result.Succeeded {
+		userCtx := b.withUserLanguage(ctx, booking.UserID)
+		if _, err := b.tgService.Send(tgbotapi.NewMessage(booking.UserID, b.statusNotice(userCtx, booking, action))); err != nil {
+			b.logger.Error().Err(err).Int64("booking_id", booking.ID).Msg("Failed to send user notification")
+		}
+	}
+
+	b.sendMessage(chatID, b.bulkSummary(ctx, action, result))
+}
+
+// bulkSummary описывает итог массового действия с номерами заявок, требующих внимания
+func (b *Bot) bulkSummary(ctx context.Context, action string, result *models.BulkResult) string {
+	name := action
+	for _, a := range bulkActions {
+		if a.action == action {
+			name = b.t(ctx, a.button)
+		}
+	}
+
+	lines := []string{
+		b.t(ctx, "bulk.summary", name, result.Total()),
+		b.t(ctx, "bulk.summary_succeeded", len(result.Succeeded)),
+	}
+	if len(result.Conflicts) > 0 {
+		lines = append(lines, b.t(ctx, "bulk.summary_conflicts", len(result.Conflicts), bookingIDList(result.Conflicts)))
+	}
+	if len(result.Skipped) > 0 {
+		lines = append(lines, b.t(ctx, "bulk.summary_skipped", len(result.Skipped), bookingIDList(result.Skipped)))
+	}
+	if len(result.Failed) > 0 {
+		lines = append(lines, b.t(ctx, "bulk.summary_failed", len(result.Failed), bookingIDList(result.Failed)))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// saveBulkState сохраняет фильтр списка и выбранные заявки с версиями, которые видел менеджер
+func (b *Bot) saveBulkState(ctx context.Context, userID int64, filter *models.BookingFilter, selected map[int64]int64, origin string) {
+	b.setUserState(ctx, userID, models.StateManagerBulkSelect, map[string]interface{}{
+		bulkFilterKey:   filter.Values().Encode(),
+		bulkSelectedKey: formatBulkSelection(selected),
+		bulkOriginKey:   origin,
+	})
+}
+
+func bulkFilterFromState(state *models.UserState) models.BookingFilter {
+	values, err := url.ParseQuery(state.GetString(bulkFilterKey))
+	if err != nil {
+		return models.BookingFilter{}
+	}
+	filter, err := models.ParseBookingFilter(values)
+	if err != nil {
+		return models.BookingFilter{}
+	}
+	return filter
+}
+
+// formatBulkSelection кодирует выбор как "id:version,id:version"
+func formatBulkSelection(selected map[int64]int64) string {
+	ids := make([]int64, 0, len(selected))
+	for id := range selected {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+
+	parts := make([]string, 0, len(ids))
+	for _, id := range ids {
+		parts = append(parts, fmt.Sprintf("%d:%d", id, selected[id]))
+	}
+	return strings.Join(parts, ",")
+}
+
+func parseBulkSelection(raw string) map[int64]int64 {
+	selected := make(map[int64]int64)
+	for _, part := range strings.Split(raw, ",") {
+		idStr, versionStr, ok := strings.Cut(part, ":")
+		if !ok {
+			continue
+		}
+		id, errID := strconv.ParseInt(idStr, 10, 64)
+		version, errVersion := strconv.ParseInt(versionStr, 10, 64)
+		if errID == nil && errVersion == nil && id > 0 {
+			selected[id] = version
+		}
+	}
+	return selected
+}
+
+func bookingIDList(bookings []*models.Booking) string {
+	ids := make([]string, 0, len(bookings))
+	for _, booking := range bookings {
+		ids = append(ids, "#"+strconv.FormatInt(booking.ID, 10))
+	}
+	return strings.Join(ids, ", ")
+}
@@ -0,0 +1,147 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"bronivik/internal/exports"
+	"bronivik/internal/models"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// handleManagerExportCommands обрабатывает /export_bookings
+func (b *Bot) handleManagerExportCommands(ctx context.Context, update *tgbotapi.Update, text string) bool {
+	fields := strings.Fields(text)
+	if len(fields) == 0 || fields[0] != "/export_bookings" {
+		return false
+	}
+
+	if !b.denyWithoutPermission(ctx, update.Message.Chat.ID, update.Message.From.ID, models.PermExportData) {
+		b.handleBookingExportCommand(ctx, update, fields[1:])
+	}
+	return true
+}
+
+// parseExportArgs разбирает аргументы выгрузки: две даты ДД.ММ.ГГГГ, затем в любом порядке
+// формат (csv, jsonl, xlsx), статусы и номера аппаратов через запятую
+func parseExportArgs(args []string) (filter models.BookingFilter, format string, ok bool) {
+	format = exports.FormatCSV
+	if len(args) < 2 {
+		return filter, format, false
+	}
+	from, errFrom := time.Parse("02.01.2006", args[0])
+	to, errTo := time.Parse("02.01.2006", args[1])
+	if errFrom != nil || errTo != nil || to.Before(from) {
+		return filter, format, false
+	}
+	filter.DateFrom, filter.DateTo = from, to
+
+	for _, arg := range args[2:] {
+		if f, err := exports.ParseFormat(arg); err == nil {
+			format = f
+			continue
+		}
+		for _, part := range strings.Split(strings.ToLower(arg), ",") {
+			if part == "" {
+				continue
+			}
+			if models.IsBookingStatus(part) {
+				filter.Statuses = append(filter.Statuses, part)
+				continue
+			}
+			id, err := strconv.ParseInt(part, 10, 64)
+			if err != nil || id <= 0 {
+				return filter, format, false
+			}
+			filter.ItemIDs = append(filter.ItemIDs, id)
+		}
+	}
+	return filter, format, true
+}
+
+// handleBookingExportCommand выгружает список заявок за период файлом:
+// /export_bookings <ДД.ММ.ГГГГ> <ДД.ММ.ГГГГ> [csv|jsonl|xlsx] [статусы] [id_аппаратов]
+func (b *Bot) handleBookingExportCommand(ctx context.Context, update *tgbotapi.Update, args []string) {
+	chatID := update.Message.Chat.ID
+	userID := update.Message.From.ID
+
+	filter, format, ok := parseExportArgs(args)
+	if !ok {
+		b.sendMessage(chatID, b.t(ctx, "export.usage"))
+		return
+	}
+	for _, itemID := range filter.ItemIDs {
+		if b.denyWithoutItemAccess(ctx, chatID, userID, itemID) {
+			return
+		}
+	}
+
+	filePath, count, err := b.exportBookingList(ctx, userID, &filter, format)
+	if err != nil {
+		b.logger.Error().Err(err).Str("format", format).Msg("Error exporting bookings")
+		b.sendMessage(chatID, b.t(ctx, "export.error"))
+		return
+	}
+
+	file, err := os.Open(filePath)
+	if err != nil {
+		b.logger.Error().Err(err).Str("file_path", filePath).Msg("Error opening export file")
+		b.sendMessage(chatID, b.t(ctx, "export.error"))
+		return
+	}
+	defer file.Close()
+
+	doc := tgbotapi.NewDocument(chatID, tgbotapi.FileReader{Name: filepath.Base(filePath), Reader: file})
+	doc.Caption = b.t(ctx, "export.caption", filter.DateFrom.Format("02.01.2006"), filter.DateTo.Format("02.01.2006"), count)
+	if _, err := b.tgService.Send(doc); err != nil {
+		b.logger.Error().Err(err).Msg("Error sending bookings export")
+		b.sendMessage(chatID, b.t(ctx, "export.error"))
+	}
+}
+
+// exportBookingList записывает заявки по фильтру в файл, читая их из базы по одной.
+// Менеджер с ограниченной ролью получает только заявки своих аппаратов.
+func (b *Bot) exportBookingList(
+	ctx context.Context,
+	userID int64,
+	filter *models.BookingFilter,
+	format string,
+) (filePath string, count int, err error) {
+	if err := os.MkdirAll(b.config.Exports.Path, 0o755); err != nil {
+		return "", 0, fmt.Errorf("error creating export directory: %v", err)
+	}
+
+	filePath = filepath.Join(b.config.Exports.Path, exports.Filename(format, filter, time.Now()))
+	file, err := os.Create(filePath)
+	if err != nil {
+		return "", 0, fmt.Errorf("error creating file: %v", err)
+	}
+	defer file.Close()
+
+	out, err := exports.NewBookingWriter(file, format)
+	if err != nil {
+		return "", 0, err
+	}
+	err = b.bookingService.StreamBookings(ctx, *filter, func(booking *models.Booking) error {
+		if !b.canManageItem(userID, booking.ItemID) {
+			return nil
+		}
+		count++
+		return out.Write(booking)
+	})
+	if err != nil {
+		return "", 0, err
+	}
+	if err := out.Close(); err != nil {
+		return "", 0, err
+	}
+
+	b.logger.Info().Str("file_path", filePath).Int("bookings", count).Msg("Bookings export created")
+	return filePath, count, nil
+}
@@ -0,0 +1,358 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"bronivik/internal/config"
+	"bronivik/internal/models"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+const (
+	handoverOutPrefix    = "handover_out:"
+	handoverInPrefix     = "handover_in:"
+	handoverPhotosPrefix = "handover_photos:"
+	handoverDone         = "handover_done"
+	handoverCancel       = "handover_cancel"
+
+	handoverActionKey  = "handover_action"
+	handoverBookingKey = "handover_booking"
+	handoverNoteKey    = "handover_note"
+	handoverPhotosKey  = "handover_photos"
+
+	handoverActionOut = "out"
+	handoverActionIn  = "in"
+)
+
+// handleManagerHandoverCallback обрабатывает выдачу аппарата клиенту и прием его обратно
+func (b *Bot) handleManagerHandoverCallback(ctx context.Context, update *tgbotapi.Update, data string) bool {
+	if !strings.HasPrefix(data, "handover_") {
+		return false
+	}
+
+	callback := update.CallbackQuery
+	chatID := callback.Message.Chat.ID
+	userID := callback.From.ID
+
+	if b.denyWithoutPermission(ctx, chatID, userID, models.PermManageBookings) {
+		return true
+	}
+
+	switch {
+	case strings.HasPrefix(data, handoverOutPrefix):
+		id, _ := strconv.ParseInt(strings.TrimPrefix(data, handoverOutPrefix), 10, 64)
+		b.startHandover(ctx, chatID, userID, id, handoverActionOut)
+	case strings.HasPrefix(data, handoverInPrefix):
+		id, _ := strconv.ParseInt(strings.TrimPrefix(data, handoverInPrefix), 10, 64)
+		b.startHandover(ctx, chatID, userID, id, handoverActionIn)
+	case strings.HasPrefix(data, handoverPhotosPrefix):
+		id, _ := strconv.ParseInt(strings.TrimPrefix(data, handoverPhotosPrefix), 10, 64)
+		b.sendHandoverPhotos(ctx, chatID, userID, id)
+	case data == handoverDone:
+		b.finishHandover(ctx, chatID, userID)
+	case data == handoverCancel:
+		b.clearUserState(ctx, userID)
+		b.sendMessage(chatID, b.t(ctx, "handover.canceled"))
+	default:
+		return false
+	}
+	return true
+}
+
+// startHandover переводит менеджера в режим ввода заметки и фото по выдаче или возврату
+func (b *Bot) startHandover(ctx context.Context, chatID, userID, bookingID int64, action string) {
+	booking, err := b.bookingService.GetBooking(ctx, bookingID)
+	if err != nil || booking == nil {
+		b.sendMessage(chatID, b.t(ctx, "error.booking_not_found"))
+		return
+	}
+	if b.denyWithoutItemAccess(ctx, chatID, userID, booking.ItemID) {
+		return
+	}
+
+	b.setUserState(ctx, userID, models.StateManagerHandover, map[string]interface{}{
+		handoverActionKey:  action,
+		handoverBookingKey: bookingID,
+		handoverNoteKey:    "",
+		handoverPhotosKey:  "",
+	})
+
+	prompt := "handover.out_prompt"
+	if action == handoverActionIn {
+		prompt = "handover.in_prompt"
+	}
+	keyboard := tgbotapi.NewInlineKeyboardMarkup(tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData(b.t(ctx, "btn.handover_done"), handoverDone),
+		tgbotapi.NewInlineKeyboardButtonData(b.t(ctx, "btn.cancel"), handoverCancel),
+	))
+	if _, err := b.tgService.SendWithInlineKeyboard(chatID, b.t(ctx, prompt, bookingID, booking.ItemName), keyboard); err != nil {
+		b.logger.Error().Err(err).Int64("chat_id", chatID).Msg("Failed to send handover prompt")
+	}
+}
+
+// handleHandoverInput копит заметки о состоянии аппарата и фото, присланные менеджером
+func (b *Bot) handleHandoverInput(ctx context.Context, update *tgbotapi.Update, text string, state *models.UserState) {
+	chatID := update.Message.Chat.ID
+	userID := update.Message.From.ID
+
+	if b.isButton(text, btnCancel) {
+		b.clearUserState(ctx, userID)
+		b.sendMessage(chatID, b.t(ctx, "handover.canceled"))
+		return
+	}
+
+	note := state.GetString(handoverNoteKey)
+	photos := splitHandoverPhotos(state.GetString(handoverPhotosKey))
+
+	if len(update.Message.Photo) > 0 {
+		// Telegram присылает фото в нескольких размерах, последнее - самое крупное
+		photos = append(photos, update.Message.Photo[len(update.Message.Photo)-1].FileID)
+		text = update.Message.Caption
+	}
+	if text = strings.TrimSpace(text); text != "" {
+		note = strings.TrimSpace(note + "\n" + text)
+	}
+
+	b.setUserState(ctx, userID, models.StateManagerHandover, map[string]interface{}{
+		handoverActionKey:  state.GetString(handoverActionKey),
+		handoverBookingKey: state.GetInt64(handoverBookingKey),
+		handoverNoteKey:    note,
+		handoverPhotosKey:  strings.Join(photos, ","),
+	})
+	b.sendMessage(chatID, b.t(ctx, "handover.noted", len(photos)))
+}
+
+// finishHandover сохраняет выдачу или возврат, уведомляет клиента и показывает обновленную карточку заявки
+func (b *Bot) finishHandover(ctx context.Context, chatID, userID int64) {
+	state := b.getUserState(ctx, userID)
+	if state == nil || state.CurrentStep != models.StateManagerHandover {
+		b.sendMessage(chatID, b.t(ctx, "error.session_expired"))
+		return
+	}
+
+	bookingID := state.GetInt64(handoverBookingKey)
+	note := state.GetString(handoverNoteKey)
+	photos := splitHandoverPhotos(state.GetString(handoverPhotosKey))
+
+	var handover *models.Handover
+	var err error
+	if state.GetString(handoverActionKey) == handoverActionIn {
+		handover, err = b.bookingService.CheckInBooking(ctx, bookingID, userID, note, photos)
+	} else {
+		handover, err = b.bookingService.CheckOutBooking(ctx, bookingID, userID, note, photos)
+	}
+	if err != nil {
+		b.logger.Error().Err(err).Int64("booking_id", bookingID).Int64("manager_id", userID).Msg("Error saving handover")
+		b.sendMessage(chatID, b.getErrorMessage(ctx, err))
+		return
+	}
+	b.clearUserState(ctx, userID)
+
+	b.logger.Info().
+		Int64("booking_id", bookingID).
+		Int64("handover_id", handover.ID).
+		Int64("manager_id", userID).
+		Bool("returned", handover.IsReturned()).
+		Msg("Manager recorded handover")
+
+	if handover.IsReturned() {
+		b.sendMessage(chatID, b.t(ctx, "handover.checked_in", handover.ItemName))
+	} else {
+		b.sendMessage(chatID, b.t(ctx, "handover.checked_out", handover.ItemName, b.returnDeadline(handover).Format("02.01.2006 15:04")))
+	}
+	b.notifyClientAboutHandover(ctx, userID, handover)
+
+	if booking, err := b.bookingService.GetBooking(ctx, bookingID); err == nil && booking != nil {
+		b.sendManagerBookingDetail(ctx, chatID, booking)
+	}
+}
+
+// notifyClientAboutHandover сообщает клиенту срок возврата при выдаче и благодарит при возврате.
+// Заявки, созданные менеджером от имени клиента, записаны на менеджера, их не уведомляем.
+func (b *Bot) notifyClientAboutHandover(ctx context.Context, managerID int64, handover *models.Handover) {
+	if handover.UserID == 0 || handover.UserID == managerID {
+		return
+	}
+
+	userCtx := b.withUserLanguage(ctx, handover.UserID)
+	text := b.t(userCtx, "handover.client_out", handover.ItemName, b.returnDeadline(handover).Format("02.01.2006 15:04"))
+	if handover.IsReturned() {
+		text = b.t(userCtx, "handover.client_in", handover.ItemName)
+	}
+	b.sendMessage(handover.UserID, text)
+}
+
+// sendHandoverPhotos отправляет менеджеру фото, сделанные при выдаче и возврате
+func (b *Bot) sendHandoverPhotos(ctx context.Context, chatID, userID, bookingID int64) {
+	booking, err := b.bookingService.GetBooking(ctx, bookingID)
+	if err != nil || booking == nil {
+		b.sendMessage(chatID, b.t(ctx, "error.booking_not_found"))
+		return
+	}
+	if b.denyWithoutItemAccess(ctx, chatID, userID, booking.ItemID) {
+		return
+	}
+
+	handover, err := b.bookingService.GetBookingHandover(ctx, booking)
+	if err != nil || handover == nil {
+		b.sendMessage(chatID, b.t(ctx, "handover.no_photos"))
+		return
+	}
+
+	sent := 0
+	for _, group := range []struct {
+		caption string
+		photos  []string
+	}{
+		{b.t(ctx, "handover.photo_out"), handover.CheckOutPhotos},
+		{b.t(ctx, "handover.photo_in"), handover.CheckInPhotos},
+	} {
+		for _, fileID := range group.photos {
+			photo := tgbotapi.NewPhoto(chatID, tgbotapi.FileID(fileID))
+			photo.Caption = group.caption
+			if _, err := b.tgService.Send(photo); err != nil {
+				b.logger.Error().Err(err).Int64("booking_id", bookingID).Msg("Failed to send handover photo")
+				continue
+			}
+			sent++
+		}
+	}
+	if sent == 0 {
+		b.sendMessage(chatID, b.t(ctx, "handover.no_photos"))
+	}
+}
+
+// handoverDetailLines - строки карточки заявки о фактической выдаче и возврате
+func (b *Bot) handoverDetailLines(ctx context.Context, handover *models.Handover, now time.Time) []string {
+	lines := []string{b.t(ctx, "handover.detail_out", handover.CheckedOutAt.Format("02.01.2006 15:04"), handover.CheckedOutBy)}
+	if handover.CheckOutNote != "" {
+		lines = append(lines, b.t(ctx, "handover.detail_note", handover.CheckOutNote))
+	}
+	lines = append(lines, b.t(ctx, "handover.detail_due", b.returnDeadline(handover).Format("02.01.2006 15:04")))
+
+	if handover.IsReturned() {
+		lines = append(lines, b.t(ctx, "handover.detail_in", handover.CheckedInAt.Time.Format("02.01.2006 15:04"), handover.CheckedInBy))
+		if handover.CheckInNote != "" {
+			lines = append(lines, b.t(ctx, "handover.detail_note", handover.CheckInNote))
+		}
+	}
+	switch delay := handover.DelayDays(now); {
+	case !handover.IsReturned() && now.After(b.returnDeadline(handover)):
+		lines = append(lines, b.t(ctx, "handover.detail_overdue"))
+	case handover.IsReturned() && delay > 0:
+		lines = append(lines, b.tn(ctx, "handover.detail_late", delay))
+	}
+	return lines
+}
+
+// handoverButtons - кнопки выдачи или приема аппарата для карточки заявки
+func (b *Bot) handoverButtons(ctx context.Context, booking *models.Booking, handover *models.Handover) [][]tgbotapi.InlineKeyboardButton {
+	var rows [][]tgbotapi.InlineKeyboardButton
+	switch {
+	case handover == nil && (booking.Status == models.StatusConfirmed || booking.Status == models.StatusChanged):
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(b.t(ctx, "btn.check_out"), fmt.Sprintf("%s%d", handoverOutPrefix, booking.ID)),
+		))
+	case handover != nil && !handover.IsReturned():
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(b.t(ctx, "btn.check_in"), fmt.Sprintf("%s%d", handoverInPrefix, booking.ID)),
+		))
+	}
+	if handover != nil && len(handover.CheckOutPhotos)+len(handover.CheckInPhotos) > 0 {
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(b.t(ctx, "btn.handover_photos"), fmt.Sprintf("%s%d", handoverPhotosPrefix, booking.ID)),
+		))
+	}
+	return rows
+}
+
+// returnDeadline - момент, к которому аппарат нужно вернуть: день возврата в bot.handover.return_time
+func (b *Bot) returnDeadline(handover *models.Handover) time.Time {
+	hour, minute, err := config.ParseClock(b.config.Bot.Handover.ReturnTime)
+	if err != nil {
+		hour, minute = 0, 0
+	}
+	day := handover.ReturnDate()
+	return time.Date(day.Year(), day.Month(), day.Day(), hour, minute, 0, 0, time.Local)
+}
+
+// StartOverdueReturns periodically alerts managers about equipment that was not returned in time.
+func (b *Bot) StartOverdueReturns(ctx context.Context) {
+	if b == nil || b.tgService == nil {
+		return
+	}
+
+	interval := time.Duration(b.config.Bot.Handover.OverdueCheckMinutes) * time.Minute
+	if interval <= 0 {
+		interval = 30 * time.Minute
+	}
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case now := <-ticker.C:
+				b.alertOverdueReturns(ctx, now)
+			}
+		}
+	}()
+}
+
+// alertOverdueReturns оповещает сотрудников аппарата о невозвращенных вовремя аппаратах.
+// Оповещение по одной выдаче повторяется не чаще раза в overdue_repeat_hours.
+func (b *Bot) alertOverdueReturns(ctx context.Context, now time.Time) {
+	handovers, err := b.bookingService.GetOpenHandovers(ctx)
+	if err != nil {
+		b.logger.Error().Err(err).Msg("overdue returns: load handovers error")
+		return
+	}
+	repeat := time.Duration(b.config.Bot.Handover.OverdueRepeatHours) * time.Hour
+
+	for _, h := range handovers {
+		deadline := b.returnDeadline(h)
+		if now.Before(deadline) {
+			continue
+		}
+		if h.OverdueNotifiedAt.Valid && now.Sub(h.OverdueNotifiedAt.Time) < repeat {
+			continue
+		}
+
+		staff := b.userService.GetStaffForItem(h.ItemID, models.PermManageBookings)
+		if len(staff) == 0 {
+			continue
+		}
+		// Как и при эскалации, сначала запоминаем оповещение, чтобы при ошибке записи не слать его каждую проверку
+		if err := b.bookingService.MarkHandoverOverdueNotified(ctx, h.ID, now); err != nil {
+			b.logger.Error().Err(err).Int64("handover_id", h.ID).Msg("overdue returns: save error")
+			continue
+		}
+
+		hours := int(now.Sub(deadline).Hours())
+		for _, managerID := range staff {
+			managerCtx := b.withUserLanguage(ctx, managerID)
+			keyboard := tgbotapi.NewInlineKeyboardMarkup(tgbotapi.NewInlineKeyboardRow(
+				tgbotapi.NewInlineKeyboardButtonData(b.t(managerCtx, "btn.open_booking"), fmt.Sprintf("show_booking:%d", h.BookingID)),
+			))
+			text := b.t(managerCtx, "handover.overdue_alert", h.ItemName, h.BookingID, h.UserName, deadline.Format("02.01.2006 15:04"), hours)
+			if _, err := b.tgService.SendWithInlineKeyboard(managerID, text, keyboard); err != nil {
+				b.logger.Error().Err(err).Int64("manager_id", managerID).Int64("handover_id", h.ID).Msg("overdue returns: send error")
+			}
+		}
+	}
+}
+
+func splitHandoverPhotos(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return strings.Split(s, ",")
+}
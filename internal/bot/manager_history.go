@@ -0,0 +1,116 @@
+package bot
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"bronivik/internal/models"
+)
+
+// showBookingHistory отправляет менеджеру журнал изменений заявки
+func (b *Bot) showBookingHistory(ctx context.Context, chatID, userID, bookingID int64) {
+	booking, err := b.bookingService.GetBooking(ctx, bookingID)
+	if err != nil || booking == nil {
+		b.sendMessage(chatID, b.t(ctx, "error.booking_not_found"))
+		return
+	}
+	if b.denyWithoutItemAccess(ctx, chatID, userID, booking.ItemID) {
+		return
+	}
+
+	entries, err := b.bookingService.GetBookingHistory(ctx, bookingID)
+	if err != nil {
+		b.logger.Error().Err(err).Int64("booking_id", bookingID).Msg("Error getting booking history")
+		b.sendMessage(chatID, b.t(ctx, "error.booking_history"))
+		return
+	}
+
+	if len(entries) == 0 {
+		b.sendMessage(chatID, b.t(ctx, "history.empty", bookingID))
+		return
+	}
+
+	b.sendMessage(chatID, b.formatAuditHistory(ctx, b.t(ctx, "history.title", bookingID), entries))
+}
+
+// formatAuditHistory формирует текстовое представление журнала изменений
+func (b *Bot) formatAuditHistory(ctx context.Context, title string, entries []*models.AuditEntry) string {
+	var sb strings.Builder
+	sb.WriteString(title)
+	sb.WriteString("\n")
+
+	for _, e := range entries {
+		action := b.auditLabel(ctx, "audit_action.", e.Action)
+		source := b.auditLabel(ctx, "audit_source.", e.Source)
+
+		sb.WriteString(fmt.Sprintf("\n🕐 %s — %s", e.CreatedAt.Format("02.01.2006 15:04"), action))
+		if e.ActorID != 0 {
+			sb.WriteString(fmt.Sprintf("\n👤 %d (%s)", e.ActorID, source))
+		} else {
+			sb.WriteString(fmt.Sprintf("\n👤 %s", source))
+		}
+
+		for _, line := range auditDiffLines(e.Before, e.After) {
+			sb.WriteString("\n   ")
+			sb.WriteString(line)
+		}
+		sb.WriteString("\n")
+	}
+
+	return sb.String()
+}
+
+// auditLabel переводит действие или источник журнала; неизвестные значения показываются как есть
+func (b *Bot) auditLabel(ctx context.Context, prefix, value string) string {
+	key := prefix + value
+	if text := b.t(ctx, key); text != key {
+		return text
+	}
+	return value
+}
+
+// auditDiffLines возвращает список изменившихся полей в виде "поле: было → стало"
+func auditDiffLines(before, after string) []string {
+	var beforeMap, afterMap map[string]interface{}
+	if before != "" {
+		_ = json.Unmarshal([]byte(before), &beforeMap)
+	}
+	if after != "" {
+		_ = json.Unmarshal([]byte(after), &afterMap)
+	}
+
+	keys := make(map[string]struct{}, len(beforeMap)+len(afterMap))
+	for k := range beforeMap {
+		keys[k] = struct{}{}
+	}
+	for k := range afterMap {
+		keys[k] = struct{}{}
+	}
+
+	sorted := make([]string, 0, len(keys))
+	for k := range keys {
+		if k == "version" {
+			continue
+		}
+		sorted = append(sorted, k)
+	}
+	sort.Strings(sorted)
+
+	lines := make([]string, 0, len(sorted))
+	for _, k := range sorted {
+		oldVal, hadOld := beforeMap[k]
+		newVal, hasNew := afterMap[k]
+		switch {
+		case !hadOld && hasNew:
+			lines = append(lines, fmt.Sprintf("%s: %v", k, newVal))
+		case hadOld && !hasNew:
+			lines = append(lines, fmt.Sprintf("%s: %v → —", k, oldVal))
+		case fmt.Sprint(oldVal) != fmt.Sprint(newVal):
+			lines = append(lines, fmt.Sprintf("%s: %v → %v", k, oldVal, newVal))
+		}
+	}
+	return lines
+}
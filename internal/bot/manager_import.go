@@ -0,0 +1,275 @@
+package bot
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"bronivik/internal/database"
+	"bronivik/internal/importer"
+	"bronivik/internal/models"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+const (
+	// maxImportFileSize — больше Telegram Bot API не отдает боту
+	maxImportFileSize = 20 << 20
+	// importErrorsShown — сколько ошибок по строкам показывать в отчете
+	importErrorsShown = 20
+	// importRowsShown — сколько номеров строк-дубликатов показывать в отчете
+	importRowsShown = 50
+)
+
+var (
+	errImportTooLarge = errors.New("import file is too large")
+
+	importHTTPClient = &http.Client{Timeout: time.Minute}
+)
+
+// importPermissions — права, нужные для импорта каждого вида записей
+var importPermissions = map[string]string{
+	importer.KindBookings: models.PermManageBookings,
+	importer.KindItems:    models.PermManageItems,
+	importer.KindUsers:    models.PermManageRoles,
+}
+
+// importSource дает импорту доступ к данным через сервисы бота. Менеджер с ограниченной
+// ролью видит только свои аппараты, поэтому заявки на чужие аппараты не импортируются.
+type importSource struct {
+	b      *Bot
+	userID int64
+}
+
+func (s importSource) GetItemByID(ctx context.Context, id int64) (*models.Item, error) {
+	return s.ownItem(s.b.itemService.GetItemByID(ctx, id))
+}
+
+func (s importSource) GetItemByName(ctx context.Context, name string) (*models.Item, error) {
+	return s.ownItem(s.b.itemService.GetItemByName(ctx, name))
+}
+
+func (s importSource) ownItem(item *models.Item, err error) (*models.Item, error) {
+	if err == nil && item != nil && !s.b.canManageItem(s.userID, item.ID) {
+		return nil, fmt.Errorf("item %d is not managed by %d: %w", item.ID, s.userID, sql.ErrNoRows)
+	}
+	return item, err
+}
+
+func (s importSource) GetAllUsers(ctx context.Context) ([]*models.User, error) {
+	return s.b.userService.GetAllUsers(ctx)
+}
+
+func (s importSource) StreamBookings(ctx context.Context, filter models.BookingFilter, fn func(*models.Booking) error) error {
+	return s.b.bookingService.StreamBookings(ctx, filter, fn)
+}
+
+func (s importSource) ImportItems(ctx context.Context, items []*models.Item, dryRun bool) error {
+	return s.b.itemService.ImportItems(ctx, items, dryRun)
+}
+
+func (s importSource) ImportUsers(ctx context.Context, users []*models.User, dryRun bool) error {
+	return s.b.userService.ImportUsers(ctx, users, dryRun)
+}
+
+func (s importSource) ImportBookings(ctx context.Context, bookings []*models.Booking, dryRun bool) ([]error, error) {
+	return s.b.bookingService.ImportBookings(ctx, bookings, dryRun)
+}
+
+// handleManagerImportCommands обрабатывает /import: файл CSV или XLSX с командой в подписи
+func (b *Bot) handleManagerImportCommands(ctx context.Context, update *tgbotapi.Update, text string) bool {
+	if update.Message.Document != nil {
+		text = update.Message.Caption
+	}
+	fields := strings.Fields(text)
+	if len(fields) == 0 || fields[0] != "/import" {
+		return false
+	}
+
+	chatID := update.Message.Chat.ID
+	opts, ok := parseImportArgs(fields[1:])
+	if !ok || update.Message.Document == nil {
+		b.sendMessage(chatID, b.t(ctx, "import.usage"))
+		return true
+	}
+	if !b.denyWithoutPermission(ctx, chatID, update.Message.From.ID, importPermissions[opts.Kind]) {
+		b.handleImportFile(ctx, update, opts)
+	}
+	return true
+}
+
+// parseImportArgs разбирает аргументы /import: вид записей, затем необязательные dry
+// и соответствие колонок вида поле=Заголовок через запятую (заголовки могут содержать пробелы)
+func parseImportArgs(args []string) (importer.Options, bool) {
+	var opts importer.Options
+	if len(args) == 0 {
+		return opts, false
+	}
+	kind, err := importer.ParseKind(args[0])
+	if err != nil {
+		return opts, false
+	}
+	opts.Kind = kind
+
+	args = args[1:]
+	if len(args) > 0 && strings.EqualFold(args[0], "dry") {
+		opts.DryRun = true
+		args = args[1:]
+	}
+	if len(args) > 0 {
+		if opts.Mapping, err = importer.ParseMapping(strings.Join(args, " ")); err != nil {
+			return opts, false
+		}
+	}
+	return opts, true
+}
+
+// handleImportFile скачивает присланный файл, импортирует его и отвечает отчетом
+func (b *Bot) handleImportFile(ctx context.Context, update *tgbotapi.Update, opts importer.Options) {
+	chatID := update.Message.Chat.ID
+	userID := update.Message.From.ID
+	doc := update.Message.Document
+
+	if doc.FileSize > maxImportFileSize {
+		b.sendMessage(chatID, b.t(ctx, "import.too_large"))
+		return
+	}
+	data, err := b.downloadFile(ctx, doc.FileID)
+	if errors.Is(err, errImportTooLarge) {
+		b.sendMessage(chatID, b.t(ctx, "import.too_large"))
+		return
+	}
+	if err != nil {
+		b.logger.Error().Err(err).Str("file_name", doc.FileName).Msg("Error downloading import file")
+		b.sendMessage(chatID, b.t(ctx, "import.error"))
+		return
+	}
+
+	table, err := importer.ReadTable(doc.FileName, bytes.NewReader(data))
+	if errors.Is(err, importer.ErrUnsupportedFile) {
+		b.sendMessage(chatID, b.t(ctx, "import.unsupported"))
+		return
+	}
+	if err != nil {
+		b.sendMessage(chatID, b.t(ctx, "import.invalid", err.Error()))
+		return
+	}
+
+	report, err := importer.Run(ctx, importSource{b: b, userID: userID}, table, opts)
+	if report == nil {
+		b.sendMessage(chatID, b.t(ctx, "import.invalid", err.Error()))
+		return
+	}
+	if err != nil {
+		b.logger.Error().Err(err).Str("kind", opts.Kind).Int("imported", report.Imported).Msg("Error importing file")
+		b.sendMessage(chatID, b.t(ctx, "import.error"))
+	}
+
+	b.logger.Info().
+		Int64("user_id", userID).
+		Str("kind", report.Kind).
+		Bool("dry_run", report.DryRun).
+		Int("rows", report.Rows).
+		Int("imported", report.Imported).
+		Int("errors", len(report.Errors)).
+		Msg("File imported")
+	b.sendMessage(chatID, b.importReportText(ctx, report))
+}
+
+// downloadFile скачивает файл, присланный боту
+func (b *Bot) downloadFile(ctx context.Context, fileID string) ([]byte, error) {
+	url, err := b.tgService.GetFileDirectURL(fileID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get file url: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, http.NoBody)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := importHTTPClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download file: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to download file: status %d", resp.StatusCode)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxImportFileSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to download file: %w", err)
+	}
+	if len(data) > maxImportFileSize {
+		return nil, errImportTooLarge
+	}
+	return data, nil
+}
+
+// importReportText формирует отчет об импорте: итоги, колонки, дубликаты и первые ошибки
+func (b *Bot) importReportText(ctx context.Context, report *importer.Report) string {
+	key := "import.report"
+	if report.DryRun {
+		key = "import.report_dry"
+	}
+	lines := []string{b.t(ctx, key, report.Kind, report.Rows, report.Imported, len(report.Duplicates), len(report.Errors))}
+
+	columns := make([]string, 0, len(report.Columns))
+	for field, header := range report.Columns {
+		columns = append(columns, field+" ← "+header)
+	}
+	sort.Strings(columns)
+	lines = append(lines, b.t(ctx, "import.columns", strings.Join(columns, ", ")))
+	if len(report.Ignored) > 0 {
+		lines = append(lines, b.t(ctx, "import.ignored", strings.Join(report.Ignored, ", ")))
+	}
+	if len(report.Duplicates) > 0 {
+		lines = append(lines, b.t(ctx, "import.duplicates", joinRowNumbers(report.Duplicates)))
+	}
+
+	for i, rowErr := range report.Errors {
+		if i == importErrorsShown {
+			lines = append(lines, b.t(ctx, "import.more_errors", len(report.Errors)-i))
+			break
+		}
+		lines = append(lines, b.t(ctx, "import.row_error", rowErr.Row, b.importErrorText(ctx, rowErr)))
+	}
+
+	if report.DryRun && report.Imported > 0 {
+		lines = append(lines, "", b.t(ctx, "import.dry_hint"))
+	}
+	return strings.Join(lines, "\n")
+}
+
+func (b *Bot) importErrorText(ctx context.Context, rowErr importer.RowError) string {
+	switch {
+	case errors.Is(rowErr.Err, importer.ErrRequired):
+		return b.t(ctx, "import.err_required", rowErr.Column)
+	case errors.Is(rowErr.Err, importer.ErrInvalidValue):
+		return b.t(ctx, "import.err_invalid", rowErr.Value, rowErr.Column)
+	case errors.Is(rowErr.Err, importer.ErrUnknownItem):
+		return b.t(ctx, "import.err_unknown_item", rowErr.Value)
+	case errors.Is(rowErr.Err, database.ErrNotAvailable):
+		return b.t(ctx, "import.err_no_capacity")
+	}
+	return rowErr.Err.Error()
+}
+
+func joinRowNumbers(rows []int) string {
+	parts := make([]string, 0, min(len(rows), importRowsShown)+1)
+	for i, row := range rows {
+		if i == importRowsShown {
+			parts = append(parts, "…")
+			break
+		}
+		parts = append(parts, strconv.Itoa(row))
+	}
+	return strings.Join(parts, ", ")
+}
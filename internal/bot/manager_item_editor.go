@@ -0,0 +1,435 @@
+package bot
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"strings"
+
+	"bronivik/internal/models"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+const (
+	itemEditorPrefix     = "item_editor:"
+	itemEditorFieldPref  = "item_editor:field:"
+	itemEditorPhotosDone = "item_editor:photos_done"
+	itemEditorSave       = "item_editor:save"
+	itemEditorCancel     = "item_editor:cancel"
+
+	itemEditorDraftKey = "item_draft"
+	itemEditorFieldKey = "item_field"
+
+	// itemEditorSkip очищает необязательное поле или пропускает его при создании аппарата
+	itemEditorSkip = "-"
+	// itemMaxPhotos - сколько фото можно прикрепить к аппарату
+	itemMaxPhotos = 10
+
+	itemFieldName        = "name"
+	itemFieldQuantity    = "quantity"
+	itemFieldCategory    = "category"
+	itemFieldDescription = "description"
+	itemFieldPrice       = "price"
+	itemFieldWeekend     = "weekend_price"
+	itemFieldDeposit     = "deposit"
+	itemFieldDiscount    = "discount"
+	itemFieldSpecs       = "specs"
+	itemFieldPhotos      = "photos"
+)
+
+// itemEditorFields - поля аппарата в порядке, в котором их спрашивает мастер создания
+var itemEditorFields = []string{
+	itemFieldName, itemFieldQuantity, itemFieldCategory, itemFieldDescription,
+	itemFieldPrice, itemFieldWeekend, itemFieldDeposit, itemFieldDiscount, itemFieldSpecs, itemFieldPhotos,
+}
+
+var (
+	errItemFieldRequired = errors.New("item field is required")
+	errItemFieldInvalid  = errors.New("invalid item field value")
+)
+
+// startItemEditor открывает мастер аппарата. Новый аппарат заполняется по шагам,
+// существующий - через меню полей.
+func (b *Bot) startItemEditor(ctx context.Context, chatID, userID int64, item *models.Item) {
+	field := ""
+	if item.ID == 0 {
+		field = itemEditorFields[0]
+		if item.Name != "" {
+			field = itemEditorFields[1]
+		}
+	}
+	if !b.saveItemDraft(ctx, chatID, userID, item, field) {
+		return
+	}
+	if field == "" {
+		b.sendItemEditorMenu(ctx, chatID, item)
+		return
+	}
+	b.sendItemFieldPrompt(ctx, chatID, field)
+}
+
+// handleItemEditorCallback обрабатывает кнопки мастера аппарата
+func (b *Bot) handleItemEditorCallback(ctx context.Context, update *tgbotapi.Update, data string) bool {
+	if !strings.HasPrefix(data, itemEditorPrefix) {
+		return false
+	}
+
+	callback := update.CallbackQuery
+	chatID := callback.Message.Chat.ID
+	userID := callback.From.ID
+
+	if b.denyWithoutPermission(ctx, chatID, userID, models.PermManageItems) {
+		return true
+	}
+	if data == itemEditorCancel {
+		b.clearUserState(ctx, userID)
+		b.sendMessage(chatID, b.t(ctx, "item_editor.canceled"))
+		return true
+	}
+
+	state := b.getUserState(ctx, userID)
+	item, ok := b.loadItemDraft(state)
+	if !ok {
+		b.sendMessage(chatID, b.t(ctx, "error.session_expired"))
+		return true
+	}
+
+	switch {
+	case strings.HasPrefix(data, itemEditorFieldPref):
+		field := strings.TrimPrefix(data, itemEditorFieldPref)
+		if !isItemEditorField(field) {
+			return false
+		}
+		if b.saveItemDraft(ctx, chatID, userID, item, field) {
+			b.sendItemFieldPrompt(ctx, chatID, field)
+		}
+	case data == itemEditorPhotosDone:
+		b.advanceItemEditor(ctx, chatID, userID, item, itemFieldPhotos)
+	case data == itemEditorSave:
+		b.saveItemFromEditor(ctx, chatID, userID, item)
+	default:
+		return false
+	}
+	return true
+}
+
+// handleItemEditorInput принимает значение поля, которое запросил мастер аппарата
+func (b *Bot) handleItemEditorInput(ctx context.Context, update *tgbotapi.Update, text string, state *models.UserState) {
+	chatID := update.Message.Chat.ID
+	userID := update.Message.From.ID
+
+	if b.isButton(text, btnCancel) {
+		b.clearUserState(ctx, userID)
+		b.sendMessage(chatID, b.t(ctx, "item_editor.canceled"))
+		return
+	}
+
+	item, ok := b.loadItemDraft(state)
+	if !ok {
+		b.clearUserState(ctx, userID)
+		b.sendMessage(chatID, b.t(ctx, "error.session_expired"))
+		return
+	}
+
+	field := state.GetString(itemEditorFieldKey)
+	if field == "" {
+		b.sendItemEditorMenu(ctx, chatID, item)
+		return
+	}
+
+	if field == itemFieldPhotos {
+		b.handleItemPhotoInput(ctx, update, text, item)
+		return
+	}
+
+	value := b.sanitizeInput(text)
+	if field == itemFieldSpecs {
+		// Характеристики вводятся по одной на строку, поэтому переводы строк сохраняем
+		lines := strings.Split(text, "\n")
+		for i := range lines {
+			lines[i] = b.sanitizeInput(lines[i])
+		}
+		value = strings.Join(lines, "\n")
+	}
+	if err := applyItemField(item, field, value); err != nil {
+		b.sendMessage(chatID, b.t(ctx, "item_editor.invalid_"+field))
+		return
+	}
+	b.advanceItemEditor(ctx, chatID, userID, item, field)
+}
+
+// handleItemPhotoInput добавляет присланное фото к аппарату; «-» удаляет все фото
+func (b *Bot) handleItemPhotoInput(ctx context.Context, update *tgbotapi.Update, text string, item *models.Item) {
+	chatID := update.Message.Chat.ID
+	userID := update.Message.From.ID
+
+	switch {
+	case len(update.Message.Photo) > 0:
+		if len(item.PhotoFileIDs) >= itemMaxPhotos {
+			b.sendMessage(chatID, b.t(ctx, "item_editor.photos_limit", itemMaxPhotos))
+			return
+		}
+		// Telegram присылает фото в нескольких размерах, последнее - самое крупное
+		item.PhotoFileIDs = append(item.PhotoFileIDs, update.Message.Photo[len(update.Message.Photo)-1].FileID)
+	case strings.TrimSpace(text) == itemEditorSkip:
+		item.PhotoFileIDs = nil
+	default:
+		b.sendMessage(chatID, b.t(ctx, "item_editor.invalid_photos"))
+		return
+	}
+
+	if b.saveItemDraft(ctx, chatID, userID, item, itemFieldPhotos) {
+		b.sendItemPhotosDoneButton(ctx, chatID, b.t(ctx, "item_editor.photos_noted", len(item.PhotoFileIDs)))
+	}
+}
+
+// advanceItemEditor переходит к следующему шагу мастера создания или возвращает в меню полей
+func (b *Bot) advanceItemEditor(ctx context.Context, chatID, userID int64, item *models.Item, field string) {
+	next := ""
+	if item.ID == 0 {
+		for i, f := range itemEditorFields {
+			if f == field && i+1 < len(itemEditorFields) {
+				next = itemEditorFields[i+1]
+			}
+		}
+		// Тариф выходного дня и скидка меняют суточную цену, без нее их не спрашиваем
+		for item.PricePerDay == 0 && (next == itemFieldWeekend || next == itemFieldDiscount) {
+			field = next
+			for i, f := range itemEditorFields {
+				if f == field {
+					next = itemEditorFields[i+1]
+				}
+			}
+		}
+	}
+	if !b.saveItemDraft(ctx, chatID, userID, item, next) {
+		return
+	}
+	if next == "" {
+		b.sendItemEditorMenu(ctx, chatID, item)
+		return
+	}
+	b.sendItemFieldPrompt(ctx, chatID, next)
+}
+
+// saveItemFromEditor сохраняет аппарат из мастера: создает новый или обновляет существующий
+func (b *Bot) saveItemFromEditor(ctx context.Context, chatID, userID int64, item *models.Item) {
+	if item.Name == "" || item.TotalQuantity <= 0 {
+		b.sendMessage(chatID, b.t(ctx, "item_editor.incomplete"))
+		return
+	}
+
+	var err error
+	created := item.ID == 0
+	if created {
+		item.IsActive = true
+		err = b.itemService.CreateItem(ctx, item)
+	} else {
+		if b.denyWithoutItemAccess(ctx, chatID, userID, item.ID) {
+			return
+		}
+		err = b.itemService.UpdateItem(ctx, item)
+	}
+	if err != nil {
+		b.logger.Error().Err(err).Int64("manager_id", userID).Str("item", item.Name).Msg("Error saving item")
+		b.sendMessage(chatID, b.t(ctx, "item_editor.save_error", err.Error()))
+		return
+	}
+	b.clearUserState(ctx, userID)
+
+	b.logger.Info().
+		Int64("manager_id", userID).
+		Int64("item_id", item.ID).
+		Bool("created", created).
+		Msg("Item saved from editor")
+
+	key := "item_editor.updated"
+	if created {
+		key = "item_editor.created"
+	}
+	b.sendMessage(chatID, b.t(ctx, key, item.Name, item.ID))
+}
+
+// sendItemEditorMenu показывает текущие значения аппарата и кнопки полей
+func (b *Bot) sendItemEditorMenu(ctx context.Context, chatID int64, item *models.Item) {
+	var sb strings.Builder
+	sb.WriteString(b.itemCardText(ctx, item))
+	sb.WriteString("\n\n")
+	sb.WriteString(b.t(ctx, "item_editor.menu_summary", item.TotalQuantity, len(item.PhotoFileIDs)))
+	sb.WriteString("\n\n")
+	sb.WriteString(b.t(ctx, "item_editor.menu_hint"))
+
+	rows := make([][]tgbotapi.InlineKeyboardButton, 0, len(itemEditorFields)/2+2)
+	var row []tgbotapi.InlineKeyboardButton
+	for _, field := range itemEditorFields {
+		row = append(row, tgbotapi.NewInlineKeyboardButtonData(b.t(ctx, "item_editor.field_"+field), itemEditorFieldPref+field))
+		if len(row) == 2 {
+			rows = append(rows, row)
+			row = nil
+		}
+	}
+	if len(row) > 0 {
+		rows = append(rows, row)
+	}
+	rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData(b.t(ctx, "btn.item_save"), itemEditorSave),
+		tgbotapi.NewInlineKeyboardButtonData(b.t(ctx, "btn.cancel"), itemEditorCancel),
+	))
+
+	if _, err := b.tgService.SendWithInlineKeyboard(chatID, sb.String(), tgbotapi.NewInlineKeyboardMarkup(rows...)); err != nil {
+		b.logger.Error().Err(err).Int64("chat_id", chatID).Msg("Failed to send item editor menu")
+	}
+}
+
+// sendItemFieldPrompt просит ввести значение поля
+func (b *Bot) sendItemFieldPrompt(ctx context.Context, chatID int64, field string) {
+	prompt := b.t(ctx, "item_editor.prompt_"+field)
+	if field == itemFieldPhotos {
+		b.sendItemPhotosDoneButton(ctx, chatID, prompt)
+		return
+	}
+	b.sendMessage(chatID, prompt)
+}
+
+func (b *Bot) sendItemPhotosDoneButton(ctx context.Context, chatID int64, text string) {
+	keyboard := tgbotapi.NewInlineKeyboardMarkup(tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData(b.t(ctx, "btn.handover_done"), itemEditorPhotosDone),
+	))
+	if _, err := b.tgService.SendWithInlineKeyboard(chatID, text, keyboard); err != nil {
+		b.logger.Error().Err(err).Int64("chat_id", chatID).Msg("Failed to send item photos prompt")
+	}
+}
+
+// saveItemDraft сохраняет черновик аппарата и ожидаемое поле в состоянии менеджера
+func (b *Bot) saveItemDraft(ctx context.Context, chatID, userID int64, item *models.Item, field string) bool {
+	draft, err := json.Marshal(item)
+	if err != nil {
+		b.logger.Error().Err(err).Int64("manager_id", userID).Msg("Failed to encode item draft")
+		b.sendMessage(chatID, b.t(ctx, "error.default"))
+		return false
+	}
+	b.setUserState(ctx, userID, models.StateManagerItemEditor, map[string]interface{}{
+		itemEditorDraftKey: string(draft),
+		itemEditorFieldKey: field,
+	})
+	return true
+}
+
+func (b *Bot) loadItemDraft(state *models.UserState) (*models.Item, bool) {
+	if state == nil || state.CurrentStep != models.StateManagerItemEditor {
+		return nil, false
+	}
+	var item models.Item
+	if err := json.Unmarshal([]byte(state.GetString(itemEditorDraftKey)), &item); err != nil {
+		b.logger.Error().Err(err).Int64("manager_id", state.UserID).Msg("Failed to decode item draft")
+		return nil, false
+	}
+	return &item, true
+}
+
+func isItemEditorField(field string) bool {
+	for _, f := range itemEditorFields {
+		if f == field {
+			return true
+		}
+	}
+	return false
+}
+
+// applyItemField записывает в аппарат введенное менеджером значение поля
+func applyItemField(item *models.Item, field, value string) error {
+	skip := value == itemEditorSkip
+	switch field {
+	case itemFieldName:
+		if value == "" || skip {
+			return errItemFieldRequired
+		}
+		item.Name = value
+	case itemFieldQuantity:
+		qty, err := strconv.ParseInt(value, 10, 64)
+		if err != nil || qty <= 0 {
+			return errItemFieldInvalid
+		}
+		item.TotalQuantity = qty
+	case itemFieldCategory:
+		item.Category = ""
+		if !skip {
+			item.Category = models.NormalizeCategory(value)
+		}
+	case itemFieldDescription:
+		item.Description = ""
+		if !skip {
+			item.Description = value
+		}
+	case itemFieldPrice, itemFieldWeekend, itemFieldDeposit:
+		price, err := parseItemAmount(value)
+		if err != nil {
+			return err
+		}
+		switch field {
+		case itemFieldPrice:
+			item.PricePerDay = price
+		case itemFieldWeekend:
+			item.WeekendPrice = price
+		default:
+			item.Deposit = price
+		}
+	case itemFieldDiscount:
+		item.LongRentalDays, item.LongRentalDiscount = 0, 0
+		if !skip {
+			days, percent, ok := strings.Cut(value, " ")
+			d, err1 := strconv.ParseInt(strings.TrimSpace(days), 10, 64)
+			p, err2 := strconv.ParseInt(strings.TrimSuffix(strings.TrimSpace(percent), "%"), 10, 64)
+			if !ok || err1 != nil || err2 != nil || d < 2 || p <= 0 || p > 100 {
+				return errItemFieldInvalid
+			}
+			item.LongRentalDays, item.LongRentalDiscount = d, p
+		}
+	case itemFieldSpecs:
+		specs, err := parseItemSpecs(value)
+		if err != nil {
+			return err
+		}
+		item.Specs = specs
+	default:
+		return errItemFieldInvalid
+	}
+	return nil
+}
+
+// parseItemAmount разбирает сумму в рублях; «-» означает, что сумма не указана
+func parseItemAmount(value string) (int64, error) {
+	if value == itemEditorSkip {
+		return 0, nil
+	}
+	amount, err := strconv.ParseInt(strings.ReplaceAll(value, " ", ""), 10, 64)
+	if err != nil || amount < 0 {
+		return 0, errItemFieldInvalid
+	}
+	return amount, nil
+}
+
+// parseItemSpecs разбирает характеристики вида «Название: значение», по одной на строку
+func parseItemSpecs(text string) ([]models.ItemSpec, error) {
+	if text == itemEditorSkip {
+		return nil, nil
+	}
+	var specs []models.ItemSpec
+	for _, line := range strings.Split(text, "\n") {
+		if line = strings.TrimSpace(line); line == "" {
+			continue
+		}
+		name, value, ok := strings.Cut(line, ":")
+		name, value = strings.TrimSpace(name), strings.TrimSpace(value)
+		if !ok || name == "" || value == "" {
+			return nil, errItemFieldInvalid
+		}
+		specs = append(specs, models.ItemSpec{Name: name, Value: value})
+	}
+	if len(specs) == 0 {
+		return nil, errItemFieldInvalid
+	}
+	return specs, nil
+}
@@ -2,7 +2,6 @@ package bot
 
 import (
 	"context"
-	"fmt"
 	"strconv"
 	"strings"
 
@@ -11,76 +10,56 @@ import (
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
 )
 
+// handleAddItemCommand запускает мастер создания аппарата: /add_item [название]
 func (b *Bot) handleAddItemCommand(ctx context.Context, update *tgbotapi.Update) {
 	parts := strings.Fields(update.Message.Text)
-	if len(parts) < 3 {
-		b.sendMessage(update.Message.Chat.ID, "Использование: /add_item <название> <количество>")
-		return
-	}
-
-	qty, err := strconv.ParseInt(parts[len(parts)-1], 10, 64)
-	if err != nil || qty <= 0 {
-		b.sendMessage(update.Message.Chat.ID, "Количество должно быть положительным числом")
-		return
-	}
-
-	name := b.sanitizeInput(strings.Join(parts[1:len(parts)-1], " "))
-	item := &models.Item{Name: name, TotalQuantity: qty}
-	if err := b.itemService.CreateItem(ctx, item); err != nil {
-		b.sendMessage(update.Message.Chat.ID, fmt.Sprintf("Не удалось создать аппарат: %v", err))
-		return
-	}
-
-	b.sendMessage(update.Message.Chat.ID,
-		fmt.Sprintf("✅ Аппарат '%s' добавлен (кол-во: %d, порядок: %d)",
-			item.Name, item.TotalQuantity, item.SortOrder))
+	item := &models.Item{Name: b.sanitizeInput(strings.Join(parts[1:], " "))}
+	b.startItemEditor(ctx, update.Message.Chat.ID, update.Message.From.ID, item)
 }
 
+// handleEditItemCommand открывает мастер редактирования аппарата: /edit_item <название или ID>
 func (b *Bot) handleEditItemCommand(ctx context.Context, update *tgbotapi.Update) {
+	chatID := update.Message.Chat.ID
 	parts := strings.Fields(update.Message.Text)
-	if len(parts) < 3 {
-		b.sendMessage(update.Message.Chat.ID, "Использование: /edit_item <название> <новое_количество>")
+	if len(parts) < 2 {
+		b.sendMessage(chatID, b.t(ctx, "item_editor.edit_usage"))
 		return
 	}
 
-	qty, err := strconv.ParseInt(parts[len(parts)-1], 10, 64)
-	if err != nil || qty <= 0 {
-		b.sendMessage(update.Message.Chat.ID, "Количество должно быть положительным числом")
-		return
+	name := b.sanitizeInput(strings.Join(parts[1:], " "))
+	item, err := b.itemService.GetItemByName(ctx, name)
+	if err != nil || item == nil {
+		if id, parseErr := strconv.ParseInt(name, 10, 64); parseErr == nil {
+			item, err = b.itemService.GetItemByID(ctx, id)
+		}
 	}
-
-	name := b.sanitizeInput(strings.Join(parts[1:len(parts)-1], " "))
-	current, err := b.itemService.GetItemByName(ctx, name)
-	if err != nil {
-		b.sendMessage(update.Message.Chat.ID, fmt.Sprintf("Аппарат '%s' не найден", name))
+	if err != nil || item == nil {
+		b.sendMessage(chatID, b.t(ctx, "items.not_found_name", name))
 		return
 	}
-
-	current.TotalQuantity = qty
-	if err := b.itemService.UpdateItem(ctx, current); err != nil {
-		b.sendMessage(update.Message.Chat.ID, fmt.Sprintf("Не удалось обновить аппарат: %v", err))
+	if b.denyWithoutItemAccess(ctx, chatID, update.Message.From.ID, item.ID) {
 		return
 	}
 
-	b.sendMessage(update.Message.Chat.ID, fmt.Sprintf("✅ Аппарат '%s' обновлён (кол-во: %d)", current.Name, current.TotalQuantity))
+	b.startItemEditor(ctx, chatID, update.Message.From.ID, item)
 }
 
 func (b *Bot) handleListItemsCommand(ctx context.Context, update *tgbotapi.Update) {
 	items, err := b.itemService.GetActiveItems(ctx)
 	if err != nil {
-		b.sendMessage(update.Message.Chat.ID, fmt.Sprintf("Ошибка загрузки списка: %v", err))
+		b.sendMessage(update.Message.Chat.ID, b.t(ctx, "items.list_error", err))
 		return
 	}
 
 	if len(items) == 0 {
-		b.sendMessage(update.Message.Chat.ID, "Активные аппараты отсутствуют")
+		b.sendMessage(update.Message.Chat.ID, b.t(ctx, "items.no_active"))
 		return
 	}
 
 	var sb strings.Builder
-	sb.WriteString("📋 Список активных аппаратов:\n")
+	sb.WriteString(b.t(ctx, "items.active_title") + "\n")
 	for _, it := range items {
-		sb.WriteString(fmt.Sprintf("• %s — qty: %d, order: %d\n", it.Name, it.TotalQuantity, it.SortOrder))
+		sb.WriteString(b.t(ctx, "items.active_entry", it.Name, it.TotalQuantity, it.SortOrder) + "\n")
 	}
 
 	b.sendMessage(update.Message.Chat.ID, sb.String())
@@ -89,64 +68,64 @@ func (b *Bot) handleListItemsCommand(ctx context.Context, update *tgbotapi.Updat
 func (b *Bot) handleDisableItemCommand(ctx context.Context, update *tgbotapi.Update) {
 	parts := strings.Fields(update.Message.Text)
 	if len(parts) < 2 {
-		b.sendMessage(update.Message.Chat.ID, "Использование: /disable_item <название>")
+		b.sendMessage(update.Message.Chat.ID, b.t(ctx, "items.disable_usage"))
 		return
 	}
 
 	name := b.sanitizeInput(strings.Join(parts[1:], " "))
 	item, err := b.itemService.GetItemByName(ctx, name)
 	if err != nil {
-		b.sendMessage(update.Message.Chat.ID, fmt.Sprintf("Аппарат '%s' не найден", name))
+		b.sendMessage(update.Message.Chat.ID, b.t(ctx, "items.not_found_name", name))
 		return
 	}
 
 	if err := b.itemService.DeactivateItem(ctx, item.ID); err != nil {
-		b.sendMessage(update.Message.Chat.ID, fmt.Sprintf("Не удалось отключить аппарат: %v", err))
+		b.sendMessage(update.Message.Chat.ID, b.t(ctx, "items.disable_failed", err))
 		return
 	}
 
-	b.sendMessage(update.Message.Chat.ID, fmt.Sprintf("🛑 Аппарат '%s' деактивирован", item.Name))
+	b.sendMessage(update.Message.Chat.ID, b.t(ctx, "items.disabled", item.Name))
 }
 
 func (b *Bot) handleSetItemOrderCommand(ctx context.Context, update *tgbotapi.Update) {
 	parts := strings.Fields(update.Message.Text)
 	if len(parts) < 3 {
-		b.sendMessage(update.Message.Chat.ID, "Использование: /set_item_order <название> <порядок>")
+		b.sendMessage(update.Message.Chat.ID, b.t(ctx, "items.order_usage"))
 		return
 	}
 
 	order, err := strconv.ParseInt(parts[len(parts)-1], 10, 64)
 	if err != nil || order < 1 {
-		b.sendMessage(update.Message.Chat.ID, "Порядок должен быть положительным числом")
+		b.sendMessage(update.Message.Chat.ID, b.t(ctx, "items.order_invalid"))
 		return
 	}
 
 	name := b.sanitizeInput(strings.Join(parts[1:len(parts)-1], " "))
 	item, err := b.itemService.GetItemByName(ctx, name)
 	if err != nil {
-		b.sendMessage(update.Message.Chat.ID, fmt.Sprintf("Аппарат '%s' не найден", name))
+		b.sendMessage(update.Message.Chat.ID, b.t(ctx, "items.not_found_name", name))
 		return
 	}
 
 	if err := b.itemService.ReorderItem(ctx, item.ID, order); err != nil {
-		b.sendMessage(update.Message.Chat.ID, fmt.Sprintf("Не удалось изменить порядок: %v", err))
+		b.sendMessage(update.Message.Chat.ID, b.t(ctx, "items.order_failed", err))
 		return
 	}
 
-	b.sendMessage(update.Message.Chat.ID, fmt.Sprintf("↕️ Порядок '%s' установлен на %d", item.Name, order))
+	b.sendMessage(update.Message.Chat.ID, b.t(ctx, "items.order_set", item.Name, order))
 }
 
 func (b *Bot) handleMoveItemCommand(ctx context.Context, update *tgbotapi.Update, delta int64) {
 	parts := strings.Fields(update.Message.Text)
 	if len(parts) < 2 {
-		b.sendMessage(update.Message.Chat.ID, "Использование: /move_item_up|/move_item_down <название>")
+		b.sendMessage(update.Message.Chat.ID, b.t(ctx, "items.move_usage"))
 		return
 	}
 
 	name := strings.Join(parts[1:], " ")
 	item, err := b.itemService.GetItemByName(ctx, name)
 	if err != nil {
-		b.sendMessage(update.Message.Chat.ID, fmt.Sprintf("Аппарат '%s' не найден", name))
+		b.sendMessage(update.Message.Chat.ID, b.t(ctx, "items.not_found_name", name))
 		return
 	}
 
@@ -156,21 +135,21 @@ func (b *Bot) handleMoveItemCommand(ctx context.Context, update *tgbotapi.Update
 	}
 
 	if err := b.itemService.ReorderItem(ctx, item.ID, newOrder); err != nil {
-		b.sendMessage(update.Message.Chat.ID, fmt.Sprintf("Не удалось изменить порядок: %v", err))
+		b.sendMessage(update.Message.Chat.ID, b.t(ctx, "items.order_failed", err))
 		return
 	}
 
-	direction := "вверх"
+	key := "items.moved_up"
 	if delta > 0 {
-		direction = "вниз"
+		key = "items.moved_down"
 	}
-	b.sendMessage(update.Message.Chat.ID, fmt.Sprintf("↕️ Аппарат '%s' перемещён %s (новый порядок: %d)", item.Name, direction, newOrder))
+	b.sendMessage(update.Message.Chat.ID, b.t(ctx, key, item.Name, newOrder))
 }
 
 // editManagerItemsPage редактирует страницу с аппаратами для менеджера
-func (b *Bot) editManagerItemsPage(update *tgbotapi.Update, page int) {
+func (b *Bot) editManagerItemsPage(ctx context.Context, update *tgbotapi.Update, page int) {
 	callback := update.CallbackQuery
-	b.sendManagerItemsPage(context.Background(), callback.Message.Chat.ID, callback.Message.MessageID, page)
+	b.sendManagerItemsPage(ctx, callback.Message.Chat.ID, callback.Message.MessageID, page)
 	if _, err := b.tgService.Send(tgbotapi.NewCallback(callback.ID, "")); err != nil {
 		b.logger.Error().Err(err).Msg("Failed to send callback in editManagerItemsPage")
 	}
@@ -0,0 +1,229 @@
+package bot
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"time"
+
+	"bronivik/internal/models"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// maintenanceListDays - на сколько дней вперед /maintenance показывает окна обслуживания
+const maintenanceListDays = 365
+
+// handleManagerMaintenanceCommands обрабатывает /maintenance, /maintenance_add и /maintenance_end
+func (b *Bot) handleManagerMaintenanceCommands(ctx context.Context, update *tgbotapi.Update, text string) bool {
+	fields := strings.Fields(text)
+	if len(fields) == 0 {
+		return false
+	}
+
+	var handler func(context.Context, *tgbotapi.Update, []string)
+	perm := models.PermManageItems
+
+	switch fields[0] {
+	case "/maintenance":
+		handler = b.handleListMaintenanceCommand
+		perm = models.PermViewBookings
+	case "/maintenance_add":
+		handler = b.handleAddMaintenanceCommand
+	case "/maintenance_end":
+		handler = b.handleEndMaintenanceCommand
+	default:
+		return false
+	}
+
+	if !b.denyWithoutPermission(ctx, update.Message.Chat.ID, update.Message.From.ID, perm) {
+		handler(ctx, update, fields[1:])
+	}
+	return true
+}
+
+// handleListMaintenanceCommand показывает текущие и будущие окна обслуживания доступных менеджеру аппаратов
+func (b *Bot) handleListMaintenanceCommand(ctx context.Context, update *tgbotapi.Update, _ []string) {
+	chatID := update.Message.Chat.ID
+	userID := update.Message.From.ID
+
+	today := time.Now().Truncate(24 * time.Hour)
+	windows, err := b.itemService.GetMaintenanceWindows(ctx, 0, today, today.AddDate(0, 0, maintenanceListDays))
+	if err != nil {
+		b.logger.Error().Err(err).Msg("Error listing maintenance windows")
+		b.sendMessage(chatID, b.t(ctx, "error.default"))
+		return
+	}
+
+	var sb strings.Builder
+	sb.WriteString(b.t(ctx, "maintenance.list_title"))
+	sb.WriteString("\n\n")
+	shown := 0
+	for _, w := range windows {
+		if !b.canManageItem(userID, w.ItemID) {
+			continue
+		}
+		shown++
+		sb.WriteString(b.t(ctx, "maintenance.list_line", w.ID, w.ItemName, w.Units,
+			w.StartDate.Format("02.01.2006"), w.EndDate.Format("02.01.2006")))
+		sb.WriteString("\n")
+		if w.IsExternalHold() {
+			sb.WriteString(b.t(ctx, "maintenance.external", w.Source))
+			sb.WriteString("\n")
+		}
+		if w.Reason != "" {
+			sb.WriteString(b.t(ctx, "maintenance.reason", w.Reason))
+			sb.WriteString("\n")
+		}
+	}
+	if shown == 0 {
+		sb.WriteString(b.t(ctx, "maintenance.list_empty"))
+		sb.WriteString("\n")
+	}
+	sb.WriteString("\n")
+	sb.WriteString(b.t(ctx, "maintenance.usage"))
+	b.sendMessage(chatID, sb.String())
+}
+
+// handleAddMaintenanceCommand выводит аппараты из работы:
+// /maintenance_add <ID аппарата> <кол-во> <ДД.ММ.ГГГГ> [ДД.ММ.ГГГГ] [причина]
+func (b *Bot) handleAddMaintenanceCommand(ctx context.Context, update *tgbotapi.Update, args []string) {
+	chatID := update.Message.Chat.ID
+	managerID := update.Message.From.ID
+	if len(args) < 3 {
+		b.sendMessage(chatID, b.t(ctx, "maintenance.add_usage"))
+		return
+	}
+
+	itemID, errItem := strconv.ParseInt(args[0], 10, 64)
+	units, errUnits := strconv.ParseInt(args[1], 10, 64)
+	start, errStart := time.Parse("02.01.2006", args[2])
+	if errItem != nil || errUnits != nil || errStart != nil {
+		b.sendMessage(chatID, b.t(ctx, "maintenance.add_usage"))
+		return
+	}
+	end := start
+	rest := args[3:]
+	if len(rest) > 0 {
+		if date, err := time.Parse("02.01.2006", rest[0]); err == nil {
+			end = date
+			rest = rest[1:]
+		}
+	}
+
+	if b.denyWithoutItemAccess(ctx, chatID, managerID, itemID) {
+		return
+	}
+	item, err := b.itemService.GetItemByID(ctx, itemID)
+	if err != nil {
+		b.sendMessage(chatID, b.t(ctx, "error.item_not_found"))
+		return
+	}
+
+	window := &models.MaintenanceWindow{
+		ItemID:    item.ID,
+		ItemName:  item.Name,
+		Units:     units,
+		StartDate: start,
+		EndDate:   end,
+		Reason:    b.sanitizeInput(strings.Join(rest, " ")),
+		CreatedBy: managerID,
+	}
+	overflow, err := b.itemService.AddMaintenance(ctx, window)
+	if err != nil {
+		b.sendMessage(chatID, b.getErrorMessage(ctx, err))
+		return
+	}
+
+	b.logger.Info().
+		Int64("manager_id", managerID).
+		Int64("item_id", item.ID).
+		Int64("units", units).
+		Time("start", start).
+		Time("end", end).
+		Msg("Maintenance window added")
+
+	b.sendMessage(chatID, b.t(ctx, "maintenance.added", item.Name, units,
+		start.Format("02.01.2006"), end.Format("02.01.2006"), window.ID))
+
+	if len(overflow) > 0 {
+		b.warnMaintenanceOverflow(ctx, item, managerID, overflow)
+	}
+}
+
+// handleEndMaintenanceCommand возвращает аппараты окна в работу: /maintenance_end <ID окна>
+func (b *Bot) handleEndMaintenanceCommand(ctx context.Context, update *tgbotapi.Update, args []string) {
+	chatID := update.Message.Chat.ID
+	managerID := update.Message.From.ID
+	if len(args) != 1 {
+		b.sendMessage(chatID, b.t(ctx, "maintenance.end_usage"))
+		return
+	}
+	id, err := strconv.ParseInt(args[0], 10, 64)
+	if err != nil {
+		b.sendMessage(chatID, b.t(ctx, "maintenance.end_usage"))
+		return
+	}
+
+	window, err := b.itemService.GetMaintenance(ctx, id)
+	if err != nil {
+		b.sendMessage(chatID, b.getErrorMessage(ctx, err))
+		return
+	}
+	if b.denyWithoutItemAccess(ctx, chatID, managerID, window.ItemID) {
+		return
+	}
+
+	if _, err := b.itemService.EndMaintenance(ctx, id, managerID); err != nil {
+		b.sendMessage(chatID, b.getErrorMessage(ctx, err))
+		return
+	}
+
+	b.logger.Info().Int64("manager_id", managerID).Int64("maintenance_id", id).Msg("Maintenance window ended")
+	b.sendMessage(chatID, b.t(ctx, "maintenance.ended", window.ItemName, window.ID))
+}
+
+// warnMaintenanceOverflow сообщает менеджерам аппарата о днях, где заявок стало больше, чем аппаратов в работе
+func (b *Bot) warnMaintenanceOverflow(
+	ctx context.Context,
+	item *models.Item,
+	managerID int64,
+	overflow []*models.MaintenanceOverflow,
+) {
+	recipients := b.userService.GetStaffForItem(item.ID, models.PermManageBookings)
+	found := false
+	for _, id := range recipients {
+		found = found || id == managerID
+	}
+	if !found {
+		recipients = append(recipients, managerID)
+	}
+	b.sendOverflowWarning(ctx, item, recipients, "maintenance.overflow", overflow)
+}
+
+// sendOverflowWarning рассылает список дней, где заявок больше, чем аппаратов в работе;
+// titleKey - заголовок с причиной нехватки аппаратов
+func (b *Bot) sendOverflowWarning(
+	ctx context.Context,
+	item *models.Item,
+	recipients []int64,
+	titleKey string,
+	overflow []*models.MaintenanceOverflow,
+) {
+	for _, recipient := range recipients {
+		recipientCtx := b.withUserLanguage(ctx, recipient)
+		var sb strings.Builder
+		sb.WriteString(b.t(recipientCtx, titleKey, item.Name))
+		for _, day := range overflow {
+			sb.WriteString("\n\n")
+			sb.WriteString(b.t(recipientCtx, "maintenance.overflow_day",
+				day.Date.Format("02.01.2006"), day.Capacity, len(day.Bookings)))
+			for _, booking := range day.Bookings {
+				sb.WriteString("\n")
+				sb.WriteString(b.t(recipientCtx, "maintenance.overflow_booking",
+					booking.ID, booking.UserName, b.statusName(recipientCtx, booking.Status)))
+			}
+		}
+		b.sendMessage(recipient, sb.String())
+	}
+}
@@ -0,0 +1,160 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"bronivik/internal/models"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// handleManagerRoleCommands обрабатывает команды управления ролями (только для администраторов)
+func (b *Bot) handleManagerRoleCommands(ctx context.Context, update *tgbotapi.Update, text string) bool {
+	var handler func(context.Context, *tgbotapi.Update)
+
+	switch {
+	case text == "/roles":
+		handler = b.handleListRolesCommand
+	case strings.HasPrefix(text, "/set_role"):
+		handler = b.handleSetRoleCommand
+	case strings.HasPrefix(text, "/remove_role"):
+		handler = b.handleRemoveRoleCommand
+	default:
+		return false
+	}
+
+	if !b.denyWithoutPermission(ctx, update.Message.Chat.ID, update.Message.From.ID, models.PermManageRoles) {
+		handler(ctx, update)
+	}
+	return true
+}
+
+// handleListRolesCommand показывает администраторов из конфига и роли из БД
+func (b *Bot) handleListRolesCommand(ctx context.Context, update *tgbotapi.Update) {
+	roles, err := b.userService.ListUserRoles(ctx)
+	if err != nil {
+		b.logger.Error().Err(err).Msg("Error listing roles")
+		b.sendMessage(update.Message.Chat.ID, b.t(ctx, "error.roles_list"))
+		return
+	}
+
+	var sb strings.Builder
+	sb.WriteString(b.t(ctx, "roles.title") + "\n\n")
+	for _, id := range b.config.Managers {
+		sb.WriteString(b.t(ctx, "roles.from_config", id, b.roleName(ctx, models.RoleAdmin)) + "\n")
+	}
+	for _, r := range roles {
+		sb.WriteString(b.t(ctx, "roles.entry", r.TelegramID, b.roleName(ctx, r.Role), b.formatRoleScope(r)) + "\n")
+	}
+
+	sb.WriteString("\n" + b.t(ctx, "roles.help"))
+	b.sendMessage(update.Message.Chat.ID, sb.String())
+}
+
+// roleName возвращает название роли на языке пользователя
+func (b *Bot) roleName(ctx context.Context, role string) string {
+	return b.t(ctx, "role_name."+role)
+}
+
+func (b *Bot) formatRoleScope(r *models.UserRole) string {
+	if r.Role == models.RoleAdmin || len(r.ItemIDs) == 0 {
+		return ""
+	}
+	names := make([]string, 0, len(r.ItemIDs))
+	for _, id := range r.ItemIDs {
+		if item, ok := b.getItemByID(id); ok {
+			names = append(names, item.Name)
+		} else {
+			names = append(names, fmt.Sprintf("#%d", id))
+		}
+	}
+	return " [" + strings.Join(names, ", ") + "]"
+}
+
+// handleSetRoleCommand назначает роль: /set_role <telegram_id> <role> [item_ids]
+func (b *Bot) handleSetRoleCommand(ctx context.Context, update *tgbotapi.Update) {
+	chatID := update.Message.Chat.ID
+	parts := strings.Fields(update.Message.Text)
+	if len(parts) < 3 {
+		b.sendMessage(chatID, b.t(ctx, "roles.set_usage"))
+		return
+	}
+
+	telegramID, err := strconv.ParseInt(parts[1], 10, 64)
+	if err != nil || telegramID <= 0 {
+		b.sendMessage(chatID, b.t(ctx, "error.invalid_telegram_id"))
+		return
+	}
+
+	role := strings.ToLower(parts[2])
+	if !models.IsValidRole(role) {
+		b.sendMessage(chatID, b.t(ctx, "error.invalid_role"))
+		return
+	}
+
+	var itemIDs []int64
+	if len(parts) > 3 {
+		for _, raw := range strings.Split(strings.Join(parts[3:], ""), ",") {
+			if raw == "" {
+				continue
+			}
+			id, errParse := strconv.ParseInt(raw, 10, 64)
+			if errParse != nil {
+				b.sendMessage(chatID, b.t(ctx, "roles.invalid_item_id", raw))
+				return
+			}
+			if _, ok := b.getItemByID(id); !ok {
+				b.sendMessage(chatID, b.t(ctx, "roles.item_not_found", id))
+				return
+			}
+			itemIDs = append(itemIDs, id)
+		}
+	}
+
+	userRole := &models.UserRole{
+		TelegramID: telegramID,
+		Role:       role,
+		ItemIDs:    itemIDs,
+		GrantedBy:  update.Message.From.ID,
+	}
+	if err := b.userService.SetUserRole(ctx, userRole); err != nil {
+		b.sendMessage(chatID, b.t(ctx, "roles.set_failed", b.getErrorMessage(ctx, err)))
+		return
+	}
+
+	b.logger.Info().
+		Int64("admin_id", update.Message.From.ID).
+		Int64("user_id", telegramID).
+		Str("role", role).
+		Ints64("item_ids", itemIDs).
+		Msg("Role assigned")
+
+	b.sendMessage(chatID, b.t(ctx, "roles.assigned", telegramID, b.roleName(ctx, role), b.formatRoleScope(userRole)))
+}
+
+// handleRemoveRoleCommand снимает роль: /remove_role <telegram_id>
+func (b *Bot) handleRemoveRoleCommand(ctx context.Context, update *tgbotapi.Update) {
+	chatID := update.Message.Chat.ID
+	parts := strings.Fields(update.Message.Text)
+	if len(parts) != 2 {
+		b.sendMessage(chatID, b.t(ctx, "roles.remove_usage"))
+		return
+	}
+
+	telegramID, err := strconv.ParseInt(parts[1], 10, 64)
+	if err != nil || telegramID <= 0 {
+		b.sendMessage(chatID, b.t(ctx, "error.invalid_telegram_id"))
+		return
+	}
+
+	if err := b.userService.RemoveUserRole(ctx, telegramID); err != nil {
+		b.sendMessage(chatID, b.t(ctx, "roles.remove_failed", b.getErrorMessage(ctx, err)))
+		return
+	}
+
+	b.logger.Info().Int64("admin_id", update.Message.From.ID).Int64("user_id", telegramID).Msg("Role removed")
+	b.sendMessage(chatID, b.t(ctx, "roles.removed", telegramID))
+}
@@ -0,0 +1,373 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"bronivik/internal/models"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+const (
+	searchPagePrefix   = "search_page:"
+	searchStatusPrefix = "search_status:"
+	searchPeriodPrefix = "search_period:"
+	searchItemPrefix   = "search_item:"
+	searchItemsMenu    = "search_items"
+	searchReset        = "search_reset"
+
+	searchStateKey = "search"
+)
+
+// searchStatuses - статусы, доступные в фильтре, и их значки
+var searchStatuses = []struct {
+	status string
+	icon   string
+}{
+	{models.StatusPending, statusPending},
+	{models.StatusConfirmed, statusSuccess},
+	{models.StatusCanceled, statusError},
+	{models.StatusCompleted, "🏁"},
+}
+
+var searchDateRangePattern = regexp.MustCompile(`^(\d{2}\.\d{2}\.\d{4})\s*-\s*(\d{2}\.\d{2}\.\d{4})$`)
+
+// handleManagerSearchCommands обрабатывает /search, кнопку поиска и текст запроса в режиме поиска
+func (b *Bot) handleManagerSearchCommands(ctx context.Context, update *tgbotapi.Update, text string, state *models.UserState) bool {
+	chatID := update.Message.Chat.ID
+	userID := update.Message.From.ID
+
+	switch {
+	case text == "/search" || strings.HasPrefix(text, "/search ") || b.isButton(text, btnSearchBookings):
+		if b.denyWithoutPermission(ctx, chatID, userID, models.PermViewBookings) {
+			return true
+		}
+		query := strings.TrimSpace(strings.TrimPrefix(text, "/search"))
+		if b.isButton(text, btnSearchBookings) || query == "" {
+			b.setUserState(ctx, userID, models.StateManagerSearch, map[string]interface{}{searchStateKey: ""})
+			b.sendMessage(chatID, b.t(ctx, "search.prompt"))
+			return true
+		}
+		b.applySearchQuery(ctx, chatID, userID, &models.BookingFilter{}, query)
+		return true
+
+	case state != nil && state.CurrentStep == models.StateManagerSearch &&
+		!strings.HasPrefix(text, "/") && b.i18n.Match(text) == "":
+		if b.denyWithoutPermission(ctx, chatID, userID, models.PermViewBookings) {
+			return true
+		}
+		filter := b.searchFilterFromState(state)
+		b.applySearchQuery(ctx, chatID, userID, &filter, text)
+		return true
+	}
+	return false
+}
+
+// applySearchQuery заменяет текстовую часть фильтра запросом и показывает результаты.
+// Запрос распознается как номер заявки (#15), телефон, дата или интервал дат, иначе как имя клиента.
+func (b *Bot) applySearchQuery(ctx context.Context, chatID, userID int64, filter *models.BookingFilter, query string) {
+	query = strings.TrimSpace(query)
+	filter.BookingID, filter.Phone, filter.ClientName = 0, "", ""
+
+	digits := strings.Map(func(r rune) rune {
+		if r >= '0' && r <= '9' {
+			return r
+		}
+		if strings.ContainsRune("+-() ", r) {
+			return -1
+		}
+		return 'x'
+	}, query)
+
+	switch {
+	case strings.HasPrefix(query, "#"):
+		id, err := strconv.ParseInt(strings.TrimPrefix(query, "#"), 10, 64)
+		if err != nil || id <= 0 {
+			b.sendMessage(chatID, b.t(ctx, "search.invalid_query"))
+			return
+		}
+		filter.BookingID = id
+
+	case searchDateRangePattern.MatchString(query):
+		m := searchDateRangePattern.FindStringSubmatch(query)
+		from, errFrom := time.Parse("02.01.2006", m[1])
+		to, errTo := time.Parse("02.01.2006", m[2])
+		if errFrom != nil || errTo != nil || to.Before(from) {
+			b.sendMessage(chatID, b.t(ctx, "search.invalid_query"))
+			return
+		}
+		filter.DateFrom, filter.DateTo = from, to
+
+	case digits != "" && !strings.Contains(digits, "x") && len(digits) >= 10:
+		filter.Phone = b.normalizePhone(query)
+		if filter.Phone == "" {
+			filter.Phone = digits
+		}
+
+	case digits != "" && !strings.Contains(digits, "x"):
+		// Короткое число - номер заявки
+		filter.BookingID, _ = strconv.ParseInt(digits, 10, 64)
+
+	default:
+		if date, err := time.Parse("02.01.2006", query); err == nil {
+			filter.DateFrom, filter.DateTo = date, date
+		} else {
+			filter.ClientName = b.sanitizeInput(query)
+		}
+	}
+
+	b.saveSearchFilter(ctx, userID, filter)
+	b.sendSearchResults(ctx, chatID, userID, 0, 0)
+}
+
+// handleManagerSearchCallback обрабатывает кнопки фильтров и страниц результатов поиска
+func (b *Bot) handleManagerSearchCallback(ctx context.Context, update *tgbotapi.Update, data string) bool {
+	if !strings.HasPrefix(data, "search_") {
+		return false
+	}
+
+	callback := update.CallbackQuery
+	chatID := callback.Message.Chat.ID
+	userID := callback.From.ID
+	messageID := callback.Message.MessageID
+
+	if b.denyWithoutPermission(ctx, chatID, userID, models.PermViewBookings) {
+		return true
+	}
+
+	state := b.getUserState(ctx, userID)
+	if state == nil || state.CurrentStep != models.StateManagerSearch {
+		b.sendMessage(chatID, b.t(ctx, "error.session_expired"))
+		return true
+	}
+	filter := b.searchFilterFromState(state)
+
+	switch {
+	case strings.HasPrefix(data, searchPagePrefix):
+		page, _ := strconv.Atoi(strings.TrimPrefix(data, searchPagePrefix))
+		b.sendSearchResults(ctx, chatID, userID, messageID, page)
+		return true
+
+	case strings.HasPrefix(data, searchStatusPrefix):
+		filter.Statuses = nil
+		if status := strings.TrimPrefix(data, searchStatusPrefix); models.IsBookingStatus(status) {
+			filter.Statuses = []string{status}
+		}
+
+	case strings.HasPrefix(data, searchPeriodPrefix):
+		filter.DateFrom, filter.DateTo = searchPeriod(strings.TrimPrefix(data, searchPeriodPrefix), time.Now())
+
+	case strings.HasPrefix(data, searchItemPrefix):
+		filter.ItemIDs = nil
+		if itemID, _ := strconv.ParseInt(strings.TrimPrefix(data, searchItemPrefix), 10, 64); itemID > 0 {
+			filter.ItemIDs = []int64{itemID}
+		}
+
+	case data == searchItemsMenu:
+		b.sendSearchItemChoice(ctx, chatID, userID, messageID)
+		return true
+
+	case data == searchReset:
+		filter = models.BookingFilter{}
+
+	default:
+		return false
+	}
+
+	b.saveSearchFilter(ctx, userID, &filter)
+	b.sendSearchResults(ctx, chatID, userID, messageID, 0)
+	return true
+}
+
+// searchPeriod возвращает границы быстрого фильтра по датам
+func searchPeriod(period string, now time.Time) (from, to time.Time) {
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	switch period {
+	case "week":
+		return today, today.AddDate(0, 0, 7)
+	case "month":
+		return today, today.AddDate(0, 0, 30)
+	case "past":
+		return today.AddDate(0, 0, -30), today.AddDate(0, 0, -1)
+	default:
+		return time.Time{}, time.Time{}
+	}
+}
+
+// searchFilterFromState восстанавливает фильтр, сохраненный в состоянии поиска
+func (b *Bot) searchFilterFromState(state *models.UserState) models.BookingFilter {
+	values, err := url.ParseQuery(state.GetString(searchStateKey))
+	if err != nil {
+		return models.BookingFilter{}
+	}
+	filter, err := models.ParseBookingFilter(values)
+	if err != nil {
+		b.logger.Warn().Err(err).Int64("user_id", state.UserID).Msg("Invalid saved search filter")
+		return models.BookingFilter{}
+	}
+	return filter
+}
+
+// saveSearchFilter сохраняет фильтр в состоянии, чтобы кнопки и страницы работали с ним же
+func (b *Bot) saveSearchFilter(ctx context.Context, userID int64, filter *models.BookingFilter) {
+	b.setUserState(ctx, userID, models.StateManagerSearch, map[string]interface{}{
+		searchStateKey: filter.Values().Encode(),
+	})
+}
+
+// sendSearchResults показывает страницу результатов поиска с кнопками фильтров
+func (b *Bot) sendSearchResults(ctx context.Context, chatID, userID int64, messageID, page int) {
+	state := b.getUserState(ctx, userID)
+	if state == nil {
+		b.sendMessage(chatID, b.t(ctx, "error.session_expired"))
+		return
+	}
+	filter := b.searchFilterFromState(state)
+	filter.Limit = models.MaxSearchResults
+
+	// Заголовок и кнопки показывают фильтр пользователя, а запрос ограничен областью его роли
+	query := filter
+	var bookings []*models.Booking
+	if b.scopeBookingFilter(userID, &query) {
+		var err error
+		bookings, err = b.bookingService.SearchBookings(ctx, query)
+		if err != nil {
+			b.logger.Error().Err(err).Int64("user_id", userID).Msg("Error searching bookings")
+			b.sendMessage(chatID, b.t(ctx, "error.bookings_list"))
+			return
+		}
+	}
+	bookings = b.filterBookingsByScope(userID, bookings)
+
+	title := b.searchTitle(ctx, &filter)
+	if len(bookings) == 0 {
+		title += "\n\n" + b.t(ctx, "search.empty")
+	} else {
+		title += "\n\n" + b.tn(ctx, "search.found", len(bookings))
+	}
+
+	b.renderPaginatedBookings(&PaginationParams{
+		Ctx:          ctx,
+		ChatID:       chatID,
+		MessageID:    messageID,
+		Page:         page,
+		Title:        title,
+		ItemPrefix:   "show_booking:",
+		PagePrefix:   searchPagePrefix,
+		BackCallback: "back_to_main",
+		ExtraRows:    append(b.searchFilterRows(ctx, &filter), b.bulkStartRows(ctx, userID, bulkOriginSearch)...),
+	}, bookings)
+}
+
+// searchTitle описывает примененные условия поиска
+func (b *Bot) searchTitle(ctx context.Context, filter *models.BookingFilter) string {
+	lines := []string{b.t(ctx, "search.title")}
+	escape := func(s string) string { return tgbotapi.EscapeText(models.ParseModeMarkdown, s) }
+
+	if filter.BookingID != 0 {
+		lines = append(lines, b.t(ctx, "search.by_id", filter.BookingID))
+	}
+	if filter.ClientName != "" {
+		lines = append(lines, b.t(ctx, "search.by_name", escape(filter.ClientName)))
+	}
+	if filter.Phone != "" {
+		lines = append(lines, b.t(ctx, "search.by_phone", filter.Phone))
+	}
+	for _, status := range filter.Statuses {
+		lines = append(lines, b.t(ctx, "search.by_status", b.statusName(ctx, status)))
+	}
+	for _, itemID := range filter.ItemIDs {
+		name := strconv.FormatInt(itemID, 10)
+		if item, ok := b.getItemByID(itemID); ok {
+			name = item.Name
+		}
+		lines = append(lines, b.t(ctx, "search.by_item", escape(name)))
+	}
+	if !filter.DateFrom.IsZero() || !filter.DateTo.IsZero() {
+		from, to := "…", "…"
+		if !filter.DateFrom.IsZero() {
+			from = filter.DateFrom.Format("02.01.2006")
+		}
+		if !filter.DateTo.IsZero() {
+			to = filter.DateTo.Format("02.01.2006")
+		}
+		lines = append(lines, b.t(ctx, "search.by_dates", from, to))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// searchFilterRows строит кнопки фильтров по статусу, периоду и аппарату
+func (b *Bot) searchFilterRows(ctx context.Context, filter *models.BookingFilter) [][]tgbotapi.InlineKeyboardButton {
+	active := ""
+	if len(filter.Statuses) == 1 {
+		active = filter.Statuses[0]
+	}
+
+	statusRow := make([]tgbotapi.InlineKeyboardButton, 0, len(searchStatuses)+1)
+	allLabel := b.t(ctx, "search.all_statuses")
+	if active == "" {
+		allLabel = "• " + allLabel
+	}
+	statusRow = append(statusRow, tgbotapi.NewInlineKeyboardButtonData(allLabel, searchStatusPrefix+"all"))
+	for _, s := range searchStatuses {
+		label := s.icon
+		if s.status == active {
+			label = "• " + label
+		}
+		statusRow = append(statusRow, tgbotapi.NewInlineKeyboardButtonData(label, searchStatusPrefix+s.status))
+	}
+
+	periodRow := make([]tgbotapi.InlineKeyboardButton, 0, 4)
+	now := time.Now()
+	for _, period := range []string{"week", "month", "past", "all"} {
+		label := b.t(ctx, "search.period_"+period)
+		from, to := searchPeriod(period, now)
+		if from.Equal(filter.DateFrom) && to.Equal(filter.DateTo) {
+			label = "• " + label
+		}
+		periodRow = append(periodRow, tgbotapi.NewInlineKeyboardButtonData(label, searchPeriodPrefix+period))
+	}
+
+	return [][]tgbotapi.InlineKeyboardButton{
+		statusRow,
+		periodRow,
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(b.t(ctx, "search.choose_item_button"), searchItemsMenu),
+			tgbotapi.NewInlineKeyboardButtonData(b.t(ctx, "search.reset"), searchReset),
+		),
+	}
+}
+
+// sendSearchItemChoice заменяет результаты списком аппаратов для фильтра
+func (b *Bot) sendSearchItemChoice(ctx context.Context, chatID, userID int64, messageID int) {
+	items, err := b.itemService.GetActiveItems(ctx)
+	if err != nil {
+		b.logger.Error().Err(err).Msg("Error getting items for search filter")
+		b.sendMessage(chatID, b.t(ctx, "error.items_list"))
+		return
+	}
+
+	rows := make([][]tgbotapi.InlineKeyboardButton, 0, len(items)+1)
+	rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData(b.t(ctx, "search.all_items"), searchItemPrefix+"0"),
+	))
+	for _, item := range items {
+		if !b.canManageItem(userID, item.ID) {
+			continue
+		}
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(item.Name, fmt.Sprintf("%s%d", searchItemPrefix, item.ID)),
+		))
+	}
+
+	edit := tgbotapi.NewEditMessageTextAndMarkup(chatID, messageID, b.t(ctx, "search.choose_item"), tgbotapi.NewInlineKeyboardMarkup(rows...))
+	if _, err := b.tgService.Send(edit); err != nil {
+		b.logger.Error().Err(err).Msg("Failed to send search item choice")
+	}
+}
@@ -16,39 +16,46 @@ import (
 
 // getUserStats показывает статистику менеджеру
 func (b *Bot) getUserStats(ctx context.Context, update *tgbotapi.Update) {
-	if !b.isManager(update.Message.From.ID) {
+	if !b.hasPermission(update.Message.From.ID, models.PermViewStats) {
+		return
+	}
+
+	start, end, err := parseStatsPeriod(strings.Fields(update.Message.Text)[1:], time.Now())
+	if err != nil {
+		b.sendMessage(update.Message.Chat.ID, b.t(ctx, "stats.usage"))
 		return
 	}
 
 	allUsers, err := b.userService.GetAllUsers(ctx)
 	if err != nil {
 		b.logger.Error().Err(err).Msg("Error getting all users")
-		b.sendMessage(update.Message.Chat.ID, "Ошибка при получении данных")
+		b.sendMessage(update.Message.Chat.ID, b.t(ctx, "error.stats"))
 		return
 	}
 
 	activeUsers, _ := b.userService.GetActiveUsers(ctx, 30)
 	managers, _ := b.userService.GetManagers(ctx)
 
+	now := time.Now()
 	blacklistedCount := 0
 	for _, user := range allUsers {
-		if user.IsBlacklisted {
+		if user.IsBlockedAt(now) {
 			blacklistedCount++
 		}
 	}
 
 	// Формируем сообщение со статистикой
 	var message strings.Builder
-	message.WriteString("📊 *Статистика*\n\n")
+	message.WriteString(b.t(ctx, "stats.title") + "\n\n")
 
 	// Пользователи
-	message.WriteString("👥 *Пользователи*\n")
-	message.WriteString(fmt.Sprintf("Всего: *%d*\n", len(allUsers)))
-	message.WriteString(fmt.Sprintf("Активных (30д): *%d*\n", len(activeUsers)))
-	message.WriteString(fmt.Sprintf("Менеджеров: *%d*\n", len(managers)))
-	message.WriteString(fmt.Sprintf("В черном списке: *%d*\n\n", blacklistedCount))
+	message.WriteString(b.t(ctx, "stats.users_title") + "\n")
+	message.WriteString(b.t(ctx, "stats.users_total", len(allUsers)) + "\n")
+	message.WriteString(b.t(ctx, "stats.users_active", len(activeUsers)) + "\n")
+	message.WriteString(b.t(ctx, "stats.users_managers", len(managers)) + "\n")
+	message.WriteString(b.t(ctx, "stats.users_blacklisted", blacklistedCount) + "\n\n")
 
-	message.WriteString("Последние пользователи:\n")
+	message.WriteString(b.t(ctx, "stats.recent_users") + "\n")
 	count := 5
 	if len(allUsers) < count {
 		count = len(allUsers)
@@ -58,7 +65,7 @@ func (b *Bot) getUserStats(ctx context.Context, update *tgbotapi.Update) {
 		emoji := "👤"
 		if user.IsManager {
 			emoji = "👨‍💼"
-		} else if user.IsBlacklisted {
+		} else if user.IsBlockedAt(now) {
 			emoji = "🚫"
 		}
 
@@ -71,32 +78,38 @@ func (b *Bot) getUserStats(ctx context.Context, update *tgbotapi.Update) {
 	message.WriteString("\n")
 
 	// Бронирования
-	now := time.Now()
 	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
 	periods := []struct {
 		label string
 		start time.Time
 		end   time.Time
 	}{
-		{"Сегодня", today, today},
-		{"7 дней", today.AddDate(0, 0, -6), today},
-		{"30 дней", today.AddDate(0, 0, -29), today},
+		{b.t(ctx, "stats.period_today"), today, today},
+		{b.t(ctx, "stats.period_days", 7), today.AddDate(0, 0, -6), today},
+		{b.t(ctx, "stats.period_days", 30), today.AddDate(0, 0, -29), today},
 	}
 
-	message.WriteString("📅 *Бронирования*\n")
+	message.WriteString(b.t(ctx, "stats.bookings_title") + "\n")
 	for _, p := range periods {
 		summary := b.bookingSummary(ctx, p.start, p.end)
 		message.WriteString(fmt.Sprintf("%s: %s\n", p.label, summary))
 	}
 
-	msg := tgbotapi.NewMessage(update.Message.Chat.ID, message.String())
-	msg.ParseMode = models.ParseModeMarkdown
-
 	keyboard := tgbotapi.NewInlineKeyboardMarkup(
 		tgbotapi.NewInlineKeyboardRow(
-			tgbotapi.NewInlineKeyboardButtonData("📤 Экспорт пользователей", "export_users"),
+			tgbotapi.NewInlineKeyboardButtonData(b.t(ctx, "stats.export_users"), "export_users"),
 		),
 	)
+
+	// Аналитика за выбранный период
+	if report, err := b.loadAnalytics(ctx, start, end); err == nil {
+		message.WriteString("\n")
+		message.WriteString(b.analyticsText(ctx, report))
+		keyboard = b.analyticsKeyboard(ctx, report, true)
+	}
+
+	msg := tgbotapi.NewMessage(update.Message.Chat.ID, message.String())
+	msg.ParseMode = models.ParseModeMarkdown
 	msg.ReplyMarkup = &keyboard
 
 	if _, err := b.tgService.Send(msg); err != nil {
@@ -109,11 +122,11 @@ func (b *Bot) bookingSummary(ctx context.Context, startDate, endDate time.Time)
 	bookings, err := b.bookingService.GetBookingsByDateRange(ctx, startDate, endDate)
 	if err != nil {
 		b.logger.Error().Err(err).Msg("bookingSummary error")
-		return "ошибка"
+		return b.t(ctx, "stats.summary_error")
 	}
 
 	if len(bookings) == 0 {
-		return "нет данных"
+		return b.t(ctx, "stats.summary_empty")
 	}
 
 	statusCount := map[string]int{}
@@ -154,7 +167,7 @@ func (b *Bot) bookingSummary(ctx context.Context, startDate, endDate time.Time)
 		itemParts = append(itemParts, fmt.Sprintf("%s:%d", it.name, it.count))
 	}
 
-	return fmt.Sprintf("всего %d | статусы [%s] | топ [%s]",
+	return b.t(ctx, "stats.summary",
 		len(bookings),
 		strings.Join(statusParts, ", "),
 		strings.Join(itemParts, ", "),
@@ -164,21 +177,21 @@ func (b *Bot) bookingSummary(ctx context.Context, startDate, endDate time.Time)
 // handleExportUsers обработка экспорта пользователей
 func (b *Bot) handleExportUsers(ctx context.Context, update *tgbotapi.Update) {
 	callback := update.CallbackQuery
-	if callback == nil || !b.isManager(callback.From.ID) {
+	if callback == nil || !b.hasPermission(callback.From.ID, models.PermExportData) {
 		return
 	}
 
 	users, err := b.userService.GetAllUsers(ctx)
 	if err != nil {
 		b.logger.Error().Err(err).Msg("Error getting users for export")
-		b.sendMessage(callback.Message.Chat.ID, "Ошибка при получении данных пользователей")
+		b.sendMessage(callback.Message.Chat.ID, b.t(ctx, "error.users_list"))
 		return
 	}
 
 	filePath, err := b.exportUsersToExcel(ctx, users)
 	if err != nil {
 		b.logger.Error().Err(err).Msg("Error exporting users to Excel")
-		b.sendMessage(callback.Message.Chat.ID, "Ошибка при создании файла экспорта")
+		b.sendMessage(callback.Message.Chat.ID, b.t(ctx, "error.export_file"))
 		return
 	}
 
@@ -186,7 +199,7 @@ func (b *Bot) handleExportUsers(ctx context.Context, update *tgbotapi.Update) {
 	file, err := os.Open(filePath)
 	if err != nil {
 		b.logger.Error().Err(err).Str("file_path", filePath).Msg("Error opening file")
-		b.sendMessage(callback.Message.Chat.ID, "Ошибка при открытии файла")
+		b.sendMessage(callback.Message.Chat.ID, b.t(ctx, "error.open_file"))
 		return
 	}
 	defer file.Close()
@@ -197,14 +210,14 @@ func (b *Bot) handleExportUsers(ctx context.Context, update *tgbotapi.Update) {
 	}
 
 	doc := tgbotapi.NewDocument(callback.Message.Chat.ID, fileReader)
-	doc.Caption = "📊 Экспорт данных пользователей"
+	doc.Caption = b.t(ctx, "stats.users_export_caption")
 
 	_, err = b.tgService.Send(doc)
 	if err != nil {
 		b.logger.Error().Err(err).Msg("Error sending document")
-		b.sendMessage(callback.Message.Chat.ID, "Ошибка при отправке файла")
+		b.sendMessage(callback.Message.Chat.ID, b.t(ctx, "error.send_file"))
 		return
 	}
 
-	b.sendMessage(callback.Message.Chat.ID, "✅ Файл с пользователями успешно отправлен")
+	b.sendMessage(callback.Message.Chat.ID, b.t(ctx, "stats.users_export_sent"))
 }
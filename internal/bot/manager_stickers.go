@@ -0,0 +1,59 @@
+package bot
+
+import (
+	"context"
+	"strings"
+
+	"bronivik/internal/models"
+	"bronivik/internal/stickers"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// handleStickersCommand отправляет лист QR-наклеек для всех активных аппаратов: /qr_stickers [pdf|png].
+// Код наклейки открывает бота на расписании аппарата (см. models.DeepLinkItem).
+func (b *Bot) handleStickersCommand(ctx context.Context, update *tgbotapi.Update) {
+	chatID := update.Message.Chat.ID
+	args := strings.Fields(update.Message.Text)[1:]
+
+	format := stickers.FormatPDF
+	if len(args) > 0 {
+		format = strings.ToLower(args[0])
+	}
+	if len(args) > 1 || (format != stickers.FormatPDF && format != stickers.FormatPNG) {
+		b.sendMessage(chatID, b.t(ctx, "stickers.usage"))
+		return
+	}
+
+	items, err := b.itemService.GetActiveItems(ctx)
+	if err != nil {
+		b.logger.Error().Err(err).Msg("Error getting active items for stickers")
+		b.sendMessage(chatID, b.t(ctx, "stickers.error"))
+		return
+	}
+	if len(items) == 0 {
+		b.sendMessage(chatID, b.t(ctx, "stickers.no_items"))
+		return
+	}
+
+	data, err := b.renderItemStickers(items, format)
+	if err != nil {
+		b.logger.Error().Err(err).Str("format", format).Msg("Error rendering item stickers")
+		b.sendMessage(chatID, b.t(ctx, "stickers.error"))
+		return
+	}
+
+	doc := tgbotapi.NewDocument(chatID, tgbotapi.FileBytes{Name: "item_stickers." + format, Bytes: data})
+	doc.Caption = b.t(ctx, "stickers.caption", len(items))
+	if _, err := b.tgService.Send(doc); err != nil {
+		b.logger.Error().Err(err).Msg("Error sending item stickers")
+	}
+}
+
+func (b *Bot) renderItemStickers(items []*models.Item, format string) ([]byte, error) {
+	sheet, err := stickers.ForItems(items, b.tgService.GetSelf().UserName)
+	if err != nil {
+		return nil, err
+	}
+	return stickers.Render(sheet, format)
+}
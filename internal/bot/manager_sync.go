@@ -40,6 +40,13 @@ func (b *Bot) SyncScheduleToSheets(ctx context.Context) {
 		Int("dates_count", len(dailyBookings)).
 		Msg("Found bookings for sync")
 
+	// Окна обслуживания уменьшают число доступных аппаратов
+	maintenance, err := b.itemService.GetMaintenanceWindows(ctx, 0, startDate, endDate)
+	if err != nil {
+		b.logger.Error().Err(err).Msg("Failed to get maintenance windows for schedule sync")
+		return
+	}
+
 	// Получаем активные товары
 	items, err := b.itemService.GetActiveItems(ctx)
 	if err != nil {
@@ -50,7 +57,7 @@ func (b *Bot) SyncScheduleToSheets(ctx context.Context) {
 	b.logger.Info().Int("items_count", len(items)).Msg("Updating Google Sheets")
 
 	// Обновляем расписание в Google Sheets
-	err = b.sheetsService.UpdateScheduleSheet(ctx, startDate, endDate, dailyBookings, items)
+	err = b.sheetsService.UpdateScheduleSheet(ctx, startDate, endDate, dailyBookings, maintenance, items)
 	if err != nil {
 		b.logger.Error().Err(err).Msg("Failed to sync schedule to Google Sheets")
 	} else {
@@ -0,0 +1,235 @@
+package bot
+
+import (
+	"context"
+	"strconv"
+	"strings"
+
+	"bronivik/internal/models"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// unitHistoryBookingsLimit - сколько последних заявок экземпляра показывает /unit_history
+const unitHistoryBookingsLimit = 20
+
+// handleManagerUnitCommands обрабатывает /units, /unit_add, /unit_status и /unit_history
+func (b *Bot) handleManagerUnitCommands(ctx context.Context, update *tgbotapi.Update, text string) bool {
+	fields := strings.Fields(text)
+	if len(fields) == 0 {
+		return false
+	}
+
+	var handler func(context.Context, *tgbotapi.Update, []string)
+	perm := models.PermManageItems
+
+	switch fields[0] {
+	case "/units":
+		handler = b.handleListUnitsCommand
+		perm = models.PermViewBookings
+	case "/unit_add":
+		handler = b.handleAddUnitCommand
+	case "/unit_status":
+		handler = b.handleUnitStatusCommand
+	case "/unit_history":
+		handler = b.handleUnitHistoryCommand
+		perm = models.PermViewBookings
+	default:
+		return false
+	}
+
+	if !b.denyWithoutPermission(ctx, update.Message.Chat.ID, update.Message.From.ID, perm) {
+		handler(ctx, update, fields[1:])
+	}
+	return true
+}
+
+// handleListUnitsCommand показывает экземпляры аппарата: /units <ID аппарата>
+func (b *Bot) handleListUnitsCommand(ctx context.Context, update *tgbotapi.Update, args []string) {
+	chatID := update.Message.Chat.ID
+	if len(args) != 1 {
+		b.sendMessage(chatID, b.t(ctx, "units.usage"))
+		return
+	}
+	itemID, err := strconv.ParseInt(args[0], 10, 64)
+	if err != nil {
+		b.sendMessage(chatID, b.t(ctx, "units.usage"))
+		return
+	}
+	if b.denyWithoutItemAccess(ctx, chatID, update.Message.From.ID, itemID) {
+		return
+	}
+	item, err := b.itemService.GetItemByID(ctx, itemID)
+	if err != nil {
+		b.sendMessage(chatID, b.t(ctx, "error.item_not_found"))
+		return
+	}
+
+	units, err := b.itemService.GetItemUnits(ctx, itemID)
+	if err != nil {
+		b.logger.Error().Err(err).Int64("item_id", itemID).Msg("Error listing item units")
+		b.sendMessage(chatID, b.t(ctx, "error.default"))
+		return
+	}
+
+	var sb strings.Builder
+	sb.WriteString(b.t(ctx, "units.list_title", item.Name))
+	sb.WriteString("\n\n")
+	if len(units) == 0 {
+		sb.WriteString(b.t(ctx, "units.list_empty"))
+		sb.WriteString("\n")
+	}
+	for _, u := range units {
+		sb.WriteString(b.unitLine(ctx, u))
+		sb.WriteString("\n")
+	}
+	sb.WriteString("\n")
+	sb.WriteString(b.t(ctx, "units.usage"))
+	b.sendMessage(chatID, sb.String())
+}
+
+// handleAddUnitCommand регистрирует экземпляр: /unit_add <ID аппарата> <серийный номер> [инвентарный номер]
+func (b *Bot) handleAddUnitCommand(ctx context.Context, update *tgbotapi.Update, args []string) {
+	chatID := update.Message.Chat.ID
+	managerID := update.Message.From.ID
+	if len(args) < 2 || len(args) > 3 {
+		b.sendMessage(chatID, b.t(ctx, "units.add_usage"))
+		return
+	}
+	itemID, err := strconv.ParseInt(args[0], 10, 64)
+	if err != nil {
+		b.sendMessage(chatID, b.t(ctx, "units.add_usage"))
+		return
+	}
+	if b.denyWithoutItemAccess(ctx, chatID, managerID, itemID) {
+		return
+	}
+	item, err := b.itemService.GetItemByID(ctx, itemID)
+	if err != nil {
+		b.sendMessage(chatID, b.t(ctx, "error.item_not_found"))
+		return
+	}
+
+	unit := &models.ItemUnit{ItemID: item.ID, SerialNumber: b.sanitizeInput(args[1])}
+	if len(args) == 3 {
+		unit.InventoryTag = b.sanitizeInput(args[2])
+	}
+	if err := b.itemService.AddUnit(ctx, unit, managerID); err != nil {
+		b.sendMessage(chatID, b.getErrorMessage(ctx, err))
+		return
+	}
+
+	b.logger.Info().
+		Int64("manager_id", managerID).
+		Int64("item_id", item.ID).
+		Str("serial", unit.SerialNumber).
+		Msg("Item unit added")
+	b.sendMessage(chatID, b.t(ctx, "units.added", unit.SerialNumber, item.Name, unit.ID))
+}
+
+// handleUnitStatusCommand меняет статус экземпляра: /unit_status <серийный номер> <active|repair|retired> [примечание]
+func (b *Bot) handleUnitStatusCommand(ctx context.Context, update *tgbotapi.Update, args []string) {
+	chatID := update.Message.Chat.ID
+	managerID := update.Message.From.ID
+	if len(args) < 2 {
+		b.sendMessage(chatID, b.t(ctx, "units.status_usage"))
+		return
+	}
+
+	unit, err := b.itemService.GetUnitBySerial(ctx, args[0])
+	if err != nil {
+		b.sendMessage(chatID, b.getErrorMessage(ctx, err))
+		return
+	}
+	if b.denyWithoutItemAccess(ctx, chatID, managerID, unit.ItemID) {
+		return
+	}
+
+	note := b.sanitizeInput(strings.Join(args[2:], " "))
+	unit, err = b.itemService.SetUnitStatus(ctx, unit.SerialNumber, strings.ToLower(args[1]), note, managerID)
+	if err != nil {
+		b.sendMessage(chatID, b.getErrorMessage(ctx, err))
+		return
+	}
+
+	b.logger.Info().
+		Int64("manager_id", managerID).
+		Str("serial", unit.SerialNumber).
+		Str("status", unit.Status).
+		Msg("Item unit status changed")
+	b.sendMessage(chatID, b.t(ctx, "units.status_changed", unit.SerialNumber, b.unitStatusName(ctx, unit.Status)))
+}
+
+// handleUnitHistoryCommand показывает историю экземпляра: /unit_history <серийный номер>
+func (b *Bot) handleUnitHistoryCommand(ctx context.Context, update *tgbotapi.Update, args []string) {
+	chatID := update.Message.Chat.ID
+	if len(args) != 1 {
+		b.sendMessage(chatID, b.t(ctx, "units.history_usage"))
+		return
+	}
+
+	history, err := b.itemService.GetUnitHistory(ctx, args[0])
+	if err != nil {
+		b.sendMessage(chatID, b.getErrorMessage(ctx, err))
+		return
+	}
+	unit := history.Unit
+	if b.denyWithoutItemAccess(ctx, chatID, update.Message.From.ID, unit.ItemID) {
+		return
+	}
+
+	itemName := ""
+	if item, err := b.itemService.GetItemByID(ctx, unit.ItemID); err == nil && item != nil {
+		itemName = item.Name
+	}
+
+	var sb strings.Builder
+	sb.WriteString(b.formatAuditHistory(ctx, b.t(ctx, "units.history_title", unit.SerialNumber, itemName), history.Entries))
+	sb.WriteString("\n")
+	sb.WriteString(b.unitLine(ctx, unit))
+	sb.WriteString("\n\n")
+	if len(history.Bookings) == 0 {
+		sb.WriteString(b.t(ctx, "units.history_no_bookings"))
+	} else {
+		sb.WriteString(b.t(ctx, "units.history_bookings"))
+	}
+	for i, booking := range history.Bookings {
+		if i == unitHistoryBookingsLimit {
+			break
+		}
+		sb.WriteString("\n")
+		sb.WriteString(b.t(ctx, "units.history_booking", booking.ID, booking.Date.Format("02.01.2006"),
+			booking.UserName, b.statusName(ctx, booking.Status)))
+	}
+	b.sendMessage(chatID, sb.String())
+}
+
+// unitLine формирует строку с описанием экземпляра
+func (b *Bot) unitLine(ctx context.Context, u *models.ItemUnit) string {
+	line := b.t(ctx, "units.list_line", u.ID, u.SerialNumber, b.unitStatusName(ctx, u.Status))
+	if u.InventoryTag != "" {
+		line += "\n" + b.t(ctx, "units.inventory_tag", u.InventoryTag)
+	}
+	if u.Note != "" {
+		line += "\n" + b.t(ctx, "units.note", u.Note)
+	}
+	return line
+}
+
+// bookingUnitLine возвращает строку с закрепленным за заявкой экземпляром или пустую строку
+func (b *Bot) bookingUnitLine(ctx context.Context, booking *models.Booking) string {
+	if booking.UnitID == 0 {
+		return ""
+	}
+	serial := "—"
+	if unit, err := b.itemService.GetUnit(ctx, booking.UnitID); err == nil {
+		serial = unit.SerialNumber
+	} else {
+		b.logger.Error().Err(err).Int64("unit_id", booking.UnitID).Msg("Error getting booking unit")
+	}
+	return b.t(ctx, "units.booking_line", booking.UnitID, serial)
+}
+
+func (b *Bot) unitStatusName(ctx context.Context, status string) string {
+	return b.t(ctx, "unit_status."+status)
+}
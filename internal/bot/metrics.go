@@ -17,6 +17,11 @@ type Metrics struct {
 	UpdateProcessingTime prometheus.Histogram
 	BookingsCreated      *prometheus.CounterVec
 	BookingDuration      *prometheus.HistogramVec
+
+	DispatcherQueued       prometheus.Gauge
+	DispatcherBusyWorkers  prometheus.Gauge
+	DispatcherWaitTime     prometheus.Histogram
+	DispatcherBackpressure prometheus.Counter
 }
 
 // NewMetrics создает новые метрики
@@ -72,6 +77,35 @@ func NewMetrics() *Metrics {
 			Help:      "Time spent creating a booking",
 			Buckets:   prometheus.DefBuckets,
 		}, []string{"item_name"}),
+
+		DispatcherQueued: promauto.NewGauge(prometheus.GaugeOpts{
+			Namespace: "bronivik_jr",
+			Subsystem: "bot",
+			Name:      "dispatcher_updates_queued",
+			Help:      "Updates accepted by the dispatcher and not processed yet",
+		}),
+
+		DispatcherBusyWorkers: promauto.NewGauge(prometheus.GaugeOpts{
+			Namespace: "bronivik_jr",
+			Subsystem: "bot",
+			Name:      "dispatcher_busy_workers",
+			Help:      "Workers currently processing an update",
+		}),
+
+		DispatcherWaitTime: promauto.NewHistogram(prometheus.HistogramOpts{
+			Namespace: "bronivik_jr",
+			Subsystem: "bot",
+			Name:      "dispatcher_queue_wait_seconds",
+			Help:      "Time an update waits in the dispatcher queue before processing",
+			Buckets:   prometheus.DefBuckets,
+		}),
+
+		DispatcherBackpressure: promauto.NewCounter(prometheus.CounterOpts{
+			Namespace: "bronivik_jr",
+			Subsystem: "bot",
+			Name:      "dispatcher_backpressure_total",
+			Help:      "Times update polling waited for a free dispatcher slot",
+		}),
 	}
 }
 
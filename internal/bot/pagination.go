@@ -20,6 +20,9 @@ type PaginationParams struct {
 	PagePrefix   string
 	BackCallback string
 	ShowCapacity bool
+	ExtraRows    [][]tgbotapi.InlineKeyboardButton // добавляются между навигацией и кнопкой возврата
+	Selected     map[int64]bool                    // отметки заявок в режиме выбора; nil - обычный список
+	Items        []*models.Item                    // аппараты уровня каталога; nil - все активные аппараты
 }
 
 // renderPaginatedList - универсальная функция для отрисовки пагинированного списка
@@ -53,25 +56,26 @@ func (b *Bot) renderPaginatedList(
 	var message strings.Builder
 	message.WriteString(fmt.Sprintf("%s\n\n", params.Title))
 	if totalPages > 1 {
-		message.WriteString(fmt.Sprintf("Страница %d из %d\n\n", params.Page+1, totalPages))
+		message.WriteString(b.t(params.Ctx, "pagination.page", params.Page+1, totalPages) + "\n\n")
 	}
 	message.WriteString(content)
 
 	// Добавляем навигационные кнопки
 	navButtons := make([]tgbotapi.InlineKeyboardButton, 0, 2)
 	if params.Page > 0 {
-		navButtons = append(navButtons, tgbotapi.NewInlineKeyboardButtonData(btnBack, fmt.Sprintf("%s%d", params.PagePrefix, params.Page-1)))
+		navButtons = append(navButtons, tgbotapi.NewInlineKeyboardButtonData(b.t(params.Ctx, btnBack), fmt.Sprintf("%s%d", params.PagePrefix, params.Page-1)))
 	}
 	if endIdx < totalCount {
-		navButtons = append(navButtons, tgbotapi.NewInlineKeyboardButtonData("Вперед ➡️", fmt.Sprintf("%s%d", params.PagePrefix, params.Page+1)))
+		navButtons = append(navButtons, tgbotapi.NewInlineKeyboardButtonData(b.t(params.Ctx, "btn.forward"), fmt.Sprintf("%s%d", params.PagePrefix, params.Page+1)))
 	}
 	if len(navButtons) > 0 {
 		keyboard = append(keyboard, navButtons)
 	}
+	keyboard = append(keyboard, params.ExtraRows...)
 
 	if params.BackCallback != "" {
 		keyboard = append(keyboard, []tgbotapi.InlineKeyboardButton{
-			tgbotapi.NewInlineKeyboardButtonData("⬅️ Назад в меню", params.BackCallback),
+			tgbotapi.NewInlineKeyboardButtonData(b.t(params.Ctx, "btn.back_to_menu"), params.BackCallback),
 		})
 	}
 
@@ -100,11 +104,15 @@ func (b *Bot) renderPaginatedList(
 
 // renderPaginatedItems - обертка для списка аппаратов
 func (b *Bot) renderPaginatedItems(params *PaginationParams) {
-	items, err := b.itemService.GetActiveItems(params.Ctx)
-	if err != nil {
-		b.logger.Error().Err(err).Msg("Error getting active items for pagination")
-		b.sendMessage(params.ChatID, "Ошибка при получении списка аппаратов")
-		return
+	items := params.Items
+	if items == nil {
+		var err error
+		items, err = b.itemService.GetActiveItems(params.Ctx)
+		if err != nil {
+			b.logger.Error().Err(err).Msg("Error getting active items for pagination")
+			b.sendMessage(params.ChatID, b.t(params.Ctx, "error.items_list"))
+			return
+		}
 	}
 
 	b.renderPaginatedList(params, len(items), b.config.Bot.PaginationSize,
@@ -118,8 +126,11 @@ func (b *Bot) renderPaginatedItems(params *PaginationParams) {
 				if item.Description != "" {
 					content.WriteString(fmt.Sprintf("   📝 %s\n", item.Description))
 				}
+				if item.PricePerDay > 0 {
+					content.WriteString("   " + b.t(params.Ctx, "items.price", item.PricePerDay) + "\n")
+				}
 				if params.ShowCapacity {
-					content.WriteString(fmt.Sprintf("   👥 Всего: %d\n", item.TotalQuantity))
+					content.WriteString("   " + b.t(params.Ctx, "items.total", item.TotalQuantity) + "\n")
 				}
 				content.WriteString("\n")
 
@@ -158,16 +169,22 @@ func (b *Bot) renderPaginatedBookings(params *PaginationParams, bookings []*mode
 				statusEmoji = "🏁"
 			}
 
-			content.WriteString(fmt.Sprintf("%s *Заявка #%d*\n", statusEmoji, booking.ID))
+			content.WriteString(b.t(params.Ctx, "bookings.list_item", statusEmoji, booking.ID) + "\n")
 			content.WriteString(fmt.Sprintf("   👤 %s\n", booking.UserName))
 			content.WriteString(fmt.Sprintf("   🏢 %s\n", booking.ItemName))
 			content.WriteString(fmt.Sprintf("   📅 %s\n", booking.Date.Format("02.01.2006")))
 			content.WriteString(fmt.Sprintf("   🔗 /manager_booking_%d\n\n", booking.ID))
 
-			btn := tgbotapi.NewInlineKeyboardButtonData(
-				fmt.Sprintf("#%d: %s (%s)", booking.ID, booking.UserName, booking.Date.Format("02.01")),
-				fmt.Sprintf("%s%d", params.ItemPrefix, booking.ID),
-			)
+			label := fmt.Sprintf("#%d: %s (%s)", booking.ID, booking.UserName, booking.Date.Format("02.01"))
+			if params.Selected != nil {
+				mark := "⬜ "
+				if params.Selected[booking.ID] {
+					mark = "☑️ "
+				}
+				label = mark + label
+			}
+
+			btn := tgbotapi.NewInlineKeyboardButtonData(label, fmt.Sprintf("%s%d", params.ItemPrefix, booking.ID))
 			keyboard = append(keyboard, []tgbotapi.InlineKeyboardButton{btn})
 		}
 
@@ -0,0 +1,124 @@
+package bot
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"bronivik/internal/models"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// promptBookingConsent запрашивает согласие на обработку персональных данных перед вводом ФИО и телефона
+func promptBookingConsent(c *bookingContext) {
+	c.send(c.bot.t(c.ctx, "consent.text", c.bot.t(c.ctx, btnConsentAccept)), tgbotapi.NewReplyKeyboard(
+		tgbotapi.NewKeyboardButtonRow(
+			tgbotapi.NewKeyboardButton(c.bot.t(c.ctx, btnConsentAccept)),
+		),
+		tgbotapi.NewKeyboardButtonRow(
+			tgbotapi.NewKeyboardButton(c.bot.t(c.ctx, btnBack)),
+			tgbotapi.NewKeyboardButton(c.bot.t(c.ctx, btnCancel)),
+		),
+	))
+}
+
+// inputBookingConsent сохраняет согласие; любой другой ответ повторяет запрос
+func inputBookingConsent(c *bookingContext, text string) (string, error) {
+	if !c.bot.isButton(text, btnConsentAccept) {
+		return models.StatePersonalData, nil
+	}
+
+	if err := c.bot.userService.GiveConsent(c.ctx, c.userID); err != nil {
+		c.bot.logger.Error().Err(err).Int64("user_id", c.userID).Msg("Error saving consent")
+		return "", err
+	}
+
+	c.bot.logger.Info().Int64("user_id", c.userID).Msg("Personal data consent given")
+	return models.StateEnterName, nil
+}
+
+// handleMyDataCommand отправляет пользователю все хранящиеся о нем данные файлом JSON
+func (b *Bot) handleMyDataCommand(ctx context.Context, update *tgbotapi.Update) {
+	chatID := update.Message.Chat.ID
+	userID := update.Message.From.ID
+
+	export, err := b.userService.ExportPersonalData(ctx, userID)
+	if err != nil {
+		b.logger.Error().Err(err).Int64("user_id", userID).Msg("Error exporting personal data")
+		b.sendMessage(chatID, b.getErrorMessage(ctx, err))
+		return
+	}
+
+	if state := b.getUserState(ctx, userID); state != nil {
+		export.SessionStep = state.CurrentStep
+		export.SessionData = state.TempData
+	}
+
+	data, err := json.MarshalIndent(export, "", "  ")
+	if err != nil {
+		b.logger.Error().Err(err).Int64("user_id", userID).Msg("Error encoding personal data")
+		b.sendMessage(chatID, b.getErrorMessage(ctx, err))
+		return
+	}
+
+	doc := tgbotapi.NewDocument(chatID, tgbotapi.FileBytes{
+		Name:  fmt.Sprintf("mydata_%d.json", userID),
+		Bytes: data,
+	})
+	doc.Caption = b.tn(ctx, "mydata.caption", len(export.Bookings))
+	if _, err := b.tgService.Send(doc); err != nil {
+		b.logger.Error().Err(err).Int64("user_id", userID).Msg("Failed to send personal data export")
+	}
+}
+
+// handleForgetCommand просит подтвердить удаление персональных данных
+func (b *Bot) handleForgetCommand(ctx context.Context, update *tgbotapi.Update) {
+	keyboard := tgbotapi.NewInlineKeyboardMarkup(tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData(b.t(ctx, "btn.forget_confirm"), "forget_confirm"),
+		tgbotapi.NewInlineKeyboardButtonData(b.t(ctx, "btn.forget_cancel"), "forget_cancel"),
+	))
+	if _, err := b.tgService.SendWithInlineKeyboard(update.Message.Chat.ID, b.t(ctx, "forget.confirm"), keyboard); err != nil {
+		b.logger.Error().Err(err).Msg("Failed to send forget confirmation")
+	}
+}
+
+// handleForgetConfirm удаляет персональные данные пользователя из БД, Google Sheets и Redis
+func (b *Bot) handleForgetConfirm(ctx context.Context, chatID, userID int64) {
+	bookings, err := b.userService.ForgetUser(ctx, userID)
+	if err != nil {
+		b.logger.Error().Err(err).Int64("user_id", userID).Msg("Error erasing personal data")
+		b.sendMessage(chatID, b.getErrorMessage(ctx, err))
+		return
+	}
+
+	if b.sheetsWorker != nil {
+		for _, booking := range bookings {
+			if err := b.sheetsWorker.EnqueueTask(ctx, "upsert", booking.ID, booking, ""); err != nil {
+				b.logger.Error().Err(err).Int64("booking_id", booking.ID).Msg("Failed to enqueue anonymized booking")
+			}
+		}
+		if len(bookings) > 0 {
+			if err := b.sheetsWorker.EnqueueSyncSchedule(ctx, time.Time{}, time.Time{}); err != nil {
+				b.logger.Error().Err(err).Msg("Failed to enqueue schedule sync")
+			}
+		}
+	}
+
+	if b.sheetsService != nil {
+		if users, errUsers := b.userService.GetAllUsers(ctx); errUsers == nil {
+			if err := b.sheetsService.UpdateUsersSheet(ctx, users); err != nil {
+				b.logger.Error().Err(err).Msg("Failed to update users sheet after erasure")
+			}
+		}
+		// Имя клиента и заметки о выдаче стираются и из листа выдачи и возврата
+		b.syncHandoversSheet(ctx,
+			time.Now().AddDate(0, -models.DefaultExportRangeMonthsBefore, 0),
+			time.Now().AddDate(0, models.DefaultExportRangeMonthsAfter, 0))
+	}
+
+	b.clearUserState(ctx, userID)
+
+	b.sendMessage(chatID, b.tn(ctx, "forget.done", len(bookings)))
+}
@@ -3,76 +3,131 @@ package bot
 import (
 	"context"
 	"fmt"
+	"strconv"
+	"strings"
 	"time"
 
+	"bronivik/internal/config"
 	"bronivik/internal/models"
 
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
 )
 
-// StartReminders schedules daily reminders for next-day bookings.
+// reminderCheckInterval - как часто проверяется, не наступило ли время очередного этапа напоминаний
+const reminderCheckInterval = 5 * time.Minute
+
+// StartReminders periodically sends the reminder stages configured in bot.reminders.
 func (b *Bot) StartReminders(ctx context.Context) {
-	if b == nil || b.tgService == nil {
+	if b == nil || b.tgService == nil || len(b.config.Bot.Reminders) == 0 {
 		return
 	}
 
 	go func() {
-		// Parse reminder hour from config (default to 9 if invalid)
-		hour := 9
-		if b.config.Bot.ReminderTime != "" {
-			var m int
-			_, err := fmt.Sscanf(b.config.Bot.ReminderTime, "%d:%d", &hour, &m)
-			if err != nil {
-				b.logger.Error().Err(err).Str("reminder_time", b.config.Bot.ReminderTime).Msg("Invalid reminder time format")
-				return
-			}
-		}
-
-		// First wait until next reminder time local time, then tick every 24h.
-		wait := timeUntilNextHour(hour)
-		timer := time.NewTimer(wait)
-		defer timer.Stop()
+		ticker := time.NewTicker(reminderCheckInterval)
+		defer ticker.Stop()
 
+		// После рестарта сразу досылаем этапы, время которых сегодня уже наступило
+		b.sendDueReminders(ctx, time.Now())
 		for {
 			select {
 			case <-ctx.Done():
 				return
-			case <-timer.C:
-				b.sendTomorrowReminders(ctx)
-				timer.Reset(24 * time.Hour)
+			case now := <-ticker.C:
+				b.sendDueReminders(ctx, now)
 			}
 		}
 	}()
 }
 
-func (b *Bot) sendTomorrowReminders(ctx context.Context) {
-	start := time.Now().Add(24 * time.Hour).Truncate(24 * time.Hour)
-	end := start
+// sendDueReminders отправляет этапы напоминаний, время которых сегодня уже наступило.
+// Каждое напоминание отмечается в БД, поэтому повторные проверки и другие реплики его не дублируют.
+func (b *Bot) sendDueReminders(ctx context.Context, now time.Time) {
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+
+	for _, stage := range b.config.Bot.Reminders {
+		hour, minute, err := config.ParseClock(stage.Time)
+		if err != nil {
+			b.logger.Error().Err(err).Str("reminder_time", stage.Time).Msg("Invalid reminder time format")
+			continue
+		}
+		if now.Hour()*60+now.Minute() < hour*60+minute {
+			continue
+		}
 
-	bookings, err := b.bookingService.GetBookingsByDateRange(ctx, start, end)
+		day := today.AddDate(0, 0, stage.DaysBefore)
+		bookings, err := b.reminderBookings(ctx, stage.Kind, day)
+		if err != nil {
+			b.logger.Error().Err(err).Str("kind", stage.Kind).Time("date", day).Msg("reminder: get bookings error")
+			continue
+		}
+		for _, booking := range bookings {
+			b.sendReminder(ctx, stage, day, booking)
+		}
+	}
+}
+
+// reminderBookings возвращает брони, о которых напоминает этап на дату day:
+// before - брони, которые начинаются в day, return - брони, аппарат по которым нужно вернуть в day.
+// Многодневная бронь хранится по дням, поэтому соседние дни одного клиента и аппарата считаются одной бронью.
+func (b *Bot) reminderBookings(ctx context.Context, kind string, day time.Time) ([]*models.Booking, error) {
+	prev := day.AddDate(0, 0, -1)
+	bookings, err := b.bookingService.GetBookingsByDateRange(ctx, prev, day)
 	if err != nil {
-		b.logger.Error().Err(err).Time("start", start).Time("end", end).Msg("reminder: get bookings error")
-		return
+		return nil, err
 	}
 
+	active := make(map[string]bool)
 	for _, booking := range bookings {
-		if !shouldRemindStatus(booking.Status) {
-			continue
+		if shouldRemindStatus(booking.Status) {
+			active[bookingDayKey(booking, booking.Date)] = true
 		}
+	}
 
-		user, err := b.userService.GetUserByID(ctx, booking.UserID)
-		if err != nil {
-			b.logger.Error().Err(err).Int64("user_id", booking.UserID).Msg("reminder: load user error")
+	var result []*models.Booking
+	for _, booking := range bookings {
+		if !shouldRemindStatus(booking.Status) {
 			continue
 		}
-		if user.TelegramID == 0 {
+		date := booking.Date.Format("2006-01-02")
+		switch {
+		case kind == models.ReminderKindBefore && date == day.Format("2006-01-02") && !active[bookingDayKey(booking, prev)]:
+		case kind == models.ReminderKindReturn && date == prev.Format("2006-01-02") && !active[bookingDayKey(booking, day)]:
+		default:
 			continue
 		}
+		result = append(result, booking)
+	}
+	return result, nil
+}
 
-		msgText := formatReminderMessage(booking)
-		msg := tgbotapi.NewMessage(user.TelegramID, msgText)
-		if _, err := b.tgService.Send(msg); err != nil {
-			b.logger.Error().Err(err).Int64("telegram_id", user.TelegramID).Msg("reminder: send error")
+// bookingDayKey - ключ "клиент, аппарат, день" для поиска соседних дней одной брони
+func bookingDayKey(booking *models.Booking, day time.Time) string {
+	return fmt.Sprintf("%d:%d:%s", booking.UserID, booking.ItemID, day.Format("2006-01-02"))
+}
+
+// sendReminder отправляет напоминание, если пользователь от них не отказался и его еще никто не отправил
+func (b *Bot) sendReminder(ctx context.Context, stage config.ReminderConfig, day time.Time, booking *models.Booking) {
+	if booking.UserID == 0 || !b.userService.RemindersEnabled(ctx, booking.UserID) {
+		return
+	}
+
+	key := models.ReminderKey(stage.Kind, stage.DaysBefore)
+	claimed, err := b.bookingService.ClaimReminder(ctx, booking.ID, key)
+	if err != nil {
+		b.logger.Error().Err(err).Int64("booking_id", booking.ID).Str("reminder", key).Msg("reminder: claim error")
+		return
+	}
+	if !claimed {
+		return
+	}
+
+	userCtx := b.withUserLanguage(ctx, booking.UserID)
+	msg := tgbotapi.NewMessage(booking.UserID, b.formatReminderMessage(userCtx, stage, day, booking))
+	if _, err := b.tgService.Send(msg); err != nil {
+		b.logger.Error().Err(err).Int64("telegram_id", booking.UserID).Str("reminder", key).Msg("reminder: send error")
+		// Снимаем отметку, чтобы попробовать еще раз при следующей проверке
+		if err := b.bookingService.ReleaseReminder(ctx, booking.ID, key); err != nil {
+			b.logger.Error().Err(err).Int64("booking_id", booking.ID).Str("reminder", key).Msg("reminder: release error")
 		}
 	}
 }
@@ -86,16 +141,66 @@ func shouldRemindStatus(status string) bool {
 	}
 }
 
-func formatReminderMessage(b *models.Booking) string {
-	date := b.Date.Format("02.01.2006")
-	return "Напоминание: завтра у вас бронь " + b.ItemName + " на " + date + ". Статус: " + b.Status
+// formatReminderMessage подставляет данные брони в шаблон этапа.
+// Шаблон - ключ каталога (переводится на язык пользователя) или готовый текст из конфига.
+func (b *Bot) formatReminderMessage(ctx context.Context, stage config.ReminderConfig, day time.Time, booking *models.Booking) string {
+	template := stage.Template
+	if template == "" {
+		template = "reminder." + stage.Kind
+	}
+
+	replacer := strings.NewReplacer(
+		"{id}", strconv.FormatInt(booking.ID, 10),
+		"{item}", booking.ItemName,
+		"{date}", day.Format("02.01.2006"),
+		"{when}", b.reminderWhen(ctx, stage.DaysBefore),
+		"{status}", b.statusName(ctx, booking.Status),
+		"{name}", booking.UserName,
+	)
+	return replacer.Replace(b.t(ctx, template)) + "\n\n" + b.t(ctx, "reminder.opt_out")
+}
+
+// reminderWhen - "сегодня", "завтра" или "через N дней"
+func (b *Bot) reminderWhen(ctx context.Context, days int) string {
+	switch days {
+	case 0:
+		return b.t(ctx, "reminder.when_today")
+	case 1:
+		return b.t(ctx, "reminder.when_tomorrow")
+	default:
+		return b.tn(ctx, "reminder.when_in_days", days)
+	}
+}
+
+// handleRemindersCommand показывает, включены ли напоминания, с кнопкой переключения
+func (b *Bot) handleRemindersCommand(ctx context.Context, chatID, userID int64) {
+	text, keyboard := b.remindersSettings(ctx, b.userService.RemindersEnabled(ctx, userID))
+	if _, err := b.tgService.SendWithInlineKeyboard(chatID, text, keyboard); err != nil {
+		b.logger.Error().Err(err).Int64("chat_id", chatID).Msg("Failed to send reminders settings")
+	}
+}
+
+// handleSetReminders сохраняет выбор пользователя и обновляет сообщение с настройкой
+func (b *Bot) handleSetReminders(ctx context.Context, update *tgbotapi.Update) {
+	callback := update.CallbackQuery
+	enabled := strings.TrimPrefix(callback.Data, "set_reminders:") == "on"
+
+	if err := b.userService.SetRemindersEnabled(ctx, callback.From.ID, enabled); err != nil {
+		b.logger.Error().Err(err).Int64("user_id", callback.From.ID).Bool("enabled", enabled).Msg("Error saving reminders setting")
+		b.sendMessage(callback.Message.Chat.ID, b.getErrorMessage(ctx, err))
+		return
+	}
+
+	text, keyboard := b.remindersSettings(ctx, enabled)
+	if _, err := b.tgService.EditMessage(callback.Message.Chat.ID, callback.Message.MessageID, text, &keyboard); err != nil {
+		b.logger.Error().Err(err).Int64("chat_id", callback.Message.Chat.ID).Msg("Failed to update reminders settings")
+	}
 }
 
-func timeUntilNextHour(hour int) time.Duration {
-	now := time.Now()
-	next := time.Date(now.Year(), now.Month(), now.Day(), hour, 0, 0, 0, now.Location())
-	if !next.After(now) {
-		next = next.Add(24 * time.Hour)
+func (b *Bot) remindersSettings(ctx context.Context, enabled bool) (string, tgbotapi.InlineKeyboardMarkup) {
+	text, button, data := b.t(ctx, "reminders.disabled"), b.t(ctx, "reminders.turn_on"), "set_reminders:on"
+	if enabled {
+		text, button, data = b.t(ctx, "reminders.enabled"), b.t(ctx, "reminders.turn_off"), "set_reminders:off"
 	}
-	return next.Sub(now)
+	return text, tgbotapi.NewInlineKeyboardMarkup(tgbotapi.NewInlineKeyboardRow(tgbotapi.NewInlineKeyboardButtonData(button, data)))
 }
@@ -34,6 +34,19 @@ func (b *Bot) SyncBookingsToSheets(ctx context.Context) {
 		b.logger.Info().Int("count", len(bookings)).Msg("Bookings successfully synced to Google Sheets")
 	}
 
+	// Лист выдачи и возврата за тот же период
+	b.syncHandoversSheet(ctx, startDate, endDate)
+
 	// Также синхронизируем расписание
 	b.SyncScheduleToSheets(ctx)
 }
+
+// syncHandoversSheet перезаписывает лист выдачи и возврата выдачами за период
+func (b *Bot) syncHandoversSheet(ctx context.Context, startDate, endDate time.Time) {
+	handovers, err := b.bookingService.GetHandoversByPeriod(ctx, startDate, endDate)
+	if err != nil {
+		b.logger.Error().Err(err).Msg("Failed to get handovers for Google Sheets sync")
+	} else if err := b.sheetsService.UpdateHandoversSheet(ctx, handovers); err != nil {
+		b.logger.Error().Err(err).Msg("Failed to sync handovers to Google Sheets")
+	}
+}
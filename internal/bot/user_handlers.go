@@ -33,14 +33,14 @@ func (b *Bot) handleMessage(ctx context.Context, update *tgbotapi.Update) {
 		return
 	}
 
-	if b.isManager(userID) && b.handleManagerCommand(ctx, update) {
+	if b.isStaff(userID) && b.handleManagerCommand(ctx, update) {
 		return
 	}
 
 	state := b.getUserState(ctx, userID)
 
 	// Обработка общих кнопок "Назад" и "Отмена"
-	if text == btnCancel || text == btnBack {
+	if b.isButton(text, btnCancel) || b.isButton(text, btnBack) {
 		b.handleCustomInput(ctx, update, state)
 		return
 	}
@@ -73,33 +73,53 @@ func (b *Bot) handleUserCommands(ctx context.Context, update *tgbotapi.Update, s
 
 func (b *Bot) handleBasicCommands(ctx context.Context, update *tgbotapi.Update, text string) bool {
 	switch {
-	case text == "/start" || strings.EqualFold(text, "сброс") || strings.EqualFold(text, "reset"):
+	case text == "/start" || strings.HasPrefix(text, "/start ") || strings.EqualFold(text, "сброс") || strings.EqualFold(text, "reset"):
 		b.clearUserState(ctx, update.Message.From.ID)
 		b.handleStartWithUserTracking(ctx, update)
 		return true
 
-	case text == btnManagerContacts:
+	case b.isButton(text, btnManagerContacts):
 		b.showManagerContacts(ctx, update)
 		return true
 
-	case text == btnMyBookings:
+	case b.isButton(text, btnMyBookings):
 		b.showUserBookings(ctx, update)
 		return true
+
+	case text == "/mydata":
+		b.handleMyDataCommand(ctx, update)
+		return true
+
+	case text == "/forget":
+		b.handleForgetCommand(ctx, update)
+		return true
+
+	case text == "/language":
+		b.handleLanguageCommand(ctx, update.Message.Chat.ID)
+		return true
+
+	case text == "/reminders":
+		b.handleRemindersCommand(ctx, update.Message.Chat.ID, update.Message.From.ID)
+		return true
+
+	case text == "/ics" || strings.HasPrefix(text, "/ics "):
+		b.handleICSCommand(ctx, update)
+		return true
 	}
 	return false
 }
 
 func (b *Bot) handleNavigationCommands(ctx context.Context, update *tgbotapi.Update, text string) bool {
 	switch {
-	case text == btnAvailableItems:
+	case b.isButton(text, btnAvailableItems):
 		b.showAvailableItems(ctx, update)
 		return true
 
-	case text == btnViewSchedule:
+	case b.isButton(text, btnViewSchedule):
 		b.handleViewSchedule(ctx, update)
 		return true
 
-	case text == btnBackToItems:
+	case b.isButton(text, btnBackToItems):
 		b.handleViewSchedule(ctx, update)
 		return true
 	}
@@ -108,29 +128,29 @@ func (b *Bot) handleNavigationCommands(ctx context.Context, update *tgbotapi.Upd
 
 func (b *Bot) handleBookingProcessCommands(ctx context.Context, update *tgbotapi.Update, state *models.UserState, text string) bool {
 	switch {
-	case text == btnCreateBooking:
+	case b.isButton(text, btnCreateBooking):
 		b.handleSelectItem(ctx, update)
 		return true
 
-	case text == btnMonthSchedule:
+	case b.isButton(text, btnMonthSchedule):
 		if state != nil && state.TempData["item_id"] != nil {
 			b.showMonthScheduleForItem(ctx, update)
 		} else {
-			b.sendMessage(update.Message.Chat.ID, "Сначала выберите аппарат для просмотра расписания")
+			b.sendMessage(update.Message.Chat.ID, b.t(ctx, "schedule.select_item_first"))
 			b.handleViewSchedule(ctx, update)
 		}
 		return true
 
-	case text == btnPickDate:
+	case b.isButton(text, btnPickDate):
 		if state != nil && state.TempData["item_id"] != nil {
 			b.requestSpecificDate(ctx, update)
 		} else {
-			b.sendMessage(update.Message.Chat.ID, "Сначала выберите аппарат для просмотра расписания")
+			b.sendMessage(update.Message.Chat.ID, b.t(ctx, "schedule.select_item_first"))
 			b.handleViewSchedule(ctx, update)
 		}
 		return true
 
-	case text == btnCreateForItem:
+	case b.isButton(text, btnCreateForItem):
 		if state != nil && state.TempData["item_id"] != nil {
 			itemID := state.GetInt64("item_id")
 			b.handleDateSelection(ctx, update, itemID)
@@ -143,26 +163,13 @@ func (b *Bot) handleBookingProcessCommands(ctx context.Context, update *tgbotapi
 
 // handleUserStateSteps обрабатывает ввод пользователя в зависимости от текущего шага
 func (b *Bot) handleUserStateSteps(ctx context.Context, update *tgbotapi.Update, text string, state *models.UserState) bool {
-	userID := update.Message.From.ID
-
-	switch state.CurrentStep {
-	case models.StateEnterName:
-		state.TempData["user_name"] = b.sanitizeInput(text)
-		b.setUserState(ctx, userID, models.StatePhoneNumber, state.TempData)
-		b.handlePhoneRequest(ctx, update)
-		return true
-
-	case models.StatePhoneNumber:
-		b.handlePhoneReceived(ctx, update, text)
+	if b.handleDialogMessage(ctx, update, state, text) {
 		return true
+	}
 
-	case models.StateWaitingSpecificDate:
+	if state.CurrentStep == models.StateWaitingSpecificDate {
 		b.handleSpecificDateInput(ctx, update, text)
 		return true
-
-	case models.StateWaitingDate:
-		b.handleDateInput(ctx, update, text, state)
-		return true
 	}
 
 	return false
@@ -184,6 +191,11 @@ func (b *Bot) handleStartWithUserTracking(ctx context.Context, update *tgbotapi.
 		b.logger.Error().Err(err).Int64("user_id", user.TelegramID).Msg("Error tracking user")
 	}
 
+	if payload := strings.TrimSpace(strings.TrimPrefix(update.Message.Text, "/start")); payload != "" &&
+		b.handleStartPayload(ctx, update, payload) {
+		return
+	}
+
 	b.handleMainMenu(ctx, update)
 }
 
@@ -2,12 +2,11 @@ package bot
 
 import (
 	"context"
-	"errors"
 	"fmt"
+	"slices"
 	"strings"
 	"time"
 
-	"bronivik/internal/database"
 	"bronivik/internal/models"
 
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
@@ -50,6 +49,70 @@ func (b *Bot) isManager(userID int64) bool {
 	return b.userService.IsManager(userID)
 }
 
+// isStaff возвращает true, если у пользователя есть любая роль (админ, менеджер, наблюдатель)
+func (b *Bot) isStaff(userID int64) bool {
+	return b.userService.GetRole(userID) != ""
+}
+
+func (b *Bot) hasPermission(userID int64, perm string) bool {
+	return b.userService.HasPermission(userID, perm)
+}
+
+func (b *Bot) canManageItem(userID, itemID int64) bool {
+	return b.userService.CanManageItem(userID, itemID)
+}
+
+// denyWithoutPermission сообщает об отказе и возвращает true, если у пользователя нет права perm
+func (b *Bot) denyWithoutPermission(ctx context.Context, chatID, userID int64, perm string) bool {
+	if b.hasPermission(userID, perm) {
+		return false
+	}
+	b.sendMessage(chatID, b.t(ctx, msgAccessDenied))
+	return true
+}
+
+// denyWithoutItemAccess сообщает об отказе, если аппарат вне области роли пользователя
+func (b *Bot) denyWithoutItemAccess(ctx context.Context, chatID, userID, itemID int64) bool {
+	if b.canManageItem(userID, itemID) {
+		return false
+	}
+	b.sendMessage(chatID, b.t(ctx, msgAccessDenied))
+	return true
+}
+
+// scopeBookingFilter ограничивает фильтр поиска аппаратами из области роли пользователя, оставляя
+// из выбранных в фильтре только доступные. Область применяется в запросе, а не после него: иначе
+// лимит MaxSearchResults заполняют заявки на чужие аппараты. Возвращает false, если искать нечего.
+func (b *Bot) scopeBookingFilter(userID int64, filter *models.BookingFilter) bool {
+	scope, scoped := b.userService.ItemScope(userID)
+	if !scoped {
+		return true
+	}
+	if len(filter.ItemIDs) == 0 {
+		filter.ItemIDs = scope
+		return len(scope) > 0
+	}
+	ids := make([]int64, 0, len(filter.ItemIDs))
+	for _, id := range filter.ItemIDs {
+		if slices.Contains(scope, id) {
+			ids = append(ids, id)
+		}
+	}
+	filter.ItemIDs = ids
+	return len(ids) > 0
+}
+
+// filterBookingsByScope оставляет только заявки на аппараты, доступные пользователю
+func (b *Bot) filterBookingsByScope(userID int64, bookings []*models.Booking) []*models.Booking {
+	filtered := make([]*models.Booking, 0, len(bookings))
+	for _, booking := range bookings {
+		if b.canManageItem(userID, booking.ItemID) {
+			filtered = append(filtered, booking)
+		}
+	}
+	return filtered
+}
+
 func (b *Bot) getItemByID(id int64) (models.Item, bool) {
 	item, err := b.itemService.GetItemByID(context.Background(), id)
 	if err != nil || item == nil {
@@ -85,8 +148,7 @@ func (b *Bot) handleMainMenu(ctx context.Context, update *tgbotapi.Update) {
 
 	b.updateUserActivity(userID)
 
-	msg := tgbotapi.NewMessage(chatID,
-		"Добро пожаловать! Выберите действие:")
+	msg := tgbotapi.NewMessage(chatID, b.t(ctx, "menu.welcome"))
 
 	rows := make([][]tgbotapi.KeyboardButton, 0, 5)
 
@@ -94,15 +156,15 @@ func (b *Bot) handleMainMenu(ctx context.Context, update *tgbotapi.Update) {
 	if !b.isManager(userID) {
 		rows = append(rows,
 			tgbotapi.NewKeyboardButtonRow(
-				tgbotapi.NewKeyboardButton(btnCreateBooking),
+				tgbotapi.NewKeyboardButton(b.t(ctx, btnCreateBooking)),
 			),
 			tgbotapi.NewKeyboardButtonRow(
-				tgbotapi.NewKeyboardButton(btnViewSchedule),
-				tgbotapi.NewKeyboardButton(btnAvailableItems),
+				tgbotapi.NewKeyboardButton(b.t(ctx, btnViewSchedule)),
+				tgbotapi.NewKeyboardButton(b.t(ctx, btnAvailableItems)),
 			),
 			tgbotapi.NewKeyboardButtonRow(
-				tgbotapi.NewKeyboardButton(btnMyBookings),
-				tgbotapi.NewKeyboardButton(btnManagerContacts),
+				tgbotapi.NewKeyboardButton(b.t(ctx, btnMyBookings)),
+				tgbotapi.NewKeyboardButton(b.t(ctx, btnManagerContacts)),
 			),
 		)
 	}
@@ -111,14 +173,15 @@ func (b *Bot) handleMainMenu(ctx context.Context, update *tgbotapi.Update) {
 	if b.isManager(userID) {
 		rows = append(rows,
 			tgbotapi.NewKeyboardButtonRow(
-				tgbotapi.NewKeyboardButton(btnAllBookings),
+				tgbotapi.NewKeyboardButton(b.t(ctx, btnAllBookings)),
+				tgbotapi.NewKeyboardButton(b.t(ctx, btnSearchBookings)),
 			),
 			tgbotapi.NewKeyboardButtonRow(
-				tgbotapi.NewKeyboardButton(btnCreateBookingManager),
+				tgbotapi.NewKeyboardButton(b.t(ctx, btnCreateBookingManager)),
 			),
 			tgbotapi.NewKeyboardButtonRow(
-				tgbotapi.NewKeyboardButton(btnSyncBookings),
-				tgbotapi.NewKeyboardButton(btnSyncSchedule),
+				tgbotapi.NewKeyboardButton(b.t(ctx, btnSyncBookings)),
+				tgbotapi.NewKeyboardButton(b.t(ctx, btnSyncSchedule)),
 			),
 		)
 	}
@@ -132,14 +195,14 @@ func (b *Bot) handleMainMenu(ctx context.Context, update *tgbotapi.Update) {
 }
 
 // showManagerContacts показывает контакты менеджеров
-func (b *Bot) showManagerContacts(_ context.Context, update *tgbotapi.Update) {
+func (b *Bot) showManagerContacts(ctx context.Context, update *tgbotapi.Update) {
 	contacts := b.config.ManagersContacts
 	var message strings.Builder
-	message.WriteString("📞 Контакты менеджера:\n\n")
+	message.WriteString(b.t(ctx, "contacts.title") + "\n\n")
 	for _, contact := range contacts {
 		message.WriteString(fmt.Sprintf("🔹 %s\n", contact))
 	}
-	message.WriteString("\nПо любым интересующим Вас вопросам, дадим ответ.")
+	message.WriteString("\n" + b.t(ctx, "contacts.footer"))
 
 	msg := tgbotapi.NewMessage(update.Message.Chat.ID, message.String())
 	if _, err := b.tgService.Send(msg); err != nil {
@@ -152,180 +215,42 @@ func (b *Bot) showUserBookings(ctx context.Context, update *tgbotapi.Update) {
 	bookings, err := b.userService.GetUserBookings(ctx, update.Message.From.ID)
 	if err != nil {
 		b.logger.Error().Err(err).Int64("user_id", update.Message.From.ID).Msg("Error getting user bookings")
-		b.sendMessage(update.Message.Chat.ID, "Ошибка при получении заявок")
+		b.sendMessage(update.Message.Chat.ID, b.t(ctx, "error.bookings_list"))
 		return
 	}
 
 	var message strings.Builder
-	message.WriteString("📊 Ваши заявки (за последние 2 недели и предстоящие):\n\n")
+	message.WriteString(b.t(ctx, "user_bookings.title") + "\n\n")
 
 	for _, booking := range bookings {
-		statusEmoji := statusPending
-		switch booking.Status {
-		case "confirmed":
-			statusEmoji = statusSuccess
-		case "canceled":
-			statusEmoji = statusError
-		case "changed":
-			statusEmoji = "🔄"
-		case "completed":
-			statusEmoji = "🏁"
-		}
-
-		message.WriteString(fmt.Sprintf("%s Заявка #%d\n", statusEmoji, booking.ID))
-		message.WriteString(fmt.Sprintf("   🏢 %s\n", booking.ItemName))
-		message.WriteString(fmt.Sprintf("   📅 %s\n", booking.Date.Format("02.01.2006")))
-		message.WriteString(fmt.Sprintf("   📊 Статус: %s\n\n", booking.Status))
+		message.WriteString(b.userBookingText(ctx, booking) + "\n\n")
 	}
 
 	if len(bookings) == 0 {
-		message.WriteString("У вас пока нет заявок")
+		message.WriteString(b.t(ctx, "user_bookings.empty"))
 	}
 
 	b.sendMessage(update.Message.Chat.ID, message.String())
 }
 
-// Добавляем метод для запроса имени
-func (b *Bot) handleNameRequest(ctx context.Context, update *tgbotapi.Update) {
-	b.debugState(ctx, update.Message.From.ID, "handleNameRequest START")
-
-	msg := tgbotapi.NewMessage(update.Message.Chat.ID,
-		"Пожалуйста, введите ваше ФИО для заявки:")
-
-	keyboard := tgbotapi.NewReplyKeyboard(
-		tgbotapi.NewKeyboardButtonRow(
-			tgbotapi.NewKeyboardButton(btnManagerContacts),
-			tgbotapi.NewKeyboardButton(btnCancel),
-		),
-		tgbotapi.NewKeyboardButtonRow(
-			tgbotapi.NewKeyboardButton(btnBack),
-		),
-	)
-	msg.ReplyMarkup = keyboard
-
-	state := b.getUserState(ctx, update.Message.From.ID)
-
-	b.setUserState(ctx, update.Message.From.ID, models.StateEnterName, state.TempData)
-
-	b.debugState(ctx, update.Message.From.ID, "handleNameRequest END")
-	if _, err := b.tgService.Send(msg); err != nil {
-		b.logger.Error().Err(err).Int64("user_id", update.Message.From.ID).Msg("Failed to send name request")
-	}
-}
-
-// Обновляем handlePhoneRequest - добавляем контакты
-func (b *Bot) handlePhoneRequest(ctx context.Context, update *tgbotapi.Update) {
-	msg := tgbotapi.NewMessage(update.Message.Chat.ID,
-		"Пожалуйста, предоставьте ваш номер телефона для связи:\n"+
-			"Вы можете предоставить разрешение на использование номера из контакта телеграмм\n"+
-			"Либо введите номер телефона для связи")
-
-	keyboard := tgbotapi.NewReplyKeyboard(
-		tgbotapi.NewKeyboardButtonRow(
-			tgbotapi.NewKeyboardButtonContact("📱 Отправить номер телефона из вашего контакта в телеграмм"),
-		),
-		tgbotapi.NewKeyboardButtonRow(
-			tgbotapi.NewKeyboardButton(btnManagerContacts),
-			tgbotapi.NewKeyboardButton(btnCancel),
-		),
-		tgbotapi.NewKeyboardButtonRow(
-			tgbotapi.NewKeyboardButton(btnBack),
-		),
-	)
-	msg.ReplyMarkup = keyboard
-
-	state := b.getUserState(ctx, update.Message.From.ID)
-
-	b.setUserState(ctx, update.Message.From.ID, models.StatePhoneNumber, state.TempData)
-	if _, err := b.tgService.Send(msg); err != nil {
-		b.logger.Error().Err(err).Int64("user_id", update.Message.From.ID).Msg("Failed to send phone request")
-	}
-}
-
-// finalizeBooking обновляем для использования имени
-func (b *Bot) finalizeBooking(ctx context.Context, update *tgbotapi.Update) {
-	state := b.getUserState(ctx, update.Message.From.ID)
-	if state == nil {
-		b.sendMessage(update.Message.Chat.ID, "Сессия устарела. Начните заново.")
-		b.handleMainMenu(ctx, update)
-		return
-	}
-
-	// Получаем данные из состояния
-	itemID := state.GetInt64("item_id")
-	date := state.GetTime("date")
-	phone, _ := state.TempData["phone"].(string)
-	userName, ok := state.TempData["user_name"].(string)
-	if !ok {
-		// Если имя не было введено, используем имя из Telegram
-		userName = update.Message.From.FirstName + " " + update.Message.From.LastName
-	}
-
-	// Находим элемент по ID
-	selectedItem, ok := b.getItemByID(itemID)
-
-	if !ok {
-		b.sendMessage(update.Message.Chat.ID, "Ошибка: выбранная позиция не найдена.")
-		b.handleMainMenu(ctx, update)
-		return
-	}
-
-	// Создаем бронирование
-	booking := models.Booking{
-		UserID:       update.Message.From.ID,
-		UserName:     userName,
-		UserNickname: update.Message.From.FirstName + " " + update.Message.From.LastName,
-		Phone:        phone,
-		ItemID:       selectedItem.ID,
-		ItemName:     selectedItem.Name,
-		Date:         date,
-		Status:       "pending",
-		CreatedAt:    time.Now(),
-		UpdatedAt:    time.Now(),
-	}
-
-	start := time.Now()
-	err := b.bookingService.CreateBooking(ctx, &booking)
-	if err != nil {
-		b.logger.Error().Err(err).Int64("user_id", update.Message.From.ID).Msg("Error creating booking")
-		b.sendMessage(update.Message.Chat.ID, b.getErrorMessage(err))
-		if errors.Is(err, database.ErrNotAvailable) || errors.Is(err, database.ErrPastDate) {
-			b.handleMainMenu(ctx, update)
-		}
-		return
-	}
-
-	// Track metrics
-	if b.metrics != nil {
-		b.metrics.BookingsCreated.WithLabelValues(selectedItem.Name).Inc()
-		b.metrics.BookingDuration.WithLabelValues(selectedItem.Name).Observe(time.Since(start).Seconds())
-	}
-
-	// Уведомляем менеджеров
-	b.notifyManagers(&booking)
-
-	msg := tgbotapi.NewMessage(update.Message.Chat.ID,
-		fmt.Sprintf("⏳ Ваша заявка #%d на позицию %s успешно создана. \nОжидайте подтверждения.", booking.ID, booking.ItemName))
-
-	// Очищаем состояние
-	b.clearUserState(ctx, update.Message.From.ID)
-	b.handleMainMenu(ctx, update)
-	if _, err := b.tgService.Send(msg); err != nil {
-		b.logger.Error().Err(err).Msg("Failed to send final booking msg")
-	}
-}
-
-// handleContactReceived обработка полученного контакта
-func (b *Bot) handleContactReceived(ctx context.Context, update *tgbotapi.Update) {
-	state := b.getUserState(ctx, update.Message.From.ID)
-	if state == nil {
-		b.handleMainMenu(ctx, update)
-		return
-	}
-
-	if state.CurrentStep == models.StatePhoneNumber {
-		b.handlePhoneReceived(ctx, update, update.Message.Contact.PhoneNumber)
-	}
+// userBookingText - заявка в списке заявок пользователя
+func (b *Bot) userBookingText(ctx context.Context, booking *models.Booking) string {
+	statusEmoji := statusPending
+	switch booking.Status {
+	case "confirmed":
+		statusEmoji = statusSuccess
+	case "canceled":
+		statusEmoji = statusError
+	case "changed":
+		statusEmoji = "🔄"
+	case "completed":
+		statusEmoji = "🏁"
+	}
+
+	return b.t(ctx, "user_bookings.item", statusEmoji, booking.ID) + "\n" +
+		fmt.Sprintf("   🏢 %s\n", booking.ItemName) +
+		fmt.Sprintf("   📅 %s\n", booking.Date.Format("02.01.2006")) +
+		"   " + b.t(ctx, "user_bookings.status", b.statusName(ctx, booking.Status))
 }
 
 // handleViewSchedule - меню просмотра расписания
@@ -348,7 +273,7 @@ func (b *Bot) sendScheduleItemsPage(ctx context.Context, chatID int64, messageID
 		ChatID:       chatID,
 		MessageID:    messageID,
 		Page:         page,
-		Title:        "🏢 *Выберите аппарат для просмотра расписания:*",
+		Title:        b.t(ctx, "schedule.select_item_title"),
 		ItemPrefix:   "schedule_select_item:",
 		PagePrefix:   "schedule_items_page:",
 		BackCallback: "back_to_main_from_schedule",
@@ -388,26 +313,12 @@ func (b *Bot) handleSelectItem(ctx context.Context, update *tgbotapi.Update) {
 
 	// Сохраняем состояние
 	b.setUserState(ctx, userID, models.StateSelectItem, map[string]interface{}{
-		"page": 0,
+		"page":             0,
+		catalogCategoryKey: "",
 	})
 
-	// Отправляем первую страницу
-	b.sendItemsPage(ctx, chatID, messageID, 0)
-}
-
-// sendItemsPage отправляет страницу с аппаратами
-func (b *Bot) sendItemsPage(ctx context.Context, chatID int64, messageID, page int) {
-	b.renderPaginatedItems(&PaginationParams{
-		Ctx:          ctx,
-		ChatID:       chatID,
-		MessageID:    messageID,
-		Page:         page,
-		Title:        "🏢 *Доступные аппараты*",
-		ItemPrefix:   "select_item:",
-		PagePrefix:   "items_page:",
-		BackCallback: "back_to_main",
-		ShowCapacity: false,
-	})
+	// Отправляем первую страницу корня каталога
+	b.sendItemsPage(ctx, chatID, messageID, "", 0)
 }
 
 // showAvailableItems показывает доступные позиции
@@ -415,22 +326,25 @@ func (b *Bot) showAvailableItems(ctx context.Context, update *tgbotapi.Update) {
 	items, err := b.itemService.GetActiveItems(ctx)
 	if err != nil {
 		b.logger.Error().Err(err).Msg("Error getting active items")
-		b.sendMessage(update.Message.Chat.ID, "Ошибка при получении списка аппаратов")
+		b.sendMessage(update.Message.Chat.ID, b.t(ctx, "error.items_list"))
 		return
 	}
 	var message strings.Builder
-	message.WriteString("🏢 Доступные позиции:\n\n")
+	message.WriteString(b.t(ctx, "items.available_title") + "\n\n")
 
 	for _, item := range items {
 		message.WriteString(fmt.Sprintf("🔹 %s\n", item.Name))
 		message.WriteString(fmt.Sprintf("   %s\n", item.Description))
+		if item.PricePerDay > 0 {
+			message.WriteString("   " + b.t(ctx, "items.price", item.PricePerDay) + "\n")
+		}
 		message.WriteString("\n")
 	}
 
 	keyboard := make([][]tgbotapi.InlineKeyboardButton, 0, 1)
 
 	keyboard = append(keyboard, []tgbotapi.InlineKeyboardButton{
-		tgbotapi.NewInlineKeyboardButtonData(btnCreateBooking, "start_the_order"),
+		tgbotapi.NewInlineKeyboardButtonData(b.t(ctx, btnCreateBooking), "start_the_order"),
 	})
 
 	markup := tgbotapi.NewInlineKeyboardMarkup(keyboard...)
@@ -447,14 +361,14 @@ func (b *Bot) showAvailableItems(ctx context.Context, update *tgbotapi.Update) {
 func (b *Bot) showMonthScheduleForItem(ctx context.Context, update *tgbotapi.Update) {
 	state := b.getUserState(ctx, update.Message.From.ID)
 	if state == nil || state.TempData["item_id"] == nil {
-		b.sendMessage(update.Message.Chat.ID, "Ошибка: аппарат не выбран")
+		b.sendMessage(update.Message.Chat.ID, b.t(ctx, "error.item_not_selected"))
 		return
 	}
 
 	itemID := state.GetInt64("item_id")
 	selectedItem, ok := b.getItemByID(itemID)
 	if !ok {
-		b.sendMessage(update.Message.Chat.ID, "Ошибка: аппарат не найден")
+		b.sendMessage(update.Message.Chat.ID, b.t(ctx, "error.item_not_found"))
 		return
 	}
 	startDate := time.Now()
@@ -462,22 +376,23 @@ func (b *Bot) showMonthScheduleForItem(ctx context.Context, update *tgbotapi.Upd
 	availability, err := b.bookingService.GetAvailability(ctx, selectedItem.ID, startDate, 30)
 	if err != nil {
 		b.logger.Error().Err(err).Int64("item_id", selectedItem.ID).Msg("Error getting availability")
-		b.sendMessage(update.Message.Chat.ID, "Ошибка при получении расписания")
+		b.sendMessage(update.Message.Chat.ID, b.t(ctx, "error.schedule"))
 		return
 	}
 
 	var message strings.Builder
-	message.WriteString(fmt.Sprintf("📅 *Расписание %s*\n", selectedItem.Name))
-	message.WriteString("На ближайшие 30 дней:\n\n")
+	message.WriteString(b.t(ctx, "schedule.month_title", selectedItem.Name) + "\n")
+	message.WriteString(b.t(ctx, "schedule.month_subtitle") + "\n\n")
 
 	message.WriteString("```\n")
-	message.WriteString("Дата     Статус\n")
+	message.WriteString(b.t(ctx, "schedule.table_header") + "\n")
 	message.WriteString("───────  ──────────\n")
 
+	free, busy := b.t(ctx, "schedule.free"), b.t(ctx, "schedule.busy")
 	for _, avail := range availability {
-		status := "✅ Свободно"
+		status := free
 		if avail.Available == 0 {
-			status = "❌ Занято  "
+			status = busy
 		}
 
 		message.WriteString(fmt.Sprintf("%s   %s\n",
@@ -488,7 +403,7 @@ func (b *Bot) showMonthScheduleForItem(ctx context.Context, update *tgbotapi.Upd
 	keyboard := make([][]tgbotapi.InlineKeyboardButton, 0, 1)
 
 	keyboard = append(keyboard, []tgbotapi.InlineKeyboardButton{
-		tgbotapi.NewInlineKeyboardButtonData(btnCreateForItem, "start_the_order_item"),
+		tgbotapi.NewInlineKeyboardButtonData(b.t(ctx, btnCreateForItem), "start_the_order_item"),
 	})
 
 	markup := tgbotapi.NewInlineKeyboardMarkup(keyboard...)
@@ -505,21 +420,20 @@ func (b *Bot) showMonthScheduleForItem(ctx context.Context, update *tgbotapi.Upd
 func (b *Bot) handleSpecificDateInput(ctx context.Context, update *tgbotapi.Update, dateStr string) {
 	state := b.getUserState(ctx, update.Message.From.ID)
 	if state == nil || state.TempData["item_id"] == nil {
-		b.sendMessage(update.Message.Chat.ID, "Ошибка: аппарат не выбран")
+		b.sendMessage(update.Message.Chat.ID, b.t(ctx, "error.item_not_selected"))
 		return
 	}
 
 	itemID := state.GetInt64("item_id")
 	selectedItem, ok := b.getItemByID(itemID)
 	if !ok {
-		b.sendMessage(update.Message.Chat.ID, "Ошибка: аппарат не найден")
+		b.sendMessage(update.Message.Chat.ID, b.t(ctx, "error.item_not_found"))
 		return
 	}
 
 	date, err := time.Parse("02.01.2006", dateStr)
 	if err != nil {
-		msg := tgbotapi.NewMessage(update.Message.Chat.ID,
-			"Неверный формат даты. Используйте ДД.ММ.ГГГГ (например, 25.12.2024)")
+		msg := tgbotapi.NewMessage(update.Message.Chat.ID, b.t(ctx, "error.invalid_date"))
 		if _, errSend := b.tgService.Send(msg); errSend != nil {
 			b.logger.Error().Err(errSend).Msg("Failed to send invalid date format msg in handleSpecificDateInput")
 		}
@@ -529,17 +443,17 @@ func (b *Bot) handleSpecificDateInput(ctx context.Context, update *tgbotapi.Upda
 	available, err := b.bookingService.CheckAvailability(ctx, selectedItem.ID, date)
 	if err != nil {
 		b.logger.Error().Err(err).Int64("item_id", selectedItem.ID).Time("date", date).Msg("Error checking availability")
-		b.sendMessage(update.Message.Chat.ID, "Ошибка при проверке доступности")
+		b.sendMessage(update.Message.Chat.ID, b.t(ctx, "error.availability"))
 		return
 	}
 
-	status := "✅ Доступно"
+	status := b.t(ctx, "schedule.available")
 	if !available {
-		status = "❌ Недоступно"
+		status = b.t(ctx, "schedule.unavailable")
 	}
 
 	booked, _ := b.bookingService.GetBookedCount(ctx, selectedItem.ID, date)
-	message := fmt.Sprintf("📅 Доступность *%s* на %s:\n\n%s\n\nЗабронировано: %d/%d",
+	message := b.t(ctx, "schedule.date_availability",
 		selectedItem.Name,
 		date.Format("02.01.2006"),
 		status,
@@ -555,8 +469,7 @@ func (b *Bot) handleSpecificDateInput(ctx context.Context, update *tgbotapi.Upda
 
 // requestSpecificDate запрашивает у пользователя конкретную дату
 func (b *Bot) requestSpecificDate(ctx context.Context, update *tgbotapi.Update) {
-	msg := tgbotapi.NewMessage(update.Message.Chat.ID,
-		"Введите дату в формате ДД.ММ.ГГГГ (например, 25.12.2025):")
+	msg := tgbotapi.NewMessage(update.Message.Chat.ID, b.t(ctx, "schedule.enter_date"))
 
 	b.setUserState(ctx, update.Message.From.ID, models.StateWaitingSpecificDate, nil)
 	if _, err := b.tgService.Send(msg); err != nil {
@@ -567,7 +480,7 @@ func (b *Bot) requestSpecificDate(ctx context.Context, update *tgbotapi.Update)
 // handleCustomInput ...
 func (b *Bot) handleCustomInput(ctx context.Context, update *tgbotapi.Update, state *models.UserState) {
 	if state == nil {
-		b.sendMessage(update.Message.Chat.ID, "Неизвестная команда. Используйте меню.")
+		b.sendMessage(update.Message.Chat.ID, b.t(ctx, "error.unknown_command"))
 		b.handleMainMenu(ctx, update)
 		return
 	}
@@ -575,29 +488,19 @@ func (b *Bot) handleCustomInput(ctx context.Context, update *tgbotapi.Update, st
 	text := update.Message.Text
 	userID := update.Message.From.ID
 
-	// Обработка общих кнопок "Назад" и "Отмена"
-	if text == btnCancel {
-		b.clearUserState(ctx, userID)
-		b.sendMessage(update.Message.Chat.ID, "❌ Действие отменено")
-		b.handleMainMenu(ctx, update)
+	// Кнопки "Назад" и "Отмена" внутри диалога обрабатывает сам диалог
+	if b.handleDialogMessage(ctx, update, state, text) {
 		return
 	}
 
-	if text == btnBack {
-		switch state.CurrentStep {
-		case models.StateEnterName:
-			b.handleDateSelection(ctx, update, state.GetInt64("item_id"))
-			return
-		case models.StatePhoneNumber:
-			b.handleNameRequest(ctx, update)
-			return
-		case models.StateWaitingDate:
-			b.handleSelectItem(ctx, update)
-			return
-		}
+	if b.isButton(text, btnCancel) {
+		b.clearUserState(ctx, userID)
+		b.sendMessage(update.Message.Chat.ID, b.t(ctx, "menu.action_canceled"))
+		b.handleMainMenu(ctx, update)
+		return
 	}
 
-	b.sendMessage(update.Message.Chat.ID, "Неизвестная команда. Используйте меню.")
+	b.sendMessage(update.Message.Chat.ID, b.t(ctx, "error.unknown_command"))
 	b.handleMainMenu(ctx, update)
 }
 
@@ -620,199 +523,9 @@ func (b *Bot) sanitizeInput(input string) string {
 	return strings.TrimSpace(replacer.Replace(input))
 }
 
-// handleDateInput обрабатывает ввод даты для бронирования
-func (b *Bot) handleDateInput(ctx context.Context, update *tgbotapi.Update, dateStr string, state *models.UserState) {
-	b.debugState(ctx, update.Message.From.ID, "handleDateInput START")
-
-	date, err := time.Parse("02.01.2006", dateStr)
-	if err != nil {
-		msg := tgbotapi.NewMessage(update.Message.Chat.ID,
-			"Неверный формат даты. Используйте ДД.ММ.ГГГГ (например, 25.12.2024)")
-		if _, errSend := b.tgService.Send(msg); errSend != nil {
-			b.logger.Error().Err(errSend).Msg("Failed to send invalid date format msg in handleDateInput")
-		}
-		return
-	}
-
-	// Валидация даты через сервис
-	if errVal := b.bookingService.ValidateBookingDate(date); errVal != nil {
-		b.sendMessage(update.Message.Chat.ID, b.getErrorMessage(errVal))
-		return
-	}
-
-	itemID := state.GetInt64("item_id")
-	item, ok := b.getItemByID(itemID)
-
-	if !ok {
-		b.sendMessage(update.Message.Chat.ID, "Ошибка: не найден выбранный элемент. Начните заново.")
-		b.handleMainMenu(ctx, update)
-		return
-	}
-
-	// Проверяем доступность
-	available, err := b.bookingService.CheckAvailability(ctx, item.ID, date)
-	if err != nil {
-		b.logger.Error().Err(err).Int64("item_id", item.ID).Time("date", date).Msg("Error checking availability")
-		msg := tgbotapi.NewMessage(update.Message.Chat.ID,
-			"Произошла ошибка при проверке доступности. Попробуйте позже.")
-		if _, errSend := b.tgService.Send(msg); errSend != nil {
-			b.logger.Error().Err(errSend).Msg("Failed to send error msg in handleDateInput")
-		}
-		return
-	}
-
-	if !available {
-		msg := tgbotapi.NewMessage(update.Message.Chat.ID,
-			"К сожалению, на выбранную дату позиция недоступна. Выберите другую дату.")
-		if _, errSend := b.tgService.Send(msg); errSend != nil {
-			b.logger.Error().Err(errSend).Msg("Failed to send not available msg in handleDateInput")
-		}
-		return
-	}
-
-	// Сохраняем данные в состоянии перед переходом
-	state.TempData["item_id"] = item.ID
-	state.TempData["date"] = date
-	b.setUserState(ctx, update.Message.From.ID, "waiting_date", state.TempData)
-
-	b.debugState(ctx, update.Message.From.ID, "handleDateInput END")
-
-	// Переходим к запросу персональных данных
-	b.handleNameRequest(ctx, update)
-}
-
-// restoreStateOrRestart восстанавливает состояние или начинает заново
-func (b *Bot) restoreStateOrRestart(ctx context.Context, update *tgbotapi.Update, requiredFields ...string) bool {
-	state := b.getUserState(ctx, update.Message.From.ID)
-	if state == nil {
-		b.sendMessage(update.Message.Chat.ID, "Сессия устарела. Начните заново.")
-		b.handleMainMenu(ctx, update)
-		return false
-	}
-
-	for _, field := range requiredFields {
-		if _, exists := state.TempData[field]; !exists {
-			b.sendMessage(update.Message.Chat.ID,
-				fmt.Sprintf("Ошибка: отсутствуют данные (%s). Начните заново.", field))
-			b.handleMainMenu(ctx, update)
-			return false
-		}
-	}
-
-	return true
-}
-
-// Добавьте этот метод в utils.go для отладки
-func (b *Bot) debugState(ctx context.Context, userID int64, message string) {
-	state := b.getUserState(ctx, userID)
-	if state != nil {
-		b.logger.Debug().
-			Int64("user_id", userID).
-			Str("step", state.CurrentStep).
-			Interface("temp_data", state.TempData).
-			Msg(message)
-	} else {
-		b.logger.Debug().Int64("user_id", userID).Msg(message + " (state is nil)")
-	}
-}
-
-// handlePhoneReceived обработка полученного номера телефона
-func (b *Bot) handlePhoneReceived(ctx context.Context, update *tgbotapi.Update, phone string) {
-	b.debugState(ctx, update.Message.From.ID, "handlePhoneReceived START")
-
-	// Проверяем и восстанавливаем состояние
-	if !b.restoreStateOrRestart(ctx, update, "item_id", "date") {
-		return
-	}
-
-	state := b.getUserState(ctx, update.Message.From.ID)
-
-	// Проверяем и нормализуем номер телефона
-	normalizedPhone := b.normalizePhone(phone)
-	if normalizedPhone == "" {
-		b.sendMessage(update.Message.Chat.ID, "Неверный формат номера телефона. Пожалуйста, введите номер в формате +7XXXXXXXXXX или 8XXXXXXXXXX")
-		return
-	}
-
-	// Получаем данные из состояния
-	itemID := state.GetInt64("item_id")
-	date := state.GetTime("date")
-
-	// Находим выбранный элемент по ID
-	selectedItem, ok := b.getItemByID(itemID)
-
-	if !ok {
-		b.sendMessage(update.Message.Chat.ID, "Ошибка: выбранная позиция не найдена. Начните заново.")
-		b.handleMainMenu(ctx, update)
-		return
-	}
-
-	state.TempData["phone"] = normalizedPhone
-	state.TempData["item_id"] = selectedItem.ID // Сохраняем ID элемента для подтверждения
-	b.setUserState(ctx, update.Message.From.ID, models.StateConfirmation, state.TempData)
-
-	b.debugState(ctx, update.Message.From.ID, "handlePhoneReceived END")
-
-	// Сохраняем телефон пользователя
-	b.updateUserPhone(update.Message.From.ID, normalizedPhone)
-
-	// Проверяем доступность еще раз
-	available, err := b.bookingService.CheckAvailability(ctx, selectedItem.ID, date)
-	if err != nil || !available {
-		msg := tgbotapi.NewMessage(update.Message.Chat.ID,
-			"К сожалению, выбранная позиция больше не доступна на эту дату. Пожалуйста, начните заново.")
-		if _, errSend := b.tgService.Send(msg); errSend != nil {
-			b.logger.Error().Err(errSend).Msg("Failed to send not available msg in handlePhoneReceived")
-		}
-		b.handleMainMenu(ctx, update)
-		return
-	}
-
-	name, ok := state.TempData["user_name"].(string)
-	if !ok {
-		name = ""
-	}
-
-	msg := tgbotapi.NewMessage(update.Message.Chat.ID,
-		fmt.Sprintf(`📋 Подтверждение заявки:
-
-🏢 Позиция: %s
-📅 Дата: %s
-👤 Имя: %s
-📱 Телефон: %s`,
-			selectedItem.Name,
-			date.Format("02.01.2006"),
-			name,
-			normalizedPhone))
-
-	if _, err := b.tgService.Send(msg); err != nil {
-		b.logger.Error().Err(err).Msg("Failed to send confirmation msg in handlePhoneReceived")
-	}
-	b.finalizeBooking(ctx, update)
-}
-
 // normalizePhone нормализует номер телефона
 func (b *Bot) normalizePhone(phone string) string {
-	// Удаляем все нецифровые символы
-	cleaned := ""
-	for _, char := range phone {
-		if char >= '0' && char <= '9' {
-			cleaned += string(char)
-		}
-	}
-
-	// Обрабатываем разные форматы номеров
-	if len(cleaned) == 11 {
-		if cleaned[0] == '8' {
-			return "7" + cleaned[1:] // 8XXXXXXXXXX -> 7XXXXXXXXXX
-		} else if cleaned[0] == '7' {
-			return cleaned // 7XXXXXXXXXX
-		}
-	} else if len(cleaned) == 10 {
-		return "7" + cleaned // XXXXXXXXXX -> 7XXXXXXXXXX
-	}
-
-	return "" // Неверный формат
+	return models.NormalizePhone(phone)
 }
 
 // formatPhoneForDisplay форматирует номер телефона для красивого отображения
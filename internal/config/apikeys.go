@@ -0,0 +1,260 @@
+package config
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"bronivik/internal/models"
+
+	"gopkg.in/yaml.v3"
+)
+
+var (
+	ErrAPIKeyExists   = errors.New("api key with this name already exists")
+	ErrAPIKeyNotFound = errors.New("api key not found")
+)
+
+// GenerateAPIKey создает клиента API со случайными ключом и дополнительным секретом
+func GenerateAPIKey(name, role string, permissions []string) (APIClientKey, error) {
+	if name == "" {
+		return APIClientKey{}, errors.New("api key name is required")
+	}
+	if role != "" && !models.IsValidRole(role) {
+		return APIClientKey{}, fmt.Errorf("unknown role '%s'", role)
+	}
+
+	key, err := randomHex(24)
+	if err != nil {
+		return APIClientKey{}, err
+	}
+	extra, err := randomHex(16)
+	if err != nil {
+		return APIClientKey{}, err
+	}
+	return APIClientKey{Key: key, Extra: extra, Name: name, Role: role, Permissions: permissions}, nil
+}
+
+func randomHex(n int) (string, error) {
+	buf := make([]byte, n)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("failed to generate api key: %w", err)
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+// AddAPIKey дописывает клиента в api.auth.api_keys файла конфигурации. Подстановки ${VAR}
+// не раскрываются, а остальной текст файла с комментариями и отступами остается как был.
+func AddAPIKey(configPath string, key APIClientKey) error {
+	return editConfigFile(configPath, func(lines []string, root *yaml.Node) ([]string, error) {
+		keys := mappingPath(root, false, "api", "auth", "api_keys")
+		if keys != nil && keys.Kind == yaml.SequenceNode && apiKeyIndex(keys, key.Name) >= 0 {
+			return nil, fmt.Errorf("%w: %s", ErrAPIKeyExists, key.Name)
+		}
+
+		var node yaml.Node
+		if err := node.Encode(key); err != nil {
+			return nil, err
+		}
+		if perms := mappingPath(&node, false, "permissions"); perms != nil {
+			perms.Style = yaml.FlowStyle
+		}
+
+		// Обычно список уже есть и записан блоком: новый элемент вставляется после последнего
+		if isBlockList(keys, lines) {
+			last := keys.Content[len(keys.Content)-1]
+			entry, err := listEntryLines(&node, leadingSpace(lines[last.Line-1]))
+			if err != nil {
+				return nil, err
+			}
+			at := lastLine(last)
+			return append(lines[:at:at], append(entry, lines[at:]...)...), nil
+		}
+
+		keys = mappingPath(root, true, "api", "auth", "api_keys")
+		switch {
+		case isNullNode(keys):
+			keys.Kind, keys.Tag, keys.Value = yaml.SequenceNode, "!!seq", ""
+		case keys == nil || keys.Kind != yaml.SequenceNode:
+			return nil, errors.New("api.auth.api_keys is not a list")
+		}
+		keys.Style = 0
+		keys.Content = append(keys.Content, &node)
+		return encodeLines(root)
+	})
+}
+
+// RemoveAPIKey удаляет клиента с указанным именем из api.auth.api_keys файла конфигурации
+func RemoveAPIKey(configPath, name string) error {
+	return editConfigFile(configPath, func(lines []string, root *yaml.Node) ([]string, error) {
+		keys := mappingPath(root, false, "api", "auth", "api_keys")
+		i := -1
+		if keys != nil && keys.Kind == yaml.SequenceNode {
+			i = apiKeyIndex(keys, name)
+		}
+		if i < 0 {
+			return nil, fmt.Errorf("%w: %s", ErrAPIKeyNotFound, name)
+		}
+
+		if isBlockList(keys, lines) {
+			item := keys.Content[i]
+			return append(lines[:item.Line-1:item.Line-1], lines[lastLine(item):]...), nil
+		}
+
+		keys.Content = append(keys.Content[:i], keys.Content[i+1:]...)
+		return encodeLines(root)
+	})
+}
+
+// editConfigFile читает файл конфигурации без подстановки переменных окружения,
+// применяет правку к его строкам и атомарно записывает результат
+func editConfigFile(configPath string, edit func(lines []string, root *yaml.Node) ([]string, error)) error {
+	info, err := os.Stat(configPath)
+	if err != nil {
+		return err
+	}
+	data, err := os.ReadFile(configPath)
+	if err != nil {
+		return err
+	}
+
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return fmt.Errorf("parse %s: %w", configPath, err)
+	}
+	if doc.Kind != yaml.DocumentNode || len(doc.Content) == 0 {
+		doc = yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{{Kind: yaml.MappingNode, Tag: "!!map"}}}
+	}
+	lines, err := edit(strings.Split(string(data), "\n"), doc.Content[0])
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(configPath), filepath.Base(configPath)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.WriteString(strings.Join(lines, "\n")); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Chmod(info.Mode().Perm()); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), configPath)
+}
+
+// encodeLines заново сериализует весь файл; нужен, только если правку нельзя вставить в текст
+func encodeLines(root *yaml.Node) ([]string, error) {
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(root); err != nil {
+		return nil, err
+	}
+	if err := enc.Close(); err != nil {
+		return nil, err
+	}
+	return strings.Split(buf.String(), "\n"), nil
+}
+
+// isBlockList сообщает, что список непустой, записан блоком и каждый элемент начинается с "- "
+func isBlockList(keys *yaml.Node, lines []string) bool {
+	if keys == nil || keys.Kind != yaml.SequenceNode || keys.Style&yaml.FlowStyle != 0 || len(keys.Content) == 0 {
+		return false
+	}
+	for _, item := range keys.Content {
+		if item.Line < 1 || item.Line > len(lines) || !strings.HasPrefix(strings.TrimSpace(lines[item.Line-1]), "- ") {
+			return false
+		}
+	}
+	return true
+}
+
+// listEntryLines записывает элемент списка с отступом indent
+func listEntryLines(node *yaml.Node, indent string) ([]string, error) {
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(node); err != nil {
+		return nil, err
+	}
+	if err := enc.Close(); err != nil {
+		return nil, err
+	}
+
+	entry := strings.Split(strings.TrimRight(buf.String(), "\n"), "\n")
+	for i, line := range entry {
+		prefix := indent + "  "
+		if i == 0 {
+			prefix = indent + "- "
+		}
+		entry[i] = prefix + line
+	}
+	return entry, nil
+}
+
+// lastLine возвращает номер последней строки, занятой узлом и его потомками
+func lastLine(node *yaml.Node) int {
+	last := node.Line
+	for _, child := range node.Content {
+		last = max(last, lastLine(child))
+	}
+	return last
+}
+
+func leadingSpace(line string) string {
+	return line[:len(line)-len(strings.TrimLeft(line, " "))]
+}
+
+// mappingPath находит значение по цепочке ключей; при create недостающие ключи добавляются
+func mappingPath(node *yaml.Node, create bool, keys ...string) *yaml.Node {
+	for _, key := range keys {
+		if node.Kind != yaml.MappingNode {
+			if !create || !isNullNode(node) {
+				return nil
+			}
+			node.Kind, node.Tag, node.Value = yaml.MappingNode, "!!map", ""
+		}
+
+		var next *yaml.Node
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			if node.Content[i].Value == key {
+				next = node.Content[i+1]
+				break
+			}
+		}
+		if next == nil {
+			if !create {
+				return nil
+			}
+			next = &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!null"}
+			node.Content = append(node.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: key}, next)
+		}
+		node = next
+	}
+	return node
+}
+
+func isNullNode(node *yaml.Node) bool {
+	return node != nil && node.Kind == yaml.ScalarNode && node.Tag == "!!null"
+}
+
+func apiKeyIndex(keys *yaml.Node, name string) int {
+	for i, item := range keys.Content {
+		if field := mappingPath(item, false, "name"); field != nil && field.Value == name {
+			return i
+		}
+	}
+	return -1
+}
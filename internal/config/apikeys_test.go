@@ -0,0 +1,124 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestGenerateAPIKey(t *testing.T) {
+	key, err := GenerateAPIKey("crm", "viewer", nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(key.Key) != 48 || len(key.Extra) != 32 {
+		t.Errorf("unexpected key lengths: %d, %d", len(key.Key), len(key.Extra))
+	}
+	other, _ := GenerateAPIKey("crm", "", nil)
+	if other.Key == key.Key {
+		t.Error("expected distinct keys")
+	}
+
+	if _, err := GenerateAPIKey("", "", nil); err == nil {
+		t.Error("expected error for empty name")
+	}
+	if _, err := GenerateAPIKey("crm", "owner", nil); err == nil {
+		t.Error("expected error for unknown role")
+	}
+}
+
+func TestAddRemoveAPIKey(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "config.yaml")
+	content := `telegram:
+  bot_token: ${BOT_TOKEN} # токен из .env
+api:
+  enabled: true
+  auth:
+    enabled: true
+    api_keys:
+      - key: ${CRM_API_KEY}
+        extra: ${CRM_API_EXTRA}
+        name: "crm"
+        permissions: ["read:items"]
+`
+	if err := os.WriteFile(configPath, []byte(content), 0o600); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+
+	readKeys := func() []APIClientKey {
+		data, err := os.ReadFile(configPath)
+		if err != nil {
+			t.Fatalf("failed to read config: %v", err)
+		}
+		var cfg Config
+		if err := yaml.Unmarshal(data, &cfg); err != nil {
+			t.Fatalf("failed to parse config: %v", err)
+		}
+		return cfg.API.Auth.APIKeys
+	}
+
+	added := APIClientKey{Key: "k1", Extra: "e1", Name: "reports", Role: "viewer", Permissions: []string{"read:stats"}}
+	if err := AddAPIKey(configPath, added); err != nil {
+		t.Fatalf("failed to add key: %v", err)
+	}
+	keys := readKeys()
+	if len(keys) != 2 || keys[1].Name != "reports" || keys[1].Role != "viewer" || keys[1].Key != "k1" {
+		t.Fatalf("unexpected keys after add: %+v", keys)
+	}
+	if keys[0].Key != "${CRM_API_KEY}" {
+		t.Errorf("existing key was expanded: %q", keys[0].Key)
+	}
+
+	data, _ := os.ReadFile(configPath)
+	if !strings.HasPrefix(string(data), content) {
+		t.Errorf("existing lines changed:\n%s", data)
+	}
+	if !strings.Contains(string(data), "\n      - key: k1\n") || !strings.Contains(string(data), "permissions: ['read:stats']") {
+		t.Errorf("unexpected new entry:\n%s", data)
+	}
+	if info, _ := os.Stat(configPath); info.Mode().Perm() != 0o600 {
+		t.Errorf("file mode changed: %v", info.Mode().Perm())
+	}
+
+	if err := AddAPIKey(configPath, added); !errors.Is(err, ErrAPIKeyExists) {
+		t.Errorf("expected ErrAPIKeyExists, got %v", err)
+	}
+
+	if err := RemoveAPIKey(configPath, "crm"); err != nil {
+		t.Fatalf("failed to remove key: %v", err)
+	}
+	keys = readKeys()
+	if len(keys) != 1 || keys[0].Name != "reports" {
+		t.Fatalf("unexpected keys after remove: %+v", keys)
+	}
+	if err := RemoveAPIKey(configPath, "crm"); !errors.Is(err, ErrAPIKeyNotFound) {
+		t.Errorf("expected ErrAPIKeyNotFound, got %v", err)
+	}
+}
+
+func TestAddAPIKeyCreatesSection(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(configPath, []byte("api:\n  enabled: true\n  auth:\n    api_keys:\n"), 0o644); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+
+	if err := AddAPIKey(configPath, APIClientKey{Key: "k", Extra: "e", Name: "first"}); err != nil {
+		t.Fatalf("failed to add key: %v", err)
+	}
+
+	data, _ := os.ReadFile(configPath)
+	var cfg Config
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		t.Fatalf("failed to parse config: %v", err)
+	}
+	if !cfg.API.Enabled || len(cfg.API.Auth.APIKeys) != 1 || cfg.API.Auth.APIKeys[0].Name != "first" {
+		t.Fatalf("unexpected config: %+v", cfg.API)
+	}
+	if strings.Contains(string(data), "permissions") {
+		t.Errorf("empty permissions should be omitted:\n%s", data)
+	}
+}
@@ -11,39 +11,90 @@ import (
 	"gopkg.in/yaml.v3"
 )
 
+// minCalendarSecretLen - минимальная длина секрета подписи ссылок на календарь
+const minCalendarSecretLen = 16
+
 type Config struct {
-	App              AppConfig        `yaml:"app"`
-	Telegram         TelegramConfig   `yaml:"telegram"`
-	Database         DatabaseConfig   `yaml:"database"`
-	Redis            RedisConfig      `yaml:"redis"`
-	Backup           BackupConfig     `yaml:"backup"`
-	Monitoring       MonitoringConfig `yaml:"monitoring"`
-	Logging          LoggingConfig    `yaml:"logging"`
-	API              APIConfig        `yaml:"api"`
-	Managers         []int64          `yaml:"managers"`
-	ManagersContacts []string         `yaml:"managers_contacts"`
-	Blacklist        []int64          `yaml:"blacklist"`
-	Items            []models.Item    `yaml:"items"`
-	Exports          ExportConfig     `yaml:"exports"`
-	Google           GoogleConfig     `yaml:"google"`
-	Bot              BotConfig        `yaml:"bot"`
+	App               AppConfig               `yaml:"app"`
+	Telegram          TelegramConfig          `yaml:"telegram"`
+	Database          DatabaseConfig          `yaml:"database"`
+	Redis             RedisConfig             `yaml:"redis"`
+	Backup            BackupConfig            `yaml:"backup"`
+	Monitoring        MonitoringConfig        `yaml:"monitoring"`
+	Logging           LoggingConfig           `yaml:"logging"`
+	API               APIConfig               `yaml:"api"`
+	Managers          []int64                 `yaml:"managers"`
+	ManagersContacts  []string                `yaml:"managers_contacts"`
+	Blacklist         []int64                 `yaml:"blacklist"`
+	Items             []models.Item           `yaml:"items"`
+	Exports           ExportConfig            `yaml:"exports"`
+	Google            GoogleConfig            `yaml:"google"`
+	Bot               BotConfig               `yaml:"bot"`
+	ExternalCalendars ExternalCalendarsConfig `yaml:"external_calendars"`
 }
 
 type BotConfig struct {
-	ReminderTime      string `yaml:"reminder_time"`
-	PaginationSize    int    `yaml:"pagination_size"`
-	MaxBookingDays    int    `yaml:"max_booking_days"`
-	MinBookingAdvance int    `yaml:"min_booking_advance"`
-	RateLimitMessages int    `yaml:"rate_limit_messages"`
-	RateLimitWindow   int    `yaml:"rate_limit_window"`
+	ReminderTime      string           `yaml:"reminder_time"`
+	PaginationSize    int              `yaml:"pagination_size"`
+	MaxBookingDays    int              `yaml:"max_booking_days"`
+	MinBookingAdvance int              `yaml:"min_booking_advance"`
+	RateLimitMessages int              `yaml:"rate_limit_messages"`
+	RateLimitWindow   int              `yaml:"rate_limit_window"`
+	DefaultLanguage   string           `yaml:"default_language"`
+	LocalesDir        string           `yaml:"locales_dir"`
+	Digest            DigestConfig     `yaml:"digest"`
+	Escalation        EscalationConfig `yaml:"escalation"`
+	Reminders         []ReminderConfig `yaml:"reminders"`
+	Handover          HandoverConfig   `yaml:"handover"`
+	Dispatcher        DispatcherConfig `yaml:"dispatcher"`
+}
+
+// ReminderConfig - один этап напоминаний о брони
+type ReminderConfig struct {
+	Kind       string `yaml:"kind"`        // before - перед началом брони, return - о возврате аппарата
+	DaysBefore int    `yaml:"days_before"` // за сколько дней до начала (before) или до последнего дня брони (return)
+	Time       string `yaml:"time"`        // ЧЧ:ММ, по умолчанию reminder_time
+	Template   string `yaml:"template"`    // ключ каталога или текст с подстановками {item}, {date}, {when}...
+}
+
+// DigestConfig - утренняя сводка для менеджеров
+type DigestConfig struct {
+	Enabled           bool   `yaml:"enabled"`
+	Time              string `yaml:"time"`                // ЧЧ:ММ
+	PendingAfterHours int    `yaml:"pending_after_hours"` // заявки, ждущие ответа дольше, попадают в сводку
+}
+
+// EscalationConfig - что делать с заявками, на которые не ответили дольше SLA
+type EscalationConfig struct {
+	Enabled      bool   `yaml:"enabled"`
+	SLAHours     int    `yaml:"sla_hours"`
+	Mode         string `yaml:"mode"`          // reping или assign
+	MaxRepeats   int    `yaml:"max_repeats"`   // сколько раз эскалировать одну заявку
+	CheckMinutes int    `yaml:"check_minutes"` // как часто искать просроченные заявки
+}
+
+// DispatcherConfig - параллельная обработка обновлений Telegram
+type DispatcherConfig struct {
+	Workers      int `yaml:"workers"`       // сколько обновлений обрабатывается одновременно
+	QueueSize    int `yaml:"queue_size"`    // сколько обновлений принимается, прежде чем чтение приостановится
+	DrainTimeout int `yaml:"drain_timeout"` // секунды на завершение принятых обновлений при остановке
+}
+
+// HandoverConfig - контроль возврата выданных аппаратов
+type HandoverConfig struct {
+	ReturnTime          string `yaml:"return_time"`           // ЧЧ:ММ, до которого аппарат нужно вернуть в день возврата
+	OverdueCheckMinutes int    `yaml:"overdue_check_minutes"` // как часто искать просроченные возвраты
+	OverdueRepeatHours  int    `yaml:"overdue_repeat_hours"`  // как часто повторять оповещение по одному возврату
 }
 
 type APIConfig struct {
-	Enabled   bool               `yaml:"enabled"`
-	HTTP      APIHTTPConfig      `yaml:"http"`
-	GRPC      APIGRPCConfig      `yaml:"grpc"`
-	Auth      APIAuthConfig      `yaml:"auth"`
-	RateLimit APIRateLimitConfig `yaml:"rate_limit"`
+	Enabled     bool               `yaml:"enabled"`
+	BotUsername string             `yaml:"bot_username"` // имя бота без @ для ссылок в QR-наклейках
+	HTTP        APIHTTPConfig      `yaml:"http"`
+	GRPC        APIGRPCConfig      `yaml:"grpc"`
+	Auth        APIAuthConfig      `yaml:"auth"`
+	RateLimit   APIRateLimitConfig `yaml:"rate_limit"`
+	Calendar    APICalendarConfig  `yaml:"calendar"`
 }
 
 type APIHTTPConfig struct {
@@ -76,7 +127,29 @@ type APIClientKey struct {
 	Key         string   `yaml:"key"`
 	Extra       string   `yaml:"extra"`
 	Name        string   `yaml:"name"`
-	Permissions []string `yaml:"permissions"`
+	Role        string   `yaml:"role,omitempty"`
+	Permissions []string `yaml:"permissions,omitempty"`
+}
+
+// APICalendarConfig - подписки на календарь (.ics): ссылки подписываются секретом и открываются без API-ключа
+type APICalendarConfig struct {
+	Secret    string `yaml:"secret"`     // ключ подписи токенов; без него фиды отключены
+	PublicURL string `yaml:"public_url"` // внешний адрес HTTP API для ссылок в боте, например https://bronivik.example.com
+}
+
+// ExternalCalendarsConfig - внешние календари (.ics), события которых занимают аппараты
+type ExternalCalendarsConfig struct {
+	PollMinutes int                      `yaml:"poll_minutes"` // как часто перечитывать календари
+	Sources     []ExternalCalendarSource `yaml:"sources"`
+}
+
+// ExternalCalendarSource - один внешний календарь; задается ровно одно из url и file
+type ExternalCalendarSource struct {
+	Name   string `yaml:"name"`    // уникальное имя, по нему заменяются брони при повторном импорте
+	ItemID int64  `yaml:"item_id"` // аппарат, который занимают события календаря
+	URL    string `yaml:"url"`
+	File   string `yaml:"file"`
+	Units  int64  `yaml:"units"` // сколько аппаратов занимает одно событие, по умолчанию 1
 }
 
 type APIRateLimitConfig struct {
@@ -188,9 +261,109 @@ func (c *Config) Validate() error {
 		return errors.New("database path is required")
 	}
 
+	for _, k := range c.API.Auth.APIKeys {
+		if k.Role != "" && !models.IsValidRole(k.Role) {
+			return fmt.Errorf("api key '%s' has unknown role '%s'", k.Name, k.Role)
+		}
+	}
+
+	if c.Bot.Digest.Enabled {
+		if _, _, err := ParseClock(c.Bot.Digest.Time); err != nil {
+			return fmt.Errorf("bot.digest.time: %w", err)
+		}
+	}
+
+	switch c.Bot.Escalation.Mode {
+	case "", models.EscalationModeReping, models.EscalationModeAssign:
+	default:
+		return fmt.Errorf("bot.escalation.mode must be %s or %s", models.EscalationModeReping, models.EscalationModeAssign)
+	}
+
+	if err := validateReminders(c.Bot.Reminders); err != nil {
+		return err
+	}
+
+	if c.Bot.Handover.ReturnTime != "" {
+		if _, _, err := ParseClock(c.Bot.Handover.ReturnTime); err != nil {
+			return fmt.Errorf("bot.handover.return_time: %w", err)
+		}
+	}
+
+	if c.API.Calendar.Secret != "" && len(c.API.Calendar.Secret) < minCalendarSecretLen {
+		return fmt.Errorf("api.calendar.secret must be at least %d characters", minCalendarSecretLen)
+	}
+
+	if c.Bot.Dispatcher.Workers < 0 || c.Bot.Dispatcher.QueueSize < 0 || c.Bot.Dispatcher.DrainTimeout < 0 {
+		return errors.New("bot.dispatcher values must not be negative")
+	}
+
+	if err := validateExternalCalendars(c.ExternalCalendars); err != nil {
+		return err
+	}
+
 	return ValidateItems(c.Items)
 }
 
+func validateExternalCalendars(cfg ExternalCalendarsConfig) error {
+	if cfg.PollMinutes < 0 {
+		return errors.New("external_calendars.poll_minutes must not be negative")
+	}
+	seen := make(map[string]bool, len(cfg.Sources))
+	for i, s := range cfg.Sources {
+		if s.Name == "" {
+			return fmt.Errorf("external_calendars.sources[%d].name is required", i)
+		}
+		if seen[s.Name] {
+			return fmt.Errorf("external_calendars.sources[%d]: duplicate name %q", i, s.Name)
+		}
+		seen[s.Name] = true
+		if s.ItemID <= 0 {
+			return fmt.Errorf("external_calendars.sources[%d].item_id is required", i)
+		}
+		if (s.URL == "") == (s.File == "") {
+			return fmt.Errorf("external_calendars.sources[%d] must set exactly one of url and file", i)
+		}
+		if s.Units < 0 {
+			return fmt.Errorf("external_calendars.sources[%d].units must not be negative", i)
+		}
+	}
+	return nil
+}
+
+// ParseClock разбирает время суток в формате ЧЧ:ММ
+func ParseClock(s string) (hour, minute int, err error) {
+	if _, err := fmt.Sscanf(s, "%d:%d", &hour, &minute); err != nil {
+		return 0, 0, fmt.Errorf("invalid time %q, expected HH:MM", s)
+	}
+	if hour < 0 || hour > 23 || minute < 0 || minute > 59 {
+		return 0, 0, fmt.Errorf("invalid time %q, expected HH:MM", s)
+	}
+	return hour, minute, nil
+}
+
+func validateReminders(reminders []ReminderConfig) error {
+	seen := make(map[string]bool, len(reminders))
+	for i, r := range reminders {
+		switch r.Kind {
+		case models.ReminderKindBefore, models.ReminderKindReturn:
+		default:
+			return fmt.Errorf("bot.reminders[%d].kind must be %s or %s", i, models.ReminderKindBefore, models.ReminderKindReturn)
+		}
+		if r.DaysBefore < 0 {
+			return fmt.Errorf("bot.reminders[%d].days_before must not be negative", i)
+		}
+		if _, _, err := ParseClock(r.Time); err != nil {
+			return fmt.Errorf("bot.reminders[%d].time: %w", i, err)
+		}
+		key := models.ReminderKey(r.Kind, r.DaysBefore)
+		if seen[key] {
+			return fmt.Errorf("bot.reminders[%d]: duplicate %s reminder %d days before", i, r.Kind, r.DaysBefore)
+		}
+		seen[key] = true
+	}
+	return nil
+}
+
 func ValidateItems(items []models.Item) error {
 	// Check for duplicate item IDs
 	itemIDs := make(map[int64]bool)
@@ -201,11 +374,23 @@ func ValidateItems(items []models.Item) error {
 		if itemIDs[item.ID] {
 			return fmt.Errorf("duplicate item ID found: %d", item.ID)
 		}
+		if item.PricePerDay < 0 || item.WeekendPrice < 0 || item.Deposit < 0 || item.LongRentalDays < 0 {
+			return fmt.Errorf("item '%s' has negative price_per_day, weekend_price, deposit or long_rental_days", item.Name)
+		}
+		if item.LongRentalDiscount < 0 || item.LongRentalDiscount > 100 {
+			return fmt.Errorf("item '%s' has long_rental_discount outside 0-100", item.Name)
+		}
 		itemIDs[item.ID] = true
 	}
 	return nil
 }
 
+// Заголовки ключа API по умолчанию
+const (
+	DefaultHeaderAPIKey = "x-api-key"
+	DefaultHeaderExtra  = "x-api-extra"
+)
+
 func (c *Config) applyDefaults() {
 	if c.API.GRPC.Port == 0 {
 		c.API.GRPC.Port = 8081
@@ -224,10 +409,10 @@ func (c *Config) applyDefaults() {
 		c.API.HTTP.Enabled = true
 	}
 	if c.API.Auth.HeaderAPIKey == "" {
-		c.API.Auth.HeaderAPIKey = "x-api-key"
+		c.API.Auth.HeaderAPIKey = DefaultHeaderAPIKey
 	}
 	if c.API.Auth.HeaderExtra == "" {
-		c.API.Auth.HeaderExtra = "x-api-extra"
+		c.API.Auth.HeaderExtra = DefaultHeaderExtra
 	}
 
 	// Bot defaults
@@ -246,4 +431,60 @@ func (c *Config) applyDefaults() {
 	if c.Bot.RateLimitWindow == 0 {
 		c.Bot.RateLimitWindow = models.RateLimitWindow
 	}
+	if c.Bot.Digest.Time == "" {
+		c.Bot.Digest.Time = "08:00"
+	}
+	if c.Bot.Digest.PendingAfterHours == 0 {
+		c.Bot.Digest.PendingAfterHours = 24
+	}
+	if c.Bot.Escalation.SLAHours == 0 {
+		c.Bot.Escalation.SLAHours = 4
+	}
+	if c.Bot.Escalation.Mode == "" {
+		c.Bot.Escalation.Mode = models.EscalationModeReping
+	}
+	if c.Bot.Escalation.MaxRepeats == 0 {
+		c.Bot.Escalation.MaxRepeats = 3
+	}
+	if c.Bot.Escalation.CheckMinutes == 0 {
+		c.Bot.Escalation.CheckMinutes = 15
+	}
+	// Без расписания работает прежнее напоминание накануне брони
+	if len(c.Bot.Reminders) == 0 {
+		c.Bot.Reminders = []ReminderConfig{{Kind: models.ReminderKindBefore, DaysBefore: 1}}
+	}
+	for i := range c.Bot.Reminders {
+		if c.Bot.Reminders[i].Kind == "" {
+			c.Bot.Reminders[i].Kind = models.ReminderKindBefore
+		}
+		if c.Bot.Reminders[i].Time == "" {
+			c.Bot.Reminders[i].Time = c.Bot.ReminderTime
+		}
+	}
+	if c.Bot.Handover.ReturnTime == "" {
+		c.Bot.Handover.ReturnTime = "12:00"
+	}
+	if c.Bot.Handover.OverdueCheckMinutes == 0 {
+		c.Bot.Handover.OverdueCheckMinutes = 30
+	}
+	if c.Bot.Handover.OverdueRepeatHours == 0 {
+		c.Bot.Handover.OverdueRepeatHours = 24
+	}
+	if c.Bot.Dispatcher.Workers == 0 {
+		c.Bot.Dispatcher.Workers = models.DispatcherWorkers
+	}
+	if c.Bot.Dispatcher.QueueSize == 0 {
+		c.Bot.Dispatcher.QueueSize = models.DispatcherQueueSize
+	}
+	if c.Bot.Dispatcher.DrainTimeout == 0 {
+		c.Bot.Dispatcher.DrainTimeout = models.DispatcherDrainTimeout
+	}
+	if c.ExternalCalendars.PollMinutes == 0 {
+		c.ExternalCalendars.PollMinutes = 15
+	}
+	for i := range c.ExternalCalendars.Sources {
+		if c.ExternalCalendars.Sources[i].Units == 0 {
+			c.ExternalCalendars.Sources[i].Units = 1
+		}
+	}
 }
@@ -82,6 +82,119 @@ func TestValidateConfig(t *testing.T) {
 			},
 			wantErr: true,
 		},
+		{
+			name: "unknown api key role",
+			cfg: Config{
+				Telegram: TelegramConfig{BotToken: "token"},
+				Database: DatabaseConfig{Path: "path"},
+				API: APIConfig{Auth: APIAuthConfig{
+					APIKeys: []APIClientKey{{Key: "k", Name: "crm", Role: "superuser"}},
+				}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "short calendar secret",
+			cfg: Config{
+				Telegram: TelegramConfig{BotToken: "token"},
+				Database: DatabaseConfig{Path: "path"},
+				API:      APIConfig{Calendar: APICalendarConfig{Secret: "short"}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "external calendar with url and file",
+			cfg: Config{
+				Telegram: TelegramConfig{BotToken: "token"},
+				Database: DatabaseConfig{Path: "path"},
+				ExternalCalendars: ExternalCalendarsConfig{Sources: []ExternalCalendarSource{
+					{Name: "partner", ItemID: 1, URL: "https://partner.example/cal.ics", File: "cal.ics"},
+				}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "duplicate external calendar name",
+			cfg: Config{
+				Telegram: TelegramConfig{BotToken: "token"},
+				Database: DatabaseConfig{Path: "path"},
+				ExternalCalendars: ExternalCalendarsConfig{Sources: []ExternalCalendarSource{
+					{Name: "partner", ItemID: 1, File: "a.ics"},
+					{Name: "partner", ItemID: 2, File: "b.ics"},
+				}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "invalid digest time",
+			cfg: Config{
+				Telegram: TelegramConfig{BotToken: "token"},
+				Database: DatabaseConfig{Path: "path"},
+				Bot:      BotConfig{Digest: DigestConfig{Enabled: true, Time: "25:00"}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "unknown escalation mode",
+			cfg: Config{
+				Telegram: TelegramConfig{BotToken: "token"},
+				Database: DatabaseConfig{Path: "path"},
+				Bot:      BotConfig{Escalation: EscalationConfig{Mode: "page"}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "invalid handover return time",
+			cfg: Config{
+				Telegram: TelegramConfig{BotToken: "token"},
+				Database: DatabaseConfig{Path: "path"},
+				Bot:      BotConfig{Handover: HandoverConfig{ReturnTime: "noon"}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "valid reminders",
+			cfg: Config{
+				Telegram: TelegramConfig{BotToken: "token"},
+				Database: DatabaseConfig{Path: "path"},
+				Bot: BotConfig{Reminders: []ReminderConfig{
+					{Kind: models.ReminderKindBefore, DaysBefore: 3, Time: "10:00"},
+					{Kind: models.ReminderKindBefore, DaysBefore: 0, Time: "08:30"},
+					{Kind: models.ReminderKindReturn, DaysBefore: 0, Time: "17:00"},
+				}},
+			},
+			wantErr: false,
+		},
+		{
+			name: "unknown reminder kind",
+			cfg: Config{
+				Telegram: TelegramConfig{BotToken: "token"},
+				Database: DatabaseConfig{Path: "path"},
+				Bot:      BotConfig{Reminders: []ReminderConfig{{Kind: "after", Time: "10:00"}}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "invalid reminder time",
+			cfg: Config{
+				Telegram: TelegramConfig{BotToken: "token"},
+				Database: DatabaseConfig{Path: "path"},
+				Bot:      BotConfig{Reminders: []ReminderConfig{{Kind: models.ReminderKindReturn, Time: "5pm"}}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "duplicate reminder stage",
+			cfg: Config{
+				Telegram: TelegramConfig{BotToken: "token"},
+				Database: DatabaseConfig{Path: "path"},
+				Bot: BotConfig{Reminders: []ReminderConfig{
+					{Kind: models.ReminderKindBefore, DaysBefore: 1, Time: "09:00"},
+					{Kind: models.ReminderKindBefore, DaysBefore: 1, Time: "18:00"},
+				}},
+			},
+			wantErr: true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -111,6 +224,23 @@ func TestApplyDefaults(t *testing.T) {
 	if cfg.Bot.RateLimitMessages != models.RateLimitMessages {
 		t.Errorf("expected default rate limit messages %d, got %d", models.RateLimitMessages, cfg.Bot.RateLimitMessages)
 	}
+	if cfg.Bot.Digest.Time != "08:00" || cfg.Bot.Escalation.Mode != models.EscalationModeReping {
+		t.Errorf("expected digest at 08:00 and reping escalation, got %s and %s", cfg.Bot.Digest.Time, cfg.Bot.Escalation.Mode)
+	}
+	if len(cfg.Bot.Reminders) != 1 || cfg.Bot.Reminders[0] != (ReminderConfig{Kind: models.ReminderKindBefore, DaysBefore: 1, Time: expectedReminder}) {
+		t.Errorf("expected a single reminder the day before at %s, got %+v", expectedReminder, cfg.Bot.Reminders)
+	}
+	if cfg.Bot.Handover != (HandoverConfig{ReturnTime: "12:00", OverdueCheckMinutes: 30, OverdueRepeatHours: 24}) {
+		t.Errorf("expected default handover settings, got %+v", cfg.Bot.Handover)
+	}
+	expectedDispatcher := DispatcherConfig{
+		Workers:      models.DispatcherWorkers,
+		QueueSize:    models.DispatcherQueueSize,
+		DrainTimeout: models.DispatcherDrainTimeout,
+	}
+	if cfg.Bot.Dispatcher != expectedDispatcher {
+		t.Errorf("expected default dispatcher settings, got %+v", cfg.Bot.Dispatcher)
+	}
 }
 
 func TestValidateItems(t *testing.T) {
@@ -142,6 +272,34 @@ func TestValidateItems(t *testing.T) {
 			},
 			wantErr: true,
 		},
+		{
+			name: "Negative price",
+			items: []models.Item{
+				{ID: 1, Name: "Item 1", PricePerDay: -100},
+			},
+			wantErr: true,
+		},
+		{
+			name: "Negative deposit",
+			items: []models.Item{
+				{ID: 1, Name: "Item 1", PricePerDay: 100, Deposit: -1},
+			},
+			wantErr: true,
+		},
+		{
+			name: "Discount over 100 percent",
+			items: []models.Item{
+				{ID: 1, Name: "Item 1", LongRentalDays: 7, LongRentalDiscount: 120},
+			},
+			wantErr: true,
+		},
+		{
+			name: "Full price rules",
+			items: []models.Item{
+				{ID: 1, Name: "Item 1", PricePerDay: 1000, WeekendPrice: 1500, Deposit: 5000, LongRentalDays: 7, LongRentalDiscount: 10},
+			},
+			wantErr: false,
+		},
 	}
 
 	for _, tt := range tests {
@@ -0,0 +1,68 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"bronivik/internal/models"
+)
+
+// CreateAuditEntry appends a record to the audit log.
+func (db *DB) CreateAuditEntry(ctx context.Context, entry *models.AuditEntry) error {
+	if entry.CreatedAt.IsZero() {
+		entry.CreatedAt = time.Now()
+	}
+	if entry.Source == "" {
+		entry.Source = models.AuditSourceSystem
+	}
+
+	query := `INSERT INTO audit_log (entity_type, entity_id, action, actor_id, source, before_value, after_value, created_at)
+              VALUES (?, ?, ?, ?, ?, ?, ?, ?)`
+	result, err := db.ExecContext(ctx, query,
+		entry.EntityType,
+		entry.EntityID,
+		entry.Action,
+		entry.ActorID,
+		entry.Source,
+		entry.Before,
+		entry.After,
+		entry.CreatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create audit entry: %w", err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return fmt.Errorf("failed to get last insert id: %w", err)
+	}
+	entry.ID = id
+
+	return nil
+}
+
+// GetAuditEntries returns the history of an entity ordered from oldest to newest.
+func (db *DB) GetAuditEntries(ctx context.Context, entityType string, entityID int64) ([]*models.AuditEntry, error) {
+	query := `SELECT id, entity_type, entity_id, action, actor_id, source, before_value, after_value, created_at
+              FROM audit_log
+              WHERE entity_type = ? AND entity_id = ?
+              ORDER BY created_at ASC, id ASC`
+	rows, err := db.QueryContext(ctx, query, entityType, entityID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get audit entries: %w", err)
+	}
+	defer rows.Close()
+
+	var entries []*models.AuditEntry
+	for rows.Next() {
+		var e models.AuditEntry
+		if err := rows.Scan(
+			&e.ID, &e.EntityType, &e.EntityID, &e.Action, &e.ActorID, &e.Source, &e.Before, &e.After, &e.CreatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan audit entry: %w", err)
+		}
+		entries = append(entries, &e)
+	}
+	return entries, rows.Err()
+}
@@ -0,0 +1,67 @@
+package database
+
+import (
+	"context"
+	"testing"
+
+	"bronivik/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAuditLog(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	ctx := context.Background()
+
+	first := &models.AuditEntry{
+		EntityType: models.AuditEntityBooking,
+		EntityID:   1,
+		Action:     models.AuditActionCreate,
+		ActorID:    10,
+		Source:     models.AuditSourceBot,
+		After:      `{"status":"pending"}`,
+	}
+	require.NoError(t, db.CreateAuditEntry(ctx, first))
+	assert.NotZero(t, first.ID)
+
+	second := &models.AuditEntry{
+		EntityType: models.AuditEntityBooking,
+		EntityID:   1,
+		Action:     models.AuditActionStatusChange,
+		ActorID:    20,
+		Before:     `{"status":"pending"}`,
+		After:      `{"status":"confirmed"}`,
+	}
+	require.NoError(t, db.CreateAuditEntry(ctx, second))
+	assert.Equal(t, models.AuditSourceSystem, second.Source)
+
+	require.NoError(t, db.CreateAuditEntry(ctx, &models.AuditEntry{
+		EntityType: models.AuditEntityItem,
+		EntityID:   1,
+		Action:     models.AuditActionUpdate,
+	}))
+
+	entries, err := db.GetAuditEntries(ctx, models.AuditEntityBooking, 1)
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	assert.Equal(t, models.AuditActionCreate, entries[0].Action)
+	assert.Equal(t, `{"status":"confirmed"}`, entries[1].After)
+
+	t.Run("AppendOnly", func(t *testing.T) {
+		_, err := db.ExecContext(ctx, `UPDATE audit_log SET action = 'tampered' WHERE id = ?`, first.ID)
+		assert.Error(t, err)
+
+		_, err = db.ExecContext(ctx, `UPDATE audit_log SET after_value = '{"status":"canceled"}' WHERE id = ?`, first.ID)
+		assert.Error(t, err)
+
+		_, err = db.ExecContext(ctx, `DELETE FROM audit_log WHERE id = ?`, first.ID)
+		assert.Error(t, err)
+
+		entries, err := db.GetAuditEntries(ctx, models.AuditEntityBooking, 1)
+		require.NoError(t, err)
+		assert.Len(t, entries, 2)
+	})
+}
@@ -7,6 +7,8 @@ import (
 	"io"
 	"os"
 	"path/filepath"
+	"sort"
+	"strings"
 	"time"
 
 	"bronivik/internal/config"
@@ -68,6 +70,10 @@ func (s *BackupService) Start(ctx context.Context) {
 }
 
 func (s *BackupService) PerformBackup() error {
+	return s.backupAs("backup")
+}
+
+func (s *BackupService) backupAs(prefix string) error {
 	if _, err := os.Stat(s.config.StoragePath); os.IsNotExist(err) {
 		if err := os.MkdirAll(s.config.StoragePath, 0o755); err != nil {
 			return fmt.Errorf("failed to create backup directory: %w", err)
@@ -75,7 +81,7 @@ func (s *BackupService) PerformBackup() error {
 	}
 
 	timestamp := time.Now().Format("20060102_150405")
-	backupFileName := fmt.Sprintf("backup_%s.db", timestamp)
+	backupFileName := fmt.Sprintf("%s_%s.db", prefix, timestamp)
 	backupPath := filepath.Join(s.config.StoragePath, backupFileName)
 
 	s.logger.Info().Str("path", backupPath).Msg("Performing database backup using VACUUM INTO")
@@ -149,3 +155,101 @@ func (s *BackupService) CleanupOldBackups() {
 		}
 	}
 }
+
+// ListBackups returns backup files in the storage directory, newest first.
+func (s *BackupService) ListBackups() ([]os.FileInfo, error) {
+	entries, err := os.ReadDir(s.config.StoragePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read backup directory: %w", err)
+	}
+
+	var backups []os.FileInfo
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".db") {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		backups = append(backups, info)
+	}
+	sort.Slice(backups, func(i, j int) bool {
+		return backups[i].ModTime().After(backups[j].ModTime())
+	})
+	return backups, nil
+}
+
+// RestoreBackup replaces the database with a backup file. The backup is checked with
+// PRAGMA integrity_check and the current database is saved as pre_restore_*.db first, so a restore
+// can be undone.
+// Nothing may hold the database open while it is restored.
+func (s *BackupService) RestoreBackup(backupPath string) error {
+	if err := checkBackupIntegrity(backupPath); err != nil {
+		return err
+	}
+
+	if _, err := os.Stat(s.dbPath); err == nil {
+		if err := s.backupAs("pre_restore"); err != nil {
+			return fmt.Errorf("failed to back up current database: %w", err)
+		}
+	}
+
+	source, err := os.Open(backupPath)
+	if err != nil {
+		return fmt.Errorf("failed to open backup: %w", err)
+	}
+	defer source.Close()
+
+	// Copy next to the database and rename, so a failed copy never leaves a truncated database behind.
+	tmpPath := s.dbPath + ".restore"
+	destination, err := os.Create(tmpPath)
+	if err != nil {
+		return fmt.Errorf("failed to create restore file: %w", err)
+	}
+	if _, err := io.Copy(destination, source); err != nil {
+		destination.Close()
+		os.Remove(tmpPath)
+		return fmt.Errorf("failed to copy backup: %w", err)
+	}
+	if err := destination.Close(); err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("failed to copy backup: %w", err)
+	}
+
+	// WAL files of the old database must not be replayed on top of the restored one.
+	for _, suffix := range []string{"-wal", "-shm"} {
+		if err := os.Remove(s.dbPath + suffix); err != nil && !os.IsNotExist(err) {
+			os.Remove(tmpPath)
+			return fmt.Errorf("failed to remove %s file: %w", suffix, err)
+		}
+	}
+	if err := os.Rename(tmpPath, s.dbPath); err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("failed to replace database: %w", err)
+	}
+
+	s.logger.Info().Str("backup", backupPath).Str("path", s.dbPath).Msg("Database restored from backup")
+	return nil
+}
+
+func checkBackupIntegrity(path string) error {
+	if _, err := os.Stat(path); err != nil {
+		return fmt.Errorf("failed to open backup: %w", err)
+	}
+
+	db, err := sql.Open("sqlite3", "file:"+path+"?mode=ro")
+	if err != nil {
+		return fmt.Errorf("failed to open backup: %w", err)
+	}
+	defer db.Close()
+
+	var result string
+	if err := db.QueryRow("PRAGMA integrity_check").Scan(&result); err != nil {
+		return fmt.Errorf("backup is not a valid database: %w", err)
+	}
+	if result != "ok" {
+		return fmt.Errorf("backup failed integrity check: %s", result)
+	}
+	return nil
+}
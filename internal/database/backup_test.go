@@ -5,6 +5,7 @@ import (
 	"database/sql"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
@@ -77,3 +78,62 @@ func TestBackupService_Disabled(_ *testing.T) {
 	s.Start(ctx)
 	// Should just return
 }
+
+func TestBackupService_Restore(t *testing.T) {
+	tempDir := t.TempDir()
+	dbPath := filepath.Join(tempDir, "source.db")
+	storagePath := filepath.Join(tempDir, "backups")
+
+	exec := func(query string) {
+		db, err := sql.Open("sqlite3", dbPath)
+		require.NoError(t, err)
+		defer db.Close()
+		_, err = db.Exec(query)
+		require.NoError(t, err)
+	}
+	count := func() int {
+		db, err := sql.Open("sqlite3", dbPath)
+		require.NoError(t, err)
+		defer db.Close()
+		var n int
+		require.NoError(t, db.QueryRow("SELECT COUNT(*) FROM test").Scan(&n))
+		return n
+	}
+
+	exec("CREATE TABLE test (id INTEGER PRIMARY KEY)")
+	exec("INSERT INTO test (id) VALUES (1)")
+
+	logger := zerolog.Nop()
+	s := NewBackupService(dbPath, config.BackupConfig{StoragePath: storagePath}, &logger)
+	require.NoError(t, s.PerformBackup())
+
+	backups, err := s.ListBackups()
+	require.NoError(t, err)
+	require.Len(t, backups, 1)
+	backupPath := filepath.Join(storagePath, backups[0].Name())
+
+	exec("INSERT INTO test (id) VALUES (2)")
+	require.Equal(t, 2, count())
+
+	t.Run("RejectsInvalidFile", func(t *testing.T) {
+		junk := filepath.Join(tempDir, "junk.db")
+		require.NoError(t, os.WriteFile(junk, []byte("not a database at all, just some text"), 0o644))
+		assert.Error(t, s.RestoreBackup(junk))
+		assert.Error(t, s.RestoreBackup(filepath.Join(tempDir, "missing.db")))
+		assert.Equal(t, 2, count())
+	})
+
+	t.Run("Restores", func(t *testing.T) {
+		require.NoError(t, s.RestoreBackup(backupPath))
+		assert.Equal(t, 1, count())
+
+		backups, err := s.ListBackups()
+		require.NoError(t, err)
+		require.Len(t, backups, 2)
+		var names []string
+		for _, b := range backups {
+			names = append(names, b.Name())
+		}
+		assert.Contains(t, strings.Join(names, " "), "pre_restore_")
+	})
+}
@@ -3,6 +3,7 @@ package database
 import (
 	"context"
 	"fmt"
+	"strings"
 	"time"
 
 	"bronivik/internal/models"
@@ -14,14 +15,12 @@ func (db *DB) CheckAvailability(ctx context.Context, itemID int64, date time.Tim
 		return false, fmt.Errorf("failed to check availability: %w", err)
 	}
 
-	db.mu.RLock()
-	item, ok := db.itemsCache[itemID]
-	db.mu.RUnlock()
-	if !ok {
-		return false, fmt.Errorf("item not found in cache: %d", itemID)
+	capacity, err := db.effectiveQuantity(ctx, db, itemID, date)
+	if err != nil {
+		return false, fmt.Errorf("failed to check availability: %w", err)
 	}
 
-	return bookedCount < int(item.TotalQuantity), nil
+	return int64(bookedCount) < capacity, nil
 }
 
 func (db *DB) GetBookedCount(ctx context.Context, itemID int64, date time.Time) (int, error) {
@@ -80,23 +79,8 @@ func (db *DB) CreateBookingWithLock(ctx context.Context, booking *models.Booking
 	}()
 
 	// 1. Check availability inside transaction
-	var bookedCount int
-	queryCount := `SELECT COUNT(*) FROM bookings WHERE item_id = ? AND date = ? AND status NOT IN (?, ?)`
-	err = tx.QueryRowContext(ctx, queryCount, booking.ItemID,
-		booking.Date.Format("2006-01-02"), models.StatusCanceled, "rejected").Scan(&bookedCount)
-	if err != nil {
-		return fmt.Errorf("failed to check availability in tx: %w", err)
-	}
-
-	db.mu.RLock()
-	item, ok := db.itemsCache[booking.ItemID]
-	db.mu.RUnlock()
-	if !ok {
-		return fmt.Errorf("item not found in cache: %d", booking.ItemID)
-	}
-
-	if bookedCount >= int(item.TotalQuantity) {
-		return ErrNotAvailable
+	if err := db.checkCapacity(ctx, tx, booking.ItemID, booking.Date); err != nil {
+		return err
 	}
 
 	// 2. Create booking
@@ -135,23 +119,55 @@ func (db *DB) CreateBookingWithLock(ctx context.Context, booking *models.Booking
 	return tx.Commit()
 }
 
+// checkCapacity returns ErrNotAvailable if the item has no free unit on the date:
+// bookings that are not canceled take all units in service.
+func (db *DB) checkCapacity(ctx context.Context, q rowQuerier, itemID int64, date time.Time) error {
+	var bookedCount int
+	queryCount := `SELECT COUNT(*) FROM bookings WHERE item_id = ? AND date = ? AND status NOT IN (?, ?)`
+	err := q.QueryRowContext(ctx, queryCount, itemID,
+		date.Format("2006-01-02"), models.StatusCanceled, "rejected").Scan(&bookedCount)
+	if err != nil {
+		return fmt.Errorf("failed to check availability in tx: %w", err)
+	}
+
+	capacity, err := db.effectiveQuantity(ctx, q, itemID, date)
+	if err != nil {
+		return err
+	}
+
+	if int64(bookedCount) >= capacity {
+		return ErrNotAvailable
+	}
+	return nil
+}
+
 func (db *DB) UpdateBookingComment(ctx context.Context, bookingID int64, comment string) error {
 	query := `UPDATE bookings SET comment = ?, updated_at = ? WHERE id = ?`
 	_, err := db.ExecContext(ctx, query, comment, time.Now(), bookingID)
 	return err
 }
 
+// SetBookingPrice stores the computed day price and deposit. Like the comment it does not bump the version.
+func (db *DB) SetBookingPrice(ctx context.Context, bookingID, price, deposit int64) error {
+	_, err := db.ExecContext(ctx, `UPDATE bookings SET price = ?, deposit = ?, updated_at = ? WHERE id = ?`,
+		price, deposit, time.Now(), bookingID)
+	if err != nil {
+		return fmt.Errorf("failed to set booking price: %w", err)
+	}
+	return nil
+}
+
 func (db *DB) GetBooking(ctx context.Context, id int64) (*models.Booking, error) {
 	var booking models.Booking
 	var dateStr string
 	query := `SELECT id, user_id, user_name, user_nickname, phone, item_id, 
 	                 item_name, date(date), status, comment, created_at, 
-					 updated_at, version 
+					 updated_at, version, unit_id, price, deposit
               FROM bookings WHERE id = ?`
 	err := db.QueryRowContext(ctx, query, id).Scan(
 		&booking.ID, &booking.UserID, &booking.UserName, &booking.UserNickname, &booking.Phone,
 		&booking.ItemID, &booking.ItemName, &dateStr, &booking.Status, &booking.Comment,
-		&booking.CreatedAt, &booking.UpdatedAt, &booking.Version,
+		&booking.CreatedAt, &booking.UpdatedAt, &booking.Version, &booking.UnitID, &booking.Price, &booking.Deposit,
 	)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get booking: %w", err)
@@ -186,7 +202,7 @@ func (db *DB) UpdateBookingStatusWithVersion(ctx context.Context, id, fromVersio
 func (db *DB) GetBookingsByDateRange(ctx context.Context, startDate, endDate time.Time) ([]*models.Booking, error) {
 	query := `SELECT id, user_id, user_name, user_nickname, phone, item_id, 
 	                 item_name, date(date), status, comment, created_at, 
-					 updated_at, version 
+					 updated_at, version, unit_id, price, deposit
               FROM bookings WHERE date(date) >= ? AND date(date) <= ? ORDER BY date ASC`
 	rows, err := db.QueryContext(ctx, query, startDate.Format("2006-01-02"), endDate.Format("2006-01-02"))
 	if err != nil {
@@ -201,7 +217,7 @@ func (db *DB) GetBookingsByDateRange(ctx context.Context, startDate, endDate tim
 		err := rows.Scan(
 			&b.ID, &b.UserID, &b.UserName, &b.UserNickname, &b.Phone,
 			&b.ItemID, &b.ItemName, &dateStr, &b.Status, &b.Comment,
-			&b.CreatedAt, &b.UpdatedAt, &b.Version,
+			&b.CreatedAt, &b.UpdatedAt, &b.Version, &b.UnitID, &b.Price, &b.Deposit,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan booking: %w", err)
@@ -239,6 +255,15 @@ func (db *DB) GetAvailabilityForPeriod(ctx context.Context, itemID int64, startD
 		bookedCounts[dateStr] = count
 	}
 
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	windows, err := db.GetMaintenanceWindows(ctx, itemID, startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+
 	db.mu.RLock()
 	item := db.itemsCache[itemID]
 	db.mu.RUnlock()
@@ -247,18 +272,20 @@ func (db *DB) GetAvailabilityForPeriod(ctx context.Context, itemID int64, startD
 	for i := 0; i < days; i++ {
 		date := startDate.AddDate(0, 0, i)
 		dateStr := date.Format("2006-01-02")
-		booked := bookedCounts[dateStr]
+		booked := int64(bookedCounts[dateStr])
+		maintenance := models.MaintenanceUnits(windows, itemID, date)
 
-		available := int(item.TotalQuantity) - booked
+		available := item.EffectiveQuantity(maintenance) - booked
 		if available < 0 {
 			available = 0
 		}
 
 		availability = append(availability, &models.Availability{
-			Date:      date,
-			ItemID:    itemID,
-			Booked:    int64(booked),
-			Available: int64(available),
+			Date:        date,
+			ItemID:      itemID,
+			Booked:      booked,
+			Available:   available,
+			Maintenance: maintenance,
 		})
 	}
 	return availability, nil
@@ -271,7 +298,7 @@ func (db *DB) UpdateBookingItem(ctx context.Context, id, itemID int64, itemName
 }
 
 func (db *DB) UpdateBookingItemWithVersion(ctx context.Context, id, fromVersion, itemID int64, itemName string) error {
-	query := `UPDATE bookings SET item_id = ?, item_name = ?, version = version + 1, updated_at = ? WHERE id = ? AND version = ?`
+	query := `UPDATE bookings SET item_id = ?, item_name = ?, unit_id = 0, version = version + 1, updated_at = ? WHERE id = ? AND version = ?`
 	result, err := db.ExecContext(ctx, query, itemID, itemName, time.Now(), id, fromVersion)
 	if err != nil {
 		return fmt.Errorf("failed to update booking item: %w", err)
@@ -284,7 +311,7 @@ func (db *DB) UpdateBookingItemWithVersion(ctx context.Context, id, fromVersion,
 }
 
 func (db *DB) UpdateBookingItemAndStatusWithVersion(ctx context.Context, id, fromVersion, itemID int64, itemName, status string) error {
-	query := `UPDATE bookings SET item_id = ?, item_name = ?, status = ?, version = version + 1, updated_at = ? WHERE id = ? AND version = ?`
+	query := `UPDATE bookings SET item_id = ?, item_name = ?, unit_id = 0, status = ?, version = version + 1, updated_at = ? WHERE id = ? AND version = ?`
 	result, err := db.ExecContext(ctx, query, itemID, itemName, status, time.Now(), id, fromVersion)
 	if err != nil {
 		return fmt.Errorf("failed to update booking item and status: %w", err)
@@ -301,7 +328,7 @@ func (db *DB) GetUserBookings(ctx context.Context, userID int64) ([]*models.Book
 	twoWeeksAgo := time.Now().AddDate(0, 0, -14).Format("2006-01-02")
 	query := `SELECT id, user_id, user_name, user_nickname, phone, item_id, 
 	                 item_name, date(date), status, comment, created_at, 
-					 updated_at, version 
+					 updated_at, version, unit_id, price, deposit
               FROM bookings WHERE user_id = ? AND date >= ? ORDER BY date DESC`
 	rows, err := db.QueryContext(ctx, query, userID, twoWeeksAgo)
 	if err != nil {
@@ -316,7 +343,7 @@ func (db *DB) GetUserBookings(ctx context.Context, userID int64) ([]*models.Book
 		err := rows.Scan(
 			&b.ID, &b.UserID, &b.UserName, &b.UserNickname, &b.Phone,
 			&b.ItemID, &b.ItemName, &dateStr, &b.Status, &b.Comment,
-			&b.CreatedAt, &b.UpdatedAt, &b.Version,
+			&b.CreatedAt, &b.UpdatedAt, &b.Version, &b.UnitID, &b.Price, &b.Deposit,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan booking: %w", err)
@@ -357,3 +384,149 @@ func (db *DB) GetDailyBookings(ctx context.Context, startDate, endDate time.Time
 	}
 	return daily, nil
 }
+
+// SearchBookings returns bookings matching the filter, most recent date first.
+// The client name is matched in Go because SQLite's LOWER/LIKE fold only ASCII,
+// so limit and offset are applied after that match.
+func (db *DB) SearchBookings(ctx context.Context, filter models.BookingFilter) ([]*models.Booking, error) {
+	where, args := bookingFilterWhere(&filter)
+
+	query := searchBookingsQuery + where + " ORDER BY date DESC, id DESC"
+
+	limit := filter.Limit
+	if limit <= 0 || limit > models.MaxSearchResults {
+		limit = models.MaxSearchResults
+	}
+	name := strings.ToLower(strings.TrimSpace(filter.ClientName))
+	if name == "" {
+		query += " LIMIT ? OFFSET ?"
+		args = append(args, limit, filter.Offset)
+	}
+
+	rows, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search bookings: %w", err)
+	}
+	defer rows.Close()
+
+	bookings := make([]*models.Booking, 0)
+	skipped := 0
+	for rows.Next() {
+		b, err := scanBooking(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		if name != "" {
+			if !strings.Contains(strings.ToLower(b.UserName), name) {
+				continue
+			}
+			if skipped < filter.Offset {
+				skipped++
+				continue
+			}
+			if len(bookings) >= limit {
+				break
+			}
+		}
+		bookings = append(bookings, b)
+	}
+	return bookings, rows.Err()
+}
+
+// StreamBookings calls fn for every booking matching the filter in date order, reading
+// rows one by one so large exports are not held in memory. Limit and offset are ignored.
+// An error returned by fn stops the iteration and is returned as is.
+func (db *DB) StreamBookings(ctx context.Context, filter models.BookingFilter, fn func(*models.Booking) error) error {
+	where, args := bookingFilterWhere(&filter)
+
+	rows, err := db.QueryContext(ctx, searchBookingsQuery+where+" ORDER BY date, id", args...)
+	if err != nil {
+		return fmt.Errorf("failed to query bookings: %w", err)
+	}
+	defer rows.Close()
+
+	name := strings.ToLower(strings.TrimSpace(filter.ClientName))
+	for rows.Next() {
+		b, err := scanBooking(rows)
+		if err != nil {
+			return err
+		}
+		if name != "" && !strings.Contains(strings.ToLower(b.UserName), name) {
+			continue
+		}
+		if err := fn(b); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+const searchBookingsQuery = `SELECT id, user_id, user_name, user_nickname, phone, item_id, 
+	                 item_name, date(date), status, comment, created_at, 
+					 updated_at, version, unit_id, price, deposit
+              FROM bookings`
+
+// bookingFilterWhere builds the WHERE clause of the SQL conditions of the filter.
+// The client name is not included, it is matched by the callers.
+func bookingFilterWhere(filter *models.BookingFilter) (string, []interface{}) {
+	var where []string
+	var args []interface{}
+
+	if filter.BookingID != 0 {
+		where = append(where, "id = ?")
+		args = append(args, filter.BookingID)
+	}
+	if len(filter.Statuses) > 0 {
+		where = append(where, "status IN ("+placeholders(len(filter.Statuses))+")")
+		for _, s := range filter.Statuses {
+			args = append(args, s)
+		}
+	}
+	if len(filter.ItemIDs) > 0 {
+		where = append(where, "item_id IN ("+placeholders(len(filter.ItemIDs))+")")
+		for _, id := range filter.ItemIDs {
+			args = append(args, id)
+		}
+	}
+	if !filter.DateFrom.IsZero() {
+		where = append(where, "date(date) >= ?")
+		args = append(args, filter.DateFrom.Format("2006-01-02"))
+	}
+	if !filter.DateTo.IsZero() {
+		where = append(where, "date(date) <= ?")
+		args = append(args, filter.DateTo.Format("2006-01-02"))
+	}
+	if filter.Phone != "" {
+		where = append(where, "phone LIKE ?")
+		args = append(args, "%"+filter.Phone+"%")
+	}
+
+	if len(where) == 0 {
+		return "", args
+	}
+	return " WHERE " + strings.Join(where, " AND "), args
+}
+
+// scanBooking scans a row of searchBookingsQuery.
+func scanBooking(rs rowScanner) (*models.Booking, error) {
+	b := &models.Booking{}
+	var dateStr string
+	err := rs.Scan(
+		&b.ID, &b.UserID, &b.UserName, &b.UserNickname, &b.Phone,
+		&b.ItemID, &b.ItemName, &dateStr, &b.Status, &b.Comment,
+		&b.CreatedAt, &b.UpdatedAt, &b.Version, &b.UnitID, &b.Price, &b.Deposit,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan booking: %w", err)
+	}
+	b.Date, err = time.Parse("2006-01-02", dateStr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse booking date %s: %w", dateStr, err)
+	}
+	return b, nil
+}
+
+func placeholders(n int) string {
+	return strings.TrimSuffix(strings.Repeat("?,", n), ",")
+}
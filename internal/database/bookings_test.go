@@ -2,6 +2,7 @@ package database
 
 import (
 	"context"
+	"errors"
 	"os"
 	"testing"
 	"time"
@@ -229,6 +230,14 @@ func TestBookingUpdateExtras(t *testing.T) {
 		assert.Equal(t, "New Comment", updated.Comment)
 	})
 
+	t.Run("SetPrice", func(t *testing.T) {
+		require.NoError(t, db.SetBookingPrice(ctx, booking.ID, 1500, 5000))
+		updated, _ := db.GetBooking(ctx, booking.ID)
+		assert.Equal(t, int64(1500), updated.Price)
+		assert.Equal(t, int64(5000), updated.Deposit)
+		assert.Equal(t, booking.Version, updated.Version)
+	})
+
 	t.Run("UpdateStatus", func(t *testing.T) {
 		err := db.UpdateBookingStatus(ctx, booking.ID, models.StatusConfirmed)
 		require.NoError(t, err)
@@ -324,3 +333,101 @@ func TestCreateBookingWithLock_Failure(t *testing.T) {
 	err = db.CreateBookingWithLock(ctx, b2)
 	assert.ErrorIs(t, err, ErrNotAvailable)
 }
+
+func TestSearchBookings(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	ctx := context.Background()
+
+	camera := &models.Item{Name: "Camera", TotalQuantity: 5, IsActive: true}
+	light := &models.Item{Name: "Light", TotalQuantity: 5, IsActive: true}
+	require.NoError(t, db.CreateItem(ctx, camera))
+	require.NoError(t, db.CreateItem(ctx, light))
+
+	day := time.Date(2030, 3, 10, 0, 0, 0, 0, time.UTC)
+	for _, b := range []*models.Booking{
+		{ItemID: camera.ID, ItemName: "Camera", Date: day, UserID: 1, UserName: "Иван Петров", Phone: "79991234567", Status: models.StatusPending},
+		{ItemID: light.ID, ItemName: "Light", Date: day.AddDate(0, 0, 1), UserID: 2, UserName: "Анна Иванова", Phone: "79990000000", Status: models.StatusConfirmed},
+		{ItemID: camera.ID, ItemName: "Camera", Date: day.AddDate(0, 0, 5), UserID: 3, UserName: "John Smith", Phone: "79995554433", Status: models.StatusCanceled},
+	} {
+		require.NoError(t, db.CreateBooking(ctx, b))
+	}
+
+	names := func(bookings []*models.Booking) []string {
+		out := make([]string, 0, len(bookings))
+		for _, b := range bookings {
+			out = append(out, b.UserName)
+		}
+		return out
+	}
+
+	tests := []struct {
+		name   string
+		filter models.BookingFilter
+		want   []string
+	}{
+		{"All newest first", models.BookingFilter{}, []string{"John Smith", "Анна Иванова", "Иван Петров"}},
+		{"Name is case-insensitive for cyrillic", models.BookingFilter{ClientName: "иван"}, []string{"Анна Иванова", "Иван Петров"}},
+		{"Phone substring", models.BookingFilter{Phone: "1234567"}, []string{"Иван Петров"}},
+		{"Status", models.BookingFilter{Statuses: []string{models.StatusConfirmed, models.StatusCanceled}}, []string{"John Smith", "Анна Иванова"}},
+		{"Item", models.BookingFilter{ItemIDs: []int64{camera.ID}}, []string{"John Smith", "Иван Петров"}},
+		{"Date range", models.BookingFilter{DateFrom: day, DateTo: day.AddDate(0, 0, 1)}, []string{"Анна Иванова", "Иван Петров"}},
+		{"Limit and offset", models.BookingFilter{Limit: 1, Offset: 1}, []string{"Анна Иванова"}},
+		{"Name with offset", models.BookingFilter{ClientName: "иван", Offset: 1}, []string{"Иван Петров"}},
+		{"Combined without match", models.BookingFilter{ClientName: "john", Statuses: []string{models.StatusPending}}, []string{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bookings, err := db.SearchBookings(ctx, tt.filter)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, names(bookings))
+		})
+	}
+
+	byID, err := db.SearchBookings(ctx, models.BookingFilter{BookingID: 2})
+	require.NoError(t, err)
+	require.Len(t, byID, 1)
+	assert.Equal(t, int64(2), byID[0].ID)
+}
+
+func TestStreamBookings(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	ctx := context.Background()
+
+	camera := &models.Item{Name: "Camera", TotalQuantity: 5, IsActive: true}
+	require.NoError(t, db.CreateItem(ctx, camera))
+
+	day := time.Date(2030, 3, 10, 0, 0, 0, 0, time.UTC)
+	for i, name := range []string{"Иван Петров", "Анна Иванова", "John Smith"} {
+		require.NoError(t, db.CreateBooking(ctx, &models.Booking{
+			ItemID: camera.ID, ItemName: "Camera", Date: day.AddDate(0, 0, 2-i), UserID: int64(i + 1),
+			UserName: name, Phone: "79991234567", Status: models.StatusConfirmed, Price: 1000,
+		}))
+	}
+
+	stream := func(filter models.BookingFilter) []string {
+		var names []string
+		require.NoError(t, db.StreamBookings(ctx, filter, func(b *models.Booking) error {
+			names = append(names, b.UserName)
+			return nil
+		}))
+		return names
+	}
+
+	assert.Equal(t, []string{"John Smith", "Анна Иванова", "Иван Петров"}, stream(models.BookingFilter{}), "oldest date first")
+	assert.Equal(t, []string{"Анна Иванова", "Иван Петров"}, stream(models.BookingFilter{ClientName: "иван"}))
+	assert.Equal(t, []string{"Анна Иванова"}, stream(models.BookingFilter{DateFrom: day.AddDate(0, 0, 1), DateTo: day.AddDate(0, 0, 1)}))
+	assert.Equal(t, []string{"John Smith", "Анна Иванова", "Иван Петров"}, stream(models.BookingFilter{Limit: 1}), "limit is ignored")
+
+	stop := errors.New("stop")
+	calls := 0
+	err := db.StreamBookings(ctx, models.BookingFilter{}, func(*models.Booking) error {
+		calls++
+		return stop
+	})
+	assert.ErrorIs(t, err, stop)
+	assert.Equal(t, 1, calls)
+}
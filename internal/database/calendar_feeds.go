@@ -0,0 +1,70 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"bronivik/internal/models"
+)
+
+const calendarFeedColumns = `id, scope, subject_id, created_by, created_at, revoked_at`
+
+// CreateCalendarFeed issues a new calendar subscription.
+func (db *DB) CreateCalendarFeed(ctx context.Context, feed *models.CalendarFeed) error {
+	now := time.Now()
+	res, err := db.ExecContext(ctx, `INSERT INTO calendar_feeds (scope, subject_id, created_by, created_at)
+              VALUES (?, ?, ?, ?)`, feed.Scope, feed.SubjectID, feed.CreatedBy, now)
+	if err != nil {
+		return fmt.Errorf("failed to create calendar feed: %w", err)
+	}
+
+	id, err := res.LastInsertId()
+	if err != nil {
+		return fmt.Errorf("failed to get last insert id: %w", err)
+	}
+	feed.ID = id
+	feed.CreatedAt = now
+	feed.RevokedAt = sql.NullTime{}
+	return nil
+}
+
+// GetCalendarFeed returns a feed by ID, revoked or not, or models.ErrCalendarFeedNotFound.
+func (db *DB) GetCalendarFeed(ctx context.Context, id int64) (*models.CalendarFeed, error) {
+	row := db.QueryRowContext(ctx, `SELECT `+calendarFeedColumns+` FROM calendar_feeds WHERE id = ?`, id)
+	return scanCalendarFeed(row)
+}
+
+// GetActiveCalendarFeed returns the latest not revoked feed of the scope and subject
+// or models.ErrCalendarFeedNotFound.
+func (db *DB) GetActiveCalendarFeed(ctx context.Context, scope string, subjectID int64) (*models.CalendarFeed, error) {
+	row := db.QueryRowContext(ctx, `SELECT `+calendarFeedColumns+` FROM calendar_feeds
+              WHERE scope = ? AND subject_id = ? AND revoked_at IS NULL
+              ORDER BY id DESC LIMIT 1`, scope, subjectID)
+	return scanCalendarFeed(row)
+}
+
+// RevokeCalendarFeeds revokes all active feeds of the scope and subject and returns how many were revoked.
+func (db *DB) RevokeCalendarFeeds(ctx context.Context, scope string, subjectID int64) (int64, error) {
+	res, err := db.ExecContext(ctx, `UPDATE calendar_feeds SET revoked_at = ?
+              WHERE scope = ? AND subject_id = ? AND revoked_at IS NULL`, time.Now(), scope, subjectID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to revoke calendar feeds: %w", err)
+	}
+	n, _ := res.RowsAffected()
+	return n, nil
+}
+
+func scanCalendarFeed(row *sql.Row) (*models.CalendarFeed, error) {
+	feed := &models.CalendarFeed{}
+	err := row.Scan(&feed.ID, &feed.Scope, &feed.SubjectID, &feed.CreatedBy, &feed.CreatedAt, &feed.RevokedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, models.ErrCalendarFeedNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get calendar feed: %w", err)
+	}
+	return feed, nil
+}
@@ -0,0 +1,62 @@
+package database
+
+import (
+	"context"
+	"testing"
+
+	"bronivik/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCalendarFeeds(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	ctx := context.Background()
+
+	_, err := db.GetActiveCalendarFeed(ctx, models.CalendarScopeUser, 777)
+	assert.ErrorIs(t, err, models.ErrCalendarFeedNotFound)
+
+	feed := &models.CalendarFeed{Scope: models.CalendarScopeUser, SubjectID: 777, CreatedBy: 777}
+	require.NoError(t, db.CreateCalendarFeed(ctx, feed))
+	assert.NotZero(t, feed.ID)
+	require.NoError(t, db.CreateCalendarFeed(ctx, &models.CalendarFeed{Scope: models.CalendarScopeItem, SubjectID: 777}))
+
+	active, err := db.GetActiveCalendarFeed(ctx, models.CalendarScopeUser, 777)
+	require.NoError(t, err)
+	assert.Equal(t, feed.ID, active.ID)
+	assert.False(t, active.IsRevoked())
+
+	revoked, err := db.RevokeCalendarFeeds(ctx, models.CalendarScopeUser, 777)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), revoked)
+
+	_, err = db.GetActiveCalendarFeed(ctx, models.CalendarScopeUser, 777)
+	assert.ErrorIs(t, err, models.ErrCalendarFeedNotFound)
+	old, err := db.GetCalendarFeed(ctx, feed.ID)
+	require.NoError(t, err)
+	assert.True(t, old.IsRevoked())
+
+	// Фид аппарата с тем же subject_id не затронут
+	_, err = db.GetActiveCalendarFeed(ctx, models.CalendarScopeItem, 777)
+	require.NoError(t, err)
+
+	_, err = db.GetCalendarFeed(ctx, 9999)
+	assert.ErrorIs(t, err, models.ErrCalendarFeedNotFound)
+}
+
+func TestAnonymizeUser_RevokesCalendarFeeds(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	ctx := context.Background()
+	require.NoError(t, db.CreateCalendarFeed(ctx, &models.CalendarFeed{Scope: models.CalendarScopeUser, SubjectID: 777}))
+
+	_, err := db.AnonymizeUser(ctx, 777)
+	require.NoError(t, err)
+
+	_, err = db.GetActiveCalendarFeed(ctx, models.CalendarScopeUser, 777)
+	assert.ErrorIs(t, err, models.ErrCalendarFeedNotFound)
+}
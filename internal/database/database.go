@@ -89,6 +89,14 @@ func (db *DB) createTables() error {
 			total_quantity INTEGER NOT NULL DEFAULT 1,
 			sort_order INTEGER NOT NULL DEFAULT 0,
 			is_active BOOLEAN NOT NULL DEFAULT 1,
+			category TEXT NOT NULL DEFAULT '',
+			photos TEXT NOT NULL DEFAULT '',
+			specs TEXT NOT NULL DEFAULT '',
+			price_per_day INTEGER NOT NULL DEFAULT 0,
+			weekend_price INTEGER NOT NULL DEFAULT 0,
+			deposit INTEGER NOT NULL DEFAULT 0,
+			long_rental_days INTEGER NOT NULL DEFAULT 0,
+			long_rental_discount INTEGER NOT NULL DEFAULT 0,
 			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
 			updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
 		)`,
@@ -105,7 +113,15 @@ func (db *DB) createTables() error {
             language_code TEXT,
             last_activity DATETIME NOT NULL,
             created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
-            updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+            updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+            blacklist_reason TEXT NOT NULL DEFAULT '',
+            blacklisted_until DATETIME,
+            blacklisted_by INTEGER NOT NULL DEFAULT 0,
+            consent_given BOOLEAN NOT NULL DEFAULT 0,
+            consent_given_at DATETIME,
+            consent_revoked BOOLEAN NOT NULL DEFAULT 0,
+            consent_revoked_at DATETIME,
+            preferred_language TEXT NOT NULL DEFAULT ''
         )`,
 		// Таблица бронирований
 		`CREATE TABLE IF NOT EXISTS bookings (
@@ -122,6 +138,9 @@ func (db *DB) createTables() error {
 			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
 			updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
 			version INTEGER NOT NULL DEFAULT 1,
+			unit_id INTEGER NOT NULL DEFAULT 0,
+			price INTEGER NOT NULL DEFAULT 0,
+			deposit INTEGER NOT NULL DEFAULT 0,
 			FOREIGN KEY(item_id) REFERENCES items(id),
 			FOREIGN KEY(user_id) REFERENCES users(telegram_id)
 		)`,
@@ -156,6 +175,124 @@ func (db *DB) createTables() error {
 		`CREATE INDEX IF NOT EXISTS idx_sync_queue_status ON sync_queue(status)`,
 		`CREATE INDEX IF NOT EXISTS idx_sync_queue_next_retry ON sync_queue(next_retry_at)`,
 
+		// Роли сотрудников и их области действия по аппаратам
+		`CREATE TABLE IF NOT EXISTS user_roles (
+			telegram_id INTEGER PRIMARY KEY,
+			role TEXT NOT NULL,
+			granted_by INTEGER NOT NULL DEFAULT 0,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+		)`,
+		`CREATE TABLE IF NOT EXISTS user_role_items (
+			telegram_id INTEGER NOT NULL,
+			item_id INTEGER NOT NULL,
+			PRIMARY KEY (telegram_id, item_id)
+		)`,
+
+		// Журнал изменений (только добавление записей)
+		`CREATE TABLE IF NOT EXISTS audit_log (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			entity_type TEXT NOT NULL,
+			entity_id INTEGER NOT NULL,
+			action TEXT NOT NULL,
+			actor_id INTEGER NOT NULL DEFAULT 0,
+			source TEXT NOT NULL DEFAULT 'system',
+			before_value TEXT NOT NULL DEFAULT '',
+			after_value TEXT NOT NULL DEFAULT '',
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+		)`,
+		`CREATE INDEX IF NOT EXISTS idx_audit_log_entity ON audit_log(entity_type, entity_id)`,
+		// Изменять записи журнала нельзя, кроме удаления комментария и заметок о выдаче клиента
+		// из снимков заявки при обезличивании (см. AnonymizeUser). Триггеры прежних версий
+		// запрещали любое изменение или разрешали удалять только комментарий.
+		`DROP TRIGGER IF EXISTS audit_log_no_update`,
+		`DROP TRIGGER IF EXISTS audit_log_scrub_only`,
+		`CREATE TRIGGER IF NOT EXISTS audit_log_scrub_personal BEFORE UPDATE ON audit_log
+			WHEN NEW.id IS NOT OLD.id OR NEW.entity_type IS NOT OLD.entity_type OR NEW.entity_id IS NOT OLD.entity_id
+				OR NEW.action IS NOT OLD.action OR NEW.actor_id IS NOT OLD.actor_id OR NEW.source IS NOT OLD.source
+				OR NEW.created_at IS NOT OLD.created_at
+				OR NEW.before_value IS NOT (` + auditScrubExpr("OLD.before_value") + `)
+				OR NEW.after_value IS NOT (` + auditScrubExpr("OLD.after_value") + `)
+			BEGIN SELECT RAISE(ABORT, 'audit_log is append-only'); END`,
+		`CREATE TRIGGER IF NOT EXISTS audit_log_no_delete BEFORE DELETE ON audit_log
+			BEGIN SELECT RAISE(ABORT, 'audit_log is append-only'); END`,
+
+		// Эскалации заявок, оставшихся без ответа дольше SLA
+		`CREATE TABLE IF NOT EXISTS booking_escalations (
+			booking_id INTEGER PRIMARY KEY,
+			level INTEGER NOT NULL DEFAULT 0,
+			assigned_to INTEGER NOT NULL DEFAULT 0,
+			escalated_at DATETIME NOT NULL
+		)`,
+
+		// Отправленные напоминания: не даем повторить их после рестарта или со второй реплики
+		`CREATE TABLE IF NOT EXISTS sent_reminders (
+			booking_id INTEGER NOT NULL,
+			reminder_key TEXT NOT NULL,
+			sent_at DATETIME NOT NULL,
+			PRIMARY KEY (booking_id, reminder_key)
+		)`,
+
+		// Фактическая выдача и возврат аппаратов
+		`CREATE TABLE IF NOT EXISTS booking_handovers (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			booking_id INTEGER NOT NULL UNIQUE,
+			user_id INTEGER NOT NULL,
+			user_name TEXT NOT NULL DEFAULT '',
+			item_id INTEGER NOT NULL,
+			item_name TEXT NOT NULL DEFAULT '',
+			planned_start TEXT NOT NULL,
+			planned_end TEXT NOT NULL,
+			checked_out_at DATETIME NOT NULL,
+			checked_out_by INTEGER NOT NULL,
+			check_out_note TEXT NOT NULL DEFAULT '',
+			check_out_photos TEXT NOT NULL DEFAULT '',
+			checked_in_at DATETIME,
+			checked_in_by INTEGER NOT NULL DEFAULT 0,
+			check_in_note TEXT NOT NULL DEFAULT '',
+			check_in_photos TEXT NOT NULL DEFAULT '',
+			overdue_notified_at DATETIME,
+			unit_id INTEGER NOT NULL DEFAULT 0
+		)`,
+		`CREATE INDEX IF NOT EXISTS idx_handovers_user_item ON booking_handovers(user_id, item_id, planned_start)`,
+
+		// Таблица обслуживания: часть аппаратов выведена из работы на период
+		`CREATE TABLE IF NOT EXISTS item_maintenance (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			item_id INTEGER NOT NULL,
+			units INTEGER NOT NULL,
+			start_date TEXT NOT NULL,
+			end_date TEXT NOT NULL,
+			reason TEXT NOT NULL DEFAULT '',
+			created_by INTEGER NOT NULL DEFAULT 0,
+			created_at DATETIME NOT NULL
+		)`,
+		`CREATE INDEX IF NOT EXISTS idx_maintenance_item_dates ON item_maintenance(item_id, start_date, end_date)`,
+
+		// Таблица экземпляров аппаратов с серийными и инвентарными номерами
+		`CREATE TABLE IF NOT EXISTS item_units (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			item_id INTEGER NOT NULL,
+			serial_number TEXT NOT NULL UNIQUE,
+			inventory_tag TEXT NOT NULL DEFAULT '',
+			status TEXT NOT NULL DEFAULT 'active',
+			note TEXT NOT NULL DEFAULT '',
+			created_at DATETIME NOT NULL,
+			updated_at DATETIME NOT NULL
+		)`,
+		`CREATE INDEX IF NOT EXISTS idx_item_units_item ON item_units(item_id)`,
+
+		// Таблица выданных подписок на календарь (iCalendar); отозванная подписка перестает открываться
+		`CREATE TABLE IF NOT EXISTS calendar_feeds (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			scope TEXT NOT NULL,
+			subject_id INTEGER NOT NULL DEFAULT 0,
+			created_by INTEGER NOT NULL DEFAULT 0,
+			created_at DATETIME NOT NULL,
+			revoked_at DATETIME
+		)`,
+		`CREATE INDEX IF NOT EXISTS idx_calendar_feeds_subject ON calendar_feeds(scope, subject_id)`,
+
 		// Существующие индексы для бронирований
 		`CREATE INDEX IF NOT EXISTS idx_bookings_date ON bookings(date)`,
 		`CREATE INDEX IF NOT EXISTS idx_bookings_status ON bookings(status)`,
@@ -172,17 +309,93 @@ func (db *DB) createTables() error {
 	if err := db.ensureBookingVersionColumn(); err != nil {
 		return err
 	}
-	return nil
+	if err := db.ensureUserBlacklistColumns(); err != nil {
+		return err
+	}
+	if err := db.ensureUserConsentColumns(); err != nil {
+		return err
+	}
+	if err := db.ensureColumn("users", "preferred_language", "TEXT NOT NULL DEFAULT ''"); err != nil {
+		return err
+	}
+	if err := db.ensureColumn("users", "reminders_disabled", "BOOLEAN NOT NULL DEFAULT 0"); err != nil {
+		return err
+	}
+	if err := db.ensureColumn("bookings", "unit_id", "INTEGER NOT NULL DEFAULT 0"); err != nil {
+		return err
+	}
+	if err := db.ensureColumn("booking_handovers", "unit_id", "INTEGER NOT NULL DEFAULT 0"); err != nil {
+		return err
+	}
+	if err := db.ensureItemCatalogColumns(); err != nil {
+		return err
+	}
+	if err := db.ensureColumn("bookings", "price", "INTEGER NOT NULL DEFAULT 0"); err != nil {
+		return err
+	}
+	if err := db.ensureColumn("item_maintenance", "source", "TEXT NOT NULL DEFAULT ''"); err != nil {
+		return err
+	}
+	return db.ensureColumn("bookings", "deposit", "INTEGER NOT NULL DEFAULT 0")
 }
 
 func (db *DB) ensureBookingVersionColumn() error {
-	_, err := db.Exec(`ALTER TABLE bookings ADD COLUMN version INTEGER NOT NULL DEFAULT 1`)
+	return db.ensureColumn("bookings", "version", "INTEGER NOT NULL DEFAULT 1")
+}
+
+func (db *DB) ensureUserBlacklistColumns() error {
+	if err := db.ensureColumn("users", "blacklist_reason", "TEXT NOT NULL DEFAULT ''"); err != nil {
+		return err
+	}
+	if err := db.ensureColumn("users", "blacklisted_until", "DATETIME"); err != nil {
+		return err
+	}
+	return db.ensureColumn("users", "blacklisted_by", "INTEGER NOT NULL DEFAULT 0")
+}
+
+func (db *DB) ensureUserConsentColumns() error {
+	columns := [][2]string{
+		{"consent_given", "BOOLEAN NOT NULL DEFAULT 0"},
+		{"consent_given_at", "DATETIME"},
+		{"consent_revoked", "BOOLEAN NOT NULL DEFAULT 0"},
+		{"consent_revoked_at", "DATETIME"},
+	}
+	for _, c := range columns {
+		if err := db.ensureColumn("users", c[0], c[1]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (db *DB) ensureItemCatalogColumns() error {
+	columns := [][2]string{
+		{"category", "TEXT NOT NULL DEFAULT ''"},
+		{"photos", "TEXT NOT NULL DEFAULT ''"},
+		{"specs", "TEXT NOT NULL DEFAULT ''"},
+		{"price_per_day", "INTEGER NOT NULL DEFAULT 0"},
+		{"weekend_price", "INTEGER NOT NULL DEFAULT 0"},
+		{"deposit", "INTEGER NOT NULL DEFAULT 0"},
+		{"long_rental_days", "INTEGER NOT NULL DEFAULT 0"},
+		{"long_rental_discount", "INTEGER NOT NULL DEFAULT 0"},
+	}
+	for _, c := range columns {
+		if err := db.ensureColumn("items", c[0], c[1]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ensureColumn adds a column to tables created by older versions of the schema.
+func (db *DB) ensureColumn(table, column, definition string) error {
+	_, err := db.Exec(fmt.Sprintf(`ALTER TABLE %s ADD COLUMN %s %s`, table, column, definition))
 	if err != nil {
 		// Ignore duplicate column error for SQLite
 		if strings.Contains(strings.ToLower(err.Error()), "duplicate column") {
 			return nil
 		}
-		return fmt.Errorf("failed to add version column: %w", err)
+		return fmt.Errorf("failed to add %s.%s column: %w", table, column, err)
 	}
 	return nil
 }
@@ -0,0 +1,54 @@
+package database
+
+import (
+	"context"
+	"fmt"
+
+	"bronivik/internal/models"
+)
+
+// GetBookingEscalations returns escalation records of the given bookings keyed by booking ID.
+// Bookings that were never escalated are absent from the map.
+func (db *DB) GetBookingEscalations(ctx context.Context, bookingIDs []int64) (map[int64]*models.BookingEscalation, error) {
+	result := make(map[int64]*models.BookingEscalation, len(bookingIDs))
+	if len(bookingIDs) == 0 {
+		return result, nil
+	}
+
+	args := make([]interface{}, 0, len(bookingIDs))
+	for _, id := range bookingIDs {
+		args = append(args, id)
+	}
+
+	rows, err := db.QueryContext(ctx,
+		`SELECT booking_id, level, assigned_to, escalated_at FROM booking_escalations
+		 WHERE booking_id IN (`+placeholders(len(bookingIDs))+`)`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get booking escalations: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var e models.BookingEscalation
+		if err := rows.Scan(&e.BookingID, &e.Level, &e.AssignedTo, &e.EscalatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan booking escalation: %w", err)
+		}
+		result[e.BookingID] = &e
+	}
+	return result, rows.Err()
+}
+
+// SaveBookingEscalation creates or replaces the escalation record of a booking.
+func (db *DB) SaveBookingEscalation(ctx context.Context, e *models.BookingEscalation) error {
+	_, err := db.ExecContext(ctx, `INSERT INTO booking_escalations (booking_id, level, assigned_to, escalated_at)
+              VALUES (?, ?, ?, ?)
+              ON CONFLICT(booking_id) DO UPDATE SET
+                level = excluded.level,
+                assigned_to = excluded.assigned_to,
+                escalated_at = excluded.escalated_at`,
+		e.BookingID, e.Level, e.AssignedTo, e.EscalatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to save booking escalation: %w", err)
+	}
+	return nil
+}
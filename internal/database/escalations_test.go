@@ -0,0 +1,38 @@
+package database
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"bronivik/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBookingEscalations(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	ctx := context.Background()
+
+	empty, err := db.GetBookingEscalations(ctx, nil)
+	require.NoError(t, err)
+	assert.Empty(t, empty)
+
+	first := time.Date(2030, 1, 10, 9, 0, 0, 0, time.UTC)
+	require.NoError(t, db.SaveBookingEscalation(ctx, &models.BookingEscalation{BookingID: 5, Level: 1, EscalatedAt: first}))
+	require.NoError(t, db.SaveBookingEscalation(ctx, &models.BookingEscalation{BookingID: 6, Level: 1, AssignedTo: 42, EscalatedAt: first}))
+
+	// Повторная эскалация обновляет запись
+	second := first.Add(4 * time.Hour)
+	require.NoError(t, db.SaveBookingEscalation(ctx, &models.BookingEscalation{BookingID: 5, Level: 2, AssignedTo: 43, EscalatedAt: second}))
+
+	escalations, err := db.GetBookingEscalations(ctx, []int64{5, 7})
+	require.NoError(t, err)
+	require.Len(t, escalations, 1)
+	assert.Equal(t, 2, escalations[5].Level)
+	assert.Equal(t, int64(43), escalations[5].AssignedTo)
+	assert.True(t, second.Equal(escalations[5].EscalatedAt))
+}
@@ -0,0 +1,153 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"bronivik/internal/models"
+)
+
+const handoverColumns = `id, booking_id, user_id, user_name, item_id, item_name,
+		planned_start, planned_end, checked_out_at, checked_out_by, check_out_note, check_out_photos,
+		checked_in_at, checked_in_by, check_in_note, check_in_photos, overdue_notified_at, unit_id`
+
+// CreateHandover records that the equipment of a booking run left the office.
+// It returns models.ErrAlreadyCheckedOut if the run was checked out before.
+func (db *DB) CreateHandover(ctx context.Context, h *models.Handover) error {
+	res, err := db.ExecContext(ctx, `INSERT INTO booking_handovers (
+				booking_id, user_id, user_name, item_id, item_name, planned_start, planned_end,
+				checked_out_at, checked_out_by, check_out_note, check_out_photos, unit_id
+			) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+              ON CONFLICT(booking_id) DO NOTHING`,
+		h.BookingID, h.UserID, h.UserName, h.ItemID, h.ItemName,
+		h.PlannedStart.Format("2006-01-02"), h.PlannedEnd.Format("2006-01-02"),
+		h.CheckedOutAt, h.CheckedOutBy, h.CheckOutNote, joinPhotos(h.CheckOutPhotos), h.UnitID)
+	if err != nil {
+		return fmt.Errorf("failed to create handover: %w", err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return models.ErrAlreadyCheckedOut
+	}
+
+	id, err := res.LastInsertId()
+	if err != nil {
+		return fmt.Errorf("failed to get last insert id: %w", err)
+	}
+	h.ID = id
+	return nil
+}
+
+// CheckInHandover records the return of the equipment.
+// It returns models.ErrNotCheckedOut if the handover is already closed.
+func (db *DB) CheckInHandover(ctx context.Context, h *models.Handover) error {
+	res, err := db.ExecContext(ctx, `UPDATE booking_handovers
+              SET checked_in_at = ?, checked_in_by = ?, check_in_note = ?, check_in_photos = ?
+              WHERE id = ? AND checked_in_at IS NULL`,
+		h.CheckedInAt.Time, h.CheckedInBy, h.CheckInNote, joinPhotos(h.CheckInPhotos), h.ID)
+	if err != nil {
+		return fmt.Errorf("failed to check in handover: %w", err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return models.ErrNotCheckedOut
+	}
+	return nil
+}
+
+// FindHandover returns the handover of the client's run of the item that covers date.
+// It returns nil without an error when the equipment was not checked out.
+func (db *DB) FindHandover(ctx context.Context, userID, itemID int64, date time.Time) (*models.Handover, error) {
+	day := date.Format("2006-01-02")
+	row := db.QueryRowContext(ctx, `SELECT `+handoverColumns+` FROM booking_handovers
+              WHERE user_id = ? AND item_id = ? AND planned_start <= ? AND planned_end >= ?
+              ORDER BY id DESC LIMIT 1`, userID, itemID, day, day)
+	h, err := scanHandover(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to find handover: %w", err)
+	}
+	return h, nil
+}
+
+// GetUserHandovers returns every handover of the client, newest first.
+func (db *DB) GetUserHandovers(ctx context.Context, userID int64) ([]*models.Handover, error) {
+	return db.queryHandovers(ctx, `SELECT `+handoverColumns+` FROM booking_handovers
+              WHERE user_id = ? ORDER BY planned_start DESC, id DESC`, userID)
+}
+
+// GetOpenHandovers returns handovers whose equipment has not been returned yet.
+func (db *DB) GetOpenHandovers(ctx context.Context) ([]*models.Handover, error) {
+	return db.queryHandovers(ctx, `SELECT `+handoverColumns+` FROM booking_handovers
+              WHERE checked_in_at IS NULL ORDER BY planned_end ASC, id ASC`)
+}
+
+// GetHandoversByPeriod returns handovers whose planned usage overlaps the period.
+func (db *DB) GetHandoversByPeriod(ctx context.Context, start, end time.Time) ([]*models.Handover, error) {
+	return db.queryHandovers(ctx, `SELECT `+handoverColumns+` FROM booking_handovers
+              WHERE planned_start <= ? AND planned_end >= ? ORDER BY planned_start ASC, id ASC`,
+		end.Format("2006-01-02"), start.Format("2006-01-02"))
+}
+
+// SetHandoverOverdueNotified remembers when managers were last alerted about an overdue return.
+func (db *DB) SetHandoverOverdueNotified(ctx context.Context, id int64, at time.Time) error {
+	_, err := db.ExecContext(ctx, `UPDATE booking_handovers SET overdue_notified_at = ? WHERE id = ?`, at, id)
+	if err != nil {
+		return fmt.Errorf("failed to update handover: %w", err)
+	}
+	return nil
+}
+
+func (db *DB) queryHandovers(ctx context.Context, query string, args ...interface{}) ([]*models.Handover, error) {
+	rows, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get handovers: %w", err)
+	}
+	defer rows.Close()
+
+	var handovers []*models.Handover
+	for rows.Next() {
+		h, err := scanHandover(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan handover: %w", err)
+		}
+		handovers = append(handovers, h)
+	}
+	return handovers, rows.Err()
+}
+
+func scanHandover(row rowScanner) (*models.Handover, error) {
+	var h models.Handover
+	var plannedStart, plannedEnd, outPhotos, inPhotos string
+	err := row.Scan(
+		&h.ID, &h.BookingID, &h.UserID, &h.UserName, &h.ItemID, &h.ItemName,
+		&plannedStart, &plannedEnd, &h.CheckedOutAt, &h.CheckedOutBy, &h.CheckOutNote, &outPhotos,
+		&h.CheckedInAt, &h.CheckedInBy, &h.CheckInNote, &inPhotos, &h.OverdueNotifiedAt, &h.UnitID,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if h.PlannedStart, err = time.Parse("2006-01-02", plannedStart); err != nil {
+		return nil, fmt.Errorf("failed to parse planned start %s: %w", plannedStart, err)
+	}
+	if h.PlannedEnd, err = time.Parse("2006-01-02", plannedEnd); err != nil {
+		return nil, fmt.Errorf("failed to parse planned end %s: %w", plannedEnd, err)
+	}
+	h.CheckOutPhotos = splitPhotos(outPhotos)
+	h.CheckInPhotos = splitPhotos(inPhotos)
+	return &h, nil
+}
+
+// Telegram file IDs never contain spaces, so photos are stored as a space separated list.
+func joinPhotos(photos []string) string {
+	return strings.Join(photos, " ")
+}
+
+func splitPhotos(s string) []string {
+	return strings.Fields(s)
+}
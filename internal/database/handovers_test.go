@@ -0,0 +1,81 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"bronivik/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandovers(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	ctx := context.Background()
+	day := func(d int) time.Time { return time.Date(2030, 3, d, 0, 0, 0, 0, time.UTC) }
+
+	none, err := db.FindHandover(ctx, 10, 1, day(2))
+	require.NoError(t, err)
+	assert.Nil(t, none)
+
+	out := &models.Handover{
+		BookingID: 100, UserID: 10, UserName: "Client", ItemID: 1, ItemName: "Camera",
+		PlannedStart: day(1), PlannedEnd: day(3),
+		CheckedOutAt: time.Date(2030, 3, 1, 10, 0, 0, 0, time.UTC), CheckedOutBy: 7,
+		CheckOutNote: "scratch on the lens", CheckOutPhotos: []string{"photo-1", "photo-2"},
+	}
+	require.NoError(t, db.CreateHandover(ctx, out))
+	assert.NotZero(t, out.ID)
+
+	// Повторная выдача той же брони не создает вторую запись
+	err = db.CreateHandover(ctx, &models.Handover{BookingID: 100, UserID: 10, ItemID: 1, PlannedStart: day(1), PlannedEnd: day(3)})
+	assert.ErrorIs(t, err, models.ErrAlreadyCheckedOut)
+
+	found, err := db.FindHandover(ctx, 10, 1, day(3))
+	require.NoError(t, err)
+	require.NotNil(t, found)
+	assert.Equal(t, out.ID, found.ID)
+	assert.Equal(t, day(1), found.PlannedStart)
+	assert.Equal(t, day(3), found.PlannedEnd)
+	assert.Equal(t, []string{"photo-1", "photo-2"}, found.CheckOutPhotos)
+	assert.False(t, found.IsReturned())
+
+	missing, err := db.FindHandover(ctx, 10, 1, day(4))
+	require.NoError(t, err)
+	assert.Nil(t, missing)
+
+	open, err := db.GetOpenHandovers(ctx)
+	require.NoError(t, err)
+	require.Len(t, open, 1)
+
+	notified := time.Date(2030, 3, 4, 13, 0, 0, 0, time.UTC)
+	require.NoError(t, db.SetHandoverOverdueNotified(ctx, out.ID, notified))
+
+	found.CheckedInAt = sql.NullTime{Time: time.Date(2030, 3, 5, 9, 0, 0, 0, time.UTC), Valid: true}
+	found.CheckedInBy = 8
+	found.CheckInNote = "ok"
+	require.NoError(t, db.CheckInHandover(ctx, found))
+	assert.ErrorIs(t, db.CheckInHandover(ctx, found), models.ErrNotCheckedOut)
+
+	open, err = db.GetOpenHandovers(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, open)
+
+	period, err := db.GetHandoversByPeriod(ctx, day(3), day(10))
+	require.NoError(t, err)
+	require.Len(t, period, 1)
+	assert.True(t, period[0].IsReturned())
+	assert.Equal(t, int64(8), period[0].CheckedInBy)
+	assert.Equal(t, "ok", period[0].CheckInNote)
+	assert.Empty(t, period[0].CheckInPhotos)
+	assert.True(t, notified.Equal(period[0].OverdueNotifiedAt.Time))
+
+	period, err = db.GetHandoversByPeriod(ctx, day(4), day(10))
+	require.NoError(t, err)
+	assert.Empty(t, period)
+}
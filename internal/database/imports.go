@@ -0,0 +1,120 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"bronivik/internal/models"
+)
+
+// ImportItems creates items in one transaction. With dryRun the transaction is rolled back.
+func (db *DB) ImportItems(ctx context.Context, items []*models.Item, dryRun bool) error {
+	err := db.importTx(ctx, len(items), dryRun, func(tx *sql.Tx, i int) error {
+		return insertItem(ctx, tx, items[i])
+	})
+	if err != nil || dryRun {
+		return err
+	}
+	return db.LoadItems(ctx)
+}
+
+// ImportUsers creates users in one transaction. Users whose Telegram ID is already
+// registered are left as they are. With dryRun the transaction is rolled back.
+func (db *DB) ImportUsers(ctx context.Context, users []*models.User, dryRun bool) error {
+	query := `INSERT INTO users (
+				telegram_id, username, first_name, last_name, phone,
+				is_manager, is_blacklisted, language_code,
+				last_activity, created_at, updated_at
+			) VALUES (?, ?, ?, ?, ?, 0, 0, ?, ?, ?, ?)
+              ON CONFLICT(telegram_id) DO NOTHING`
+	now := time.Now()
+	return db.importTx(ctx, len(users), dryRun, func(tx *sql.Tx, i int) error {
+		u := users[i]
+		createdAt := u.CreatedAt
+		if createdAt.IsZero() {
+			createdAt = now
+		}
+		lastActivity := u.LastActivity
+		if lastActivity.IsZero() {
+			lastActivity = createdAt
+		}
+		_, err := tx.ExecContext(ctx, query,
+			u.TelegramID, u.Username, u.FirstName, u.LastName, u.Phone,
+			u.LanguageCode, lastActivity, createdAt, now,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to import user %d: %w", u.TelegramID, err)
+		}
+		return nil
+	})
+}
+
+// ImportBookings creates bookings in one transaction. Bookings that take a unit are checked
+// against item capacity like in CreateBookingWithLock, including the bookings imported
+// before them. A booking that does not fit is skipped and its ErrNotAvailable is returned
+// at its index in the slice. The original creation time, price and deposit are kept.
+// With dryRun the transaction is rolled back.
+func (db *DB) ImportBookings(ctx context.Context, bookings []*models.Booking, dryRun bool) ([]error, error) {
+	query := `INSERT INTO bookings (
+				user_id, user_name, user_nickname, phone, item_id, item_name,
+				date, status, comment, created_at, updated_at, version, price, deposit
+			) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, 1, ?, ?)`
+	now := time.Now()
+	rejected := make([]error, len(bookings))
+	err := db.importTx(ctx, len(bookings), dryRun, func(tx *sql.Tx, i int) error {
+		b := bookings[i]
+		if b.Status != models.StatusCanceled {
+			err := db.checkCapacity(ctx, tx, b.ItemID, b.Date)
+			if errors.Is(err, ErrNotAvailable) {
+				rejected[i] = err
+				return nil
+			}
+			if err != nil {
+				return err
+			}
+		}
+
+		createdAt := b.CreatedAt
+		if createdAt.IsZero() {
+			createdAt = now
+		}
+		result, err := tx.ExecContext(ctx, query,
+			b.UserID, b.UserName, b.UserNickname, b.Phone, b.ItemID, b.ItemName,
+			b.Date.Format("2006-01-02"), b.Status, b.Comment, createdAt, now, b.Price, b.Deposit,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to import booking: %w", err)
+		}
+		if b.ID, err = result.LastInsertId(); err != nil {
+			return fmt.Errorf("failed to get last insert id: %w", err)
+		}
+		b.CreatedAt, b.UpdatedAt, b.Version = createdAt, now, 1
+		return nil
+	})
+	return rejected, err
+}
+
+// importTx calls fn for rows 0 to n-1 in one transaction. An error from fn rolls back
+// the transaction; with dryRun it is rolled back in any case.
+func (db *DB) importTx(ctx context.Context, n int, dryRun bool, fn func(tx *sql.Tx, i int) error) error {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	for i := 0; i < n; i++ {
+		if err := fn(tx, i); err != nil {
+			return err
+		}
+	}
+	if dryRun {
+		return nil
+	}
+	return tx.Commit()
+}
@@ -0,0 +1,101 @@
+package database
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"bronivik/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestImportItemsAndUsers(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	ctx := context.Background()
+
+	items := []*models.Item{{Name: "Camera", TotalQuantity: 2, IsActive: true}, {Name: "Light", TotalQuantity: 1}}
+	require.NoError(t, db.ImportItems(ctx, items, true))
+	_, err := db.GetItemByName(ctx, "Camera")
+	assert.Error(t, err, "dry run saves nothing")
+
+	require.NoError(t, db.ImportItems(ctx, items, false))
+	camera, err := db.GetItemByName(ctx, "Camera")
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), camera.TotalQuantity)
+	assert.NotZero(t, items[1].ID)
+
+	require.NoError(t, db.CreateOrUpdateUser(ctx, &models.User{TelegramID: 1, FirstName: "Old"}))
+	created := time.Date(2029, 5, 1, 10, 0, 0, 0, time.UTC)
+	users := []*models.User{
+		{TelegramID: 1, FirstName: "New"},
+		{TelegramID: 2, FirstName: "Анна", Phone: "79991234567", CreatedAt: created},
+	}
+	require.NoError(t, db.ImportUsers(ctx, users, false))
+
+	existing, err := db.GetUserByTelegramID(ctx, 1)
+	require.NoError(t, err)
+	assert.Equal(t, "Old", existing.FirstName, "registered users are kept")
+	imported, err := db.GetUserByTelegramID(ctx, 2)
+	require.NoError(t, err)
+	assert.Equal(t, "79991234567", imported.Phone)
+	assert.True(t, created.Equal(imported.CreatedAt))
+}
+
+func TestImportBookings(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	ctx := context.Background()
+	day := time.Date(2030, 4, 10, 0, 0, 0, 0, time.UTC)
+
+	item := &models.Item{Name: "Camera", TotalQuantity: 2, IsActive: true}
+	require.NoError(t, db.CreateItem(ctx, item))
+	require.NoError(t, db.CreateBooking(ctx, &models.Booking{
+		ItemID: item.ID, ItemName: item.Name, Date: day, UserName: "Existing", Status: models.StatusConfirmed,
+	}))
+
+	created := time.Date(2030, 3, 1, 9, 0, 0, 0, time.UTC)
+	booking := func(name, status string) *models.Booking {
+		return &models.Booking{
+			ItemID: item.ID, ItemName: item.Name, Date: day, UserName: name, Status: status,
+			Price: 1000, Deposit: 3000, CreatedAt: created,
+		}
+	}
+
+	t.Run("DryRun", func(t *testing.T) {
+		bookings := []*models.Booking{booking("A", models.StatusCompleted), booking("B", models.StatusConfirmed)}
+		rejected, err := db.ImportBookings(ctx, bookings, true)
+		require.NoError(t, err)
+		assert.NoError(t, rejected[0])
+		assert.ErrorIs(t, rejected[1], ErrNotAvailable, "the first imported booking takes the last unit")
+
+		count, err := db.GetBookedCount(ctx, item.ID, day)
+		require.NoError(t, err)
+		assert.Equal(t, 1, count)
+	})
+
+	t.Run("Commit", func(t *testing.T) {
+		bookings := []*models.Booking{
+			booking("A", models.StatusCompleted),
+			booking("B", models.StatusCanceled),
+			booking("C", models.StatusConfirmed),
+		}
+		rejected, err := db.ImportBookings(ctx, bookings, false)
+		require.NoError(t, err)
+		assert.NoError(t, rejected[0])
+		assert.NoError(t, rejected[1], "canceled bookings do not take a unit")
+		assert.ErrorIs(t, rejected[2], ErrNotAvailable)
+
+		saved, err := db.GetBooking(ctx, bookings[0].ID)
+		require.NoError(t, err)
+		assert.Equal(t, models.StatusCompleted, saved.Status)
+		assert.Equal(t, int64(1000), saved.Price)
+		assert.Equal(t, int64(3000), saved.Deposit)
+		assert.True(t, created.Equal(saved.CreatedAt))
+		assert.Zero(t, bookings[2].ID)
+	})
+}
@@ -3,6 +3,7 @@ package database
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
 	"fmt"
 	"sort"
 	"time"
@@ -10,8 +11,12 @@ import (
 	"bronivik/internal/models"
 )
 
+const itemColumns = `id, name, description, total_quantity, sort_order, is_active,
+	category, photos, specs, price_per_day, weekend_price, deposit, long_rental_days, long_rental_discount,
+	created_at, updated_at`
+
 func (db *DB) LoadItems(ctx context.Context) error {
-	query := `SELECT id, name, description, total_quantity, sort_order, is_active, created_at, updated_at FROM items`
+	query := `SELECT ` + itemColumns + ` FROM items`
 	rows, err := db.QueryContext(ctx, query)
 	if err != nil {
 		return fmt.Errorf("failed to load items: %w", err)
@@ -23,14 +28,11 @@ func (db *DB) LoadItems(ctx context.Context) error {
 	db.itemsCache = make(map[int64]models.Item)
 
 	for rows.Next() {
-		var item models.Item
-		if err := rows.Scan(
-			&item.ID, &item.Name, &item.Description, &item.TotalQuantity,
-			&item.SortOrder, &item.IsActive, &item.CreatedAt, &item.UpdatedAt,
-		); err != nil {
+		item, err := scanItem(rows)
+		if err != nil {
 			return fmt.Errorf("failed to scan item: %w", err)
 		}
-		db.itemsCache[item.ID] = item
+		db.itemsCache[item.ID] = *item
 	}
 	db.cacheTime = time.Now()
 	return nil
@@ -91,15 +93,42 @@ func (db *DB) itemByNameFromCache(name string) (*models.Item, bool) {
 }
 
 func (db *DB) CreateItem(ctx context.Context, item *models.Item) error {
-	query := `INSERT INTO items (name, description, total_quantity, sort_order, is_active, created_at, updated_at)
-              VALUES (?, ?, ?, ?, ?, ?, ?)`
+	if err := insertItem(ctx, db, item); err != nil {
+		return err
+	}
+
+	// Update cache
+	db.mu.Lock()
+	db.itemsCache[item.ID] = *item
+	db.mu.Unlock()
+
+	return nil
+}
+
+func insertItem(ctx context.Context, ex execer, item *models.Item) error {
+	specs, err := marshalSpecs(item.Specs)
+	if err != nil {
+		return err
+	}
+	query := `INSERT INTO items (name, description, total_quantity, sort_order, is_active,
+              category, photos, specs, price_per_day, weekend_price, deposit, long_rental_days, long_rental_discount,
+              created_at, updated_at)
+              VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
 	now := time.Now()
-	result, err := db.ExecContext(ctx, query,
+	result, err := ex.ExecContext(ctx, query,
 		item.Name,
 		item.Description,
 		item.TotalQuantity,
 		item.SortOrder,
 		item.IsActive,
+		item.Category,
+		joinPhotos(item.PhotoFileIDs),
+		specs,
+		item.PricePerDay,
+		item.WeekendPrice,
+		item.Deposit,
+		item.LongRentalDays,
+		item.LongRentalDiscount,
 		now,
 		now,
 	)
@@ -114,12 +143,6 @@ func (db *DB) CreateItem(ctx context.Context, item *models.Item) error {
 	item.ID = id
 	item.CreatedAt = now
 	item.UpdatedAt = now
-
-	// Update cache
-	db.mu.Lock()
-	db.itemsCache[id] = *item
-	db.mu.Unlock()
-
 	return nil
 }
 
@@ -132,22 +155,18 @@ func (db *DB) GetItemByID(ctx context.Context, id int64) (*models.Item, error) {
 		return &item, nil
 	}
 
-	var dbItem models.Item
-	query := `SELECT id, name, description, total_quantity, sort_order, is_active, created_at, updated_at FROM items WHERE id = ?`
-	err := db.QueryRowContext(ctx, query, id).Scan(
-		&dbItem.ID, &dbItem.Name, &dbItem.Description, &dbItem.TotalQuantity,
-		&dbItem.SortOrder, &dbItem.IsActive, &dbItem.CreatedAt, &dbItem.UpdatedAt,
-	)
+	query := `SELECT ` + itemColumns + ` FROM items WHERE id = ?`
+	dbItem, err := scanItem(db.QueryRowContext(ctx, query, id))
 	if err != nil {
 		return nil, fmt.Errorf("failed to get item by id: %w", err)
 	}
 
 	// Update cache
 	db.mu.Lock()
-	db.itemsCache[dbItem.ID] = dbItem
+	db.itemsCache[dbItem.ID] = *dbItem
 	db.mu.Unlock()
 
-	return &dbItem, nil
+	return dbItem, nil
 }
 
 func (db *DB) GetItemByName(ctx context.Context, name string) (*models.Item, error) {
@@ -157,21 +176,18 @@ func (db *DB) GetItemByName(ctx context.Context, name string) (*models.Item, err
 		return item, nil
 	}
 
-	var item models.Item
-	query := `SELECT id, name, description, total_quantity, sort_order, is_active, created_at, updated_at FROM items WHERE name = ?`
-	err := db.QueryRowContext(ctx, query, name).Scan(
-		&item.ID, &item.Name, &item.Description, &item.TotalQuantity, &item.SortOrder, &item.IsActive, &item.CreatedAt, &item.UpdatedAt,
-	)
+	query := `SELECT ` + itemColumns + ` FROM items WHERE name = ?`
+	item, err := scanItem(db.QueryRowContext(ctx, query, name))
 	if err != nil {
 		return nil, fmt.Errorf("failed to get item by name: %w", err)
 	}
 
 	// Update cache
 	db.mu.Lock()
-	db.itemsCache[item.ID] = item
+	db.itemsCache[item.ID] = *item
 	db.mu.Unlock()
 
-	return &item, nil
+	return item, nil
 }
 
 func (db *DB) GetItemAvailabilityByName(ctx context.Context, itemName string, date time.Time) (*models.AvailabilityInfo, error) {
@@ -185,12 +201,19 @@ func (db *DB) GetItemAvailabilityByName(ctx context.Context, itemName string, da
 		return nil, err
 	}
 
+	maintenance, err := db.GetMaintenanceUnits(ctx, item.ID, date)
+	if err != nil {
+		return nil, err
+	}
+	total := item.EffectiveQuantity(maintenance)
+
 	return &models.AvailabilityInfo{
 		ItemName:    item.Name,
 		Date:        date,
-		Available:   bookedCount < int(item.TotalQuantity),
+		Available:   int64(bookedCount) < total,
 		BookedCount: int64(bookedCount),
-		Total:       item.TotalQuantity,
+		Total:       total,
+		Maintenance: maintenance,
 	}, nil
 }
 
@@ -219,9 +242,17 @@ func (db *DB) GetActiveItems(ctx context.Context) ([]*models.Item, error) {
 }
 
 func (db *DB) UpdateItem(ctx context.Context, item *models.Item) error {
-	query := `UPDATE items SET name = ?, description = ?, total_quantity = ?, sort_order = ?, is_active = ?, updated_at = ? WHERE id = ?`
+	specs, err := marshalSpecs(item.Specs)
+	if err != nil {
+		return err
+	}
+	query := `UPDATE items SET name = ?, description = ?, total_quantity = ?, sort_order = ?, is_active = ?,
+              category = ?, photos = ?, specs = ?, price_per_day = ?, weekend_price = ?, deposit = ?,
+              long_rental_days = ?, long_rental_discount = ?, updated_at = ? WHERE id = ?`
 	now := time.Now()
-	_, err := db.ExecContext(ctx, query, item.Name, item.Description, item.TotalQuantity, item.SortOrder, item.IsActive, now, item.ID)
+	_, err = db.ExecContext(ctx, query, item.Name, item.Description, item.TotalQuantity, item.SortOrder, item.IsActive,
+		item.Category, joinPhotos(item.PhotoFileIDs), specs, item.PricePerDay, item.WeekendPrice, item.Deposit,
+		item.LongRentalDays, item.LongRentalDiscount, now, item.ID)
 	if err != nil {
 		return fmt.Errorf("failed to update item: %w", err)
 	}
@@ -278,3 +309,36 @@ func (db *DB) GetItems() []*models.Item {
 	}
 	return items
 }
+
+func scanItem(row rowScanner) (*models.Item, error) {
+	var item models.Item
+	var description sql.NullString
+	var photos, specs string
+	if err := row.Scan(
+		&item.ID, &item.Name, &description, &item.TotalQuantity, &item.SortOrder, &item.IsActive,
+		&item.Category, &photos, &specs, &item.PricePerDay, &item.WeekendPrice, &item.Deposit,
+		&item.LongRentalDays, &item.LongRentalDiscount, &item.CreatedAt, &item.UpdatedAt,
+	); err != nil {
+		return nil, err
+	}
+	item.Description = description.String
+	item.PhotoFileIDs = splitPhotos(photos)
+	if specs != "" {
+		if err := json.Unmarshal([]byte(specs), &item.Specs); err != nil {
+			return nil, fmt.Errorf("failed to decode specs of item %d: %w", item.ID, err)
+		}
+	}
+	return &item, nil
+}
+
+// marshalSpecs encodes item specs as a JSON array; no specs are stored as an empty string.
+func marshalSpecs(specs []models.ItemSpec) (string, error) {
+	if len(specs) == 0 {
+		return "", nil
+	}
+	data, err := json.Marshal(specs)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode item specs: %w", err)
+	}
+	return string(data), nil
+}
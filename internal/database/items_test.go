@@ -52,6 +52,52 @@ func TestItemCRUD(t *testing.T) {
 	assert.Len(t, activeItems, 0)
 }
 
+func TestItemCatalogFields(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	ctx := context.Background()
+
+	item := &models.Item{
+		Name:               "Laser",
+		TotalQuantity:      2,
+		IsActive:           true,
+		Category:           "Лазеры/Диодные",
+		PhotoFileIDs:       []string{"photo-1", "photo-2"},
+		Specs:              []models.ItemSpec{{Name: "Мощность", Value: "1200 Вт"}, {Name: "Вес", Value: "40 кг"}},
+		PricePerDay:        3500,
+		WeekendPrice:       4000,
+		Deposit:            20000,
+		LongRentalDays:     7,
+		LongRentalDiscount: 10,
+	}
+	require.NoError(t, db.CreateItem(ctx, item))
+
+	// Читаем из базы, минуя кэш
+	require.NoError(t, db.LoadItems(ctx))
+	found, err := db.GetItemByID(ctx, item.ID)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Лазеры", "Диодные"}, found.CategoryPath())
+	assert.Equal(t, item.PhotoFileIDs, found.PhotoFileIDs)
+	assert.Equal(t, item.Specs, found.Specs)
+	assert.Equal(t, int64(3500), found.PricePerDay)
+	assert.Equal(t, int64(4000), found.WeekendPrice)
+	assert.Equal(t, int64(20000), found.Deposit)
+	assert.Equal(t, int64(7), found.LongRentalDays)
+	assert.Equal(t, int64(10), found.LongRentalDiscount)
+
+	found.Category = ""
+	found.PhotoFileIDs = nil
+	found.Specs = nil
+	require.NoError(t, db.UpdateItem(ctx, found))
+	require.NoError(t, db.LoadItems(ctx))
+	cleared, err := db.GetItemByName(ctx, "Laser")
+	require.NoError(t, err)
+	assert.Empty(t, cleared.Category)
+	assert.Empty(t, cleared.PhotoFileIDs)
+	assert.Empty(t, cleared.Specs)
+}
+
 func TestItemReordering(t *testing.T) {
 	db := setupTestDB(t)
 	defer db.Close()
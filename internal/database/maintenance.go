@@ -0,0 +1,206 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"bronivik/internal/models"
+)
+
+const maintenanceColumns = `id, item_id, units, start_date, end_date, reason, created_by, created_at, source`
+
+// rowQuerier is implemented by both *sql.DB and *sql.Tx.
+type rowQuerier interface {
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+// execer is implemented by both *sql.DB and *sql.Tx.
+type execer interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+}
+
+// CreateMaintenance takes units of an item out of service for the window's days.
+func (db *DB) CreateMaintenance(ctx context.Context, w *models.MaintenanceWindow) error {
+	return db.insertMaintenance(ctx, db, w)
+}
+
+func (db *DB) insertMaintenance(ctx context.Context, ex execer, w *models.MaintenanceWindow) error {
+	now := time.Now()
+	res, err := ex.ExecContext(ctx, `INSERT INTO item_maintenance (
+				item_id, units, start_date, end_date, reason, created_by, created_at, source
+			) VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
+		w.ItemID, w.Units, w.StartDate.Format("2006-01-02"), w.EndDate.Format("2006-01-02"),
+		w.Reason, w.CreatedBy, now, w.Source)
+	if err != nil {
+		return fmt.Errorf("failed to create maintenance: %w", err)
+	}
+
+	id, err := res.LastInsertId()
+	if err != nil {
+		return fmt.Errorf("failed to get last insert id: %w", err)
+	}
+	w.ID = id
+	w.CreatedAt = now
+	w.ItemName = db.cachedItemName(w.ItemID)
+	return nil
+}
+
+// GetMaintenance returns a maintenance window by ID or models.ErrMaintenanceNotFound.
+func (db *DB) GetMaintenance(ctx context.Context, id int64) (*models.MaintenanceWindow, error) {
+	row := db.QueryRowContext(ctx, `SELECT `+maintenanceColumns+` FROM item_maintenance WHERE id = ?`, id)
+	w, err := db.scanMaintenance(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, models.ErrMaintenanceNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get maintenance: %w", err)
+	}
+	return w, nil
+}
+
+// DeleteMaintenance returns the units of a window back into service.
+func (db *DB) DeleteMaintenance(ctx context.Context, id int64) error {
+	res, err := db.ExecContext(ctx, `DELETE FROM item_maintenance WHERE id = ?`, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete maintenance: %w", err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return models.ErrMaintenanceNotFound
+	}
+	return nil
+}
+
+// GetMaintenanceWindows returns windows that overlap the period. itemID 0 means all items.
+func (db *DB) GetMaintenanceWindows(ctx context.Context, itemID int64, start, end time.Time) ([]*models.MaintenanceWindow, error) {
+	query := `SELECT ` + maintenanceColumns + ` FROM item_maintenance WHERE start_date <= ? AND end_date >= ?`
+	args := []interface{}{end.Format("2006-01-02"), start.Format("2006-01-02")}
+	if itemID != 0 {
+		query += ` AND item_id = ?`
+		args = append(args, itemID)
+	}
+	query += ` ORDER BY start_date ASC, id ASC`
+
+	rows, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get maintenance windows: %w", err)
+	}
+	defer rows.Close()
+
+	var windows []*models.MaintenanceWindow
+	for rows.Next() {
+		w, err := db.scanMaintenance(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan maintenance: %w", err)
+		}
+		windows = append(windows, w)
+	}
+	return windows, rows.Err()
+}
+
+// GetExternalHolds returns the windows last imported from the external calendar source.
+func (db *DB) GetExternalHolds(ctx context.Context, source string) ([]*models.MaintenanceWindow, error) {
+	rows, err := db.QueryContext(ctx, `SELECT `+maintenanceColumns+` FROM item_maintenance
+              WHERE source = ? ORDER BY start_date ASC, id ASC`, source)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get external holds: %w", err)
+	}
+	defer rows.Close()
+
+	var windows []*models.MaintenanceWindow
+	for rows.Next() {
+		w, err := db.scanMaintenance(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan external hold: %w", err)
+		}
+		windows = append(windows, w)
+	}
+	return windows, rows.Err()
+}
+
+// ReplaceExternalHolds atomically replaces the windows imported from source with windows.
+func (db *DB) ReplaceExternalHolds(ctx context.Context, source string, windows []*models.MaintenanceWindow) error {
+	if source == "" {
+		return fmt.Errorf("external hold source is required")
+	}
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM item_maintenance WHERE source = ?`, source); err != nil {
+		return fmt.Errorf("failed to delete external holds: %w", err)
+	}
+	for _, w := range windows {
+		w.Source = source
+		if err := db.insertMaintenance(ctx, tx, w); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// GetMaintenanceUnits returns how many units of the item are out of service on date.
+func (db *DB) GetMaintenanceUnits(ctx context.Context, itemID int64, date time.Time) (int64, error) {
+	return maintenanceUnits(ctx, db, itemID, date)
+}
+
+// effectiveQuantity returns the bookable units of a cached item on date.
+func (db *DB) effectiveQuantity(ctx context.Context, q rowQuerier, itemID int64, date time.Time) (int64, error) {
+	db.mu.RLock()
+	item, ok := db.itemsCache[itemID]
+	db.mu.RUnlock()
+	if !ok {
+		return 0, fmt.Errorf("item not found in cache: %d", itemID)
+	}
+
+	units, err := maintenanceUnits(ctx, q, itemID, date)
+	if err != nil {
+		return 0, err
+	}
+	return item.EffectiveQuantity(units), nil
+}
+
+func maintenanceUnits(ctx context.Context, q rowQuerier, itemID int64, date time.Time) (int64, error) {
+	day := date.Format("2006-01-02")
+	var units int64
+	err := q.QueryRowContext(ctx, `SELECT COALESCE(SUM(units), 0) FROM item_maintenance
+              WHERE item_id = ? AND start_date <= ? AND end_date >= ?`, itemID, day, day).Scan(&units)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get maintenance units: %w", err)
+	}
+	return units, nil
+}
+
+func (db *DB) scanMaintenance(row rowScanner) (*models.MaintenanceWindow, error) {
+	var w models.MaintenanceWindow
+	var start, end string
+	if err := row.Scan(&w.ID, &w.ItemID, &w.Units, &start, &end, &w.Reason, &w.CreatedBy, &w.CreatedAt, &w.Source); err != nil {
+		return nil, err
+	}
+
+	var err error
+	if w.StartDate, err = time.Parse("2006-01-02", start); err != nil {
+		return nil, fmt.Errorf("failed to parse maintenance start %s: %w", start, err)
+	}
+	if w.EndDate, err = time.Parse("2006-01-02", end); err != nil {
+		return nil, fmt.Errorf("failed to parse maintenance end %s: %w", end, err)
+	}
+	w.ItemName = db.cachedItemName(w.ItemID)
+	return &w, nil
+}
+
+func (db *DB) cachedItemName(itemID int64) string {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+	if item, ok := db.itemsCache[itemID]; ok {
+		return item.Name
+	}
+	return ""
+}
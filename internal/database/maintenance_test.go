@@ -0,0 +1,129 @@
+package database
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"bronivik/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMaintenance(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	ctx := context.Background()
+	day := func(d int) time.Time { return time.Date(2030, 4, d, 0, 0, 0, 0, time.UTC) }
+
+	item := &models.Item{Name: "Camera", TotalQuantity: 3}
+	require.NoError(t, db.CreateItem(ctx, item))
+
+	repair := &models.MaintenanceWindow{ItemID: item.ID, Units: 1, StartDate: day(2), EndDate: day(4), Reason: "repair", CreatedBy: 7}
+	require.NoError(t, db.CreateMaintenance(ctx, repair))
+	assert.NotZero(t, repair.ID)
+	assert.Equal(t, "Camera", repair.ItemName)
+	require.NoError(t, db.CreateMaintenance(ctx, &models.MaintenanceWindow{ItemID: item.ID, Units: 2, StartDate: day(4), EndDate: day(4)}))
+
+	units, err := db.GetMaintenanceUnits(ctx, item.ID, day(4))
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), units)
+
+	windows, err := db.GetMaintenanceWindows(ctx, 0, day(1), day(2))
+	require.NoError(t, err)
+	require.Len(t, windows, 1)
+	assert.Equal(t, day(2), windows[0].StartDate)
+	assert.Equal(t, day(4), windows[0].EndDate)
+	assert.Equal(t, "repair", windows[0].Reason)
+
+	// Вместимость уменьшается только в дни обслуживания
+	for i := 0; i < 2; i++ {
+		require.NoError(t, db.CreateBookingWithLock(ctx, &models.Booking{
+			UserID: int64(i + 1), ItemID: item.ID, ItemName: item.Name, Date: day(2), Status: models.StatusConfirmed,
+		}))
+	}
+	err = db.CreateBookingWithLock(ctx, &models.Booking{UserID: 3, ItemID: item.ID, Date: day(2), Status: models.StatusPending})
+	assert.ErrorIs(t, err, ErrNotAvailable)
+
+	available, err := db.CheckAvailability(ctx, item.ID, day(1))
+	require.NoError(t, err)
+	assert.True(t, available)
+	available, err = db.CheckAvailability(ctx, item.ID, day(4))
+	require.NoError(t, err)
+	assert.False(t, available)
+
+	period, err := db.GetAvailabilityForPeriod(ctx, item.ID, day(1), 4)
+	require.NoError(t, err)
+	require.Len(t, period, 4)
+	assert.Equal(t, int64(3), period[0].Available)
+	assert.Equal(t, int64(0), period[1].Available)
+	assert.Equal(t, int64(1), period[1].Maintenance)
+	assert.Equal(t, int64(2), period[2].Available)
+	assert.Equal(t, int64(0), period[3].Available)
+	assert.Equal(t, int64(3), period[3].Maintenance)
+
+	info, err := db.GetItemAvailabilityByName(ctx, "Camera", day(3))
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), info.Total)
+	assert.Equal(t, int64(1), info.Maintenance)
+	assert.True(t, info.Available)
+
+	got, err := db.GetMaintenance(ctx, repair.ID)
+	require.NoError(t, err)
+	assert.Equal(t, int64(7), got.CreatedBy)
+
+	require.NoError(t, db.DeleteMaintenance(ctx, repair.ID))
+	assert.ErrorIs(t, db.DeleteMaintenance(ctx, repair.ID), models.ErrMaintenanceNotFound)
+	_, err = db.GetMaintenance(ctx, repair.ID)
+	assert.ErrorIs(t, err, models.ErrMaintenanceNotFound)
+
+	available, err = db.CheckAvailability(ctx, item.ID, day(3))
+	require.NoError(t, err)
+	assert.True(t, available)
+}
+
+func TestExternalHolds(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	ctx := context.Background()
+	day := func(d int) time.Time { return time.Date(2030, 4, d, 0, 0, 0, 0, time.UTC) }
+
+	item := &models.Item{Name: "Camera", TotalQuantity: 1}
+	require.NoError(t, db.CreateItem(ctx, item))
+	require.NoError(t, db.CreateMaintenance(ctx, &models.MaintenanceWindow{ItemID: item.ID, Units: 1, StartDate: day(10), EndDate: day(10)}))
+
+	require.NoError(t, db.ReplaceExternalHolds(ctx, "partner", []*models.MaintenanceWindow{
+		{ItemID: item.ID, Units: 1, StartDate: day(2), EndDate: day(3), Reason: "Wedding"},
+	}))
+	available, err := db.CheckAvailability(ctx, item.ID, day(3))
+	require.NoError(t, err)
+	assert.False(t, available, "imported events block capacity")
+
+	// Повторный импорт заменяет прежние события источника, ручное обслуживание не трогается
+	require.NoError(t, db.ReplaceExternalHolds(ctx, "partner", []*models.MaintenanceWindow{
+		{ItemID: item.ID, Units: 1, StartDate: day(5), EndDate: day(5), Reason: "Concert"},
+	}))
+	holds, err := db.GetExternalHolds(ctx, "partner")
+	require.NoError(t, err)
+	require.Len(t, holds, 1)
+	assert.Equal(t, "partner", holds[0].Source)
+	assert.True(t, holds[0].IsExternalHold())
+	assert.Equal(t, "Concert", holds[0].Reason)
+
+	available, err = db.CheckAvailability(ctx, item.ID, day(3))
+	require.NoError(t, err)
+	assert.True(t, available)
+
+	windows, err := db.GetMaintenanceWindows(ctx, item.ID, day(1), day(30))
+	require.NoError(t, err)
+	assert.Len(t, windows, 2)
+
+	require.NoError(t, db.ReplaceExternalHolds(ctx, "partner", nil))
+	holds, err = db.GetExternalHolds(ctx, "partner")
+	require.NoError(t, err)
+	assert.Empty(t, holds)
+	assert.Error(t, db.ReplaceExternalHolds(ctx, "", nil))
+}
@@ -0,0 +1,191 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"bronivik/internal/models"
+)
+
+// SetUserConsent records that the user has given consent to personal data processing.
+// Users that never talked to the bot get a placeholder record.
+func (db *DB) SetUserConsent(ctx context.Context, telegramID int64) error {
+	now := time.Now()
+	query := `INSERT INTO users (
+				telegram_id, username, first_name, last_name, phone, language_code,
+				consent_given, consent_given_at, consent_revoked, consent_revoked_at,
+				last_activity, created_at, updated_at
+			) VALUES (?, '', '', '', '', '', 1, ?, 0, NULL, ?, ?, ?)
+              ON CONFLICT(telegram_id) DO UPDATE SET
+                consent_given = 1,
+                consent_given_at = excluded.consent_given_at,
+                consent_revoked = 0,
+                consent_revoked_at = NULL,
+                updated_at = excluded.updated_at`
+	if _, err := db.ExecContext(ctx, query, telegramID, now, now, now, now); err != nil {
+		return fmt.Errorf("failed to save user consent: %w", err)
+	}
+	return nil
+}
+
+// GetAllUserBookings returns every booking of the user regardless of date.
+func (db *DB) GetAllUserBookings(ctx context.Context, userID int64) ([]*models.Booking, error) {
+	query := `SELECT id, user_id, user_name, user_nickname, phone, item_id,
+	                 item_name, date(date), status, comment, created_at,
+	                 updated_at, version, unit_id, price, deposit
+              FROM bookings WHERE user_id = ? ORDER BY date DESC, id DESC`
+	rows, err := db.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user bookings: %w", err)
+	}
+	defer rows.Close()
+
+	var bookings []*models.Booking
+	for rows.Next() {
+		b := &models.Booking{}
+		var dateStr string
+		err := rows.Scan(
+			&b.ID, &b.UserID, &b.UserName, &b.UserNickname, &b.Phone,
+			&b.ItemID, &b.ItemName, &dateStr, &b.Status, &b.Comment,
+			&b.CreatedAt, &b.UpdatedAt, &b.Version, &b.UnitID, &b.Price, &b.Deposit,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan booking: %w", err)
+		}
+		b.Date, err = time.Parse("2006-01-02", dateStr)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse booking date %s: %w", dateStr, err)
+		}
+		bookings = append(bookings, b)
+	}
+	return bookings, rows.Err()
+}
+
+// AnonymizeUser removes personal data of the user: bookings are unlinked from the
+// user and stripped of name, nickname, phone and comment, handovers lose the name and
+// the check-out and check-in notes, and the user profile is cleared with consent marked
+// as revoked. Calendar feeds of the user are revoked too. Copies of the bookings in
+// sync_queue payloads and comments and notes in audit_log snapshots are scrubbed
+// in the same transaction.
+// The telegram_id row is kept so that blacklist and role records stay consistent.
+// Returns IDs of affected bookings.
+func (db *DB) AnonymizeUser(ctx context.Context, telegramID int64) ([]int64, error) {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	rows, err := tx.QueryContext(ctx, `SELECT id FROM bookings WHERE user_id = ? ORDER BY id`, telegramID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user bookings: %w", err)
+	}
+	var ids []int64
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to scan booking id: %w", err)
+		}
+		ids = append(ids, id)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	_, err = tx.ExecContext(ctx, `UPDATE bookings SET user_id = 0, user_name = ?, user_nickname = '', phone = '',
+                comment = '', updated_at = ?, version = version + 1
+              WHERE user_id = ?`, models.AnonymizedUserName, now, telegramID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to anonymize bookings: %w", err)
+	}
+
+	_, err = tx.ExecContext(ctx, `UPDATE booking_handovers SET user_id = 0, user_name = ?,
+                check_out_note = '', check_in_note = ''
+              WHERE user_id = ?`, models.AnonymizedUserName, telegramID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to anonymize handovers: %w", err)
+	}
+
+	_, err = tx.ExecContext(ctx, `UPDATE users SET username = '', first_name = '', last_name = '', phone = '',
+                language_code = '', consent_given = 0, consent_given_at = NULL,
+                consent_revoked = 1, consent_revoked_at = ?, updated_at = ?
+              WHERE telegram_id = ?`, now, now, telegramID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to anonymize user: %w", err)
+	}
+
+	_, err = tx.ExecContext(ctx, `UPDATE calendar_feeds SET revoked_at = ?
+              WHERE scope = ? AND subject_id = ? AND revoked_at IS NULL`, now, models.CalendarScopeUser, telegramID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to revoke calendar feeds: %w", err)
+	}
+
+	if err := scrubSyncQueue(ctx, tx, ids); err != nil {
+		return nil, err
+	}
+	if err := scrubAuditComments(ctx, tx, ids); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
+
+// scrubSyncQueue strips personal data from booking copies stored in sync task payloads.
+// Pending tasks stay valid and push the anonymized booking to the sheet.
+func scrubSyncQueue(ctx context.Context, tx *sql.Tx, bookingIDs []int64) error {
+	if len(bookingIDs) == 0 {
+		return nil
+	}
+	query := `UPDATE sync_queue SET payload = json_set(payload,
+                '$.booking.user_id', 0, '$.booking.user_name', ?, '$.booking.user_nickname', '',
+                '$.booking.phone', '', '$.booking.comment', '')
+              WHERE booking_id IN (` + placeholders(len(bookingIDs)) + `)
+                AND json_valid(payload) AND json_type(payload, '$.booking') = 'object'`
+	args := make([]any, 0, len(bookingIDs)+1)
+	args = append(args, models.AnonymizedUserName)
+	for _, id := range bookingIDs {
+		args = append(args, id)
+	}
+	if _, err := tx.ExecContext(ctx, query, args...); err != nil {
+		return fmt.Errorf("failed to scrub sync queue: %w", err)
+	}
+	return nil
+}
+
+// scrubAuditComments removes booking comments and handover notes from audit snapshots
+// of the given bookings. The append-only trigger of audit_log allows exactly this change
+// and nothing else.
+func scrubAuditComments(ctx context.Context, tx *sql.Tx, bookingIDs []int64) error {
+	if len(bookingIDs) == 0 {
+		return nil
+	}
+	query := `UPDATE audit_log SET
+                before_value = ` + auditScrubExpr("before_value") + `,
+                after_value = ` + auditScrubExpr("after_value") + `
+              WHERE entity_type = ? AND entity_id IN (` + placeholders(len(bookingIDs)) + `)`
+	args := make([]any, 0, len(bookingIDs)+1)
+	args = append(args, models.AuditEntityBooking)
+	for _, id := range bookingIDs {
+		args = append(args, id)
+	}
+	if _, err := tx.ExecContext(ctx, query, args...); err != nil {
+		return fmt.Errorf("failed to scrub audit log: %w", err)
+	}
+	return nil
+}
+
+// auditScrubExpr returns the SQL expression that drops the client's free text (booking comment,
+// handover note) from a JSON snapshot column. Empty and non-JSON values are kept as they are.
+func auditScrubExpr(column string) string {
+	return `CASE WHEN json_valid(` + column + `) THEN json_remove(` + column + `, '$.comment', '$.note') ELSE ` + column + ` END`
+}
@@ -0,0 +1,227 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"bronivik/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPersonalData(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	ctx := context.Background()
+
+	user := &models.User{
+		TelegramID: 777, Username: "client", FirstName: "Иван", LastName: "Петров",
+		Phone: "79990000000", LastActivity: time.Now(),
+	}
+	require.NoError(t, db.CreateOrUpdateUser(ctx, user))
+
+	found, err := db.GetUserByTelegramID(ctx, 777)
+	require.NoError(t, err)
+	assert.False(t, found.ConsentGiven)
+
+	require.NoError(t, db.SetUserConsent(ctx, 777))
+	found, err = db.GetUserByTelegramID(ctx, 777)
+	require.NoError(t, err)
+	assert.True(t, found.ConsentGiven)
+	assert.True(t, found.ConsentGivenAt.Valid)
+
+	item := &models.Item{Name: "Item", TotalQuantity: 5, IsActive: true}
+	require.NoError(t, db.CreateItem(ctx, item))
+
+	old := &models.Booking{
+		UserID: 777, UserName: "Иван Петров", Phone: "79990000000", ItemID: item.ID, ItemName: item.Name,
+		Date: time.Now().AddDate(0, -2, 0), Status: models.StatusCompleted, Comment: "звонить после 18",
+	}
+	future := &models.Booking{
+		UserID: 777, UserName: "Иван Петров", Phone: "79990000000", ItemID: item.ID, ItemName: item.Name,
+		Date: time.Now().AddDate(0, 0, 5), Status: models.StatusPending,
+	}
+	other := &models.Booking{
+		UserID: 888, UserName: "Другой", Phone: "79991111111", ItemID: item.ID, ItemName: item.Name,
+		Date: time.Now().AddDate(0, 0, 5), Status: models.StatusPending,
+	}
+	for _, b := range []*models.Booking{old, future, other} {
+		require.NoError(t, db.CreateBooking(ctx, b))
+	}
+
+	all, err := db.GetAllUserBookings(ctx, 777)
+	require.NoError(t, err)
+	assert.Len(t, all, 2, "old bookings must be included in the export")
+
+	ids, err := db.AnonymizeUser(ctx, 777)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []int64{old.ID, future.ID}, ids)
+
+	anonymized, err := db.GetBooking(ctx, old.ID)
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), anonymized.UserID)
+	assert.Equal(t, models.AnonymizedUserName, anonymized.UserName)
+	assert.Empty(t, anonymized.Phone)
+	assert.Empty(t, anonymized.Comment)
+	assert.Equal(t, old.Version+1, anonymized.Version)
+
+	untouched, err := db.GetBooking(ctx, other.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "Другой", untouched.UserName)
+
+	found, err = db.GetUserByTelegramID(ctx, 777)
+	require.NoError(t, err)
+	assert.Empty(t, found.FirstName)
+	assert.Empty(t, found.Phone)
+	assert.False(t, found.ConsentGiven)
+	assert.True(t, found.ConsentRevoked)
+	assert.True(t, found.ConsentRevokedAt.Valid)
+
+	all, err = db.GetAllUserBookings(ctx, 777)
+	require.NoError(t, err)
+	assert.Empty(t, all)
+}
+
+func TestAnonymizeUser_ScrubsSyncQueueAndAudit(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+	ctx := context.Background()
+
+	item := &models.Item{Name: "Item", TotalQuantity: 5, IsActive: true}
+	require.NoError(t, db.CreateItem(ctx, item))
+	booking := &models.Booking{
+		UserID: 777, UserName: "Иван Петров", UserNickname: "ivan", Phone: "79990000000",
+		ItemID: item.ID, ItemName: item.Name, Date: time.Now().AddDate(0, 0, 5),
+		Status: models.StatusPending, Comment: "звонить после 18",
+	}
+	require.NoError(t, db.CreateBooking(ctx, booking))
+
+	payload, err := json.Marshal(map[string]any{"booking_id": booking.ID, "booking": booking})
+	require.NoError(t, err)
+	task := &models.SyncTask{TaskType: "upsert", BookingID: booking.ID, Payload: string(payload), Status: "pending"}
+	require.NoError(t, db.CreateSyncTask(ctx, task))
+	schedule := &models.SyncTask{TaskType: "sync_schedule", Payload: `{"booking_id":0}`, Status: "pending"}
+	require.NoError(t, db.CreateSyncTask(ctx, schedule))
+
+	require.NoError(t, db.CreateAuditEntry(ctx, &models.AuditEntry{
+		EntityType: models.AuditEntityBooking, EntityID: booking.ID, Action: models.AuditActionCreate,
+		After: `{"status":"pending","comment":"звонить после 18","version":1}`,
+	}))
+	require.NoError(t, db.CreateAuditEntry(ctx, &models.AuditEntry{
+		EntityType: models.AuditEntityBooking, EntityID: booking.ID, Action: models.AuditActionStatusChange,
+		Before: `{"status":"pending","comment":"звонить после 18","version":1}`, After: `{"status":"confirmed","version":2}`,
+	}))
+
+	_, err = db.AnonymizeUser(ctx, 777)
+	require.NoError(t, err)
+
+	tasks, err := db.GetPendingSyncTasks(ctx, 10)
+	require.NoError(t, err)
+	require.Len(t, tasks, 2)
+	for _, task := range tasks {
+		for _, secret := range []string{"Иван", "ivan", "79990000000", "звонить"} {
+			assert.NotContains(t, task.Payload, secret)
+		}
+	}
+	var scrubbed struct {
+		BookingID int64           `json:"booking_id"`
+		Booking   *models.Booking `json:"booking"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(tasks[0].Payload), &scrubbed))
+	assert.Equal(t, booking.ID, scrubbed.Booking.ID)
+	assert.Equal(t, models.AnonymizedUserName, scrubbed.Booking.UserName)
+	assert.Equal(t, item.Name, scrubbed.Booking.ItemName)
+	assert.Equal(t, `{"booking_id":0}`, tasks[1].Payload)
+
+	entries, err := db.GetAuditEntries(ctx, models.AuditEntityBooking, booking.ID)
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	assert.Equal(t, `{"status":"pending","version":1}`, entries[0].After)
+	assert.Equal(t, `{"status":"pending","version":1}`, entries[1].Before)
+	assert.Equal(t, `{"status":"confirmed","version":2}`, entries[1].After)
+	assert.Empty(t, entries[0].Before)
+}
+
+func TestAnonymizeUser_ScrubsHandovers(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+	ctx := context.Background()
+
+	item := &models.Item{Name: "Item", TotalQuantity: 5, IsActive: true}
+	require.NoError(t, db.CreateItem(ctx, item))
+	day := time.Now().AddDate(0, 0, 1).Truncate(24 * time.Hour)
+	booking := &models.Booking{
+		UserID: 777, UserName: "Иван Петров", Phone: "79990000000", ItemID: item.ID, ItemName: item.Name,
+		Date: day, Status: models.StatusConfirmed,
+	}
+	other := &models.Booking{
+		UserID: 888, UserName: "Другой", Phone: "79991111111", ItemID: item.ID, ItemName: item.Name,
+		Date: day, Status: models.StatusConfirmed,
+	}
+	require.NoError(t, db.CreateBooking(ctx, booking))
+	require.NoError(t, db.CreateBooking(ctx, other))
+
+	handover := &models.Handover{
+		BookingID: booking.ID, UserID: 777, UserName: "Иван Петров", ItemID: item.ID, ItemName: item.Name,
+		PlannedStart: day, PlannedEnd: day, CheckedOutAt: time.Now(), CheckedOutBy: 1,
+		CheckOutNote: "паспорт 4510 123456",
+	}
+	require.NoError(t, db.CreateHandover(ctx, handover))
+	handover.CheckedInAt = sql.NullTime{Time: time.Now(), Valid: true}
+	handover.CheckedInBy = 1
+	handover.CheckInNote = "Иван вернул без чехла"
+	require.NoError(t, db.CheckInHandover(ctx, handover))
+	otherHandover := &models.Handover{
+		BookingID: other.ID, UserID: 888, UserName: "Другой", ItemID: item.ID, ItemName: item.Name,
+		PlannedStart: day, PlannedEnd: day, CheckedOutAt: time.Now(), CheckedOutBy: 1, CheckOutNote: "залог наличными",
+	}
+	require.NoError(t, db.CreateHandover(ctx, otherHandover))
+
+	require.NoError(t, db.CreateAuditEntry(ctx, &models.AuditEntry{
+		EntityType: models.AuditEntityBooking, EntityID: booking.ID, Action: models.AuditActionCheckOut,
+		After: `{"planned_start":"2030-04-10","note":"паспорт 4510 123456","booking_ids":[1]}`,
+	}))
+
+	handovers, err := db.GetUserHandovers(ctx, 777)
+	require.NoError(t, err)
+	require.Len(t, handovers, 1, "/mydata includes the client's handovers")
+	assert.Equal(t, "Иван вернул без чехла", handovers[0].CheckInNote)
+
+	_, err = db.AnonymizeUser(ctx, 777)
+	require.NoError(t, err)
+
+	handovers, err = db.GetUserHandovers(ctx, 777)
+	require.NoError(t, err)
+	assert.Empty(t, handovers)
+
+	// Лист выдач строится из GetHandoversByPeriod
+	period, err := db.GetHandoversByPeriod(ctx, day, day)
+	require.NoError(t, err)
+	require.Len(t, period, 2)
+	for _, h := range period {
+		if h.ID == otherHandover.ID {
+			assert.Equal(t, int64(888), h.UserID)
+			assert.Equal(t, "Другой", h.UserName)
+			assert.Equal(t, "залог наличными", h.CheckOutNote)
+			continue
+		}
+		assert.Equal(t, int64(0), h.UserID)
+		assert.Equal(t, models.AnonymizedUserName, h.UserName)
+		assert.Empty(t, h.CheckOutNote)
+		assert.Empty(t, h.CheckInNote)
+		assert.True(t, h.CheckedInAt.Valid, "handover facts are kept")
+	}
+
+	entries, err := db.GetAuditEntries(ctx, models.AuditEntityBooking, booking.ID)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.Equal(t, `{"planned_start":"2030-04-10","booking_ids":[1]}`, entries[0].After)
+
+	_, err = db.ExecContext(ctx, `UPDATE audit_log SET after_value = '{}' WHERE id = ?`, entries[0].ID)
+	assert.ErrorContains(t, err, "append-only", "only comments and notes may be scrubbed")
+}
@@ -0,0 +1,34 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// ClaimReminder marks the reminder as sent for the booking.
+// It returns false when the reminder was already claimed, so every reminder goes out once
+// even after a restart or when several bot replicas share the database.
+func (db *DB) ClaimReminder(ctx context.Context, bookingID int64, key string) (bool, error) {
+	res, err := db.ExecContext(ctx,
+		`INSERT OR IGNORE INTO sent_reminders (booking_id, reminder_key, sent_at) VALUES (?, ?, ?)`,
+		bookingID, key, time.Now())
+	if err != nil {
+		return false, fmt.Errorf("failed to claim reminder: %w", err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to claim reminder: %w", err)
+	}
+	return n > 0, nil
+}
+
+// ReleaseReminder drops a claim whose reminder could not be delivered, so it is retried later.
+func (db *DB) ReleaseReminder(ctx context.Context, bookingID int64, key string) error {
+	_, err := db.ExecContext(ctx,
+		`DELETE FROM sent_reminders WHERE booking_id = ? AND reminder_key = ?`, bookingID, key)
+	if err != nil {
+		return fmt.Errorf("failed to release reminder: %w", err)
+	}
+	return nil
+}
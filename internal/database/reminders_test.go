@@ -0,0 +1,39 @@
+package database
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClaimReminder(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	ctx := context.Background()
+
+	claimed, err := db.ClaimReminder(ctx, 5, "before:1")
+	require.NoError(t, err)
+	assert.True(t, claimed)
+
+	// Вторая реплика или повторный запуск не получают то же напоминание
+	claimed, err = db.ClaimReminder(ctx, 5, "before:1")
+	require.NoError(t, err)
+	assert.False(t, claimed)
+
+	// Другой этап и другая бронь независимы
+	claimed, err = db.ClaimReminder(ctx, 5, "return:0")
+	require.NoError(t, err)
+	assert.True(t, claimed)
+	claimed, err = db.ClaimReminder(ctx, 6, "before:1")
+	require.NoError(t, err)
+	assert.True(t, claimed)
+
+	// После неудачной отправки напоминание можно взять снова
+	require.NoError(t, db.ReleaseReminder(ctx, 5, "before:1"))
+	claimed, err = db.ClaimReminder(ctx, 5, "before:1")
+	require.NoError(t, err)
+	assert.True(t, claimed)
+}
@@ -0,0 +1,124 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"bronivik/internal/models"
+)
+
+// GetUserRoles returns all assigned roles together with their item scopes.
+func (db *DB) GetUserRoles(ctx context.Context) ([]*models.UserRole, error) {
+	rows, err := db.QueryContext(ctx,
+		`SELECT telegram_id, role, granted_by, created_at, updated_at FROM user_roles ORDER BY telegram_id`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user roles: %w", err)
+	}
+	defer rows.Close()
+
+	var roles []*models.UserRole
+	byUser := make(map[int64]*models.UserRole)
+	for rows.Next() {
+		var r models.UserRole
+		if err := rows.Scan(&r.TelegramID, &r.Role, &r.GrantedBy, &r.CreatedAt, &r.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan user role: %w", err)
+		}
+		roles = append(roles, &r)
+		byUser[r.TelegramID] = &r
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	scopeRows, err := db.QueryContext(ctx, `SELECT telegram_id, item_id FROM user_role_items ORDER BY telegram_id, item_id`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get role scopes: %w", err)
+	}
+	defer scopeRows.Close()
+
+	for scopeRows.Next() {
+		var telegramID, itemID int64
+		if err := scopeRows.Scan(&telegramID, &itemID); err != nil {
+			return nil, fmt.Errorf("failed to scan role scope: %w", err)
+		}
+		if r, ok := byUser[telegramID]; ok {
+			r.ItemIDs = append(r.ItemIDs, itemID)
+		}
+	}
+
+	return roles, scopeRows.Err()
+}
+
+// SetUserRole assigns a role to a user, replacing any previous role and item scope.
+func (db *DB) SetUserRole(ctx context.Context, role *models.UserRole) error {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	now := time.Now()
+	_, err = tx.ExecContext(ctx, `INSERT INTO user_roles (telegram_id, role, granted_by, created_at, updated_at)
+              VALUES (?, ?, ?, ?, ?)
+              ON CONFLICT(telegram_id) DO UPDATE SET
+                role = excluded.role,
+                granted_by = excluded.granted_by,
+                updated_at = excluded.updated_at`,
+		role.TelegramID, role.Role, role.GrantedBy, now, now)
+	if err != nil {
+		return fmt.Errorf("failed to save user role: %w", err)
+	}
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM user_role_items WHERE telegram_id = ?`, role.TelegramID); err != nil {
+		return fmt.Errorf("failed to clear role scope: %w", err)
+	}
+	for _, itemID := range role.ItemIDs {
+		if _, err := tx.ExecContext(ctx,
+			`INSERT OR IGNORE INTO user_role_items (telegram_id, item_id) VALUES (?, ?)`, role.TelegramID, itemID); err != nil {
+			return fmt.Errorf("failed to save role scope: %w", err)
+		}
+	}
+
+	isManager := models.RoleHasPermission(role.Role, models.PermManageBookings)
+	if _, err := tx.ExecContext(ctx,
+		`UPDATE users SET is_manager = ?, updated_at = ? WHERE telegram_id = ?`, isManager, now, role.TelegramID); err != nil {
+		return fmt.Errorf("failed to update manager flag: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	if role.CreatedAt.IsZero() {
+		role.CreatedAt = now
+	}
+	role.UpdatedAt = now
+	return nil
+}
+
+// DeleteUserRole removes the role and item scope of a user.
+func (db *DB) DeleteUserRole(ctx context.Context, telegramID int64) error {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM user_role_items WHERE telegram_id = ?`, telegramID); err != nil {
+		return fmt.Errorf("failed to delete role scope: %w", err)
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM user_roles WHERE telegram_id = ?`, telegramID); err != nil {
+		return fmt.Errorf("failed to delete user role: %w", err)
+	}
+	if _, err := tx.ExecContext(ctx,
+		`UPDATE users SET is_manager = 0, updated_at = ? WHERE telegram_id = ?`, time.Now(), telegramID); err != nil {
+		return fmt.Errorf("failed to update manager flag: %w", err)
+	}
+
+	return tx.Commit()
+}
@@ -0,0 +1,53 @@
+package database
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"bronivik/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUserRoles(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	ctx := context.Background()
+
+	user := &models.User{TelegramID: 42, FirstName: "Staff", LastActivity: time.Now()}
+	require.NoError(t, db.CreateOrUpdateUser(ctx, user))
+
+	role := &models.UserRole{TelegramID: 42, Role: models.RoleManager, ItemIDs: []int64{2, 1}, GrantedBy: 7}
+	require.NoError(t, db.SetUserRole(ctx, role))
+
+	roles, err := db.GetUserRoles(ctx)
+	require.NoError(t, err)
+	require.Len(t, roles, 1)
+	assert.Equal(t, models.RoleManager, roles[0].Role)
+	assert.Equal(t, []int64{1, 2}, roles[0].ItemIDs)
+	assert.Equal(t, int64(7), roles[0].GrantedBy)
+
+	stored, err := db.GetUserByTelegramID(ctx, 42)
+	require.NoError(t, err)
+	assert.True(t, stored.IsManager)
+
+	// Повторное назначение заменяет роль и область действия
+	require.NoError(t, db.SetUserRole(ctx, &models.UserRole{TelegramID: 42, Role: models.RoleViewer}))
+	roles, err = db.GetUserRoles(ctx)
+	require.NoError(t, err)
+	require.Len(t, roles, 1)
+	assert.Equal(t, models.RoleViewer, roles[0].Role)
+	assert.Empty(t, roles[0].ItemIDs)
+
+	stored, err = db.GetUserByTelegramID(ctx, 42)
+	require.NoError(t, err)
+	assert.False(t, stored.IsManager)
+
+	require.NoError(t, db.DeleteUserRole(ctx, 42))
+	roles, err = db.GetUserRoles(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, roles)
+}
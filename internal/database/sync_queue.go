@@ -3,6 +3,7 @@ package database
 import (
 	"context"
 	"fmt"
+	"strings"
 	"time"
 
 	"bronivik/internal/models"
@@ -107,3 +108,23 @@ func (db *DB) GetFailedSyncTasks(ctx context.Context) ([]models.SyncTask, error)
 	}
 	return tasks, nil
 }
+
+// RequeueFailedSyncTasks returns failed tasks to the queue with a fresh retry budget.
+// Without ids every failed task is requeued. It returns the number of requeued tasks.
+func (db *DB) RequeueFailedSyncTasks(ctx context.Context, ids []int64) (int64, error) {
+	query := `UPDATE sync_queue SET status = 'pending', retry_count = 0, next_retry_at = NULL, processed_at = NULL
+              WHERE status = 'failed'`
+	args := make([]interface{}, 0, len(ids))
+	if len(ids) > 0 {
+		query += ` AND id IN (?` + strings.Repeat(", ?", len(ids)-1) + `)`
+		for _, id := range ids {
+			args = append(args, id)
+		}
+	}
+
+	result, err := db.ExecContext(ctx, query, args...)
+	if err != nil {
+		return 0, fmt.Errorf("failed to requeue sync tasks: %w", err)
+	}
+	return result.RowsAffected()
+}
@@ -81,3 +81,41 @@ func TestSyncQueueCRUD(t *testing.T) {
 	}
 	assert.True(t, found)
 }
+
+func TestRequeueFailedSyncTasks(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	ctx := context.Background()
+
+	var failed []*models.SyncTask
+	for i := int64(1); i <= 3; i++ {
+		task := &models.SyncTask{TaskType: "upsert", BookingID: i, Status: "pending"}
+		require.NoError(t, db.CreateSyncTask(ctx, task))
+		nextRetry := time.Now().Add(time.Hour)
+		require.NoError(t, db.UpdateSyncTaskStatus(ctx, task.ID, "retry", "sheets unavailable", &nextRetry))
+		require.NoError(t, db.UpdateSyncTaskStatus(ctx, task.ID, "failed", "sheets unavailable", nil))
+		failed = append(failed, task)
+	}
+	done := &models.SyncTask{TaskType: "upsert", BookingID: 4, Status: "completed"}
+	require.NoError(t, db.CreateSyncTask(ctx, done))
+
+	n, err := db.RequeueFailedSyncTasks(ctx, []int64{failed[0].ID, done.ID})
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), n)
+
+	tasks, err := db.GetPendingSyncTasks(ctx, 10)
+	require.NoError(t, err)
+	require.Len(t, tasks, 1)
+	assert.Equal(t, failed[0].ID, tasks[0].ID)
+	assert.Equal(t, 0, tasks[0].RetryCount)
+	assert.Nil(t, tasks[0].NextRetryAt)
+
+	n, err = db.RequeueFailedSyncTasks(ctx, nil)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), n)
+
+	stillFailed, err := db.GetFailedSyncTasks(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, stillFailed)
+}
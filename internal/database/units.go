@@ -0,0 +1,169 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"bronivik/internal/models"
+)
+
+const unitColumns = `id, item_id, serial_number, inventory_tag, status, note, created_at, updated_at`
+
+// CreateUnit registers a physical unit of an item.
+// It returns models.ErrDuplicateSerial if the serial number is already taken.
+func (db *DB) CreateUnit(ctx context.Context, u *models.ItemUnit) error {
+	now := time.Now()
+	if u.Status == "" {
+		u.Status = models.UnitStatusActive
+	}
+	res, err := db.ExecContext(ctx, `INSERT INTO item_units (
+				item_id, serial_number, inventory_tag, status, note, created_at, updated_at
+			) VALUES (?, ?, ?, ?, ?, ?, ?)
+              ON CONFLICT(serial_number) DO NOTHING`,
+		u.ItemID, u.SerialNumber, u.InventoryTag, u.Status, u.Note, now, now)
+	if err != nil {
+		return fmt.Errorf("failed to create unit: %w", err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return models.ErrDuplicateSerial
+	}
+
+	id, err := res.LastInsertId()
+	if err != nil {
+		return fmt.Errorf("failed to get last insert id: %w", err)
+	}
+	u.ID = id
+	u.CreatedAt = now
+	u.UpdatedAt = now
+	return nil
+}
+
+// GetUnit returns a unit by ID or models.ErrUnitNotFound.
+func (db *DB) GetUnit(ctx context.Context, id int64) (*models.ItemUnit, error) {
+	return db.getUnit(ctx, `SELECT `+unitColumns+` FROM item_units WHERE id = ?`, id)
+}
+
+// GetUnitBySerial returns a unit by its serial number or models.ErrUnitNotFound.
+func (db *DB) GetUnitBySerial(ctx context.Context, serial string) (*models.ItemUnit, error) {
+	return db.getUnit(ctx, `SELECT `+unitColumns+` FROM item_units WHERE serial_number = ?`, serial)
+}
+
+// GetItemUnits returns all units of the item ordered by ID.
+func (db *DB) GetItemUnits(ctx context.Context, itemID int64) ([]*models.ItemUnit, error) {
+	rows, err := db.QueryContext(ctx, `SELECT `+unitColumns+` FROM item_units WHERE item_id = ? ORDER BY id ASC`, itemID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get item units: %w", err)
+	}
+	defer rows.Close()
+
+	var units []*models.ItemUnit
+	for rows.Next() {
+		u, err := scanUnit(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan unit: %w", err)
+		}
+		units = append(units, u)
+	}
+	return units, rows.Err()
+}
+
+// UpdateUnitStatus changes the status of a unit, for example when it is sent for repair.
+func (db *DB) UpdateUnitStatus(ctx context.Context, id int64, status, note string) error {
+	res, err := db.ExecContext(ctx, `UPDATE item_units SET status = ?, note = ?, updated_at = ? WHERE id = ?`,
+		status, note, time.Now(), id)
+	if err != nil {
+		return fmt.Errorf("failed to update unit status: %w", err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return models.ErrUnitNotFound
+	}
+	return nil
+}
+
+// SetBookingUnit assigns a unit to a booking. The booking version is not changed
+// because the assignment does not conflict with concurrent status updates.
+func (db *DB) SetBookingUnit(ctx context.Context, bookingID, unitID int64) error {
+	_, err := db.ExecContext(ctx, `UPDATE bookings SET unit_id = ?, updated_at = ? WHERE id = ?`,
+		unitID, time.Now(), bookingID)
+	if err != nil {
+		return fmt.Errorf("failed to set booking unit: %w", err)
+	}
+	return nil
+}
+
+// GetBusyUnitIDs returns the units already assigned to active bookings of the item on date.
+func (db *DB) GetBusyUnitIDs(ctx context.Context, itemID int64, date time.Time) ([]int64, error) {
+	rows, err := db.QueryContext(ctx, `SELECT DISTINCT unit_id FROM bookings
+              WHERE item_id = ? AND date(date) = ? AND unit_id != 0 AND status NOT IN (?, ?)`,
+		itemID, date.Format("2006-01-02"), models.StatusCanceled, "rejected")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get busy units: %w", err)
+	}
+	defer rows.Close()
+
+	var ids []int64
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("failed to scan unit id: %w", err)
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+// GetUnitBookings returns every booking the unit was assigned to, newest first.
+func (db *DB) GetUnitBookings(ctx context.Context, unitID int64) ([]*models.Booking, error) {
+	query := `SELECT id, user_id, user_name, user_nickname, phone, item_id,
+	                 item_name, date(date), status, comment, created_at,
+	                 updated_at, version, unit_id, price, deposit
+              FROM bookings WHERE unit_id = ? ORDER BY date DESC, id DESC`
+	rows, err := db.QueryContext(ctx, query, unitID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get unit bookings: %w", err)
+	}
+	defer rows.Close()
+
+	var bookings []*models.Booking
+	for rows.Next() {
+		b := &models.Booking{}
+		var dateStr string
+		err := rows.Scan(
+			&b.ID, &b.UserID, &b.UserName, &b.UserNickname, &b.Phone,
+			&b.ItemID, &b.ItemName, &dateStr, &b.Status, &b.Comment,
+			&b.CreatedAt, &b.UpdatedAt, &b.Version, &b.UnitID, &b.Price, &b.Deposit,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan booking: %w", err)
+		}
+		b.Date, err = time.Parse("2006-01-02", dateStr)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse booking date %s: %w", dateStr, err)
+		}
+		bookings = append(bookings, b)
+	}
+	return bookings, rows.Err()
+}
+
+func (db *DB) getUnit(ctx context.Context, query string, arg interface{}) (*models.ItemUnit, error) {
+	u, err := scanUnit(db.QueryRowContext(ctx, query, arg))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, models.ErrUnitNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get unit: %w", err)
+	}
+	return u, nil
+}
+
+func scanUnit(row rowScanner) (*models.ItemUnit, error) {
+	var u models.ItemUnit
+	err := row.Scan(&u.ID, &u.ItemID, &u.SerialNumber, &u.InventoryTag, &u.Status, &u.Note, &u.CreatedAt, &u.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &u, nil
+}
@@ -0,0 +1,74 @@
+package database
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"bronivik/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestItemUnits(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	ctx := context.Background()
+	date := time.Date(2030, 5, 10, 0, 0, 0, 0, time.UTC)
+
+	item := &models.Item{Name: "Camera", TotalQuantity: 2}
+	require.NoError(t, db.CreateItem(ctx, item))
+
+	first := &models.ItemUnit{ItemID: item.ID, SerialNumber: "SN-1", InventoryTag: "INV-1"}
+	require.NoError(t, db.CreateUnit(ctx, first))
+	assert.NotZero(t, first.ID)
+	assert.Equal(t, models.UnitStatusActive, first.Status)
+	second := &models.ItemUnit{ItemID: item.ID, SerialNumber: "SN-2"}
+	require.NoError(t, db.CreateUnit(ctx, second))
+	assert.ErrorIs(t, db.CreateUnit(ctx, &models.ItemUnit{ItemID: item.ID, SerialNumber: "SN-1"}), models.ErrDuplicateSerial)
+
+	units, err := db.GetItemUnits(ctx, item.ID)
+	require.NoError(t, err)
+	require.Len(t, units, 2)
+	assert.Equal(t, "INV-1", units[0].InventoryTag)
+
+	require.NoError(t, db.UpdateUnitStatus(ctx, second.ID, models.UnitStatusRepair, "broken lens"))
+	got, err := db.GetUnitBySerial(ctx, "SN-2")
+	require.NoError(t, err)
+	assert.Equal(t, models.UnitStatusRepair, got.Status)
+	assert.Equal(t, "broken lens", got.Note)
+	assert.ErrorIs(t, db.UpdateUnitStatus(ctx, 999, models.UnitStatusActive, ""), models.ErrUnitNotFound)
+	_, err = db.GetUnit(ctx, 999)
+	assert.ErrorIs(t, err, models.ErrUnitNotFound)
+
+	booking := &models.Booking{UserID: 1, ItemID: item.ID, ItemName: item.Name, Date: date, Status: models.StatusConfirmed}
+	require.NoError(t, db.CreateBookingWithLock(ctx, booking))
+	require.NoError(t, db.SetBookingUnit(ctx, booking.ID, first.ID))
+
+	stored, err := db.GetBooking(ctx, booking.ID)
+	require.NoError(t, err)
+	assert.Equal(t, first.ID, stored.UnitID)
+	assert.Equal(t, int64(1), stored.Version)
+
+	busy, err := db.GetBusyUnitIDs(ctx, item.ID, date)
+	require.NoError(t, err)
+	assert.Equal(t, []int64{first.ID}, busy)
+
+	history, err := db.GetUnitBookings(ctx, first.ID)
+	require.NoError(t, err)
+	require.Len(t, history, 1)
+	assert.Equal(t, booking.ID, history[0].ID)
+
+	// Отмененная заявка освобождает экземпляр, смена аппарата снимает привязку
+	require.NoError(t, db.UpdateBookingStatusWithVersion(ctx, booking.ID, 1, models.StatusCanceled))
+	busy, err = db.GetBusyUnitIDs(ctx, item.ID, date)
+	require.NoError(t, err)
+	assert.Empty(t, busy)
+
+	require.NoError(t, db.UpdateBookingItemAndStatusWithVersion(ctx, booking.ID, 2, item.ID, item.Name, models.StatusChanged))
+	stored, err = db.GetBooking(ctx, booking.ID)
+	require.NoError(t, err)
+	assert.Zero(t, stored.UnitID)
+}
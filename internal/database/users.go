@@ -2,12 +2,20 @@ package database
 
 import (
 	"context"
+	"database/sql"
 	"fmt"
 	"time"
 
 	"bronivik/internal/models"
 )
 
+const userColumns = `id, telegram_id, username, first_name, last_name,
+		phone, is_manager, is_blacklisted, language_code,
+		last_activity, created_at, updated_at,
+		blacklist_reason, blacklisted_until, blacklisted_by,
+		consent_given, consent_given_at, consent_revoked, consent_revoked_at,
+		preferred_language, reminders_disabled`
+
 func (db *DB) CreateOrUpdateUser(ctx context.Context, user *models.User) error {
 	query := `INSERT INTO users (
 				telegram_id, username, first_name, last_name, phone, 
@@ -46,26 +54,33 @@ func (db *DB) CreateOrUpdateUser(ctx context.Context, user *models.User) error {
 }
 
 func (db *DB) GetUserByTelegramID(ctx context.Context, telegramID int64) (*models.User, error) {
-	query := `SELECT id, telegram_id, username, first_name, last_name, 
-	                 phone, is_manager, is_blacklisted, language_code, 
-					 last_activity, created_at, updated_at 
+	query := `SELECT ` + userColumns + `
               FROM users WHERE telegram_id = ?`
 	return db.queryUser(ctx, query, telegramID)
 }
 
 func (db *DB) GetUserByID(ctx context.Context, id int64) (*models.User, error) {
-	query := `SELECT id, telegram_id, username, first_name, last_name, 
-	                 phone, is_manager, is_blacklisted, language_code, 
-					 last_activity, created_at, updated_at 
+	query := `SELECT ` + userColumns + `
               FROM users WHERE id = ?`
 	return db.queryUser(ctx, query, id)
 }
 
 func (db *DB) queryUser(ctx context.Context, query string, args ...interface{}) (*models.User, error) {
+	return scanUser(db.QueryRowContext(ctx, query, args...))
+}
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanUser(row rowScanner) (*models.User, error) {
 	var user models.User
-	err := db.QueryRowContext(ctx, query, args...).Scan(
+	err := row.Scan(
 		&user.ID, &user.TelegramID, &user.Username, &user.FirstName, &user.LastName, &user.Phone,
 		&user.IsManager, &user.IsBlacklisted, &user.LanguageCode, &user.LastActivity, &user.CreatedAt, &user.UpdatedAt,
+		&user.BlacklistReason, &user.BlacklistedUntil, &user.BlacklistedBy,
+		&user.ConsentGiven, &user.ConsentGivenAt, &user.ConsentRevoked, &user.ConsentRevokedAt,
+		&user.PreferredLanguage, &user.RemindersDisabled,
 	)
 	if err != nil {
 		return nil, err
@@ -79,6 +94,39 @@ func (db *DB) UpdateUserPhone(ctx context.Context, telegramID int64, phone strin
 	return err
 }
 
+// UpdateUserLanguage stores the language picked by the user with /language.
+// Unlike language_code it is not overwritten by the Telegram client settings.
+func (db *DB) UpdateUserLanguage(ctx context.Context, telegramID int64, lang string) error {
+	now := time.Now()
+	query := `INSERT INTO users (
+				telegram_id, username, first_name, last_name, phone, language_code,
+				preferred_language, last_activity, created_at, updated_at
+			) VALUES (?, '', '', '', '', '', ?, ?, ?, ?)
+              ON CONFLICT(telegram_id) DO UPDATE SET
+                preferred_language = excluded.preferred_language,
+                updated_at = excluded.updated_at`
+	if _, err := db.ExecContext(ctx, query, telegramID, lang, now, now, now); err != nil {
+		return fmt.Errorf("failed to update user language: %w", err)
+	}
+	return nil
+}
+
+// UpdateUserReminders switches booking reminders off or back on for the user.
+func (db *DB) UpdateUserReminders(ctx context.Context, telegramID int64, disabled bool) error {
+	now := time.Now()
+	query := `INSERT INTO users (
+				telegram_id, username, first_name, last_name, phone, language_code,
+				reminders_disabled, last_activity, created_at, updated_at
+			) VALUES (?, '', '', '', '', '', ?, ?, ?, ?)
+              ON CONFLICT(telegram_id) DO UPDATE SET
+                reminders_disabled = excluded.reminders_disabled,
+                updated_at = excluded.updated_at`
+	if _, err := db.ExecContext(ctx, query, telegramID, disabled, now, now, now); err != nil {
+		return fmt.Errorf("failed to update user reminders: %w", err)
+	}
+	return nil
+}
+
 func (db *DB) UpdateUserActivity(ctx context.Context, telegramID int64) error {
 	query := `UPDATE users SET last_activity = ?, updated_at = ? WHERE telegram_id = ?`
 	now := time.Now()
@@ -87,9 +135,7 @@ func (db *DB) UpdateUserActivity(ctx context.Context, telegramID int64) error {
 }
 
 func (db *DB) GetAllUsers(ctx context.Context) ([]*models.User, error) {
-	query := `SELECT id, telegram_id, username, first_name, last_name, 
-		phone, is_manager, is_blacklisted, language_code, 
-		last_activity, created_at, updated_at
+	query := `SELECT ` + userColumns + `
               FROM users ORDER BY last_activity DESC`
 	rows, err := db.QueryContext(ctx, query)
 	if err != nil {
@@ -99,11 +145,7 @@ func (db *DB) GetAllUsers(ctx context.Context) ([]*models.User, error) {
 
 	var users []*models.User
 	for rows.Next() {
-		u := &models.User{}
-		err := rows.Scan(
-			&u.ID, &u.TelegramID, &u.Username, &u.FirstName, &u.LastName, &u.Phone,
-			&u.IsManager, &u.IsBlacklisted, &u.LanguageCode, &u.LastActivity, &u.CreatedAt, &u.UpdatedAt,
-		)
+		u, err := scanUser(rows)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan user: %w", err)
 		}
@@ -113,9 +155,7 @@ func (db *DB) GetAllUsers(ctx context.Context) ([]*models.User, error) {
 }
 
 func (db *DB) GetUsersByManagerStatus(ctx context.Context, isManager bool) ([]*models.User, error) {
-	query := `SELECT id, telegram_id, username, first_name, last_name, 
-		phone, is_manager, is_blacklisted, language_code, 
-		last_activity, created_at, updated_at
+	query := `SELECT ` + userColumns + `
               FROM users WHERE is_manager = ? ORDER BY last_activity DESC`
 	rows, err := db.QueryContext(ctx, query, isManager)
 	if err != nil {
@@ -125,11 +165,7 @@ func (db *DB) GetUsersByManagerStatus(ctx context.Context, isManager bool) ([]*m
 
 	var users []*models.User
 	for rows.Next() {
-		u := &models.User{}
-		err := rows.Scan(
-			&u.ID, &u.TelegramID, &u.Username, &u.FirstName, &u.LastName, &u.Phone,
-			&u.IsManager, &u.IsBlacklisted, &u.LanguageCode, &u.LastActivity, &u.CreatedAt, &u.UpdatedAt,
-		)
+		u, err := scanUser(rows)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan user: %w", err)
 		}
@@ -140,9 +176,7 @@ func (db *DB) GetUsersByManagerStatus(ctx context.Context, isManager bool) ([]*m
 
 func (db *DB) GetActiveUsers(ctx context.Context, days int) ([]*models.User, error) {
 	since := time.Now().AddDate(0, 0, -days)
-	query := `SELECT id, telegram_id, username, first_name, last_name, 
-		phone, is_manager, is_blacklisted, language_code, 
-		last_activity, created_at, updated_at
+	query := `SELECT ` + userColumns + `
               FROM users WHERE last_activity >= ? ORDER BY last_activity DESC`
 	rows, err := db.QueryContext(ctx, query, since)
 	if err != nil {
@@ -152,11 +186,7 @@ func (db *DB) GetActiveUsers(ctx context.Context, days int) ([]*models.User, err
 
 	var users []*models.User
 	for rows.Next() {
-		u := &models.User{}
-		err := rows.Scan(
-			&u.ID, &u.TelegramID, &u.Username, &u.FirstName, &u.LastName, &u.Phone,
-			&u.IsManager, &u.IsBlacklisted, &u.LanguageCode, &u.LastActivity, &u.CreatedAt, &u.UpdatedAt,
-		)
+		u, err := scanUser(rows)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan user: %w", err)
 		}
@@ -164,3 +194,63 @@ func (db *DB) GetActiveUsers(ctx context.Context, days int) ([]*models.User, err
 	}
 	return users, nil
 }
+
+// GetBlacklistedUsers returns users currently flagged as blacklisted, including expired blocks.
+func (db *DB) GetBlacklistedUsers(ctx context.Context) ([]*models.User, error) {
+	query := `SELECT ` + userColumns + `
+              FROM users WHERE is_blacklisted = 1 ORDER BY updated_at DESC`
+	rows, err := db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get blacklisted users: %w", err)
+	}
+	defer rows.Close()
+
+	var users []*models.User
+	for rows.Next() {
+		u, err := scanUser(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan user: %w", err)
+		}
+		users = append(users, u)
+	}
+	return users, rows.Err()
+}
+
+// BlacklistUser blocks a user. A zero until means the block never expires.
+// Users that never talked to the bot get a placeholder record.
+func (db *DB) BlacklistUser(ctx context.Context, telegramID int64, reason string, until time.Time, blockedBy int64) error {
+	var untilValue sql.NullTime
+	if !until.IsZero() {
+		untilValue = sql.NullTime{Time: until, Valid: true}
+	}
+
+	now := time.Now()
+	query := `INSERT INTO users (
+				telegram_id, username, first_name, last_name, phone, language_code,
+				is_blacklisted, blacklist_reason, blacklisted_until, blacklisted_by,
+				last_activity, created_at, updated_at
+			) VALUES (?, '', '', '', '', '', 1, ?, ?, ?, ?, ?, ?)
+              ON CONFLICT(telegram_id) DO UPDATE SET
+                is_blacklisted = 1,
+                blacklist_reason = excluded.blacklist_reason,
+                blacklisted_until = excluded.blacklisted_until,
+                blacklisted_by = excluded.blacklisted_by,
+                updated_at = excluded.updated_at`
+	_, err := db.ExecContext(ctx, query, telegramID, reason, untilValue, blockedBy, now, now, now)
+	if err != nil {
+		return fmt.Errorf("failed to blacklist user: %w", err)
+	}
+	return nil
+}
+
+// UnblacklistUser lifts a block and clears its reason and expiry.
+func (db *DB) UnblacklistUser(ctx context.Context, telegramID int64) error {
+	query := `UPDATE users SET is_blacklisted = 0, blacklist_reason = '', blacklisted_until = NULL,
+                blacklisted_by = 0, updated_at = ?
+              WHERE telegram_id = ?`
+	_, err := db.ExecContext(ctx, query, time.Now(), telegramID)
+	if err != nil {
+		return fmt.Errorf("failed to unblacklist user: %w", err)
+	}
+	return nil
+}
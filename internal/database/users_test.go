@@ -109,6 +109,45 @@ func TestUserBlacklist(t *testing.T) {
 	require.NoError(t, err)
 	assert.True(t, found.IsBlacklisted)
 }
+
+func TestBlacklistUser(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	ctx := context.Background()
+
+	user := &models.User{TelegramID: 444, FirstName: "Client", LastActivity: time.Now()}
+	require.NoError(t, db.CreateOrUpdateUser(ctx, user))
+
+	until := time.Now().Add(72 * time.Hour)
+	require.NoError(t, db.BlacklistUser(ctx, 444, "no-show", until, 1))
+	// Пользователь, ещё не писавший боту
+	require.NoError(t, db.BlacklistUser(ctx, 555, "", time.Time{}, 1))
+
+	// Обновление профиля не снимает блокировку
+	user.FirstName = "Renamed"
+	require.NoError(t, db.CreateOrUpdateUser(ctx, user))
+
+	found, err := db.GetUserByTelegramID(ctx, 444)
+	require.NoError(t, err)
+	assert.True(t, found.IsBlacklisted)
+	assert.Equal(t, "Renamed", found.FirstName)
+	assert.Equal(t, "no-show", found.BlacklistReason)
+	assert.Equal(t, int64(1), found.BlacklistedBy)
+	require.True(t, found.BlacklistedUntil.Valid)
+	assert.WithinDuration(t, until, found.BlacklistedUntil.Time, time.Second)
+
+	blocked, err := db.GetBlacklistedUsers(ctx)
+	require.NoError(t, err)
+	assert.Len(t, blocked, 2)
+
+	require.NoError(t, db.UnblacklistUser(ctx, 444))
+	found, err = db.GetUserByTelegramID(ctx, 444)
+	require.NoError(t, err)
+	assert.False(t, found.IsBlacklisted)
+	assert.Empty(t, found.BlacklistReason)
+	assert.False(t, found.BlacklistedUntil.Valid)
+}
 func TestGetUserByID(t *testing.T) {
 	db := setupTestDB(t)
 	defer db.Close()
@@ -159,3 +198,47 @@ func TestGetActiveUsers(t *testing.T) {
 	assert.Len(t, active, 1)
 	assert.Equal(t, int64(555), active[0].TelegramID)
 }
+
+func TestUpdateUserLanguage(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	ctx := context.Background()
+
+	// Language chosen before /start creates a placeholder user
+	require.NoError(t, db.UpdateUserLanguage(ctx, 777, "en"))
+	found, err := db.GetUserByTelegramID(ctx, 777)
+	require.NoError(t, err)
+	assert.Equal(t, "en", found.PreferredLanguage)
+
+	// Saving the profile from Telegram must not reset the choice
+	require.NoError(t, db.CreateOrUpdateUser(ctx, &models.User{TelegramID: 777, FirstName: "Test", LanguageCode: "ru"}))
+	found, err = db.GetUserByTelegramID(ctx, 777)
+	require.NoError(t, err)
+	assert.Equal(t, "ru", found.LanguageCode)
+	assert.Equal(t, "en", found.PreferredLanguage)
+}
+
+func TestUpdateUserReminders(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	ctx := context.Background()
+
+	require.NoError(t, db.CreateOrUpdateUser(ctx, &models.User{TelegramID: 778, FirstName: "Test"}))
+	found, err := db.GetUserByTelegramID(ctx, 778)
+	require.NoError(t, err)
+	assert.False(t, found.RemindersDisabled)
+
+	require.NoError(t, db.UpdateUserReminders(ctx, 778, true))
+	require.NoError(t, db.CreateOrUpdateUser(ctx, &models.User{TelegramID: 778, FirstName: "Test"}))
+	found, err = db.GetUserByTelegramID(ctx, 778)
+	require.NoError(t, err)
+	assert.True(t, found.RemindersDisabled)
+	assert.Equal(t, "Test", found.FirstName)
+
+	require.NoError(t, db.UpdateUserReminders(ctx, 778, false))
+	found, err = db.GetUserByTelegramID(ctx, 778)
+	require.NoError(t, err)
+	assert.False(t, found.RemindersDisabled)
+}
@@ -16,21 +16,28 @@ type Repository interface {
 	UpdateBookingStatus(ctx context.Context, id int64, status string) error
 	UpdateBookingStatusWithVersion(ctx context.Context, id int64, version int64, status string) error
 	GetBookingsByDateRange(ctx context.Context, start, end time.Time) ([]*models.Booking, error)
+	SearchBookings(ctx context.Context, filter models.BookingFilter) ([]*models.Booking, error)
+	StreamBookings(ctx context.Context, filter models.BookingFilter, fn func(*models.Booking) error) error
+	ImportBookings(ctx context.Context, bookings []*models.Booking, dryRun bool) ([]error, error)
 	CheckAvailability(ctx context.Context, itemID int64, date time.Time) (bool, error)
 	GetAvailabilityForPeriod(ctx context.Context, itemID int64, startDate time.Time, days int) ([]*models.Availability, error)
 	GetActiveItems(ctx context.Context) ([]*models.Item, error)
 	GetItemByID(ctx context.Context, id int64) (*models.Item, error)
 	GetItemByName(ctx context.Context, name string) (*models.Item, error)
 	CreateItem(ctx context.Context, item *models.Item) error
+	ImportItems(ctx context.Context, items []*models.Item, dryRun bool) error
 	UpdateItem(ctx context.Context, item *models.Item) error
 	DeactivateItem(ctx context.Context, id int64) error
 	ReorderItem(ctx context.Context, id int64, newOrder int64) error
 	GetAllUsers(ctx context.Context) ([]*models.User, error)
+	ImportUsers(ctx context.Context, users []*models.User, dryRun bool) error
 	GetUserByTelegramID(ctx context.Context, telegramID int64) (*models.User, error)
 	GetUserByID(ctx context.Context, id int64) (*models.User, error)
 	CreateOrUpdateUser(ctx context.Context, user *models.User) error
 	UpdateUserActivity(ctx context.Context, telegramID int64) error
 	UpdateUserPhone(ctx context.Context, telegramID int64, phone string) error
+	UpdateUserLanguage(ctx context.Context, telegramID int64, lang string) error
+	UpdateUserReminders(ctx context.Context, telegramID int64, disabled bool) error
 	GetDailyBookings(ctx context.Context, start, end time.Time) (map[string][]*models.Booking, error)
 	GetBookedCount(ctx context.Context, itemID int64, date time.Time) (int, error)
 	GetBookingWithAvailability(ctx context.Context, id int64, newItemID int64) (*models.Booking, bool, error)
@@ -39,6 +46,47 @@ type Repository interface {
 	GetActiveUsers(ctx context.Context, days int) ([]*models.User, error)
 	GetUsersByManagerStatus(ctx context.Context, isManager bool) ([]*models.User, error)
 	GetUserBookings(ctx context.Context, userID int64) ([]*models.Booking, error)
+	CreateAuditEntry(ctx context.Context, entry *models.AuditEntry) error
+	GetAuditEntries(ctx context.Context, entityType string, entityID int64) ([]*models.AuditEntry, error)
+	GetUserRoles(ctx context.Context) ([]*models.UserRole, error)
+	SetUserRole(ctx context.Context, role *models.UserRole) error
+	DeleteUserRole(ctx context.Context, telegramID int64) error
+	GetBlacklistedUsers(ctx context.Context) ([]*models.User, error)
+	BlacklistUser(ctx context.Context, telegramID int64, reason string, until time.Time, blockedBy int64) error
+	UnblacklistUser(ctx context.Context, telegramID int64) error
+	SetUserConsent(ctx context.Context, telegramID int64) error
+	GetAllUserBookings(ctx context.Context, userID int64) ([]*models.Booking, error)
+	AnonymizeUser(ctx context.Context, telegramID int64) ([]int64, error)
+	GetBookingEscalations(ctx context.Context, bookingIDs []int64) (map[int64]*models.BookingEscalation, error)
+	SaveBookingEscalation(ctx context.Context, escalation *models.BookingEscalation) error
+	ClaimReminder(ctx context.Context, bookingID int64, key string) (bool, error)
+	ReleaseReminder(ctx context.Context, bookingID int64, key string) error
+	CreateHandover(ctx context.Context, handover *models.Handover) error
+	CheckInHandover(ctx context.Context, handover *models.Handover) error
+	FindHandover(ctx context.Context, userID, itemID int64, date time.Time) (*models.Handover, error)
+	GetUserHandovers(ctx context.Context, userID int64) ([]*models.Handover, error)
+	GetOpenHandovers(ctx context.Context) ([]*models.Handover, error)
+	GetHandoversByPeriod(ctx context.Context, start, end time.Time) ([]*models.Handover, error)
+	SetHandoverOverdueNotified(ctx context.Context, id int64, at time.Time) error
+	CreateMaintenance(ctx context.Context, window *models.MaintenanceWindow) error
+	GetMaintenance(ctx context.Context, id int64) (*models.MaintenanceWindow, error)
+	DeleteMaintenance(ctx context.Context, id int64) error
+	GetMaintenanceWindows(ctx context.Context, itemID int64, start, end time.Time) ([]*models.MaintenanceWindow, error)
+	GetExternalHolds(ctx context.Context, source string) ([]*models.MaintenanceWindow, error)
+	ReplaceExternalHolds(ctx context.Context, source string, windows []*models.MaintenanceWindow) error
+	CreateUnit(ctx context.Context, unit *models.ItemUnit) error
+	GetUnit(ctx context.Context, id int64) (*models.ItemUnit, error)
+	GetUnitBySerial(ctx context.Context, serial string) (*models.ItemUnit, error)
+	GetItemUnits(ctx context.Context, itemID int64) ([]*models.ItemUnit, error)
+	UpdateUnitStatus(ctx context.Context, id int64, status, note string) error
+	SetBookingUnit(ctx context.Context, bookingID, unitID int64) error
+	SetBookingPrice(ctx context.Context, bookingID, price, deposit int64) error
+	GetBusyUnitIDs(ctx context.Context, itemID int64, date time.Time) ([]int64, error)
+	GetUnitBookings(ctx context.Context, unitID int64) ([]*models.Booking, error)
+	CreateCalendarFeed(ctx context.Context, feed *models.CalendarFeed) error
+	GetCalendarFeed(ctx context.Context, id int64) (*models.CalendarFeed, error)
+	GetActiveCalendarFeed(ctx context.Context, scope string, subjectID int64) (*models.CalendarFeed, error)
+	RevokeCalendarFeeds(ctx context.Context, scope string, subjectID int64) (int64, error)
 }
 
 type StateRepository interface {
@@ -64,6 +112,7 @@ type TelegramSender interface {
 	Request(c tgbotapi.Chattable) (*tgbotapi.APIResponse, error)
 	GetUpdatesChan(config tgbotapi.UpdateConfig) tgbotapi.UpdatesChannel
 	GetSelf() tgbotapi.User
+	GetFileDirectURL(fileID string) (string, error)
 	StopReceivingUpdates()
 }
 
@@ -76,10 +125,12 @@ type SheetsWriter interface {
 		ctx context.Context,
 		startDate, endDate time.Time,
 		dailyBookings map[string][]*models.Booking,
+		maintenance []*models.MaintenanceWindow,
 		items []*models.Item,
 	) error
 	UpsertBooking(ctx context.Context, booking *models.Booking) error
 	UpdateBookingStatus(ctx context.Context, bookingID int64, status string) error
+	UpdateHandoversSheet(ctx context.Context, handovers []*models.Handover) error
 }
 
 type SyncWorker interface {
@@ -98,6 +149,7 @@ type TelegramService interface {
 	AnswerCallback(callbackID string, text string) error
 	GetUpdatesChan(config tgbotapi.UpdateConfig) tgbotapi.UpdatesChannel
 	GetSelf() tgbotapi.User
+	GetFileDirectURL(fileID string) (string, error)
 	StopReceivingUpdates()
 }
 
@@ -108,27 +160,68 @@ type BookingService interface {
 	RejectBooking(ctx context.Context, bookingID int64, version int64, managerID int64) error
 	CompleteBooking(ctx context.Context, bookingID int64, version int64, managerID int64) error
 	ReopenBooking(ctx context.Context, bookingID int64, version int64, managerID int64) error
+	ApplyBulkAction(ctx context.Context, action string, bookings []*models.Booking, managerID int64) (*models.BulkResult, error)
 	ChangeBookingItem(ctx context.Context, bookingID int64, version int64, newItemID int64, managerID int64) error
 	RescheduleBooking(ctx context.Context, bookingID int64, managerID int64) error
 	GetAvailability(ctx context.Context, itemID int64, startDate time.Time, days int) ([]*models.Availability, error)
 	CheckAvailability(ctx context.Context, itemID int64, date time.Time) (bool, error)
 	GetBookedCount(ctx context.Context, itemID int64, date time.Time) (int, error)
 	GetBookingsByDateRange(ctx context.Context, start, end time.Time) ([]*models.Booking, error)
+	SearchBookings(ctx context.Context, filter models.BookingFilter) ([]*models.Booking, error)
+	StreamBookings(ctx context.Context, filter models.BookingFilter, fn func(*models.Booking) error) error
+	ImportBookings(ctx context.Context, bookings []*models.Booking, dryRun bool) ([]error, error)
 	GetBooking(ctx context.Context, id int64) (*models.Booking, error)
 	GetDailyBookings(ctx context.Context, start, end time.Time) (map[string][]*models.Booking, error)
+	GetBookingHistory(ctx context.Context, bookingID int64) ([]*models.AuditEntry, error)
+	GetBookingEscalations(ctx context.Context, bookingIDs []int64) (map[int64]*models.BookingEscalation, error)
+	SaveBookingEscalation(ctx context.Context, escalation *models.BookingEscalation) error
+	ClaimReminder(ctx context.Context, bookingID int64, key string) (bool, error)
+	ReleaseReminder(ctx context.Context, bookingID int64, key string) error
+	CheckOutBooking(ctx context.Context, bookingID, managerID int64, note string, photos []string) (*models.Handover, error)
+	CheckInBooking(ctx context.Context, bookingID, managerID int64, note string, photos []string) (*models.Handover, error)
+	GetBookingHandover(ctx context.Context, booking *models.Booking) (*models.Handover, error)
+	GetBookingQuote(ctx context.Context, booking *models.Booking) (*models.BookingQuote, error)
+	GetOpenHandovers(ctx context.Context) ([]*models.Handover, error)
+	GetHandoversByPeriod(ctx context.Context, start, end time.Time) ([]*models.Handover, error)
+	MarkHandoverOverdueNotified(ctx context.Context, id int64, at time.Time) error
 }
 
 type UserService interface {
 	IsManager(userID int64) bool
+	GetRole(userID int64) string
+	HasPermission(userID int64, perm string) bool
+	CanManageItem(userID, itemID int64) bool
+	ItemScope(userID int64) (itemIDs []int64, scoped bool)
+	GetStaffForItem(itemID int64, perm string) []int64
+	LoadRoles(ctx context.Context) error
+	ListUserRoles(ctx context.Context) ([]*models.UserRole, error)
+	SetUserRole(ctx context.Context, role *models.UserRole) error
+	RemoveUserRole(ctx context.Context, telegramID int64) error
 	IsBlacklisted(userID int64) bool
+	GetBlacklistInfo(userID int64) (*models.User, bool)
+	LoadBlacklist(ctx context.Context) error
+	ListBlacklistedUsers(ctx context.Context) ([]*models.User, error)
+	BlockUser(ctx context.Context, telegramID int64, reason string, until time.Time, blockedBy int64) error
+	UnblockUser(ctx context.Context, telegramID int64) error
+	HasConsent(ctx context.Context, telegramID int64) bool
+	GiveConsent(ctx context.Context, telegramID int64) error
+	ExportPersonalData(ctx context.Context, telegramID int64) (*models.PersonalDataExport, error)
+	ForgetUser(ctx context.Context, telegramID int64) ([]*models.Booking, error)
 	SaveUser(ctx context.Context, user *models.User) error
 	UpdateUserPhone(ctx context.Context, telegramID int64, phone string) error
 	UpdateUserActivity(ctx context.Context, telegramID int64) error
+	GetLanguage(ctx context.Context, telegramID int64) string
+	SetLanguage(ctx context.Context, telegramID int64, lang string) error
+	RemindersEnabled(ctx context.Context, telegramID int64) bool
+	SetRemindersEnabled(ctx context.Context, telegramID int64, enabled bool) error
 	GetAllUsers(ctx context.Context) ([]*models.User, error)
+	ImportUsers(ctx context.Context, users []*models.User, dryRun bool) error
 	GetActiveUsers(ctx context.Context, days int) ([]*models.User, error)
 	GetManagers(ctx context.Context) ([]*models.User, error)
 	GetUserBookings(ctx context.Context, userID int64) ([]*models.Booking, error)
 	GetUserByID(ctx context.Context, id int64) (*models.User, error)
+	GetCalendarFeed(ctx context.Context, scope string, subjectID, createdBy int64) (*models.CalendarFeed, error)
+	ResetCalendarFeed(ctx context.Context, scope string, subjectID, createdBy int64) (*models.CalendarFeed, error)
 }
 
 type ItemService interface {
@@ -136,7 +229,20 @@ type ItemService interface {
 	GetItemByID(ctx context.Context, id int64) (*models.Item, error)
 	GetItemByName(ctx context.Context, name string) (*models.Item, error)
 	CreateItem(ctx context.Context, item *models.Item) error
+	ImportItems(ctx context.Context, items []*models.Item, dryRun bool) error
 	UpdateItem(ctx context.Context, item *models.Item) error
 	DeactivateItem(ctx context.Context, id int64) error
 	ReorderItem(ctx context.Context, id int64, newOrder int64) error
+	GetItemHistory(ctx context.Context, id int64) ([]*models.AuditEntry, error)
+	AddMaintenance(ctx context.Context, window *models.MaintenanceWindow) ([]*models.MaintenanceOverflow, error)
+	EndMaintenance(ctx context.Context, id, actorID int64) (*models.MaintenanceWindow, error)
+	GetMaintenance(ctx context.Context, id int64) (*models.MaintenanceWindow, error)
+	GetMaintenanceWindows(ctx context.Context, itemID int64, start, end time.Time) ([]*models.MaintenanceWindow, error)
+	SyncExternalHolds(ctx context.Context, source string, windows []*models.MaintenanceWindow) ([]*models.MaintenanceOverflow, error)
+	AddUnit(ctx context.Context, unit *models.ItemUnit, actorID int64) error
+	SetUnitStatus(ctx context.Context, serial, status, note string, actorID int64) (*models.ItemUnit, error)
+	GetUnit(ctx context.Context, id int64) (*models.ItemUnit, error)
+	GetUnitBySerial(ctx context.Context, serial string) (*models.ItemUnit, error)
+	GetItemUnits(ctx context.Context, itemID int64) ([]*models.ItemUnit, error)
+	GetUnitHistory(ctx context.Context, serial string) (*models.UnitHistory, error)
 }
@@ -0,0 +1,238 @@
+// Package exports writes flat lists of bookings with all their fields as CSV,
+// JSON Lines or XLSX. Writers accept bookings one by one, so an export can be
+// streamed from the database without loading it into memory.
+package exports
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"bronivik/internal/models"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// Output formats accepted by NewBookingWriter.
+const (
+	FormatCSV   = "csv"
+	FormatJSONL = "jsonl"
+	FormatXLSX  = "xlsx"
+)
+
+// columns are the fields of an exported booking, in output order.
+var columns = []string{
+	"id", "date", "status", "item_id", "item_name", "unit_id",
+	"user_id", "user_name", "user_nickname", "phone",
+	"price", "deposit", "comment", "created_at", "updated_at",
+}
+
+// record is the JSON Lines representation of a booking. Unlike models.Booking
+// it keeps every field, and the date has no time part.
+type record struct {
+	ID           int64     `json:"id"`
+	Date         string    `json:"date"`
+	Status       string    `json:"status"`
+	ItemID       int64     `json:"item_id"`
+	ItemName     string    `json:"item_name"`
+	UnitID       int64     `json:"unit_id"`
+	UserID       int64     `json:"user_id"`
+	UserName     string    `json:"user_name"`
+	UserNickname string    `json:"user_nickname"`
+	Phone        string    `json:"phone"`
+	Price        int64     `json:"price"`
+	Deposit      int64     `json:"deposit"`
+	Comment      string    `json:"comment"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+// BookingWriter writes bookings in one of the export formats.
+// Close must be called to complete the output; it does not close the underlying writer.
+// Flush passes buffered rows to the underlying writer; XLSX rows are only written on Close.
+type BookingWriter interface {
+	Write(b *models.Booking) error
+	Flush() error
+	Close() error
+}
+
+// ParseFormat validates an export format; an empty string means CSV.
+func ParseFormat(s string) (string, error) {
+	switch s = strings.ToLower(strings.TrimSpace(s)); s {
+	case "":
+		return FormatCSV, nil
+	case FormatCSV, FormatJSONL, FormatXLSX:
+		return s, nil
+	}
+	return "", fmt.Errorf("unsupported format %q", s)
+}
+
+// ContentType returns the MIME type of the format.
+func ContentType(format string) string {
+	switch format {
+	case FormatJSONL:
+		return "application/x-ndjson"
+	case FormatXLSX:
+		return "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	}
+	return "text/csv; charset=utf-8"
+}
+
+// Filename names an export of the filter's period, or of the export time if the period is open.
+func Filename(format string, filter *models.BookingFilter, now time.Time) string {
+	if filter.DateFrom.IsZero() || filter.DateTo.IsZero() {
+		return fmt.Sprintf("bookings_%s.%s", now.Format("2006-01-02_15-04-05"), format)
+	}
+	return fmt.Sprintf("bookings_%s_%s.%s", filter.DateFrom.Format("2006-01-02"), filter.DateTo.Format("2006-01-02"), format)
+}
+
+// NewBookingWriter returns a writer of the format to w. The header, if the format has
+// one, is written immediately.
+func NewBookingWriter(w io.Writer, format string) (BookingWriter, error) {
+	switch format {
+	case FormatCSV:
+		return newCSVWriter(w)
+	case FormatJSONL:
+		return &jsonlWriter{enc: json.NewEncoder(w)}, nil
+	case FormatXLSX:
+		return newXLSXWriter(w)
+	}
+	return nil, fmt.Errorf("unsupported format %q", format)
+}
+
+func toRecord(b *models.Booking) record {
+	return record{
+		ID: b.ID, Date: b.Date.Format("2006-01-02"), Status: b.Status,
+		ItemID: b.ItemID, ItemName: b.ItemName, UnitID: b.UnitID,
+		UserID: b.UserID, UserName: b.UserName, UserNickname: b.UserNickname, Phone: b.Phone,
+		Price: b.Price, Deposit: b.Deposit, Comment: b.Comment,
+		CreatedAt: b.CreatedAt, UpdatedAt: b.UpdatedAt,
+	}
+}
+
+type csvWriter struct {
+	w *csv.Writer
+}
+
+func newCSVWriter(w io.Writer) (*csvWriter, error) {
+	cw := &csvWriter{w: csv.NewWriter(w)}
+	if err := cw.w.Write(columns); err != nil {
+		return nil, err
+	}
+	return cw, nil
+}
+
+func (c *csvWriter) Write(b *models.Booking) error {
+	r := toRecord(b)
+	return c.w.Write([]string{
+		strconv.FormatInt(r.ID, 10), r.Date, r.Status,
+		strconv.FormatInt(r.ItemID, 10), r.ItemName, strconv.FormatInt(r.UnitID, 10),
+		strconv.FormatInt(r.UserID, 10), r.UserName, r.UserNickname, r.Phone,
+		strconv.FormatInt(r.Price, 10), strconv.FormatInt(r.Deposit, 10), r.Comment,
+		r.CreatedAt.Format(time.RFC3339), r.UpdatedAt.Format(time.RFC3339),
+	})
+}
+
+func (c *csvWriter) Flush() error {
+	c.w.Flush()
+	return c.w.Error()
+}
+
+func (c *csvWriter) Close() error {
+	return c.Flush()
+}
+
+type jsonlWriter struct {
+	enc *json.Encoder
+}
+
+func (j *jsonlWriter) Write(b *models.Booking) error {
+	return j.enc.Encode(toRecord(b))
+}
+
+func (j *jsonlWriter) Flush() error { return nil }
+
+func (j *jsonlWriter) Close() error { return nil }
+
+// xlsxWriter uses excelize's stream writer, which moves rows to a temporary file once
+// they outgrow a small buffer. The workbook can only be written out as a whole on Close.
+type xlsxWriter struct {
+	out      io.Writer
+	f        *excelize.File
+	sw       *excelize.StreamWriter
+	row      int
+	date     int
+	dateTime int
+}
+
+const xlsxSheet = "Bookings"
+
+func newXLSXWriter(w io.Writer) (*xlsxWriter, error) {
+	f := excelize.NewFile()
+	x := &xlsxWriter{out: w, f: f, row: 1}
+	if err := x.init(); err != nil {
+		f.Close()
+		return nil, err
+	}
+	return x, nil
+}
+
+func (x *xlsxWriter) init() error {
+	if err := x.f.SetSheetName("Sheet1", xlsxSheet); err != nil {
+		return err
+	}
+	var err error
+	if x.date, err = x.f.NewStyle(&excelize.Style{NumFmt: 14}); err != nil { // dd.mm.yyyy
+		return err
+	}
+	if x.dateTime, err = x.f.NewStyle(&excelize.Style{NumFmt: 22}); err != nil { // dd.mm.yyyy hh:mm
+		return err
+	}
+	bold, err := x.f.NewStyle(&excelize.Style{Font: &excelize.Font{Bold: true}})
+	if err != nil {
+		return err
+	}
+	if x.sw, err = x.f.NewStreamWriter(xlsxSheet); err != nil {
+		return err
+	}
+	if err := x.sw.SetPanes(&excelize.Panes{Freeze: true, YSplit: 1, TopLeftCell: "A2", ActivePane: "bottomLeft"}); err != nil {
+		return err
+	}
+	header := make([]interface{}, len(columns))
+	for i, c := range columns {
+		header[i] = excelize.Cell{StyleID: bold, Value: c}
+	}
+	return x.sw.SetRow("A1", header)
+}
+
+func (x *xlsxWriter) Write(b *models.Booking) error {
+	r := toRecord(b)
+	x.row++
+	cell, err := excelize.CoordinatesToCellName(1, x.row)
+	if err != nil {
+		return err
+	}
+	return x.sw.SetRow(cell, []interface{}{
+		r.ID, excelize.Cell{StyleID: x.date, Value: b.Date}, r.Status,
+		r.ItemID, r.ItemName, r.UnitID,
+		r.UserID, r.UserName, r.UserNickname, r.Phone,
+		r.Price, r.Deposit, r.Comment,
+		excelize.Cell{StyleID: x.dateTime, Value: r.CreatedAt},
+		excelize.Cell{StyleID: x.dateTime, Value: r.UpdatedAt},
+	})
+}
+
+func (x *xlsxWriter) Flush() error { return nil }
+
+func (x *xlsxWriter) Close() error {
+	defer x.f.Close()
+	if err := x.sw.Flush(); err != nil {
+		return err
+	}
+	_, err := x.f.WriteTo(x.out)
+	return err
+}
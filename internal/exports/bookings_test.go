@@ -0,0 +1,106 @@
+package exports
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"bronivik/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xuri/excelize/v2"
+)
+
+func testBookings() []*models.Booking {
+	created := time.Date(2030, 3, 1, 12, 30, 0, 0, time.UTC)
+	return []*models.Booking{
+		{
+			ID: 1, Date: time.Date(2030, 3, 10, 0, 0, 0, 0, time.UTC), Status: models.StatusConfirmed,
+			ItemID: 2, ItemName: "Camera", UnitID: 5, UserID: 7, UserName: "Иван, Петров", Phone: "79991234567",
+			Price: 1500, Deposit: 5000, Comment: "с \"кофром\"", CreatedAt: created, UpdatedAt: created,
+		},
+		{ID: 2, Date: time.Date(2030, 3, 11, 0, 0, 0, 0, time.UTC), Status: models.StatusPending, ItemName: "Light"},
+	}
+}
+
+func write(t *testing.T, format string) []byte {
+	var buf bytes.Buffer
+	w, err := NewBookingWriter(&buf, format)
+	require.NoError(t, err)
+	for _, b := range testBookings() {
+		require.NoError(t, w.Write(b))
+	}
+	require.NoError(t, w.Close())
+	return buf.Bytes()
+}
+
+func TestCSV(t *testing.T) {
+	rows, err := csv.NewReader(bytes.NewReader(write(t, FormatCSV))).ReadAll()
+	require.NoError(t, err)
+	require.Len(t, rows, 3)
+	assert.Equal(t, columns, rows[0])
+	assert.Equal(t, []string{
+		"1", "2030-03-10", "confirmed", "2", "Camera", "5", "7", "Иван, Петров", "", "79991234567",
+		"1500", "5000", "с \"кофром\"", "2030-03-01T12:30:00Z", "2030-03-01T12:30:00Z",
+	}, rows[1])
+}
+
+func TestJSONL(t *testing.T) {
+	scanner := bufio.NewScanner(bytes.NewReader(write(t, FormatJSONL)))
+	var lines []map[string]interface{}
+	for scanner.Scan() {
+		var m map[string]interface{}
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &m))
+		lines = append(lines, m)
+	}
+	require.Len(t, lines, 2)
+	assert.Equal(t, "2030-03-10", lines[0]["date"])
+	assert.Equal(t, "Иван, Петров", lines[0]["user_name"])
+	assert.Len(t, lines[1], len(columns), "zero fields are kept")
+}
+
+func TestXLSX(t *testing.T) {
+	f, err := excelize.OpenReader(bytes.NewReader(write(t, FormatXLSX)))
+	require.NoError(t, err)
+	defer f.Close()
+
+	rows, err := f.GetRows(xlsxSheet)
+	require.NoError(t, err)
+	require.Len(t, rows, 3)
+	assert.Equal(t, columns, rows[0])
+	assert.Equal(t, "Camera", rows[1][4])
+	assert.Equal(t, "1500", rows[1][10])
+}
+
+func TestParseFormat(t *testing.T) {
+	format, err := ParseFormat("")
+	require.NoError(t, err)
+	assert.Equal(t, FormatCSV, format)
+	format, err = ParseFormat(" XLSX ")
+	require.NoError(t, err)
+	assert.Equal(t, FormatXLSX, format)
+	_, err = ParseFormat("pdf")
+	assert.Error(t, err)
+
+	now := time.Date(2030, 3, 1, 9, 5, 0, 0, time.UTC)
+	filter := &models.BookingFilter{DateFrom: now, DateTo: now.AddDate(0, 0, 30)}
+	assert.Equal(t, "bookings_2030-03-01_2030-03-31.jsonl", Filename(FormatJSONL, filter, now))
+	assert.Equal(t, "bookings_2030-03-01_09-05-00.csv", Filename(FormatCSV, &models.BookingFilter{}, now))
+}
+
+func TestCSV_Flush(t *testing.T) {
+	var buf bytes.Buffer
+	w, err := NewBookingWriter(&buf, FormatCSV)
+	require.NoError(t, err)
+	require.NoError(t, w.Write(testBookings()[0]))
+	assert.Zero(t, buf.Len(), "rows are buffered until Flush")
+
+	require.NoError(t, w.Flush())
+	rows, err := csv.NewReader(bytes.NewReader(buf.Bytes())).ReadAll()
+	require.NoError(t, err)
+	assert.Len(t, rows, 2)
+}
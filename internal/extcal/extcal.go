@@ -0,0 +1,178 @@
+// Package extcal imports external iCalendar feeds, such as a partner's booking
+// calendar, as holds that take item units out of the bookable capacity.
+package extcal
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"time"
+
+	"bronivik/internal/config"
+	"bronivik/internal/ical"
+	"bronivik/internal/models"
+
+	"github.com/rs/zerolog"
+)
+
+const (
+	// fetchTimeout limits one download of a remote calendar.
+	fetchTimeout = 30 * time.Second
+
+	// maxCalendarSize protects against huge or endless responses.
+	maxCalendarSize = 10 << 20
+
+	// recurrenceHorizonDays limits how far ahead recurring events are expanded.
+	// It covers the default booking window.
+	recurrenceHorizonDays = 366
+)
+
+// ErrCalendarTooLarge is returned when a feed exceeds the size limit. The feed
+// is rejected as a whole so that a truncated calendar never replaces the holds.
+var ErrCalendarTooLarge = errors.New("calendar is too large")
+
+// HoldStore replaces the holds imported from a source. It is implemented by domain.ItemService.
+type HoldStore interface {
+	SyncExternalHolds(ctx context.Context, source string, windows []*models.MaintenanceWindow) ([]*models.MaintenanceOverflow, error)
+}
+
+// Result reports one source sync. Overflow lists days where the new holds left
+// existing bookings without enough units.
+type Result struct {
+	Source   config.ExternalCalendarSource
+	Holds    int
+	Overflow []*models.MaintenanceOverflow
+	Err      error
+}
+
+// Syncer polls the configured calendars.
+type Syncer struct {
+	sources []config.ExternalCalendarSource
+	store   HoldStore
+	client  *http.Client
+	logger  *zerolog.Logger
+	now     func() time.Time
+	maxSize int64
+}
+
+// NewSyncer creates a syncer for the sources of cfg.
+func NewSyncer(cfg config.ExternalCalendarsConfig, store HoldStore, logger *zerolog.Logger) *Syncer {
+	return &Syncer{
+		sources: cfg.Sources,
+		store:   store,
+		client:  &http.Client{Timeout: fetchTimeout},
+		logger:  logger,
+		now:     time.Now,
+		maxSize: maxCalendarSize,
+	}
+}
+
+// SyncAll imports every source. A source that cannot be fetched or parsed keeps
+// its previous holds until the next successful sync.
+func (s *Syncer) SyncAll(ctx context.Context) []Result {
+	results := make([]Result, 0, len(s.sources))
+	for _, src := range s.sources {
+		res := s.Sync(ctx, src)
+		if res.Err != nil {
+			s.logger.Error().Err(res.Err).Str("source", src.Name).Msg("external calendar sync failed")
+		}
+		results = append(results, res)
+	}
+	return results
+}
+
+// Sync imports one source.
+func (s *Syncer) Sync(ctx context.Context, src config.ExternalCalendarSource) Result {
+	res := Result{Source: src}
+	events, err := s.fetch(ctx, src)
+	if err != nil {
+		res.Err = err
+		return res
+	}
+
+	windows := Windows(src, events, s.now())
+	res.Holds = len(windows)
+	res.Overflow, res.Err = s.store.SyncExternalHolds(ctx, src.Name, windows)
+	return res
+}
+
+// Windows converts events to holds on the source's item. Events that ended
+// before today are dropped.
+func Windows(src config.ExternalCalendarSource, events []ical.BusyEvent, now time.Time) []*models.MaintenanceWindow {
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	units := src.Units
+	if units <= 0 {
+		units = 1
+	}
+
+	windows := make([]*models.MaintenanceWindow, 0, len(events))
+	for _, e := range events {
+		if e.End.Before(today) {
+			continue
+		}
+		windows = append(windows, &models.MaintenanceWindow{
+			ItemID:    src.ItemID,
+			Units:     units,
+			StartDate: e.Start,
+			EndDate:   e.End,
+			Reason:    e.Summary,
+			Source:    src.Name,
+		})
+	}
+	return windows
+}
+
+func (s *Syncer) fetch(ctx context.Context, src config.ExternalCalendarSource) ([]ical.BusyEvent, error) {
+	body, err := s.open(ctx, src)
+	if err != nil {
+		return nil, err
+	}
+	defer body.Close()
+
+	data, err := io.ReadAll(io.LimitReader(body, s.maxSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read calendar %s: %w", src.Name, err)
+	}
+	if int64(len(data)) > s.maxSize {
+		return nil, fmt.Errorf("calendar %s exceeds %d bytes: %w", src.Name, s.maxSize, ErrCalendarTooLarge)
+	}
+
+	horizon := s.now().AddDate(0, 0, recurrenceHorizonDays)
+	events, skipped, err := ical.Parse(bytes.NewReader(data), time.Local, horizon)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse calendar %s: %w", src.Name, err)
+	}
+	if len(skipped) > 0 {
+		s.logger.Warn().Str("source", src.Name).Strs("uids", skipped).
+			Msg("external calendar events with unsupported recurrence rules skipped")
+	}
+	return events, nil
+}
+
+func (s *Syncer) open(ctx context.Context, src config.ExternalCalendarSource) (io.ReadCloser, error) {
+	if src.File != "" {
+		f, err := os.Open(src.File)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open calendar %s: %w", src.Name, err)
+		}
+		return f, nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, src.URL, http.NoBody)
+	if err != nil {
+		return nil, fmt.Errorf("invalid calendar url %s: %w", src.Name, err)
+	}
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch calendar %s: %w", src.Name, err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, fmt.Errorf("failed to fetch calendar %s: status %d", src.Name, resp.StatusCode)
+	}
+	return resp.Body, nil
+}
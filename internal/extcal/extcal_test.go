@@ -0,0 +1,92 @@
+package extcal
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"bronivik/internal/config"
+	"bronivik/internal/models"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testCalendar = "BEGIN:VCALENDAR\r\n" +
+	"BEGIN:VEVENT\r\nUID:old\r\nSUMMARY:Past\r\nDTSTART;VALUE=DATE:20300101\r\nDTEND;VALUE=DATE:20300102\r\nEND:VEVENT\r\n" +
+	"BEGIN:VEVENT\r\nUID:wedding\r\nSUMMARY:Wedding\r\nDTSTART;VALUE=DATE:20300510\r\nDTEND;VALUE=DATE:20300512\r\nEND:VEVENT\r\n" +
+	"END:VCALENDAR\r\n"
+
+type fakeStore struct {
+	synced map[string][]*models.MaintenanceWindow
+}
+
+func (f *fakeStore) SyncExternalHolds(
+	_ context.Context,
+	source string,
+	windows []*models.MaintenanceWindow,
+) ([]*models.MaintenanceOverflow, error) {
+	f.synced[source] = windows
+	return []*models.MaintenanceOverflow{{Date: windows[0].StartDate}}, nil
+}
+
+func TestSyncer(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "partner.ics")
+	require.NoError(t, os.WriteFile(path, []byte(testCalendar), 0o600))
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/cal.ics":
+			_, _ = w.Write([]byte(testCalendar))
+		case "/huge.ics":
+			// Truncating this feed at the limit would still leave a valid calendar.
+			_, _ = w.Write([]byte(testCalendar + strings.Repeat("X-PAD:x\r\n", 10)))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(ts.Close)
+
+	cfg := config.ExternalCalendarsConfig{Sources: []config.ExternalCalendarSource{
+		{Name: "file", ItemID: 1, File: path, Units: 2},
+		{Name: "url", ItemID: 2, URL: ts.URL + "/cal.ics"},
+		{Name: "broken", ItemID: 3, URL: ts.URL + "/missing.ics"},
+		{Name: "huge", ItemID: 4, URL: ts.URL + "/huge.ics"},
+	}}
+	store := &fakeStore{synced: make(map[string][]*models.MaintenanceWindow)}
+	logger := zerolog.Nop()
+	s := NewSyncer(cfg, store, &logger)
+	s.now = func() time.Time { return time.Date(2030, 3, 1, 12, 0, 0, 0, time.UTC) }
+	s.maxSize = int64(len(testCalendar))
+
+	results := s.SyncAll(context.Background())
+	require.Len(t, results, 4)
+
+	require.NoError(t, results[0].Err)
+	assert.Equal(t, 1, results[0].Holds, "past events are dropped")
+	assert.Len(t, results[0].Overflow, 1)
+	hold := store.synced["file"][0]
+	assert.Equal(t, int64(1), hold.ItemID)
+	assert.Equal(t, int64(2), hold.Units)
+	assert.Equal(t, time.Date(2030, 5, 10, 0, 0, 0, 0, time.UTC), hold.StartDate)
+	assert.Equal(t, time.Date(2030, 5, 11, 0, 0, 0, 0, time.UTC), hold.EndDate)
+	assert.Equal(t, "Wedding", hold.Reason)
+	assert.Equal(t, "file", hold.Source)
+
+	require.NoError(t, results[1].Err)
+	assert.Equal(t, int64(1), store.synced["url"][0].Units, "one unit by default")
+
+	assert.Error(t, results[2].Err)
+	_, synced := store.synced["broken"]
+	assert.False(t, synced, "a failed fetch keeps the previous holds")
+
+	assert.ErrorIs(t, results[3].Err, ErrCalendarTooLarge)
+	_, synced = store.synced["huge"]
+	assert.False(t, synced, "an oversized feed keeps the previous holds")
+}
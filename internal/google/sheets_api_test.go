@@ -2,6 +2,7 @@ package google
 
 import (
 	"context"
+	"database/sql"
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
@@ -44,15 +45,26 @@ func TestSheetsService_UpdateUsersSheet(t *testing.T) {
 	ctx := context.Background()
 	mux, server, s := setupMockServer(ctx)
 	defer server.Close()
-	mux.HandleFunc("/v4/spreadsheets/users_tid/values/Users!A1:K2", func(w http.ResponseWriter, r *http.Request) {
+	var sent sheets.ValueRange
+	mux.HandleFunc("/v4/spreadsheets/users_tid/values/Users!A:M:clear", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(sheets.ClearValuesResponse{})
+	})
+	mux.HandleFunc("/v4/spreadsheets/users_tid/values/Users!A1:M2", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewDecoder(r.Body).Decode(&sent)
 		w.WriteHeader(http.StatusOK)
 		_ = json.NewEncoder(w).Encode(sheets.UpdateValuesResponse{})
 	})
-	users := []*models.User{{ID: 1, Username: "test", CreatedAt: time.Now(), LastActivity: time.Now()}}
+	users := []*models.User{{
+		ID: 1, Username: "test", CreatedAt: time.Now(), LastActivity: time.Now(),
+		IsBlacklisted: true, BlacklistReason: "spam",
+	}}
 	err := s.UpdateUsersSheet(ctx, users)
 	if err != nil {
 		t.Errorf("UpdateUsersSheet failed: %v", err)
 	}
+	if len(sent.Values) != 2 || sent.Values[1][11] != "spam" {
+		t.Errorf("expected blacklist reason in users sheet, got %v", sent.Values)
+	}
 }
 
 func TestSheetsService_WarmUpCache(t *testing.T) {
@@ -80,7 +92,7 @@ func TestSheetsService_AppendBooking(t *testing.T) {
 	mux.HandleFunc("/v4/spreadsheets/bookings_tid/values/Bookings!A:A:append", func(w http.ResponseWriter, r *http.Request) {
 		_ = json.NewEncoder(w).Encode(sheets.AppendValuesResponse{
 			Updates: &sheets.UpdateValuesResponse{
-				UpdatedRange: "Bookings!A10:J10",
+				UpdatedRange: "Bookings!A10:K10",
 			},
 		})
 	})
@@ -99,7 +111,7 @@ func TestSheetsService_UpsertBooking_Update(t *testing.T) {
 	mux, server, s := setupMockServer(ctx)
 	defer server.Close()
 	s.setCachedRow(123, 2)
-	mux.HandleFunc("/v4/spreadsheets/bookings_tid/values/Bookings!A2:J2", func(w http.ResponseWriter, r *http.Request) {
+	mux.HandleFunc("/v4/spreadsheets/bookings_tid/values/Bookings!A2:K2", func(w http.ResponseWriter, r *http.Request) {
 		_ = json.NewEncoder(w).Encode(sheets.UpdateValuesResponse{})
 	})
 	booking := &models.Booking{ID: 123, Date: time.Now(), CreatedAt: time.Now(), UpdatedAt: time.Now()}
@@ -114,7 +126,7 @@ func TestSheetsService_DeleteBookingRow(t *testing.T) {
 	mux, server, s := setupMockServer(ctx)
 	defer server.Close()
 	s.setCachedRow(456, 3)
-	mux.HandleFunc("/v4/spreadsheets/bookings_tid/values/Bookings!A3:J3:clear", func(w http.ResponseWriter, r *http.Request) {
+	mux.HandleFunc("/v4/spreadsheets/bookings_tid/values/Bookings!A3:K3:clear", func(w http.ResponseWriter, r *http.Request) {
 		_ = json.NewEncoder(w).Encode(sheets.ClearValuesResponse{})
 	})
 	err := s.DeleteBookingRow(ctx, 456)
@@ -172,7 +184,7 @@ func TestSheetsService_UpdateBookingsSheet(t *testing.T) {
 	ctx := context.Background()
 	mux, server, s := setupMockServer(ctx)
 	defer server.Close()
-	mux.HandleFunc("/v4/spreadsheets/bookings_tid/values/Bookings!A1:J2", func(w http.ResponseWriter, r *http.Request) {
+	mux.HandleFunc("/v4/spreadsheets/bookings_tid/values/Bookings!A1:K2", func(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusOK)
 		_ = json.NewEncoder(w).Encode(sheets.UpdateValuesResponse{})
 	})
@@ -183,6 +195,36 @@ func TestSheetsService_UpdateBookingsSheet(t *testing.T) {
 	}
 }
 
+func TestSheetsService_UpdateHandoversSheet(t *testing.T) {
+	ctx := context.Background()
+	mux, server, s := setupMockServer(ctx)
+	defer server.Close()
+	var sent sheets.ValueRange
+	mux.HandleFunc("/v4/spreadsheets/bookings_tid/values/Handovers!A:N:clear", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(sheets.ClearValuesResponse{})
+	})
+	mux.HandleFunc("/v4/spreadsheets/bookings_tid/values/Handovers!A1:N2", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewDecoder(r.Body).Decode(&sent)
+		w.WriteHeader(http.StatusOK)
+		_ = json.NewEncoder(w).Encode(sheets.UpdateValuesResponse{})
+	})
+	handovers := []*models.Handover{{
+		ID: 1, BookingID: 10, UserName: "test", ItemName: "Camera",
+		PlannedStart: time.Date(2030, 3, 1, 0, 0, 0, 0, time.UTC),
+		PlannedEnd:   time.Date(2030, 3, 2, 0, 0, 0, 0, time.UTC),
+		CheckedOutAt: time.Date(2030, 3, 1, 10, 0, 0, 0, time.UTC),
+		CheckedInAt:  sql.NullTime{Time: time.Date(2030, 3, 5, 9, 0, 0, 0, time.UTC), Valid: true},
+		CheckedInBy:  7,
+	}}
+	err := s.UpdateHandoversSheet(ctx, handovers)
+	if err != nil {
+		t.Errorf("UpdateHandoversSheet failed: %v", err)
+	}
+	if len(sent.Values) != 2 || sent.Values[1][5] != "2030-03-03" || sent.Values[1][8] != float64(2) {
+		t.Errorf("expected planned return and delay in handovers sheet, got %v", sent.Values)
+	}
+}
+
 func TestSheetsService_ReplaceBookingsSheet(t *testing.T) {
 	ctx := context.Background()
 	mux, server, s := setupMockServer(ctx)
@@ -236,7 +278,7 @@ func TestSheetsService_UpdateScheduleSheet(t *testing.T) {
 	}
 	items := []*models.Item{{ID: 1, Name: "Item 1", TotalQuantity: 5}}
 
-	err := s.UpdateScheduleSheet(ctx, startDate, endDate, dailyBookings, items)
+	err := s.UpdateScheduleSheet(ctx, startDate, endDate, dailyBookings, nil, items)
 	if err != nil {
 		t.Errorf("UpdateScheduleSheet failed: %v", err)
 	}
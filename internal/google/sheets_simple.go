@@ -114,12 +114,21 @@ func (s *SheetsService) UpdateUsersSheet(ctx context.Context, users []*models.Us
 	headers := []interface{}{
 		"ID", "Telegram ID", "Username", "First Name", "Last Name",
 		"Phone", "Is Manager", "Is Blacklisted", "Language Code",
-		"Last Activity", "Created At",
+		"Last Activity", "Created At", "Blacklist Reason", "Blacklisted Until",
 	}
 	values = append(values, headers)
 
 	// Данные пользователей
+	now := time.Now()
 	for _, user := range users {
+		blocked := user.IsBlockedAt(now)
+		reason, until := "", ""
+		if blocked {
+			reason = user.BlacklistReason
+			if user.BlacklistedUntil.Valid {
+				until = user.BlacklistedUntil.Time.Format("2006-01-02 15:04:05")
+			}
+		}
 		row := []interface{}{
 			user.ID,
 			user.TelegramID,
@@ -128,22 +137,31 @@ func (s *SheetsService) UpdateUsersSheet(ctx context.Context, users []*models.Us
 			user.LastName,
 			user.Phone,
 			user.IsManager,
-			user.IsBlacklisted,
+			blocked,
 			user.LanguageCode,
 			user.LastActivity.Format("2006-01-02 15:04:05"),
 			user.CreatedAt.Format("2006-01-02 15:04:05"),
+			reason,
+			until,
 		}
 		values = append(values, row)
 	}
 
-	// Полностью очищаем и перезаписываем лист
-	rangeData := "Users!A1:K" + fmt.Sprintf("%d", len(values))
+	// Полностью очищаем и перезаписываем лист, чтобы не оставалось строк удаленных пользователей
+	_, err := s.service.Spreadsheets.Values.Clear(s.usersSheetID, "Users!A:M", &sheets.ClearValuesRequest{}).
+		Context(ctx).
+		Do()
+	if err != nil {
+		return fmt.Errorf("failed to clear users sheet: %w", err)
+	}
+
+	rangeData := "Users!A1:M" + fmt.Sprintf("%d", len(values))
 	valueRange := &sheets.ValueRange{
 		Values: values,
 	}
 
 	// Используем Overwrite для полной замены данных
-	_, err := s.service.Spreadsheets.Values.Update(s.usersSheetID, rangeData, valueRange).
+	_, err = s.service.Spreadsheets.Values.Update(s.usersSheetID, rangeData, valueRange).
 		ValueInputOption("RAW").
 		Context(ctx).
 		Do()
@@ -194,6 +212,7 @@ func (s *SheetsService) AppendBooking(ctx context.Context, booking *models.Booki
 		booking.ItemName,
 		booking.CreatedAt.Format("2006-01-02 15:04:05"),
 		booking.UpdatedAt.Format("2006-01-02 15:04:05"),
+		unitCellValue(booking.UnitID),
 	}
 
 	rangeData := "Bookings!A:A"
@@ -208,7 +227,7 @@ func (s *SheetsService) AppendBooking(ctx context.Context, booking *models.Booki
 		Do()
 
 	if err == nil && resp != nil && resp.Updates != nil {
-		// Parse row number from range like "Bookings!A10:J10"
+		// Parse row number from range like "Bookings!A10:K10"
 		var rowIdx int
 		if _, sErr := fmt.Sscanf(resp.Updates.UpdatedRange, "Bookings!A%d", &rowIdx); sErr == nil && rowIdx > 0 {
 			s.setCachedRow(booking.ID, rowIdx)
@@ -232,7 +251,7 @@ func (s *SheetsService) UpsertBooking(ctx context.Context, booking *models.Booki
 		return err
 	}
 
-	rangeData := fmt.Sprintf("Bookings!A%d:J%d", rowIdx, rowIdx)
+	rangeData := fmt.Sprintf("Bookings!A%d:K%d", rowIdx, rowIdx)
 	valueRange := &sheets.ValueRange{
 		Values: [][]interface{}{bookingRowValues(booking)},
 	}
@@ -251,7 +270,7 @@ func (s *SheetsService) DeleteBookingRow(ctx context.Context, bookingID int64) e
 		return err
 	}
 
-	rangeData := fmt.Sprintf("Bookings!A%d:J%d", rowIdx, rowIdx)
+	rangeData := fmt.Sprintf("Bookings!A%d:K%d", rowIdx, rowIdx)
 	_, err = s.service.Spreadsheets.Values.Clear(s.bookingsSheetID, rangeData, &sheets.ClearValuesRequest{}).
 		Context(ctx).
 		Do()
@@ -373,7 +392,16 @@ func bookingRowValues(booking *models.Booking) []interface{} {
 		booking.ItemName,
 		booking.CreatedAt.Format("2006-01-02 15:04:05"),
 		booking.UpdatedAt.Format("2006-01-02 15:04:05"),
+		unitCellValue(booking.UnitID),
+	}
+}
+
+// unitCellValue возвращает ID экземпляра или пустую ячейку, если экземпляр не закреплен
+func unitCellValue(unitID int64) interface{} {
+	if unitID == 0 {
+		return ""
 	}
+	return unitID
 }
 
 // UpdateBookingsSheet обновляет всю таблицу бронирований
@@ -383,7 +411,7 @@ func (s *SheetsService) UpdateBookingsSheet(ctx context.Context, bookings []*mod
 	// Заголовки
 	headers := []interface{}{
 		"ID", "User ID", "Item ID", "Date", "Status",
-		"User Name", "User Phone", "Item Name", "Created At", "Updated At",
+		"User Name", "User Phone", "Item Name", "Created At", "Updated At", "Unit ID",
 	}
 	values = append(values, headers)
 
@@ -400,12 +428,13 @@ func (s *SheetsService) UpdateBookingsSheet(ctx context.Context, bookings []*mod
 			booking.ItemName,
 			booking.CreatedAt.Format("2006-01-02 15:04:05"),
 			booking.UpdatedAt.Format("2006-01-02 15:04:05"),
+			unitCellValue(booking.UnitID),
 		}
 		values = append(values, row)
 	}
 
 	// Полностью очищаем и перезаписываем лист
-	rangeData := "Bookings!A1:J" + fmt.Sprintf("%d", len(values))
+	rangeData := "Bookings!A1:K" + fmt.Sprintf("%d", len(values))
 	valueRange := &sheets.ValueRange{
 		Values: values,
 	}
@@ -418,11 +447,71 @@ func (s *SheetsService) UpdateBookingsSheet(ctx context.Context, bookings []*mod
 	return err
 }
 
+// UpdateHandoversSheet перезаписывает лист выдачи и возврата: плановые и фактические даты по каждой выдаче
+func (s *SheetsService) UpdateHandoversSheet(ctx context.Context, handovers []*models.Handover) error {
+	values := make([][]interface{}, 0, len(handovers)+1)
+
+	// Заголовки
+	headers := []interface{}{
+		"ID", "Booking ID", "User Name", "Item Name", "Planned Out", "Planned Return",
+		"Checked Out At", "Checked In At", "Delay Days", "Checked Out By", "Checked In By",
+		"Check-out Note", "Check-in Note", "Unit ID",
+	}
+	values = append(values, headers)
+
+	now := time.Now()
+	for _, h := range handovers {
+		checkedIn, checkedInBy := "", ""
+		if h.IsReturned() {
+			checkedIn = h.CheckedInAt.Time.Format("2006-01-02 15:04:05")
+			checkedInBy = fmt.Sprintf("%d", h.CheckedInBy)
+		}
+		row := []interface{}{
+			h.ID,
+			h.BookingID,
+			h.UserName,
+			h.ItemName,
+			h.PlannedStart.Format("2006-01-02"),
+			h.ReturnDate().Format("2006-01-02"),
+			h.CheckedOutAt.Format("2006-01-02 15:04:05"),
+			checkedIn,
+			h.DelayDays(now),
+			h.CheckedOutBy,
+			checkedInBy,
+			h.CheckOutNote,
+			h.CheckInNote,
+			unitCellValue(h.UnitID),
+		}
+		values = append(values, row)
+	}
+
+	// Полностью очищаем и перезаписываем лист, чтобы не оставалось строк вне периода выгрузки
+	_, err := s.service.Spreadsheets.Values.Clear(s.bookingsSheetID, "Handovers!A:N", &sheets.ClearValuesRequest{}).
+		Context(ctx).
+		Do()
+	if err != nil {
+		return fmt.Errorf("failed to clear handovers sheet: %w", err)
+	}
+
+	rangeData := "Handovers!A1:N" + fmt.Sprintf("%d", len(values))
+	valueRange := &sheets.ValueRange{
+		Values: values,
+	}
+
+	_, err = s.service.Spreadsheets.Values.Update(s.bookingsSheetID, rangeData, valueRange).
+		ValueInputOption("RAW").
+		Context(ctx).
+		Do()
+
+	return err
+}
+
 // UpdateScheduleSheet обновляет лист с расписанием бронирований в формате таблицы
 func (s *SheetsService) UpdateScheduleSheet(
 	ctx context.Context,
 	startDate, endDate time.Time,
 	dailyBookings map[string][]*models.Booking,
+	maintenance []*models.MaintenanceWindow,
 	items []*models.Item,
 ) error {
 	sheetId, err := s.GetSheetIdByName(ctx, s.bookingsSheetID, "Бронирования")
@@ -460,7 +549,7 @@ func (s *SheetsService) UpdateScheduleSheet(
 
 	// 4. Данные по аппаратам
 	for rowIndex, item := range items {
-		rowData, cellFormats := s.prepareItemRowData(item, startDate, dateCols, dailyBookings)
+		rowData, cellFormats := s.prepareItemRowData(item, startDate, dateCols, dailyBookings, maintenance)
 		data = append(data, rowData)
 
 		for colIndex, cellFormat := range cellFormats {
@@ -581,6 +670,7 @@ func (s *SheetsService) prepareItemRowData(
 	startDate time.Time,
 	dateCols int,
 	dailyBookings map[string][]*models.Booking,
+	maintenance []*models.MaintenanceWindow,
 ) ([]interface{}, []*sheets.CellData) {
 	rowData := []interface{}{fmt.Sprintf("%s (%d)", item.Name, item.TotalQuantity)}
 	cellFormats := make([]*sheets.CellData, 0, dateCols)
@@ -598,7 +688,9 @@ func (s *SheetsService) prepareItemRowData(
 			}
 		}
 
-		cellValue, bgColor := s.formatScheduleCell(item, itemBookings)
+		units := models.MaintenanceUnits(maintenance, item.ID, currentDate)
+		external := models.ExternalHoldUnits(maintenance, item.ID, currentDate)
+		cellValue, bgColor := s.formatScheduleCell(item, itemBookings, units, external)
 		rowData = append(rowData, cellValue)
 
 		cellFormats = append(cellFormats, &sheets.CellData{
@@ -613,15 +705,33 @@ func (s *SheetsService) prepareItemRowData(
 	return rowData, cellFormats
 }
 
-func (s *SheetsService) formatScheduleCell(item *models.Item, itemBookings []*models.Booking) (string, *sheets.Color) {
+// formatScheduleCell формирует текст и цвет ячейки; maintenance - аппараты вне работы в этот день,
+// включая external - занятые бронями из внешних календарей
+func (s *SheetsService) formatScheduleCell(
+	item *models.Item,
+	itemBookings []*models.Booking,
+	maintenance, external int64,
+) (string, *sheets.Color) {
 	activeBookings := s.filterActiveBookings(itemBookings)
 	bookedCount := len(activeBookings)
+	capacity := item.EffectiveQuantity(maintenance)
+
+	var cellValue string
+	if repair := maintenance - external; repair > 0 {
+		cellValue = fmt.Sprintf("🔧 На обслуживании: %d\n", repair)
+	}
+	if external > 0 {
+		cellValue += fmt.Sprintf("📅 Внешняя бронь: %d\n", external)
+	}
 
 	if bookedCount == 0 {
-		return "Свободно\n\nДоступно: " + fmt.Sprintf("%d/%d", item.TotalQuantity, item.TotalQuantity), &sheets.Color{Red: 1, Green: 1, Blue: 1}
+		cellValue += "Свободно\n\nДоступно: " + fmt.Sprintf("%d/%d", capacity, item.TotalQuantity)
+		if maintenance > 0 {
+			return cellValue, &sheets.Color{Red: 0.85, Green: 0.85, Blue: 0.85} // Gray
+		}
+		return cellValue, &sheets.Color{Red: 1, Green: 1, Blue: 1}
 	}
 
-	var cellValue string
 	hasUnconfirmed := false
 	for _, b := range activeBookings {
 		statusIcon := "❓"
@@ -640,10 +750,13 @@ func (s *SheetsService) formatScheduleCell(item *models.Item, itemBookings []*mo
 			cellValue += fmt.Sprintf("   💬 %s\n", b.Comment)
 		}
 	}
-	cellValue += fmt.Sprintf("\nЗанято: %d/%d", bookedCount, item.TotalQuantity)
+	cellValue += fmt.Sprintf("\nЗанято: %d/%d", bookedCount, capacity)
+	if int64(bookedCount) > capacity {
+		cellValue += "\n⚠️ Заявок больше, чем аппаратов в работе"
+	}
 
 	var bgColor *sheets.Color
-	if bookedCount >= int(item.TotalQuantity) {
+	if int64(bookedCount) >= capacity {
 		if hasUnconfirmed {
 			bgColor = &sheets.Color{Red: 1.0, Green: 0.92, Blue: 0.61} // Yellow
 		} else {
@@ -805,6 +918,7 @@ func (s *SheetsService) ReplaceBookingsSheet(ctx context.Context, bookings []*mo
 			booking.Comment,
 			booking.CreatedAt.Format("02.01.2006 15:04"),
 			booking.UpdatedAt.Format("02.01.2006 15:04"),
+			unitCellValue(booking.UnitID),
 		}
 		values = append(values, row)
 	}
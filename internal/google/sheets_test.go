@@ -4,6 +4,7 @@ import (
 	"bronivik/internal/models"
 	"context"
 	"os"
+	"strings"
 	"testing"
 	"time"
 )
@@ -47,6 +48,7 @@ func TestBookingRowValues(t *testing.T) {
 		ItemName:  "Test Item",
 		CreatedAt: createdAt,
 		UpdatedAt: updatedAt,
+		UnitID:    42,
 	}
 
 	values := bookingRowValues(booking)
@@ -62,6 +64,7 @@ func TestBookingRowValues(t *testing.T) {
 		"Test Item",
 		"2024-12-20 10:00:00",
 		"2024-12-21 11:00:00",
+		int64(42),
 	}
 
 	if len(values) != len(expected) {
@@ -122,7 +125,7 @@ func TestFormatScheduleCell(t *testing.T) {
 	item := &models.Item{Name: "Camera", TotalQuantity: 2}
 
 	t.Run("Empty", func(t *testing.T) {
-		val, color := s.formatScheduleCell(item, nil)
+		val, color := s.formatScheduleCell(item, nil, 0, 0)
 		if val == "" || color == nil {
 			t.Error("Expected non-empty value and color")
 		}
@@ -132,7 +135,7 @@ func TestFormatScheduleCell(t *testing.T) {
 		bookings := []*models.Booking{
 			{ID: 1, UserName: "User 1", Phone: "111", Status: models.StatusConfirmed},
 		}
-		val, color := s.formatScheduleCell(item, bookings)
+		val, color := s.formatScheduleCell(item, bookings, 0, 0)
 		if val == "" {
 			t.Error("Expected non-empty value")
 		}
@@ -147,7 +150,7 @@ func TestFormatScheduleCell(t *testing.T) {
 			{ID: 1, UserName: "User 1", Phone: "111", Status: models.StatusConfirmed},
 			{ID: 2, UserName: "User 2", Phone: "222", Status: models.StatusConfirmed},
 		}
-		val, color := s.formatScheduleCell(item, bookings)
+		val, color := s.formatScheduleCell(item, bookings, 0, 0)
 		if val == "" {
 			t.Error("Expected non-empty value")
 		}
@@ -161,7 +164,7 @@ func TestFormatScheduleCell(t *testing.T) {
 		bookings := []*models.Booking{
 			{ID: 1, UserName: "User 1", Phone: "111", Status: models.StatusPending},
 		}
-		val, color := s.formatScheduleCell(item, bookings)
+		val, color := s.formatScheduleCell(item, bookings, 0, 0)
 		if val == "" {
 			t.Error("Expected non-empty value")
 		}
@@ -170,6 +173,44 @@ func TestFormatScheduleCell(t *testing.T) {
 			t.Errorf("Expected yellow color, got %+v", color)
 		}
 	})
+
+	t.Run("Maintenance", func(t *testing.T) {
+		val, color := s.formatScheduleCell(item, nil, 1, 0)
+		if !strings.Contains(val, "На обслуживании: 1") || !strings.Contains(val, "Доступно: 1/2") {
+			t.Errorf("Expected maintenance mark, got %q", val)
+		}
+		// Gray
+		if color.Red != color.Green || color.Red > 0.9 {
+			t.Errorf("Expected gray color, got %+v", color)
+		}
+	})
+
+	t.Run("ExternalHold", func(t *testing.T) {
+		val, _ := s.formatScheduleCell(item, nil, 2, 1)
+		if !strings.Contains(val, "На обслуживании: 1") || !strings.Contains(val, "Внешняя бронь: 1") ||
+			!strings.Contains(val, "Доступно: 0/2") {
+			t.Errorf("Expected maintenance and external hold marks, got %q", val)
+		}
+		val, _ = s.formatScheduleCell(item, nil, 1, 1)
+		if strings.Contains(val, "На обслуживании") {
+			t.Errorf("Expected only external hold mark, got %q", val)
+		}
+	})
+
+	t.Run("MaintenanceOverflow", func(t *testing.T) {
+		bookings := []*models.Booking{
+			{ID: 1, UserName: "User 1", Phone: "111", Status: models.StatusConfirmed},
+			{ID: 2, UserName: "User 2", Phone: "222", Status: models.StatusConfirmed},
+		}
+		val, color := s.formatScheduleCell(item, bookings, 1, 0)
+		if !strings.Contains(val, "Занято: 2/1") || !strings.Contains(val, "⚠️") {
+			t.Errorf("Expected overflow warning, got %q", val)
+		}
+		// Red-ish
+		if color.Red < 0.9 || color.Green > 0.9 {
+			t.Errorf("Expected red color, got %+v", color)
+		}
+	})
 }
 
 func TestPrepareItemRowData(t *testing.T) {
@@ -180,7 +221,7 @@ func TestPrepareItemRowData(t *testing.T) {
 		"2025-01-01": {{ID: 1, ItemID: 1, Status: models.StatusConfirmed}},
 	}
 
-	rowData, cellFormats := s.prepareItemRowData(item, startDate, 2, dailyBookings)
+	rowData, cellFormats := s.prepareItemRowData(item, startDate, 2, dailyBookings, nil)
 	if len(rowData) != 3 {
 		t.Errorf("Expected 3 elements in rowData, got %d", len(rowData))
 	}
@@ -0,0 +1,19 @@
+package i18n
+
+import "context"
+
+type languageKey struct{}
+
+// WithLanguage stores the language of the user being served in ctx.
+func WithLanguage(ctx context.Context, lang string) context.Context {
+	return context.WithValue(ctx, languageKey{}, lang)
+}
+
+// LanguageFromContext returns the language stored by WithLanguage or "".
+func LanguageFromContext(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	lang, _ := ctx.Value(languageKey{}).(string)
+	return lang
+}
@@ -0,0 +1,244 @@
+package i18n
+
+import (
+	"embed"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultLanguage is used when neither the user nor the config picks a supported language.
+const DefaultLanguage = "ru"
+
+// KeyLanguageName is the catalog key holding the native name of a language.
+const KeyLanguageName = "language.name"
+
+// ButtonPrefix marks keys of reply keyboard buttons recognised by Match.
+const ButtonPrefix = "btn."
+
+//go:embed locales/*.yaml
+var embedded embed.FS
+
+// Message is a single catalog entry. Plain strings are stored in Other;
+// entries with plural forms fill One/Few/Many as the language requires.
+type Message struct {
+	One   string `yaml:"one"`
+	Few   string `yaml:"few"`
+	Many  string `yaml:"many"`
+	Other string `yaml:"other"`
+}
+
+// UnmarshalYAML accepts either a scalar or a mapping of plural forms.
+func (m *Message) UnmarshalYAML(value *yaml.Node) error {
+	if value.Kind == yaml.ScalarNode {
+		m.Other = value.Value
+		return nil
+	}
+	type plain Message
+	return value.Decode((*plain)(m))
+}
+
+func (m Message) form(form string) string {
+	var s string
+	switch form {
+	case FormOne:
+		s = m.One
+	case FormFew:
+		s = m.Few
+	case FormMany:
+		s = m.Many
+	}
+	if s == "" {
+		s = m.Other
+	}
+	if s == "" {
+		s = m.Many
+	}
+	return s
+}
+
+// Bundle holds message catalogs for all supported languages.
+type Bundle struct {
+	defaultLang string
+	catalogs    map[string]map[string]Message
+	reverse     map[string]string
+}
+
+// New loads the embedded catalogs and, if dir is set, catalogs from that directory
+// (*.yaml, *.yml or *.json named after the language code) on top of them.
+func New(defaultLang, dir string) (*Bundle, error) {
+	if defaultLang == "" {
+		defaultLang = DefaultLanguage
+	}
+	b := &Bundle{
+		defaultLang: Normalize(defaultLang),
+		catalogs:    make(map[string]map[string]Message),
+		reverse:     make(map[string]string),
+	}
+
+	if err := b.loadFS(embedded, "locales"); err != nil {
+		return nil, err
+	}
+	if dir != "" {
+		if err := b.loadFS(os.DirFS(dir), "."); err != nil {
+			return nil, err
+		}
+	}
+
+	if _, ok := b.catalogs[b.defaultLang]; !ok {
+		return nil, fmt.Errorf("no catalog for default language %q", b.defaultLang)
+	}
+	return b, nil
+}
+
+func (b *Bundle) loadFS(fsys fs.FS, dir string) error {
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return fmt.Errorf("failed to read locales: %w", err)
+	}
+	for _, entry := range entries {
+		ext := filepath.Ext(entry.Name())
+		if entry.IsDir() || (ext != ".yaml" && ext != ".yml" && ext != ".json") {
+			continue
+		}
+		data, err := fs.ReadFile(fsys, filepath.ToSlash(filepath.Join(dir, entry.Name())))
+		if err != nil {
+			return fmt.Errorf("failed to read locale %s: %w", entry.Name(), err)
+		}
+		if err := b.AddCatalog(strings.TrimSuffix(entry.Name(), ext), data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// AddCatalog parses a YAML (or JSON) catalog and merges it into the language.
+// Button texts must be unique across all languages: Match maps a pressed button
+// back to its key, so two buttons with the same text would be indistinguishable.
+func (b *Bundle) AddCatalog(lang string, data []byte) error {
+	var messages map[string]Message
+	if err := yaml.Unmarshal(data, &messages); err != nil {
+		return fmt.Errorf("failed to parse %s catalog: %w", lang, err)
+	}
+
+	lang = Normalize(lang)
+	catalog, ok := b.catalogs[lang]
+	if !ok {
+		catalog = make(map[string]Message, len(messages))
+		b.catalogs[lang] = catalog
+	}
+	keys := make([]string, 0, len(messages))
+	for key := range messages {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		msg := messages[key]
+		catalog[key] = msg
+		if msg.Other == "" || !strings.HasPrefix(key, ButtonPrefix) {
+			continue
+		}
+		if other, ok := b.reverse[msg.Other]; ok && other != key {
+			return fmt.Errorf("%s catalog: buttons %s and %s share the text %q", lang, other, key, msg.Other)
+		}
+		b.reverse[msg.Other] = key
+	}
+	return nil
+}
+
+// Languages returns the supported language codes, default language first.
+func (b *Bundle) Languages() []string {
+	langs := make([]string, 0, len(b.catalogs))
+	for lang := range b.catalogs {
+		if lang != b.defaultLang {
+			langs = append(langs, lang)
+		}
+	}
+	sort.Strings(langs)
+	return append([]string{b.defaultLang}, langs...)
+}
+
+// Supports reports whether the bundle has a catalog for lang.
+func (b *Bundle) Supports(lang string) bool {
+	_, ok := b.catalogs[Normalize(lang)]
+	return ok
+}
+
+// Resolve returns the first supported language among codes (e.g. "en-US" -> "en"),
+// falling back to the default language.
+func (b *Bundle) Resolve(codes ...string) string {
+	for _, code := range codes {
+		if code == "" {
+			continue
+		}
+		if lang := Normalize(code); b.Supports(lang) {
+			return lang
+		}
+	}
+	return b.defaultLang
+}
+
+// T returns the translation of key formatted with args.
+func (b *Bundle) T(lang, key string, args ...interface{}) string {
+	return b.format(b.lookup(lang, key).form(FormOther), key, args)
+}
+
+// N returns the plural form of key matching n. Without args the text is formatted with n.
+func (b *Bundle) N(lang, key string, n int, args ...interface{}) string {
+	lang = b.Resolve(lang)
+	if len(args) == 0 {
+		args = []interface{}{n}
+	}
+	return b.format(b.lookup(lang, key).form(PluralForm(lang, n)), key, args)
+}
+
+// Match returns the button key whose translation in any language equals text.
+// It is used to recognise reply keyboard buttons regardless of the user's language.
+func (b *Bundle) Match(text string) string {
+	return b.reverse[text]
+}
+
+// Keys returns the sorted keys of the language catalog.
+func (b *Bundle) Keys(lang string) []string {
+	catalog := b.catalogs[Normalize(lang)]
+	keys := make([]string, 0, len(catalog))
+	for key := range catalog {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func (b *Bundle) lookup(lang, key string) Message {
+	if msg, ok := b.catalogs[Normalize(lang)][key]; ok {
+		return msg
+	}
+	if msg, ok := b.catalogs[b.defaultLang][key]; ok {
+		return msg
+	}
+	return Message{Other: key}
+}
+
+func (b *Bundle) format(text, key string, args []interface{}) string {
+	if text == "" {
+		text = key
+	}
+	if len(args) == 0 {
+		return text
+	}
+	return fmt.Sprintf(text, args...)
+}
+
+// Normalize reduces an IETF language tag to its lowercase primary subtag.
+func Normalize(code string) string {
+	code = strings.ToLower(strings.TrimSpace(code))
+	if i := strings.IndexAny(code, "-_"); i > 0 {
+		code = code[:i]
+	}
+	return code
+}
@@ -0,0 +1,141 @@
+package i18n
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPluralForm(t *testing.T) {
+	ru := map[int]string{
+		0: FormMany, 1: FormOne, 2: FormFew, 4: FormFew, 5: FormMany,
+		11: FormMany, 12: FormMany, 21: FormOne, 22: FormFew, 112: FormMany, 101: FormOne,
+	}
+	for n, form := range ru {
+		assert.Equal(t, form, PluralForm("ru", n), "ru n=%d", n)
+	}
+
+	assert.Equal(t, FormOne, PluralForm("en", 1))
+	assert.Equal(t, FormOther, PluralForm("en", 0))
+	assert.Equal(t, FormOther, PluralForm("en", 21))
+	assert.Equal(t, FormOne, PluralForm("ru-RU", -1))
+}
+
+func TestBundle_ResolveAndTranslate(t *testing.T) {
+	b, err := New("", "")
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"ru", "en"}, b.Languages())
+	assert.Equal(t, "en", b.Resolve("en-US"))
+	assert.Equal(t, "en", b.Resolve("", "de", "EN_gb"))
+	assert.Equal(t, "ru", b.Resolve("de"))
+	assert.Equal(t, "ru", b.Resolve())
+
+	assert.Equal(t, "❌ Cancel", b.T("en", "btn.cancel"))
+	assert.Equal(t, "❌ Отмена", b.T("de", "btn.cancel"))
+	assert.Equal(t, "no.such.key", b.T("en", "no.such.key"))
+	assert.Equal(t, "👥 Total: 3", b.T("en", "items.total", 3))
+
+	assert.Equal(t, "btn.cancel", b.Match("❌ Cancel"))
+	assert.Equal(t, "btn.cancel", b.Match("❌ Отмена"))
+	assert.Empty(t, b.Match("random text"))
+}
+
+func TestBundle_Plural(t *testing.T) {
+	b, err := New("ru", "")
+	require.NoError(t, err)
+
+	assert.Contains(t, b.N("ru", "forget.done", 1), "обезличена 1 заявка")
+	assert.Contains(t, b.N("ru", "forget.done", 3), "обезличено 3 заявки")
+	assert.Contains(t, b.N("ru", "forget.done", 5), "обезличено 5 заявок")
+	assert.Contains(t, b.N("en", "forget.done", 1), "1 booking anonymized")
+	assert.Contains(t, b.N("en", "forget.done", 2), "2 bookings anonymized")
+	assert.Contains(t, b.N("en", "manager_booking.range", 2, "01.01.2025", "02.01.2025", 2), "(2 days)")
+}
+
+var verbPattern = regexp.MustCompile(`%[-+# 0-9.]*[a-zA-Z]`)
+
+func TestCatalogsParity(t *testing.T) {
+	b, err := New("ru", "")
+	require.NoError(t, err)
+
+	ru := b.catalogs["ru"]
+	for _, lang := range b.Languages() {
+		catalog := b.catalogs[lang]
+		for key, msg := range ru {
+			other, ok := catalog[key]
+			if !assert.True(t, ok, "%s: missing key %s", lang, key) {
+				continue
+			}
+			assert.Equal(t, verbPattern.FindAllString(msg.form(FormOther), -1),
+				verbPattern.FindAllString(other.form(FormOther), -1), "%s: verbs differ for %s", lang, key)
+		}
+		assert.Len(t, catalog, len(ru), "%s has extra keys", lang)
+	}
+}
+
+func TestCatalogsUniqueButtons(t *testing.T) {
+	// New fails on a collision; the loop below names every colliding pair at once
+	b, err := New("ru", "")
+	require.NoError(t, err)
+
+	seen := make(map[string]string)
+	for _, lang := range b.Languages() {
+		for _, key := range b.Keys(lang) {
+			if !strings.HasPrefix(key, ButtonPrefix) {
+				continue
+			}
+			text := b.catalogs[lang][key].Other
+			if other, ok := seen[text]; ok && other != key {
+				t.Errorf("%s: buttons %s and %s share the text %q", lang, other, key, text)
+			}
+			seen[text] = key
+			assert.Equal(t, key, b.Match(text))
+		}
+	}
+}
+
+func TestBundle_ButtonCollision(t *testing.T) {
+	b, err := New("ru", "")
+	require.NoError(t, err)
+
+	err = b.AddCatalog("de", []byte(`{"btn.cancel": "❌ Abbrechen", "btn.back": "❌ Abbrechen"}`))
+	assert.ErrorContains(t, err, "share the text")
+
+	err = b.AddCatalog("fr", []byte(`{"btn.back": "❌ Отмена"}`))
+	assert.ErrorContains(t, err, "btn.cancel")
+
+	require.NoError(t, b.AddCatalog("es", []byte(`{"btn.cancel": "❌ Cancelar", "forget.canceled": "❌ Cancelar"}`)),
+		"only button texts must be unique")
+}
+
+func TestBundle_LocalesDir(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "de.json"),
+		[]byte(`{"btn.cancel": "❌ Abbrechen", "language.name": "Deutsch"}`), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "en.yaml"),
+		[]byte(`btn.cancel: "✖ Cancel"`), 0o600))
+
+	b, err := New("ru", dir)
+	require.NoError(t, err)
+
+	assert.True(t, b.Supports("de"))
+	assert.Equal(t, "❌ Abbrechen", b.T("de", "btn.cancel"))
+	assert.Equal(t, "⬅️ Назад", b.T("de", "btn.back"), "missing keys fall back to the default language")
+	assert.Equal(t, "✖ Cancel", b.T("en", "btn.cancel"))
+	assert.Equal(t, "⬅️ Back", b.T("en", "btn.back"))
+
+	_, err = New("fr", dir)
+	assert.Error(t, err)
+}
+
+func TestLanguageContext(t *testing.T) {
+	assert.Empty(t, LanguageFromContext(context.Background()))
+	assert.Equal(t, "en", LanguageFromContext(WithLanguage(context.Background(), "en")))
+}
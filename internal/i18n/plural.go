@@ -0,0 +1,34 @@
+package i18n
+
+// CLDR plural categories used by the supported languages.
+const (
+	FormOne   = "one"
+	FormFew   = "few"
+	FormMany  = "many"
+	FormOther = "other"
+)
+
+// PluralForm returns the CLDR plural category of n for lang.
+func PluralForm(lang string, n int) string {
+	if n < 0 {
+		n = -n
+	}
+
+	switch Normalize(lang) {
+	case "ru", "uk", "be":
+		mod10, mod100 := n%10, n%100
+		switch {
+		case mod10 == 1 && mod100 != 11:
+			return FormOne
+		case mod10 >= 2 && mod10 <= 4 && (mod100 < 12 || mod100 > 14):
+			return FormFew
+		default:
+			return FormMany
+		}
+	default:
+		if n == 1 {
+			return FormOne
+		}
+		return FormOther
+	}
+}
@@ -0,0 +1,148 @@
+// Package ical renders bookings as iCalendar (RFC 5545) feeds and signs the tokens
+// that protect feed URLs, so calendar apps can subscribe without API keys. It also
+// reads events from external calendars that hold items.
+package ical
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"bronivik/internal/models"
+)
+
+// ContentType is the MIME type of a rendered feed.
+const ContentType = "text/calendar; charset=utf-8"
+
+const (
+	prodID = "-//bronivik//bookings//RU"
+
+	// maxLineOctets is the longest content line allowed before folding.
+	maxLineOctets = 75
+
+	// refreshInterval tells subscribed clients how often to poll the feed.
+	refreshInterval = "PT1H"
+)
+
+// Event is one all-day calendar event.
+type Event struct {
+	UID         string
+	Date        time.Time
+	Summary     string
+	Description string
+	Status      string // TENTATIVE, CONFIRMED or CANCELLED
+	Sequence    int64
+	Modified    time.Time
+}
+
+// BookingEvents converts bookings to events. Staff feeds set withClient to show
+// the client's name and phone; a client's own feed shows only the item.
+func BookingEvents(bookings []*models.Booking, withClient bool) []Event {
+	events := make([]Event, 0, len(bookings))
+	for _, b := range bookings {
+		summary := b.ItemName
+		description := []string{fmt.Sprintf("#%d", b.ID)}
+		if withClient {
+			summary = fmt.Sprintf("%s — %s", b.ItemName, b.UserName)
+			if b.Phone != "" {
+				description = append(description, b.Phone)
+			}
+			if b.Comment != "" {
+				description = append(description, b.Comment)
+			}
+		}
+		events = append(events, Event{
+			UID:         fmt.Sprintf("booking-%d@bronivik", b.ID),
+			Date:        b.Date,
+			Summary:     summary,
+			Description: strings.Join(description, "\n"),
+			Status:      eventStatus(b.Status),
+			Sequence:    b.Version,
+			Modified:    b.UpdatedAt,
+		})
+	}
+	return events
+}
+
+func eventStatus(status string) string {
+	switch status {
+	case models.StatusPending:
+		return "TENTATIVE"
+	case models.StatusConfirmed, models.StatusCompleted:
+		return "CONFIRMED"
+	default:
+		return "CANCELLED"
+	}
+}
+
+// Render writes a calendar named name with the events.
+func Render(name string, events []Event) []byte {
+	var buf bytes.Buffer
+	writeLine(&buf, "BEGIN:VCALENDAR")
+	writeLine(&buf, "VERSION:2.0")
+	writeLine(&buf, "PRODID:"+prodID)
+	writeLine(&buf, "CALSCALE:GREGORIAN")
+	writeLine(&buf, "METHOD:PUBLISH")
+	writeLine(&buf, "X-WR-CALNAME:"+escapeText(name))
+	writeLine(&buf, "REFRESH-INTERVAL;VALUE=DURATION:"+refreshInterval)
+	writeLine(&buf, "X-PUBLISHED-TTL:"+refreshInterval)
+
+	for _, e := range events {
+		modified := e.Modified
+		if modified.IsZero() {
+			modified = time.Now()
+		}
+		writeLine(&buf, "BEGIN:VEVENT")
+		writeLine(&buf, "UID:"+e.UID)
+		writeLine(&buf, "DTSTAMP:"+modified.UTC().Format("20060102T150405Z"))
+		writeLine(&buf, "LAST-MODIFIED:"+modified.UTC().Format("20060102T150405Z"))
+		writeLine(&buf, "DTSTART;VALUE=DATE:"+e.Date.Format("20060102"))
+		writeLine(&buf, "DTEND;VALUE=DATE:"+e.Date.AddDate(0, 0, 1).Format("20060102"))
+		writeLine(&buf, "SUMMARY:"+escapeText(e.Summary))
+		if e.Description != "" {
+			writeLine(&buf, "DESCRIPTION:"+escapeText(e.Description))
+		}
+		if e.Status != "" {
+			writeLine(&buf, "STATUS:"+e.Status)
+		}
+		writeLine(&buf, fmt.Sprintf("SEQUENCE:%d", e.Sequence))
+		writeLine(&buf, "TRANSP:TRANSPARENT")
+		writeLine(&buf, "END:VEVENT")
+	}
+
+	writeLine(&buf, "END:VCALENDAR")
+	return buf.Bytes()
+}
+
+// escapeText escapes a TEXT property value.
+func escapeText(s string) string {
+	return strings.NewReplacer(
+		`\`, `\\`,
+		";", `\;`,
+		",", `\,`,
+		"\r\n", `\n`,
+		"\n", `\n`,
+		"\r", "",
+	).Replace(s)
+}
+
+// writeLine writes a CRLF-terminated content line folded at 75 octets
+// without splitting multi-byte characters.
+func writeLine(buf *bytes.Buffer, line string) {
+	limit := maxLineOctets
+	for len(line) > limit {
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(line[cut]) {
+			cut--
+		}
+		buf.WriteString(line[:cut])
+		buf.WriteString("\r\n ")
+		line = line[cut:]
+		// continuation lines start with a space that counts towards the limit
+		limit = maxLineOctets - 1
+	}
+	buf.WriteString(line)
+	buf.WriteString("\r\n")
+}
@@ -0,0 +1,270 @@
+package ical
+
+import (
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"bronivik/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRender(t *testing.T) {
+	bookings := []*models.Booking{
+		{
+			ID: 12, ItemID: 1, ItemName: "Laser", UserName: "Иван", Phone: "+7900", Comment: "до 18:00; с кейсом",
+			Date: time.Date(2030, 5, 31, 0, 0, 0, 0, time.UTC), Status: models.StatusConfirmed, Version: 2,
+			UpdatedAt: time.Date(2030, 5, 1, 10, 0, 0, 0, time.UTC),
+		},
+		{ID: 13, ItemName: "Laser", Date: time.Date(2030, 6, 1, 0, 0, 0, 0, time.UTC), Status: models.StatusPending},
+	}
+
+	out := string(Render("Laser", BookingEvents(bookings, true)))
+	assert.True(t, strings.HasPrefix(out, "BEGIN:VCALENDAR\r\n"))
+	assert.True(t, strings.HasSuffix(out, "END:VCALENDAR\r\n"))
+	assert.Equal(t, 2, strings.Count(out, "BEGIN:VEVENT"))
+	assert.Contains(t, out, "UID:booking-12@bronivik\r\n")
+	assert.Contains(t, out, "DTSTART;VALUE=DATE:20300531\r\n")
+	assert.Contains(t, out, "DTEND;VALUE=DATE:20300601\r\n", "all-day event ends on the next day")
+	assert.Contains(t, out, "SUMMARY:Laser — Иван\r\n")
+	assert.Contains(t, out, `DESCRIPTION:#12\n+7900\nдо 18:00\; с кейсом`)
+	assert.Contains(t, out, "STATUS:CONFIRMED\r\n")
+	assert.Contains(t, out, "STATUS:TENTATIVE\r\n")
+	assert.Contains(t, out, "SEQUENCE:2\r\n")
+	assert.Contains(t, out, "DTSTAMP:20300501T100000Z\r\n")
+
+	own := string(Render("My bookings", BookingEvents(bookings[:1], false)))
+	assert.Contains(t, own, "SUMMARY:Laser\r\n")
+	assert.NotContains(t, own, "+7900")
+}
+
+func TestWriteLine_Folds(t *testing.T) {
+	name := strings.Repeat("Кресло-коляска, ", 20)
+	out := string(Render(name, nil))
+
+	for _, l := range strings.Split(strings.TrimSuffix(out, "\r\n"), "\r\n") {
+		assert.LessOrEqual(t, len(l), maxLineOctets)
+		assert.True(t, utf8.ValidString(l), "folding must not split characters")
+	}
+	unfolded := strings.ReplaceAll(out, "\r\n ", "")
+	assert.Contains(t, unfolded, "X-WR-CALNAME:"+escapeText(name)+"\r\n")
+}
+
+func TestSigner(t *testing.T) {
+	signer := NewSigner("0123456789abcdef")
+	feed := &models.CalendarFeed{ID: 7, Scope: models.CalendarScopeUser, SubjectID: 777}
+
+	token := signer.Token(feed)
+	id, err := FeedID(token)
+	require.NoError(t, err)
+	assert.Equal(t, int64(7), id)
+	assert.True(t, signer.Verify(feed, token))
+
+	other := &models.CalendarFeed{ID: 7, Scope: models.CalendarScopeUser, SubjectID: 778}
+	assert.False(t, signer.Verify(other, token), "token is bound to the subject")
+	assert.False(t, NewSigner("another secret").Verify(feed, token))
+	assert.False(t, signer.Verify(feed, "7.AAAA"))
+	assert.False(t, signer.Verify(feed, "7"))
+
+	_, err = FeedID("abc.def")
+	assert.ErrorIs(t, err, ErrInvalidToken)
+
+	link := signer.URL("https://bronivik.example/", feed)
+	assert.True(t, strings.HasPrefix(link, "https://bronivik.example/api/v1/ics/user/777.ics?token=7."))
+}
+
+func TestPath(t *testing.T) {
+	tests := []struct {
+		scope   string
+		subject int64
+		path    string
+	}{
+		{models.CalendarScopeUser, 777, "/api/v1/ics/user/777.ics"},
+		{models.CalendarScopeItem, 3, "/api/v1/ics/item/3.ics"},
+		{models.CalendarScopeAll, 0, "/api/v1/ics/all.ics"},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.path, Path(tt.scope, tt.subject))
+		scope, subject, ok := ParsePath(tt.path)
+		assert.True(t, ok, tt.path)
+		assert.Equal(t, tt.scope, scope)
+		assert.Equal(t, tt.subject, subject)
+	}
+
+	for _, path := range []string{"/api/v1/ics/user/abc.ics", "/api/v1/ics/team/1.ics", "/api/v1/ics/user/1", "/api/v1/ics/item/0.ics"} {
+		_, _, ok := ParsePath(path)
+		assert.False(t, ok, path)
+	}
+}
+
+func TestParse(t *testing.T) {
+	moscow := time.FixedZone("MSK", 3*60*60)
+	doc := strings.Join([]string{
+		"BEGIN:VCALENDAR",
+		"VERSION:2.0",
+		"BEGIN:VEVENT",
+		"UID:wedding@partner",
+		"SUMMARY:Свадьба\\, зал 2",
+		"DTSTART;VALUE=DATE:20300510",
+		"DTEND;VALUE=DATE:20300512",
+		"END:VEVENT",
+		"BEGIN:VEVENT",
+		"UID:night@partner",
+		"SUMMARY:Ночная съем",
+		" ка",
+		"DTSTART:20300520T200000Z",
+		"DTEND:20300521T020000Z",
+		"END:VEVENT",
+		"BEGIN:VEVENT",
+		"UID:local@partner",
+		"DTSTART;TZID=Europe/Moscow:20300601T100000",
+		"END:VEVENT",
+		"BEGIN:VEVENT",
+		"UID:cancelled@partner",
+		"STATUS:CANCELLED",
+		"DTSTART;VALUE=DATE:20300701",
+		"END:VEVENT",
+		"END:VCALENDAR",
+	}, "\r\n")
+
+	horizon := time.Date(2031, 1, 1, 0, 0, 0, 0, time.UTC)
+	events, skipped, err := Parse(strings.NewReader(doc), moscow, horizon)
+	require.NoError(t, err)
+	assert.Empty(t, skipped)
+	require.Len(t, events, 3)
+
+	day := func(m time.Month, d int) time.Time { return time.Date(2030, m, d, 0, 0, 0, 0, time.UTC) }
+	assert.Equal(t, BusyEvent{UID: "wedding@partner", Summary: "Свадьба, зал 2", Start: day(5, 10), End: day(5, 11)}, events[0],
+		"DTEND of an all-day event is exclusive")
+	assert.Equal(t, "Ночная съемка", events[1].Summary)
+	assert.Equal(t, day(5, 20), events[1].Start, "23:00 MSK")
+	assert.Equal(t, day(5, 21), events[1].End, "05:00 MSK the next day")
+	assert.Equal(t, day(6, 1), events[2].Start)
+	assert.Equal(t, day(6, 1), events[2].End, "an event without DTEND takes its start day")
+
+	_, _, err = Parse(strings.NewReader("hello"), time.UTC, horizon)
+	assert.ErrorIs(t, err, ErrNotCalendar)
+	invalid := "BEGIN:VCALENDAR\r\nBEGIN:VEVENT\r\nDTSTART:soon\r\nEND:VEVENT\r\nEND:VCALENDAR"
+	_, _, err = Parse(strings.NewReader(invalid), time.UTC, horizon)
+	assert.Error(t, err)
+
+	// Собственный фид читается обратно
+	own := Render("Laser", BookingEvents([]*models.Booking{{ID: 1, ItemName: "Laser", Date: day(8, 3), Status: models.StatusConfirmed}}, false))
+	events, _, err = Parse(strings.NewReader(string(own)), time.UTC, horizon)
+	require.NoError(t, err)
+	require.Len(t, events, 1)
+	assert.Equal(t, day(8, 3), events[0].End)
+}
+
+func TestParse_Recurrence(t *testing.T) {
+	day := func(y int, m time.Month, d int) time.Time { return time.Date(y, m, d, 0, 0, 0, 0, time.UTC) }
+	horizon := day(2030, 3, 31)
+	calendar := func(lines ...string) string {
+		return "BEGIN:VCALENDAR\r\nBEGIN:VEVENT\r\nUID:rec\r\nSUMMARY:Rent\r\n" +
+			strings.Join(lines, "\r\n") + "\r\nEND:VEVENT\r\nEND:VCALENDAR\r\n"
+	}
+
+	tests := []struct {
+		name   string
+		doc    string
+		starts []time.Time
+	}{
+		{
+			name:   "DailyCount",
+			doc:    calendar("DTSTART;VALUE=DATE:20300301", "RRULE:FREQ=DAILY;COUNT=3"),
+			starts: []time.Time{day(2030, 3, 1), day(2030, 3, 2), day(2030, 3, 3)},
+		},
+		{
+			name:   "DailyWeekdaysUntil",
+			doc:    calendar("DTSTART;VALUE=DATE:20300301", "RRULE:FREQ=DAILY;BYDAY=MO,TU,WE,TH,FR;UNTIL=20300305"),
+			starts: []time.Time{day(2030, 3, 1), day(2030, 3, 4), day(2030, 3, 5)},
+		},
+		{
+			name:   "WeeklyByDayInterval",
+			doc:    calendar("DTSTART;VALUE=DATE:20300304", "RRULE:FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,WE;COUNT=4"),
+			starts: []time.Time{day(2030, 3, 4), day(2030, 3, 6), day(2030, 3, 18), day(2030, 3, 20)},
+		},
+		{
+			name:   "WeeklyUntilHorizon",
+			doc:    calendar("DTSTART;VALUE=DATE:20300310", "RRULE:FREQ=WEEKLY"),
+			starts: []time.Time{day(2030, 3, 10), day(2030, 3, 17), day(2030, 3, 24), day(2030, 3, 31)},
+		},
+		{
+			name:   "MonthlySkipsShortMonths",
+			doc:    calendar("DTSTART;VALUE=DATE:20290131", "RRULE:FREQ=MONTHLY;COUNT=3"),
+			starts: []time.Time{day(2029, 1, 31), day(2029, 3, 31), day(2029, 5, 31)},
+		},
+		{
+			name: "ExdateAndRdate",
+			doc: calendar("DTSTART;VALUE=DATE:20300301", "RRULE:FREQ=DAILY;COUNT=3",
+				"EXDATE;VALUE=DATE:20300302", "RDATE;VALUE=DATE:20300310,20300301"),
+			starts: []time.Time{day(2030, 3, 1), day(2030, 3, 3), day(2030, 3, 10)},
+		},
+		{
+			name:   "RdateWithoutRule",
+			doc:    calendar("DTSTART;VALUE=DATE:20300301", "RDATE;VALUE=DATE:20300320,20300420"),
+			starts: []time.Time{day(2030, 3, 1), day(2030, 3, 20)},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			events, skipped, err := Parse(strings.NewReader(tt.doc), time.UTC, horizon)
+			require.NoError(t, err)
+			assert.Empty(t, skipped)
+
+			starts := make([]time.Time, 0, len(events))
+			for _, e := range events {
+				assert.Equal(t, "Rent", e.Summary)
+				assert.Equal(t, e.Start, e.End)
+				starts = append(starts, e.Start)
+			}
+			assert.Equal(t, tt.starts, starts)
+		})
+	}
+
+	t.Run("MultiDayOccurrences", func(t *testing.T) {
+		doc := calendar("DTSTART;VALUE=DATE:20300301", "DTEND;VALUE=DATE:20300303", "RRULE:FREQ=WEEKLY;COUNT=2")
+		events, _, err := Parse(strings.NewReader(doc), time.UTC, horizon)
+		require.NoError(t, err)
+		require.Len(t, events, 2)
+		assert.Equal(t, day(2030, 3, 8), events[1].Start)
+		assert.Equal(t, day(2030, 3, 9), events[1].End)
+	})
+
+	t.Run("RecurrenceIDOverrides", func(t *testing.T) {
+		doc := strings.Join([]string{
+			"BEGIN:VCALENDAR",
+			"BEGIN:VEVENT", "UID:rec", "DTSTART;VALUE=DATE:20300301", "RRULE:FREQ=DAILY;COUNT=3", "END:VEVENT",
+			"BEGIN:VEVENT", "UID:rec", "RECURRENCE-ID;VALUE=DATE:20300302", "DTSTART;VALUE=DATE:20300305", "END:VEVENT",
+			"BEGIN:VEVENT", "UID:rec", "RECURRENCE-ID;VALUE=DATE:20300303", "STATUS:CANCELLED",
+			"DTSTART;VALUE=DATE:20300303", "END:VEVENT",
+			"END:VCALENDAR",
+		}, "\r\n")
+		events, _, err := Parse(strings.NewReader(doc), time.UTC, horizon)
+		require.NoError(t, err)
+		require.Len(t, events, 2)
+		assert.Equal(t, day(2030, 3, 1), events[0].Start)
+		assert.Equal(t, day(2030, 3, 5), events[1].Start, "the moved occurrence replaces the original one")
+	})
+
+	t.Run("UnsupportedRulesAreSkipped", func(t *testing.T) {
+		for _, rule := range []string{
+			"RRULE:FREQ=YEARLY",
+			"RRULE:FREQ=MONTHLY;BYDAY=1MO",
+			"RRULE:FREQ=WEEKLY;BYSETPOS=1",
+		} {
+			doc := "BEGIN:VCALENDAR\r\n" +
+				"BEGIN:VEVENT\r\nUID:plain\r\nDTSTART;VALUE=DATE:20300301\r\nEND:VEVENT\r\n" +
+				"BEGIN:VEVENT\r\nUID:rec\r\nDTSTART;VALUE=DATE:20300301\r\n" + rule + "\r\nEND:VEVENT\r\n" +
+				"END:VCALENDAR\r\n"
+			events, skipped, err := Parse(strings.NewReader(doc), time.UTC, horizon)
+			require.NoError(t, err, rule)
+			require.Len(t, events, 1, rule)
+			assert.Equal(t, "plain", events[0].UID, rule)
+			assert.Equal(t, []string{"rec"}, skipped, rule)
+		}
+	})
+}
@@ -0,0 +1,304 @@
+package ical
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ErrNotCalendar is returned by Parse when the input has no VCALENDAR object.
+var ErrNotCalendar = errors.New("not an iCalendar document")
+
+// BusyEvent is an event read from an external calendar. Start and End are
+// inclusive days at midnight UTC.
+type BusyEvent struct {
+	UID     string
+	Summary string
+	Start   time.Time
+	End     time.Time
+}
+
+// Parse reads the non-cancelled events of an iCalendar document. Floating
+// times and times with an unknown TZID are read in loc. Recurring events with
+// DAILY, WEEKLY or MONTHLY rules, RDATE and EXDATE are expanded into separate
+// occurrences up to horizon; a RECURRENCE-ID instance replaces the occurrence it
+// overrides. Recurring events that cannot be expanded are left out and their
+// UIDs returned as skipped.
+func Parse(r io.Reader, loc *time.Location, horizon time.Time) (events []BusyEvent, skipped []string, err error) {
+	lines, err := unfold(r)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var (
+		raw      []rawEvent
+		calendar bool
+		current  *rawEvent
+	)
+	for _, line := range lines {
+		p, ok := parseProperty(line)
+		if !ok {
+			continue
+		}
+		switch {
+		case p.name == "BEGIN" && strings.EqualFold(p.value, "VCALENDAR"):
+			calendar = true
+		case p.name == "BEGIN" && strings.EqualFold(p.value, "VEVENT"):
+			current = &rawEvent{props: make(map[string]property)}
+		case p.name == "END" && strings.EqualFold(p.value, "VEVENT"):
+			if current != nil {
+				raw = append(raw, *current)
+			}
+			current = nil
+		case current != nil && (p.name == "RDATE" || p.name == "EXDATE"):
+			current.dates = append(current.dates, p)
+		case current != nil:
+			if _, seen := current.props[p.name]; !seen {
+				current.props[p.name] = p
+			}
+		}
+	}
+	if !calendar {
+		return nil, nil, ErrNotCalendar
+	}
+
+	// Occurrences replaced by RECURRENCE-ID instances, by UID.
+	overridden := make(map[string][]time.Time)
+	for _, e := range raw {
+		if id, ok := e.props["RECURRENCE-ID"]; ok {
+			t, _, errID := parseTime(id, loc)
+			if errID != nil {
+				return nil, nil, errID
+			}
+			uid := e.props["UID"].value
+			overridden[uid] = append(overridden[uid], day(t))
+		}
+	}
+
+	horizon = day(horizon)
+	for _, e := range raw {
+		event, keep, errEvent := busyEvent(e.props, loc)
+		if errEvent != nil {
+			return nil, nil, errEvent
+		}
+		if !keep {
+			continue
+		}
+		if _, instance := e.props["RECURRENCE-ID"]; instance || !e.recurring() {
+			events = append(events, event)
+			continue
+		}
+
+		occurrences, errExpand := e.expand(event, loc, horizon, overridden[event.UID])
+		if errors.Is(errExpand, errUnsupportedRule) {
+			skipped = append(skipped, event.UID)
+			continue
+		}
+		if errExpand != nil {
+			return nil, nil, errExpand
+		}
+		events = append(events, occurrences...)
+	}
+	return events, skipped, nil
+}
+
+// rawEvent holds the properties of a VEVENT. RDATE and EXDATE may repeat and
+// are kept separately.
+type rawEvent struct {
+	props map[string]property
+	dates []property
+}
+
+func (e rawEvent) recurring() bool {
+	if _, ok := e.props["RRULE"]; ok {
+		return true
+	}
+	for _, p := range e.dates {
+		if p.name == "RDATE" {
+			return true
+		}
+	}
+	return false
+}
+
+// expand returns the occurrences of a recurring event whose first occurrence
+// is first. Occurrences after horizon, excluded by EXDATE or overridden by a
+// RECURRENCE-ID instance are dropped.
+func (e rawEvent) expand(
+	first BusyEvent,
+	loc *time.Location,
+	horizon time.Time,
+	overridden []time.Time,
+) ([]BusyEvent, error) {
+	starts := []time.Time{first.Start}
+	if p, ok := e.props["RRULE"]; ok {
+		rule, err := parseRule(p.value, loc)
+		if err != nil {
+			return nil, err
+		}
+		starts = rule.starts(first.Start, horizon)
+	}
+
+	excluded := make(map[time.Time]bool, len(overridden))
+	for _, d := range overridden {
+		excluded[d] = true
+	}
+	for _, p := range e.dates {
+		days, err := parseDates(p, loc)
+		if err != nil {
+			return nil, err
+		}
+		switch p.name {
+		case "RDATE":
+			starts = append(starts, days...)
+		case "EXDATE":
+			for _, d := range days {
+				excluded[d] = true
+			}
+		}
+	}
+
+	span := first.End.Sub(first.Start)
+	sort.Slice(starts, func(i, j int) bool { return starts[i].Before(starts[j]) })
+	occurrences := make([]BusyEvent, 0, len(starts))
+	for i, start := range starts {
+		if excluded[start] || start.After(horizon) || (i > 0 && start.Equal(starts[i-1])) {
+			continue
+		}
+		occurrence := first
+		occurrence.Start, occurrence.End = start, start.Add(span)
+		occurrences = append(occurrences, occurrence)
+	}
+	return occurrences, nil
+}
+
+// property is one content line: NAME;PARAM=VALUE:value.
+type property struct {
+	name   string
+	params map[string]string
+	value  string
+}
+
+func parseProperty(line string) (property, bool) {
+	colon := strings.IndexByte(line, ':')
+	if colon <= 0 {
+		return property{}, false
+	}
+	parts := strings.Split(line[:colon], ";")
+	p := property{name: strings.ToUpper(parts[0]), params: make(map[string]string), value: line[colon+1:]}
+	for _, param := range parts[1:] {
+		if k, v, ok := strings.Cut(param, "="); ok {
+			p.params[strings.ToUpper(k)] = strings.Trim(v, `"`)
+		}
+	}
+	return p, true
+}
+
+// busyEvent converts the properties of a VEVENT. Cancelled events are skipped.
+func busyEvent(props map[string]property, loc *time.Location) (BusyEvent, bool, error) {
+	if strings.EqualFold(props["STATUS"].value, "CANCELLED") {
+		return BusyEvent{}, false, nil
+	}
+	startProp, ok := props["DTSTART"]
+	if !ok {
+		return BusyEvent{}, false, fmt.Errorf("event %q has no DTSTART", props["UID"].value)
+	}
+	start, allDay, err := parseTime(startProp, loc)
+	if err != nil {
+		return BusyEvent{}, false, err
+	}
+
+	// DTEND is exclusive: an all-day event ends the day before, a timed one
+	// ends on the day of its last moment.
+	end := start
+	if endProp, ok := props["DTEND"]; ok {
+		end, _, err = parseTime(endProp, loc)
+		if err != nil {
+			return BusyEvent{}, false, err
+		}
+		if end.After(start) {
+			if allDay {
+				end = end.AddDate(0, 0, -1)
+			} else {
+				end = end.Add(-time.Nanosecond)
+			}
+		} else {
+			end = start
+		}
+	}
+
+	return BusyEvent{
+		UID:     props["UID"].value,
+		Summary: unescapeText(props["SUMMARY"].value),
+		Start:   day(start),
+		End:     day(end),
+	}, true, nil
+}
+
+// parseTime reads a DATE or DATE-TIME value and reports whether it is a DATE.
+func parseTime(p property, loc *time.Location) (time.Time, bool, error) {
+	value := strings.TrimSpace(p.value)
+	if strings.EqualFold(p.params["VALUE"], "DATE") || len(value) == len("20060102") {
+		t, err := time.ParseInLocation("20060102", value, time.UTC)
+		if err != nil {
+			return time.Time{}, false, fmt.Errorf("invalid %s %q: %w", p.name, value, err)
+		}
+		return t, true, nil
+	}
+
+	if strings.HasSuffix(value, "Z") {
+		t, err := time.Parse("20060102T150405Z", value)
+		if err != nil {
+			return time.Time{}, false, fmt.Errorf("invalid %s %q: %w", p.name, value, err)
+		}
+		return t.In(loc), false, nil
+	}
+
+	tz := loc
+	if name := p.params["TZID"]; name != "" {
+		if l, err := time.LoadLocation(name); err == nil {
+			tz = l
+		}
+	}
+	t, err := time.ParseInLocation("20060102T150405", value, tz)
+	if err != nil {
+		return time.Time{}, false, fmt.Errorf("invalid %s %q: %w", p.name, value, err)
+	}
+	return t, false, nil
+}
+
+// day returns the calendar day of t as midnight UTC.
+func day(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// unfold joins folded content lines.
+func unfold(r io.Reader) ([]string, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+
+	var lines []string
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")) && len(lines) > 0 {
+			lines[len(lines)-1] += line[1:]
+			continue
+		}
+		if line != "" {
+			lines = append(lines, line)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read calendar: %w", err)
+	}
+	return lines, nil
+}
+
+// unescapeText reverses escapeText.
+func unescapeText(s string) string {
+	return strings.NewReplacer(`\\`, `\`, `\;`, ";", `\,`, ",", `\n`, "\n", `\N`, "\n").Replace(s)
+}
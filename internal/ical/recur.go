@@ -0,0 +1,190 @@
+package ical
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// errUnsupportedRule marks a recurrence that Parse cannot expand. Such events
+// are skipped rather than read as a single occurrence.
+var errUnsupportedRule = errors.New("unsupported recurrence rule")
+
+var weekdayCodes = map[string]time.Weekday{
+	"MO": time.Monday,
+	"TU": time.Tuesday,
+	"WE": time.Wednesday,
+	"TH": time.Thursday,
+	"FR": time.Friday,
+	"SA": time.Saturday,
+	"SU": time.Sunday,
+}
+
+// recurrence is an RRULE limited to DAILY, WEEKLY and MONTHLY frequencies.
+// BYDAY is supported as a plain weekday list for DAILY and WEEKLY rules.
+type recurrence struct {
+	freq     string
+	interval int
+	count    int
+	until    time.Time
+	byDay    []time.Weekday
+	wkst     time.Weekday
+}
+
+// parseRule reads the value of an RRULE property. UNTIL is kept as a day.
+func parseRule(value string, loc *time.Location) (recurrence, error) {
+	r := recurrence{interval: 1, wkst: time.Monday}
+	for _, part := range strings.Split(value, ";") {
+		k, v, ok := strings.Cut(part, "=")
+		if !ok {
+			return r, fmt.Errorf("%w: %q", errUnsupportedRule, value)
+		}
+		switch strings.ToUpper(k) {
+		case "FREQ":
+			r.freq = strings.ToUpper(v)
+		case "INTERVAL":
+			n, err := strconv.Atoi(v)
+			if err != nil || n < 1 {
+				return r, fmt.Errorf("%w: INTERVAL=%s", errUnsupportedRule, v)
+			}
+			r.interval = n
+		case "COUNT":
+			n, err := strconv.Atoi(v)
+			if err != nil || n < 1 {
+				return r, fmt.Errorf("%w: COUNT=%s", errUnsupportedRule, v)
+			}
+			r.count = n
+		case "UNTIL":
+			until, _, err := parseTime(property{name: "UNTIL", params: map[string]string{}, value: v}, loc)
+			if err != nil {
+				return r, fmt.Errorf("%w: %w", errUnsupportedRule, err)
+			}
+			r.until = day(until)
+		case "BYDAY":
+			for _, code := range strings.Split(v, ",") {
+				wd, ok := weekdayCodes[strings.ToUpper(code)]
+				if !ok {
+					return r, fmt.Errorf("%w: BYDAY=%s", errUnsupportedRule, v)
+				}
+				r.byDay = append(r.byDay, wd)
+			}
+		case "WKST":
+			wd, ok := weekdayCodes[strings.ToUpper(v)]
+			if !ok {
+				return r, fmt.Errorf("%w: WKST=%s", errUnsupportedRule, v)
+			}
+			r.wkst = wd
+		default:
+			return r, fmt.Errorf("%w: %s", errUnsupportedRule, k)
+		}
+	}
+
+	switch {
+	case r.freq != "DAILY" && r.freq != "WEEKLY" && r.freq != "MONTHLY":
+		return r, fmt.Errorf("%w: FREQ=%s", errUnsupportedRule, r.freq)
+	case r.freq == "MONTHLY" && len(r.byDay) > 0:
+		return r, fmt.Errorf("%w: MONTHLY with BYDAY", errUnsupportedRule)
+	}
+	return r, nil
+}
+
+// starts returns the start days of the occurrences from first up to and
+// including horizon.
+func (r recurrence) starts(first, horizon time.Time) []time.Time {
+	last := horizon
+	if !r.until.IsZero() && r.until.Before(last) {
+		last = r.until
+	}
+
+	var days []time.Time
+	emitted := 0
+	// emit reports false once the rule is exhausted.
+	emit := func(d time.Time) bool {
+		if d.After(last) || (r.count > 0 && emitted >= r.count) {
+			return false
+		}
+		emitted++
+		days = append(days, d)
+		return true
+	}
+
+	switch r.freq {
+	case "DAILY":
+		for d := first; !d.After(last); d = d.AddDate(0, 0, r.interval) {
+			if len(r.byDay) > 0 && !containsWeekday(r.byDay, d.Weekday()) {
+				continue
+			}
+			if !emit(d) {
+				break
+			}
+		}
+	case "WEEKLY":
+		byDay := r.byDay
+		if len(byDay) == 0 {
+			byDay = []time.Weekday{first.Weekday()}
+		}
+		offsets := make([]int, 0, len(byDay))
+		for _, wd := range byDay {
+			offsets = append(offsets, (int(wd)-int(r.wkst)+7)%7)
+		}
+		sort.Ints(offsets)
+
+		weekStart := first.AddDate(0, 0, -((int(first.Weekday()) - int(r.wkst) + 7) % 7))
+	weeks:
+		for ; !weekStart.After(last); weekStart = weekStart.AddDate(0, 0, 7*r.interval) {
+			for _, off := range offsets {
+				d := weekStart.AddDate(0, 0, off)
+				if d.Before(first) {
+					continue
+				}
+				if !emit(d) {
+					break weeks
+				}
+			}
+		}
+	case "MONTHLY":
+		for i := 0; ; i += r.interval {
+			d := first.AddDate(0, i, 0)
+			if d.After(last) {
+				break
+			}
+			// Months without this day of month have no occurrence.
+			if d.Day() != first.Day() {
+				continue
+			}
+			if !emit(d) {
+				break
+			}
+		}
+	}
+	return days
+}
+
+func containsWeekday(days []time.Weekday, wd time.Weekday) bool {
+	for _, d := range days {
+		if d == wd {
+			return true
+		}
+	}
+	return false
+}
+
+// parseDates reads the days of an RDATE or EXDATE property, which may list
+// several comma-separated values.
+func parseDates(p property, loc *time.Location) ([]time.Time, error) {
+	if strings.EqualFold(p.params["VALUE"], "PERIOD") {
+		return nil, fmt.Errorf("%w: %s with periods", errUnsupportedRule, p.name)
+	}
+	var days []time.Time
+	for _, v := range strings.Split(p.value, ",") {
+		t, _, err := parseTime(property{name: p.name, params: p.params, value: v}, loc)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", errUnsupportedRule, err)
+		}
+		days = append(days, day(t))
+	}
+	return days, nil
+}
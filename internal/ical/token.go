@@ -0,0 +1,107 @@
+package ical
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"bronivik/internal/models"
+)
+
+// PathPrefix is the HTTP API path under which feeds are served.
+const PathPrefix = "/api/v1/ics/"
+
+// ErrInvalidToken is returned for malformed tokens.
+var ErrInvalidToken = errors.New("invalid calendar token")
+
+// Signer issues and checks feed tokens. A token is "<feed id>.<signature>", where the
+// signature is an HMAC-SHA256 of the feed ID, scope and subject, so a token cannot be
+// reused for another feed. Revocation is checked against the stored feed, not the token.
+type Signer struct {
+	secret []byte
+}
+
+// NewSigner returns a signer using secret as the HMAC key.
+func NewSigner(secret string) *Signer {
+	return &Signer{secret: []byte(secret)}
+}
+
+// Token returns the token of a feed.
+func (s *Signer) Token(feed *models.CalendarFeed) string {
+	return strconv.FormatInt(feed.ID, 10) + "." + base64.RawURLEncoding.EncodeToString(s.sign(feed))
+}
+
+// Verify reports whether token was issued for feed.
+func (s *Signer) Verify(feed *models.CalendarFeed, token string) bool {
+	_, sig, found := strings.Cut(token, ".")
+	if !found {
+		return false
+	}
+	got, err := base64.RawURLEncoding.DecodeString(sig)
+	if err != nil {
+		return false
+	}
+	return hmac.Equal(got, s.sign(feed))
+}
+
+// URL returns the subscription URL of a feed on the API served at baseURL.
+func (s *Signer) URL(baseURL string, feed *models.CalendarFeed) string {
+	return strings.TrimRight(baseURL, "/") + Path(feed.Scope, feed.SubjectID) + "?token=" + url.QueryEscape(s.Token(feed))
+}
+
+func (s *Signer) sign(feed *models.CalendarFeed) []byte {
+	mac := hmac.New(sha256.New, s.secret)
+	fmt.Fprintf(mac, "%d:%s:%d", feed.ID, feed.Scope, feed.SubjectID)
+	return mac.Sum(nil)
+}
+
+// FeedID extracts the feed ID from a token without checking its signature.
+func FeedID(token string) (int64, error) {
+	idPart, _, found := strings.Cut(token, ".")
+	if !found {
+		return 0, ErrInvalidToken
+	}
+	id, err := strconv.ParseInt(idPart, 10, 64)
+	if err != nil || id <= 0 {
+		return 0, ErrInvalidToken
+	}
+	return id, nil
+}
+
+// Path returns the API path of a feed: user/{telegram id}.ics, item/{item id}.ics or all.ics.
+func Path(scope string, subjectID int64) string {
+	if scope == models.CalendarScopeAll {
+		return PathPrefix + "all.ics"
+	}
+	return fmt.Sprintf("%s%s/%d.ics", PathPrefix, scope, subjectID)
+}
+
+// ParsePath is the inverse of Path.
+func ParsePath(path string) (scope string, subjectID int64, ok bool) {
+	rest, found := strings.CutPrefix(path, PathPrefix)
+	if !found {
+		return "", 0, false
+	}
+	rest, found = strings.CutSuffix(rest, ".ics")
+	if !found {
+		return "", 0, false
+	}
+	if rest == models.CalendarScopeAll {
+		return models.CalendarScopeAll, 0, true
+	}
+
+	scope, idPart, found := strings.Cut(rest, "/")
+	if !found || (scope != models.CalendarScopeUser && scope != models.CalendarScopeItem) {
+		return "", 0, false
+	}
+	subjectID, err := strconv.ParseInt(idPart, 10, 64)
+	if err != nil || subjectID <= 0 {
+		return "", 0, false
+	}
+	return scope, subjectID, true
+}
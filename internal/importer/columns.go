@@ -0,0 +1,153 @@
+package importer
+
+import (
+	"fmt"
+	"strings"
+)
+
+// field is an importable field with the headers it is recognized by.
+type field struct {
+	name    string
+	aliases []string
+}
+
+// fields lists the importable fields of each kind. Booking fields use the column names
+// of the booking export, so an exported file can be imported back.
+var fields = map[string][]field{
+	KindBookings: {
+		{"date", []string{"дата", "дата аренды"}},
+		{"item", []string{"item_name", "аппарат", "оборудование"}},
+		{"item_id", []string{"id аппарата"}},
+		{"user_name", []string{"client", "name", "клиент", "имя", "фио"}},
+		{"phone", []string{"телефон"}},
+		{"user_id", []string{"telegram_id"}},
+		{"user_nickname", []string{"username", "ник"}},
+		{"status", []string{"статус"}},
+		{"comment", []string{"комментарий"}},
+		{"price", []string{"цена", "стоимость"}},
+		{"deposit", []string{"залог"}},
+		{"created_at", []string{"создана"}},
+	},
+	KindItems: {
+		{"name", []string{"item", "item_name", "название", "аппарат"}},
+		{"total_quantity", []string{"quantity", "количество"}},
+		{"description", []string{"описание"}},
+		{"category", []string{"категория"}},
+		{"price_per_day", []string{"price", "цена"}},
+		{"weekend_price", []string{"цена выходные"}},
+		{"deposit", []string{"залог"}},
+		{"sort_order", []string{"порядок"}},
+		{"is_active", []string{"active", "активен"}},
+	},
+	KindUsers: {
+		{"telegram_id", []string{"user_id", "id"}},
+		{"first_name", []string{"name", "имя"}},
+		{"last_name", []string{"фамилия"}},
+		{"username", []string{"user_nickname", "ник"}},
+		{"phone", []string{"телефон"}},
+		{"language_code", []string{"language", "язык"}},
+	},
+}
+
+// required lists groups of fields of which at least one column must be present.
+var required = map[string][][]string{
+	KindBookings: {{"date"}, {"item", "item_id"}, {"user_name", "phone"}},
+	KindItems:    {{"name"}, {"total_quantity"}},
+	KindUsers:    {{"telegram_id"}, {"first_name"}},
+}
+
+// columns maps field names to column indexes of a table.
+type columns struct {
+	index  map[string]int
+	header []string
+}
+
+// matchColumns finds the column of every field: the mapped header if there is one,
+// otherwise a header equal to the field name or one of its aliases.
+func matchColumns(kind string, header []string, mapping map[string]string) (*columns, error) {
+	cols := &columns{index: make(map[string]int), header: header}
+	known := make(map[string]bool)
+	for _, f := range fields[kind] {
+		known[f.name] = true
+	}
+	for name, h := range mapping {
+		if !known[name] {
+			return nil, fmt.Errorf("%w %q for %s", ErrUnknownField, name, kind)
+		}
+		i := findHeader(header, h)
+		if i < 0 {
+			return nil, fmt.Errorf("column %q mapped to %s is not in the file", h, name)
+		}
+		cols.index[name] = i
+	}
+
+	for _, f := range fields[kind] {
+		if _, ok := cols.index[f.name]; ok {
+			continue
+		}
+		for _, h := range append([]string{f.name}, f.aliases...) {
+			if i := findHeader(header, h); i >= 0 && !cols.uses(i) {
+				cols.index[f.name] = i
+				break
+			}
+		}
+	}
+
+	for _, group := range required[kind] {
+		found := false
+		for _, name := range group {
+			_, ok := cols.index[name]
+			found = found || ok
+		}
+		if !found {
+			return nil, fmt.Errorf("%w: %s", ErrMissingColumn, strings.Join(group, " or "))
+		}
+	}
+	return cols, nil
+}
+
+func findHeader(header []string, name string) int {
+	name = normalizeHeader(name)
+	for i, h := range header {
+		if normalizeHeader(h) == name {
+			return i
+		}
+	}
+	return -1
+}
+
+func normalizeHeader(s string) string {
+	return strings.ToLower(strings.Join(strings.Fields(s), " "))
+}
+
+func (c *columns) uses(i int) bool {
+	for _, idx := range c.index {
+		if idx == i {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *columns) headers() map[string]string {
+	out := make(map[string]string, len(c.index))
+	for name, i := range c.index {
+		out[name] = c.header[i]
+	}
+	return out
+}
+
+// has reports whether the field has a column.
+func (c *columns) has(name string) bool {
+	_, ok := c.index[name]
+	return ok
+}
+
+// value returns the cell of the field in a row, empty if there is no such column.
+func (c *columns) value(row Row, name string) string {
+	i, ok := c.index[name]
+	if !ok || i >= len(row.Cells) {
+		return ""
+	}
+	return row.Cells[i]
+}
@@ -0,0 +1,225 @@
+// Package importer loads bookings, items and users from CSV or XLSX spreadsheets.
+// Columns are matched to fields by name, by a known alias or by an explicit mapping.
+// Every row is validated and checked for duplicates before anything is written, and a
+// Report lists what was imported and why the other rows were skipped. A dry run
+// goes through the same steps, including capacity checks, without saving anything.
+package importer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"bronivik/internal/models"
+)
+
+// Kinds of imported records.
+const (
+	KindBookings = "bookings"
+	KindItems    = "items"
+	KindUsers    = "users"
+)
+
+// DefaultBatchSize is the number of records saved in one transaction.
+const DefaultBatchSize = 500
+
+var (
+	ErrUnknownKind   = errors.New("unknown import kind, expected bookings, items or users")
+	ErrUnknownField  = errors.New("unknown field")
+	ErrMissingColumn = errors.New("required column is missing")
+	ErrRequired      = errors.New("value is required")
+	ErrInvalidValue  = errors.New("invalid value")
+	ErrUnknownItem   = errors.New("unknown item")
+)
+
+// Store is the storage the importer reads existing records from and writes to.
+// GetItemByID and GetItemByName must wrap sql.ErrNoRows for a missing item.
+// ImportBookings returns a per-booking error for bookings that did not fit into item capacity.
+type Store interface {
+	GetItemByID(ctx context.Context, id int64) (*models.Item, error)
+	GetItemByName(ctx context.Context, name string) (*models.Item, error)
+	GetAllUsers(ctx context.Context) ([]*models.User, error)
+	StreamBookings(ctx context.Context, filter models.BookingFilter, fn func(*models.Booking) error) error
+	ImportItems(ctx context.Context, items []*models.Item, dryRun bool) error
+	ImportUsers(ctx context.Context, users []*models.User, dryRun bool) error
+	ImportBookings(ctx context.Context, bookings []*models.Booking, dryRun bool) ([]error, error)
+}
+
+// Options control a single import.
+type Options struct {
+	Kind      string
+	Mapping   map[string]string // field name to column header, overrides the automatic matching
+	DryRun    bool
+	BatchSize int // DefaultBatchSize if zero
+}
+
+// RowError describes why a row was not imported. Column is empty for errors that
+// concern the whole row.
+type RowError struct {
+	Row    int
+	Column string
+	Value  string
+	Err    error
+}
+
+func (e RowError) Error() string {
+	switch {
+	case e.Column == "":
+		return fmt.Sprintf("row %d: %v", e.Row, e.Err)
+	case e.Value == "":
+		return fmt.Sprintf("row %d, column %q: %v", e.Row, e.Column, e.Err)
+	}
+	return fmt.Sprintf("row %d, column %q: %v: %q", e.Row, e.Column, e.Err, e.Value)
+}
+
+func (e RowError) Unwrap() error {
+	return e.Err
+}
+
+// Report is the result of an import. With DryRun, Imported is the number of rows
+// that would have been imported.
+type Report struct {
+	Kind       string
+	DryRun     bool
+	Columns    map[string]string // field name to the matched column header
+	Ignored    []string          // headers not matched to any field
+	Rows       int
+	Imported   int
+	Duplicates []int // rows skipped as duplicates of existing records or earlier rows
+	Errors     []RowError
+}
+
+// WriteText writes a plain text summary of the report, listing at most maxErrors errors
+// (all of them if maxErrors is zero).
+func (r *Report) WriteText(w io.Writer, maxErrors int) error {
+	mode := "import"
+	if r.DryRun {
+		mode = "dry run"
+	}
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "%s %s: %d rows, %d imported, %d duplicates, %d errors\n",
+		r.Kind, mode, r.Rows, r.Imported, len(r.Duplicates), len(r.Errors))
+
+	fields := make([]string, 0, len(r.Columns))
+	for field := range r.Columns {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+	for _, field := range fields {
+		fmt.Fprintf(&sb, "  %s <- %q\n", field, r.Columns[field])
+	}
+	if len(r.Ignored) > 0 {
+		fmt.Fprintf(&sb, "ignored columns: %s\n", strings.Join(r.Ignored, ", "))
+	}
+	if len(r.Duplicates) > 0 {
+		fmt.Fprintf(&sb, "duplicate rows: %s\n", joinInts(r.Duplicates))
+	}
+	for i, e := range r.Errors {
+		if maxErrors > 0 && i == maxErrors {
+			fmt.Fprintf(&sb, "... and %d more errors\n", len(r.Errors)-i)
+			break
+		}
+		fmt.Fprintf(&sb, "%s\n", e.Error())
+	}
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+// ParseKind validates an import kind.
+func ParseKind(s string) (string, error) {
+	switch s = strings.ToLower(strings.TrimSpace(s)); s {
+	case KindBookings, KindItems, KindUsers:
+		return s, nil
+	}
+	return "", fmt.Errorf("%w: %q", ErrUnknownKind, s)
+}
+
+// ParseMapping parses a column mapping like "date=Дата заявки,item=Аппарат".
+func ParseMapping(s string) (map[string]string, error) {
+	mapping := make(map[string]string)
+	for _, pair := range strings.Split(s, ",") {
+		if strings.TrimSpace(pair) == "" {
+			continue
+		}
+		field, header, ok := strings.Cut(pair, "=")
+		field, header = strings.ToLower(strings.TrimSpace(field)), strings.TrimSpace(header)
+		if !ok || field == "" || header == "" {
+			return nil, fmt.Errorf("invalid column mapping %q, expected field=header", pair)
+		}
+		mapping[field] = header
+	}
+	return mapping, nil
+}
+
+// Run validates the table rows as records of opts.Kind and imports them in batches.
+// Rows with errors and duplicates are skipped and listed in the report. An error is
+// returned if the columns do not fit the kind or storage fails; the report then
+// counts the batches committed before the failure.
+func Run(ctx context.Context, store Store, table *Table, opts Options) (*Report, error) {
+	kind, err := ParseKind(opts.Kind)
+	if err != nil {
+		return nil, err
+	}
+	cols, err := matchColumns(kind, table.Header, opts.Mapping)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &Report{Kind: kind, DryRun: opts.DryRun, Columns: cols.headers(), Rows: len(table.Rows)}
+	for i, header := range table.Header {
+		if !cols.uses(i) && header != "" {
+			report.Ignored = append(report.Ignored, header)
+		}
+	}
+
+	switch kind {
+	case KindBookings:
+		err = importBookings(ctx, store, table, cols, opts, report)
+	case KindItems:
+		err = importItems(ctx, store, table, cols, opts, report)
+	case KindUsers:
+		err = importUsers(ctx, store, table, cols, opts, report)
+	}
+	sort.SliceStable(report.Errors, func(i, j int) bool { return report.Errors[i].Row < report.Errors[j].Row })
+	return report, err
+}
+
+// saveBatches calls save for consecutive batches of n records; lines are their row
+// numbers. A dry run is saved as one batch, so that capacity checks of later rows see
+// the earlier ones.
+func saveBatches(n int, lines []int, opts Options, report *Report, save func(from, to int) ([]error, error)) error {
+	size := opts.BatchSize
+	if size <= 0 {
+		size = DefaultBatchSize
+	}
+	if opts.DryRun {
+		size = n
+	}
+
+	for from := 0; from < n; from += size {
+		to := min(from+size, n)
+		rejected, err := save(from, to)
+		if err != nil {
+			return fmt.Errorf("failed to import rows %d-%d: %w", lines[from], lines[to-1], err)
+		}
+		report.Imported += to - from
+		for i, rowErr := range rejected {
+			if rowErr != nil {
+				report.Errors = append(report.Errors, RowError{Row: lines[from+i], Err: rowErr})
+				report.Imported--
+			}
+		}
+	}
+	return nil
+}
+
+func joinInts(values []int) string {
+	parts := make([]string, len(values))
+	for i, v := range values {
+		parts[i] = fmt.Sprint(v)
+	}
+	return strings.Join(parts, ", ")
+}
@@ -0,0 +1,341 @@
+package importer
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"bronivik/internal/exports"
+	"bronivik/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xuri/excelize/v2"
+)
+
+var errNoCapacity = errors.New("no capacity")
+
+// memStore keeps records in memory and checks capacity like the database.
+type memStore struct {
+	items    []*models.Item
+	users    []*models.User
+	bookings []*models.Booking
+	batches  int
+}
+
+func (s *memStore) GetItemByID(_ context.Context, id int64) (*models.Item, error) {
+	for _, item := range s.items {
+		if item.ID == id {
+			return item, nil
+		}
+	}
+	return nil, fmt.Errorf("failed to get item by id: %w", sql.ErrNoRows)
+}
+
+func (s *memStore) GetItemByName(_ context.Context, name string) (*models.Item, error) {
+	for _, item := range s.items {
+		if item.Name == name {
+			return item, nil
+		}
+	}
+	return nil, fmt.Errorf("failed to get item by name: %w", sql.ErrNoRows)
+}
+
+func (s *memStore) GetAllUsers(context.Context) ([]*models.User, error) {
+	return s.users, nil
+}
+
+func (s *memStore) StreamBookings(_ context.Context, filter models.BookingFilter, fn func(*models.Booking) error) error {
+	for _, b := range s.bookings {
+		if b.Date.Before(filter.DateFrom) || b.Date.After(filter.DateTo) {
+			continue
+		}
+		if err := fn(b); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *memStore) ImportItems(_ context.Context, items []*models.Item, dryRun bool) error {
+	s.batches++
+	if !dryRun {
+		for _, item := range items {
+			item.ID = int64(len(s.items) + 1)
+			s.items = append(s.items, item)
+		}
+	}
+	return nil
+}
+
+func (s *memStore) ImportUsers(_ context.Context, users []*models.User, dryRun bool) error {
+	s.batches++
+	if !dryRun {
+		s.users = append(s.users, users...)
+	}
+	return nil
+}
+
+func (s *memStore) ImportBookings(ctx context.Context, bookings []*models.Booking, dryRun bool) ([]error, error) {
+	s.batches++
+	saved := s.bookings
+	rejected := make([]error, len(bookings))
+	for i, b := range bookings {
+		item, _ := s.GetItemByID(ctx, b.ItemID)
+		taken := 0
+		for _, other := range saved {
+			if other.ItemID == b.ItemID && other.Date.Equal(b.Date) && other.Status != models.StatusCanceled {
+				taken++
+			}
+		}
+		if b.Status != models.StatusCanceled && int64(taken) >= item.TotalQuantity {
+			rejected[i] = errNoCapacity
+			continue
+		}
+		saved = append(saved, b)
+	}
+	if !dryRun {
+		s.bookings = saved
+	}
+	return rejected, nil
+}
+
+func newMemStore() *memStore {
+	return &memStore{items: []*models.Item{
+		{ID: 1, Name: "Camera", TotalQuantity: 2},
+		{ID: 2, Name: "Light", TotalQuantity: 1},
+	}}
+}
+
+func csvTable(t *testing.T, content string) *Table {
+	table, err := ReadTable("data.csv", strings.NewReader(content))
+	require.NoError(t, err)
+	return table
+}
+
+func TestReadTable(t *testing.T) {
+	t.Run("CSV", func(t *testing.T) {
+		table := csvTable(t, "\ufeffДата;Аппарат;Клиент\n10.04.2030;Camera;Иван\n\n11.04.2030;Light;\"Петров, Петр\"\n")
+		assert.Equal(t, []string{"Дата", "Аппарат", "Клиент"}, table.Header)
+		require.Len(t, table.Rows, 2)
+		assert.Equal(t, 2, table.Rows[0].Line)
+		assert.Equal(t, 4, table.Rows[1].Line)
+		assert.Equal(t, "Петров, Петр", table.Rows[1].Cells[2])
+	})
+
+	t.Run("XLSX", func(t *testing.T) {
+		f := excelize.NewFile()
+		require.NoError(t, f.SetSheetRow("Sheet1", "A1", &[]interface{}{"date", "item", "user_name"}))
+		require.NoError(t, f.SetSheetRow("Sheet1", "A3", &[]interface{}{time.Date(2030, 4, 10, 0, 0, 0, 0, time.UTC), "Camera", "Иван"}))
+		var buf bytes.Buffer
+		require.NoError(t, f.Write(&buf))
+
+		table, err := ReadTable("data.XLSX", &buf)
+		require.NoError(t, err)
+		require.Len(t, table.Rows, 1)
+		assert.Equal(t, 3, table.Rows[0].Line)
+
+		date, ok := parseTime(table.Rows[0].Cells[0])
+		require.True(t, ok)
+		assert.Equal(t, "2030-04-10", date.Format("2006-01-02"))
+	})
+
+	_, err := ReadTable("data.pdf", strings.NewReader(""))
+	assert.ErrorIs(t, err, ErrUnsupportedFile)
+	_, err = ReadTable("data.csv", strings.NewReader("\n\n"))
+	assert.Error(t, err)
+}
+
+func TestColumns(t *testing.T) {
+	store := newMemStore()
+	table := csvTable(t, "Дата заявки,Техника,Телефон,Примечание\n10.04.2030,Camera,89991234567,x\n")
+
+	_, err := Run(context.Background(), store, table, Options{Kind: KindBookings})
+	assert.ErrorIs(t, err, ErrMissingColumn)
+
+	mapping, err := ParseMapping("date=Дата заявки, item = техника")
+	require.NoError(t, err)
+	report, err := Run(context.Background(), store, table, Options{Kind: KindBookings, Mapping: mapping})
+	require.NoError(t, err)
+	assert.Equal(t, "Техника", report.Columns["item"])
+	assert.Equal(t, "Телефон", report.Columns["phone"])
+	assert.Equal(t, []string{"Примечание"}, report.Ignored)
+	assert.Equal(t, 1, report.Imported)
+	assert.Equal(t, "79991234567", store.bookings[0].Phone)
+
+	_, err = Run(context.Background(), store, table, Options{Kind: KindBookings, Mapping: map[string]string{"colour": "Техника"}})
+	assert.ErrorIs(t, err, ErrUnknownField)
+	_, err = Run(context.Background(), store, table, Options{Kind: KindBookings, Mapping: map[string]string{"date": "Нет"}})
+	assert.Error(t, err)
+	_, err = Run(context.Background(), store, table, Options{Kind: "orders"})
+	assert.ErrorIs(t, err, ErrUnknownKind)
+	_, err = ParseMapping("date")
+	assert.Error(t, err)
+}
+
+func TestImportBookings(t *testing.T) {
+	ctx := context.Background()
+	store := newMemStore()
+	store.bookings = []*models.Booking{{
+		ID: 1, ItemID: 1, ItemName: "Camera", Date: time.Date(2030, 4, 10, 0, 0, 0, 0, time.UTC),
+		UserName: "Иван", Phone: "79990000001", Status: models.StatusConfirmed,
+	}}
+	table := csvTable(t, strings.Join([]string{
+		"дата,аппарат,клиент,телефон,статус,цена",
+		"10.04.2030,Camera,иван,,подтверждена,1000",     // 2: дубликат заявки из базы по имени
+		"10.04.2030,Camera,Анна,8 999 000-00-02,,1 500", // 3
+		"10.04.2030,Camera,Анна,+79990000002,,",         // 4: дубликат строки 3
+		"10.04.2030,Camera,Олег,,,",                     // 5: мест нет
+		"10.04.2030,Projector,Олег,,,",                  // 6
+		"31.02.2030,Light,Олег,,done,abc",               // 7
+		"11.04.2030,Light,,,,",                          // 8
+		"01.01.2020,Light,Олег,,,",                      // 9
+	}, "\n"))
+
+	t.Run("DryRun", func(t *testing.T) {
+		report, err := Run(ctx, store, table, Options{Kind: KindBookings, DryRun: true, BatchSize: 1})
+		require.NoError(t, err)
+		assert.Equal(t, 8, report.Rows)
+		assert.Equal(t, 2, report.Imported)
+		assert.Equal(t, []int{2, 4}, report.Duplicates)
+		assert.Equal(t, 1, store.batches, "a dry run is checked as one batch")
+		assert.Len(t, store.bookings, 1)
+
+		rows := make(map[int][]error)
+		for _, e := range report.Errors {
+			rows[e.Row] = append(rows[e.Row], e.Err)
+		}
+		assert.Equal(t, []error{errNoCapacity}, rows[5])
+		assert.Equal(t, []error{ErrUnknownItem}, rows[6])
+		assert.Equal(t, []error{ErrInvalidValue, ErrInvalidValue, ErrInvalidValue}, rows[7])
+		assert.Equal(t, []error{ErrRequired}, rows[8])
+		assert.Len(t, report.Errors, 6)
+
+		var text bytes.Buffer
+		require.NoError(t, report.WriteText(&text, 2))
+		assert.Contains(t, text.String(), "bookings dry run: 8 rows, 2 imported, 2 duplicates, 6 errors")
+		assert.Contains(t, text.String(), `row 5: no capacity`)
+		assert.Contains(t, text.String(), "and 4 more errors")
+	})
+
+	t.Run("Import", func(t *testing.T) {
+		store.batches = 0
+		report, err := Run(ctx, store, table, Options{Kind: KindBookings, BatchSize: 1})
+		require.NoError(t, err)
+		assert.Equal(t, 2, report.Imported)
+		assert.Equal(t, 3, store.batches)
+		require.Len(t, store.bookings, 3)
+
+		anna := store.bookings[1]
+		assert.Equal(t, "Анна", anna.UserName)
+		assert.Equal(t, "79990000002", anna.Phone)
+		assert.Equal(t, int64(1500), anna.Price)
+		assert.Equal(t, models.StatusConfirmed, anna.Status)
+		assert.Equal(t, models.StatusCompleted, store.bookings[2].Status, "past bookings are completed")
+
+		// Повторный импорт того же файла не создает заявок
+		report, err = Run(ctx, store, table, Options{Kind: KindBookings})
+		require.NoError(t, err)
+		assert.Zero(t, report.Imported)
+		assert.Equal(t, []int{2, 3, 4, 9}, report.Duplicates)
+	})
+}
+
+func TestImportExportedBookings(t *testing.T) {
+	ctx := context.Background()
+	store := newMemStore()
+	day := time.Date(2030, 4, 10, 0, 0, 0, 0, time.UTC)
+	source := []*models.Booking{
+		{ID: 7, ItemID: 1, ItemName: "Camera", Date: day, UserName: "Иван", Phone: "79990000001", Status: models.StatusConfirmed, Price: 900},
+		{ID: 8, ItemID: 2, ItemName: "Light", Date: day, UserName: "Анна", Status: models.StatusCanceled},
+	}
+
+	var buf bytes.Buffer
+	out, err := exports.NewBookingWriter(&buf, exports.FormatXLSX)
+	require.NoError(t, err)
+	for _, b := range source {
+		require.NoError(t, out.Write(b))
+	}
+	require.NoError(t, out.Close())
+
+	table, err := ReadTable("bookings.xlsx", &buf)
+	require.NoError(t, err)
+	report, err := Run(ctx, store, table, Options{Kind: KindBookings})
+	require.NoError(t, err)
+	assert.Empty(t, report.Errors)
+	assert.Equal(t, 2, report.Imported)
+	assert.Equal(t, "item", mapKey(report.Columns, "item_name"))
+
+	require.Len(t, store.bookings, 2)
+	assert.Equal(t, int64(900), store.bookings[0].Price)
+	assert.Equal(t, models.StatusCanceled, store.bookings[1].Status)
+	assert.True(t, day.Equal(store.bookings[1].Date))
+}
+
+func TestImportItemsAndUsers(t *testing.T) {
+	ctx := context.Background()
+	store := newMemStore()
+
+	items := csvTable(t, strings.Join([]string{
+		"Название,Количество,Категория,Цена,Активен",
+		"Tripod,3,Штативы / Большие,500,да",
+		"Camera,1,,,",
+		"Tripod,1,,,",
+		"Lens,0,,,",
+		",2,,,",
+		"Flash,1,,,нет",
+	}, "\n"))
+	report, err := Run(ctx, store, items, Options{Kind: KindItems})
+	require.NoError(t, err)
+	assert.Equal(t, 2, report.Imported)
+	assert.Equal(t, []int{3, 4}, report.Duplicates)
+	require.Len(t, report.Errors, 2)
+	assert.Equal(t, "Количество", report.Errors[0].Column)
+
+	tripod, err := store.GetItemByName(ctx, "Tripod")
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), tripod.TotalQuantity)
+	assert.Equal(t, int64(500), tripod.PricePerDay)
+	assert.Equal(t, "Штативы/Большие", tripod.Category)
+	assert.True(t, tripod.IsActive)
+	flash, err := store.GetItemByName(ctx, "Flash")
+	require.NoError(t, err)
+	assert.False(t, flash.IsActive)
+
+	store.users = []*models.User{{TelegramID: 100, FirstName: "Old"}}
+	users := csvTable(t, strings.Join([]string{
+		"telegram_id,имя,фамилия,username,телефон",
+		"100,New,,,",
+		"200,Анна,Петрова,@anna,8(999)123-45-67",
+		"200,Анна,,,",
+		"abc,Олег,,,",
+		"300,,,,",
+	}, "\n"))
+	report, err = Run(ctx, store, users, Options{Kind: KindUsers, DryRun: true})
+	require.NoError(t, err)
+	assert.Equal(t, 1, report.Imported)
+	assert.Len(t, store.users, 1)
+
+	report, err = Run(ctx, store, users, Options{Kind: KindUsers})
+	require.NoError(t, err)
+	assert.Equal(t, 1, report.Imported)
+	assert.Equal(t, []int{2, 4}, report.Duplicates)
+	assert.Len(t, report.Errors, 2)
+	require.Len(t, store.users, 2)
+	assert.Equal(t, "anna", store.users[1].Username)
+	assert.Equal(t, "79991234567", store.users[1].Phone)
+}
+
+func mapKey(m map[string]string, value string) string {
+	for k, v := range m {
+		if v == value {
+			return k
+		}
+	}
+	return ""
+}
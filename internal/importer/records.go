@@ -0,0 +1,259 @@
+package importer
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"bronivik/internal/models"
+)
+
+func importItems(ctx context.Context, store Store, table *Table, cols *columns, opts Options, report *Report) error {
+	var items []*models.Item
+	var lines []int
+	seen := make(map[string]bool)
+	for _, row := range table.Rows {
+		r := newRowReader(cols, row)
+		item := &models.Item{
+			Name:          r.required("name"),
+			Description:   r.str("description"),
+			Category:      models.NormalizeCategory(r.str("category")),
+			TotalQuantity: r.id("total_quantity"),
+			PricePerDay:   r.amount("price_per_day"),
+			WeekendPrice:  r.amount("weekend_price"),
+			Deposit:       r.amount("deposit"),
+			SortOrder:     r.amount("sort_order"),
+			IsActive:      r.boolean("is_active", true),
+		}
+		if item.TotalQuantity == 0 && r.str("total_quantity") == "" {
+			r.fail("total_quantity", ErrRequired)
+		}
+		if !r.ok(report) {
+			continue
+		}
+
+		if seen[item.Name] {
+			report.Duplicates = append(report.Duplicates, row.Line)
+			continue
+		}
+		seen[item.Name] = true
+		_, err := store.GetItemByName(ctx, item.Name)
+		if err == nil {
+			report.Duplicates = append(report.Duplicates, row.Line)
+			continue
+		}
+		if !errors.Is(err, sql.ErrNoRows) {
+			return err
+		}
+		items = append(items, item)
+		lines = append(lines, row.Line)
+	}
+
+	return saveBatches(len(items), lines, opts, report, func(from, to int) ([]error, error) {
+		return nil, store.ImportItems(ctx, items[from:to], opts.DryRun)
+	})
+}
+
+func importUsers(ctx context.Context, store Store, table *Table, cols *columns, opts Options, report *Report) error {
+	existing, err := store.GetAllUsers(ctx)
+	if err != nil {
+		return err
+	}
+	seen := make(map[int64]bool, len(existing))
+	for _, u := range existing {
+		seen[u.TelegramID] = true
+	}
+
+	var users []*models.User
+	var lines []int
+	for _, row := range table.Rows {
+		r := newRowReader(cols, row)
+		user := &models.User{
+			TelegramID:   r.id("telegram_id"),
+			FirstName:    r.required("first_name"),
+			LastName:     r.str("last_name"),
+			Username:     strings.TrimPrefix(r.str("username"), "@"),
+			Phone:        normalizePhone(r.str("phone")),
+			LanguageCode: strings.ToLower(r.str("language_code")),
+		}
+		if user.TelegramID == 0 && r.str("telegram_id") == "" {
+			r.fail("telegram_id", ErrRequired)
+		}
+		if !r.ok(report) {
+			continue
+		}
+
+		if seen[user.TelegramID] {
+			report.Duplicates = append(report.Duplicates, row.Line)
+			continue
+		}
+		seen[user.TelegramID] = true
+		users = append(users, user)
+		lines = append(lines, row.Line)
+	}
+
+	return saveBatches(len(users), lines, opts, report, func(from, to int) ([]error, error) {
+		return nil, store.ImportUsers(ctx, users[from:to], opts.DryRun)
+	})
+}
+
+func importBookings(ctx context.Context, store Store, table *Table, cols *columns, opts Options, report *Report) error {
+	now := time.Now()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	items := newItemLookup(store)
+
+	var bookings []*models.Booking
+	var lines []int
+	var from, to time.Time
+	for _, row := range table.Rows {
+		r := newRowReader(cols, row)
+		date := r.date("date")
+		if date.IsZero() && r.str("date") == "" {
+			r.fail("date", ErrRequired)
+		}
+		booking := &models.Booking{
+			Date:         date,
+			Status:       r.status("status", date, today),
+			UserID:       r.amount("user_id"),
+			UserName:     r.str("user_name"),
+			UserNickname: strings.TrimPrefix(r.str("user_nickname"), "@"),
+			Phone:        normalizePhone(r.str("phone")),
+			Comment:      r.str("comment"),
+			Price:        r.amount("price"),
+			Deposit:      r.amount("deposit"),
+			CreatedAt:    r.time("created_at"),
+		}
+		if booking.UserName == "" && booking.Phone == "" {
+			client := "user_name"
+			if !cols.has(client) {
+				client = "phone"
+			}
+			r.fail(client, ErrRequired)
+		}
+
+		item, err := items.find(ctx, r)
+		if err != nil {
+			return err
+		}
+		if !r.ok(report) {
+			continue
+		}
+		booking.ItemID, booking.ItemName = item.ID, item.Name
+
+		bookings = append(bookings, booking)
+		lines = append(lines, row.Line)
+		if from.IsZero() || date.Before(from) {
+			from = date
+		}
+		if date.After(to) {
+			to = date
+		}
+	}
+
+	// Duplicates are looked up among all bookings on the dates of the file, canceled ones included
+	seen := make(map[string]bool)
+	if len(bookings) > 0 {
+		err := store.StreamBookings(ctx, models.BookingFilter{DateFrom: from, DateTo: to}, func(b *models.Booking) error {
+			for _, key := range bookingKeys(b) {
+				seen[key] = true
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	unique := bookings[:0]
+	uniqueLines := lines[:0]
+	for i, b := range bookings {
+		keys := bookingKeys(b)
+		if seen[keys[0]] {
+			report.Duplicates = append(report.Duplicates, lines[i])
+			continue
+		}
+		for _, key := range keys {
+			seen[key] = true
+		}
+		unique = append(unique, b)
+		uniqueLines = append(uniqueLines, lines[i])
+	}
+
+	return saveBatches(len(unique), uniqueLines, opts, report, func(from, to int) ([]error, error) {
+		return store.ImportBookings(ctx, unique[from:to], opts.DryRun)
+	})
+}
+
+// bookingKeys returns the keys a booking is matched against other bookings by:
+// item, date and client. The first key identifies the client by phone if there is
+// one, otherwise by name; the rest let a booking with a name only match one that
+// also has a phone.
+func bookingKeys(b *models.Booking) []string {
+	prefix := fmt.Sprintf("%d|%s|", b.ItemID, b.Date.Format("2006-01-02"))
+	name := prefix + "name:" + strings.ToLower(strings.Join(strings.Fields(b.UserName), " "))
+	if b.Phone == "" {
+		return []string{name}
+	}
+	phone := prefix + "phone:" + normalizePhone(b.Phone)
+	if b.UserName == "" {
+		return []string{phone}
+	}
+	return []string{phone, name}
+}
+
+// itemLookup resolves booking items by ID or name, asking the store once per value.
+type itemLookup struct {
+	store  Store
+	byID   map[int64]*models.Item
+	byName map[string]*models.Item
+}
+
+func newItemLookup(store Store) *itemLookup {
+	return &itemLookup{store: store, byID: make(map[int64]*models.Item), byName: make(map[string]*models.Item)}
+}
+
+// find returns the item of the row, preferring item_id over the name. An unknown item
+// is a row error; only storage failures are returned.
+func (l *itemLookup) find(ctx context.Context, r *rowReader) (*models.Item, error) {
+	if id := r.id("item_id"); id != 0 {
+		item, ok := l.byID[id]
+		if !ok {
+			var err error
+			if item, err = l.store.GetItemByID(ctx, id); err != nil && !errors.Is(err, sql.ErrNoRows) {
+				return nil, err
+			}
+			l.byID[id] = item
+		}
+		if item == nil {
+			r.fail("item_id", ErrUnknownItem)
+		}
+		return item, nil
+	}
+
+	name := r.str("item")
+	if name == "" {
+		field := "item"
+		if !r.cols.has(field) {
+			field = "item_id"
+		}
+		if r.str("item_id") == "" {
+			r.fail(field, ErrRequired)
+		}
+		return nil, nil
+	}
+	item, ok := l.byName[name]
+	if !ok {
+		var err error
+		if item, err = l.store.GetItemByName(ctx, name); err != nil && !errors.Is(err, sql.ErrNoRows) {
+			return nil, err
+		}
+		l.byName[name] = item
+	}
+	if item == nil {
+		r.fail("item", ErrUnknownItem)
+	}
+	return item, nil
+}
@@ -0,0 +1,124 @@
+package importer
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// ErrUnsupportedFile is returned for files that are neither CSV nor XLSX.
+var ErrUnsupportedFile = errors.New("unsupported file type, expected .csv or .xlsx")
+
+// Table is a spreadsheet read by ReadTable: the header and the non-empty rows below it.
+type Table struct {
+	Header []string
+	Rows   []Row
+}
+
+// Row is a data row with its 1-based line number in the source, the header being line 1.
+type Row struct {
+	Line  int
+	Cells []string
+}
+
+// ReadTable reads the first sheet of an XLSX file or a CSV file, chosen by the extension
+// of name. CSV may be separated by commas or semicolons. XLSX cells are read raw, so
+// dates come as Excel serial numbers; parseDate accepts them.
+func ReadTable(name string, r io.Reader) (*Table, error) {
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".csv":
+		return readCSV(r)
+	case ".xlsx":
+		return readXLSX(r)
+	}
+	return nil, ErrUnsupportedFile
+}
+
+func readCSV(r io.Reader) (*Table, error) {
+	br := bufio.NewReader(r)
+	if bom, _ := br.Peek(3); string(bom) == "\ufeff" {
+		_, _ = br.Discard(3)
+	}
+	// Excel saves CSV with semicolons in locales with a decimal comma
+	head, err := br.Peek(4096)
+	if err != nil && !errors.Is(err, io.EOF) && !errors.Is(err, bufio.ErrBufferFull) {
+		return nil, fmt.Errorf("failed to read csv: %w", err)
+	}
+	head, _, _ = bytes.Cut(head, []byte("\n"))
+
+	cr := csv.NewReader(br)
+	cr.FieldsPerRecord = -1
+	if bytes.Count(head, []byte(";")) > bytes.Count(head, []byte(",")) {
+		cr.Comma = ';'
+	}
+
+	t := &Table{}
+	for {
+		record, err := cr.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read csv: %w", err)
+		}
+		line, _ := cr.FieldPos(0)
+		t.add(line, record)
+	}
+	return t.check()
+}
+
+func readXLSX(r io.Reader) (*Table, error) {
+	f, err := excelize.OpenReader(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read xlsx: %w", err)
+	}
+	defer f.Close()
+
+	rows, err := f.Rows(f.GetSheetName(0))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read xlsx: %w", err)
+	}
+	defer rows.Close()
+
+	t := &Table{}
+	for line := 1; rows.Next(); line++ {
+		cells, err := rows.Columns(excelize.Options{RawCellValue: true})
+		if err != nil {
+			return nil, fmt.Errorf("failed to read xlsx row %d: %w", line, err)
+		}
+		t.add(line, cells)
+	}
+	return t.check()
+}
+
+// add takes the first non-empty row as the header and skips empty rows.
+func (t *Table) add(line int, cells []string) {
+	empty := true
+	for i := range cells {
+		cells[i] = strings.TrimSpace(cells[i])
+		if cells[i] != "" {
+			empty = false
+		}
+	}
+	switch {
+	case empty:
+	case t.Header == nil:
+		t.Header = cells
+	default:
+		t.Rows = append(t.Rows, Row{Line: line, Cells: cells})
+	}
+}
+
+func (t *Table) check() (*Table, error) {
+	if t.Header == nil {
+		return nil, errors.New("file is empty")
+	}
+	return t, nil
+}
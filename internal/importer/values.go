@@ -0,0 +1,191 @@
+package importer
+
+import (
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"bronivik/internal/models"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// dateLayouts are the accepted text date formats; XLSX dates also come as serial numbers.
+var dateLayouts = []string{
+	"2006-01-02", "02.01.2006", "2.1.2006", "02/01/2006",
+	time.RFC3339, "2006-01-02 15:04:05", "2006-01-02 15:04", "02.01.2006 15:04:05", "02.01.2006 15:04",
+}
+
+// statusAliases maps Russian and legacy status names to booking statuses.
+var statusAliases = map[string]string{
+	"новая":        models.StatusPending,
+	"ожидает":      models.StatusPending,
+	"подтверждена": models.StatusConfirmed,
+	"отменена":     models.StatusCanceled,
+	"отклонена":    models.StatusCanceled,
+	"cancelled":    models.StatusCanceled,
+	"rejected":     models.StatusCanceled,
+	"завершена":    models.StatusCompleted,
+	"изменена":     models.StatusChanged,
+}
+
+// rowReader reads the fields of one row and collects their errors.
+type rowReader struct {
+	cols *columns
+	row  Row
+	errs []RowError
+}
+
+func newRowReader(cols *columns, row Row) *rowReader {
+	return &rowReader{cols: cols, row: row}
+}
+
+func (r *rowReader) fail(name string, err error) {
+	var column string
+	if i, ok := r.cols.index[name]; ok {
+		column = r.cols.header[i]
+	}
+	r.errs = append(r.errs, RowError{Row: r.row.Line, Column: column, Value: r.str(name), Err: err})
+}
+
+// ok adds the collected errors to the report and reports whether there were none.
+func (r *rowReader) ok(report *Report) bool {
+	report.Errors = append(report.Errors, r.errs...)
+	return len(r.errs) == 0
+}
+
+func (r *rowReader) str(name string) string {
+	return r.cols.value(r.row, name)
+}
+
+func (r *rowReader) required(name string) string {
+	s := r.str(name)
+	if s == "" {
+		r.fail(name, ErrRequired)
+	}
+	return s
+}
+
+// id reads a positive integer, 0 if the cell is empty.
+func (r *rowReader) id(name string) int64 {
+	s := r.str(name)
+	if s == "" {
+		return 0
+	}
+	n, ok := parseNumber(s)
+	if !ok || n <= 0 {
+		r.fail(name, ErrInvalidValue)
+		return 0
+	}
+	return n
+}
+
+// amount reads a non-negative whole number such as a price, 0 if the cell is empty.
+func (r *rowReader) amount(name string) int64 {
+	s := r.str(name)
+	if s == "" {
+		return 0
+	}
+	n, ok := parseNumber(s)
+	if !ok || n < 0 {
+		r.fail(name, ErrInvalidValue)
+		return 0
+	}
+	return n
+}
+
+func (r *rowReader) boolean(name string, def bool) bool {
+	switch strings.ToLower(r.str(name)) {
+	case "":
+		return def
+	case "1", "true", "yes", "да", "+":
+		return true
+	case "0", "false", "no", "нет", "-":
+		return false
+	}
+	r.fail(name, ErrInvalidValue)
+	return def
+}
+
+// date reads a date without the time of day, in UTC like booking dates in the database.
+func (r *rowReader) date(name string) time.Time {
+	t := r.time(name)
+	if t.IsZero() {
+		return t
+	}
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// time reads a date and time, zero if the cell is empty.
+func (r *rowReader) time(name string) time.Time {
+	s := r.str(name)
+	if s == "" {
+		return time.Time{}
+	}
+	if t, ok := parseTime(s); ok {
+		return t
+	}
+	r.fail(name, ErrInvalidValue)
+	return time.Time{}
+}
+
+// status reads a booking status. Without one, past bookings are completed and
+// the others confirmed.
+func (r *rowReader) status(name string, date, today time.Time) string {
+	s := strings.ToLower(r.str(name))
+	switch {
+	case s == "" && date.Before(today):
+		return models.StatusCompleted
+	case s == "":
+		return models.StatusConfirmed
+	case models.IsBookingStatus(s):
+		return s
+	}
+	if status, ok := statusAliases[s]; ok {
+		return status
+	}
+	r.fail(name, ErrInvalidValue)
+	return ""
+}
+
+func parseTime(s string) (time.Time, bool) {
+	for _, layout := range dateLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t, true
+		}
+	}
+	if serial, err := strconv.ParseFloat(s, 64); err == nil && serial > 0 {
+		if t, err := excelize.ExcelDateToTime(serial, false); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// parseNumber parses a whole number written with spaces, a currency sign or a zero
+// fraction, e.g. "1 500 ₽" or "1500,00".
+func parseNumber(s string) (int64, bool) {
+	s = strings.Map(func(r rune) rune {
+		switch r {
+		case ' ', '\u00a0', '\u202f', '₽':
+			return -1
+		case ',':
+			return '.'
+		}
+		return r
+	}, s)
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil || f != math.Trunc(f) || math.Abs(f) > 1e15 {
+		return 0, false
+	}
+	return int64(f), true
+}
+
+// normalizePhone returns a Russian phone number as 7XXXXXXXXXX and other numbers as they are.
+func normalizePhone(phone string) string {
+	if normalized := models.NormalizePhone(phone); normalized != "" {
+		return normalized
+	}
+	return phone
+}
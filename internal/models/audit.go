@@ -0,0 +1,81 @@
+package models
+
+import (
+	"context"
+	"time"
+)
+
+// Audit entity types.
+const (
+	AuditEntityBooking = "booking"
+	AuditEntityItem    = "item"
+	AuditEntityRole    = "role"
+	AuditEntityUser    = "user"
+	AuditEntityUnit    = "unit"
+)
+
+// Audit sources describe which channel initiated a change.
+const (
+	AuditSourceBot    = "bot"
+	AuditSourceAPI    = "api"
+	AuditSourceSheet  = "sheet"
+	AuditSourceSystem = "system"
+	AuditSourceCLI    = "cli"
+)
+
+// Audit actions.
+const (
+	AuditActionCreate         = "create"
+	AuditActionUpdate         = "update"
+	AuditActionStatusChange   = "status_change"
+	AuditActionItemChange     = "item_change"
+	AuditActionDeactivate     = "deactivate"
+	AuditActionReorder        = "reorder"
+	AuditActionDelete         = "delete"
+	AuditActionBlock          = "block"
+	AuditActionUnblock        = "unblock"
+	AuditActionCheckOut       = "check_out"
+	AuditActionCheckIn        = "check_in"
+	AuditActionMaintenance    = "maintenance"
+	AuditActionMaintenanceEnd = "maintenance_end"
+	AuditActionUnitAssign     = "unit_assign"
+)
+
+// AuditEntry is a single append-only record describing a change of a booking or an item.
+// Before and After hold JSON snapshots of the relevant fields (empty when not applicable).
+type AuditEntry struct {
+	ID         int64     `json:"id"`
+	EntityType string    `json:"entity_type"`
+	EntityID   int64     `json:"entity_id"`
+	Action     string    `json:"action"`
+	ActorID    int64     `json:"actor_id"`
+	Source     string    `json:"source"`
+	Before     string    `json:"before,omitempty"`
+	After      string    `json:"after,omitempty"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// AuditActor identifies who performs a change and through which channel.
+type AuditActor struct {
+	ID     int64
+	Source string
+}
+
+type auditActorKey struct{}
+
+// WithAuditActor attaches the actor to the context so that services can record it.
+func WithAuditActor(ctx context.Context, actor AuditActor) context.Context {
+	return context.WithValue(ctx, auditActorKey{}, actor)
+}
+
+// AuditActorFromContext returns the actor stored in the context.
+// Changes without an explicit actor are attributed to the system.
+func AuditActorFromContext(ctx context.Context) AuditActor {
+	if actor, ok := ctx.Value(auditActorKey{}).(AuditActor); ok {
+		if actor.Source == "" {
+			actor.Source = AuditSourceSystem
+		}
+		return actor
+	}
+	return AuditActor{Source: AuditSourceSystem}
+}
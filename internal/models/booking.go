@@ -16,4 +16,7 @@ type Booking struct {
 	CreatedAt    time.Time `json:"created_at"`
 	UpdatedAt    time.Time `json:"updated_at"`
 	Version      int64     `json:"version"`
+	UnitID       int64     `json:"unit_id,omitempty"` // assigned serial unit, 0 until confirmation or checkout
+	Price        int64     `json:"price,omitempty"`   // price of this day with the rental discount applied
+	Deposit      int64     `json:"deposit,omitempty"` // deposit of the whole rental, kept on its first day
 }
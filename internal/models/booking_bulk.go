@@ -0,0 +1,33 @@
+package models
+
+// Actions a manager can apply to several bookings at once.
+const (
+	BulkActionConfirm  = "confirm"
+	BulkActionReject   = "reject"
+	BulkActionComplete = "complete"
+)
+
+// BulkActionAllowed reports whether a booking in the given status can take the action.
+// It mirrors the buttons offered on a single booking card.
+func BulkActionAllowed(action, status string) bool {
+	switch action {
+	case BulkActionConfirm, BulkActionReject:
+		return status == StatusPending || status == StatusChanged
+	case BulkActionComplete:
+		return status == StatusConfirmed
+	}
+	return false
+}
+
+// BulkResult splits the bookings of a bulk action by outcome.
+type BulkResult struct {
+	Succeeded []*Booking
+	Conflicts []*Booking // changed by someone else after the manager loaded them
+	Skipped   []*Booking // status does not allow the action
+	Failed    []*Booking
+}
+
+// Total returns the number of bookings the action was applied to.
+func (r *BulkResult) Total() int {
+	return len(r.Succeeded) + len(r.Conflicts) + len(r.Skipped) + len(r.Failed)
+}
@@ -0,0 +1,182 @@
+package models
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// MaxSearchResults caps a single booking search so a broad filter cannot load the whole table.
+const MaxSearchResults = 500
+
+// BookingFilter narrows a booking search. Zero fields do not restrict the result.
+type BookingFilter struct {
+	BookingID  int64
+	Statuses   []string
+	ItemIDs    []int64
+	DateFrom   time.Time
+	DateTo     time.Time
+	ClientName string // case-insensitive substring of the client name
+	Phone      string // digits, matched as a substring of the normalized phone
+	Limit      int
+	Offset     int
+}
+
+// IsEmpty reports whether the filter has no conditions.
+func (f *BookingFilter) IsEmpty() bool {
+	return f.BookingID == 0 && len(f.Statuses) == 0 && len(f.ItemIDs) == 0 &&
+		f.DateFrom.IsZero() && f.DateTo.IsZero() && f.ClientName == "" && f.Phone == ""
+}
+
+// Values encodes the filter as query parameters understood by ParseBookingFilter.
+func (f *BookingFilter) Values() url.Values {
+	v := url.Values{}
+	if f.BookingID != 0 {
+		v.Set("id", strconv.FormatInt(f.BookingID, 10))
+	}
+	if len(f.Statuses) > 0 {
+		v.Set("status", strings.Join(f.Statuses, ","))
+	}
+	if len(f.ItemIDs) > 0 {
+		ids := make([]string, 0, len(f.ItemIDs))
+		for _, id := range f.ItemIDs {
+			ids = append(ids, strconv.FormatInt(id, 10))
+		}
+		v.Set("item_id", strings.Join(ids, ","))
+	}
+	if !f.DateFrom.IsZero() {
+		v.Set("from", f.DateFrom.Format("2006-01-02"))
+	}
+	if !f.DateTo.IsZero() {
+		v.Set("to", f.DateTo.Format("2006-01-02"))
+	}
+	if f.ClientName != "" {
+		v.Set("name", f.ClientName)
+	}
+	if f.Phone != "" {
+		v.Set("phone", f.Phone)
+	}
+	if f.Limit > 0 {
+		v.Set("limit", strconv.Itoa(f.Limit))
+	}
+	if f.Offset > 0 {
+		v.Set("offset", strconv.Itoa(f.Offset))
+	}
+	return v
+}
+
+// ParseBookingFilter builds a filter from query parameters:
+// id, status and item_id (comma-separated), from and to (YYYY-MM-DD), name, phone, limit, offset.
+func ParseBookingFilter(v url.Values) (BookingFilter, error) {
+	var f BookingFilter
+	var err error
+
+	if s := strings.TrimSpace(v.Get("id")); s != "" {
+		if f.BookingID, err = strconv.ParseInt(strings.TrimPrefix(s, "#"), 10, 64); err != nil || f.BookingID <= 0 {
+			return f, fmt.Errorf("invalid id: %s", s)
+		}
+	}
+
+	for _, s := range splitList(v.Get("status")) {
+		if !IsBookingStatus(s) {
+			return f, fmt.Errorf("invalid status: %s", s)
+		}
+		f.Statuses = append(f.Statuses, s)
+	}
+
+	for _, s := range splitList(v.Get("item_id")) {
+		id, err := strconv.ParseInt(s, 10, 64)
+		if err != nil || id <= 0 {
+			return f, fmt.Errorf("invalid item_id: %s", s)
+		}
+		f.ItemIDs = append(f.ItemIDs, id)
+	}
+
+	if f.DateFrom, err = parseFilterDate(v.Get("from")); err != nil {
+		return f, fmt.Errorf("invalid from: %w", err)
+	}
+	if f.DateTo, err = parseFilterDate(v.Get("to")); err != nil {
+		return f, fmt.Errorf("invalid to: %w", err)
+	}
+	if !f.DateFrom.IsZero() && !f.DateTo.IsZero() && f.DateTo.Before(f.DateFrom) {
+		return f, fmt.Errorf("to is before from")
+	}
+
+	f.ClientName = strings.TrimSpace(v.Get("name"))
+
+	if s := strings.TrimSpace(v.Get("phone")); s != "" {
+		if f.Phone = NormalizePhone(s); f.Phone == "" {
+			f.Phone = digitsOnly(s)
+		}
+		if f.Phone == "" {
+			return f, fmt.Errorf("invalid phone: %s", s)
+		}
+	}
+
+	if s := strings.TrimSpace(v.Get("limit")); s != "" {
+		if f.Limit, err = strconv.Atoi(s); err != nil || f.Limit < 0 {
+			return f, fmt.Errorf("invalid limit: %s", s)
+		}
+	}
+	if s := strings.TrimSpace(v.Get("offset")); s != "" {
+		if f.Offset, err = strconv.Atoi(s); err != nil || f.Offset < 0 {
+			return f, fmt.Errorf("invalid offset: %s", s)
+		}
+	}
+
+	return f, nil
+}
+
+// IsBookingStatus reports whether s is one of the booking statuses.
+func IsBookingStatus(s string) bool {
+	switch s {
+	case StatusPending, StatusConfirmed, StatusCanceled, StatusChanged, StatusCompleted, StatusRescheduled:
+		return true
+	}
+	return false
+}
+
+// NormalizePhone converts a Russian phone number to 7XXXXXXXXXX or returns "" if it is not one.
+func NormalizePhone(phone string) string {
+	cleaned := digitsOnly(phone)
+
+	switch {
+	case len(cleaned) == 11 && cleaned[0] == '8':
+		return "7" + cleaned[1:] // 8XXXXXXXXXX -> 7XXXXXXXXXX
+	case len(cleaned) == 11 && cleaned[0] == '7':
+		return cleaned
+	case len(cleaned) == 10:
+		return "7" + cleaned // XXXXXXXXXX -> 7XXXXXXXXXX
+	}
+	return ""
+}
+
+func digitsOnly(s string) string {
+	var sb strings.Builder
+	for _, r := range s {
+		if r >= '0' && r <= '9' {
+			sb.WriteRune(r)
+		}
+	}
+	return sb.String()
+}
+
+func parseFilterDate(s string) (time.Time, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return time.Time{}, nil
+	}
+	return time.Parse("2006-01-02", s)
+}
+
+func splitList(raw string) []string {
+	var out []string
+	for _, p := range strings.Split(raw, ",") {
+		if p = strings.TrimSpace(p); p != "" {
+			out = append(out, p)
+		}
+	}
+	return out
+}
@@ -0,0 +1,44 @@
+package models
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+)
+
+// Calendar feed scopes: which bookings an iCalendar feed exports.
+const (
+	CalendarScopeUser = "user" // bookings of one client, SubjectID is the Telegram ID
+	CalendarScopeItem = "item" // schedule of one item, SubjectID is the item ID
+	CalendarScopeAll  = "all"  // confirmed bookings of all items, SubjectID is 0
+)
+
+var (
+	ErrCalendarFeedNotFound = errors.New("calendar feed not found")
+	ErrCalendarFeedRevoked  = errors.New("calendar feed is revoked")
+)
+
+// CalendarFeed is an issued iCalendar subscription. Its URL carries a signed token,
+// so revoking the feed invalidates the URL without changing the signing secret.
+type CalendarFeed struct {
+	ID        int64        `json:"id"`
+	Scope     string       `json:"scope"`
+	SubjectID int64        `json:"subject_id"`
+	CreatedBy int64        `json:"created_by"`
+	CreatedAt time.Time    `json:"created_at"`
+	RevokedAt sql.NullTime `json:"revoked_at"`
+}
+
+// IsRevoked reports whether the feed URL no longer works.
+func (f *CalendarFeed) IsRevoked() bool {
+	return f.RevokedAt.Valid
+}
+
+// IsCalendarScope reports whether s is a known feed scope.
+func IsCalendarScope(s string) bool {
+	switch s {
+	case CalendarScopeUser, CalendarScopeItem, CalendarScopeAll:
+		return true
+	}
+	return false
+}
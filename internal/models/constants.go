@@ -1,11 +1,12 @@
 package models
 
 const (
-	StatusPending   = "pending"
-	StatusConfirmed = "confirmed"
-	StatusCanceled  = "canceled"
-	StatusChanged   = "changed"
-	StatusCompleted = "completed"
+	StatusPending     = "pending"
+	StatusConfirmed   = "confirmed"
+	StatusCanceled    = "canceled"
+	StatusChanged     = "changed"
+	StatusCompleted   = "completed"
+	StatusRescheduled = "rescheduled"
 )
 
 const (
@@ -21,7 +22,6 @@ const (
 	StatePersonalData        = "personal_data"
 	StateEnterName           = "enter_name"
 	StatePhoneNumber         = "phone_number"
-	StateConfirmation        = "confirmation"
 	StateWaitingDate         = "waiting_date"
 	StateWaitingSpecificDate = "waiting_specific_date"
 
@@ -35,6 +35,10 @@ const (
 	StateManagerWaitingEndDate       = "manager_waiting_end_date"
 	StateManagerWaitingComment       = "manager_waiting_comment"
 	StateManagerConfirmBooking       = "manager_confirm_booking"
+	StateManagerSearch               = "manager_search"
+	StateManagerBulkSelect           = "manager_bulk_select"
+	StateManagerHandover             = "manager_handover"
+	StateManagerItemEditor           = "manager_item_editor"
 )
 
 const (
@@ -63,6 +67,15 @@ const (
 	// RateLimitWindow окно ограничения частоты сообщений
 	RateLimitWindow = 60 // 1 минута в секундах
 
+	// DispatcherWorkers число параллельных обработчиков обновлений Telegram
+	DispatcherWorkers = 8
+
+	// DispatcherQueueSize сколько обновлений принимается в обработку, прежде чем чтение обновлений приостановится
+	DispatcherQueueSize = 256
+
+	// DispatcherDrainTimeout сколько ждать завершения принятых обновлений при остановке
+	DispatcherDrainTimeout = 30 // в секундах
+
 	// ItemsCacheTTL время жизни кэша предметов в памяти
 	ItemsCacheTTL = 30 * 60 // 30 минут в секундах
 
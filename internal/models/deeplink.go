@@ -0,0 +1,30 @@
+package models
+
+import (
+	"fmt"
+	"net/url"
+)
+
+// Параметры ссылок t.me/<бот>?start=<payload>
+const (
+	// DeepLinkItem - item_<id>: расписание аппарата, ссылка QR-наклейки
+	DeepLinkItem = "item_"
+	// DeepLinkBooking - booking_<id>: карточка заявки
+	DeepLinkBooking = "booking_"
+	// DeepLinkBook - book_<id>[_<ГГГГММДД>]: бронирование аппарата на дату
+	DeepLinkBook = "book_"
+)
+
+// StartLink возвращает ссылку, открывающую бота с параметром payload.
+// Пустая строка, если имя бота неизвестно.
+func StartLink(botUsername, payload string) string {
+	if botUsername == "" {
+		return ""
+	}
+	return fmt.Sprintf("https://t.me/%s?start=%s", botUsername, url.QueryEscape(payload))
+}
+
+// ItemStartLink возвращает ссылку на расписание аппарата
+func ItemStartLink(botUsername string, itemID int64) string {
+	return StartLink(botUsername, fmt.Sprintf("%s%d", DeepLinkItem, itemID))
+}
@@ -0,0 +1,17 @@
+package models
+
+import "time"
+
+// Escalation modes for pending bookings nobody answered within the SLA.
+const (
+	EscalationModeReping = "reping" // remind every manager responsible for the item again
+	EscalationModeAssign = "assign" // hand the booking to the least loaded manager
+)
+
+// BookingEscalation records how a stale pending booking has been escalated so far.
+type BookingEscalation struct {
+	BookingID   int64     `json:"booking_id"`
+	Level       int       `json:"level"`       // number of escalations sent
+	AssignedTo  int64     `json:"assigned_to"` // 0 unless the booking was assigned to a manager
+	EscalatedAt time.Time `json:"escalated_at"`
+}
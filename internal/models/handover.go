@@ -0,0 +1,61 @@
+package models
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+)
+
+var (
+	ErrHandoverNotAllowed = errors.New("only confirmed bookings can be checked out")
+	ErrAlreadyCheckedOut  = errors.New("equipment is already checked out for this booking")
+	ErrNotCheckedOut      = errors.New("equipment was not checked out or is already returned")
+)
+
+// Handover records when a booked device actually left and came back and in what condition.
+// One handover covers a run of consecutive booking days of the same client and item.
+type Handover struct {
+	ID                int64        `json:"id"`
+	BookingID         int64        `json:"booking_id"` // first booking of the run
+	UserID            int64        `json:"user_id"`
+	UserName          string       `json:"user_name"`
+	ItemID            int64        `json:"item_id"`
+	ItemName          string       `json:"item_name"`
+	UnitID            int64        `json:"unit_id,omitempty"`
+	PlannedStart      time.Time    `json:"planned_start"` // first booked day
+	PlannedEnd        time.Time    `json:"planned_end"`   // last booked day
+	CheckedOutAt      time.Time    `json:"checked_out_at"`
+	CheckedOutBy      int64        `json:"checked_out_by"`
+	CheckOutNote      string       `json:"check_out_note,omitempty"`
+	CheckOutPhotos    []string     `json:"check_out_photos,omitempty"` // Telegram file IDs
+	CheckedInAt       sql.NullTime `json:"checked_in_at"`
+	CheckedInBy       int64        `json:"checked_in_by,omitempty"`
+	CheckInNote       string       `json:"check_in_note,omitempty"`
+	CheckInPhotos     []string     `json:"check_in_photos,omitempty"`
+	OverdueNotifiedAt sql.NullTime `json:"-"`
+}
+
+// ReturnDate is the day the device is due back: the day after the last booked day.
+func (h *Handover) ReturnDate() time.Time {
+	return h.PlannedEnd.AddDate(0, 0, 1)
+}
+
+// IsReturned reports whether the device has been checked in.
+func (h *Handover) IsReturned() bool {
+	return h.CheckedInAt.Valid
+}
+
+// DelayDays returns how many days the return is late compared to ReturnDate.
+// Devices still out are measured against now.
+func (h *Handover) DelayDays(now time.Time) int {
+	returned := now
+	if h.IsReturned() {
+		returned = h.CheckedInAt.Time
+	}
+	returnDay := time.Date(returned.Year(), returned.Month(), returned.Day(), 0, 0, 0, 0, time.UTC)
+	days := int(returnDay.Sub(h.ReturnDate()).Hours() / 24)
+	if days < 0 {
+		return 0
+	}
+	return days
+}
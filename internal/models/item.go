@@ -1,16 +1,56 @@
 package models
 
-import "time"
+import (
+	"strings"
+	"time"
+)
+
+// CategorySeparator separates nesting levels of an item category, e.g. "Лазеры/Диодные".
+const CategorySeparator = "/"
 
 type Item struct {
-	ID            int64     `yaml:"id"`
-	Name          string    `yaml:"name"`
-	Description   string    `yaml:"description"`
-	TotalQuantity int64     `yaml:"total_quantity"`
-	SortOrder     int64     `yaml:"sort_order" json:"sort_order"`
-	IsActive      bool      `yaml:"is_active" json:"is_active"`
-	CreatedAt     time.Time `yaml:"created_at" json:"created_at"`
-	UpdatedAt     time.Time `yaml:"updated_at" json:"updated_at"`
+	ID                 int64      `yaml:"id"`
+	Name               string     `yaml:"name"`
+	Description        string     `yaml:"description"`
+	TotalQuantity      int64      `yaml:"total_quantity"`
+	SortOrder          int64      `yaml:"sort_order" json:"sort_order"`
+	IsActive           bool       `yaml:"is_active" json:"is_active"`
+	Category           string     `yaml:"category" json:"category,omitempty"`
+	PhotoFileIDs       []string   `yaml:"photos" json:"photos,omitempty"` // Telegram file IDs
+	Specs              []ItemSpec `yaml:"specs" json:"specs,omitempty"`
+	PricePerDay        int64      `yaml:"price_per_day" json:"price_per_day,omitempty"`               // whole currency units, 0 - not set
+	WeekendPrice       int64      `yaml:"weekend_price" json:"weekend_price,omitempty"`               // Saturday and Sunday, 0 - daily rate
+	Deposit            int64      `yaml:"deposit" json:"deposit,omitempty"`                           // per rental, not per day
+	LongRentalDays     int64      `yaml:"long_rental_days" json:"long_rental_days,omitempty"`         // rental length for the discount
+	LongRentalDiscount int64      `yaml:"long_rental_discount" json:"long_rental_discount,omitempty"` // percent, 0 - no discount
+	CreatedAt          time.Time  `yaml:"created_at" json:"created_at"`
+	UpdatedAt          time.Time  `yaml:"updated_at" json:"updated_at"`
+}
+
+// ItemSpec is a single named characteristic of an item, e.g. "Мощность: 1200 Вт".
+type ItemSpec struct {
+	Name  string `yaml:"name" json:"name"`
+	Value string `yaml:"value" json:"value"`
+}
+
+// CategoryPath returns the category levels of the item, empty for uncategorized items.
+func (i *Item) CategoryPath() []string {
+	if i.Category == "" {
+		return nil
+	}
+	return strings.Split(i.Category, CategorySeparator)
+}
+
+// NormalizeCategory trims every level of a category path and drops empty ones.
+func NormalizeCategory(category string) string {
+	parts := strings.Split(category, CategorySeparator)
+	levels := make([]string, 0, len(parts))
+	for _, p := range parts {
+		if p = strings.TrimSpace(p); p != "" {
+			levels = append(levels, p)
+		}
+	}
+	return strings.Join(levels, CategorySeparator)
 }
 
 // AvailabilityInfo describes availability for a given item on a date.
@@ -19,5 +59,6 @@ type AvailabilityInfo struct {
 	Date        time.Time `json:"date"`
 	Available   bool      `json:"available"`
 	BookedCount int64     `json:"booked_count"`
-	Total       int64     `json:"total"`
+	Total       int64     `json:"total"`                 // units that can be booked on the date
+	Maintenance int64     `json:"maintenance,omitempty"` // units out of service on the date
 }
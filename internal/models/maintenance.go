@@ -0,0 +1,79 @@
+package models
+
+import (
+	"errors"
+	"time"
+)
+
+var (
+	ErrInvalidMaintenancePeriod = errors.New("maintenance must end on or after its start date")
+	ErrInvalidMaintenanceUnits  = errors.New("maintenance units must be between 1 and the item quantity")
+	ErrMaintenanceNotFound      = errors.New("maintenance window not found")
+	ErrExternalHold             = errors.New("window is imported from an external calendar")
+)
+
+// MaintenanceWindow takes some units of an item out of service for a range of days,
+// for example one of three devices sent for repair. Both dates are inclusive.
+// Windows with a Source are external holds: reservations imported from a partner's
+// calendar, which are replaced on every import and cannot be ended by hand.
+type MaintenanceWindow struct {
+	ID        int64     `json:"id"`
+	ItemID    int64     `json:"item_id"`
+	ItemName  string    `json:"item_name"`
+	Units     int64     `json:"units"`
+	StartDate time.Time `json:"start_date"`
+	EndDate   time.Time `json:"end_date"`
+	Reason    string    `json:"reason,omitempty"`
+	CreatedBy int64     `json:"created_by"`
+	CreatedAt time.Time `json:"created_at"`
+	Source    string    `json:"source,omitempty"` // external calendar name, empty for manual maintenance
+}
+
+// IsExternalHold reports whether the window was imported from an external calendar.
+func (w *MaintenanceWindow) IsExternalHold() bool {
+	return w.Source != ""
+}
+
+// Covers reports whether the window takes units out of service on date.
+func (w *MaintenanceWindow) Covers(date time.Time) bool {
+	day := date.Format("2006-01-02")
+	return w.StartDate.Format("2006-01-02") <= day && day <= w.EndDate.Format("2006-01-02")
+}
+
+// MaintenanceUnits sums the units of the item that the windows take out of service on date.
+func MaintenanceUnits(windows []*MaintenanceWindow, itemID int64, date time.Time) int64 {
+	var units int64
+	for _, w := range windows {
+		if w.ItemID == itemID && w.Covers(date) {
+			units += w.Units
+		}
+	}
+	return units
+}
+
+// ExternalHoldUnits sums the units of the item held by external calendar reservations on date.
+// They are included in MaintenanceUnits.
+func ExternalHoldUnits(windows []*MaintenanceWindow, itemID int64, date time.Time) int64 {
+	var units int64
+	for _, w := range windows {
+		if w.IsExternalHold() && w.ItemID == itemID && w.Covers(date) {
+			units += w.Units
+		}
+	}
+	return units
+}
+
+// EffectiveQuantity returns how many units of the item can be booked when maintenance units are out of service.
+func (i *Item) EffectiveQuantity(maintenance int64) int64 {
+	if maintenance >= i.TotalQuantity {
+		return 0
+	}
+	return i.TotalQuantity - maintenance
+}
+
+// MaintenanceOverflow is a day on which existing bookings exceed the capacity left after maintenance.
+type MaintenanceOverflow struct {
+	Date     time.Time  `json:"date"`
+	Capacity int64      `json:"capacity"`
+	Bookings []*Booking `json:"bookings"`
+}
@@ -101,8 +101,9 @@ func (s *UserState) GetDates(key string) []time.Time {
 }
 
 type Availability struct {
-	Date      time.Time `json:"date"`
-	ItemID    int64     `json:"item_id"`
-	Booked    int64     `json:"booked"`
-	Available int64     `json:"available"`
+	Date        time.Time `json:"date"`
+	ItemID      int64     `json:"item_id"`
+	Booked      int64     `json:"booked"`
+	Available   int64     `json:"available"`
+	Maintenance int64     `json:"maintenance,omitempty"`
 }
@@ -1,6 +1,8 @@
 package models
 
 import (
+	"database/sql"
+	"net/url"
 	"testing"
 	"time"
 
@@ -72,3 +74,124 @@ func TestUserState_Helpers(t *testing.T) {
 		assert.Nil(t, state.GetDates("missing"))
 	})
 }
+
+func TestBookingFilter_RoundTrip(t *testing.T) {
+	values, err := url.ParseQuery("id=%2315&status=pending,confirmed&item_id=1,2&from=2025-01-01&to=2025-01-31&name=Иван&phone=8(999)123-45-67&limit=10&offset=20")
+	assert.NoError(t, err)
+
+	f, err := ParseBookingFilter(values)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(15), f.BookingID)
+	assert.Equal(t, []string{StatusPending, StatusConfirmed}, f.Statuses)
+	assert.Equal(t, []int64{1, 2}, f.ItemIDs)
+	assert.Equal(t, time.Date(2025, 1, 31, 0, 0, 0, 0, time.UTC), f.DateTo)
+	assert.Equal(t, "Иван", f.ClientName)
+	assert.Equal(t, "79991234567", f.Phone)
+	assert.Equal(t, 10, f.Limit)
+	assert.Equal(t, 20, f.Offset)
+	assert.False(t, f.IsEmpty())
+
+	again, err := ParseBookingFilter(f.Values())
+	assert.NoError(t, err)
+	assert.Equal(t, f, again)
+
+	partial, err := ParseBookingFilter(url.Values{"phone": {"4567"}})
+	assert.NoError(t, err)
+	assert.Equal(t, "4567", partial.Phone)
+
+	empty, err := ParseBookingFilter(url.Values{})
+	assert.NoError(t, err)
+	assert.True(t, empty.IsEmpty())
+
+	rescheduled, err := ParseBookingFilter(url.Values{"status": {"rescheduled"}})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{StatusRescheduled}, rescheduled.Statuses)
+
+	for _, bad := range []string{"status=unknown", "item_id=x", "from=01.01.2025", "from=2025-02-01&to=2025-01-01", "id=0", "phone=abc", "limit=-1"} {
+		values, _ := url.ParseQuery(bad)
+		_, err := ParseBookingFilter(values)
+		assert.Error(t, err, bad)
+	}
+}
+
+func TestIsBookingStatus(t *testing.T) {
+	tests := []struct {
+		status string
+		want   bool
+	}{
+		{StatusPending, true},
+		{StatusConfirmed, true},
+		{StatusCanceled, true},
+		{StatusChanged, true},
+		{StatusCompleted, true},
+		{StatusRescheduled, true},
+		{"", false},
+		{"unknown", false},
+		{"Pending", false},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, IsBookingStatus(tt.status), tt.status)
+	}
+}
+
+func TestNormalizePhone(t *testing.T) {
+	assert.Equal(t, "79991234567", NormalizePhone("+7 (999) 123-45-67"))
+	assert.Equal(t, "79991234567", NormalizePhone("89991234567"))
+	assert.Equal(t, "79991234567", NormalizePhone("9991234567"))
+	assert.Empty(t, NormalizePhone("12345"))
+}
+
+func TestHandover_DelayDays(t *testing.T) {
+	h := &Handover{
+		PlannedStart: time.Date(2030, 3, 1, 0, 0, 0, 0, time.UTC),
+		PlannedEnd:   time.Date(2030, 3, 3, 0, 0, 0, 0, time.UTC),
+	}
+	assert.Equal(t, time.Date(2030, 3, 4, 0, 0, 0, 0, time.UTC), h.ReturnDate())
+
+	// Еще не возвращен: считаем от текущего момента
+	assert.Equal(t, 0, h.DelayDays(time.Date(2030, 3, 4, 18, 0, 0, 0, time.Local)))
+	assert.Equal(t, 2, h.DelayDays(time.Date(2030, 3, 6, 9, 0, 0, 0, time.Local)))
+
+	h.CheckedInAt = sql.NullTime{Time: time.Date(2030, 3, 5, 10, 0, 0, 0, time.Local), Valid: true}
+	assert.True(t, h.IsReturned())
+	assert.Equal(t, 1, h.DelayDays(time.Date(2030, 4, 1, 0, 0, 0, 0, time.Local)))
+
+	h.CheckedInAt.Time = time.Date(2030, 3, 2, 10, 0, 0, 0, time.Local)
+	assert.Equal(t, 0, h.DelayDays(time.Now()), "early return is not a delay")
+}
+
+func TestItem_DayPrice(t *testing.T) {
+	friday := time.Date(2030, 3, 1, 0, 0, 0, 0, time.UTC)
+	saturday := friday.AddDate(0, 0, 1)
+
+	item := &Item{PricePerDay: 1000}
+	assert.True(t, item.HasPricing())
+	assert.Equal(t, int64(1000), item.DayPrice(saturday, 1), "no weekend rate falls back to the daily one")
+
+	item.WeekendPrice = 1500
+	assert.Equal(t, int64(1000), item.DayPrice(friday, 1))
+	assert.Equal(t, int64(1500), item.DayPrice(saturday, 1))
+
+	item.LongRentalDays, item.LongRentalDiscount = 7, 15
+	assert.Equal(t, int64(1500), item.DayPrice(saturday, 6))
+	assert.Equal(t, int64(1275), item.DayPrice(saturday, 7))
+	assert.Equal(t, int64(850), item.DayPrice(friday, 10))
+
+	assert.False(t, (&Item{}).HasPricing())
+}
+
+func TestNewBookingQuote(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2030, 3, d, 0, 0, 0, 0, time.UTC) }
+	quote := NewBookingQuote([]*Booking{
+		{Date: day(1), Price: 1000, Deposit: 3000},
+		{Date: day(2), Price: 1500},
+	})
+	assert.Equal(t, 2, quote.Days)
+	assert.Equal(t, day(1), quote.Start)
+	assert.Equal(t, day(2), quote.End)
+	assert.Equal(t, int64(2500), quote.Total)
+	assert.Equal(t, int64(3000), quote.Deposit)
+	assert.True(t, quote.IsPriced())
+
+	assert.False(t, NewBookingQuote(nil).IsPriced())
+}
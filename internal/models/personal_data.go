@@ -0,0 +1,65 @@
+package models
+
+import "time"
+
+// AnonymizedUserName replaces the client name in bookings after the user asked to erase their data.
+const AnonymizedUserName = "[удалено]"
+
+// PersonalDataExport is everything the service stores about a user.
+type PersonalDataExport struct {
+	TelegramID     int64                  `json:"telegram_id"`
+	Username       string                 `json:"username,omitempty"`
+	FirstName      string                 `json:"first_name,omitempty"`
+	LastName       string                 `json:"last_name,omitempty"`
+	Phone          string                 `json:"phone,omitempty"`
+	LanguageCode   string                 `json:"language_code,omitempty"`
+	RegisteredAt   *time.Time             `json:"registered_at,omitempty"`
+	LastActivity   *time.Time             `json:"last_activity,omitempty"`
+	ConsentGiven   bool                   `json:"consent_given"`
+	ConsentGivenAt *time.Time             `json:"consent_given_at,omitempty"`
+	Bookings       []*Booking             `json:"bookings"`
+	Handovers      []*Handover            `json:"handovers"`
+	SessionStep    string                 `json:"session_step,omitempty"`
+	SessionData    map[string]interface{} `json:"session_data,omitempty"`
+	ExportedAt     time.Time              `json:"exported_at"`
+}
+
+// NewPersonalDataExport builds an export from the stored profile (may be nil), bookings
+// and handovers.
+func NewPersonalDataExport(telegramID int64, user *User, bookings []*Booking, handovers []*Handover) *PersonalDataExport {
+	export := &PersonalDataExport{
+		TelegramID: telegramID,
+		Bookings:   bookings,
+		Handovers:  handovers,
+		ExportedAt: time.Now(),
+	}
+	if export.Bookings == nil {
+		export.Bookings = []*Booking{}
+	}
+	if export.Handovers == nil {
+		export.Handovers = []*Handover{}
+	}
+	if user == nil {
+		return export
+	}
+
+	export.Username = user.Username
+	export.FirstName = user.FirstName
+	export.LastName = user.LastName
+	export.Phone = user.Phone
+	export.LanguageCode = user.LanguageCode
+	if !user.CreatedAt.IsZero() {
+		createdAt := user.CreatedAt
+		export.RegisteredAt = &createdAt
+	}
+	if !user.LastActivity.IsZero() {
+		lastActivity := user.LastActivity
+		export.LastActivity = &lastActivity
+	}
+	export.ConsentGiven = user.ConsentGiven
+	if user.ConsentGivenAt.Valid {
+		givenAt := user.ConsentGivenAt.Time
+		export.ConsentGivenAt = &givenAt
+	}
+	return export
+}
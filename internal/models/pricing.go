@@ -0,0 +1,57 @@
+package models
+
+import "time"
+
+// HasPricing reports whether bookings of the item are priced.
+func (i *Item) HasPricing() bool {
+	return i.PricePerDay > 0 || i.WeekendPrice > 0 || i.Deposit > 0
+}
+
+// IsLongRental reports whether a rental of the given length gets the long-rental discount.
+func (i *Item) IsLongRental(rentalDays int) bool {
+	return i.LongRentalDiscount > 0 && i.LongRentalDays > 0 && int64(rentalDays) >= i.LongRentalDays
+}
+
+// DayPrice returns the price of one day of a rental that lasts rentalDays days.
+// Saturday and Sunday use the weekend rate when it is set; the long-rental discount
+// applies to every day of a long enough rental and is rounded down.
+func (i *Item) DayPrice(date time.Time, rentalDays int) int64 {
+	price := i.PricePerDay
+	if wd := date.Weekday(); i.WeekendPrice > 0 && (wd == time.Saturday || wd == time.Sunday) {
+		price = i.WeekendPrice
+	}
+	if i.IsLongRental(rentalDays) {
+		price = price * (100 - i.LongRentalDiscount) / 100
+	}
+	return price
+}
+
+// BookingQuote is the cost of a rental: consecutive booking days of one client and item.
+type BookingQuote struct {
+	Bookings []*Booking // rental days sorted by date
+	Start    time.Time
+	End      time.Time
+	Days     int
+	Total    int64
+	Deposit  int64
+}
+
+// NewBookingQuote sums the stored prices of the rental days, which must be sorted by date.
+func NewBookingQuote(run []*Booking) *BookingQuote {
+	quote := &BookingQuote{Bookings: run, Days: len(run)}
+	if len(run) == 0 {
+		return quote
+	}
+	quote.Start = run[0].Date
+	quote.End = run[len(run)-1].Date
+	for _, b := range run {
+		quote.Total += b.Price
+		quote.Deposit += b.Deposit
+	}
+	return quote
+}
+
+// IsPriced reports whether the rental has any cost to show.
+func (q *BookingQuote) IsPriced() bool {
+	return q.Total > 0 || q.Deposit > 0
+}
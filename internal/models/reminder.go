@@ -0,0 +1,14 @@
+package models
+
+import "fmt"
+
+// Kinds of booking reminders.
+const (
+	ReminderKindBefore = "before" // before the booking starts
+	ReminderKindReturn = "return" // before the equipment has to be returned
+)
+
+// ReminderKey identifies a reminder stage in the sent reminders log.
+func ReminderKey(kind string, daysBefore int) string {
+	return fmt.Sprintf("%s:%d", kind, daysBefore)
+}
@@ -0,0 +1,114 @@
+package models
+
+import (
+	"errors"
+	"time"
+)
+
+// Staff roles.
+const (
+	RoleAdmin   = "admin"
+	RoleManager = "manager"
+	RoleViewer  = "viewer"
+)
+
+var (
+	ErrInvalidRole         = errors.New("invalid role")
+	ErrRoleManagedByConfig = errors.New("role is defined in config")
+)
+
+// Bot permissions.
+const (
+	PermViewStats       = "view_stats"
+	PermViewBookings    = "view_bookings"
+	PermManageBookings  = "manage_bookings"
+	PermManageItems     = "manage_items"
+	PermManageRoles     = "manage_roles"
+	PermSyncSheets      = "sync_sheets"
+	PermExportData      = "export_data"
+	PermManageBlacklist = "manage_blacklist"
+)
+
+// API permissions.
+const (
+	PermAPIReadAvailability = "read:availability"
+	PermAPIReadItems        = "read:items"
+	PermAPIReadAudit        = "read:audit"
+	PermAPIReadBookings     = "read:bookings"
+	PermAPIReadStats        = "read:stats"
+)
+
+var rolePermissions = map[string]map[string]bool{
+	RoleAdmin: {
+		PermViewStats:           true,
+		PermViewBookings:        true,
+		PermManageBookings:      true,
+		PermManageItems:         true,
+		PermManageRoles:         true,
+		PermSyncSheets:          true,
+		PermExportData:          true,
+		PermManageBlacklist:     true,
+		PermAPIReadAvailability: true,
+		PermAPIReadItems:        true,
+		PermAPIReadAudit:        true,
+		PermAPIReadBookings:     true,
+		PermAPIReadStats:        true,
+	},
+	RoleManager: {
+		PermViewStats:           true,
+		PermViewBookings:        true,
+		PermManageBookings:      true,
+		PermSyncSheets:          true,
+		PermExportData:          true,
+		PermManageBlacklist:     true,
+		PermAPIReadAvailability: true,
+		PermAPIReadItems:        true,
+		PermAPIReadAudit:        true,
+		PermAPIReadBookings:     true,
+		PermAPIReadStats:        true,
+	},
+	RoleViewer: {
+		PermViewStats:           true,
+		PermAPIReadAvailability: true,
+		PermAPIReadItems:        true,
+		PermAPIReadStats:        true,
+	},
+}
+
+// IsValidRole reports whether role is one of the known roles.
+func IsValidRole(role string) bool {
+	_, ok := rolePermissions[role]
+	return ok
+}
+
+// RoleHasPermission reports whether role grants perm. Admins are granted every permission.
+func RoleHasPermission(role, perm string) bool {
+	if role == RoleAdmin {
+		return true
+	}
+	return rolePermissions[role][perm]
+}
+
+// UserRole is a role assigned to a Telegram user. ItemIDs limits the role to
+// specific items; an empty list means all items.
+type UserRole struct {
+	TelegramID int64     `json:"telegram_id"`
+	Role       string    `json:"role"`
+	ItemIDs    []int64   `json:"item_ids,omitempty"`
+	GrantedBy  int64     `json:"granted_by"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+// CoversItem reports whether the role applies to the given item.
+func (r *UserRole) CoversItem(itemID int64) bool {
+	if r.Role == RoleAdmin || len(r.ItemIDs) == 0 {
+		return true
+	}
+	for _, id := range r.ItemIDs {
+		if id == itemID {
+			return true
+		}
+	}
+	return false
+}
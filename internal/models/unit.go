@@ -0,0 +1,54 @@
+package models
+
+import (
+	"errors"
+	"time"
+)
+
+// Unit statuses.
+const (
+	UnitStatusActive  = "active"
+	UnitStatusRepair  = "repair"
+	UnitStatusRetired = "retired"
+)
+
+var (
+	ErrUnitNotFound      = errors.New("unit not found")
+	ErrDuplicateSerial   = errors.New("unit with this serial number already exists")
+	ErrInvalidUnitStatus = errors.New("unknown unit status")
+	ErrNoFreeUnit        = errors.New("no free unit of the item for this date")
+)
+
+// ItemUnit is a physical device of an item identified by its serial number.
+// Units are optional: items without units are booked by quantity only.
+type ItemUnit struct {
+	ID           int64     `json:"id"`
+	ItemID       int64     `json:"item_id"`
+	SerialNumber string    `json:"serial_number"`
+	InventoryTag string    `json:"inventory_tag,omitempty"`
+	Status       string    `json:"status"`
+	Note         string    `json:"note,omitempty"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+// IsAssignable reports whether the unit can be given out to a client.
+func (u *ItemUnit) IsAssignable() bool {
+	return u.Status == UnitStatusActive
+}
+
+// ValidUnitStatus reports whether status is one of the known unit statuses.
+func ValidUnitStatus(status string) bool {
+	switch status {
+	case UnitStatusActive, UnitStatusRepair, UnitStatusRetired:
+		return true
+	}
+	return false
+}
+
+// UnitHistory is the log of a unit: its status changes and the bookings it was assigned to.
+type UnitHistory struct {
+	Unit     *ItemUnit     `json:"unit"`
+	Entries  []*AuditEntry `json:"entries"`
+	Bookings []*Booking    `json:"bookings"`
+}
@@ -2,9 +2,15 @@ package models
 
 import (
 	"database/sql"
+	"errors"
 	"time"
 )
 
+var (
+	ErrCannotBlockStaff         = errors.New("staff members cannot be blacklisted")
+	ErrBlacklistManagedByConfig = errors.New("user is blacklisted in config")
+)
+
 type User struct {
 	ID               uint         `gorm:"primaryKey;autoIncrement"`
 	TelegramID       int64        `gorm:"uniqueIndex;not null"` // Уникальный ID Telegram
@@ -22,4 +28,18 @@ type User struct {
 	ConsentGivenAt   sql.NullTime // Когда было дано согласие
 	ConsentRevoked   bool         `gorm:"default:false"` // Отозвано ли согласие
 	ConsentRevokedAt sql.NullTime // Когда было отозвано
+	BlacklistReason  string       `gorm:"size:500"` // Причина блокировки
+	BlacklistedUntil sql.NullTime // До какого момента действует блокировка (NULL — бессрочно)
+	BlacklistedBy    int64        // Кто заблокировал
+
+	PreferredLanguage string // Язык, выбранный через /language (приоритетнее LanguageCode)
+	RemindersDisabled bool   // Пользователь отказался от напоминаний через /reminders
+}
+
+// IsBlockedAt сообщает, действует ли блокировка пользователя в момент t
+func (u *User) IsBlockedAt(t time.Time) bool {
+	if !u.IsBlacklisted {
+		return false
+	}
+	return !u.BlacklistedUntil.Valid || t.Before(u.BlacklistedUntil.Time)
 }
@@ -0,0 +1,152 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+
+	"bronivik/internal/domain"
+	"bronivik/internal/models"
+
+	"github.com/rs/zerolog"
+)
+
+// bookingAuditSnapshot содержит поля заявки, которые попадают в журнал.
+// Персональные данные клиента (имя, телефон) в журнал не пишутся.
+type bookingAuditSnapshot struct {
+	Status   string `json:"status"`
+	ItemID   int64  `json:"item_id"`
+	ItemName string `json:"item_name"`
+	Date     string `json:"date"`
+	Comment  string `json:"comment,omitempty"`
+	Version  int64  `json:"version"`
+}
+
+type itemAuditSnapshot struct {
+	Name          string            `json:"name"`
+	Description   string            `json:"description,omitempty"`
+	TotalQuantity int64             `json:"total_quantity"`
+	SortOrder     int64             `json:"sort_order"`
+	IsActive      bool              `json:"is_active"`
+	Category      string            `json:"category,omitempty"`
+	PricePerDay   int64             `json:"price_per_day,omitempty"`
+	WeekendPrice  int64             `json:"weekend_price,omitempty"`
+	Deposit       int64             `json:"deposit,omitempty"`
+	LongDays      int64             `json:"long_rental_days,omitempty"`
+	LongDiscount  int64             `json:"long_rental_discount,omitempty"`
+	Specs         []models.ItemSpec `json:"specs,omitempty"`
+	Photos        int               `json:"photos,omitempty"`
+}
+
+type roleAuditSnapshot struct {
+	Role    string  `json:"role"`
+	ItemIDs []int64 `json:"item_ids,omitempty"`
+}
+
+type blacklistAuditSnapshot struct {
+	Blacklisted bool   `json:"blacklisted"`
+	Reason      string `json:"reason,omitempty"`
+	Until       string `json:"until,omitempty"`
+}
+
+func bookingSnapshot(b *models.Booking) interface{} {
+	if b == nil {
+		return nil
+	}
+	return bookingAuditSnapshot{
+		Status:   b.Status,
+		ItemID:   b.ItemID,
+		ItemName: b.ItemName,
+		Date:     b.Date.Format("2006-01-02"),
+		Comment:  b.Comment,
+		Version:  b.Version,
+	}
+}
+
+func itemSnapshot(i *models.Item) interface{} {
+	if i == nil {
+		return nil
+	}
+	return itemAuditSnapshot{
+		Name:          i.Name,
+		Description:   i.Description,
+		TotalQuantity: i.TotalQuantity,
+		SortOrder:     i.SortOrder,
+		IsActive:      i.IsActive,
+		Category:      i.Category,
+		PricePerDay:   i.PricePerDay,
+		WeekendPrice:  i.WeekendPrice,
+		Deposit:       i.Deposit,
+		LongDays:      i.LongRentalDays,
+		LongDiscount:  i.LongRentalDiscount,
+		Specs:         i.Specs,
+		Photos:        len(i.PhotoFileIDs),
+	}
+}
+
+func roleSnapshot(r *models.UserRole) interface{} {
+	if r == nil {
+		return nil
+	}
+	return roleAuditSnapshot{Role: r.Role, ItemIDs: r.ItemIDs}
+}
+
+func blacklistSnapshot(u *models.User) interface{} {
+	if u == nil || !u.IsBlacklisted {
+		return blacklistAuditSnapshot{}
+	}
+	snap := blacklistAuditSnapshot{Blacklisted: true, Reason: u.BlacklistReason}
+	if u.BlacklistedUntil.Valid {
+		snap.Until = u.BlacklistedUntil.Time.Format("2006-01-02")
+	}
+	return snap
+}
+
+// recordAudit пишет запись в журнал изменений. Изменение к этому моменту уже сохранено, поэтому
+// ошибка записи журнала только логируется: операция, которая выглядит неудачной, повторяется
+// клиентом или менеджером и создает дубликат.
+func recordAudit(
+	ctx context.Context,
+	repo domain.Repository,
+	logger *zerolog.Logger,
+	entityType string,
+	entityID int64,
+	action string,
+	actorID int64,
+	before, after interface{},
+) {
+	actor := models.AuditActorFromContext(ctx)
+	if actorID == 0 {
+		actorID = actor.ID
+	}
+
+	entry := &models.AuditEntry{
+		EntityType: entityType,
+		EntityID:   entityID,
+		Action:     action,
+		ActorID:    actorID,
+		Source:     actor.Source,
+		Before:     marshalAuditValue(before),
+		After:      marshalAuditValue(after),
+	}
+
+	if err := repo.CreateAuditEntry(ctx, entry); err != nil && logger != nil {
+		logger.Error().Err(err).
+			Str("entity_type", entityType).
+			Int64("entity_id", entityID).
+			Str("action", action).
+			Int64("actor_id", actorID).
+			Str("source", actor.Source).
+			Msg("failed to write audit entry")
+	}
+}
+
+func marshalAuditValue(v interface{}) string {
+	if v == nil {
+		return ""
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return ""
+	}
+	return string(data)
+}
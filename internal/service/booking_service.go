@@ -3,6 +3,7 @@ package service
 import (
 	"context"
 	"errors"
+	"fmt"
 	"time"
 
 	"bronivik/internal/database"
@@ -88,6 +89,10 @@ func (s *BookingService) CreateBooking(ctx context.Context, booking *models.Book
 		return err
 	}
 
+	s.priceRental(ctx, booking)
+
+	recordAudit(ctx, s.repo, s.logger, models.AuditEntityBooking, booking.ID, models.AuditActionCreate, 0, nil, bookingSnapshot(booking))
+
 	// Публикуем событие
 	s.publishEvent(events.EventBookingCreated, booking, "system", 0)
 
@@ -116,12 +121,56 @@ func (s *BookingService) ReopenBooking(ctx context.Context, bookingID, version,
 	return s.updateStatusAndSync(ctx, bookingID, version, models.StatusPending, "", "", managerID)
 }
 
+// ApplyBulkAction применяет действие менеджера к каждой заявке отдельно со своей проверкой версии.
+// Конфликт или ошибка по одной заявке не прерывает обработку остальных.
+func (s *BookingService) ApplyBulkAction(
+	ctx context.Context,
+	action string,
+	bookings []*models.Booking,
+	managerID int64,
+) (*models.BulkResult, error) {
+	var apply func(ctx context.Context, bookingID, version, managerID int64) error
+	switch action {
+	case models.BulkActionConfirm:
+		apply = s.ConfirmBooking
+	case models.BulkActionReject:
+		apply = s.RejectBooking
+	case models.BulkActionComplete:
+		apply = s.CompleteBooking
+	default:
+		return nil, fmt.Errorf("unknown bulk action: %s", action)
+	}
+
+	result := &models.BulkResult{}
+	for _, booking := range bookings {
+		if !models.BulkActionAllowed(action, booking.Status) {
+			result.Skipped = append(result.Skipped, booking)
+			continue
+		}
+
+		err := apply(ctx, booking.ID, booking.Version, managerID)
+		switch {
+		case err == nil:
+			result.Succeeded = append(result.Succeeded, booking)
+		case errors.Is(err, database.ErrConcurrentModification):
+			result.Conflicts = append(result.Conflicts, booking)
+		default:
+			s.logger.Error().Err(err).Int64("booking_id", booking.ID).Str("action", action).Msg("bulk action failed")
+			result.Failed = append(result.Failed, booking)
+		}
+	}
+
+	return result, nil
+}
+
 func (s *BookingService) updateStatusAndSync(
 	ctx context.Context,
 	bookingID, version int64,
 	status, eventType, changedBy string,
 	managerID int64,
 ) error {
+	before, _ := s.repo.GetBooking(ctx, bookingID)
+
 	err := s.repo.UpdateBookingStatusWithVersion(ctx, bookingID, version, status)
 	if err != nil {
 		return err
@@ -129,12 +178,20 @@ func (s *BookingService) updateStatusAndSync(
 
 	booking, err := s.repo.GetBooking(ctx, bookingID)
 	if err == nil {
+		if status == models.StatusConfirmed {
+			s.assignUnit(ctx, booking, managerID)
+		}
+		s.repriceRental(ctx, before, booking)
+		recordAudit(ctx, s.repo, s.logger, models.AuditEntityBooking, bookingID, models.AuditActionStatusChange,
+			managerID, bookingSnapshot(before), bookingSnapshot(booking))
 		if eventType != "" {
 			s.publishEvent(eventType, booking, changedBy, managerID)
 		}
 		s.enqueueSync(ctx, booking, "update_status")
-		if err := s.sheetsWorker.EnqueueSyncSchedule(ctx, time.Time{}, time.Time{}); err != nil {
-			s.logger.Error().Err(err).Msg("failed to enqueue sync schedule")
+		if s.sheetsWorker != nil {
+			if err := s.sheetsWorker.EnqueueSyncSchedule(ctx, time.Time{}, time.Time{}); err != nil {
+				s.logger.Error().Err(err).Msg("failed to enqueue sync schedule")
+			}
 		}
 	}
 
@@ -143,7 +200,7 @@ func (s *BookingService) updateStatusAndSync(
 
 func (s *BookingService) ChangeBookingItem(ctx context.Context, bookingID, version, newItemID, managerID int64) error {
 	// Получаем текущую заявку и проверяем доступность нового аппарата
-	_, available, err := s.repo.GetBookingWithAvailability(ctx, bookingID, newItemID)
+	before, available, err := s.repo.GetBookingWithAvailability(ctx, bookingID, newItemID)
 	if err != nil {
 		return err
 	}
@@ -174,6 +231,9 @@ func (s *BookingService) ChangeBookingItem(ctx context.Context, bookingID, versi
 
 	updatedBooking, err := s.repo.GetBooking(ctx, bookingID)
 	if err == nil {
+		s.repriceRental(ctx, before, updatedBooking)
+		recordAudit(ctx, s.repo, s.logger, models.AuditEntityBooking, bookingID, models.AuditActionItemChange,
+			managerID, bookingSnapshot(before), bookingSnapshot(updatedBooking))
 		s.publishEvent(events.EventBookingItemChange, updatedBooking, "manager", managerID)
 		s.enqueueSync(ctx, updatedBooking, "upsert")
 		if err := s.sheetsWorker.EnqueueSyncSchedule(ctx, time.Time{}, time.Time{}); err != nil {
@@ -185,16 +245,23 @@ func (s *BookingService) ChangeBookingItem(ctx context.Context, bookingID, versi
 }
 
 func (s *BookingService) RescheduleBooking(ctx context.Context, bookingID, managerID int64) error {
-	err := s.repo.UpdateBookingStatus(ctx, bookingID, "rescheduled")
+	before, _ := s.repo.GetBooking(ctx, bookingID)
+
+	err := s.repo.UpdateBookingStatus(ctx, bookingID, models.StatusRescheduled)
 	if err != nil {
 		return err
 	}
 
 	booking, err := s.repo.GetBooking(ctx, bookingID)
 	if err == nil {
+		s.repriceRental(ctx, before, booking)
+		recordAudit(ctx, s.repo, s.logger, models.AuditEntityBooking, bookingID, models.AuditActionStatusChange,
+			managerID, bookingSnapshot(before), bookingSnapshot(booking))
 		s.enqueueSync(ctx, booking, "update_status")
-		if err := s.sheetsWorker.EnqueueSyncSchedule(ctx, time.Time{}, time.Time{}); err != nil {
-			s.logger.Error().Err(err).Msg("failed to enqueue sync schedule")
+		if s.sheetsWorker != nil {
+			if err := s.sheetsWorker.EnqueueSyncSchedule(ctx, time.Time{}, time.Time{}); err != nil {
+				s.logger.Error().Err(err).Msg("failed to enqueue sync schedule")
+			}
 		}
 	}
 
@@ -217,10 +284,45 @@ func (s *BookingService) GetBookingsByDateRange(ctx context.Context, start, end
 	return s.repo.GetBookingsByDateRange(ctx, start, end)
 }
 
+// SearchBookings возвращает заявки, подходящие под фильтр (поиск менеджера и HTTP API)
+func (s *BookingService) SearchBookings(ctx context.Context, filter models.BookingFilter) ([]*models.Booking, error) {
+	return s.repo.SearchBookings(ctx, filter)
+}
+
+// StreamBookings передает fn заявки по фильтру по одной, не загружая их все в память (выгрузки)
+func (s *BookingService) StreamBookings(ctx context.Context, filter models.BookingFilter, fn func(*models.Booking) error) error {
+	return s.repo.StreamBookings(ctx, filter, fn)
+}
+
 func (s *BookingService) GetBooking(ctx context.Context, id int64) (*models.Booking, error) {
 	return s.repo.GetBooking(ctx, id)
 }
 
+// GetBookingHistory возвращает журнал изменений заявки
+func (s *BookingService) GetBookingHistory(ctx context.Context, bookingID int64) ([]*models.AuditEntry, error) {
+	return s.repo.GetAuditEntries(ctx, models.AuditEntityBooking, bookingID)
+}
+
+// GetBookingEscalations возвращает записи об эскалации заявок, оставшихся без ответа
+func (s *BookingService) GetBookingEscalations(ctx context.Context, bookingIDs []int64) (map[int64]*models.BookingEscalation, error) {
+	return s.repo.GetBookingEscalations(ctx, bookingIDs)
+}
+
+// SaveBookingEscalation сохраняет очередную эскалацию заявки
+func (s *BookingService) SaveBookingEscalation(ctx context.Context, escalation *models.BookingEscalation) error {
+	return s.repo.SaveBookingEscalation(ctx, escalation)
+}
+
+// ClaimReminder отмечает напоминание отправленным; false — его уже отправил другой процесс
+func (s *BookingService) ClaimReminder(ctx context.Context, bookingID int64, key string) (bool, error) {
+	return s.repo.ClaimReminder(ctx, bookingID, key)
+}
+
+// ReleaseReminder снимает отметку, если напоминание не удалось доставить
+func (s *BookingService) ReleaseReminder(ctx context.Context, bookingID int64, key string) error {
+	return s.repo.ReleaseReminder(ctx, bookingID, key)
+}
+
 func (s *BookingService) GetDailyBookings(ctx context.Context, start, end time.Time) (map[string][]*models.Booking, error) {
 	return s.repo.GetDailyBookings(ctx, start, end)
 }
@@ -1,16 +1,20 @@
 package service
 
 import (
+	"bytes"
 	"context"
+	"errors"
 	"io"
 	"testing"
 	"time"
 
+	"bronivik/internal/database"
 	"bronivik/internal/models"
 
 	"github.com/rs/zerolog"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 )
 
 type mockRepo struct {
@@ -43,6 +47,24 @@ func (m *mockRepo) GetBookingsByDateRange(ctx context.Context, s, e time.Time) (
 	}
 	return args.Get(0).([]*models.Booking), args.Error(1)
 }
+func (m *mockRepo) SearchBookings(ctx context.Context, f models.BookingFilter) ([]*models.Booking, error) {
+	args := m.Called(ctx, f)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.Booking), args.Error(1)
+}
+func (m *mockRepo) StreamBookings(ctx context.Context, f models.BookingFilter, fn func(*models.Booking) error) error {
+	args := m.Called(ctx, f, fn)
+	return args.Error(0)
+}
+func (m *mockRepo) ImportBookings(ctx context.Context, b []*models.Booking, dryRun bool) ([]error, error) {
+	args := m.Called(ctx, b, dryRun)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]error), args.Error(1)
+}
 func (m *mockRepo) CheckAvailability(ctx context.Context, id int64, d time.Time) (bool, error) {
 	args := m.Called(ctx, id, d)
 	return args.Bool(0), args.Error(1)
@@ -78,6 +100,9 @@ func (m *mockRepo) GetItemByName(ctx context.Context, n string) (*models.Item, e
 func (m *mockRepo) CreateItem(ctx context.Context, i *models.Item) error {
 	return m.Called(ctx, i).Error(0)
 }
+func (m *mockRepo) ImportItems(ctx context.Context, i []*models.Item, dryRun bool) error {
+	return m.Called(ctx, i, dryRun).Error(0)
+}
 func (m *mockRepo) UpdateItem(ctx context.Context, i *models.Item) error {
 	return m.Called(ctx, i).Error(0)
 }
@@ -94,6 +119,9 @@ func (m *mockRepo) GetAllUsers(ctx context.Context) ([]*models.User, error) {
 	}
 	return args.Get(0).([]*models.User), args.Error(1)
 }
+func (m *mockRepo) ImportUsers(ctx context.Context, u []*models.User, dryRun bool) error {
+	return m.Called(ctx, u, dryRun).Error(0)
+}
 func (m *mockRepo) GetUserByTelegramID(ctx context.Context, id int64) (*models.User, error) {
 	args := m.Called(ctx, id)
 	if args.Get(0) == nil {
@@ -117,6 +145,12 @@ func (m *mockRepo) UpdateUserActivity(ctx context.Context, id int64) error {
 func (m *mockRepo) UpdateUserPhone(ctx context.Context, id int64, p string) error {
 	return m.Called(ctx, id, p).Error(0)
 }
+func (m *mockRepo) UpdateUserLanguage(ctx context.Context, id int64, lang string) error {
+	return m.Called(ctx, id, lang).Error(0)
+}
+func (m *mockRepo) UpdateUserReminders(ctx context.Context, id int64, disabled bool) error {
+	return m.Called(ctx, id, disabled).Error(0)
+}
 func (m *mockRepo) GetDailyBookings(ctx context.Context, s, e time.Time) (map[string][]*models.Booking, error) {
 	args := m.Called(ctx, s, e)
 	if args.Get(0) == nil {
@@ -160,6 +194,211 @@ func (m *mockRepo) GetUserBookings(ctx context.Context, id int64) ([]*models.Boo
 	}
 	return args.Get(0).([]*models.Booking), args.Error(1)
 }
+func (m *mockRepo) CreateAuditEntry(ctx context.Context, e *models.AuditEntry) error {
+	return m.Called(ctx, e).Error(0)
+}
+func (m *mockRepo) GetAuditEntries(ctx context.Context, et string, id int64) ([]*models.AuditEntry, error) {
+	args := m.Called(ctx, et, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.AuditEntry), args.Error(1)
+}
+func (m *mockRepo) GetUserRoles(ctx context.Context) ([]*models.UserRole, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.UserRole), args.Error(1)
+}
+func (m *mockRepo) SetUserRole(ctx context.Context, r *models.UserRole) error {
+	return m.Called(ctx, r).Error(0)
+}
+func (m *mockRepo) DeleteUserRole(ctx context.Context, id int64) error {
+	return m.Called(ctx, id).Error(0)
+}
+func (m *mockRepo) GetBlacklistedUsers(ctx context.Context) ([]*models.User, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.User), args.Error(1)
+}
+func (m *mockRepo) BlacklistUser(ctx context.Context, id int64, reason string, until time.Time, by int64) error {
+	return m.Called(ctx, id, reason, until, by).Error(0)
+}
+func (m *mockRepo) UnblacklistUser(ctx context.Context, id int64) error {
+	return m.Called(ctx, id).Error(0)
+}
+func (m *mockRepo) SetUserConsent(ctx context.Context, id int64) error {
+	return m.Called(ctx, id).Error(0)
+}
+func (m *mockRepo) GetAllUserBookings(ctx context.Context, id int64) ([]*models.Booking, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.Booking), args.Error(1)
+}
+func (m *mockRepo) AnonymizeUser(ctx context.Context, id int64) ([]int64, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]int64), args.Error(1)
+}
+func (m *mockRepo) GetBookingEscalations(ctx context.Context, ids []int64) (map[int64]*models.BookingEscalation, error) {
+	args := m.Called(ctx, ids)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(map[int64]*models.BookingEscalation), args.Error(1)
+}
+func (m *mockRepo) SaveBookingEscalation(ctx context.Context, e *models.BookingEscalation) error {
+	return m.Called(ctx, e).Error(0)
+}
+func (m *mockRepo) ClaimReminder(ctx context.Context, id int64, key string) (bool, error) {
+	args := m.Called(ctx, id, key)
+	return args.Bool(0), args.Error(1)
+}
+func (m *mockRepo) ReleaseReminder(ctx context.Context, id int64, key string) error {
+	return m.Called(ctx, id, key).Error(0)
+}
+func (m *mockRepo) CreateHandover(ctx context.Context, h *models.Handover) error {
+	return m.Called(ctx, h).Error(0)
+}
+func (m *mockRepo) CheckInHandover(ctx context.Context, h *models.Handover) error {
+	return m.Called(ctx, h).Error(0)
+}
+func (m *mockRepo) FindHandover(ctx context.Context, userID, itemID int64, d time.Time) (*models.Handover, error) {
+	args := m.Called(ctx, userID, itemID, d)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Handover), args.Error(1)
+}
+func (m *mockRepo) GetUserHandovers(ctx context.Context, id int64) ([]*models.Handover, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.Handover), args.Error(1)
+}
+func (m *mockRepo) GetOpenHandovers(ctx context.Context) ([]*models.Handover, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.Handover), args.Error(1)
+}
+func (m *mockRepo) GetHandoversByPeriod(ctx context.Context, s, e time.Time) ([]*models.Handover, error) {
+	args := m.Called(ctx, s, e)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.Handover), args.Error(1)
+}
+func (m *mockRepo) SetHandoverOverdueNotified(ctx context.Context, id int64, at time.Time) error {
+	return m.Called(ctx, id, at).Error(0)
+}
+func (m *mockRepo) CreateMaintenance(ctx context.Context, w *models.MaintenanceWindow) error {
+	return m.Called(ctx, w).Error(0)
+}
+func (m *mockRepo) GetMaintenance(ctx context.Context, id int64) (*models.MaintenanceWindow, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.MaintenanceWindow), args.Error(1)
+}
+func (m *mockRepo) DeleteMaintenance(ctx context.Context, id int64) error {
+	return m.Called(ctx, id).Error(0)
+}
+func (m *mockRepo) GetMaintenanceWindows(ctx context.Context, itemID int64, s, e time.Time) ([]*models.MaintenanceWindow, error) {
+	args := m.Called(ctx, itemID, s, e)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.MaintenanceWindow), args.Error(1)
+}
+func (m *mockRepo) GetExternalHolds(ctx context.Context, source string) ([]*models.MaintenanceWindow, error) {
+	args := m.Called(ctx, source)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.MaintenanceWindow), args.Error(1)
+}
+func (m *mockRepo) ReplaceExternalHolds(ctx context.Context, source string, windows []*models.MaintenanceWindow) error {
+	return m.Called(ctx, source, windows).Error(0)
+}
+func (m *mockRepo) CreateUnit(ctx context.Context, u *models.ItemUnit) error {
+	return m.Called(ctx, u).Error(0)
+}
+func (m *mockRepo) GetUnit(ctx context.Context, id int64) (*models.ItemUnit, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.ItemUnit), args.Error(1)
+}
+func (m *mockRepo) GetUnitBySerial(ctx context.Context, serial string) (*models.ItemUnit, error) {
+	args := m.Called(ctx, serial)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.ItemUnit), args.Error(1)
+}
+func (m *mockRepo) GetItemUnits(ctx context.Context, itemID int64) ([]*models.ItemUnit, error) {
+	args := m.Called(ctx, itemID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.ItemUnit), args.Error(1)
+}
+func (m *mockRepo) UpdateUnitStatus(ctx context.Context, id int64, s, n string) error {
+	return m.Called(ctx, id, s, n).Error(0)
+}
+func (m *mockRepo) SetBookingUnit(ctx context.Context, bid, uid int64) error {
+	return m.Called(ctx, bid, uid).Error(0)
+}
+func (m *mockRepo) SetBookingPrice(ctx context.Context, bid, price, deposit int64) error {
+	return m.Called(ctx, bid, price, deposit).Error(0)
+}
+func (m *mockRepo) GetBusyUnitIDs(ctx context.Context, itemID int64, d time.Time) ([]int64, error) {
+	args := m.Called(ctx, itemID, d)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]int64), args.Error(1)
+}
+func (m *mockRepo) GetUnitBookings(ctx context.Context, unitID int64) ([]*models.Booking, error) {
+	args := m.Called(ctx, unitID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.Booking), args.Error(1)
+}
+func (m *mockRepo) CreateCalendarFeed(ctx context.Context, f *models.CalendarFeed) error {
+	return m.Called(ctx, f).Error(0)
+}
+func (m *mockRepo) GetCalendarFeed(ctx context.Context, id int64) (*models.CalendarFeed, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.CalendarFeed), args.Error(1)
+}
+func (m *mockRepo) GetActiveCalendarFeed(ctx context.Context, scope string, subjectID int64) (*models.CalendarFeed, error) {
+	args := m.Called(ctx, scope, subjectID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.CalendarFeed), args.Error(1)
+}
+func (m *mockRepo) RevokeCalendarFeeds(ctx context.Context, scope string, subjectID int64) (int64, error) {
+	args := m.Called(ctx, scope, subjectID)
+	return args.Get(0).(int64), args.Error(1)
+}
 
 type mockEventBus struct {
 	mock.Mock
@@ -186,6 +425,9 @@ func TestBookingService(t *testing.T) {
 	svc := NewBookingService(repo, bus, worker, 30, 2, &logger)
 	ctx := context.Background()
 
+	repo.On("CreateAuditEntry", mock.Anything, mock.AnythingOfType("*models.AuditEntry")).Return(nil)
+	repo.On("GetItemUnits", ctx, mock.Anything).Return(nil, nil).Maybe()
+
 	t.Run("ValidateBookingDate", func(t *testing.T) {
 		now := time.Now()
 
@@ -208,6 +450,7 @@ func TestBookingService(t *testing.T) {
 
 		repo.On("CheckAvailability", ctx, int64(1), date).Return(true, nil).Once()
 		repo.On("CreateBookingWithLock", ctx, booking).Return(nil).Once()
+		repo.On("GetItemByID", ctx, int64(1)).Return(&models.Item{ID: 1}, nil).Once()
 		bus.On("PublishJSON", mock.Anything, mock.Anything).Return(nil).Once()
 		worker.On("EnqueueTask", ctx, "upsert", int64(0), booking, "").Return(nil).Once()
 		worker.On("EnqueueSyncSchedule", ctx, mock.Anything, mock.Anything).Return(nil).Once()
@@ -227,7 +470,7 @@ func TestBookingService(t *testing.T) {
 		t.Run(name, func(t *testing.T) {
 			booking := &models.Booking{ID: bookingID, Status: status}
 			repo.On("UpdateBookingStatusWithVersion", ctx, bookingID, version, status).Return(nil).Once()
-			repo.On("GetBooking", ctx, bookingID).Return(booking, nil).Once()
+			repo.On("GetBooking", ctx, bookingID).Return(booking, nil).Twice()
 			bus.On("PublishJSON", mock.Anything, mock.Anything).Return(nil).Once()
 			worker.On("EnqueueTask", ctx, "update_status", bookingID, booking, status).Return(nil).Once()
 			worker.On("EnqueueSyncSchedule", ctx, mock.Anything, mock.Anything).Return(nil).Once()
@@ -243,6 +486,39 @@ func TestBookingService(t *testing.T) {
 	testStatusUpdate("CompleteBooking", 12, 3, models.StatusCompleted, svc.CompleteBooking)
 	testStatusUpdate("ReopenBooking", 13, 4, models.StatusPending, svc.ReopenBooking)
 
+	t.Run("ApplyBulkAction", func(t *testing.T) {
+		ok := &models.Booking{ID: 20, Version: 1, Status: models.StatusPending}
+		stale := &models.Booking{ID: 21, Version: 1, Status: models.StatusPending}
+		broken := &models.Booking{ID: 22, Version: 4, Status: models.StatusChanged}
+		done := &models.Booking{ID: 23, Version: 2, Status: models.StatusCompleted}
+
+		repo.On("GetBooking", ctx, int64(20)).Return(ok, nil).Twice()
+		repo.On("UpdateBookingStatusWithVersion", ctx, int64(20), int64(1), models.StatusConfirmed).Return(nil).Once()
+		bus.On("PublishJSON", mock.Anything, mock.Anything).Return(nil).Once()
+		worker.On("EnqueueTask", ctx, "update_status", int64(20), ok, models.StatusPending).Return(nil).Once()
+		worker.On("EnqueueSyncSchedule", ctx, mock.Anything, mock.Anything).Return(nil).Once()
+
+		repo.On("GetBooking", ctx, int64(21)).Return(stale, nil).Once()
+		repo.On("UpdateBookingStatusWithVersion", ctx, int64(21), int64(1), models.StatusConfirmed).
+			Return(database.ErrConcurrentModification).Once()
+
+		repo.On("GetBooking", ctx, int64(22)).Return(broken, nil).Once()
+		repo.On("UpdateBookingStatusWithVersion", ctx, int64(22), int64(4), models.StatusConfirmed).
+			Return(assert.AnError).Once()
+
+		result, err := svc.ApplyBulkAction(ctx, models.BulkActionConfirm, []*models.Booking{ok, stale, broken, done}, 100)
+		assert.NoError(t, err)
+		assert.Equal(t, []*models.Booking{ok}, result.Succeeded)
+		assert.Equal(t, []*models.Booking{stale}, result.Conflicts)
+		assert.Equal(t, []*models.Booking{broken}, result.Failed)
+		assert.Equal(t, []*models.Booking{done}, result.Skipped)
+		assert.Equal(t, 4, result.Total())
+		repo.AssertExpectations(t)
+
+		_, err = svc.ApplyBulkAction(ctx, "reopen", []*models.Booking{ok}, 100)
+		assert.Error(t, err)
+	})
+
 	t.Run("ChangeBookingItem", func(t *testing.T) {
 		oldBooking := &models.Booking{ID: 14, ItemID: 1, ItemName: "Old Item", Status: models.StatusPending}
 		newBooking := &models.Booking{ID: 14, ItemID: 2, ItemName: "New Item", Status: models.StatusChanged}
@@ -252,6 +528,8 @@ func TestBookingService(t *testing.T) {
 		repo.On("GetActiveItems", ctx).Return(items, nil).Once()
 		repo.On("UpdateBookingItemAndStatusWithVersion", ctx, int64(14), int64(5), int64(2), "New Item", models.StatusChanged).Return(nil).Once()
 		repo.On("GetBooking", ctx, int64(14)).Return(newBooking, nil).Once()
+		repo.On("GetItemByID", ctx, int64(1)).Return(&models.Item{ID: 1}, nil).Once()
+		repo.On("GetItemByID", ctx, int64(2)).Return(&models.Item{ID: 2}, nil).Once()
 		bus.On("PublishJSON", mock.Anything, mock.Anything).Return(nil).Once()
 		worker.On("EnqueueTask", ctx, "upsert", int64(14), newBooking, "").Return(nil).Once()
 		worker.On("EnqueueSyncSchedule", ctx, mock.Anything, mock.Anything).Return(nil).Once()
@@ -265,7 +543,7 @@ func TestBookingService(t *testing.T) {
 		booking := &models.Booking{ID: 15, Status: "rescheduled"}
 
 		repo.On("UpdateBookingStatus", ctx, int64(15), "rescheduled").Return(nil).Once()
-		repo.On("GetBooking", ctx, int64(15)).Return(booking, nil).Once()
+		repo.On("GetBooking", ctx, int64(15)).Return(booking, nil).Twice()
 		worker.On("EnqueueTask", ctx, "update_status", int64(15), booking, "rescheduled").Return(nil).Once()
 		worker.On("EnqueueSyncSchedule", ctx, mock.Anything, mock.Anything).Return(nil).Once()
 
@@ -274,6 +552,17 @@ func TestBookingService(t *testing.T) {
 		repo.AssertExpectations(t)
 	})
 
+	t.Run("GetBookingHistory", func(t *testing.T) {
+		entries := []*models.AuditEntry{{ID: 1, EntityType: models.AuditEntityBooking, EntityID: 17}}
+
+		repo.On("GetAuditEntries", ctx, models.AuditEntityBooking, int64(17)).Return(entries, nil).Once()
+
+		result, err := svc.GetBookingHistory(ctx, 17)
+		assert.NoError(t, err)
+		assert.Equal(t, entries, result)
+		repo.AssertExpectations(t)
+	})
+
 	t.Run("GetAvailability", func(t *testing.T) {
 		availabilities := []*models.Availability{{Date: time.Now(), ItemID: 1, Booked: 2, Available: 3}}
 
@@ -316,6 +605,51 @@ func TestBookingService(t *testing.T) {
 		repo.AssertExpectations(t)
 	})
 
+	t.Run("SearchBookings", func(t *testing.T) {
+		filter := models.BookingFilter{Statuses: []string{models.StatusPending}, Phone: "79991234567"}
+		bookings := []*models.Booking{{ID: 3}}
+
+		repo.On("SearchBookings", ctx, filter).Return(bookings, nil).Once()
+
+		result, err := svc.SearchBookings(ctx, filter)
+		assert.NoError(t, err)
+		assert.Equal(t, bookings, result)
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("StreamBookings", func(t *testing.T) {
+		filter := models.BookingFilter{Statuses: []string{models.StatusConfirmed}}
+		stop := errors.New("stop")
+
+		repo.On("StreamBookings", ctx, filter, mock.Anything).Return(stop).Once()
+
+		err := svc.StreamBookings(ctx, filter, func(*models.Booking) error { return nil })
+		assert.ErrorIs(t, err, stop)
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("ImportBookings", func(t *testing.T) {
+		saved := &models.Booking{ID: 30, Date: time.Now(), Status: models.StatusCompleted}
+		skipped := &models.Booking{Date: time.Now(), Status: models.StatusConfirmed}
+		bookings := []*models.Booking{saved, skipped}
+
+		// Пробный импорт ничего не ставит в очередь синхронизации
+		repo.On("ImportBookings", ctx, bookings, true).Return([]error{nil, database.ErrNotAvailable}, nil).Once()
+		rejected, err := svc.ImportBookings(ctx, bookings, true)
+		assert.NoError(t, err)
+		assert.ErrorIs(t, rejected[1], database.ErrNotAvailable)
+		worker.AssertNotCalled(t, "EnqueueTask", ctx, "upsert", int64(30), saved, "")
+
+		repo.On("ImportBookings", ctx, bookings, false).Return([]error{nil, database.ErrNotAvailable}, nil).Once()
+		worker.On("EnqueueTask", ctx, "upsert", int64(30), saved, "").Return(nil).Once()
+		worker.On("EnqueueSyncSchedule", ctx, time.Time{}, time.Time{}).Return(nil).Once()
+
+		_, err = svc.ImportBookings(ctx, bookings, false)
+		assert.NoError(t, err)
+		repo.AssertExpectations(t)
+		worker.AssertExpectations(t)
+	})
+
 	t.Run("GetBooking", func(t *testing.T) {
 		booking := &models.Booking{ID: 16}
 
@@ -327,6 +661,31 @@ func TestBookingService(t *testing.T) {
 		repo.AssertExpectations(t)
 	})
 
+	t.Run("BookingEscalations", func(t *testing.T) {
+		escalation := &models.BookingEscalation{BookingID: 17, Level: 1, AssignedTo: 5, EscalatedAt: time.Now()}
+		escalations := map[int64]*models.BookingEscalation{17: escalation}
+
+		repo.On("GetBookingEscalations", ctx, []int64{17, 18}).Return(escalations, nil).Once()
+		repo.On("SaveBookingEscalation", ctx, escalation).Return(nil).Once()
+
+		result, err := svc.GetBookingEscalations(ctx, []int64{17, 18})
+		assert.NoError(t, err)
+		assert.Equal(t, escalations, result)
+		assert.NoError(t, svc.SaveBookingEscalation(ctx, escalation))
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("Reminders", func(t *testing.T) {
+		repo.On("ClaimReminder", ctx, int64(19), "before:1").Return(true, nil).Once()
+		repo.On("ReleaseReminder", ctx, int64(19), "before:1").Return(nil).Once()
+
+		claimed, err := svc.ClaimReminder(ctx, 19, "before:1")
+		assert.NoError(t, err)
+		assert.True(t, claimed)
+		assert.NoError(t, svc.ReleaseReminder(ctx, 19, "before:1"))
+		repo.AssertExpectations(t)
+	})
+
 	t.Run("GetDailyBookings", func(t *testing.T) {
 		start := time.Now()
 		end := start.AddDate(0, 0, 7)
@@ -340,3 +699,349 @@ func TestBookingService(t *testing.T) {
 		repo.AssertExpectations(t)
 	})
 }
+
+func TestBookingService_Handovers(t *testing.T) {
+	repo := new(mockRepo)
+	bus := new(mockEventBus)
+	worker := new(mockWorker)
+	logger := zerolog.New(io.Discard)
+	svc := NewBookingService(repo, bus, worker, 30, 2, &logger)
+	ctx := context.Background()
+
+	day := func(d int) time.Time { return time.Date(2030, 3, d, 0, 0, 0, 0, time.UTC) }
+	first := &models.Booking{ID: 30, UserID: 5, ItemID: 1, ItemName: "Camera", Date: day(1), Status: models.StatusConfirmed, Version: 1}
+	middle := &models.Booking{ID: 31, UserID: 5, ItemID: 1, ItemName: "Camera", Date: day(2), Status: models.StatusChanged, Version: 2}
+	last := &models.Booking{ID: 32, UserID: 5, ItemID: 1, ItemName: "Camera", Date: day(3), Status: models.StatusConfirmed, Version: 3}
+	otherItem := &models.Booking{ID: 33, UserID: 5, ItemID: 2, Date: day(4), Status: models.StatusConfirmed}
+	afterGap := &models.Booking{ID: 34, UserID: 5, ItemID: 1, Date: day(5), Status: models.StatusConfirmed}
+	pending := &models.Booking{ID: 35, UserID: 5, ItemID: 1, Date: day(9), Status: models.StatusPending}
+	all := []*models.Booking{pending, afterGap, otherItem, last, middle, first}
+
+	repo.On("CreateAuditEntry", ctx, mock.AnythingOfType("*models.AuditEntry")).Return(nil)
+	repo.On("GetAllUserBookings", ctx, int64(5)).Return(all, nil)
+	repo.On("GetItemUnits", ctx, int64(1)).Return(nil, nil)
+	for _, b := range []*models.Booking{first, middle, last, afterGap, pending} {
+		repo.On("GetBooking", ctx, b.ID).Return(b, nil)
+	}
+
+	t.Run("CheckOutRequiresConfirmedBooking", func(t *testing.T) {
+		_, err := svc.CheckOutBooking(ctx, 35, 100, "", nil)
+		assert.ErrorIs(t, err, models.ErrHandoverNotAllowed)
+	})
+
+	var handover *models.Handover
+	t.Run("CheckOutCoversRun", func(t *testing.T) {
+		repo.On("FindHandover", ctx, int64(5), int64(1), day(2)).Return(nil, nil).Once()
+		repo.On("CreateHandover", ctx, mock.AnythingOfType("*models.Handover")).
+			Run(func(args mock.Arguments) { args.Get(1).(*models.Handover).ID = 7 }).
+			Return(nil).Once()
+
+		var err error
+		handover, err = svc.CheckOutBooking(ctx, 31, 100, "scratch", []string{"photo"})
+		require.NoError(t, err)
+		assert.Equal(t, int64(7), handover.ID)
+		assert.Equal(t, int64(30), handover.BookingID)
+		assert.Equal(t, day(1), handover.PlannedStart)
+		assert.Equal(t, day(3), handover.PlannedEnd)
+		assert.Equal(t, int64(100), handover.CheckedOutBy)
+		assert.Equal(t, []string{"photo"}, handover.CheckOutPhotos)
+	})
+
+	t.Run("CheckOutTwice", func(t *testing.T) {
+		repo.On("FindHandover", ctx, int64(5), int64(1), day(3)).Return(handover, nil).Once()
+
+		_, err := svc.CheckOutBooking(ctx, 32, 100, "", nil)
+		assert.ErrorIs(t, err, models.ErrAlreadyCheckedOut)
+	})
+
+	t.Run("CheckInCompletesRun", func(t *testing.T) {
+		repo.On("FindHandover", ctx, int64(5), int64(1), day(3)).Return(handover, nil).Once()
+		repo.On("CheckInHandover", ctx, handover).Return(nil).Once()
+		for _, b := range []*models.Booking{first, middle, last} {
+			repo.On("UpdateBookingStatusWithVersion", ctx, b.ID, b.Version, models.StatusCompleted).Return(nil).Once()
+		}
+		bus.On("PublishJSON", mock.Anything, mock.Anything).Return(nil)
+		worker.On("EnqueueTask", ctx, "update_status", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+		worker.On("EnqueueSyncSchedule", ctx, mock.Anything, mock.Anything).Return(nil)
+
+		result, err := svc.CheckInBooking(ctx, 32, 101, "ok", nil)
+		require.NoError(t, err)
+		assert.True(t, result.IsReturned())
+		assert.Equal(t, int64(101), result.CheckedInBy)
+		assert.Equal(t, "ok", result.CheckInNote)
+	})
+
+	t.Run("CheckInWithoutCheckOut", func(t *testing.T) {
+		repo.On("FindHandover", ctx, int64(5), int64(1), day(5)).Return(nil, nil).Once()
+
+		_, err := svc.CheckInBooking(ctx, 34, 101, "", nil)
+		assert.ErrorIs(t, err, models.ErrNotCheckedOut)
+	})
+
+	t.Run("Queries", func(t *testing.T) {
+		at := time.Now()
+		repo.On("GetOpenHandovers", ctx).Return([]*models.Handover{handover}, nil).Once()
+		repo.On("GetHandoversByPeriod", ctx, day(1), day(31)).Return([]*models.Handover{handover}, nil).Once()
+		repo.On("SetHandoverOverdueNotified", ctx, int64(7), at).Return(nil).Once()
+
+		open, err := svc.GetOpenHandovers(ctx)
+		assert.NoError(t, err)
+		assert.Len(t, open, 1)
+		period, err := svc.GetHandoversByPeriod(ctx, day(1), day(31))
+		assert.NoError(t, err)
+		assert.Len(t, period, 1)
+		assert.NoError(t, svc.MarkHandoverOverdueNotified(ctx, 7, at))
+	})
+
+	repo.AssertExpectations(t)
+}
+
+func TestBookingService_AuditRecordsActorAndSnapshots(t *testing.T) {
+	repo := new(mockRepo)
+	bus := new(mockEventBus)
+	worker := new(mockWorker)
+	logger := zerolog.New(io.Discard)
+	svc := NewBookingService(repo, bus, worker, 30, 2, &logger)
+	ctx := models.WithAuditActor(context.Background(), models.AuditActor{ID: 555, Source: models.AuditSourceBot})
+
+	before := &models.Booking{ID: 20, Status: models.StatusPending, ItemName: "Camera", Phone: "79991234567", Version: 1}
+	after := &models.Booking{ID: 20, Status: models.StatusConfirmed, ItemName: "Camera", Phone: "79991234567", Version: 2}
+
+	repo.On("GetBooking", ctx, int64(20)).Return(before, nil).Once()
+	repo.On("UpdateBookingStatusWithVersion", ctx, int64(20), int64(1), models.StatusConfirmed).Return(nil).Once()
+	repo.On("GetBooking", ctx, int64(20)).Return(after, nil).Once()
+	repo.On("GetItemUnits", ctx, int64(0)).Return(nil, nil)
+	bus.On("PublishJSON", mock.Anything, mock.Anything).Return(nil)
+	worker.On("EnqueueTask", ctx, "update_status", int64(20), after, models.StatusConfirmed).Return(nil)
+	worker.On("EnqueueSyncSchedule", ctx, mock.Anything, mock.Anything).Return(nil)
+
+	var recorded *models.AuditEntry
+	repo.On("CreateAuditEntry", ctx, mock.AnythingOfType("*models.AuditEntry")).
+		Run(func(args mock.Arguments) { recorded = args.Get(1).(*models.AuditEntry) }).
+		Return(nil).Once()
+
+	err := svc.ConfirmBooking(ctx, 20, 1, 100)
+	assert.NoError(t, err)
+	repo.AssertExpectations(t)
+
+	if assert.NotNil(t, recorded) {
+		assert.Equal(t, models.AuditEntityBooking, recorded.EntityType)
+		assert.Equal(t, int64(20), recorded.EntityID)
+		assert.Equal(t, models.AuditActionStatusChange, recorded.Action)
+		assert.Equal(t, int64(100), recorded.ActorID)
+		assert.Equal(t, models.AuditSourceBot, recorded.Source)
+		assert.Contains(t, recorded.Before, `"status":"pending"`)
+		assert.Contains(t, recorded.After, `"status":"confirmed"`)
+		assert.NotContains(t, recorded.After, "79991234567")
+	}
+}
+
+func TestBookingService_AuditErrorIsLogged(t *testing.T) {
+	ctx := context.Background()
+	newService := func() (*BookingService, *mockRepo, *mockWorker, *bytes.Buffer) {
+		repo := new(mockRepo)
+		bus := new(mockEventBus)
+		worker := new(mockWorker)
+		var logs bytes.Buffer
+		logger := zerolog.New(&logs)
+		repo.On("CreateAuditEntry", ctx, mock.AnythingOfType("*models.AuditEntry")).Return(errors.New("disk full")).Once()
+		bus.On("PublishJSON", mock.Anything, mock.Anything).Return(nil)
+		worker.On("EnqueueSyncSchedule", ctx, mock.Anything, mock.Anything).Return(nil).Once()
+		return NewBookingService(repo, bus, worker, 30, 2, &logger), repo, worker, &logs
+	}
+
+	// Изменение уже сохранено: ошибка журнала не должна выглядеть как неудачная операция,
+	// иначе клиент повторит бронирование, а таблица не получит изменение
+	t.Run("Reject", func(t *testing.T) {
+		svc, repo, worker, logs := newService()
+		before := &models.Booking{ID: 21, Status: models.StatusConfirmed, Version: 3}
+		after := &models.Booking{ID: 21, Status: models.StatusCanceled, Version: 4}
+		repo.On("GetBooking", ctx, int64(21)).Return(before, nil).Once()
+		repo.On("UpdateBookingStatusWithVersion", ctx, int64(21), int64(3), models.StatusCanceled).Return(nil).Once()
+		repo.On("GetBooking", ctx, int64(21)).Return(after, nil).Once()
+		repo.On("GetItemByID", ctx, int64(0)).Return(&models.Item{}, nil).Once()
+		worker.On("EnqueueTask", ctx, "update_status", int64(21), after, models.StatusCanceled).Return(nil).Once()
+
+		assert.NoError(t, svc.RejectBooking(ctx, 21, 3, 100))
+		assert.Contains(t, logs.String(), "failed to write audit entry")
+		assert.Contains(t, logs.String(), "disk full")
+		repo.AssertExpectations(t)
+		worker.AssertExpectations(t)
+	})
+
+	t.Run("Create", func(t *testing.T) {
+		svc, repo, worker, logs := newService()
+		date := time.Now().AddDate(0, 0, 5)
+		booking := &models.Booking{ItemID: 1, Date: date}
+		repo.On("CheckAvailability", ctx, int64(1), date).Return(true, nil).Once()
+		repo.On("CreateBookingWithLock", ctx, booking).Return(nil).Once()
+		repo.On("GetItemByID", ctx, int64(1)).Return(&models.Item{ID: 1}, nil).Once()
+		worker.On("EnqueueTask", ctx, "upsert", int64(0), booking, "").Return(nil).Once()
+
+		assert.NoError(t, svc.CreateBooking(ctx, booking))
+		assert.Contains(t, logs.String(), "failed to write audit entry")
+		repo.AssertExpectations(t)
+		worker.AssertExpectations(t)
+	})
+}
+
+func TestBookingService_AssignUnit(t *testing.T) {
+	repo := new(mockRepo)
+	bus := new(mockEventBus)
+	worker := new(mockWorker)
+	logger := zerolog.New(io.Discard)
+	svc := NewBookingService(repo, bus, worker, 30, 2, &logger)
+	ctx := context.Background()
+
+	day := func(d int) time.Time { return time.Date(2030, 6, d, 0, 0, 0, 0, time.UTC) }
+	repairing := &models.ItemUnit{ID: 1, ItemID: 1, SerialNumber: "SN-1", Status: models.UnitStatusRepair}
+	taken := &models.ItemUnit{ID: 2, ItemID: 1, SerialNumber: "SN-2", Status: models.UnitStatusActive}
+	free := &models.ItemUnit{ID: 3, ItemID: 1, SerialNumber: "SN-3", Status: models.UnitStatusActive}
+	units := []*models.ItemUnit{repairing, taken, free}
+
+	assigned := &models.Booking{ID: 40, UserID: 5, ItemID: 1, Date: day(1), Status: models.StatusConfirmed, UnitID: 3}
+	before := &models.Booking{ID: 41, UserID: 5, ItemID: 1, Date: day(2), Status: models.StatusPending, Version: 1}
+	after := &models.Booking{ID: 41, UserID: 5, ItemID: 1, Date: day(2), Status: models.StatusConfirmed, Version: 2}
+
+	repo.On("CreateAuditEntry", ctx, mock.AnythingOfType("*models.AuditEntry")).Return(nil)
+	repo.On("GetBooking", ctx, int64(41)).Return(before, nil).Once()
+	repo.On("UpdateBookingStatusWithVersion", ctx, int64(41), int64(1), models.StatusConfirmed).Return(nil).Once()
+	repo.On("GetBooking", ctx, int64(41)).Return(after, nil).Once()
+	repo.On("GetItemUnits", ctx, int64(1)).Return(units, nil)
+	repo.On("GetAllUserBookings", ctx, int64(5)).Return([]*models.Booking{after, assigned}, nil)
+	bus.On("PublishJSON", mock.Anything, mock.Anything).Return(nil)
+	worker.On("EnqueueTask", ctx, "update_status", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	worker.On("EnqueueSyncSchedule", ctx, mock.Anything, mock.Anything).Return(nil)
+
+	t.Run("PrefersUnitOfRun", func(t *testing.T) {
+		repo.On("GetBusyUnitIDs", ctx, int64(1), day(2)).Return([]int64{2}, nil).Once()
+		repo.On("SetBookingUnit", ctx, int64(41), int64(3)).Return(nil).Once()
+
+		require.NoError(t, svc.ConfirmBooking(ctx, 41, 1, 100))
+		assert.Equal(t, int64(3), after.UnitID)
+	})
+
+	t.Run("SkipsRepairAndBusyUnits", func(t *testing.T) {
+		run := []*models.Booking{{ID: 50, ItemID: 1, Date: day(5)}, {ID: 51, ItemID: 1, Date: day(6)}}
+		repo.On("GetBusyUnitIDs", ctx, int64(1), day(5)).Return(nil, nil).Once()
+		repo.On("GetBusyUnitIDs", ctx, int64(1), day(6)).Return([]int64{2}, nil).Once()
+		repo.On("SetBookingUnit", ctx, int64(50), int64(3)).Return(nil).Once()
+		repo.On("SetBookingUnit", ctx, int64(51), int64(3)).Return(nil).Once()
+
+		require.NoError(t, svc.assignRunUnit(ctx, run, units, 100))
+		assert.Equal(t, int64(3), run[0].UnitID)
+		assert.Equal(t, int64(3), run[1].UnitID)
+	})
+
+	t.Run("NoFreeUnit", func(t *testing.T) {
+		run := []*models.Booking{{ID: 60, ItemID: 1, Date: day(9)}}
+		repo.On("GetBusyUnitIDs", ctx, int64(1), day(9)).Return([]int64{2, 3}, nil).Once()
+
+		assert.ErrorIs(t, svc.assignRunUnit(ctx, run, units, 100), models.ErrNoFreeUnit)
+		assert.Zero(t, run[0].UnitID)
+	})
+
+	repo.AssertExpectations(t)
+}
+
+func TestBookingService_PriceRental(t *testing.T) {
+	repo := new(mockRepo)
+	logger := zerolog.New(io.Discard)
+	svc := NewBookingService(repo, new(mockEventBus), new(mockWorker), 30, 2, &logger)
+	ctx := context.Background()
+
+	// 1 марта 2030 - пятница, 2 и 3 - выходные
+	day := func(d int) time.Time { return time.Date(2030, 3, d, 0, 0, 0, 0, time.UTC) }
+	item := &models.Item{ID: 1, PricePerDay: 1000, WeekendPrice: 1500, Deposit: 5000, LongRentalDays: 3, LongRentalDiscount: 10}
+	first := &models.Booking{ID: 40, UserID: 5, ItemID: 1, Phone: "79991234567", Date: day(1), Status: models.StatusPending, Price: 1000, Deposit: 5000}
+	second := &models.Booking{ID: 41, UserID: 5, ItemID: 1, Phone: "79991234567", Date: day(2), Status: models.StatusConfirmed, Price: 1500}
+	created := &models.Booking{ID: 42, UserID: 5, ItemID: 1, Phone: "79991234567", Date: day(3), Status: models.StatusPending}
+	otherClient := &models.Booking{ID: 43, UserID: 5, ItemID: 1, Phone: "79990000000", Date: day(4), Status: models.StatusPending}
+	canceled := &models.Booking{ID: 44, UserID: 5, ItemID: 1, Phone: "79991234567", Date: day(4), Status: models.StatusCanceled}
+
+	repo.On("GetItemByID", ctx, int64(1)).Return(item, nil).Once()
+	repo.On("GetAllUserBookings", ctx, int64(5)).Return([]*models.Booking{canceled, otherClient, created, second, first}, nil)
+	repo.On("SetBookingPrice", ctx, int64(40), int64(900), int64(5000)).Return(nil).Once()
+	repo.On("SetBookingPrice", ctx, int64(41), int64(1350), int64(0)).Return(nil).Once()
+	repo.On("SetBookingPrice", ctx, int64(42), int64(1350), int64(0)).Return(nil).Once()
+
+	svc.priceRental(ctx, created)
+	assert.Equal(t, int64(1350), created.Price)
+
+	quote, err := svc.GetBookingQuote(ctx, created)
+	require.NoError(t, err)
+	assert.Equal(t, 3, quote.Days)
+	assert.Equal(t, day(1), quote.Start)
+	assert.Equal(t, day(3), quote.End)
+	assert.Equal(t, int64(3600), quote.Total)
+	assert.Equal(t, int64(5000), quote.Deposit)
+
+	repo.AssertExpectations(t)
+}
+
+// Цены оставшихся дней пересчитываются, когда аренда становится короче
+func TestBookingService_RepriceShortenedRental(t *testing.T) {
+	ctx := context.Background()
+	// 1 марта 2030 - пятница, 2 и 3 - выходные; три дня аренды идут со скидкой
+	day := func(d int) time.Time { return time.Date(2030, 3, d, 0, 0, 0, 0, time.UTC) }
+	item := &models.Item{ID: 1, PricePerDay: 1000, WeekendPrice: 1500, Deposit: 5000, LongRentalDays: 3, LongRentalDiscount: 10}
+	rental := func() []*models.Booking {
+		days := []*models.Booking{{ID: 40, Price: 900, Deposit: 5000}, {ID: 41, Price: 1350}, {ID: 42, Price: 1350}}
+		for i, b := range days {
+			b.UserID, b.ItemID, b.Phone, b.Date = 5, 1, "79991234567", day(i+1)
+			b.Status, b.Version = models.StatusConfirmed, 1
+		}
+		return days
+	}
+	newService := func() (*BookingService, *mockRepo) {
+		repo := new(mockRepo)
+		bus := new(mockEventBus)
+		worker := new(mockWorker)
+		logger := zerolog.New(io.Discard)
+		repo.On("CreateAuditEntry", ctx, mock.AnythingOfType("*models.AuditEntry")).Return(nil)
+		bus.On("PublishJSON", mock.Anything, mock.Anything).Return(nil)
+		worker.On("EnqueueTask", ctx, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
+		worker.On("EnqueueSyncSchedule", ctx, mock.Anything, mock.Anything).Return(nil)
+		return NewBookingService(repo, bus, worker, 30, 2, &logger), repo
+	}
+
+	t.Run("RejectFirstDay", func(t *testing.T) {
+		svc, repo := newService()
+		days := rental()
+		before := *days[0]
+		days[0].Status = models.StatusCanceled
+
+		repo.On("GetBooking", ctx, int64(40)).Return(&before, nil).Once()
+		repo.On("UpdateBookingStatusWithVersion", ctx, int64(40), int64(1), models.StatusCanceled).Return(nil).Once()
+		repo.On("GetBooking", ctx, int64(40)).Return(days[0], nil).Once()
+		repo.On("GetItemByID", ctx, int64(1)).Return(item, nil).Once()
+		repo.On("GetAllUserBookings", ctx, int64(5)).Return(days, nil).Once()
+		// Два выходных дня без скидки, залог переходит на новый первый день
+		repo.On("SetBookingPrice", ctx, int64(41), int64(1500), int64(5000)).Return(nil).Once()
+		repo.On("SetBookingPrice", ctx, int64(42), int64(1500), int64(0)).Return(nil).Once()
+
+		require.NoError(t, svc.RejectBooking(ctx, 40, 1, 100))
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("ChangeItemOfLastDay", func(t *testing.T) {
+		svc, repo := newService()
+		days := rental()
+		before := *days[2]
+		days[2].ItemID, days[2].ItemName, days[2].Status = 2, "Light", models.StatusChanged
+
+		repo.On("GetBookingWithAvailability", ctx, int64(42), int64(2)).Return(&before, true, nil).Once()
+		repo.On("GetActiveItems", ctx).Return([]*models.Item{item, {ID: 2, Name: "Light"}}, nil).Once()
+		repo.On("UpdateBookingItemAndStatusWithVersion", ctx, int64(42), int64(1), int64(2), "Light", models.StatusChanged).
+			Return(nil).Once()
+		repo.On("GetBooking", ctx, int64(42)).Return(days[2], nil).Once()
+		repo.On("GetItemByID", ctx, int64(1)).Return(item, nil).Once()
+		repo.On("GetItemByID", ctx, int64(2)).Return(&models.Item{ID: 2}, nil).Once()
+		repo.On("GetAllUserBookings", ctx, int64(5)).Return(days, nil).Once()
+		repo.On("SetBookingPrice", ctx, int64(40), int64(1000), int64(5000)).Return(nil).Once()
+		repo.On("SetBookingPrice", ctx, int64(41), int64(1500), int64(0)).Return(nil).Once()
+
+		require.NoError(t, svc.ChangeBookingItem(ctx, 42, 1, 2, 100))
+		repo.AssertExpectations(t)
+	})
+}
@@ -0,0 +1,48 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"bronivik/internal/models"
+)
+
+// GetCalendarFeed возвращает действующую подписку на календарь, а если ее нет — выдает новую
+func (s *UserService) GetCalendarFeed(ctx context.Context, scope string, subjectID, createdBy int64) (*models.CalendarFeed, error) {
+	if !models.IsCalendarScope(scope) {
+		return nil, fmt.Errorf("unknown calendar scope %q", scope)
+	}
+
+	feed, err := s.repo.GetActiveCalendarFeed(ctx, scope, subjectID)
+	if err == nil {
+		return feed, nil
+	}
+	if !errors.Is(err, models.ErrCalendarFeedNotFound) {
+		return nil, err
+	}
+	return s.issueCalendarFeed(ctx, scope, subjectID, createdBy)
+}
+
+// ResetCalendarFeed отзывает выданные ссылки на календарь и выдает новую
+func (s *UserService) ResetCalendarFeed(ctx context.Context, scope string, subjectID, createdBy int64) (*models.CalendarFeed, error) {
+	if !models.IsCalendarScope(scope) {
+		return nil, fmt.Errorf("unknown calendar scope %q", scope)
+	}
+
+	revoked, err := s.repo.RevokeCalendarFeeds(ctx, scope, subjectID)
+	if err != nil {
+		return nil, err
+	}
+	s.logger.Info().Str("scope", scope).Int64("subject_id", subjectID).Int64("revoked", revoked).
+		Int64("actor_id", createdBy).Msg("Calendar feeds revoked")
+	return s.issueCalendarFeed(ctx, scope, subjectID, createdBy)
+}
+
+func (s *UserService) issueCalendarFeed(ctx context.Context, scope string, subjectID, createdBy int64) (*models.CalendarFeed, error) {
+	feed := &models.CalendarFeed{Scope: scope, SubjectID: subjectID, CreatedBy: createdBy}
+	if err := s.repo.CreateCalendarFeed(ctx, feed); err != nil {
+		return nil, err
+	}
+	return feed, nil
+}
@@ -0,0 +1,211 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"sort"
+	"time"
+
+	"bronivik/internal/models"
+)
+
+// handoverAuditSnapshot - данные выдачи или возврата для журнала; фото хранятся в выдаче, в журнал идет их количество
+type handoverAuditSnapshot struct {
+	PlannedStart string  `json:"planned_start"`
+	PlannedEnd   string  `json:"planned_end"`
+	Note         string  `json:"note,omitempty"`
+	Photos       int     `json:"photos,omitempty"`
+	BookingIDs   []int64 `json:"booking_ids,omitempty"`
+}
+
+// CheckOutBooking фиксирует выдачу аппарата по подтвержденной заявке.
+// Выдача относится ко всем подряд идущим дням брони того же клиента и аппарата.
+func (s *BookingService) CheckOutBooking(
+	ctx context.Context,
+	bookingID, managerID int64,
+	note string,
+	photos []string,
+) (*models.Handover, error) {
+	booking, err := s.repo.GetBooking(ctx, bookingID)
+	if err != nil {
+		return nil, err
+	}
+	if !isHandoverStatus(booking.Status) {
+		return nil, models.ErrHandoverNotAllowed
+	}
+
+	existing, err := s.repo.FindHandover(ctx, booking.UserID, booking.ItemID, booking.Date)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		return nil, models.ErrAlreadyCheckedOut
+	}
+
+	run, err := s.bookingRun(ctx, booking)
+	if err != nil {
+		return nil, err
+	}
+	first, last := run[0], run[len(run)-1]
+
+	// Экземпляр закрепляется при выдаче, если его не назначили при подтверждении
+	if units, err := s.repo.GetItemUnits(ctx, booking.ItemID); err != nil {
+		s.logger.Error().Err(err).Int64("booking_id", bookingID).Msg("failed to get item units")
+	} else if err := s.assignRunUnit(ctx, run, units, managerID); err != nil {
+		s.logger.Warn().Err(err).Int64("booking_id", bookingID).Msg("failed to assign unit")
+	}
+
+	handover := &models.Handover{
+		BookingID:      first.ID,
+		UserID:         booking.UserID,
+		UserName:       booking.UserName,
+		ItemID:         booking.ItemID,
+		ItemName:       booking.ItemName,
+		UnitID:         first.UnitID,
+		PlannedStart:   first.Date,
+		PlannedEnd:     last.Date,
+		CheckedOutAt:   time.Now(),
+		CheckedOutBy:   managerID,
+		CheckOutNote:   note,
+		CheckOutPhotos: photos,
+	}
+	if err := s.repo.CreateHandover(ctx, handover); err != nil {
+		return nil, err
+	}
+
+	recordAudit(ctx, s.repo, s.logger, models.AuditEntityBooking, bookingID, models.AuditActionCheckOut, managerID, nil,
+		handoverAuditSnapshot{
+			PlannedStart: handover.PlannedStart.Format("2006-01-02"),
+			PlannedEnd:   handover.PlannedEnd.Format("2006-01-02"),
+			Note:         note,
+			Photos:       len(photos),
+			BookingIDs:   bookingIDs(run),
+		})
+
+	return handover, nil
+}
+
+// CheckInBooking фиксирует возврат аппарата и завершает заявки выданного периода.
+// Возврат сохраняется, даже если какую-то из заявок завершить не удалось: ошибка только логируется.
+func (s *BookingService) CheckInBooking(
+	ctx context.Context,
+	bookingID, managerID int64,
+	note string,
+	photos []string,
+) (*models.Handover, error) {
+	booking, err := s.repo.GetBooking(ctx, bookingID)
+	if err != nil {
+		return nil, err
+	}
+
+	handover, err := s.repo.FindHandover(ctx, booking.UserID, booking.ItemID, booking.Date)
+	if err != nil {
+		return nil, err
+	}
+	if handover == nil || handover.IsReturned() {
+		return nil, models.ErrNotCheckedOut
+	}
+
+	handover.CheckedInAt = sql.NullTime{Time: time.Now(), Valid: true}
+	handover.CheckedInBy = managerID
+	handover.CheckInNote = note
+	handover.CheckInPhotos = photos
+	if err := s.repo.CheckInHandover(ctx, handover); err != nil {
+		return nil, err
+	}
+
+	bookings, err := s.repo.GetAllUserBookings(ctx, booking.UserID)
+	if err != nil {
+		s.logger.Error().Err(err).Int64("handover_id", handover.ID).Msg("failed to get bookings of returned handover")
+		bookings = nil
+	}
+	var completed []*models.Booking
+	for _, b := range bookings {
+		if b.ItemID != handover.ItemID || !isHandoverStatus(b.Status) ||
+			b.Date.Before(handover.PlannedStart) || b.Date.After(handover.PlannedEnd) {
+			continue
+		}
+		if err := s.CompleteBooking(ctx, b.ID, b.Version, managerID); err != nil {
+			s.logger.Error().Err(err).Int64("booking_id", b.ID).Msg("failed to complete returned booking")
+			continue
+		}
+		completed = append(completed, b)
+	}
+
+	recordAudit(ctx, s.repo, s.logger, models.AuditEntityBooking, bookingID, models.AuditActionCheckIn, managerID, nil,
+		handoverAuditSnapshot{
+			PlannedStart: handover.PlannedStart.Format("2006-01-02"),
+			PlannedEnd:   handover.PlannedEnd.Format("2006-01-02"),
+			Note:         note,
+			Photos:       len(photos),
+			BookingIDs:   bookingIDs(completed),
+		})
+
+	return handover, nil
+}
+
+// GetBookingHandover возвращает выдачу, к которой относится заявка, или nil, если аппарат не выдавался
+func (s *BookingService) GetBookingHandover(ctx context.Context, booking *models.Booking) (*models.Handover, error) {
+	return s.repo.FindHandover(ctx, booking.UserID, booking.ItemID, booking.Date)
+}
+
+// GetOpenHandovers возвращает выданные и еще не возвращенные аппараты
+func (s *BookingService) GetOpenHandovers(ctx context.Context) ([]*models.Handover, error) {
+	return s.repo.GetOpenHandovers(ctx)
+}
+
+// GetHandoversByPeriod возвращает выдачи, плановый период которых пересекается с заданным
+func (s *BookingService) GetHandoversByPeriod(ctx context.Context, start, end time.Time) ([]*models.Handover, error) {
+	return s.repo.GetHandoversByPeriod(ctx, start, end)
+}
+
+// MarkHandoverOverdueNotified запоминает время последнего оповещения о просроченном возврате
+func (s *BookingService) MarkHandoverOverdueNotified(ctx context.Context, id int64, at time.Time) error {
+	return s.repo.SetHandoverOverdueNotified(ctx, id, at)
+}
+
+// bookingRun возвращает подряд идущие дни брони клиента по тому же аппарату, в которые входит заявка
+func (s *BookingService) bookingRun(ctx context.Context, booking *models.Booking) ([]*models.Booking, error) {
+	return s.consecutiveBookings(ctx, booking, func(b *models.Booking) bool { return isHandoverStatus(b.Status) })
+}
+
+// consecutiveBookings собирает вокруг заявки подряд идущие дни того же клиента и аппарата, прошедшие фильтр
+func (s *BookingService) consecutiveBookings(
+	ctx context.Context,
+	booking *models.Booking,
+	include func(*models.Booking) bool,
+) ([]*models.Booking, error) {
+	bookings, err := s.repo.GetAllUserBookings(ctx, booking.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	byDay := make(map[string]*models.Booking)
+	for _, b := range bookings {
+		if b.ItemID == booking.ItemID && b.ID != booking.ID && include(b) {
+			byDay[b.Date.Format("2006-01-02")] = b
+		}
+	}
+
+	run := []*models.Booking{booking}
+	for day := booking.Date.AddDate(0, 0, -1); byDay[day.Format("2006-01-02")] != nil; day = day.AddDate(0, 0, -1) {
+		run = append(run, byDay[day.Format("2006-01-02")])
+	}
+	for day := booking.Date.AddDate(0, 0, 1); byDay[day.Format("2006-01-02")] != nil; day = day.AddDate(0, 0, 1) {
+		run = append(run, byDay[day.Format("2006-01-02")])
+	}
+	sort.Slice(run, func(i, j int) bool { return run[i].Date.Before(run[j].Date) })
+	return run, nil
+}
+
+func isHandoverStatus(status string) bool {
+	return status == models.StatusConfirmed || status == models.StatusChanged
+}
+
+func bookingIDs(bookings []*models.Booking) []int64 {
+	ids := make([]int64, 0, len(bookings))
+	for _, b := range bookings {
+		ids = append(ids, b.ID)
+	}
+	return ids
+}
@@ -0,0 +1,50 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"bronivik/internal/models"
+)
+
+// ImportItems сохраняет позиции из файла импорта одной транзакцией; dryRun только проверяет их
+func (s *ItemService) ImportItems(ctx context.Context, items []*models.Item, dryRun bool) error {
+	if err := s.repo.ImportItems(ctx, items, dryRun); err != nil || dryRun {
+		return err
+	}
+	for _, item := range items {
+		recordAudit(ctx, s.repo, s.logger, models.AuditEntityItem, item.ID, models.AuditActionCreate, 0, nil, itemSnapshot(item))
+	}
+	return nil
+}
+
+// ImportUsers сохраняет пользователей из файла импорта; уже зарегистрированные не меняются
+func (s *UserService) ImportUsers(ctx context.Context, users []*models.User, dryRun bool) error {
+	return s.repo.ImportUsers(ctx, users, dryRun)
+}
+
+// ImportBookings сохраняет заявки из файла импорта одной транзакцией с проверкой вместимости.
+// Для не поместившихся заявок возвращается ошибка по их индексу. Импорт не рассылает
+// уведомлений: заявки попадают в журнал и ставятся в очередь синхронизации с таблицей.
+func (s *BookingService) ImportBookings(ctx context.Context, bookings []*models.Booking, dryRun bool) ([]error, error) {
+	rejected, err := s.repo.ImportBookings(ctx, bookings, dryRun)
+	if err != nil || dryRun {
+		return rejected, err
+	}
+
+	imported := 0
+	for i, booking := range bookings {
+		if rejected[i] != nil {
+			continue
+		}
+		imported++
+		recordAudit(ctx, s.repo, s.logger, models.AuditEntityBooking, booking.ID, models.AuditActionCreate, 0, nil, bookingSnapshot(booking))
+		s.enqueueSync(ctx, booking, "upsert")
+	}
+	if imported > 0 && s.sheetsWorker != nil {
+		if err := s.sheetsWorker.EnqueueSyncSchedule(ctx, time.Time{}, time.Time{}); err != nil {
+			s.logger.Error().Err(err).Msg("failed to enqueue sync schedule")
+		}
+	}
+	return rejected, nil
+}
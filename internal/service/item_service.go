@@ -34,19 +34,58 @@ func (s *ItemService) GetItemByName(ctx context.Context, name string) (*models.I
 }
 
 func (s *ItemService) CreateItem(ctx context.Context, item *models.Item) error {
-	return s.repo.CreateItem(ctx, item)
+	if err := s.repo.CreateItem(ctx, item); err != nil {
+		return err
+	}
+	recordAudit(ctx, s.repo, s.logger, models.AuditEntityItem, item.ID, models.AuditActionCreate, 0, nil, itemSnapshot(item))
+	return nil
 }
 
 func (s *ItemService) UpdateItem(ctx context.Context, item *models.Item) error {
-	return s.repo.UpdateItem(ctx, item)
+	before, _ := s.repo.GetItemByID(ctx, item.ID)
+	if err := s.repo.UpdateItem(ctx, item); err != nil {
+		return err
+	}
+	recordAudit(ctx, s.repo, s.logger, models.AuditEntityItem, item.ID, models.AuditActionUpdate, 0,
+		itemSnapshot(before), itemSnapshot(item))
+	return nil
 }
 
 func (s *ItemService) DeactivateItem(ctx context.Context, id int64) error {
-	return s.repo.DeactivateItem(ctx, id)
+	before, _ := s.repo.GetItemByID(ctx, id)
+	if err := s.repo.DeactivateItem(ctx, id); err != nil {
+		return err
+	}
+	var after *models.Item
+	if before != nil {
+		deactivated := *before
+		deactivated.IsActive = false
+		after = &deactivated
+	}
+	recordAudit(ctx, s.repo, s.logger, models.AuditEntityItem, id, models.AuditActionDeactivate, 0,
+		itemSnapshot(before), itemSnapshot(after))
+	return nil
 }
 
 func (s *ItemService) ReorderItem(ctx context.Context, id, newOrder int64) error {
-	return s.repo.ReorderItem(ctx, id, newOrder)
+	before, _ := s.repo.GetItemByID(ctx, id)
+	if err := s.repo.ReorderItem(ctx, id, newOrder); err != nil {
+		return err
+	}
+	var after *models.Item
+	if before != nil {
+		reordered := *before
+		reordered.SortOrder = newOrder
+		after = &reordered
+	}
+	recordAudit(ctx, s.repo, s.logger, models.AuditEntityItem, id, models.AuditActionReorder, 0,
+		itemSnapshot(before), itemSnapshot(after))
+	return nil
+}
+
+// GetItemHistory возвращает журнал изменений аппарата
+func (s *ItemService) GetItemHistory(ctx context.Context, id int64) ([]*models.AuditEntry, error) {
+	return s.repo.GetAuditEntries(ctx, models.AuditEntityItem, id)
 }
 
 func (s *ItemService) Refresh(ctx context.Context) error {
@@ -2,13 +2,16 @@ package service
 
 import (
 	"context"
+	"strings"
 	"testing"
+	"time"
 
 	"bronivik/internal/models"
 
 	"github.com/rs/zerolog"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 )
 
 func TestItemService_GetActiveItems(t *testing.T) {
@@ -85,6 +88,9 @@ func TestItemService_CreateItem(t *testing.T) {
 	item := &models.Item{Name: "New Item"}
 
 	mockRepo.On("CreateItem", mock.Anything, item).Return(nil)
+	mockRepo.On("CreateAuditEntry", mock.Anything, mock.MatchedBy(func(e *models.AuditEntry) bool {
+		return e.EntityType == models.AuditEntityItem && e.Action == models.AuditActionCreate && e.Before == ""
+	})).Return(nil)
 
 	s := NewItemService(mockRepo, &logger)
 
@@ -98,7 +104,12 @@ func TestItemService_UpdateItem(t *testing.T) {
 	logger := zerolog.Nop()
 	item := &models.Item{ID: 1, Name: "Updated Item"}
 
+	mockRepo.On("GetItemByID", mock.Anything, int64(1)).Return(&models.Item{ID: 1, Name: "Old Item"}, nil)
 	mockRepo.On("UpdateItem", mock.Anything, item).Return(nil)
+	mockRepo.On("CreateAuditEntry", mock.Anything, mock.MatchedBy(func(e *models.AuditEntry) bool {
+		return e.Action == models.AuditActionUpdate &&
+			strings.Contains(e.Before, "Old Item") && strings.Contains(e.After, "Updated Item")
+	})).Return(nil)
 
 	s := NewItemService(mockRepo, &logger)
 
@@ -111,7 +122,11 @@ func TestItemService_DeactivateItem(t *testing.T) {
 	mockRepo := new(MockRepository)
 	logger := zerolog.Nop()
 
+	mockRepo.On("GetItemByID", mock.Anything, int64(1)).Return(&models.Item{ID: 1, IsActive: true}, nil)
 	mockRepo.On("DeactivateItem", mock.Anything, int64(1)).Return(nil)
+	mockRepo.On("CreateAuditEntry", mock.Anything, mock.MatchedBy(func(e *models.AuditEntry) bool {
+		return e.Action == models.AuditActionDeactivate && strings.Contains(e.After, `"is_active":false`)
+	})).Return(nil)
 
 	s := NewItemService(mockRepo, &logger)
 
@@ -124,7 +139,11 @@ func TestItemService_ReorderItem(t *testing.T) {
 	mockRepo := new(MockRepository)
 	logger := zerolog.Nop()
 
+	mockRepo.On("GetItemByID", mock.Anything, int64(1)).Return(&models.Item{ID: 1, SortOrder: 2}, nil)
 	mockRepo.On("ReorderItem", mock.Anything, int64(1), int64(5)).Return(nil)
+	mockRepo.On("CreateAuditEntry", mock.Anything, mock.MatchedBy(func(e *models.AuditEntry) bool {
+		return e.Action == models.AuditActionReorder && strings.Contains(e.After, `"sort_order":5`)
+	})).Return(nil)
 
 	s := NewItemService(mockRepo, &logger)
 
@@ -132,3 +151,149 @@ func TestItemService_ReorderItem(t *testing.T) {
 	assert.NoError(t, err)
 	mockRepo.AssertExpectations(t)
 }
+
+func TestItemService_AddMaintenance(t *testing.T) {
+	mockRepo := new(MockRepository)
+	logger := zerolog.Nop()
+	ctx := context.Background()
+	day := func(d int) time.Time { return time.Date(2030, 5, d, 0, 0, 0, 0, time.UTC) }
+	item := &models.Item{ID: 1, Name: "Camera", TotalQuantity: 2}
+
+	s := NewItemService(mockRepo, &logger)
+
+	_, err := s.AddMaintenance(ctx, &models.MaintenanceWindow{ItemID: 1, Units: 1, StartDate: day(3), EndDate: day(2)})
+	assert.ErrorIs(t, err, models.ErrInvalidMaintenancePeriod)
+
+	mockRepo.On("GetItemByID", mock.Anything, int64(1)).Return(item, nil)
+	_, err = s.AddMaintenance(ctx, &models.MaintenanceWindow{ItemID: 1, Units: 3, StartDate: day(2), EndDate: day(3)})
+	assert.ErrorIs(t, err, models.ErrInvalidMaintenanceUnits)
+
+	window := &models.MaintenanceWindow{ItemID: 1, Units: 1, StartDate: day(2), EndDate: day(3), CreatedBy: 7}
+	mockRepo.On("CreateMaintenance", mock.Anything, window).Return(nil)
+	mockRepo.On("CreateAuditEntry", mock.Anything, mock.MatchedBy(func(e *models.AuditEntry) bool {
+		return e.Action == models.AuditActionMaintenance && e.ActorID == 7 && strings.Contains(e.After, `"units":1`)
+	})).Return(nil)
+	mockRepo.On("GetMaintenanceWindows", mock.Anything, int64(1), day(2), day(3)).Return([]*models.MaintenanceWindow{window}, nil)
+	mockRepo.On("GetBookingsByDateRange", mock.Anything, day(2), day(3)).Return([]*models.Booking{
+		{ID: 10, ItemID: 1, Date: day(2), Status: models.StatusConfirmed},
+		{ID: 11, ItemID: 1, Date: day(2), Status: models.StatusPending},
+		{ID: 12, ItemID: 1, Date: day(3), Status: models.StatusConfirmed},
+		{ID: 13, ItemID: 1, Date: day(3), Status: models.StatusCanceled},
+		{ID: 14, ItemID: 2, Date: day(3), Status: models.StatusConfirmed},
+	}, nil)
+
+	overflow, err := s.AddMaintenance(ctx, window)
+	assert.NoError(t, err)
+	if assert.Len(t, overflow, 1) {
+		assert.Equal(t, day(2), overflow[0].Date)
+		assert.Equal(t, int64(1), overflow[0].Capacity)
+		assert.Len(t, overflow[0].Bookings, 2)
+	}
+	mockRepo.AssertExpectations(t)
+}
+
+func TestItemService_EndMaintenance(t *testing.T) {
+	mockRepo := new(MockRepository)
+	logger := zerolog.Nop()
+	window := &models.MaintenanceWindow{ID: 5, ItemID: 1, Units: 1}
+
+	mockRepo.On("GetMaintenance", mock.Anything, int64(5)).Return(window, nil)
+	mockRepo.On("GetMaintenance", mock.Anything, int64(6)).Return(nil, models.ErrMaintenanceNotFound)
+	mockRepo.On("DeleteMaintenance", mock.Anything, int64(5)).Return(nil)
+	mockRepo.On("CreateAuditEntry", mock.Anything, mock.MatchedBy(func(e *models.AuditEntry) bool {
+		return e.Action == models.AuditActionMaintenanceEnd && e.EntityID == 1 && e.After == ""
+	})).Return(nil)
+
+	s := NewItemService(mockRepo, &logger)
+
+	ended, err := s.EndMaintenance(context.Background(), 5, 7)
+	assert.NoError(t, err)
+	assert.Equal(t, window, ended)
+
+	_, err = s.EndMaintenance(context.Background(), 6, 7)
+	assert.ErrorIs(t, err, models.ErrMaintenanceNotFound)
+
+	mockRepo.On("GetMaintenance", mock.Anything, int64(8)).Return(&models.MaintenanceWindow{ID: 8, ItemID: 1, Source: "partner"}, nil)
+	_, err = s.EndMaintenance(context.Background(), 8, 7)
+	assert.ErrorIs(t, err, models.ErrExternalHold)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestItemService_SyncExternalHolds(t *testing.T) {
+	mockRepo := new(MockRepository)
+	logger := zerolog.Nop()
+	ctx := context.Background()
+	day := func(d int) time.Time { return time.Date(2030, 5, d, 0, 0, 0, 0, time.UTC) }
+	item := &models.Item{ID: 1, Name: "Camera", TotalQuantity: 1}
+
+	unchanged := &models.MaintenanceWindow{ItemID: 1, Units: 1, StartDate: day(2), EndDate: day(2)}
+	added := &models.MaintenanceWindow{ItemID: 1, Units: 1, StartDate: day(5), EndDate: day(6)}
+	windows := []*models.MaintenanceWindow{unchanged, added}
+
+	mockRepo.On("GetExternalHolds", mock.Anything, "partner").Return([]*models.MaintenanceWindow{
+		{ItemID: 1, Units: 1, StartDate: day(2), EndDate: day(2), Source: "partner"},
+	}, nil)
+	mockRepo.On("ReplaceExternalHolds", mock.Anything, "partner", windows).Return(nil)
+	mockRepo.On("GetItemByID", mock.Anything, int64(1)).Return(item, nil)
+	// Проверяется только новая бронь
+	mockRepo.On("GetMaintenanceWindows", mock.Anything, int64(1), day(5), day(6)).Return(windows, nil)
+	mockRepo.On("GetBookingsByDateRange", mock.Anything, day(5), day(6)).Return([]*models.Booking{
+		{ID: 10, ItemID: 1, Date: day(6), Status: models.StatusConfirmed},
+	}, nil)
+
+	s := NewItemService(mockRepo, &logger)
+
+	overflow, err := s.SyncExternalHolds(ctx, "partner", windows)
+	require.NoError(t, err)
+	require.Len(t, overflow, 1)
+	assert.Equal(t, day(6), overflow[0].Date)
+	assert.Equal(t, int64(0), overflow[0].Capacity)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestItemService_Units(t *testing.T) {
+	mockRepo := new(MockRepository)
+	logger := zerolog.Nop()
+	ctx := context.Background()
+	unit := &models.ItemUnit{ItemID: 1, SerialNumber: "SN-1"}
+
+	mockRepo.On("GetItemByID", mock.Anything, int64(1)).Return(&models.Item{ID: 1, Name: "Camera"}, nil)
+	mockRepo.On("CreateUnit", mock.Anything, unit).
+		Run(func(args mock.Arguments) { args.Get(1).(*models.ItemUnit).ID = 3 }).
+		Return(nil)
+	mockRepo.On("CreateAuditEntry", mock.Anything, mock.MatchedBy(func(e *models.AuditEntry) bool {
+		return e.EntityType == models.AuditEntityUnit && e.EntityID == 3 && e.Action == models.AuditActionCreate
+	})).Return(nil).Once()
+
+	s := NewItemService(mockRepo, &logger)
+
+	assert.ErrorIs(t, s.AddUnit(ctx, &models.ItemUnit{ItemID: 1, SerialNumber: "SN-2", Status: "lost"}, 7),
+		models.ErrInvalidUnitStatus)
+	require.NoError(t, s.AddUnit(ctx, unit, 7))
+	assert.Equal(t, models.UnitStatusActive, unit.Status)
+
+	mockRepo.On("GetUnitBySerial", mock.Anything, "SN-1").Return(unit, nil)
+	mockRepo.On("UpdateUnitStatus", mock.Anything, int64(3), models.UnitStatusRepair, "lens").Return(nil)
+	mockRepo.On("CreateAuditEntry", mock.Anything, mock.MatchedBy(func(e *models.AuditEntry) bool {
+		return e.Action == models.AuditActionStatusChange && strings.Contains(e.Before, `"status":"active"`) &&
+			strings.Contains(e.After, `"status":"repair"`)
+	})).Return(nil).Once()
+
+	_, err := s.SetUnitStatus(ctx, "SN-1", "broken", "", 7)
+	assert.ErrorIs(t, err, models.ErrInvalidUnitStatus)
+	updated, err := s.SetUnitStatus(ctx, "SN-1", models.UnitStatusRepair, "lens", 7)
+	require.NoError(t, err)
+	assert.Equal(t, models.UnitStatusRepair, updated.Status)
+
+	entries := []*models.AuditEntry{{ID: 1}}
+	bookings := []*models.Booking{{ID: 10, UnitID: 3}}
+	mockRepo.On("GetAuditEntries", mock.Anything, models.AuditEntityUnit, int64(3)).Return(entries, nil)
+	mockRepo.On("GetUnitBookings", mock.Anything, int64(3)).Return(bookings, nil)
+
+	history, err := s.GetUnitHistory(ctx, "SN-1")
+	require.NoError(t, err)
+	assert.Equal(t, unit, history.Unit)
+	assert.Equal(t, entries, history.Entries)
+	assert.Equal(t, bookings, history.Bookings)
+	mockRepo.AssertExpectations(t)
+}
@@ -0,0 +1,184 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"bronivik/internal/models"
+)
+
+// maintenanceAuditSnapshot - окно обслуживания для журнала аппарата
+type maintenanceAuditSnapshot struct {
+	ID        int64  `json:"id"`
+	Units     int64  `json:"units"`
+	StartDate string `json:"start_date"`
+	EndDate   string `json:"end_date"`
+	Reason    string `json:"reason,omitempty"`
+}
+
+// AddMaintenance выводит часть аппаратов из работы на период и возвращает дни,
+// в которые уже существующих заявок стало больше, чем оставшихся аппаратов.
+func (s *ItemService) AddMaintenance(
+	ctx context.Context,
+	window *models.MaintenanceWindow,
+) ([]*models.MaintenanceOverflow, error) {
+	if window.EndDate.Before(window.StartDate) {
+		return nil, models.ErrInvalidMaintenancePeriod
+	}
+
+	item, err := s.repo.GetItemByID(ctx, window.ItemID)
+	if err != nil {
+		return nil, err
+	}
+	if window.Units < 1 || window.Units > item.TotalQuantity {
+		return nil, models.ErrInvalidMaintenanceUnits
+	}
+
+	if err := s.repo.CreateMaintenance(ctx, window); err != nil {
+		return nil, err
+	}
+	recordAudit(ctx, s.repo, s.logger, models.AuditEntityItem, item.ID, models.AuditActionMaintenance, window.CreatedBy,
+		nil, maintenanceSnapshot(window))
+
+	overflow, err := s.maintenanceOverflow(ctx, item, window.StartDate, window.EndDate)
+	if err != nil {
+		// Окно уже сохранено, поэтому ошибку проверки пересечений только логируем
+		s.logger.Error().Err(err).Int64("maintenance_id", window.ID).Msg("failed to check bookings overflow")
+		return nil, nil
+	}
+	return overflow, nil
+}
+
+// EndMaintenance досрочно возвращает аппараты окна обслуживания в работу
+func (s *ItemService) EndMaintenance(ctx context.Context, id, actorID int64) (*models.MaintenanceWindow, error) {
+	window, err := s.repo.GetMaintenance(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	// Внешняя бронь вернется при следующем импорте, снимать ее нужно в исходном календаре
+	if window.IsExternalHold() {
+		return nil, models.ErrExternalHold
+	}
+	if err := s.repo.DeleteMaintenance(ctx, id); err != nil {
+		return nil, err
+	}
+	recordAudit(ctx, s.repo, s.logger, models.AuditEntityItem, window.ItemID, models.AuditActionMaintenanceEnd, actorID,
+		maintenanceSnapshot(window), nil)
+	return window, nil
+}
+
+// GetMaintenance возвращает окно обслуживания по ID
+func (s *ItemService) GetMaintenance(ctx context.Context, id int64) (*models.MaintenanceWindow, error) {
+	return s.repo.GetMaintenance(ctx, id)
+}
+
+// GetMaintenanceWindows возвращает окна обслуживания, пересекающиеся с периодом; itemID 0 - по всем аппаратам
+func (s *ItemService) GetMaintenanceWindows(
+	ctx context.Context,
+	itemID int64,
+	start, end time.Time,
+) ([]*models.MaintenanceWindow, error) {
+	return s.repo.GetMaintenanceWindows(ctx, itemID, start, end)
+}
+
+// SyncExternalHolds заменяет брони, импортированные из внешнего календаря source, и возвращает
+// дни, в которые новые брони оставили существующим заявкам меньше аппаратов, чем нужно.
+// Брони, не изменившиеся с прошлого импорта, повторно не проверяются.
+func (s *ItemService) SyncExternalHolds(
+	ctx context.Context,
+	source string,
+	windows []*models.MaintenanceWindow,
+) ([]*models.MaintenanceOverflow, error) {
+	previous, err := s.repo.GetExternalHolds(ctx, source)
+	if err != nil {
+		return nil, err
+	}
+	known := make(map[string]bool, len(previous))
+	for _, w := range previous {
+		known[externalHoldKey(w)] = true
+	}
+
+	if err := s.repo.ReplaceExternalHolds(ctx, source, windows); err != nil {
+		return nil, err
+	}
+	s.logger.Info().Str("source", source).Int("holds", len(windows)).Int("previous", len(previous)).
+		Msg("external calendar holds imported")
+
+	var overflow []*models.MaintenanceOverflow
+	reported := make(map[string]bool)
+	for _, w := range windows {
+		if known[externalHoldKey(w)] {
+			continue
+		}
+		item, err := s.repo.GetItemByID(ctx, w.ItemID)
+		if err != nil {
+			s.logger.Error().Err(err).Str("source", source).Int64("item_id", w.ItemID).Msg("external hold item not found")
+			continue
+		}
+		days, err := s.maintenanceOverflow(ctx, item, w.StartDate, w.EndDate)
+		if err != nil {
+			// Брони уже сохранены, поэтому ошибку проверки пересечений только логируем
+			s.logger.Error().Err(err).Str("source", source).Msg("failed to check bookings overflow")
+			continue
+		}
+		for _, o := range days {
+			key := fmt.Sprintf("%d:%s", item.ID, o.Date.Format("2006-01-02"))
+			if !reported[key] {
+				reported[key] = true
+				overflow = append(overflow, o)
+			}
+		}
+	}
+	return overflow, nil
+}
+
+// externalHoldKey - признак, по которому бронь сравнивается с прошлым импортом
+func externalHoldKey(w *models.MaintenanceWindow) string {
+	return fmt.Sprintf("%d:%d:%s:%s", w.ItemID, w.Units, w.StartDate.Format("2006-01-02"), w.EndDate.Format("2006-01-02"))
+}
+
+// maintenanceOverflow находит дни периода, в которые заявок на аппарат больше, чем аппаратов в работе
+func (s *ItemService) maintenanceOverflow(
+	ctx context.Context,
+	item *models.Item,
+	start, end time.Time,
+) ([]*models.MaintenanceOverflow, error) {
+	windows, err := s.repo.GetMaintenanceWindows(ctx, item.ID, start, end)
+	if err != nil {
+		return nil, err
+	}
+	bookings, err := s.repo.GetBookingsByDateRange(ctx, start, end)
+	if err != nil {
+		return nil, err
+	}
+
+	byDay := make(map[string][]*models.Booking)
+	for _, b := range bookings {
+		if b.ItemID != item.ID || b.Status == models.StatusCanceled || b.Status == "rejected" {
+			continue
+		}
+		day := b.Date.Format("2006-01-02")
+		byDay[day] = append(byDay[day], b)
+	}
+
+	var overflow []*models.MaintenanceOverflow
+	for day := start; !day.After(end); day = day.AddDate(0, 0, 1) {
+		dayBookings := byDay[day.Format("2006-01-02")]
+		capacity := item.EffectiveQuantity(models.MaintenanceUnits(windows, item.ID, day))
+		if int64(len(dayBookings)) > capacity {
+			overflow = append(overflow, &models.MaintenanceOverflow{Date: day, Capacity: capacity, Bookings: dayBookings})
+		}
+	}
+	return overflow, nil
+}
+
+func maintenanceSnapshot(w *models.MaintenanceWindow) interface{} {
+	return maintenanceAuditSnapshot{
+		ID:        w.ID,
+		Units:     w.Units,
+		StartDate: w.StartDate.Format("2006-01-02"),
+		EndDate:   w.EndDate.Format("2006-01-02"),
+		Reason:    w.Reason,
+	}
+}
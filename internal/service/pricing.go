@@ -0,0 +1,107 @@
+package service
+
+import (
+	"context"
+	"slices"
+
+	"bronivik/internal/models"
+)
+
+// GetBookingQuote возвращает стоимость аренды, в которую входит заявка: сумму цен дней и залог
+func (s *BookingService) GetBookingQuote(ctx context.Context, booking *models.Booking) (*models.BookingQuote, error) {
+	run, err := s.rentalRun(ctx, booking)
+	if err != nil {
+		return nil, err
+	}
+	return models.NewBookingQuote(run), nil
+}
+
+// priceRental пересчитывает цены дней аренды после добавления заявки.
+// Длина аренды влияет на скидку, поэтому меняются и ранее созданные дни; залог берется один раз - в первый день.
+// Ошибки только логируются: заявка уже создана, а цену менеджер увидит в счете.
+func (s *BookingService) priceRental(ctx context.Context, booking *models.Booking) {
+	item := s.pricedItem(ctx, booking)
+	if item == nil {
+		return
+	}
+
+	run, err := s.rentalRun(ctx, booking)
+	if err != nil {
+		s.logger.Error().Err(err).Int64("booking_id", booking.ID).Msg("failed to load rental for pricing")
+		return
+	}
+	s.priceRun(ctx, item, run)
+}
+
+// repriceRental пересчитывает аренды, затронутые сменой статуса или аппарата заявки.
+// Если день выпал из аренды, оставшиеся до и после него дни стали отдельными арендами:
+// у них меняется скидка за длину, а залог переходит на новый первый день.
+// Если день вошел в аренду (возврат в работу, новый аппарат), цены пересчитываются с ним.
+func (s *BookingService) repriceRental(ctx context.Context, before, after *models.Booking) {
+	if before == nil || after == nil {
+		return
+	}
+	wasRental, isRental := isRentalStatus(before.Status), isRentalStatus(after.Status)
+	moved := before.ItemID != after.ItemID
+
+	if wasRental && (!isRental || moved) {
+		if item := s.pricedItem(ctx, before); item != nil {
+			run, err := s.rentalRun(ctx, before)
+			if err != nil {
+				s.logger.Error().Err(err).Int64("booking_id", before.ID).Msg("failed to load rental for pricing")
+			} else {
+				i := slices.IndexFunc(run, func(b *models.Booking) bool { return b.ID == before.ID })
+				s.priceRun(ctx, item, run[:i])
+				s.priceRun(ctx, item, run[i+1:])
+			}
+		}
+	}
+	if isRental && (!wasRental || moved) {
+		s.priceRental(ctx, after)
+	}
+}
+
+// pricedItem возвращает аппарат заявки, если у него заданы цены
+func (s *BookingService) pricedItem(ctx context.Context, booking *models.Booking) *models.Item {
+	item, err := s.repo.GetItemByID(ctx, booking.ItemID)
+	if err != nil || item == nil {
+		s.logger.Error().Err(err).Int64("booking_id", booking.ID).Msg("failed to load item for pricing")
+		return nil
+	}
+	if !item.HasPricing() {
+		return nil
+	}
+	return item
+}
+
+// priceRun проставляет цены дням одной аренды, идущим по порядку дат
+func (s *BookingService) priceRun(ctx context.Context, item *models.Item, run []*models.Booking) {
+	for i, b := range run {
+		price := item.DayPrice(b.Date, len(run))
+		var deposit int64
+		if i == 0 {
+			deposit = item.Deposit
+		}
+		if b.Price == price && b.Deposit == deposit {
+			continue
+		}
+		if err := s.repo.SetBookingPrice(ctx, b.ID, price, deposit); err != nil {
+			s.logger.Error().Err(err).Int64("booking_id", b.ID).Msg("failed to set booking price")
+			continue
+		}
+		b.Price, b.Deposit = price, deposit
+	}
+}
+
+// rentalRun возвращает дни одной аренды: подряд идущие неотмененные заявки клиента на аппарат с тем же телефоном.
+// Завершенные дни остаются в аренде, чтобы счет после возврата аппарата покрывал ее целиком.
+// Телефон отделяет клиентов, чьи заявки менеджер создал от своего имени.
+func (s *BookingService) rentalRun(ctx context.Context, booking *models.Booking) ([]*models.Booking, error) {
+	return s.consecutiveBookings(ctx, booking, func(b *models.Booking) bool {
+		return isRentalStatus(b.Status) && b.Phone == booking.Phone
+	})
+}
+
+func isRentalStatus(status string) bool {
+	return status == models.StatusPending || status == models.StatusCompleted || isHandoverStatus(status)
+}
@@ -82,6 +82,11 @@ func (s *TelegramService) GetSelf() tgbotapi.User {
 	return s.bot.GetSelf()
 }
 
+// GetFileDirectURL возвращает ссылку для скачивания файла, присланного боту
+func (s *TelegramService) GetFileDirectURL(fileID string) (string, error) {
+	return s.bot.GetFileDirectURL(fileID)
+}
+
 func (s *TelegramService) StopReceivingUpdates() {
 	s.bot.StopReceivingUpdates()
 }
@@ -34,6 +34,11 @@ func (m *mockTelegramSender) GetSelf() tgbotapi.User {
 	return args.Get(0).(tgbotapi.User)
 }
 
+func (m *mockTelegramSender) GetFileDirectURL(fileID string) (string, error) {
+	args := m.Called(fileID)
+	return args.String(0), args.Error(1)
+}
+
 func (m *mockTelegramSender) StopReceivingUpdates() {
 	m.Called()
 }
@@ -0,0 +1,199 @@
+package service
+
+import (
+	"context"
+
+	"bronivik/internal/models"
+)
+
+// unitAuditSnapshot - данные экземпляра для журнала
+type unitAuditSnapshot struct {
+	ItemID       int64  `json:"item_id"`
+	SerialNumber string `json:"serial_number"`
+	InventoryTag string `json:"inventory_tag,omitempty"`
+	Status       string `json:"status"`
+	Note         string `json:"note,omitempty"`
+}
+
+// unitAssignSnapshot - экземпляр, закрепленный за заявкой
+type unitAssignSnapshot struct {
+	UnitID       int64  `json:"unit_id"`
+	SerialNumber string `json:"serial_number"`
+}
+
+// AddUnit регистрирует экземпляр аппарата с серийным номером
+func (s *ItemService) AddUnit(ctx context.Context, unit *models.ItemUnit, actorID int64) error {
+	if unit.Status == "" {
+		unit.Status = models.UnitStatusActive
+	}
+	if !models.ValidUnitStatus(unit.Status) {
+		return models.ErrInvalidUnitStatus
+	}
+	if _, err := s.repo.GetItemByID(ctx, unit.ItemID); err != nil {
+		return err
+	}
+
+	if err := s.repo.CreateUnit(ctx, unit); err != nil {
+		return err
+	}
+	recordAudit(ctx, s.repo, s.logger, models.AuditEntityUnit, unit.ID, models.AuditActionCreate, actorID,
+		nil, unitSnapshot(unit))
+	return nil
+}
+
+// SetUnitStatus меняет статус экземпляра по серийному номеру, например отправляет его в ремонт
+func (s *ItemService) SetUnitStatus(
+	ctx context.Context,
+	serial, status, note string,
+	actorID int64,
+) (*models.ItemUnit, error) {
+	if !models.ValidUnitStatus(status) {
+		return nil, models.ErrInvalidUnitStatus
+	}
+	unit, err := s.repo.GetUnitBySerial(ctx, serial)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repo.UpdateUnitStatus(ctx, unit.ID, status, note); err != nil {
+		return nil, err
+	}
+
+	before := unitSnapshot(unit)
+	unit.Status = status
+	unit.Note = note
+	recordAudit(ctx, s.repo, s.logger, models.AuditEntityUnit, unit.ID, models.AuditActionStatusChange, actorID,
+		before, unitSnapshot(unit))
+	return unit, nil
+}
+
+// GetUnit возвращает экземпляр по ID
+func (s *ItemService) GetUnit(ctx context.Context, id int64) (*models.ItemUnit, error) {
+	return s.repo.GetUnit(ctx, id)
+}
+
+// GetUnitBySerial возвращает экземпляр по серийному номеру
+func (s *ItemService) GetUnitBySerial(ctx context.Context, serial string) (*models.ItemUnit, error) {
+	return s.repo.GetUnitBySerial(ctx, serial)
+}
+
+// GetItemUnits возвращает экземпляры аппарата
+func (s *ItemService) GetItemUnits(ctx context.Context, itemID int64) ([]*models.ItemUnit, error) {
+	return s.repo.GetItemUnits(ctx, itemID)
+}
+
+// GetUnitHistory возвращает изменения статуса экземпляра и заявки, за которыми он был закреплен
+func (s *ItemService) GetUnitHistory(ctx context.Context, serial string) (*models.UnitHistory, error) {
+	unit, err := s.repo.GetUnitBySerial(ctx, serial)
+	if err != nil {
+		return nil, err
+	}
+	entries, err := s.repo.GetAuditEntries(ctx, models.AuditEntityUnit, unit.ID)
+	if err != nil {
+		return nil, err
+	}
+	bookings, err := s.repo.GetUnitBookings(ctx, unit.ID)
+	if err != nil {
+		return nil, err
+	}
+	return &models.UnitHistory{Unit: unit, Entries: entries, Bookings: bookings}, nil
+}
+
+// assignUnit закрепляет за подтвержденной заявкой конкретный экземпляр аппарата.
+// Аппараты без экземпляров не затрагиваются, нехватка свободных экземпляров только логируется.
+func (s *BookingService) assignUnit(ctx context.Context, booking *models.Booking, managerID int64) {
+	if booking.UnitID != 0 || !isHandoverStatus(booking.Status) {
+		return
+	}
+	units, err := s.repo.GetItemUnits(ctx, booking.ItemID)
+	if err != nil || len(units) == 0 {
+		if err != nil {
+			s.logger.Error().Err(err).Int64("booking_id", booking.ID).Msg("failed to get item units")
+		}
+		return
+	}
+	run, err := s.bookingRun(ctx, booking)
+	if err == nil {
+		err = s.assignRunUnit(ctx, run, units, managerID)
+	}
+	if err != nil {
+		s.logger.Warn().Err(err).Int64("booking_id", booking.ID).Msg("failed to assign unit")
+	}
+}
+
+// assignRunUnit закрепляет один экземпляр за всеми днями брони, у которых его еще нет.
+// Предпочтение отдается экземпляру, уже закрепленному за другими днями той же брони;
+// экземпляры в ремонте и занятые в эти дни другими заявками пропускаются.
+func (s *BookingService) assignRunUnit(
+	ctx context.Context,
+	run []*models.Booking,
+	units []*models.ItemUnit,
+	managerID int64,
+) error {
+	var pending []*models.Booking
+	var preferred []int64
+	for _, b := range run {
+		if b.UnitID == 0 {
+			pending = append(pending, b)
+		} else {
+			preferred = append(preferred, b.UnitID)
+		}
+	}
+	if len(pending) == 0 || len(units) == 0 {
+		return nil
+	}
+
+	busy := make(map[int64]bool)
+	for _, b := range pending {
+		ids, err := s.repo.GetBusyUnitIDs(ctx, b.ItemID, b.Date)
+		if err != nil {
+			return err
+		}
+		for _, id := range ids {
+			busy[id] = true
+		}
+	}
+
+	unit := pickUnit(units, preferred, busy)
+	if unit == nil {
+		return models.ErrNoFreeUnit
+	}
+
+	for _, b := range pending {
+		if err := s.repo.SetBookingUnit(ctx, b.ID, unit.ID); err != nil {
+			return err
+		}
+		b.UnitID = unit.ID
+		recordAudit(ctx, s.repo, s.logger, models.AuditEntityBooking, b.ID, models.AuditActionUnitAssign, managerID,
+			nil, unitAssignSnapshot{UnitID: unit.ID, SerialNumber: unit.SerialNumber})
+	}
+	return nil
+}
+
+// pickUnit выбирает рабочий свободный экземпляр, начиная с предпочтительных
+func pickUnit(units []*models.ItemUnit, preferred []int64, busy map[int64]bool) *models.ItemUnit {
+	byID := make(map[int64]*models.ItemUnit, len(units))
+	for _, u := range units {
+		byID[u.ID] = u
+	}
+	for _, id := range preferred {
+		if u := byID[id]; u != nil && u.IsAssignable() && !busy[id] {
+			return u
+		}
+	}
+	for _, u := range units {
+		if u.IsAssignable() && !busy[u.ID] {
+			return u
+		}
+	}
+	return nil
+}
+
+func unitSnapshot(u *models.ItemUnit) interface{} {
+	return unitAuditSnapshot{
+		ItemID:       u.ItemID,
+		SerialNumber: u.SerialNumber,
+		InventoryTag: u.InventoryTag,
+		Status:       u.Status,
+		Note:         u.Note,
+	}
+}
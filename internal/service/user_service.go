@@ -2,6 +2,11 @@ package service
 
 import (
 	"context"
+	"database/sql"
+	"errors"
+	"sort"
+	"sync"
+	"time"
 
 	"bronivik/internal/config"
 	"bronivik/internal/domain"
@@ -16,6 +21,12 @@ type UserService struct {
 	logger       *zerolog.Logger
 	managersMap  map[int64]bool
 	blacklistMap map[int64]bool
+
+	rolesMu sync.RWMutex
+	roles   map[int64]*models.UserRole
+
+	blacklistMu sync.RWMutex
+	blocked     map[int64]*models.User
 }
 
 func NewUserService(repo domain.Repository, config *config.Config, logger *zerolog.Logger) *UserService {
@@ -35,20 +46,336 @@ func NewUserService(repo domain.Repository, config *config.Config, logger *zerol
 		logger:       logger,
 		managersMap:  managersMap,
 		blacklistMap: blacklistMap,
+		roles:        make(map[int64]*models.UserRole),
+		blocked:      make(map[int64]*models.User),
+	}
+}
+
+// LoadRoles загружает роли из БД в память. Менеджеры из конфига всегда считаются администраторами.
+func (s *UserService) LoadRoles(ctx context.Context) error {
+	roles, err := s.repo.GetUserRoles(ctx)
+	if err != nil {
+		return err
+	}
+
+	m := make(map[int64]*models.UserRole, len(roles))
+	for _, r := range roles {
+		m[r.TelegramID] = r
+	}
+
+	s.rolesMu.Lock()
+	s.roles = m
+	s.rolesMu.Unlock()
+	return nil
+}
+
+// GetRole возвращает роль пользователя или пустую строку, если роли нет
+func (s *UserService) GetRole(userID int64) string {
+	if s.managersMap[userID] {
+		return models.RoleAdmin
+	}
+	s.rolesMu.RLock()
+	defer s.rolesMu.RUnlock()
+	if r, ok := s.roles[userID]; ok {
+		return r.Role
+	}
+	return ""
+}
+
+func (s *UserService) HasPermission(userID int64, perm string) bool {
+	role := s.GetRole(userID)
+	return role != "" && models.RoleHasPermission(role, perm)
+}
+
+// CanManageItem проверяет право управлять заявками на конкретный аппарат с учетом области роли
+func (s *UserService) CanManageItem(userID, itemID int64) bool {
+	if !s.HasPermission(userID, models.PermManageBookings) {
+		return false
+	}
+	if s.managersMap[userID] {
+		return true
+	}
+	s.rolesMu.RLock()
+	defer s.rolesMu.RUnlock()
+	r, ok := s.roles[userID]
+	return ok && r.CoversItem(itemID)
+}
+
+// ItemScope возвращает аппараты, которыми ограничена работа пользователя с заявками, как в
+// CanManageItem. scoped=false — ограничений нет; у пользователя без права на заявки область пуста.
+func (s *UserService) ItemScope(userID int64) (itemIDs []int64, scoped bool) {
+	if !s.HasPermission(userID, models.PermManageBookings) {
+		return nil, true
+	}
+	if s.managersMap[userID] {
+		return nil, false
+	}
+	s.rolesMu.RLock()
+	defer s.rolesMu.RUnlock()
+	r, ok := s.roles[userID]
+	if !ok {
+		return nil, true
+	}
+	if r.Role == models.RoleAdmin || len(r.ItemIDs) == 0 {
+		return nil, false
+	}
+	return append([]int64(nil), r.ItemIDs...), true
+}
+
+// GetStaffForItem возвращает ID сотрудников, у которых есть право perm на указанный аппарат
+func (s *UserService) GetStaffForItem(itemID int64, perm string) []int64 {
+	seen := make(map[int64]bool, len(s.config.Managers))
+	result := make([]int64, 0, len(s.config.Managers))
+	for _, id := range s.config.Managers {
+		if !seen[id] {
+			seen[id] = true
+			result = append(result, id)
+		}
+	}
+
+	s.rolesMu.RLock()
+	scoped := make([]int64, 0, len(s.roles))
+	for id, r := range s.roles {
+		if !seen[id] && models.RoleHasPermission(r.Role, perm) && r.CoversItem(itemID) {
+			scoped = append(scoped, id)
+		}
+	}
+	s.rolesMu.RUnlock()
+
+	sort.Slice(scoped, func(i, j int) bool { return scoped[i] < scoped[j] })
+	return append(result, scoped...)
+}
+
+func (s *UserService) ListUserRoles(ctx context.Context) ([]*models.UserRole, error) {
+	return s.repo.GetUserRoles(ctx)
+}
+
+// SetUserRole назначает роль пользователю и обновляет кэш ролей
+func (s *UserService) SetUserRole(ctx context.Context, role *models.UserRole) error {
+	if !models.IsValidRole(role.Role) {
+		return models.ErrInvalidRole
+	}
+	if s.managersMap[role.TelegramID] {
+		return models.ErrRoleManagedByConfig
+	}
+
+	s.rolesMu.RLock()
+	before := s.roles[role.TelegramID]
+	s.rolesMu.RUnlock()
+
+	if err := s.repo.SetUserRole(ctx, role); err != nil {
+		return err
+	}
+
+	stored := *role
+	stored.ItemIDs = append([]int64(nil), role.ItemIDs...)
+	s.rolesMu.Lock()
+	s.roles[role.TelegramID] = &stored
+	s.rolesMu.Unlock()
+
+	recordAudit(ctx, s.repo, s.logger, models.AuditEntityRole, role.TelegramID, models.AuditActionUpdate, role.GrantedBy,
+		roleSnapshot(before), roleSnapshot(&stored))
+	return nil
+}
+
+// RemoveUserRole снимает роль с пользователя
+func (s *UserService) RemoveUserRole(ctx context.Context, telegramID int64) error {
+	if s.managersMap[telegramID] {
+		return models.ErrRoleManagedByConfig
 	}
+
+	s.rolesMu.RLock()
+	before := s.roles[telegramID]
+	s.rolesMu.RUnlock()
+
+	if err := s.repo.DeleteUserRole(ctx, telegramID); err != nil {
+		return err
+	}
+
+	s.rolesMu.Lock()
+	delete(s.roles, telegramID)
+	s.rolesMu.Unlock()
+
+	recordAudit(ctx, s.repo, s.logger, models.AuditEntityRole, telegramID, models.AuditActionDelete, 0,
+		roleSnapshot(before), nil)
+	return nil
 }
 
+// IsManager сообщает, может ли пользователь работать с заявками
 func (s *UserService) IsManager(userID int64) bool {
-	return s.managersMap[userID]
+	return s.HasPermission(userID, models.PermManageBookings)
 }
 
+// IsBlacklisted учитывает черный список из конфига и действующие блокировки из БД
 func (s *UserService) IsBlacklisted(userID int64) bool {
-	return s.blacklistMap[userID]
+	_, ok := s.GetBlacklistInfo(userID)
+	return ok
+}
+
+// GetBlacklistInfo возвращает сведения о действующей блокировке пользователя
+func (s *UserService) GetBlacklistInfo(userID int64) (*models.User, bool) {
+	if s.blacklistMap[userID] {
+		return &models.User{TelegramID: userID, IsBlacklisted: true}, true
+	}
+
+	s.blacklistMu.RLock()
+	defer s.blacklistMu.RUnlock()
+	u, ok := s.blocked[userID]
+	if !ok || !u.IsBlockedAt(time.Now()) {
+		return nil, false
+	}
+	info := *u
+	return &info, true
+}
+
+// LoadBlacklist загружает блокировки из БД в память
+func (s *UserService) LoadBlacklist(ctx context.Context) error {
+	users, err := s.repo.GetBlacklistedUsers(ctx)
+	if err != nil {
+		return err
+	}
+
+	m := make(map[int64]*models.User, len(users))
+	for _, u := range users {
+		m[u.TelegramID] = u
+	}
+
+	s.blacklistMu.Lock()
+	s.blocked = m
+	s.blacklistMu.Unlock()
+	return nil
+}
+
+// ListBlacklistedUsers возвращает пользователей с действующей блокировкой из БД
+func (s *UserService) ListBlacklistedUsers(ctx context.Context) ([]*models.User, error) {
+	users, err := s.repo.GetBlacklistedUsers(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	active := make([]*models.User, 0, len(users))
+	for _, u := range users {
+		if u.IsBlockedAt(now) {
+			active = append(active, u)
+		}
+	}
+	return active, nil
+}
+
+// BlockUser блокирует пользователя. Нулевое until означает бессрочную блокировку.
+func (s *UserService) BlockUser(ctx context.Context, telegramID int64, reason string, until time.Time, blockedBy int64) error {
+	if s.GetRole(telegramID) != "" {
+		return models.ErrCannotBlockStaff
+	}
+
+	s.blacklistMu.RLock()
+	before := s.blocked[telegramID]
+	s.blacklistMu.RUnlock()
+
+	if err := s.repo.BlacklistUser(ctx, telegramID, reason, until, blockedBy); err != nil {
+		return err
+	}
+
+	blocked := &models.User{
+		TelegramID:      telegramID,
+		IsBlacklisted:   true,
+		BlacklistReason: reason,
+		BlacklistedBy:   blockedBy,
+	}
+	if !until.IsZero() {
+		blocked.BlacklistedUntil = sql.NullTime{Time: until, Valid: true}
+	}
+
+	s.blacklistMu.Lock()
+	s.blocked[telegramID] = blocked
+	s.blacklistMu.Unlock()
+
+	recordAudit(ctx, s.repo, s.logger, models.AuditEntityUser, telegramID, models.AuditActionBlock, blockedBy,
+		blacklistSnapshot(before), blacklistSnapshot(blocked))
+	return nil
+}
+
+// UnblockUser снимает блокировку. Пользователей из черного списка конфига разблокировать нельзя.
+func (s *UserService) UnblockUser(ctx context.Context, telegramID int64) error {
+	if s.blacklistMap[telegramID] {
+		return models.ErrBlacklistManagedByConfig
+	}
+
+	s.blacklistMu.RLock()
+	before := s.blocked[telegramID]
+	s.blacklistMu.RUnlock()
+
+	if err := s.repo.UnblacklistUser(ctx, telegramID); err != nil {
+		return err
+	}
+
+	s.blacklistMu.Lock()
+	delete(s.blocked, telegramID)
+	s.blacklistMu.Unlock()
+
+	recordAudit(ctx, s.repo, s.logger, models.AuditEntityUser, telegramID, models.AuditActionUnblock, 0,
+		blacklistSnapshot(before), blacklistSnapshot(nil))
+	return nil
 }
 
+// HasConsent сообщает, дал ли пользователь согласие на обработку персональных данных
+func (s *UserService) HasConsent(ctx context.Context, telegramID int64) bool {
+	user, err := s.repo.GetUserByTelegramID(ctx, telegramID)
+	if err != nil {
+		return false
+	}
+	return user.ConsentGiven && !user.ConsentRevoked
+}
+
+// GiveConsent сохраняет согласие пользователя на обработку персональных данных
+func (s *UserService) GiveConsent(ctx context.Context, telegramID int64) error {
+	return s.repo.SetUserConsent(ctx, telegramID)
+}
+
+// ExportPersonalData собирает все данные, которые хранятся о пользователе
+func (s *UserService) ExportPersonalData(ctx context.Context, telegramID int64) (*models.PersonalDataExport, error) {
+	user, err := s.repo.GetUserByTelegramID(ctx, telegramID)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return nil, err
+	}
+
+	bookings, err := s.repo.GetAllUserBookings(ctx, telegramID)
+	if err != nil {
+		return nil, err
+	}
+	handovers, err := s.repo.GetUserHandovers(ctx, telegramID)
+	if err != nil {
+		return nil, err
+	}
+	return models.NewPersonalDataExport(telegramID, user, bookings, handovers), nil
+}
+
+// ForgetUser обезличивает заявки пользователя и удаляет его персональные данные.
+// Возвращает обезличенные заявки, чтобы вызывающий код мог обновить внешние копии (Google Sheets).
+func (s *UserService) ForgetUser(ctx context.Context, telegramID int64) ([]*models.Booking, error) {
+	ids, err := s.repo.AnonymizeUser(ctx, telegramID)
+	if err != nil {
+		return nil, err
+	}
+
+	bookings := make([]*models.Booking, 0, len(ids))
+	for _, id := range ids {
+		booking, err := s.repo.GetBooking(ctx, id)
+		if err != nil {
+			s.logger.Error().Err(err).Int64("booking_id", id).Msg("failed to reload anonymized booking")
+			continue
+		}
+		bookings = append(bookings, booking)
+	}
+
+	s.logger.Info().Int("bookings", len(bookings)).Msg("User personal data erased")
+	return bookings, nil
+}
+
+// SaveUser сохраняет профиль пользователя. Флаг блокировки меняется только через BlockUser/UnblockUser.
 func (s *UserService) SaveUser(ctx context.Context, user *models.User) error {
 	user.IsManager = s.IsManager(user.TelegramID)
-	user.IsBlacklisted = s.IsBlacklisted(user.TelegramID)
 	return s.repo.CreateOrUpdateUser(ctx, user)
 }
 
@@ -56,6 +383,35 @@ func (s *UserService) UpdateUserPhone(ctx context.Context, telegramID int64, pho
 	return s.repo.UpdateUserPhone(ctx, telegramID, phone)
 }
 
+// GetLanguage возвращает язык, выбранный пользователем, а если его нет — язык клиента Telegram из профиля
+func (s *UserService) GetLanguage(ctx context.Context, telegramID int64) string {
+	user, err := s.repo.GetUserByTelegramID(ctx, telegramID)
+	if err != nil || user == nil {
+		return ""
+	}
+	if user.PreferredLanguage != "" {
+		return user.PreferredLanguage
+	}
+	return user.LanguageCode
+}
+
+func (s *UserService) SetLanguage(ctx context.Context, telegramID int64, lang string) error {
+	return s.repo.UpdateUserLanguage(ctx, telegramID, lang)
+}
+
+// RemindersEnabled сообщает, получает ли пользователь напоминания о бронях (по умолчанию да)
+func (s *UserService) RemindersEnabled(ctx context.Context, telegramID int64) bool {
+	user, err := s.repo.GetUserByTelegramID(ctx, telegramID)
+	if err != nil || user == nil {
+		return true
+	}
+	return !user.RemindersDisabled
+}
+
+func (s *UserService) SetRemindersEnabled(ctx context.Context, telegramID int64, enabled bool) error {
+	return s.repo.UpdateUserReminders(ctx, telegramID, !enabled)
+}
+
 func (s *UserService) UpdateUserActivity(ctx context.Context, telegramID int64) error {
 	return s.repo.UpdateUserActivity(ctx, telegramID)
 }
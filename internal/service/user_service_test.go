@@ -2,6 +2,7 @@ package service
 
 import (
 	"context"
+	"database/sql"
 	"testing"
 	"time"
 
@@ -11,6 +12,7 @@ import (
 	"github.com/rs/zerolog"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 )
 
 // MockRepository is a mock of the domain.Repository interface
@@ -54,6 +56,31 @@ func (m *MockRepository) GetBookingsByDateRange(ctx context.Context, start, end
 	return args.Get(0).([]*models.Booking), args.Error(1)
 }
 
+func (m *MockRepository) SearchBookings(ctx context.Context, filter models.BookingFilter) ([]*models.Booking, error) {
+	args := m.Called(ctx, filter)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.Booking), args.Error(1)
+}
+
+func (m *MockRepository) StreamBookings(
+	ctx context.Context,
+	filter models.BookingFilter,
+	fn func(*models.Booking) error,
+) error {
+	args := m.Called(ctx, filter, fn)
+	return args.Error(0)
+}
+
+func (m *MockRepository) ImportBookings(ctx context.Context, bookings []*models.Booking, dryRun bool) ([]error, error) {
+	args := m.Called(ctx, bookings, dryRun)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]error), args.Error(1)
+}
+
 func (m *MockRepository) CheckAvailability(ctx context.Context, itemID int64, date time.Time) (bool, error) {
 	args := m.Called(ctx, itemID, date)
 	return args.Bool(0), args.Error(1)
@@ -101,6 +128,11 @@ func (m *MockRepository) CreateItem(ctx context.Context, item *models.Item) erro
 	return args.Error(0)
 }
 
+func (m *MockRepository) ImportItems(ctx context.Context, items []*models.Item, dryRun bool) error {
+	args := m.Called(ctx, items, dryRun)
+	return args.Error(0)
+}
+
 func (m *MockRepository) UpdateItem(ctx context.Context, item *models.Item) error {
 	args := m.Called(ctx, item)
 	return args.Error(0)
@@ -124,6 +156,11 @@ func (m *MockRepository) GetAllUsers(ctx context.Context) ([]*models.User, error
 	return args.Get(0).([]*models.User), args.Error(1)
 }
 
+func (m *MockRepository) ImportUsers(ctx context.Context, users []*models.User, dryRun bool) error {
+	args := m.Called(ctx, users, dryRun)
+	return args.Error(0)
+}
+
 func (m *MockRepository) GetUserByTelegramID(ctx context.Context, telegramID int64) (*models.User, error) {
 	args := m.Called(ctx, telegramID)
 	if args.Get(0) == nil {
@@ -155,6 +192,16 @@ func (m *MockRepository) UpdateUserPhone(ctx context.Context, telegramID int64,
 	return args.Error(0)
 }
 
+func (m *MockRepository) UpdateUserLanguage(ctx context.Context, telegramID int64, lang string) error {
+	args := m.Called(ctx, telegramID, lang)
+	return args.Error(0)
+}
+
+func (m *MockRepository) UpdateUserReminders(ctx context.Context, telegramID int64, disabled bool) error {
+	args := m.Called(ctx, telegramID, disabled)
+	return args.Error(0)
+}
+
 func (m *MockRepository) GetDailyBookings(ctx context.Context, start, end time.Time) (map[string][]*models.Booking, error) {
 	args := m.Called(ctx, start, end)
 	if args.Get(0) == nil {
@@ -213,6 +260,271 @@ func (m *MockRepository) GetUserBookings(ctx context.Context, userID int64) ([]*
 	return args.Get(0).([]*models.Booking), args.Error(1)
 }
 
+func (m *MockRepository) CreateAuditEntry(ctx context.Context, entry *models.AuditEntry) error {
+	args := m.Called(ctx, entry)
+	return args.Error(0)
+}
+
+func (m *MockRepository) GetAuditEntries(ctx context.Context, entityType string, entityID int64) ([]*models.AuditEntry, error) {
+	args := m.Called(ctx, entityType, entityID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.AuditEntry), args.Error(1)
+}
+
+func (m *MockRepository) GetUserRoles(ctx context.Context) ([]*models.UserRole, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.UserRole), args.Error(1)
+}
+
+func (m *MockRepository) SetUserRole(ctx context.Context, role *models.UserRole) error {
+	args := m.Called(ctx, role)
+	return args.Error(0)
+}
+
+func (m *MockRepository) DeleteUserRole(ctx context.Context, telegramID int64) error {
+	args := m.Called(ctx, telegramID)
+	return args.Error(0)
+}
+
+func (m *MockRepository) GetBlacklistedUsers(ctx context.Context) ([]*models.User, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.User), args.Error(1)
+}
+
+func (m *MockRepository) BlacklistUser(ctx context.Context, telegramID int64, reason string, until time.Time, blockedBy int64) error {
+	args := m.Called(ctx, telegramID, reason, until, blockedBy)
+	return args.Error(0)
+}
+
+func (m *MockRepository) UnblacklistUser(ctx context.Context, telegramID int64) error {
+	args := m.Called(ctx, telegramID)
+	return args.Error(0)
+}
+
+func (m *MockRepository) SetUserConsent(ctx context.Context, telegramID int64) error {
+	args := m.Called(ctx, telegramID)
+	return args.Error(0)
+}
+
+func (m *MockRepository) GetAllUserBookings(ctx context.Context, userID int64) ([]*models.Booking, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.Booking), args.Error(1)
+}
+
+func (m *MockRepository) AnonymizeUser(ctx context.Context, telegramID int64) ([]int64, error) {
+	args := m.Called(ctx, telegramID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]int64), args.Error(1)
+}
+
+func (m *MockRepository) GetBookingEscalations(ctx context.Context, bookingIDs []int64) (map[int64]*models.BookingEscalation, error) {
+	args := m.Called(ctx, bookingIDs)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(map[int64]*models.BookingEscalation), args.Error(1)
+}
+
+func (m *MockRepository) SaveBookingEscalation(ctx context.Context, escalation *models.BookingEscalation) error {
+	args := m.Called(ctx, escalation)
+	return args.Error(0)
+}
+
+func (m *MockRepository) ClaimReminder(ctx context.Context, bookingID int64, key string) (bool, error) {
+	args := m.Called(ctx, bookingID, key)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockRepository) ReleaseReminder(ctx context.Context, bookingID int64, key string) error {
+	args := m.Called(ctx, bookingID, key)
+	return args.Error(0)
+}
+
+func (m *MockRepository) CreateHandover(ctx context.Context, handover *models.Handover) error {
+	args := m.Called(ctx, handover)
+	return args.Error(0)
+}
+
+func (m *MockRepository) CheckInHandover(ctx context.Context, handover *models.Handover) error {
+	args := m.Called(ctx, handover)
+	return args.Error(0)
+}
+
+func (m *MockRepository) FindHandover(ctx context.Context, userID, itemID int64, date time.Time) (*models.Handover, error) {
+	args := m.Called(ctx, userID, itemID, date)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Handover), args.Error(1)
+}
+
+func (m *MockRepository) GetUserHandovers(ctx context.Context, userID int64) ([]*models.Handover, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.Handover), args.Error(1)
+}
+
+func (m *MockRepository) GetOpenHandovers(ctx context.Context) ([]*models.Handover, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.Handover), args.Error(1)
+}
+
+func (m *MockRepository) GetHandoversByPeriod(ctx context.Context, start, end time.Time) ([]*models.Handover, error) {
+	args := m.Called(ctx, start, end)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.Handover), args.Error(1)
+}
+
+func (m *MockRepository) SetHandoverOverdueNotified(ctx context.Context, id int64, at time.Time) error {
+	args := m.Called(ctx, id, at)
+	return args.Error(0)
+}
+
+func (m *MockRepository) CreateMaintenance(ctx context.Context, window *models.MaintenanceWindow) error {
+	args := m.Called(ctx, window)
+	return args.Error(0)
+}
+
+func (m *MockRepository) GetMaintenance(ctx context.Context, id int64) (*models.MaintenanceWindow, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.MaintenanceWindow), args.Error(1)
+}
+
+func (m *MockRepository) DeleteMaintenance(ctx context.Context, id int64) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockRepository) GetMaintenanceWindows(ctx context.Context, itemID int64, start, end time.Time) ([]*models.MaintenanceWindow, error) {
+	args := m.Called(ctx, itemID, start, end)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.MaintenanceWindow), args.Error(1)
+}
+
+func (m *MockRepository) GetExternalHolds(ctx context.Context, source string) ([]*models.MaintenanceWindow, error) {
+	args := m.Called(ctx, source)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.MaintenanceWindow), args.Error(1)
+}
+
+func (m *MockRepository) ReplaceExternalHolds(ctx context.Context, source string, windows []*models.MaintenanceWindow) error {
+	args := m.Called(ctx, source, windows)
+	return args.Error(0)
+}
+
+func (m *MockRepository) CreateUnit(ctx context.Context, unit *models.ItemUnit) error {
+	args := m.Called(ctx, unit)
+	return args.Error(0)
+}
+
+func (m *MockRepository) GetUnit(ctx context.Context, id int64) (*models.ItemUnit, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.ItemUnit), args.Error(1)
+}
+
+func (m *MockRepository) GetUnitBySerial(ctx context.Context, serial string) (*models.ItemUnit, error) {
+	args := m.Called(ctx, serial)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.ItemUnit), args.Error(1)
+}
+
+func (m *MockRepository) GetItemUnits(ctx context.Context, itemID int64) ([]*models.ItemUnit, error) {
+	args := m.Called(ctx, itemID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.ItemUnit), args.Error(1)
+}
+
+func (m *MockRepository) UpdateUnitStatus(ctx context.Context, id int64, status, note string) error {
+	args := m.Called(ctx, id, status, note)
+	return args.Error(0)
+}
+
+func (m *MockRepository) SetBookingUnit(ctx context.Context, bookingID, unitID int64) error {
+	args := m.Called(ctx, bookingID, unitID)
+	return args.Error(0)
+}
+
+func (m *MockRepository) SetBookingPrice(ctx context.Context, bookingID, price, deposit int64) error {
+	args := m.Called(ctx, bookingID, price, deposit)
+	return args.Error(0)
+}
+
+func (m *MockRepository) GetBusyUnitIDs(ctx context.Context, itemID int64, date time.Time) ([]int64, error) {
+	args := m.Called(ctx, itemID, date)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]int64), args.Error(1)
+}
+
+func (m *MockRepository) GetUnitBookings(ctx context.Context, unitID int64) ([]*models.Booking, error) {
+	args := m.Called(ctx, unitID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.Booking), args.Error(1)
+}
+
+func (m *MockRepository) CreateCalendarFeed(ctx context.Context, feed *models.CalendarFeed) error {
+	args := m.Called(ctx, feed)
+	return args.Error(0)
+}
+
+func (m *MockRepository) GetCalendarFeed(ctx context.Context, id int64) (*models.CalendarFeed, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.CalendarFeed), args.Error(1)
+}
+
+func (m *MockRepository) GetActiveCalendarFeed(ctx context.Context, scope string, subjectID int64) (*models.CalendarFeed, error) {
+	args := m.Called(ctx, scope, subjectID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.CalendarFeed), args.Error(1)
+}
+
+func (m *MockRepository) RevokeCalendarFeeds(ctx context.Context, scope string, subjectID int64) (int64, error) {
+	args := m.Called(ctx, scope, subjectID)
+	return args.Get(0).(int64), args.Error(1)
+}
+
 func TestUserService_IsManager(t *testing.T) {
 	mockRepo := new(MockRepository)
 	logger := zerolog.Nop()
@@ -364,3 +676,238 @@ func TestUserService_GetUserByID(t *testing.T) {
 	assert.Nil(t, result)
 	mockRepo.AssertExpectations(t)
 }
+
+func TestUserService_Roles(t *testing.T) {
+	mockRepo := new(MockRepository)
+	logger := zerolog.Nop()
+	cfg := &config.Config{Managers: []int64{1}}
+	s := NewUserService(mockRepo, cfg, &logger)
+	ctx := context.Background()
+
+	mockRepo.On("GetUserRoles", mock.Anything).Return([]*models.UserRole{
+		{TelegramID: 20, Role: models.RoleManager, ItemIDs: []int64{5}},
+		{TelegramID: 10, Role: models.RoleManager},
+		{TelegramID: 30, Role: models.RoleViewer},
+	}, nil)
+	assert.NoError(t, s.LoadRoles(ctx))
+
+	assert.Equal(t, models.RoleAdmin, s.GetRole(1))
+	assert.Equal(t, models.RoleManager, s.GetRole(20))
+	assert.Equal(t, "", s.GetRole(99))
+
+	assert.True(t, s.IsManager(20))
+	assert.False(t, s.IsManager(30))
+	assert.True(t, s.HasPermission(30, models.PermViewStats))
+	assert.False(t, s.HasPermission(30, models.PermViewBookings))
+	assert.False(t, s.HasPermission(20, models.PermManageRoles))
+
+	assert.True(t, s.CanManageItem(20, 5))
+	assert.False(t, s.CanManageItem(20, 6))
+	assert.True(t, s.CanManageItem(10, 6))
+	assert.False(t, s.CanManageItem(30, 5))
+
+	assert.Equal(t, []int64{1, 10, 20}, s.GetStaffForItem(5, models.PermManageBookings))
+	assert.Equal(t, []int64{1, 10}, s.GetStaffForItem(6, models.PermManageBookings))
+}
+
+func TestUserService_SetUserRole(t *testing.T) {
+	mockRepo := new(MockRepository)
+	logger := zerolog.Nop()
+	cfg := &config.Config{Managers: []int64{1}}
+	s := NewUserService(mockRepo, cfg, &logger)
+	ctx := context.Background()
+
+	err := s.SetUserRole(ctx, &models.UserRole{TelegramID: 1, Role: models.RoleViewer})
+	assert.ErrorIs(t, err, models.ErrRoleManagedByConfig)
+
+	err = s.SetUserRole(ctx, &models.UserRole{TelegramID: 2, Role: "owner"})
+	assert.ErrorIs(t, err, models.ErrInvalidRole)
+
+	role := &models.UserRole{TelegramID: 2, Role: models.RoleManager, ItemIDs: []int64{3}, GrantedBy: 1}
+	mockRepo.On("SetUserRole", mock.Anything, role).Return(nil).Once()
+	mockRepo.On("CreateAuditEntry", mock.Anything, mock.MatchedBy(func(e *models.AuditEntry) bool {
+		return e.EntityType == models.AuditEntityRole && e.EntityID == 2 && e.ActorID == 1 && e.Before == ""
+	})).Return(nil).Once()
+
+	assert.NoError(t, s.SetUserRole(ctx, role))
+	assert.True(t, s.CanManageItem(2, 3))
+	assert.False(t, s.CanManageItem(2, 4))
+
+	mockRepo.On("DeleteUserRole", mock.Anything, int64(2)).Return(nil).Once()
+	mockRepo.On("CreateAuditEntry", mock.Anything, mock.MatchedBy(func(e *models.AuditEntry) bool {
+		return e.Action == models.AuditActionDelete && e.After == ""
+	})).Return(nil).Once()
+
+	assert.NoError(t, s.RemoveUserRole(ctx, 2))
+	assert.Equal(t, "", s.GetRole(2))
+	mockRepo.AssertExpectations(t)
+}
+
+func TestUserService_Blacklist(t *testing.T) {
+	mockRepo := new(MockRepository)
+	logger := zerolog.Nop()
+	cfg := &config.Config{Managers: []int64{1}, Blacklist: []int64{900}}
+	s := NewUserService(mockRepo, cfg, &logger)
+	ctx := context.Background()
+
+	mockRepo.On("GetBlacklistedUsers", mock.Anything).Return([]*models.User{
+		{TelegramID: 10, IsBlacklisted: true, BlacklistReason: "spam"},
+		{
+			TelegramID: 11, IsBlacklisted: true,
+			BlacklistedUntil: sql.NullTime{Time: time.Now().Add(-time.Hour), Valid: true},
+		},
+	}, nil)
+	assert.NoError(t, s.LoadBlacklist(ctx))
+
+	info, ok := s.GetBlacklistInfo(10)
+	assert.True(t, ok)
+	assert.Equal(t, "spam", info.BlacklistReason)
+	assert.False(t, s.IsBlacklisted(11), "expired block must not apply")
+	assert.True(t, s.IsBlacklisted(900))
+
+	active, err := s.ListBlacklistedUsers(ctx)
+	assert.NoError(t, err)
+	assert.Len(t, active, 1)
+
+	assert.ErrorIs(t, s.BlockUser(ctx, 1, "", time.Time{}, 2), models.ErrCannotBlockStaff)
+	assert.ErrorIs(t, s.UnblockUser(ctx, 900), models.ErrBlacklistManagedByConfig)
+
+	until := time.Now().Add(48 * time.Hour)
+	mockRepo.On("BlacklistUser", mock.Anything, int64(20), "no-show", until, int64(1)).Return(nil).Once()
+	mockRepo.On("CreateAuditEntry", mock.Anything, mock.MatchedBy(func(e *models.AuditEntry) bool {
+		return e.EntityType == models.AuditEntityUser && e.Action == models.AuditActionBlock && e.ActorID == 1
+	})).Return(nil).Once()
+	assert.NoError(t, s.BlockUser(ctx, 20, "no-show", until, 1))
+	assert.True(t, s.IsBlacklisted(20))
+
+	mockRepo.On("UnblacklistUser", mock.Anything, int64(20)).Return(nil).Once()
+	mockRepo.On("CreateAuditEntry", mock.Anything, mock.MatchedBy(func(e *models.AuditEntry) bool {
+		return e.Action == models.AuditActionUnblock
+	})).Return(nil).Once()
+	assert.NoError(t, s.UnblockUser(ctx, 20))
+	assert.False(t, s.IsBlacklisted(20))
+
+	// SaveUser не должен перетирать флаг блокировки
+	user := &models.User{TelegramID: 10, FirstName: "Test"}
+	mockRepo.On("CreateOrUpdateUser", mock.Anything, user).Return(nil).Once()
+	assert.NoError(t, s.SaveUser(ctx, user))
+	assert.False(t, user.IsBlacklisted)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestUserService_PersonalData(t *testing.T) {
+	mockRepo := new(MockRepository)
+	logger := zerolog.Nop()
+	s := NewUserService(mockRepo, &config.Config{}, &logger)
+	ctx := context.Background()
+
+	mockRepo.On("GetUserByTelegramID", mock.Anything, int64(1)).Return(&models.User{
+		TelegramID: 1, FirstName: "Test", Phone: "79990000000", ConsentGiven: true,
+		ConsentGivenAt: sql.NullTime{Time: time.Now(), Valid: true},
+	}, nil)
+	mockRepo.On("GetUserByTelegramID", mock.Anything, int64(2)).Return(nil, sql.ErrNoRows)
+
+	assert.True(t, s.HasConsent(ctx, 1))
+	assert.False(t, s.HasConsent(ctx, 2))
+
+	mockRepo.On("SetUserConsent", mock.Anything, int64(2)).Return(nil).Once()
+	assert.NoError(t, s.GiveConsent(ctx, 2))
+
+	bookings := []*models.Booking{{ID: 10, UserID: 1}}
+	handovers := []*models.Handover{{ID: 3, BookingID: 10, UserID: 1, CheckOutNote: "паспорт"}}
+	mockRepo.On("GetAllUserBookings", mock.Anything, int64(1)).Return(bookings, nil).Once()
+	mockRepo.On("GetUserHandovers", mock.Anything, int64(1)).Return(handovers, nil).Once()
+	export, err := s.ExportPersonalData(ctx, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, "79990000000", export.Phone)
+	assert.NotNil(t, export.ConsentGivenAt)
+	assert.Equal(t, bookings, export.Bookings)
+	assert.Equal(t, handovers, export.Handovers)
+
+	// Пользователь без профиля получает пустую выгрузку, а не ошибку
+	mockRepo.On("GetAllUserBookings", mock.Anything, int64(2)).Return(nil, nil).Once()
+	mockRepo.On("GetUserHandovers", mock.Anything, int64(2)).Return(nil, nil).Once()
+	export, err = s.ExportPersonalData(ctx, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), export.TelegramID)
+	assert.NotNil(t, export.Bookings)
+	assert.NotNil(t, export.Handovers)
+
+	mockRepo.On("AnonymizeUser", mock.Anything, int64(1)).Return([]int64{10}, nil).Once()
+	mockRepo.On("GetBooking", mock.Anything, int64(10)).Return(&models.Booking{ID: 10, UserName: models.AnonymizedUserName}, nil).Once()
+	erased, err := s.ForgetUser(ctx, 1)
+	assert.NoError(t, err)
+	assert.Len(t, erased, 1)
+	assert.Equal(t, models.AnonymizedUserName, erased[0].UserName)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestUserService_Language(t *testing.T) {
+	mockRepo := new(MockRepository)
+	logger := zerolog.Nop()
+	s := NewUserService(mockRepo, &config.Config{}, &logger)
+	ctx := context.Background()
+
+	mockRepo.On("GetUserByTelegramID", mock.Anything, int64(1)).Return(&models.User{TelegramID: 1, LanguageCode: "en-US"}, nil)
+	mockRepo.On("GetUserByTelegramID", mock.Anything, int64(2)).
+		Return(&models.User{TelegramID: 2, LanguageCode: "ru", PreferredLanguage: "en"}, nil)
+	mockRepo.On("GetUserByTelegramID", mock.Anything, int64(3)).Return(nil, sql.ErrNoRows)
+
+	assert.Equal(t, "en-US", s.GetLanguage(ctx, 1))
+	assert.Equal(t, "en", s.GetLanguage(ctx, 2))
+	assert.Equal(t, "", s.GetLanguage(ctx, 3))
+
+	mockRepo.On("UpdateUserLanguage", mock.Anything, int64(3), "en").Return(nil).Once()
+	assert.NoError(t, s.SetLanguage(ctx, 3, "en"))
+	mockRepo.AssertExpectations(t)
+}
+
+func TestUserService_Reminders(t *testing.T) {
+	mockRepo := new(MockRepository)
+	logger := zerolog.Nop()
+	s := NewUserService(mockRepo, &config.Config{}, &logger)
+	ctx := context.Background()
+
+	mockRepo.On("GetUserByTelegramID", mock.Anything, int64(1)).Return(&models.User{TelegramID: 1}, nil)
+	mockRepo.On("GetUserByTelegramID", mock.Anything, int64(2)).Return(&models.User{TelegramID: 2, RemindersDisabled: true}, nil)
+	mockRepo.On("GetUserByTelegramID", mock.Anything, int64(3)).Return(nil, sql.ErrNoRows)
+
+	assert.True(t, s.RemindersEnabled(ctx, 1))
+	assert.False(t, s.RemindersEnabled(ctx, 2))
+	assert.True(t, s.RemindersEnabled(ctx, 3))
+
+	mockRepo.On("UpdateUserReminders", mock.Anything, int64(1), true).Return(nil).Once()
+	assert.NoError(t, s.SetRemindersEnabled(ctx, 1, false))
+	mockRepo.AssertExpectations(t)
+}
+
+func TestUserService_CalendarFeed(t *testing.T) {
+	mockRepo := new(MockRepository)
+	logger := zerolog.Nop()
+	s := NewUserService(mockRepo, &config.Config{}, &logger)
+	ctx := context.Background()
+
+	existing := &models.CalendarFeed{ID: 5, Scope: models.CalendarScopeUser, SubjectID: 1}
+	mockRepo.On("GetActiveCalendarFeed", mock.Anything, models.CalendarScopeUser, int64(1)).Return(existing, nil)
+	mockRepo.On("GetActiveCalendarFeed", mock.Anything, models.CalendarScopeUser, int64(2)).Return(nil, models.ErrCalendarFeedNotFound)
+	mockRepo.On("CreateCalendarFeed", mock.Anything, mock.AnythingOfType("*models.CalendarFeed")).
+		Run(func(args mock.Arguments) { args.Get(1).(*models.CalendarFeed).ID = 6 }).Return(nil)
+
+	feed, err := s.GetCalendarFeed(ctx, models.CalendarScopeUser, 1, 1)
+	require.NoError(t, err)
+	assert.Equal(t, int64(5), feed.ID)
+
+	feed, err = s.GetCalendarFeed(ctx, models.CalendarScopeUser, 2, 2)
+	require.NoError(t, err)
+	assert.Equal(t, int64(6), feed.ID)
+	assert.Equal(t, int64(2), feed.SubjectID)
+
+	mockRepo.On("RevokeCalendarFeeds", mock.Anything, models.CalendarScopeUser, int64(1)).Return(int64(1), nil).Once()
+	feed, err = s.ResetCalendarFeed(ctx, models.CalendarScopeUser, 1, 1)
+	require.NoError(t, err)
+	assert.Equal(t, int64(6), feed.ID)
+
+	_, err = s.GetCalendarFeed(ctx, "team", 1, 1)
+	assert.Error(t, err)
+	mockRepo.AssertExpectations(t)
+}
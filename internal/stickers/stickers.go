@@ -0,0 +1,236 @@
+// Package stickers renders printable sheets of QR code stickers. Each sticker opens
+// the bot on an item's schedule and is meant to be glued onto the physical device.
+package stickers
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"strings"
+
+	"bronivik/internal/models"
+
+	"github.com/jung-kurt/gofpdf"
+	qrcode "github.com/skip2/go-qrcode"
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/gofont/goregular"
+	"golang.org/x/image/font/opentype"
+	"golang.org/x/image/math/fixed"
+)
+
+// Output formats accepted by Render.
+const (
+	FormatPNG = "png"
+	FormatPDF = "pdf"
+)
+
+// Sheet layout: stickers are placed in a grid, PDF pages are A4 portrait.
+const (
+	columns     = 3
+	rowsPerPage = 4
+
+	// PNG cell size in pixels.
+	cellWidth  = 400
+	cellHeight = 480
+	qrSize     = 340
+
+	// PDF cell size and QR side in millimeters.
+	pdfCellWidth  = 70.0
+	pdfCellHeight = 74.0
+	pdfQRSize     = 50.0
+
+	fontFamily = "goregular"
+)
+
+// Sticker is one QR code with its printed labels.
+type Sticker struct {
+	URL     string // encoded in the QR code
+	Title   string // item name printed under the code
+	Caption string // smaller second line, e.g. the item ID
+}
+
+// ForItems builds stickers linking to each item's schedule in the bot.
+func ForItems(items []*models.Item, botUsername string) ([]Sticker, error) {
+	if botUsername == "" {
+		return nil, fmt.Errorf("bot username is not configured")
+	}
+	stickers := make([]Sticker, 0, len(items))
+	for _, item := range items {
+		stickers = append(stickers, Sticker{
+			URL:     models.ItemStartLink(botUsername, item.ID),
+			Title:   item.Name,
+			Caption: fmt.Sprintf("#%d", item.ID),
+		})
+	}
+	return stickers, nil
+}
+
+// ContentType returns the MIME type of a format.
+func ContentType(format string) string {
+	if format == FormatPDF {
+		return "application/pdf"
+	}
+	return "image/png"
+}
+
+// Render renders the stickers in the given format.
+func Render(stickers []Sticker, format string) ([]byte, error) {
+	switch format {
+	case FormatPNG:
+		return PNG(stickers)
+	case FormatPDF:
+		return PDF(stickers)
+	default:
+		return nil, fmt.Errorf("unknown sticker format %q", format)
+	}
+}
+
+// PNG renders all stickers onto one image, three per row.
+func PNG(stickers []Sticker) ([]byte, error) {
+	if len(stickers) == 0 {
+		return nil, fmt.Errorf("no stickers to render")
+	}
+
+	titleFace, err := newFace(24)
+	if err != nil {
+		return nil, err
+	}
+	captionFace, err := newFace(18)
+	if err != nil {
+		return nil, err
+	}
+
+	rows := (len(stickers) + columns - 1) / columns
+	sheet := image.NewRGBA(image.Rect(0, 0, columns*cellWidth, rows*cellHeight))
+	draw.Draw(sheet, sheet.Bounds(), image.White, image.Point{}, draw.Src)
+
+	border := color.Gray{Y: 200}
+	for i, s := range stickers {
+		x0, y0 := (i%columns)*cellWidth, (i/columns)*cellHeight
+		drawFrame(sheet, image.Rect(x0, y0, x0+cellWidth, y0+cellHeight), border)
+
+		code, errCode := qrcode.New(s.URL, qrcode.Medium)
+		if errCode != nil {
+			return nil, fmt.Errorf("qr code for %q: %w", s.Title, errCode)
+		}
+		qr := code.Image(qrSize)
+		qrX := x0 + (cellWidth-qrSize)/2
+		draw.Draw(sheet, image.Rect(qrX, y0+20, qrX+qrSize, y0+20+qrSize), qr, image.Point{}, draw.Src)
+
+		drawCentered(sheet, titleFace, s.Title, x0, y0+qrSize+60)
+		drawCentered(sheet, captionFace, s.Caption, x0, y0+qrSize+95)
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, sheet); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// PDF renders the stickers on A4 pages, twelve per page.
+func PDF(stickers []Sticker) ([]byte, error) {
+	if len(stickers) == 0 {
+		return nil, fmt.Errorf("no stickers to render")
+	}
+
+	pdf := gofpdf.New("P", "mm", "A4", "")
+	pdf.SetMargins(0, 0, 0)
+	pdf.SetAutoPageBreak(false, 0)
+	pdf.AddUTF8FontFromBytes(fontFamily, "", goregular.TTF)
+	pdf.SetDrawColor(200, 200, 200)
+
+	pageWidth, _ := pdf.GetPageSize()
+	left := (pageWidth - columns*pdfCellWidth) / 2
+	perPage := columns * rowsPerPage
+
+	for i, s := range stickers {
+		if i%perPage == 0 {
+			pdf.AddPage()
+		}
+		pos := i % perPage
+		x := left + float64(pos%columns)*pdfCellWidth
+		y := 10 + float64(pos/columns)*pdfCellHeight
+		pdf.Rect(x, y, pdfCellWidth, pdfCellHeight, "D")
+
+		qr, err := qrcode.Encode(s.URL, qrcode.Medium, 512)
+		if err != nil {
+			return nil, fmt.Errorf("qr code for %q: %w", s.Title, err)
+		}
+		name := fmt.Sprintf("qr%d", i)
+		pdf.RegisterImageOptionsReader(name, gofpdf.ImageOptions{ImageType: "PNG"}, bytes.NewReader(qr))
+		pdf.ImageOptions(name, x+(pdfCellWidth-pdfQRSize)/2, y+4, pdfQRSize, pdfQRSize, false, gofpdf.ImageOptions{}, 0, "")
+
+		pdf.SetFont(fontFamily, "", 11)
+		pdf.SetXY(x+2, y+pdfQRSize+6)
+		pdf.CellFormat(pdfCellWidth-4, 6, fitPDF(pdf, s.Title, pdfCellWidth-4), "", 0, "C", false, 0, "")
+		pdf.SetFont(fontFamily, "", 9)
+		pdf.SetXY(x+2, y+pdfQRSize+12)
+		pdf.CellFormat(pdfCellWidth-4, 5, s.Caption, "", 0, "C", false, 0, "")
+	}
+
+	var buf bytes.Buffer
+	if err := pdf.Output(&buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func newFace(size float64) (font.Face, error) {
+	parsed, err := opentype.Parse(goregular.TTF)
+	if err != nil {
+		return nil, err
+	}
+	return opentype.NewFace(parsed, &opentype.FaceOptions{Size: size, DPI: 72, Hinting: font.HintingFull})
+}
+
+// drawCentered draws one line of text centered in the cell starting at x0, with the baseline at y.
+func drawCentered(dst draw.Image, face font.Face, text string, x0, y int) {
+	text = fitPNG(face, text, cellWidth-20)
+	width := font.MeasureString(face, text).Round()
+	d := &font.Drawer{
+		Dst:  dst,
+		Src:  image.Black,
+		Face: face,
+		Dot:  fixed.P(x0+(cellWidth-width)/2, y),
+	}
+	d.DrawString(text)
+}
+
+func drawFrame(dst *image.RGBA, r image.Rectangle, c color.Color) {
+	for x := r.Min.X; x < r.Max.X; x++ {
+		dst.Set(x, r.Min.Y, c)
+		dst.Set(x, r.Max.Y-1, c)
+	}
+	for y := r.Min.Y; y < r.Max.Y; y++ {
+		dst.Set(r.Min.X, y, c)
+		dst.Set(r.Max.X-1, y, c)
+	}
+}
+
+// fitPNG shortens text with an ellipsis until it fits into width pixels.
+func fitPNG(face font.Face, text string, width int) string {
+	return fit(text, func(s string) bool { return font.MeasureString(face, s).Round() <= width })
+}
+
+// fitPDF shortens text with an ellipsis until it fits into width millimeters.
+func fitPDF(pdf *gofpdf.Fpdf, text string, width float64) string {
+	return fit(text, func(s string) bool { return pdf.GetStringWidth(s) <= width })
+}
+
+func fit(text string, fits func(string) bool) string {
+	if fits(text) {
+		return text
+	}
+	runes := []rune(strings.TrimSpace(text))
+	for len(runes) > 0 {
+		runes = runes[:len(runes)-1]
+		if candidate := strings.TrimSpace(string(runes)) + "…"; fits(candidate) {
+			return candidate
+		}
+	}
+	return ""
+}
@@ -0,0 +1,65 @@
+package stickers
+
+import (
+	"bytes"
+	"image/png"
+	"strings"
+	"testing"
+
+	"bronivik/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testStickers(n int) []Sticker {
+	items := make([]*models.Item, 0, n)
+	for i := 1; i <= n; i++ {
+		items = append(items, &models.Item{ID: int64(i), Name: "Кресло-коляска с очень длинным названием модели"})
+	}
+	stickers, _ := ForItems(items, "bronivik_bot")
+	return stickers
+}
+
+func TestForItems(t *testing.T) {
+	stickers, err := ForItems([]*models.Item{{ID: 7, Name: "Laser"}}, "bronivik_bot")
+	require.NoError(t, err)
+	require.Len(t, stickers, 1)
+	assert.Equal(t, "https://t.me/bronivik_bot?start=item_7", stickers[0].URL)
+	assert.Equal(t, "Laser", stickers[0].Title)
+	assert.Equal(t, "#7", stickers[0].Caption)
+
+	_, err = ForItems([]*models.Item{{ID: 7}}, "")
+	assert.Error(t, err)
+}
+
+func TestPNG(t *testing.T) {
+	data, err := Render(testStickers(4), FormatPNG)
+	require.NoError(t, err)
+
+	img, err := png.Decode(bytes.NewReader(data))
+	require.NoError(t, err)
+	assert.Equal(t, columns*cellWidth, img.Bounds().Dx())
+	assert.Equal(t, 2*cellHeight, img.Bounds().Dy(), "4 наклейки занимают 2 ряда")
+}
+
+func TestPDF(t *testing.T) {
+	data, err := Render(testStickers(13), FormatPDF)
+	require.NoError(t, err)
+	assert.True(t, bytes.HasPrefix(data, []byte("%PDF")))
+	assert.Equal(t, 2, bytes.Count(data, []byte("/Type /Page\n")), "13 наклеек занимают 2 страницы")
+}
+
+func TestRender_Errors(t *testing.T) {
+	_, err := Render(testStickers(1), "gif")
+	assert.Error(t, err)
+	_, err = Render(nil, FormatPDF)
+	assert.Error(t, err)
+}
+
+func TestFit(t *testing.T) {
+	fits := func(s string) bool { return len([]rune(s)) <= 6 }
+	assert.Equal(t, "Laser", fit("Laser", fits))
+	assert.Equal(t, "Кресл…", fit("Кресло-коляска", fits))
+	assert.True(t, strings.HasSuffix(fit("Кресло коляска", fits), "…"))
+}
@@ -49,6 +49,7 @@ type SheetsClient interface {
 		ctx context.Context,
 		startDate, endDate time.Time,
 		dailyBookings map[string][]*models.Booking,
+		maintenance []*models.MaintenanceWindow,
 		items []*models.Item,
 	) error
 }
@@ -160,7 +161,9 @@ func (w *SheetsWorker) EnqueueTask(ctx context.Context, taskType string, booking
 }
 
 // Start launches main loop; stops when ctx is done.
+// Changes made while processing sync tasks are attributed to the sheet source in the audit log.
 func (w *SheetsWorker) Start(ctx context.Context) {
+	ctx = models.WithAuditActor(ctx, models.AuditActor{Source: models.AuditSourceSheet})
 	w.logger.Info().Msg("sheets_worker: started")
 	defer w.logger.Info().Msg("sheets_worker: stopped")
 
@@ -279,12 +282,17 @@ func (w *SheetsWorker) handleSheetTask(ctx context.Context, taskType string, pay
 			return fmt.Errorf("get daily bookings: %w", err)
 		}
 
+		maintenance, err := w.db.GetMaintenanceWindows(ctx, 0, startDate, endDate)
+		if err != nil {
+			return fmt.Errorf("get maintenance windows: %w", err)
+		}
+
 		items, err := w.db.GetActiveItems(ctx)
 		if err != nil {
 			return fmt.Errorf("get active items: %w", err)
 		}
 
-		return w.sheets.UpdateScheduleSheet(ctx, startDate, endDate, dailyBookings, items)
+		return w.sheets.UpdateScheduleSheet(ctx, startDate, endDate, dailyBookings, maintenance, items)
 	default:
 		return fmt.Errorf("unknown task type: %s", taskType)
 	}
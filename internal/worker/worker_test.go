@@ -536,6 +536,7 @@ func (f *fakeSheets) UpdateScheduleSheet(
 	ctx context.Context,
 	startDate, endDate time.Time,
 	dailyBookings map[string][]*models.Booking,
+	maintenance []*models.MaintenanceWindow,
 	items []*models.Item,
 ) error {
 	return f.err